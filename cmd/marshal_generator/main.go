@@ -0,0 +1,633 @@
+// This program generates reflection-free encoders and decoders for the
+// structs declared in the given files of the wire package. The generated
+// code follows the same `oscar` struct tag rules as wire.MarshalBE and
+// wire.UnmarshalBE. Structs that use features the generator doesn't support,
+// such as interface fields, are left to the reflective path.
+// Usage: go run ./cmd/marshal_generator -o [output] -test [test output] [files...]
+// Example: go run ../cmd/marshal_generator -o marshal_gen.go -test marshal_gen_test.go snacs.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const header = "// Code generated by marshal_generator. DO NOT EDIT.\n\n"
+
+func main() {
+	out := flag.String("o", "", "file to write the generated code to")
+	testOut := flag.String("test", "", "file to write the list of generated types to, for tests")
+	flag.Parse()
+
+	if *out == "" || flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: go run ./cmd/marshal_generator -o [output] -test [test output] [files...]")
+		os.Exit(1)
+	}
+
+	g, err := newGenerator(".", *out, *testOut)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error parsing package: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	names := g.structsIn(flag.Args())
+	src, err := g.generate(names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error generating code: %s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Println("writing to", *out)
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing file: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if *testOut != "" {
+		src, err := g.generateTypeList(names)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error generating type list: %s\n", err.Error())
+			os.Exit(1)
+		}
+		fmt.Println("writing to", *testOut)
+		if err := os.WriteFile(*testOut, src, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing file: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+// oscarTag mirrors the `oscar` struct tag options understood by the wire
+// package.
+type oscarTag struct {
+	countPrefix int // size in bytes, 0 if absent
+	lenPrefix   int // size in bytes, 0 if absent
+	nullTerm    bool
+	optional    bool
+}
+
+func parseTag(lit *ast.BasicLit) (oscarTag, bool) {
+	var tag oscarTag
+	if lit == nil {
+		return tag, true
+	}
+	raw, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return tag, false
+	}
+	val, ok := reflect.StructTag(raw).Lookup("oscar")
+	if !ok {
+		return tag, true
+	}
+	prefixSize := func(s string) int {
+		switch s {
+		case "uint8":
+			return 1
+		case "uint16":
+			return 2
+		}
+		return 0
+	}
+	for _, kv := range strings.Split(val, ",") {
+		k, v, hasVal := strings.Cut(kv, "=")
+		switch {
+		case hasVal && k == "len_prefix":
+			if tag.lenPrefix = prefixSize(v); tag.lenPrefix == 0 {
+				return tag, false
+			}
+		case hasVal && k == "count_prefix":
+			if tag.countPrefix = prefixSize(v); tag.countPrefix == 0 {
+				return tag, false
+			}
+		case hasVal:
+			// the reflective path ignores unknown key=value options
+		case k == "optional":
+			tag.optional = true
+		case k == "nullterm":
+			tag.nullTerm = true
+		default:
+			return tag, false
+		}
+	}
+	return tag, tag.lenPrefix == 0 || tag.countPrefix == 0
+}
+
+// uintSizes maps the unsigned integer types to their size in bytes.
+var uintSizes = map[string]int{
+	"uint8":  1,
+	"byte":   1,
+	"uint16": 2,
+	"uint32": 4,
+	"uint64": 8,
+}
+
+type generator struct {
+	fset    *token.FileSet
+	types   map[string]*ast.TypeSpec
+	files   map[string][]string // type names declared in each file
+	pkg     string
+	allowed map[string]bool // types that may get generated methods
+	support map[string]bool
+	buf     bytes.Buffer
+	vars    int
+	// usesEOF is set if the generated code checks for the end of input.
+	usesEOF bool
+}
+
+// newGenerator parses the non-test, non-generated Go files in dir.
+func newGenerator(dir string, skip ...string) (*generator, error) {
+	g := &generator{
+		fset:    token.NewFileSet(),
+		allowed: make(map[string]bool),
+		types:   make(map[string]*ast.TypeSpec),
+		files:   make(map[string][]string),
+		support: make(map[string]bool),
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if strings.HasSuffix(name, "_test.go") || slices.Contains(skip, name) {
+			continue
+		}
+		f, err := parser.ParseFile(g.fset, path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		g.pkg = f.Name.Name
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.TypeParams != nil || ts.Assign.IsValid() {
+					continue
+				}
+				g.types[ts.Name.Name] = ts
+				g.files[name] = append(g.files[name], ts.Name.Name)
+			}
+		}
+	}
+	return g, nil
+}
+
+// structsIn returns the exported struct types declared in files that the
+// generator supports, sorted by name.
+func (g *generator) structsIn(files []string) []string {
+	for _, file := range files {
+		for _, name := range g.files[filepath.Base(file)] {
+			// ICQMessageReplyEnvelope is always encoded little-endian by
+			// the reflective path
+			if ast.IsExported(name) && name != "ICQMessageReplyEnvelope" {
+				g.allowed[name] = true
+			}
+		}
+	}
+	var names []string
+	for name := range g.allowed {
+		if _, isStruct := g.types[name].Type.(*ast.StructType); isStruct && g.supportedNamed(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// supportedNamed indicates whether the named struct type can get generated
+// methods.
+func (g *generator) supportedNamed(name string) bool {
+	if !g.allowed[name] {
+		return false
+	}
+	if ok, seen := g.support[name]; seen {
+		return ok
+	}
+	// assume recursive references are fine until proven otherwise
+	g.support[name] = true
+	ok := g.supportedStruct(g.types[name].Type.(*ast.StructType))
+	g.support[name] = ok
+	return ok
+}
+
+func (g *generator) supportedStruct(st *ast.StructType) bool {
+	fields := st.Fields.List
+	for i, field := range fields {
+		if len(field.Names) > 1 {
+			return false
+		}
+		if len(field.Names) == 1 && !field.Names[0].IsExported() {
+			// the reflective path can't set unexported fields either
+			return false
+		}
+		tag, ok := parseTag(field.Tag)
+		if !ok {
+			return false
+		}
+		if _, isPtr := field.Type.(*ast.StarExpr); isPtr != tag.optional || (isPtr && i != len(fields)-1) {
+			return false
+		}
+		if !g.supported(field.Type, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *generator) supported(expr ast.Expr, tag oscarTag) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if _, ok := uintSizes[t.Name]; ok {
+			return tag.lenPrefix == 0 && tag.countPrefix == 0 && !tag.optional
+		}
+		if t.Name == "string" {
+			return tag.lenPrefix > 0
+		}
+		spec, ok := g.types[t.Name]
+		if !ok {
+			return false
+		}
+		if _, isStruct := spec.Type.(*ast.StructType); isStruct {
+			return tag.countPrefix == 0 && g.supportedNamed(t.Name)
+		}
+		return g.supported(spec.Type, tag)
+	case *ast.StructType:
+		return tag.countPrefix == 0 && g.supportedStruct(t)
+	case *ast.StarExpr:
+		if !tag.optional {
+			return false
+		}
+		tag.optional = false
+		return g.isStruct(t.X) && g.supported(t.X, tag)
+	case *ast.ArrayType:
+		if tag.optional || tag.nullTerm {
+			return false
+		}
+		if t.Len != nil && (tag.lenPrefix > 0 || tag.countPrefix > 0) {
+			return false
+		}
+		return (g.uintSize(t.Elt) > 0 || g.isStruct(t.Elt)) && g.supported(t.Elt, oscarTag{})
+	}
+	return false
+}
+
+// resolve follows named non-struct types to their definition.
+func (g *generator) resolve(expr ast.Expr) ast.Expr {
+	for {
+		id, ok := expr.(*ast.Ident)
+		if !ok {
+			return expr
+		}
+		spec, ok := g.types[id.Name]
+		if !ok {
+			return expr
+		}
+		if _, isStruct := spec.Type.(*ast.StructType); isStruct {
+			return expr
+		}
+		expr = spec.Type
+	}
+}
+
+func (g *generator) isStruct(expr ast.Expr) bool {
+	switch t := g.resolve(expr).(type) {
+	case *ast.StructType:
+		return true
+	case *ast.Ident:
+		_, ok := g.types[t.Name]
+		return ok
+	}
+	return false
+}
+
+// uintSize returns the size in bytes of an unsigned integer type, or 0 if
+// expr is not one.
+func (g *generator) uintSize(expr ast.Expr) int {
+	if id, ok := g.resolve(expr).(*ast.Ident); ok {
+		return uintSizes[id.Name]
+	}
+	return 0
+}
+
+func (g *generator) typeString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, g.fset, expr); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// newVar returns a unique variable name.
+func (g *generator) newVar(prefix string) string {
+	g.vars++
+	return fmt.Sprintf("%s%d", prefix, g.vars)
+}
+
+func (g *generator) generate(names []string) ([]byte, error) {
+	g.buf.Reset()
+	g.printf("%spackage %s\n\n", header, g.pkg)
+	g.printf("import (\n\"errors\"\n\"io\"\n)\n\n")
+
+	g.printf("// appendGenerated appends the encoding of v to b. It returns false if v\n")
+	g.printf("// has no generated encoder.\n")
+	g.printf("func appendGenerated(v any, b []byte, order byteOrder) ([]byte, bool) {\n")
+	g.printf("switch v := v.(type) {\n")
+	for _, name := range names {
+		g.printf("case %s:\nreturn v.appendOSCAR(b, order), true\n", name)
+	}
+	g.printf("}\nreturn b, false\n}\n\n")
+
+	g.printf("// decodeGenerated decodes into v. It returns false if v has no generated\n")
+	g.printf("// decoder.\n")
+	g.printf("func decodeGenerated(v any, d *decoder) (bool, error) {\n")
+	g.printf("switch v := v.(type) {\n")
+	for _, name := range names {
+		g.printf("case *%s:\nreturn true, v.decodeOSCAR(d)\n", name)
+	}
+	g.printf("}\nreturn false, nil\n}\n\n")
+
+	for _, name := range names {
+		st := g.types[name].Type.(*ast.StructType)
+		g.vars = 0
+		g.printf("func (v %s) appendOSCAR(b []byte, order byteOrder) []byte {\n", name)
+		g.encodeFields("v", st)
+		g.printf("return b\n}\n\n")
+
+		g.vars = 0
+		g.printf("func (v *%s) decodeOSCAR(d *decoder) error {\n", name)
+		g.decodeFields("v", "d", st)
+		g.printf("return nil\n}\n\n")
+	}
+
+	src := g.buf.Bytes()
+	if !g.usesEOF {
+		src = bytes.Replace(src, []byte("import (\n\"errors\"\n\"io\"\n)\n\n"), nil, 1)
+	}
+	return format.Source(src)
+}
+
+func (g *generator) generateTypeList(names []string) ([]byte, error) {
+	g.buf.Reset()
+	g.printf("%spackage %s\n\n", header, g.pkg)
+	g.printf("// generatedTypes lists a value of each type that has a generated encoder\n")
+	g.printf("// and decoder.\n")
+	g.printf("var generatedTypes = []any{\n")
+	for _, name := range names {
+		g.printf("%s{},\n", name)
+	}
+	g.printf("}\n")
+	return format.Source(g.buf.Bytes())
+}
+
+// fieldName returns the name used to access a struct field, which for
+// embedded fields is the type name.
+func fieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+	expr := field.Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	return expr.(*ast.Ident).Name
+}
+
+func (g *generator) encodeFields(v string, st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		tag, _ := parseTag(field.Tag)
+		g.encode(v+"."+fieldName(field), field.Type, tag)
+	}
+}
+
+// encode emits statements that append the encoding of v, whose type is expr,
+// to b.
+func (g *generator) encode(v string, expr ast.Expr, tag oscarTag) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		g.printf("if %s != nil {\n", v)
+		tag.optional = false
+		g.encode(v, star.X, tag)
+		g.printf("}\n")
+		return
+	}
+
+	if size := g.uintSize(expr); size > 0 {
+		switch size {
+		case 1:
+			g.printf("b = append(b, uint8(%s))\n", v)
+		default:
+			g.printf("b = order.AppendUint%d(b, uint%d(%s))\n", size*8, size*8, v)
+		}
+		return
+	}
+
+	switch t := g.resolve(expr).(type) {
+	case *ast.Ident:
+		if t.Name == "string" {
+			g.printf("b = appendString(b, order, string(%s), %d, %t)\n", v, tag.lenPrefix, tag.nullTerm)
+			return
+		}
+		g.withLenPrefix(tag.lenPrefix, func() {
+			g.printf("b = %s.appendOSCAR(b, order)\n", v)
+		})
+	case *ast.StructType:
+		g.withLenPrefix(tag.lenPrefix, func() {
+			g.encodeFields(v, t)
+		})
+	case *ast.ArrayType:
+		if t.Len == nil && tag.countPrefix > 0 {
+			g.printf("b = appendLen(b, order, %d, len(%s))\n", tag.countPrefix, v)
+		}
+		g.withLenPrefix(tag.lenPrefix, func() {
+			if g.uintSize(t.Elt) == 1 {
+				if t.Len != nil {
+					g.printf("b = append(b, %s[:]...)\n", v)
+				} else {
+					g.printf("b = append(b, %s...)\n", v)
+				}
+				return
+			}
+			i := g.newVar("i")
+			g.printf("for %s := range %s {\n", i, v)
+			g.encode(fmt.Sprintf("%s[%s]", v, i), t.Elt, oscarTag{})
+			g.printf("}\n")
+		})
+	default:
+		panic(fmt.Sprintf("unsupported type %s", g.typeString(expr)))
+	}
+}
+
+// withLenPrefix emits a placeholder length prefix before the statements
+// emitted by body and sets it afterward.
+func (g *generator) withLenPrefix(size int, body func()) {
+	if size == 0 {
+		body()
+		return
+	}
+	at := g.newVar("at")
+	g.printf("%s := len(b)\n", at)
+	g.printf("b = append(b, make([]byte, %d)...)\n", size)
+	body()
+	g.printf("putLen(b, order, %d, %s)\n", size, at)
+}
+
+func (g *generator) decodeFields(v string, d string, st *ast.StructType) {
+	for _, field := range st.Fields.List {
+		tag, _ := parseTag(field.Tag)
+		g.decode(v+"."+fieldName(field), d, field.Type, tag)
+	}
+}
+
+// decode emits statements that decode v, whose type is expr, from the
+// decoder d, returning on error.
+func (g *generator) decode(v string, d string, expr ast.Expr, tag oscarTag) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		// an optional struct is absent if the input ends before it does
+		g.usesEOF = true
+		p := g.newVar("p")
+		err := g.newVar("err")
+		g.printf("%s := newOf(%s)\n", p, v)
+		g.printf("%s := func() error {\n", err)
+		tag.optional = false
+		g.decode("(*"+p+")", d, star.X, tag)
+		g.printf("return nil\n}()\n")
+		g.printf("switch {\ncase errors.Is(%s, io.EOF):\n%s = nil\n", err, v)
+		g.printf("case %s != nil:\nreturn %s\ndefault:\n%s = %s\n}\n", err, err, v, p)
+		return
+	}
+
+	if size := g.uintSize(expr); size > 0 {
+		g.printf("if err := decodeUint%d(%s, &%s); err != nil {\nreturn err\n}\n", size*8, d, v)
+		return
+	}
+
+	switch t := g.resolve(expr).(type) {
+	case *ast.Ident:
+		if t.Name == "string" {
+			g.printf("if err := decodeString(%s, &%s, %d, %t); err != nil {\nreturn err\n}\n", d, v, tag.lenPrefix, tag.nullTerm)
+			return
+		}
+		g.withBlock(d, tag.lenPrefix, func(d string) {
+			g.printf("if err := %s.decodeOSCAR(%s); err != nil {\nreturn err\n}\n", v, d)
+		})
+	case *ast.StructType:
+		g.withBlock(d, tag.lenPrefix, func(d string) {
+			g.decodeFields(v, d, t)
+		})
+	case *ast.ArrayType:
+		if t.Len != nil {
+			g.decodeArray(v, d, t)
+			return
+		}
+		g.decodeSlice(v, d, expr, t, tag)
+	default:
+		panic(fmt.Sprintf("unsupported type %s", g.typeString(expr)))
+	}
+}
+
+// withBlock emits statements that read a length-prefixed block and decode
+// its contents with the statements emitted by body.
+func (g *generator) withBlock(d string, size int, body func(d string)) {
+	if size == 0 {
+		body(d)
+		return
+	}
+	sub := g.newVar("d")
+	g.printf("%s, err := %s.readBlock(%d)\nif err != nil {\nreturn err\n}\n", sub, d, size)
+	body(sub)
+}
+
+func (g *generator) decodeArray(v string, d string, t *ast.ArrayType) {
+	if g.uintSize(t.Elt) == 1 {
+		g.printf("if err := %s.readElems(%s[:]); err != nil {\nreturn err\n}\n", d, v)
+		return
+	}
+	i := g.newVar("i")
+	g.printf("for %s := range %s {\n", i, v)
+	g.decode(fmt.Sprintf("%s[%s]", v, i), d, t.Elt, oscarTag{})
+	g.printf("}\n")
+}
+
+func (g *generator) decodeSlice(v string, d string, expr ast.Expr, t *ast.ArrayType, tag oscarTag) {
+	isBytes := g.uintSize(t.Elt) == 1
+
+	switch {
+	case tag.lenPrefix > 0 && isBytes:
+		g.printf("{\nb, err := %s.readBytes(%d)\nif err != nil {\nreturn err\n}\n%s = b\n}\n", d, tag.lenPrefix, v)
+	case tag.lenPrefix > 0:
+		g.printf("{\n")
+		g.withBlock(d, tag.lenPrefix, func(sub string) {
+			g.printf("%s = nil\n", v)
+			g.printf("for %s.more() {\n", sub)
+			g.decodeElem(v, sub, t.Elt)
+			g.checkListLen(v, d)
+			g.printf("}\n")
+		})
+		g.printf("}\n")
+	case tag.countPrefix > 0:
+		n := g.newVar("n")
+		g.printf("{\n%s, err := %s.readLen(%d)\nif err != nil {\nreturn err\n}\n", n, d, tag.countPrefix)
+		g.printf("if err := %s.checkListLen(%s); err != nil {\nreturn err\n}\n", d, n)
+		g.printf("%s = nil\n", v)
+		if isBytes {
+			g.printf("if %s > 0 {\nb := make(%s, %s)\n", n, g.typeString(expr), n)
+			g.printf("if err := %s.readElems(b); err != nil {\nreturn err\n}\n%s = b\n}\n", d, v)
+		} else {
+			g.printf("for range %s {\n", n)
+			g.decodeElem(v, d, t.Elt)
+			g.printf("}\n")
+		}
+		g.printf("}\n")
+	case isBytes:
+		g.printf("{\nb, err := %s.readRest()\nif err != nil {\nreturn err\n}\n%s = b\n}\n", d, v)
+	default:
+		// read elements until the input runs out
+		g.usesEOF = true
+		g.printf("%s = nil\n", v)
+		g.printf("for {\n")
+		e := g.newVar("e")
+		err := g.newVar("err")
+		g.printf("var %s %s\n", e, g.typeString(t.Elt))
+		g.printf("%s := func() error {\n", err)
+		g.decode(e, d, t.Elt, oscarTag{})
+		g.printf("return nil\n}()\n")
+		g.printf("if errors.Is(%s, io.EOF) {\nbreak\n}\n", err)
+		g.printf("if %s != nil {\nreturn %s\n}\n", err, err)
+		g.printf("%s = append(%s, %s)\n", v, v, e)
+		g.checkListLen(v, d)
+		g.printf("}\n")
+	}
+}
+
+// checkListLen emits statements that check the length of the slice v
+// against the limits of the decoder d.
+func (g *generator) checkListLen(v string, d string) {
+	g.printf("if err := %s.checkListLen(len(%s)); err != nil {\nreturn err\n}\n", d, v)
+}
+
+// decodeElem emits statements that decode one element and append it to the
+// slice v.
+func (g *generator) decodeElem(v string, d string, elem ast.Expr) {
+	e := g.newVar("e")
+	g.printf("var %s %s\n", e, g.typeString(elem))
+	g.decode(e, d, elem, oscarTag{})
+	g.printf("%s = append(%s, %s)\n", v, v, e)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGeneratedCodeIsCurrent makes sure the checked-in generated code matches
+// the wire structs. Stale generated code would silently skip new fields.
+func TestGeneratedCodeIsCurrent(t *testing.T) {
+	dir := filepath.Join("..", "..", "wire")
+
+	g, err := newGenerator(dir, "marshal_gen.go", "marshal_gen_test.go")
+	require.NoError(t, err)
+	names := g.structsIn([]string{"frames.go", "snacs.go", "tlv.go"})
+
+	want, err := g.generate(names)
+	require.NoError(t, err)
+	have, err := os.ReadFile(filepath.Join(dir, "marshal_gen.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(have), "run `make marshalers` to regenerate")
+
+	want, err = g.generateTypeList(names)
+	require.NoError(t, err)
+	have, err = os.ReadFile(filepath.Join(dir, "marshal_gen_test.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(have), "run `make marshalers` to regenerate")
+}
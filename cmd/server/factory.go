@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/mail"
 	"os"
 	"strings"
 	"time"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/mk6i/retro-aim-server/config"
 	"github.com/mk6i/retro-aim-server/foodgroup"
+	"github.com/mk6i/retro-aim-server/mailer"
 	"github.com/mk6i/retro-aim-server/server/http"
 	"github.com/mk6i/retro-aim-server/server/kerberos"
 	"github.com/mk6i/retro-aim-server/server/oscar"
@@ -27,17 +30,30 @@ import (
 
 // Container groups together common dependencies.
 type Container struct {
-	cfg                    config.Config
-	chatSessionManager     *state.InMemoryChatSessionManager
-	hmacCookieBaker        state.HMACCookieBaker
-	icbmSvc                *foodgroup.ICBMService
-	inMemorySessionManager *state.InMemorySessionManager
-	logger                 *slog.Logger
-	rateLimitClasses       wire.RateLimitClasses
-	snacRateLimits         wire.SNACRateLimits
-	sqLiteUserStore        *state.SQLiteUserStore
-	webAPISessionManager   *state.WebAPISessionManager
-	Listeners              []config.Listener
+	apiTLSConfig         *tls.Config
+	authMode             *state.AuthMode
+	cfg                  config.Config
+	chatSessionManager   state.ChatSessionManager
+	clusterRunners       []func(ctx context.Context) error
+	configReloader       *configReloader
+	hmacCookieBaker      state.HMACCookieBaker
+	icbmSvc              *foodgroup.ICBMService
+	imArchiver           foodgroup.IMArchiver
+	keepAlive            *state.KeepAliveMonitor
+	logLevel             *slog.LevelVar
+	logger               *slog.Logger
+	loginLockout         *state.LoginLockoutTracker
+	mailer               mailer.Mailer
+	motd                 *state.MOTD
+	outboundQueue        *state.OutboundQueueMonitor
+	rateLimitService     foodgroup.RateLimitService
+	rateLimits           *state.RateLimits
+	sessionManager       state.SessionManager
+	snacRateLimits       wire.SNACRateLimits
+	sqLiteUserStore      *state.SQLiteUserStore
+	tocListeners         []config.TOCListener
+	webAPISessionManager *state.WebAPISessionManager
+	Listeners            []config.Listener
 }
 
 // MakeCommonDeps creates common dependencies used by the food group services.
@@ -62,6 +78,11 @@ func MakeCommonDeps() (Container, error) {
 		return c, fmt.Errorf("unable to parse listener config: %s", err.Error())
 	}
 
+	c.tocListeners, err = c.cfg.ParseTOCListenersCfg()
+	if err != nil {
+		return c, fmt.Errorf("unable to parse TOC listener config: %s", err.Error())
+	}
+
 	c.sqLiteUserStore, err = state.NewSQLiteUserStore(c.cfg.DBPath)
 	if err != nil {
 		return c, fmt.Errorf("unable to create feedbag store: %s", err.Error())
@@ -72,28 +93,103 @@ func MakeCommonDeps() (Container, error) {
 		return c, fmt.Errorf("unable to create HMAC cookie baker: %s", err.Error())
 	}
 
-	c.logger = oscarmiddleware.NewLogger(c.cfg)
-	c.inMemorySessionManager = state.NewInMemorySessionManager(c.logger)
-	c.chatSessionManager = state.NewInMemoryChatSessionManager(c.logger)
+	c.logLevel = new(slog.LevelVar)
+	c.logLevel.Set(oscarmiddleware.ParseLevel(c.cfg.LogLevel))
+	c.logger = oscarmiddleware.NewLogger(c.logLevel)
+	if c.cfg.ClusterRedisAddress != "" {
+		bus := state.NewRedisMessageBus(c.cfg.ClusterRedisAddress, c.cfg.ClusterRedisPassword, c.logger)
+		sessionManager := state.NewClusterSessionManager(bus, c.logger)
+		chatSessionManager := state.NewClusterChatSessionManager(bus, c.logger)
+		c.sessionManager = sessionManager
+		c.chatSessionManager = chatSessionManager
+		c.clusterRunners = append(c.clusterRunners, sessionManager.Run, chatSessionManager.Run)
+	} else {
+		c.sessionManager = state.NewInMemorySessionManager(c.logger)
+		c.chatSessionManager = state.NewInMemoryChatSessionManager(c.logger)
+		// the relationship cache is only safe when no other instance writes
+		// to the database
+		if err := c.sqLiteUserStore.EnableRelationshipCache(context.Background()); err != nil {
+			return c, fmt.Errorf("unable to load relationship cache: %s", err.Error())
+		}
+	}
+	password, err := c.sqLiteUserStore.BootstrapAPIAdmin(context.Background(), time.Now())
+	if err != nil {
+		return c, fmt.Errorf("unable to create management API account: %s", err.Error())
+	}
+	if password != "" {
+		// print the password straight to stderr so that it never ends up in
+		// log files or log aggregators
+		fmt.Fprintf(os.Stderr, "management API account password for admin: %s\n", password)
+		c.logger.Warn("created management API account, its password was printed to stderr; change it with PATCH /admin/account/admin",
+			"username", "admin")
+	}
 	c.webAPISessionManager = state.NewWebAPISessionManager()
-	c.rateLimitClasses = wire.DefaultRateLimitClasses()
-	c.snacRateLimits = wire.DefaultSNACRateLimits()
+	c.motd = state.NewMOTD(c.cfg.MOTD)
+	c.loginLockout = state.NewLoginLockoutTracker(c.cfg.LoginLockoutThreshold, c.cfg.LoginLockoutWindow, c.cfg.LoginLockoutCooldown)
+	c.keepAlive = state.NewKeepAliveMonitor(c.cfg.KeepAliveInterval, c.cfg.IdleTimeout)
+	c.outboundQueue = state.DefaultOutboundQueueMonitor()
+
+	rateLimitClasses, snacRateLimits, err := c.cfg.RateLimits()
+	if err != nil {
+		return c, fmt.Errorf("unable to load rate limits: %s", err.Error())
+	}
+	c.rateLimits = state.NewRateLimits(rateLimitClasses)
+	c.snacRateLimits = snacRateLimits
+	c.rateLimitService = foodgroup.NewRateLimitService(c.rateLimits, c.sqLiteUserStore, c.sessionManager)
+
+	c.authMode = state.NewAuthMode(c.cfg.DisableAuth, newAuthProvider(c.cfg))
+	c.configReloader = newConfigReloader(cfgFile, envVars, c.cfg, c.Listeners, c.logger, c.logLevel, c.authMode, c.rateLimitService)
+	switch c.cfg.MailBackend {
+	case config.MailBackendSMTP, config.MailBackendOutbox:
+		from, err := mail.ParseAddress(c.cfg.MailFrom)
+		if err != nil {
+			return c, fmt.Errorf("unable to parse mail from address: %s", err.Error())
+		}
+		if c.cfg.MailBackend == config.MailBackendSMTP {
+			c.mailer = mailer.NewSMTPMailer(c.cfg.SMTPAddress, c.cfg.SMTPUsername, c.cfg.SMTPPassword, from)
+		} else {
+			c.mailer = mailer.NewOutboxMailer(c.cfg.MailOutboxDir, from)
+		}
+	}
+	if c.cfg.APITLS {
+		// the management API isn't used by AIM clients, so it never needs
+		// the legacy ciphers
+		c.apiTLSConfig, err = state.NewTLSConfig(c.cfg.TLSCertFile, c.cfg.TLSKeyFile, false)
+		if err != nil {
+			return c, fmt.Errorf("unable to load management API certificate: %s", err.Error())
+		}
+	}
+
+	if c.cfg.IMArchiveEnabled {
+		c.imArchiver = c.sqLiteUserStore
+	}
 
 	// ICBM svc is a common dep because OSCAR and TOC need to share convo history state.
 	c.icbmSvc = foodgroup.NewICBMService(
 		c.sqLiteUserStore,
-		c.inMemorySessionManager,
+		c.imArchiver,
+		c.sessionManager,
 		c.sqLiteUserStore,
 		c.sqLiteUserStore,
-		c.inMemorySessionManager,
+		c.sessionManager,
 		c.sqLiteUserStore,
 		c.snacRateLimits,
+		c.cfg.OfflineMsgMaxPerRecipient,
 		c.logger,
 	)
 
 	return c, nil
 }
 
+// newAuthProvider returns the external provider that checks passwords, or
+// nil if passwords are checked against the local user database.
+func newAuthProvider(cfg config.Config) state.AuthProvider {
+	if cfg.AuthProvider == config.AuthProviderLDAP {
+		return state.NewLDAPAuthProvider(cfg.LDAPURL, cfg.LDAPBindDNTemplate)
+	}
+	return nil
+}
+
 func validateConfigMigration() error {
 	// Old environment variables that should be removed
 	oldEnvVars := []string{
@@ -220,75 +316,81 @@ func OSCAR(deps Container) *oscar.Server {
 		deps.sqLiteUserStore,
 		deps.sqLiteUserStore,
 		deps.sqLiteUserStore,
-		deps.inMemorySessionManager,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
+		deps.sessionManager,
+		deps.mailer,
+		deps.cfg.MailLinkBaseURL,
 		deps.logger,
 	)
 	authService := foodgroup.NewAuthService(
 		deps.cfg,
-		deps.inMemorySessionManager,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
+		deps.sessionManager,
 		deps.chatSessionManager,
 		deps.sqLiteUserStore,
 		deps.hmacCookieBaker,
 		deps.chatSessionManager,
 		deps.sqLiteUserStore,
-		deps.rateLimitClasses,
+		deps.rateLimits,
+		deps.loginLockout,
+		deps.authMode,
 	)
 	bartService := foodgroup.NewBARTService(
 		logger,
 		deps.sqLiteUserStore,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 		deps.sqLiteUserStore,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 	)
 	buddyService := foodgroup.NewBuddyService(
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 		deps.sqLiteUserStore,
 		deps.sqLiteUserStore,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 		deps.sqLiteUserStore,
 	)
 	chatService := foodgroup.NewChatService(deps.chatSessionManager)
 	chatNavService := foodgroup.NewChatNavService(logger, deps.sqLiteUserStore)
 	feedbagService := foodgroup.NewFeedbagService(
 		logger,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 		deps.sqLiteUserStore,
 		deps.sqLiteUserStore,
 		deps.sqLiteUserStore,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 	)
 	permitDenyService := foodgroup.NewPermitDenyService(
 		deps.sqLiteUserStore,
 		deps.sqLiteUserStore,
 		deps.sqLiteUserStore,
-		deps.inMemorySessionManager,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
+		deps.sessionManager,
 	)
-	icqService := foodgroup.NewICQService(deps.inMemorySessionManager, deps.sqLiteUserStore, deps.sqLiteUserStore,
-		logger, deps.inMemorySessionManager, deps.sqLiteUserStore)
+	icqService := foodgroup.NewICQService(deps.sessionManager, deps.sqLiteUserStore, deps.sqLiteUserStore,
+		logger, deps.sessionManager, deps.sqLiteUserStore)
 	locateService := foodgroup.NewLocateService(
 		deps.sqLiteUserStore,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 		deps.sqLiteUserStore,
 		deps.sqLiteUserStore,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 	)
 	oServiceService := foodgroup.NewOServiceService(
 		deps.cfg,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 		logger,
 		deps.hmacCookieBaker,
 		deps.sqLiteUserStore,
 		deps.sqLiteUserStore,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 		deps.sqLiteUserStore,
 		deps.snacRateLimits,
 		deps.chatSessionManager,
+		deps.motd,
 	)
 	userLookupService := foodgroup.NewUserLookupService(deps.sqLiteUserStore)
 	statsService := foodgroup.NewStatsService()
+	inviteService := foodgroup.NewInviteService(deps.sqLiteUserStore, deps.mailer, deps.cfg.MailLinkBaseURL, deps.cfg.InviteDailyLimit, logger)
 	oDirService := foodgroup.NewODirService(logger, deps.sqLiteUserStore)
 
 	if err := deps.sqLiteUserStore.ClearBuddyListRegistry(context.Background()); err != nil {
@@ -311,6 +413,7 @@ func OSCAR(deps Container) *oscar.Server {
 			FeedbagService:    feedbagService,
 			ICBMService:       deps.icbmSvc,
 			ICQService:        icqService,
+			InviteService:     inviteService,
 			LocateService:     locateService,
 			ODirService:       oDirService,
 			OServiceService:   oServiceService,
@@ -327,13 +430,14 @@ func OSCAR(deps Container) *oscar.Server {
 		deps.Listeners,
 		deps.icbmSvc.RestoreWarningLevel,
 		deps.icbmSvc.UpdateWarnLevel,
+		deps.keepAlive,
 	)
 }
 
 // KerberosAPI creates an HTTP server for the Kerberos server.
 func KerberosAPI(deps Container) *kerberos.Server {
 	logger := deps.logger.With("svc", "Kerberos")
-	authService := foodgroup.NewAuthService(deps.cfg, deps.inMemorySessionManager, deps.inMemorySessionManager, deps.chatSessionManager, deps.sqLiteUserStore, deps.hmacCookieBaker, deps.chatSessionManager, deps.sqLiteUserStore, deps.rateLimitClasses)
+	authService := foodgroup.NewAuthService(deps.cfg, deps.sessionManager, deps.sessionManager, deps.chatSessionManager, deps.sqLiteUserStore, deps.hmacCookieBaker, deps.chatSessionManager, deps.sqLiteUserStore, deps.rateLimits, deps.loginLockout, deps.authMode)
 	return kerberos.NewKerberosServer(deps.Listeners, logger, authService)
 }
 
@@ -345,22 +449,42 @@ func MgmtAPI(deps Container) *http.Server {
 		Date:    date,
 	}
 	logger := deps.logger.With("svc", "API")
+	popupService := foodgroup.NewPopupService(deps.sessionManager)
+	motdService := foodgroup.NewMOTDService(deps.motd, deps.sessionManager)
 	return http.NewManagementAPI(
 		bld,
 		deps.cfg.APIListener,
-		deps.sqLiteUserStore,        // userManager
-		deps.inMemorySessionManager, // sessionRetriever
-		deps.sqLiteUserStore,        // chatRoomRetriever
-		deps.sqLiteUserStore,        // chatRoomCreator
-		deps.sqLiteUserStore,        // chatRoomDeleter
-		deps.chatSessionManager,     // chatSessionRetriever
-		deps.sqLiteUserStore,        // directoryManager
-		deps.inMemorySessionManager, // messageRelayer
-		deps.sqLiteUserStore,        // bartAssetManager
-		deps.sqLiteUserStore,        // feedbagRetriever
-		deps.sqLiteUserStore,        // accountManager
-		deps.sqLiteUserStore,        // profileRetriever
-		deps.sqLiteUserStore,        // webAPIKeyManager
+		deps.apiTLSConfig,        // tlsConfig
+		deps.sqLiteUserStore,     // userManager
+		deps.sessionManager,      // sessionRetriever
+		deps.sqLiteUserStore,     // chatRoomRetriever
+		deps.sqLiteUserStore,     // chatRoomCreator
+		deps.sqLiteUserStore,     // chatRoomDeleter
+		deps.chatSessionManager,  // chatSessionRetriever
+		deps.sqLiteUserStore,     // directoryManager
+		deps.sessionManager,      // messageRelayer
+		deps.sqLiteUserStore,     // bartAssetManager
+		deps.sqLiteUserStore,     // feedbagRetriever
+		deps.sqLiteUserStore,     // accountManager
+		deps.sqLiteUserStore,     // profileRetriever
+		deps.sqLiteUserStore,     // webAPIKeyManager
+		deps.loginLockout,        // loginLockoutManager
+		deps.authMode,            // authMode
+		deps.sqLiteUserStore,     // emailTokenManager
+		deps.sqLiteUserStore,     // inviteManager
+		popupService,             // popupService
+		motdService,              // motdService
+		deps.rateLimitService,    // rateLimitService
+		deps.sqLiteUserStore,     // relationshipCacheStats
+		deps.keepAlive,           // keepAliveStats
+		deps.outboundQueue,       // outboundQueueStats
+		deps.configReloader,      // configReloader
+		deps.sqLiteUserStore,     // apiAdminManager
+		deps.sqLiteUserStore,     // auditLog
+		deps.sqLiteUserStore,     // imArchive
+		deps.sqLiteUserStore,     // offlineMessages
+		deps.mailer,              // mailSender
+		deps.cfg.MailLinkBaseURL, // linkBaseURL
 		logger,
 	)
 }
@@ -370,34 +494,38 @@ func TOC(deps Container) *toc.Server {
 	logger := deps.logger.With("svc", "TOC")
 
 	return toc.NewServer(
-		deps.cfg.TOCListeners,
+		deps.tocListeners,
 		logger,
 		toc.OSCARProxy{
 			AdminService: foodgroup.NewAdminService(
 				deps.sqLiteUserStore,
 				deps.sqLiteUserStore,
 				deps.sqLiteUserStore,
-				deps.inMemorySessionManager,
-				deps.inMemorySessionManager,
+				deps.sessionManager,
+				deps.sessionManager,
+				deps.mailer,
+				deps.cfg.MailLinkBaseURL,
 				deps.logger,
 			),
 			AuthService: foodgroup.NewAuthService(
 				deps.cfg,
-				deps.inMemorySessionManager,
-				deps.inMemorySessionManager,
+				deps.sessionManager,
+				deps.sessionManager,
 				deps.chatSessionManager,
 				deps.sqLiteUserStore,
 				deps.hmacCookieBaker,
 				deps.chatSessionManager,
 				deps.sqLiteUserStore,
-				deps.rateLimitClasses,
+				deps.rateLimits,
+				deps.loginLockout,
+				deps.authMode,
 			),
 			BuddyListRegistry: deps.sqLiteUserStore,
 			BuddyService: foodgroup.NewBuddyService(
-				deps.inMemorySessionManager,
+				deps.sessionManager,
 				deps.sqLiteUserStore,
 				deps.sqLiteUserStore,
-				deps.inMemorySessionManager,
+				deps.sessionManager,
 				deps.sqLiteUserStore,
 			),
 			CookieBaker:      deps.hmacCookieBaker,
@@ -405,30 +533,31 @@ func TOC(deps Container) *toc.Server {
 			ICBMService:      deps.icbmSvc,
 			LocateService: foodgroup.NewLocateService(
 				deps.sqLiteUserStore,
-				deps.inMemorySessionManager,
+				deps.sessionManager,
 				deps.sqLiteUserStore,
 				deps.sqLiteUserStore,
-				deps.inMemorySessionManager,
+				deps.sessionManager,
 			),
 			Logger: logger,
 			OServiceService: foodgroup.NewOServiceService(
 				deps.cfg,
-				deps.inMemorySessionManager,
+				deps.sessionManager,
 				logger,
 				deps.hmacCookieBaker,
 				deps.sqLiteUserStore,
 				deps.sqLiteUserStore,
-				deps.inMemorySessionManager,
+				deps.sessionManager,
 				deps.sqLiteUserStore,
 				deps.snacRateLimits,
 				deps.chatSessionManager,
+				deps.motd,
 			),
 			PermitDenyService: foodgroup.NewPermitDenyService(
 				deps.sqLiteUserStore,
 				deps.sqLiteUserStore,
 				deps.sqLiteUserStore,
-				deps.inMemorySessionManager,
-				deps.inMemorySessionManager,
+				deps.sessionManager,
+				deps.sessionManager,
 			),
 			TOCConfigStore:    deps.sqLiteUserStore,
 			ChatService:       foodgroup.NewChatService(deps.chatSessionManager),
@@ -439,6 +568,7 @@ func TOC(deps Container) *toc.Server {
 		toc.NewIPRateLimiter(rate.Every(1*time.Minute), 10, 1*time.Minute),
 		deps.icbmSvc.RestoreWarningLevel,
 		deps.icbmSvc.UpdateWarnLevel,
+		deps.keepAlive,
 	)
 }
 
@@ -454,16 +584,16 @@ func WebAPI(deps Container) *webapi.Server {
 	// Create WebAPI buddy list manager (local to WebAPI)
 	buddyListManager := handlers.NewBuddyListManager(
 		feedbagAdapter,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 		logger,
 	)
 
 	// Create the OSCAR buddy broadcaster for WebAPI to use
 	oscarBuddyBroadcaster := foodgroup.NewBuddyService(
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 		deps.sqLiteUserStore,
 		deps.sqLiteUserStore,
-		deps.inMemorySessionManager,
+		deps.sessionManager,
 		deps.sqLiteUserStore,
 	)
 
@@ -472,27 +602,31 @@ func WebAPI(deps Container) *webapi.Server {
 			deps.sqLiteUserStore,
 			deps.sqLiteUserStore,
 			deps.sqLiteUserStore,
-			deps.inMemorySessionManager,
-			deps.inMemorySessionManager,
+			deps.sessionManager,
+			deps.sessionManager,
+			deps.mailer,
+			deps.cfg.MailLinkBaseURL,
 			deps.logger,
 		),
 		AuthService: foodgroup.NewAuthService(
 			deps.cfg,
-			deps.inMemorySessionManager,
-			deps.inMemorySessionManager,
+			deps.sessionManager,
+			deps.sessionManager,
 			deps.chatSessionManager,
 			deps.sqLiteUserStore,
 			deps.hmacCookieBaker,
 			deps.chatSessionManager,
 			deps.sqLiteUserStore,
-			deps.rateLimitClasses,
+			deps.rateLimits,
+			deps.loginLockout,
+			deps.authMode,
 		),
 		BuddyListRegistry: deps.sqLiteUserStore,
 		BuddyService: foodgroup.NewBuddyService(
-			deps.inMemorySessionManager,
+			deps.sessionManager,
 			deps.sqLiteUserStore,
 			deps.sqLiteUserStore,
-			deps.inMemorySessionManager,
+			deps.sessionManager,
 			deps.sqLiteUserStore,
 		),
 		CookieBaker:      deps.hmacCookieBaker,
@@ -500,48 +634,54 @@ func WebAPI(deps Container) *webapi.Server {
 		ICBMService:      deps.icbmSvc,
 		LocateService: foodgroup.NewLocateService(
 			deps.sqLiteUserStore,
-			deps.inMemorySessionManager,
+			deps.sessionManager,
 			deps.sqLiteUserStore,
 			deps.sqLiteUserStore,
-			deps.inMemorySessionManager,
+			deps.sessionManager,
 		),
 		Logger: logger,
 		OServiceService: foodgroup.NewOServiceService(
 			deps.cfg,
-			deps.inMemorySessionManager,
+			deps.sessionManager,
 			logger,
 			deps.hmacCookieBaker,
 			deps.sqLiteUserStore,
 			deps.sqLiteUserStore,
-			deps.inMemorySessionManager,
+			deps.sessionManager,
 			deps.sqLiteUserStore,
 			deps.snacRateLimits,
 			deps.chatSessionManager,
+			deps.motd,
 		),
 		PermitDenyService: foodgroup.NewPermitDenyService(
 			deps.sqLiteUserStore,
 			deps.sqLiteUserStore,
 			deps.sqLiteUserStore,
-			deps.inMemorySessionManager,
-			deps.inMemorySessionManager,
+			deps.sessionManager,
+			deps.sessionManager,
 		),
 		TOCConfigStore: deps.sqLiteUserStore,
 		ChatService:    foodgroup.NewChatService(deps.chatSessionManager),
 		ChatNavService: foodgroup.NewChatNavService(logger, deps.sqLiteUserStore),
 		SNACRateLimits: deps.snacRateLimits,
 		// New fields for WebAPI handlers
-		SessionRetriever: deps.inMemorySessionManager,
+		SessionRetriever: deps.sessionManager,
 		FeedbagRetriever: feedbagAdapter,
 		FeedbagManager:   feedbagAdapter,
 		// Phase 2 additions
-		MessageRelayer:        deps.inMemorySessionManager,
+		MessageRelayer:        deps.sessionManager,
 		OfflineMessageManager: deps.sqLiteUserStore,
+		MaxOfflineMessages:    deps.cfg.OfflineMsgMaxPerRecipient,
+		IMArchiver:            deps.imArchiver,
 		BuddyBroadcaster:      oscarBuddyBroadcaster,
 		ProfileManager:        deps.sqLiteUserStore,
 		RelationshipFetcher:   deps.sqLiteUserStore,
 		// Authentication support
-		UserManager: deps.sqLiteUserStore,
-		TokenStore:  deps.sqLiteUserStore.NewWebAPITokenStore(),
+		UserManager:  deps.sqLiteUserStore,
+		TokenStore:   deps.sqLiteUserStore.NewWebAPITokenStore(),
+		LoginLockout: deps.loginLockout,
+		AuthMode:     deps.authMode,
+		MOTDManager:  deps.motd,
 		// Phase 3 additions
 		PreferenceManager: deps.sqLiteUserStore.NewWebPreferenceManager(),
 		PermitDenyManager: deps.sqLiteUserStore.NewWebPermitDenyManager(),
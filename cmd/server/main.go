@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -22,8 +23,16 @@ var (
 	date    = "unknown"
 )
 
+var (
+	// cfgFile is the path of the config file.
+	cfgFile string
+	// envVars are the environment variables set before the config file is
+	// loaded. They take precedence over the config file.
+	envVars = make(map[string]bool)
+)
+
 func init() {
-	cfgFile := flag.String("config", "settings.env", "Path to config file")
+	flag.StringVar(&cfgFile, "config", "settings.env", "Path to config file")
 	showHelp := flag.Bool("help", false, "Display help")
 	showVersion := flag.Bool("version", false, "Display build information")
 
@@ -40,11 +49,16 @@ func init() {
 		os.Exit(0)
 	}
 
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		envVars[key] = true
+	}
+
 	// optionally populate environment variables with config file
-	if err := godotenv.Load(*cfgFile); err != nil {
-		fmt.Printf("Config file (%s) not found, defaulting to env vars for app config...\n", *cfgFile)
+	if err := godotenv.Load(cfgFile); err != nil {
+		fmt.Printf("Config file (%s) not found, defaulting to env vars for app config...\n", cfgFile)
 	} else {
-		fmt.Printf("Successfully loaded config file (%s)\n", *cfgFile)
+		fmt.Printf("Successfully loaded config file (%s)\n", cfgFile)
 	}
 }
 
@@ -60,18 +74,53 @@ func main() {
 
 	g, ctx := errgroup.WithContext(ctx)
 
-	oscar := OSCAR(deps)
-	g.Go(oscar.ListenAndServe)
+	for _, run := range deps.clusterRunners {
+		g.Go(func() error { return run(ctx) })
+	}
 
+	oscar := OSCAR(deps)
 	kerb := KerberosAPI(deps)
+	toc := TOC(deps)
+	deps.configReloader.setServers(oscar, kerb, toc)
+
+	g.Go(oscar.ListenAndServe)
 	g.Go(kerb.ListenAndServe)
 
 	api := MgmtAPI(deps)
 	g.Go(api.ListenAndServe)
 
-	toc := TOC(deps)
 	g.Go(toc.ListenAndServe)
 
+	// delete expired messages from the IM archive, even if archiving has
+	// since been disabled
+	g.Go(func() error {
+		purgeIMArchive(ctx, deps)
+		return nil
+	})
+
+	// delete offline messages that have gone undelivered for too long
+	g.Go(func() error {
+		purgeOfflineMessages(ctx, deps)
+		return nil
+	})
+
+	// reload the configuration on SIGHUP
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	g.Go(func() error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-hup:
+				if err := deps.configReloader.Reload(ctx); err != nil {
+					deps.logger.Error("unable to reload configuration", "err", err.Error())
+				}
+			}
+		}
+	})
+
 	var webAPI *webapi.Server
 	if os.Getenv("ENABLE_WEBAPI") == "1" {
 		webAPI = WebAPI(deps)
@@ -96,3 +145,61 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// imArchivePurgeInterval is how often expired messages are deleted from the
+// IM archive.
+const imArchivePurgeInterval = time.Hour
+
+// purgeIMArchive deletes expired messages from the IM archive at startup and
+// then every imArchivePurgeInterval until ctx is done.
+func purgeIMArchive(ctx context.Context, deps Container) {
+	ticker := time.NewTicker(imArchivePurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := deps.sqLiteUserStore.PurgeIMArchive(ctx, time.Now(), deps.cfg.IMArchiveRetentionDays)
+		switch {
+		case err != nil:
+			deps.logger.Error("unable to purge IM archive", "err", err.Error())
+		case n > 0:
+			deps.logger.Info("purged expired messages from IM archive", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// offlineMessagePurgeInterval is how often expired offline messages are
+// deleted.
+const offlineMessagePurgeInterval = time.Hour
+
+// purgeOfflineMessages deletes offline messages older than
+// OFFLINE_MSG_RETENTION_DAYS at startup and then every
+// offlineMessagePurgeInterval until ctx is done.
+func purgeOfflineMessages(ctx context.Context, deps Container) {
+	ticker := time.NewTicker(offlineMessagePurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if days := deps.cfg.OfflineMsgRetentionDays; days > 0 {
+			sentBefore := time.Now().AddDate(0, 0, -days)
+			n, err := deps.sqLiteUserStore.DeleteExpiredMessages(ctx, sentBefore)
+			switch {
+			case err != nil:
+				deps.logger.Error("unable to purge offline messages", "err", err.Error())
+			case n > 0:
+				deps.logger.Info("purged expired offline messages", "count", n)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/joho/godotenv"
+	"github.com/kelseyhightower/envconfig"
+
+	"github.com/mk6i/retro-aim-server/config"
+	"github.com/mk6i/retro-aim-server/foodgroup"
+	"github.com/mk6i/retro-aim-server/server/kerberos"
+	"github.com/mk6i/retro-aim-server/server/oscar"
+	oscarmiddleware "github.com/mk6i/retro-aim-server/server/oscar/middleware"
+	"github.com/mk6i/retro-aim-server/server/toc"
+	"github.com/mk6i/retro-aim-server/state"
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// configReloader re-reads the configuration and applies the settings that
+// can change while the server runs: the log level, the auth mode, the rate
+// limit classes and the OSCAR, Kerberos and TOC listeners, including their
+// TLS settings. The other settings take effect when the server restarts.
+type configReloader struct {
+	mutex sync.Mutex
+	// cfgFile is the path of the config file.
+	cfgFile string
+	// envVars are the environment variables set before the config file was
+	// loaded. They take precedence over the config file.
+	envVars map[string]bool
+	// fileVars are the variables last loaded from the config file.
+	fileVars map[string]string
+	// cfg is the configuration the server started with.
+	cfg       config.Config
+	listeners []config.Listener
+	logger    *slog.Logger
+	logLevel  *slog.LevelVar
+	authMode  *state.AuthMode
+	oscar     *oscar.Server
+	kerberos  *kerberos.Server
+	toc       *toc.Server
+
+	// rateLimits pushes the rate limit classes in the file at
+	// RATE_LIMITS_FILE to the active sessions.
+	rateLimits foodgroup.RateLimitService
+}
+
+// newConfigReloader creates a new instance of configReloader for a server
+// started with cfg.
+func newConfigReloader(
+	cfgFile string,
+	envVars map[string]bool,
+	cfg config.Config,
+	listeners []config.Listener,
+	logger *slog.Logger,
+	logLevel *slog.LevelVar,
+	authMode *state.AuthMode,
+	rateLimits foodgroup.RateLimitService,
+) *configReloader {
+	fileVars, _ := godotenv.Read(cfgFile)
+	return &configReloader{
+		cfgFile:    cfgFile,
+		envVars:    envVars,
+		fileVars:   fileVars,
+		cfg:        cfg,
+		listeners:  listeners,
+		logger:     logger,
+		logLevel:   logLevel,
+		authMode:   authMode,
+		rateLimits: rateLimits,
+	}
+}
+
+// setServers sets the servers whose listeners are updated on reload.
+func (r *configReloader) setServers(oscar *oscar.Server, kerberos *kerberos.Server, toc *toc.Server) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.oscar = oscar
+	r.kerberos = kerberos
+	r.toc = toc
+}
+
+// Reload re-reads the config file and environment and applies the new
+// configuration. It returns an error wrapping config.ErrInvalidConfig if
+// the configuration is invalid, in which case nothing is applied.
+func (r *configReloader) Reload(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fileVars, err := godotenv.Read(r.cfgFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: unable to read config file: %s", config.ErrInvalidConfig, err.Error())
+	}
+
+	r.setFileVars(r.fileVars, fileVars)
+	cfg, listeners, err := loadConfig()
+	var rateLimitClasses wire.RateLimitClasses
+	if err == nil {
+		rateLimitClasses, _, err = cfg.RateLimits()
+		if err != nil {
+			err = fmt.Errorf("%w: %s", config.ErrInvalidConfig, err.Error())
+		}
+	}
+	if err == nil {
+		err = r.setListeners(cfg, listeners)
+	}
+	if err != nil {
+		r.setFileVars(fileVars, r.fileVars)
+		return err
+	}
+	r.fileVars = fileVars
+	r.listeners = listeners
+
+	r.logLevel.Set(oscarmiddleware.ParseLevel(cfg.LogLevel))
+	r.authMode.Set(cfg.DisableAuth, newAuthProvider(cfg))
+	if err := r.rateLimits.SetClasses(ctx, rateLimitClasses); err != nil {
+		r.logger.ErrorContext(ctx, "unable to update the rate limits of active sessions", "err", err.Error())
+	}
+
+	if names := r.cfg.RestartRequired(cfg); len(names) > 0 {
+		r.logger.WarnContext(ctx, "some settings take effect only after a restart", "settings", names)
+	}
+	r.logger.InfoContext(ctx, "configuration reloaded")
+
+	return nil
+}
+
+// setFileVars replaces the environment variables loaded from the config
+// file. Variables set in the environment are left alone.
+func (r *configReloader) setFileVars(prev, next map[string]string) {
+	for key := range prev {
+		if _, ok := next[key]; !ok && !r.envVars[key] {
+			_ = os.Unsetenv(key)
+		}
+	}
+	for key, val := range next {
+		if !r.envVars[key] {
+			_ = os.Setenv(key, val)
+		}
+	}
+}
+
+// setListeners updates the listeners of each server. If a server fails to
+// update its listeners, the servers already updated are reverted.
+func (r *configReloader) setListeners(cfg config.Config, listeners []config.Listener) error {
+	tocListeners, err := cfg.ParseTOCListenersCfg()
+	if err != nil {
+		return fmt.Errorf("%w: %s", config.ErrInvalidConfig, err.Error())
+	}
+	if err := r.oscar.SetListeners(listeners); err != nil {
+		return fmt.Errorf("unable to update OSCAR listeners: %w", err)
+	}
+	if err := r.kerberos.SetListeners(listeners); err != nil {
+		_ = r.oscar.SetListeners(r.listeners)
+		return fmt.Errorf("unable to update Kerberos listeners: %w", err)
+	}
+	if err := r.toc.SetListeners(tocListeners); err != nil {
+		_ = r.oscar.SetListeners(r.listeners)
+		_ = r.kerberos.SetListeners(r.listeners)
+		return fmt.Errorf("unable to update TOC listeners: %w", err)
+	}
+	return nil
+}
+
+// loadConfig reads and validates the configuration from the environment.
+func loadConfig() (config.Config, []config.Listener, error) {
+	var cfg config.Config
+	if err := envconfig.Process("", &cfg); err != nil {
+		return cfg, nil, fmt.Errorf("%w: %s", config.ErrInvalidConfig, err.Error())
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, nil, fmt.Errorf("%w: %s", config.ErrInvalidConfig, err.Error())
+	}
+	listeners, err := cfg.ParseListenersCfg()
+	if err != nil {
+		return cfg, nil, fmt.Errorf("%w: %s", config.ErrInvalidConfig, err.Error())
+	}
+	return cfg, listeners, nil
+}
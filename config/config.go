@@ -4,8 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/mail"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/mk6i/retro-aim-server/wire"
 )
 
 var (
@@ -25,6 +30,31 @@ func (e uriFormatError) Error() string {
 	return fmt.Sprintf("invalid listener URI %q: %v. Valid format: SCHEME://HOST:PORT (e.g., LOCAL://0.0.0.0:5190)", e.URI, e.Err)
 }
 
+// AuthProviderLDAP is the AUTH_PROVIDER value that authenticates users
+// against a directory server.
+const AuthProviderLDAP = "ldap"
+
+const (
+	// MailBackendSMTP is the MAIL_BACKEND value that delivers email via an
+	// SMTP server.
+	MailBackendSMTP = "smtp"
+	// MailBackendOutbox is the MAIL_BACKEND value that writes email to a
+	// directory.
+	MailBackendOutbox = "outbox"
+)
+
+const (
+	// OverflowPolicyDropPresence is the OUTBOUND_QUEUE_OVERFLOW_POLICY value
+	// that discards buddy presence notifications to make room.
+	OverflowPolicyDropPresence = "drop-presence"
+	// OverflowPolicyDropNewest is the OUTBOUND_QUEUE_OVERFLOW_POLICY value
+	// that discards the message that didn't fit.
+	OverflowPolicyDropNewest = "drop-newest"
+	// OverflowPolicyDisconnect is the OUTBOUND_QUEUE_OVERFLOW_POLICY value
+	// that disconnects the client.
+	OverflowPolicyDisconnect = "disconnect"
+)
+
 type Build struct {
 	Version string `json:"version"`
 	Commit  string `json:"commit"`
@@ -37,20 +67,106 @@ type Listener struct {
 	BOSAdvertisedHostSSL   string
 	KerberosListenAddress  string
 	HasSSL                 bool
+	// BOSListenAddressTLS and KerberosListenAddressTLS accept connections
+	// over TLS, if set.
+	BOSListenAddressTLS      string
+	KerberosListenAddressTLS string
+	// TLS configures the TLS listen addresses.
+	TLS TLS
+	// DecodeLimits bounds the messages decoded from the listener's clients.
+	DecodeLimits wire.DecodeLimits
+}
+
+// TOCListener is a network listener for the TOC protocol service.
+type TOCListener struct {
+	ListenAddress string
+	// HasTLS indicates whether the listener accepts connections over TLS.
+	HasTLS bool
+	// TLS configures the listener if HasTLS is set.
+	TLS TLS
+	// DecodeLimits bounds the messages decoded from the listener's TOC/FLAP
+	// clients.
+	DecodeLimits wire.DecodeLimits
+}
+
+// TLS configures a listener that accepts connections over TLS.
+type TLS struct {
+	// CertFile and KeyFile are the paths of the PEM-encoded certificate
+	// chain and private key.
+	CertFile string
+	KeyFile  string
+	// LegacyCiphers allows the older protocol versions and cipher suites
+	// that AIM 6 clients need.
+	LegacyCiphers bool
 }
 
 //go:generate go run ../cmd/config_generator unix settings.env ssl
 type Config struct {
-	BOSListeners            []string `envconfig:"OSCAR_LISTENERS" required:"true" basic:"LOCAL://0.0.0.0:5190" ssl:"LOCAL://0.0.0.0:5190" description:"Network listeners for core OSCAR services. For multi-homed servers, allows users to connect from multiple networks. For example, you can allow both LAN and Internet clients to connect to the same server using different connection settings.\n\nFormat:\n\t- Comma-separated list of [NAME]://[HOSTNAME]:[PORT]\n\t- Listener names and ports must be unique\n\t- Listener names are user-defined\n\t- Each listener needs a listener in OSCAR_ADVERTISED_LISTENERS_PLAIN\n\nExamples:\n\t// Listen on all interfaces\n\tLAN://0.0.0.0:5190\n\t// Separate Internet and LAN config\n\tWAN://142.250.176.206:5190,LAN://192.168.1.10:5191"`
-	BOSAdvertisedHostsPlain []string `envconfig:"OSCAR_ADVERTISED_LISTENERS_PLAIN" required:"true" basic:"LOCAL://127.0.0.1:5190" ssl:"LOCAL://127.0.0.1:5190" description:"Hostnames published by the server that clients connect to for accessing various OSCAR services. These hostnames are NOT the bind addresses. For multi-homed use servers, allows clients to connect using separate hostnames per network.\n\nFormat:\n\t- Comma-separated list of [NAME]://[HOSTNAME]:[PORT]\n\t- Each listener config must correspond to a config in OSCAR_LISTENERS\n\t- Clients MUST be able to connect to these hostnames\n\nExamples:\n\t// Local LAN config, server behind NAT\n\tLAN://192.168.1.10:5190\n\t// Separate Internet and LAN config\n\tWAN://aim.example.com:5190,LAN://192.168.1.10:5191"`
-	BOSAdvertisedHostsSSL   []string `envconfig:"OSCAR_ADVERTISED_LISTENERS_SSL" required:"false" basic:"" ssl:"LOCAL://ras.dev:5193" description:"Same as OSCAR_ADVERTISED_LISTENERS_PLAIN, except the hostname is for the server that terminates SSL."`
-	KerberosListeners       []string `envconfig:"KERBEROS_LISTENERS" required:"false" basic:"" ssl:"LOCAL://0.0.0.0:1088" description:"Network listeners for Kerberos authentication. See OSCAR_LISTENERS doc for more details.\n\nExamples:\n\t// Listen on all interfaces\n\tLAN://0.0.0.0:1088\n\t// Separate Internet and LAN config\n\tWAN://142.250.176.206:1088,LAN://192.168.1.10:1087"`
-	TOCListeners            []string `envconfig:"TOC_LISTENERS" required:"true" basic:"0.0.0.0:9898" ssl:"0.0.0.0:9898" description:"Network listeners for TOC protocol service.\n\nFormat: Comma-separated list of hostname:port pairs.\n\nExamples:\n\t// All interfaces\n\t0.0.0.0:9898\n\t// Multiple listeners\n\t0.0.0.0:9898,192.168.1.10:9899"`
+	BOSListeners            []string `envconfig:"OSCAR_LISTENERS" required:"true" basic:"LOCAL://0.0.0.0:5190" ssl:"LOCAL://0.0.0.0:5190" description:"Network listeners for core OSCAR services. For multi-homed servers, allows users to connect from multiple networks. For example, you can allow both LAN and Internet clients to connect to the same server using different connection settings.\n\nFormat:\n\t- Comma-separated list of [NAME]://[HOSTNAME]:[PORT]\n\t- Listener names and ports must be unique\n\t- Listener names are user-defined\n\t- Each listener needs a listener in OSCAR_ADVERTISED_LISTENERS_PLAIN\n\nExamples:\n\t// Listen on all interfaces\n\tLAN://0.0.0.0:5190\n\t// Separate Internet and LAN config\n\tWAN://142.250.176.206:5190,LAN://192.168.1.10:5191" reload:"true"`
+	BOSTLSListeners         []string `envconfig:"OSCAR_TLS_LISTENERS" required:"false" basic:"" ssl:"LOCAL://0.0.0.0:5193" reload:"true" description:"Network listeners that accept OSCAR connections over SSL, for AIM 6 clients with SSL enabled. Each listener needs a listener of the same name in OSCAR_LISTENERS, and one in OSCAR_ADVERTISED_LISTENERS_SSL that points at this port. The certificate is set by TLS_CERT_FILE and TLS_KEY_FILE, which can be overridden for a listener by adding '?cert_file=PATH&key_file=PATH' to its entry. Legacy ciphers can be allowed for a listener by adding '?legacy_ciphers=true'. These options also apply to the listener's KERBEROS_TLS_LISTENERS entry.\n\nExamples:\n\t// Listen on all interfaces\n\tLAN://0.0.0.0:5193\n\t// Separate certificate for the Internet listener\n\tWAN://142.250.176.206:5193?cert_file=/etc/ras/wan.pem&key_file=/etc/ras/wan.key,LAN://192.168.1.10:5194"`
+	BOSAdvertisedHostsPlain []string `envconfig:"OSCAR_ADVERTISED_LISTENERS_PLAIN" required:"true" basic:"LOCAL://127.0.0.1:5190" ssl:"LOCAL://127.0.0.1:5190" description:"Hostnames published by the server that clients connect to for accessing various OSCAR services. These hostnames are NOT the bind addresses. For multi-homed use servers, allows clients to connect using separate hostnames per network.\n\nFormat:\n\t- Comma-separated list of [NAME]://[HOSTNAME]:[PORT]\n\t- Each listener config must correspond to a config in OSCAR_LISTENERS\n\t- Clients MUST be able to connect to these hostnames\n\nExamples:\n\t// Local LAN config, server behind NAT\n\tLAN://192.168.1.10:5190\n\t// Separate Internet and LAN config\n\tWAN://aim.example.com:5190,LAN://192.168.1.10:5191" reload:"true"`
+	BOSAdvertisedHostsSSL   []string `envconfig:"OSCAR_ADVERTISED_LISTENERS_SSL" required:"false" basic:"" ssl:"LOCAL://ras.dev:5193" description:"Same as OSCAR_ADVERTISED_LISTENERS_PLAIN, except the hostname is for the server that terminates SSL. Point it at the listener's OSCAR_TLS_LISTENERS port, or at a separate server that terminates SSL." reload:"true"`
+	KerberosListeners       []string `envconfig:"KERBEROS_LISTENERS" required:"false" basic:"" ssl:"LOCAL://0.0.0.0:1088" description:"Network listeners for Kerberos authentication. See OSCAR_LISTENERS doc for more details.\n\nExamples:\n\t// Listen on all interfaces\n\tLAN://0.0.0.0:1088\n\t// Separate Internet and LAN config\n\tWAN://142.250.176.206:1088,LAN://192.168.1.10:1087" reload:"true"`
+	KerberosTLSListeners    []string `envconfig:"KERBEROS_TLS_LISTENERS" required:"false" basic:"" ssl:"LOCAL://0.0.0.0:1443" reload:"true" description:"Network listeners for Kerberos authentication over HTTPS, used by AIM 6 clients with SSL enabled. Uses the certificate of the listener's OSCAR_TLS_LISTENERS entry, or TLS_CERT_FILE and TLS_KEY_FILE. AIM 6 clients connect to port 443, which only root can listen on, so listen on an unprivileged port and forward port 443 to it. See OSCAR_LISTENERS doc for more details.\n\nExamples:\n\t// Listen on all interfaces\n\tLAN://0.0.0.0:1443"`
+	TOCListeners            []string `envconfig:"TOC_LISTENERS" required:"true" basic:"0.0.0.0:9898" ssl:"0.0.0.0:9898" description:"Network listeners for TOC protocol service.\n\nFormat: Comma-separated list of hostname:port pairs. Each pair can be followed by a query string that overrides the DECODE_* limits for that listener: max_frame_size, max_tlv_size, max_list_len and max_depth.\n\nExamples:\n\t// All interfaces\n\t0.0.0.0:9898\n\t// Multiple listeners\n\t0.0.0.0:9898,192.168.1.10:9899\n\t// Smaller frames on a public listener\n\t0.0.0.0:9898?max_frame_size=8192" reload:"true"`
+	TOCTLSListeners         []string `envconfig:"TOC_TLS_LISTENERS" required:"false" basic:"" ssl:"" reload:"true" description:"Network listeners for TOC protocol service over SSL, using the certificate at TLS_CERT_FILE and TLS_KEY_FILE.\n\nFormat: Comma-separated list of hostname:port pairs. Each pair accepts the same decode limit options as TOC_LISTENERS.\n\nExamples:\n\t// All interfaces\n\t0.0.0.0:9899"`
 	APIListener             string   `envconfig:"API_LISTENER" required:"true" basic:"127.0.0.1:8080" ssl:"127.0.0.1:8080" description:"Network listener for management API binds to. Only 1 listener can be specified. (Default 127.0.0.1 restricts to same machine only)."`
+	APITLS                  bool     `envconfig:"API_TLS" required:"false" basic:"false" ssl:"false" description:"Serve the management API over HTTPS, using the certificate at TLS_CERT_FILE and TLS_KEY_FILE."`
+
+	TLSCertFile      string `envconfig:"TLS_CERT_FILE" required:"false" basic:"" ssl:"certs/server.pem" reload:"true" description:"The path of the PEM-encoded certificate chain presented by the SSL listeners. The certificate and key are reloaded when either file changes, so a renewed certificate takes effect without a restart. Can be the same file as TLS_KEY_FILE."`
+	TLSKeyFile       string `envconfig:"TLS_KEY_FILE" required:"false" basic:"" ssl:"certs/server.pem" reload:"true" description:"The path of the PEM-encoded private key of TLS_CERT_FILE."`
+	TLSLegacyCiphers bool   `envconfig:"TLS_LEGACY_CIPHERS" required:"false" basic:"false" ssl:"true" reload:"true" description:"Allow TLS 1.0 and 1.1 and the older cipher suites, such as RC4 and 3DES, that AIM 6 clients need. These are insecure, so only allow them if you serve AIM 6 clients."`
 
 	DBPath      string `envconfig:"DB_PATH" required:"true" basic:"oscar.sqlite" ssl:"oscar.sqlite" description:"The path to the SQLite database file. The file and DB schema are auto-created if they doesn't exist."`
-	DisableAuth bool   `envconfig:"DISABLE_AUTH" required:"true" basic:"true" ssl:"true" description:"Disable password check and auto-create new users at login time. Useful for quickly creating new accounts during development without having to register new users via the management API."`
-	LogLevel    string `envconfig:"LOG_LEVEL" required:"true" basic:"info" ssl:"info" description:"Set logging granularity. Possible values: 'trace', 'debug', 'info', 'warn', 'error'."`
+	DisableAuth bool   `envconfig:"DISABLE_AUTH" required:"true" basic:"true" ssl:"true" description:"Disable password check and auto-create new users at login time. Useful for quickly creating new accounts during development without having to register new users via the management API." reload:"true"`
+	LogLevel    string `envconfig:"LOG_LEVEL" required:"true" basic:"info" ssl:"info" description:"Set logging granularity. Possible values: 'trace', 'debug', 'info', 'warn', 'error'." reload:"true"`
+
+	AuthProvider       string `envconfig:"AUTH_PROVIDER" required:"false" basic:"" ssl:"" description:"Where to check user passwords. Leave empty to check passwords against the local user database. Set to 'ldap' to authenticate users by binding to the directory server at LDAP_URL. Accounts that exist in the directory are created on their first successful login. BUCP clients (AIM 3.5-5.9) only send a password digest that can't be checked against the directory, so they can't log in while a provider is set. Use a client that logs in with AIM 1.x-3.x, TOC, Kerberos or Web AIM auth instead." reload:"true"`
+	LDAPURL            string `envconfig:"LDAP_URL" required:"false" basic:"" ssl:"" description:"The address of the directory server used when AUTH_PROVIDER is 'ldap', e.g. 'ldaps://ldap.example.org:636' or 'ldap://127.0.0.1:389'." reload:"true"`
+	LDAPBindDNTemplate string `envconfig:"LDAP_BIND_DN_TEMPLATE" required:"false" basic:"" ssl:"" description:"The DN used to bind to the directory server as the user when AUTH_PROVIDER is 'ldap'. The %s placeholder is replaced by the screen name in lowercase with spaces removed, e.g. 'uid=%s,ou=people,dc=example,dc=org'." reload:"true"`
+
+	LoginLockoutThreshold int           `envconfig:"LOGIN_LOCKOUT_THRESHOLD" required:"false" basic:"5" ssl:"5" description:"The number of failed login attempts within LOGIN_LOCKOUT_WINDOW after which an account is temporarily locked. Applies to all login methods (BUCP, FLAP, Kerberos, TOC and WebAPI). Locked accounts are rejected with a rate limit error. When running several instances, each instance counts failures on its own, so an account can take up to this many failed attempts per instance. Set to 0 to disable account lockouts."`
+	LoginLockoutWindow    time.Duration `envconfig:"LOGIN_LOCKOUT_WINDOW" required:"false" basic:"15m" ssl:"15m" description:"The time window in which failed login attempts are counted towards LOGIN_LOCKOUT_THRESHOLD. Uses Go duration format, e.g. '30s', '15m', '1h'."`
+	LoginLockoutCooldown  time.Duration `envconfig:"LOGIN_LOCKOUT_COOLDOWN" required:"false" basic:"15m" ssl:"15m" description:"How long an account stays locked after exceeding LOGIN_LOCKOUT_THRESHOLD. Lockouts can be lifted early via the management API. Uses Go duration format, e.g. '30s', '15m', '1h'."`
+
+	RegistrationEnabled bool   `envconfig:"REGISTRATION_ENABLED" required:"false" basic:"false" ssl:"false" description:"Allow anyone to create an account from the client's sign-on window, via the 'Register new screen name' option in AIM or the 'New UIN' wizard in ICQ. When disabled, accounts can only be created via the management API or DISABLE_AUTH."`
+	RegistrationCaptcha bool   `envconfig:"REGISTRATION_CAPTCHA" required:"false" basic:"true" ssl:"true" description:"Require users to type the digits shown in a registration image before an account is created. Only applies when REGISTRATION_ENABLED is true. Disable this for old ICQ clients (pre-2003) that don't request a registration image."`
+	RegistrationUINMin  uint32 `envconfig:"REGISTRATION_UIN_MIN" required:"false" basic:"100000" ssl:"100000" description:"The lowest UIN assigned to ICQ accounts created via registration. New accounts get the UIN following the highest one in use in the REGISTRATION_UIN_MIN-REGISTRATION_UIN_MAX range. Must be at least 10000."`
+	RegistrationUINMax  uint32 `envconfig:"REGISTRATION_UIN_MAX" required:"false" basic:"999999999" ssl:"999999999" description:"The highest UIN assigned to ICQ accounts created via registration. Must be no greater than 2147483646."`
+
+	MailBackend     string `envconfig:"MAIL_BACKEND" required:"false" basic:"" ssl:"" description:"How to deliver account confirmation, password reset and notification emails. Leave empty to disable email, in which case account confirmation requests from the client succeed immediately. Set to 'smtp' to deliver mail via the server at SMTP_ADDRESS, or 'outbox' to write each message to a file in MAIL_OUTBOX_DIR."`
+	MailFrom        string `envconfig:"MAIL_FROM" required:"false" basic:"" ssl:"" description:"The sender address for outgoing email, e.g. 'Retro AIM Server <noreply@aim.example.com>'. Required when MAIL_BACKEND is set."`
+	MailLinkBaseURL string `envconfig:"MAIL_LINK_BASE_URL" required:"false" basic:"" ssl:"" description:"The base URL of the management API as reached by users, used to build confirmation, password reset and invitation links in emails, e.g. 'https://aim.example.com'. Required when MAIL_BACKEND is set."`
+	MailOutboxDir   string `envconfig:"MAIL_OUTBOX_DIR" required:"false" basic:"" ssl:"" description:"The directory where messages are written when MAIL_BACKEND is 'outbox'."`
+	SMTPAddress     string `envconfig:"SMTP_ADDRESS" required:"false" basic:"" ssl:"" description:"The host:port of the SMTP server used when MAIL_BACKEND is 'smtp', e.g. 'smtp.example.com:587'. The connection is upgraded with STARTTLS when the server supports it."`
+	SMTPUsername    string `envconfig:"SMTP_USERNAME" required:"false" basic:"" ssl:"" description:"The username for SMTP authentication. Leave empty if the SMTP server doesn't require authentication."`
+	SMTPPassword    string `envconfig:"SMTP_PASSWORD" required:"false" basic:"" ssl:"" description:"The password for SMTP authentication."`
+
+	InviteDailyLimit int `envconfig:"INVITE_DAILY_LIMIT" required:"false" basic:"5" ssl:"5" description:"The maximum number of 'Invite a friend' emails a user can send in 24 hours. Invitations are only sent when MAIL_BACKEND is set. Set to 0 to disable invitations."`
+
+	KeepAliveInterval time.Duration `envconfig:"KEEPALIVE_INTERVAL" required:"false" basic:"1m" ssl:"1m" description:"How often the OSCAR and TOC servers send a keepalive to each connected client. Keepalives make connections to clients that vanished without disconnecting, such as after a network outage, fail sooner. Uses Go duration format, e.g. '30s', '1m'. Set to 0 or leave unset to disable keepalives."`
+	IdleTimeout       time.Duration `envconfig:"IDLE_TIMEOUT" required:"false" basic:"10m" ssl:"10m" description:"How long an OSCAR or TOC client can go without sending anything, including keepalives, before it is disconnected and its buddies are told it signed off. Clients send keepalives of their own while otherwise inactive, so set this well above how often they do. Uses Go duration format, e.g. '5m', '10m'. Set to 0 or leave unset to keep quiet connections open indefinitely."`
+
+	OutboundQueueSize           int    `envconfig:"OUTBOUND_QUEUE_SIZE" required:"false" basic:"1000" ssl:"1000" description:"The number of messages that can wait to be sent to a client that is slow to receive them, such as one on a dial-up connection. Instant messages and chat messages are sent ahead of buddy arrival and departure notifications, and a waiting notification for a buddy is replaced by a newer one for the same buddy. Defaults to 1000 when unset."`
+	OutboundQueueOverflowPolicy string `envconfig:"OUTBOUND_QUEUE_OVERFLOW_POLICY" required:"false" basic:"drop-presence" ssl:"drop-presence" description:"What happens when a message arrives for a client whose queue is full. 'drop-presence' discards the oldest buddy arrival and departure notifications to make room, and disconnects the client only if the queue is full of other messages. 'drop-newest' discards the new message and never disconnects the client. 'disconnect' disconnects the client. Defaults to 'drop-presence' when unset."`
+
+	ClusterRedisAddress  string `envconfig:"CLUSTER_REDIS_ADDRESS" required:"false" basic:"" ssl:"" description:"The host:port of a Redis-compatible server (Redis, Valkey, KeyDB) used to run several server instances as a cluster, e.g. '10.0.0.5:6379'. Each instance owns the connections of its own clients, while sessions and messages are shared through the server's publish/subscribe channels so that users on different instances can see and message each other. All instances must share the same database. Leave empty to run a single instance."`
+	ClusterRedisPassword string `envconfig:"CLUSTER_REDIS_PASSWORD" required:"false" basic:"" ssl:"" description:"The password for the server at CLUSTER_REDIS_ADDRESS. Leave empty if the server doesn't require authentication."`
+
+	RateLimitsFile string `envconfig:"RATE_LIMITS_FILE" required:"false" basic:"" ssl:"" reload:"true" description:"The path to a JSON file that changes the rate limits applied to client requests. Rate limits are grouped in 5 classes, and each SNAC is assigned to a class. 'classes' replaces the parameters of the listed classes, and 'snacs' assigns SNACs to classes, e.g. {\"classes\": [{\"id\": 3, \"window_size\": 20, \"clear_level\": 5100, \"alert_level\": 5000, \"limit_level\": 4000, \"disconnect_level\": 3000, \"max_level\": 6000}], \"snacs\": [{\"food_group\": 4, \"sub_group\": 6, \"class\": 3}]}. Classes and SNACs that aren't listed keep their defaults. Individual accounts, such as bots, can be given their own classes through the management API. Changes to 'classes' take effect for signed-on clients when the configuration is reloaded; changes to 'snacs' take effect after a restart. Leave empty to use the defaults."`
+
+	DecodeMaxFrameSize int `envconfig:"DECODE_MAX_FRAME_SIZE" required:"false" basic:"32768" ssl:"32768" description:"The largest FLAP frame, in bytes, that the OSCAR and TOC servers accept from a client. Clients that send a larger frame are disconnected. Can be overridden for a listener by adding '?max_frame_size=N' to its OSCAR_LISTENERS, TOC_LISTENERS or TOC_TLS_LISTENERS entry. Set to 0 to disable the limit."`
+	DecodeMaxTLVSize   int `envconfig:"DECODE_MAX_TLV_SIZE" required:"false" basic:"16384" ssl:"16384" description:"The largest TLV, string or other length-prefixed field, in bytes, that the OSCAR server accepts in a client message. Can be overridden for an OSCAR listener by adding '?max_tlv_size=N' to its OSCAR_LISTENERS entry. Set to 0 to disable the limit."`
+	DecodeMaxListLen   int `envconfig:"DECODE_MAX_LIST_LEN" required:"false" basic:"2048" ssl:"2048" description:"The largest number of elements, such as TLVs or feedbag items, that the OSCAR server accepts in a list in a client message. Can be overridden for an OSCAR listener by adding '?max_list_len=N' to its OSCAR_LISTENERS entry. Set to 0 to disable the limit."`
+	DecodeMaxDepth     int `envconfig:"DECODE_MAX_DEPTH" required:"false" basic:"8" ssl:"8" description:"How deeply length-prefixed blocks can be nested in a client message accepted by the OSCAR server. Can be overridden for an OSCAR listener by adding '?max_depth=N' to its OSCAR_LISTENERS entry. Set to 0 to disable the limit."`
+
+	MOTD string `envconfig:"MOTD" required:"false" basic:"" ssl:"" description:"The message of the day shown to users when they sign on. AIM clients display it in a system message window, while TOC and Web AIM clients receive it as an instant message from 'MOTD'. It can be changed at runtime through the management API. Leave empty to disable."`
+
+	IMArchiveEnabled       bool `envconfig:"IM_ARCHIVE_ENABLED" required:"false" basic:"false" ssl:"false" description:"Record the instant messages exchanged between users, including those sent from TOC and Web AIM clients, in the server's database. Archived messages can be searched through the management API. Users can opt out of archiving through the management API, in which case their conversations aren't recorded."`
+	IMArchiveRetentionDays int  `envconfig:"IM_ARCHIVE_RETENTION_DAYS" required:"false" basic:"90" ssl:"90" description:"How many days archived instant messages are kept before they are deleted. Individual accounts can be given their own retention period through the management API. When a conversation's participants have different retention periods, the shorter one applies. Set to 0 to keep messages indefinitely."`
+
+	OfflineMsgMaxPerRecipient int `envconfig:"OFFLINE_MSG_MAX_PER_RECIPIENT" required:"false" basic:"100" ssl:"100" description:"The maximum number of offline messages queued for a user. Messages sent to a user whose queue is full are rejected until the user signs on and receives them. Set to 0 to disable the limit."`
+	OfflineMsgRetentionDays   int `envconfig:"OFFLINE_MSG_RETENTION_DAYS" required:"false" basic:"30" ssl:"30" description:"How many days offline messages are kept before they are deleted undelivered. Set to 0 to keep them until they are delivered."`
 }
 
 func (c *Config) ParseListenersCfg() ([]Listener, error) {
@@ -96,6 +212,33 @@ func (c *Config) ParseListenersCfg() ([]Listener, error) {
 			return nil, errDuplicateListener
 		}
 		m[u.Scheme].BOSListenAddress = net.JoinHostPort(u.Hostname(), u.Port())
+		m[u.Scheme].DecodeLimits, err = c.listenerDecodeLimits(u.Query())
+		if err != nil {
+			return nil, uriFormatError{URI: uriStr, Err: err}
+		}
+	}
+
+	// Parse TLS BOS listeners
+	for _, uriStr := range c.BOSTLSListeners {
+		u, err := parseURI(uriStr)
+		if err != nil {
+			return nil, err
+		}
+		if u == nil {
+			continue
+		}
+
+		if _, ok := m[u.Scheme]; !ok {
+			m[u.Scheme] = &Listener{}
+		}
+		if m[u.Scheme].BOSListenAddressTLS != "" {
+			return nil, errDuplicateListener
+		}
+		m[u.Scheme].BOSListenAddressTLS = net.JoinHostPort(u.Hostname(), u.Port())
+		m[u.Scheme].TLS, err = c.listenerTLS(u.Query())
+		if err != nil {
+			return nil, uriFormatError{URI: uriStr, Err: err}
+		}
 	}
 
 	// Parse plaintext BOS advertised listeners
@@ -156,14 +299,42 @@ func (c *Config) ParseListenersCfg() ([]Listener, error) {
 		m[u.Scheme].KerberosListenAddress = net.JoinHostPort(u.Hostname(), u.Port())
 	}
 
+	// Parse TLS Kerberos listeners
+	for _, uriStr := range c.KerberosTLSListeners {
+		u, err := parseURI(uriStr)
+		if err != nil {
+			return nil, err
+		}
+		if u == nil {
+			continue
+		}
+
+		if _, ok := m[u.Scheme]; !ok {
+			m[u.Scheme] = &Listener{}
+		}
+		if m[u.Scheme].KerberosListenAddressTLS != "" {
+			return nil, errDuplicateListener
+		}
+		m[u.Scheme].KerberosListenAddressTLS = net.JoinHostPort(u.Hostname(), u.Port())
+	}
+
 	ret := make([]Listener, 0, len(m))
 
 	for k, v := range m {
+		if v.KerberosListenAddressTLS != "" && v.BOSListenAddressTLS == "" {
+			// no OSCAR_TLS_LISTENERS entry to take options from
+			v.TLS, _ = c.listenerTLS(nil)
+		}
+		hasTLS := v.BOSListenAddressTLS != "" || v.KerberosListenAddressTLS != ""
 		switch {
 		case v.BOSAdvertisedHostPlain == "":
 			return nil, fmt.Errorf("missing BOS advertise address for listener `%s://`", k)
 		case v.BOSListenAddress == "":
 			return nil, fmt.Errorf("missing BOS listen address for listener `%s://`", k)
+		case v.BOSListenAddressTLS != "" && v.BOSAdvertisedHostSSL == "":
+			return nil, fmt.Errorf("missing SSL advertise address for listener `%s://`", k)
+		case hasTLS && (v.TLS.CertFile == "" || v.TLS.KeyFile == ""):
+			return nil, fmt.Errorf("missing TLS certificate for listener `%s://`: set TLS_CERT_FILE and TLS_KEY_FILE or the cert_file and key_file options", k)
 		}
 		ret = append(ret, *v)
 	}
@@ -175,6 +346,127 @@ func (c *Config) ParseListenersCfg() ([]Listener, error) {
 	return ret, nil
 }
 
+// ParseTOCListenersCfg returns the TOC listeners set by TOC_LISTENERS and
+// TOC_TLS_LISTENERS.
+func (c *Config) ParseTOCListenersCfg() ([]TOCListener, error) {
+	var listeners []TOCListener
+	for _, entry := range c.TOCListeners {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		addr, limits, err := c.parseTOCListener(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TOC listener %q: %w", entry, err)
+		}
+		listeners = append(listeners, TOCListener{
+			ListenAddress: addr,
+			DecodeLimits:  limits,
+		})
+	}
+	for _, entry := range c.TOCTLSListeners {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		addr, limits, err := c.parseTOCListener(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TOC TLS listener %q: %w", entry, err)
+		}
+		listeners = append(listeners, TOCListener{
+			ListenAddress: addr,
+			HasTLS:        true,
+			TLS: TLS{
+				CertFile:      c.TLSCertFile,
+				KeyFile:       c.TLSKeyFile,
+				LegacyCiphers: c.TLSLegacyCiphers,
+			},
+			DecodeLimits: limits,
+		})
+	}
+	return listeners, nil
+}
+
+// parseTOCListener splits a TOC listener entry into its listen address and
+// the decode limits set in its optional query string, e.g.
+// 0.0.0.0:9898?max_frame_size=8192.
+func (c *Config) parseTOCListener(entry string) (string, wire.DecodeLimits, error) {
+	addr, rawQuery, _ := strings.Cut(entry, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", wire.DecodeLimits{}, fmt.Errorf("invalid listener options: %w", err)
+	}
+	limits, err := c.listenerDecodeLimits(query)
+	if err != nil {
+		return "", wire.DecodeLimits{}, err
+	}
+	return addr, limits, nil
+}
+
+// listenerTLS returns the TLS settings for a listener, applying the
+// overrides set in the query string of its URI.
+func (c *Config) listenerTLS(query url.Values) (TLS, error) {
+	settings := TLS{
+		CertFile:      c.TLSCertFile,
+		KeyFile:       c.TLSKeyFile,
+		LegacyCiphers: c.TLSLegacyCiphers,
+	}
+	for key, values := range query {
+		value := values[len(values)-1]
+		switch key {
+		case "cert_file":
+			settings.CertFile = value
+		case "key_file":
+			settings.KeyFile = value
+		case "legacy_ciphers":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return TLS{}, fmt.Errorf("invalid legacy_ciphers %q: must be true or false", value)
+			}
+			settings.LegacyCiphers = b
+		default:
+			return TLS{}, fmt.Errorf("unknown listener option %q", key)
+		}
+	}
+	return settings, nil
+}
+
+// DecodeLimits returns the decode limits that apply to listeners that don't
+// override them.
+func (c *Config) DecodeLimits() wire.DecodeLimits {
+	return wire.DecodeLimits{
+		MaxFrameSize: c.DecodeMaxFrameSize,
+		MaxTLVSize:   c.DecodeMaxTLVSize,
+		MaxListLen:   c.DecodeMaxListLen,
+		MaxDepth:     c.DecodeMaxDepth,
+	}
+}
+
+// listenerDecodeLimits returns the decode limits for a listener, applying
+// the overrides set in the query string of its URI.
+func (c *Config) listenerDecodeLimits(query url.Values) (wire.DecodeLimits, error) {
+	limits := c.DecodeLimits()
+	for key, values := range query {
+		var dst *int
+		switch key {
+		case "max_frame_size":
+			dst = &limits.MaxFrameSize
+		case "max_tlv_size":
+			dst = &limits.MaxTLVSize
+		case "max_list_len":
+			dst = &limits.MaxListLen
+		case "max_depth":
+			dst = &limits.MaxDepth
+		default:
+			return wire.DecodeLimits{}, fmt.Errorf("unknown listener option %q", key)
+		}
+		n, err := strconv.Atoi(values[len(values)-1])
+		if err != nil || n < 0 {
+			return wire.DecodeLimits{}, fmt.Errorf("invalid %s %q: must be 0 or greater", key, values[len(values)-1])
+		}
+		*dst = n
+	}
+	return limits, nil
+}
+
 func (c *Config) Validate() error {
 	// Validate TOCListeners (format: hostname:port pairs)
 	for _, listener := range c.TOCListeners {
@@ -183,7 +475,12 @@ func (c *Config) Validate() error {
 			continue
 		}
 
-		host, port, err := net.SplitHostPort(listener)
+		addr, _, err := c.parseTOCListener(listener)
+		if err != nil {
+			return fmt.Errorf("invalid TOC listener %q: %v", listener, err)
+		}
+
+		host, port, err := net.SplitHostPort(addr)
 		if err != nil {
 			return fmt.Errorf("invalid TOC listener %q: %v. Valid format: HOST:PORT (e.g., 0.0.0.0:9898)", listener, err)
 		}
@@ -197,6 +494,32 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate TOCTLSListeners (format: hostname:port pairs)
+	for _, listener := range c.TOCTLSListeners {
+		listener = strings.TrimSpace(listener)
+		if listener == "" {
+			continue
+		}
+
+		addr, _, err := c.parseTOCListener(listener)
+		if err != nil {
+			return fmt.Errorf("invalid TOC TLS listener %q: %v", listener, err)
+		}
+
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return fmt.Errorf("invalid TOC TLS listener %q: %v. Valid format: HOST:PORT (e.g., 0.0.0.0:9899)", listener, err)
+		}
+
+		if host == "" || port == "" {
+			return fmt.Errorf("invalid TOC TLS listener %q: missing host or port. Valid format: HOST:PORT (e.g., 0.0.0.0:9899)", listener)
+		}
+
+		if c.TLSCertFile == "" || c.TLSKeyFile == "" {
+			return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE are required when TOC_TLS_LISTENERS is set")
+		}
+	}
+
 	// Validate APIListener (format: hostname:port pair, no scheme)
 	apiListener := strings.TrimSpace(c.APIListener)
 	if apiListener == "" {
@@ -216,5 +539,111 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid API listener %q: missing port. Valid format: HOST:PORT (e.g., 127.0.0.1:8080)", c.APIListener)
 	}
 
+	if c.APITLS && (c.TLSCertFile == "" || c.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE are required when API_TLS is true")
+	}
+
+	switch c.AuthProvider {
+	case "":
+	case AuthProviderLDAP:
+		if c.LDAPURL == "" {
+			return fmt.Errorf("LDAP_URL is required when AUTH_PROVIDER is %q", AuthProviderLDAP)
+		}
+		if strings.Count(c.LDAPBindDNTemplate, "%s") != 1 {
+			return fmt.Errorf("LDAP_BIND_DN_TEMPLATE must contain exactly one %%s placeholder when AUTH_PROVIDER is %q", AuthProviderLDAP)
+		}
+	default:
+		return fmt.Errorf("invalid auth provider %q. Valid values: '%s' or empty", c.AuthProvider, AuthProviderLDAP)
+	}
+
+	if c.LoginLockoutThreshold < 0 {
+		return fmt.Errorf("invalid login lockout threshold %d: must be 0 or greater", c.LoginLockoutThreshold)
+	}
+	if c.LoginLockoutThreshold > 0 && (c.LoginLockoutWindow <= 0 || c.LoginLockoutCooldown <= 0) {
+		return fmt.Errorf("login lockout window and cooldown must be greater than 0 when login lockout threshold is set")
+	}
+
+	if c.ClusterRedisAddress != "" {
+		if _, _, err := net.SplitHostPort(c.ClusterRedisAddress); err != nil {
+			return fmt.Errorf("invalid CLUSTER_REDIS_ADDRESS %q: %v. Valid format: HOST:PORT (e.g., 10.0.0.5:6379)", c.ClusterRedisAddress, err)
+		}
+	}
+
+	switch c.MailBackend {
+	case "":
+	case MailBackendSMTP, MailBackendOutbox:
+		if _, err := mail.ParseAddress(c.MailFrom); err != nil {
+			return fmt.Errorf("invalid MAIL_FROM address %q: %w", c.MailFrom, err)
+		}
+		if u, err := url.Parse(c.MailLinkBaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid MAIL_LINK_BASE_URL %q. Valid format: SCHEME://HOST[:PORT] (e.g., https://aim.example.com)", c.MailLinkBaseURL)
+		}
+		if c.MailBackend == MailBackendSMTP {
+			if _, _, err := net.SplitHostPort(c.SMTPAddress); err != nil {
+				return fmt.Errorf("invalid SMTP_ADDRESS %q: %v. Valid format: HOST:PORT (e.g., smtp.example.com:587)", c.SMTPAddress, err)
+			}
+		}
+		if c.MailBackend == MailBackendOutbox && c.MailOutboxDir == "" {
+			return fmt.Errorf("MAIL_OUTBOX_DIR is required when MAIL_BACKEND is %q", MailBackendOutbox)
+		}
+	default:
+		return fmt.Errorf("invalid mail backend %q. Valid values: '%s', '%s' or empty", c.MailBackend, MailBackendSMTP, MailBackendOutbox)
+	}
+
+	if c.KeepAliveInterval < 0 {
+		return fmt.Errorf("invalid keepalive interval %s: must be 0 or greater", c.KeepAliveInterval)
+	}
+
+	if c.IdleTimeout < 0 {
+		return fmt.Errorf("invalid idle timeout %s: must be 0 or greater", c.IdleTimeout)
+	}
+
+	if c.OutboundQueueSize < 0 {
+		return fmt.Errorf("invalid outbound queue size %d: must be 0 or greater", c.OutboundQueueSize)
+	}
+
+	switch c.OutboundQueueOverflowPolicy {
+	case "", OverflowPolicyDropPresence, OverflowPolicyDropNewest, OverflowPolicyDisconnect:
+	default:
+		return fmt.Errorf("invalid outbound queue overflow policy %q. Valid values: '%s', '%s', '%s' or empty",
+			c.OutboundQueueOverflowPolicy, OverflowPolicyDropPresence, OverflowPolicyDropNewest, OverflowPolicyDisconnect)
+	}
+
+	for name, n := range map[string]int{
+		"DECODE_MAX_FRAME_SIZE": c.DecodeMaxFrameSize,
+		"DECODE_MAX_TLV_SIZE":   c.DecodeMaxTLVSize,
+		"DECODE_MAX_LIST_LEN":   c.DecodeMaxListLen,
+		"DECODE_MAX_DEPTH":      c.DecodeMaxDepth,
+	} {
+		if n < 0 {
+			return fmt.Errorf("invalid %s %d: must be 0 or greater", name, n)
+		}
+	}
+
+	if c.IMArchiveRetentionDays < 0 {
+		return fmt.Errorf("invalid IM archive retention %d: must be 0 or greater", c.IMArchiveRetentionDays)
+	}
+
+	if c.OfflineMsgMaxPerRecipient < 0 {
+		return fmt.Errorf("invalid offline message limit %d: must be 0 or greater", c.OfflineMsgMaxPerRecipient)
+	}
+
+	if c.OfflineMsgRetentionDays < 0 {
+		return fmt.Errorf("invalid offline message retention %d: must be 0 or greater", c.OfflineMsgRetentionDays)
+	}
+
+	if c.InviteDailyLimit < 0 {
+		return fmt.Errorf("invalid invite daily limit %d: must be 0 or greater", c.InviteDailyLimit)
+	}
+
+	if c.RegistrationEnabled {
+		if c.RegistrationUINMin < 10000 || c.RegistrationUINMax > 2147483646 {
+			return fmt.Errorf("invalid registration UIN range %d-%d: must be within 10000-2147483646", c.RegistrationUINMin, c.RegistrationUINMax)
+		}
+		if c.RegistrationUINMin > c.RegistrationUINMax {
+			return fmt.Errorf("invalid registration UIN range %d-%d: min must not exceed max", c.RegistrationUINMin, c.RegistrationUINMax)
+		}
+	}
+
 	return nil
 }
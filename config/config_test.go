@@ -2,6 +2,9 @@ package config
 
 import (
 	"testing"
+	"time"
+
+	"github.com/mk6i/retro-aim-server/wire"
 )
 
 func TestParseListenersCfg(t *testing.T) {
@@ -432,6 +435,226 @@ func TestParseListenersCfg(t *testing.T) {
 	}
 }
 
+func TestParseListenersCfg_DecodeLimits(t *testing.T) {
+	defaults := wire.DecodeLimits{MaxFrameSize: 32768, MaxTLVSize: 16384, MaxListLen: 2048, MaxDepth: 8}
+
+	tests := []struct {
+		name        string
+		bosListener string
+		want        wire.DecodeLimits
+		errContains string
+	}{
+		{
+			name:        "defaults",
+			bosListener: "LOCAL://0.0.0.0:5190",
+			want:        defaults,
+		},
+		{
+			name:        "overrides",
+			bosListener: "LOCAL://0.0.0.0:5190?max_frame_size=1024&max_tlv_size=512&max_list_len=0&max_depth=4",
+			want:        wire.DecodeLimits{MaxFrameSize: 1024, MaxTLVSize: 512, MaxListLen: 0, MaxDepth: 4},
+		},
+		{
+			name:        "unknown option",
+			bosListener: "LOCAL://0.0.0.0:5190?max_frames=1024",
+			errContains: `unknown listener option "max_frames"`,
+		},
+		{
+			name:        "negative limit",
+			bosListener: "LOCAL://0.0.0.0:5190?max_depth=-1",
+			errContains: `invalid max_depth "-1"`,
+		},
+		{
+			name:        "non-numeric limit",
+			bosListener: "LOCAL://0.0.0.0:5190?max_tlv_size=big",
+			errContains: `invalid max_tlv_size "big"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				BOSListeners:            []string{tt.bosListener},
+				BOSAdvertisedHostsPlain: []string{"LOCAL://127.0.0.1:5190"},
+				DecodeMaxFrameSize:      defaults.MaxFrameSize,
+				DecodeMaxTLVSize:        defaults.MaxTLVSize,
+				DecodeMaxListLen:        defaults.MaxListLen,
+				DecodeMaxDepth:          defaults.MaxDepth,
+			}
+			got, err := config.ParseListenersCfg()
+
+			if tt.errContains != "" {
+				if err == nil || !contains(err.Error(), tt.errContains) {
+					t.Errorf("ParseListenersCfg() error = %v, want error containing %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseListenersCfg() unexpected error = %v", err)
+			}
+			if got[0].DecodeLimits != tt.want {
+				t.Errorf("ParseListenersCfg() DecodeLimits = %+v, want %+v", got[0].DecodeLimits, tt.want)
+			}
+			if got[0].BOSListenAddress != "0.0.0.0:5190" {
+				t.Errorf("ParseListenersCfg() BOSListenAddress = %v, want 0.0.0.0:5190", got[0].BOSListenAddress)
+			}
+		})
+	}
+}
+
+func TestParseListenersCfg_TLS(t *testing.T) {
+	defaults := TLS{CertFile: "server.pem", KeyFile: "server.key"}
+
+	tests := []struct {
+		name                 string
+		bosTLSListeners      []string
+		kerberosTLSListeners []string
+		advertisedHostsSSL   []string
+		certFile             string
+		want                 Listener
+		errContains          string
+	}{
+		{
+			name:                 "default certificate",
+			bosTLSListeners:      []string{"LOCAL://0.0.0.0:5193"},
+			kerberosTLSListeners: []string{"LOCAL://0.0.0.0:443"},
+			advertisedHostsSSL:   []string{"LOCAL://ras.dev:5193"},
+			certFile:             "server.pem",
+			want: Listener{
+				BOSListenAddressTLS:      "0.0.0.0:5193",
+				KerberosListenAddressTLS: "0.0.0.0:443",
+				TLS:                      defaults,
+			},
+		},
+		{
+			name:                 "per-listener certificate",
+			bosTLSListeners:      []string{"LOCAL://0.0.0.0:5193?cert_file=other.pem&key_file=other.key&legacy_ciphers=true"},
+			kerberosTLSListeners: []string{"LOCAL://0.0.0.0:443"},
+			advertisedHostsSSL:   []string{"LOCAL://ras.dev:5193"},
+			want: Listener{
+				BOSListenAddressTLS:      "0.0.0.0:5193",
+				KerberosListenAddressTLS: "0.0.0.0:443",
+				TLS:                      TLS{CertFile: "other.pem", KeyFile: "other.key", LegacyCiphers: true},
+			},
+		},
+		{
+			name:                 "kerberos only",
+			kerberosTLSListeners: []string{"LOCAL://0.0.0.0:443"},
+			certFile:             "server.pem",
+			want: Listener{
+				KerberosListenAddressTLS: "0.0.0.0:443",
+				TLS:                      defaults,
+			},
+		},
+		{
+			name:            "missing SSL advertise address",
+			bosTLSListeners: []string{"LOCAL://0.0.0.0:5193"},
+			certFile:        "server.pem",
+			errContains:     "missing SSL advertise address for listener `local://`",
+		},
+		{
+			name:               "missing certificate",
+			bosTLSListeners:    []string{"LOCAL://0.0.0.0:5193"},
+			advertisedHostsSSL: []string{"LOCAL://ras.dev:5193"},
+			errContains:        "missing TLS certificate for listener `local://`",
+		},
+		{
+			name:               "invalid legacy ciphers option",
+			bosTLSListeners:    []string{"LOCAL://0.0.0.0:5193?legacy_ciphers=maybe"},
+			advertisedHostsSSL: []string{"LOCAL://ras.dev:5193"},
+			certFile:           "server.pem",
+			errContains:        `invalid legacy_ciphers "maybe"`,
+		},
+		{
+			name:               "unknown option",
+			bosTLSListeners:    []string{"LOCAL://0.0.0.0:5193?max_depth=4"},
+			advertisedHostsSSL: []string{"LOCAL://ras.dev:5193"},
+			certFile:           "server.pem",
+			errContains:        `unknown listener option "max_depth"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				BOSListeners:            []string{"LOCAL://0.0.0.0:5190"},
+				BOSTLSListeners:         tt.bosTLSListeners,
+				BOSAdvertisedHostsPlain: []string{"LOCAL://127.0.0.1:5190"},
+				BOSAdvertisedHostsSSL:   tt.advertisedHostsSSL,
+				KerberosTLSListeners:    tt.kerberosTLSListeners,
+				TLSCertFile:             tt.certFile,
+				TLSKeyFile:              "server.key",
+			}
+			got, err := config.ParseListenersCfg()
+
+			if tt.errContains != "" {
+				if err == nil || !contains(err.Error(), tt.errContains) {
+					t.Errorf("ParseListenersCfg() error = %v, want error containing %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseListenersCfg() unexpected error = %v", err)
+			}
+			if got[0].BOSListenAddressTLS != tt.want.BOSListenAddressTLS {
+				t.Errorf("ParseListenersCfg() BOSListenAddressTLS = %v, want %v", got[0].BOSListenAddressTLS, tt.want.BOSListenAddressTLS)
+			}
+			if got[0].KerberosListenAddressTLS != tt.want.KerberosListenAddressTLS {
+				t.Errorf("ParseListenersCfg() KerberosListenAddressTLS = %v, want %v", got[0].KerberosListenAddressTLS, tt.want.KerberosListenAddressTLS)
+			}
+			if got[0].TLS != tt.want.TLS {
+				t.Errorf("ParseListenersCfg() TLS = %+v, want %+v", got[0].TLS, tt.want.TLS)
+			}
+		})
+	}
+}
+
+func TestParseTOCListenersCfg(t *testing.T) {
+	config := &Config{
+		TOCListeners:       []string{"0.0.0.0:9898", " ", "0.0.0.0:9897?max_frame_size=1024&max_depth=0"},
+		TOCTLSListeners:    []string{"0.0.0.0:9899"},
+		TLSCertFile:        "server.pem",
+		TLSKeyFile:         "server.key",
+		TLSLegacyCiphers:   true,
+		DecodeMaxFrameSize: 32768,
+		DecodeMaxTLVSize:   16384,
+		DecodeMaxListLen:   2048,
+		DecodeMaxDepth:     8,
+	}
+	defaults := config.DecodeLimits()
+	want := []TOCListener{
+		{ListenAddress: "0.0.0.0:9898", DecodeLimits: defaults},
+		{
+			ListenAddress: "0.0.0.0:9897",
+			DecodeLimits: wire.DecodeLimits{
+				MaxFrameSize: 1024,
+				MaxTLVSize:   16384,
+				MaxListLen:   2048,
+				MaxDepth:     0,
+			},
+		},
+		{
+			ListenAddress: "0.0.0.0:9899",
+			HasTLS:        true,
+			TLS:           TLS{CertFile: "server.pem", KeyFile: "server.key", LegacyCiphers: true},
+			DecodeLimits:  defaults,
+		},
+	}
+
+	got, err := config.ParseTOCListenersCfg()
+	if err != nil {
+		t.Fatalf("ParseTOCListenersCfg() error = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseTOCListenersCfg() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ParseTOCListenersCfg()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
 func TestConfigValidate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -455,6 +678,63 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid TOC listener with decode limits",
+			config: Config{
+				TOCListeners: []string{"0.0.0.0:9898?max_frame_size=1024"},
+				APIListener:  "127.0.0.1:8080",
+			},
+			wantErr: false,
+		},
+		{
+			name: "TOC listener with unknown option",
+			config: Config{
+				TOCListeners: []string{"0.0.0.0:9898?max_frame=1024"},
+				APIListener:  "127.0.0.1:8080",
+			},
+			wantErr:     true,
+			errContains: `unknown listener option "max_frame"`,
+		},
+		{
+			name: "TOC TLS listener with invalid decode limit",
+			config: Config{
+				TOCTLSListeners: []string{"0.0.0.0:9899?max_depth=-1"},
+				APIListener:     "127.0.0.1:8080",
+				TLSCertFile:     "server.pem",
+				TLSKeyFile:      "server.key",
+			},
+			wantErr:     true,
+			errContains: `invalid max_depth "-1"`,
+		},
+		{
+			name: "TOC TLS listener without certificate",
+			config: Config{
+				TOCTLSListeners: []string{"0.0.0.0:9899"},
+				APIListener:     "127.0.0.1:8080",
+			},
+			wantErr:     true,
+			errContains: "TLS_CERT_FILE and TLS_KEY_FILE are required when TOC_TLS_LISTENERS is set",
+		},
+		{
+			name: "API TLS without certificate",
+			config: Config{
+				APIListener: "127.0.0.1:8080",
+				APITLS:      true,
+			},
+			wantErr:     true,
+			errContains: "TLS_CERT_FILE and TLS_KEY_FILE are required when API_TLS is true",
+		},
+		{
+			name: "valid config with TLS",
+			config: Config{
+				TOCTLSListeners: []string{"0.0.0.0:9899"},
+				APIListener:     "127.0.0.1:8080",
+				APITLS:          true,
+				TLSCertFile:     "server.pem",
+				TLSKeyFile:      "server.key",
+			},
+			wantErr: false,
+		},
 		{
 			name: "valid config with empty TOC listeners",
 			config: Config{
@@ -508,6 +788,125 @@ func TestConfigValidate(t *testing.T) {
 			wantErr:     true,
 			errContains: "invalid TOC listener \"invalid-format\": address invalid-format: missing port in address",
 		},
+		{
+			name: "valid login lockout config",
+			config: Config{
+				APIListener:           "127.0.0.1:8080",
+				LoginLockoutThreshold: 5,
+				LoginLockoutWindow:    15 * time.Minute,
+				LoginLockoutCooldown:  15 * time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid login lockout threshold",
+			config: Config{
+				APIListener:           "127.0.0.1:8080",
+				LoginLockoutThreshold: -1,
+			},
+			wantErr:     true,
+			errContains: "invalid login lockout threshold -1: must be 0 or greater",
+		},
+		{
+			name: "invalid invite daily limit",
+			config: Config{
+				APIListener:      "127.0.0.1:8080",
+				InviteDailyLimit: -1,
+			},
+			wantErr:     true,
+			errContains: "invalid invite daily limit -1: must be 0 or greater",
+		},
+		{
+			name: "invalid IM archive retention",
+			config: Config{
+				APIListener:            "127.0.0.1:8080",
+				IMArchiveRetentionDays: -1,
+			},
+			wantErr:     true,
+			errContains: "invalid IM archive retention -1: must be 0 or greater",
+		},
+		{
+			name: "invalid offline message limit",
+			config: Config{
+				APIListener:               "127.0.0.1:8080",
+				OfflineMsgMaxPerRecipient: -1,
+			},
+			wantErr:     true,
+			errContains: "invalid offline message limit -1: must be 0 or greater",
+		},
+		{
+			name: "invalid offline message retention",
+			config: Config{
+				APIListener:             "127.0.0.1:8080",
+				OfflineMsgRetentionDays: -1,
+			},
+			wantErr:     true,
+			errContains: "invalid offline message retention -1: must be 0 or greater",
+		},
+		{
+			name: "invalid keepalive interval",
+			config: Config{
+				APIListener:       "127.0.0.1:8080",
+				KeepAliveInterval: -time.Second,
+			},
+			wantErr:     true,
+			errContains: "invalid keepalive interval -1s: must be 0 or greater",
+		},
+		{
+			name: "invalid idle timeout",
+			config: Config{
+				APIListener: "127.0.0.1:8080",
+				IdleTimeout: -time.Minute,
+			},
+			wantErr:     true,
+			errContains: "invalid idle timeout -1m0s: must be 0 or greater",
+		},
+		{
+			name: "invalid decode limit",
+			config: Config{
+				APIListener:      "127.0.0.1:8080",
+				DecodeMaxListLen: -1,
+			},
+			wantErr:     true,
+			errContains: "invalid DECODE_MAX_LIST_LEN -1: must be 0 or greater",
+		},
+		{
+			name: "invalid outbound queue size",
+			config: Config{
+				APIListener:       "127.0.0.1:8080",
+				OutboundQueueSize: -1,
+			},
+			wantErr:     true,
+			errContains: "invalid outbound queue size -1: must be 0 or greater",
+		},
+		{
+			name: "valid outbound queue overflow policy",
+			config: Config{
+				APIListener:                 "127.0.0.1:8080",
+				OutboundQueueSize:           50,
+				OutboundQueueOverflowPolicy: OverflowPolicyDisconnect,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid outbound queue overflow policy",
+			config: Config{
+				APIListener:                 "127.0.0.1:8080",
+				OutboundQueueOverflowPolicy: "drop-oldest",
+			},
+			wantErr:     true,
+			errContains: "invalid outbound queue overflow policy \"drop-oldest\"",
+		},
+		{
+			name: "login lockout threshold without cooldown",
+			config: Config{
+				APIListener:           "127.0.0.1:8080",
+				LoginLockoutThreshold: 5,
+				LoginLockoutWindow:    15 * time.Minute,
+			},
+			wantErr:     true,
+			errContains: "login lockout window and cooldown must be greater than 0",
+		},
 		{
 			name: "invalid TOC listener in comma-separated list",
 			config: Config{
@@ -561,6 +960,161 @@ func TestConfigValidate(t *testing.T) {
 			wantErr:     true,
 			errContains: "APIListener is required and cannot be empty",
 		},
+		{
+			name: "valid LDAP auth provider",
+			config: Config{
+				APIListener:        "127.0.0.1:8080",
+				AuthProvider:       AuthProviderLDAP,
+				LDAPURL:            "ldap://127.0.0.1:389",
+				LDAPBindDNTemplate: "uid=%s,ou=people,dc=example,dc=org",
+			},
+			wantErr: false,
+		},
+		{
+			name: "LDAP auth provider - missing URL",
+			config: Config{
+				APIListener:        "127.0.0.1:8080",
+				AuthProvider:       AuthProviderLDAP,
+				LDAPBindDNTemplate: "uid=%s,ou=people,dc=example,dc=org",
+			},
+			wantErr:     true,
+			errContains: "LDAP_URL is required",
+		},
+		{
+			name: "LDAP auth provider - missing placeholder",
+			config: Config{
+				APIListener:        "127.0.0.1:8080",
+				AuthProvider:       AuthProviderLDAP,
+				LDAPURL:            "ldap://127.0.0.1:389",
+				LDAPBindDNTemplate: "uid=admin,dc=example,dc=org",
+			},
+			wantErr:     true,
+			errContains: "LDAP_BIND_DN_TEMPLATE must contain exactly one %s placeholder",
+		},
+		{
+			name: "invalid auth provider",
+			config: Config{
+				APIListener:  "127.0.0.1:8080",
+				AuthProvider: "kerberos",
+			},
+			wantErr:     true,
+			errContains: "invalid auth provider \"kerberos\"",
+		},
+		{
+			name: "valid registration UIN range",
+			config: Config{
+				APIListener:         "127.0.0.1:8080",
+				RegistrationEnabled: true,
+				RegistrationUINMin:  100000,
+				RegistrationUINMax:  999999999,
+			},
+			wantErr: false,
+		},
+		{
+			name: "registration UIN range below minimum UIN",
+			config: Config{
+				APIListener:         "127.0.0.1:8080",
+				RegistrationEnabled: true,
+				RegistrationUINMin:  9999,
+				RegistrationUINMax:  999999999,
+			},
+			wantErr:     true,
+			errContains: "must be within 10000-2147483646",
+		},
+		{
+			name: "registration UIN range inverted",
+			config: Config{
+				APIListener:         "127.0.0.1:8080",
+				RegistrationEnabled: true,
+				RegistrationUINMin:  200000,
+				RegistrationUINMax:  100000,
+			},
+			wantErr:     true,
+			errContains: "min must not exceed max",
+		},
+		{
+			name: "valid SMTP mail backend",
+			config: Config{
+				APIListener:     "127.0.0.1:8080",
+				MailBackend:     MailBackendSMTP,
+				MailFrom:        "Retro AIM Server <noreply@aim.example.com>",
+				MailLinkBaseURL: "https://aim.example.com",
+				SMTPAddress:     "smtp.example.com:587",
+			},
+			wantErr: false,
+		},
+		{
+			name: "SMTP mail backend - missing port",
+			config: Config{
+				APIListener:     "127.0.0.1:8080",
+				MailBackend:     MailBackendSMTP,
+				MailFrom:        "noreply@aim.example.com",
+				MailLinkBaseURL: "https://aim.example.com",
+				SMTPAddress:     "smtp.example.com",
+			},
+			wantErr:     true,
+			errContains: "invalid SMTP_ADDRESS",
+		},
+		{
+			name: "outbox mail backend - missing directory",
+			config: Config{
+				APIListener:     "127.0.0.1:8080",
+				MailBackend:     MailBackendOutbox,
+				MailFrom:        "noreply@aim.example.com",
+				MailLinkBaseURL: "https://aim.example.com",
+			},
+			wantErr:     true,
+			errContains: "MAIL_OUTBOX_DIR is required",
+		},
+		{
+			name: "mail backend - invalid sender",
+			config: Config{
+				APIListener:     "127.0.0.1:8080",
+				MailBackend:     MailBackendOutbox,
+				MailFrom:        "not an address",
+				MailLinkBaseURL: "https://aim.example.com",
+				MailOutboxDir:   "outbox",
+			},
+			wantErr:     true,
+			errContains: "invalid MAIL_FROM address",
+		},
+		{
+			name: "mail backend - missing link base URL",
+			config: Config{
+				APIListener:   "127.0.0.1:8080",
+				MailBackend:   MailBackendOutbox,
+				MailFrom:      "noreply@aim.example.com",
+				MailOutboxDir: "outbox",
+			},
+			wantErr:     true,
+			errContains: "invalid MAIL_LINK_BASE_URL",
+		},
+		{
+			name: "invalid mail backend",
+			config: Config{
+				APIListener: "127.0.0.1:8080",
+				MailBackend: "carrier-pigeon",
+			},
+			wantErr:     true,
+			errContains: "invalid mail backend \"carrier-pigeon\"",
+		},
+		{
+			name: "valid cluster redis address",
+			config: Config{
+				APIListener:         "127.0.0.1:8080",
+				ClusterRedisAddress: "10.0.0.5:6379",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid cluster redis address",
+			config: Config{
+				APIListener:         "127.0.0.1:8080",
+				ClusterRedisAddress: "10.0.0.5",
+			},
+			wantErr:     true,
+			errContains: "invalid CLUSTER_REDIS_ADDRESS",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// RateClass is the JSON representation of a rate limit class in the file at
+// RATE_LIMITS_FILE and in the management API.
+type RateClass struct {
+	ID              uint16 `json:"id"`
+	WindowSize      int32  `json:"window_size"`
+	ClearLevel      int32  `json:"clear_level"`
+	AlertLevel      int32  `json:"alert_level"`
+	LimitLevel      int32  `json:"limit_level"`
+	DisconnectLevel int32  `json:"disconnect_level"`
+	MaxLevel        int32  `json:"max_level"`
+}
+
+// WireRateClass converts the class to its wire representation.
+func (c RateClass) WireRateClass() wire.RateClass {
+	return wire.RateClass{
+		ID:              wire.RateLimitClassID(c.ID),
+		WindowSize:      c.WindowSize,
+		ClearLevel:      c.ClearLevel,
+		AlertLevel:      c.AlertLevel,
+		LimitLevel:      c.LimitLevel,
+		DisconnectLevel: c.DisconnectLevel,
+		MaxLevel:        c.MaxLevel,
+	}
+}
+
+// NewRateClass converts a wire rate limit class to its JSON representation.
+func NewRateClass(c wire.RateClass) RateClass {
+	return RateClass{
+		ID:              uint16(c.ID),
+		WindowSize:      c.WindowSize,
+		ClearLevel:      c.ClearLevel,
+		AlertLevel:      c.AlertLevel,
+		LimitLevel:      c.LimitLevel,
+		DisconnectLevel: c.DisconnectLevel,
+		MaxLevel:        c.MaxLevel,
+	}
+}
+
+// rateLimitsFile is the format of the file at RATE_LIMITS_FILE.
+type rateLimitsFile struct {
+	// Classes replace the default classes with the same ID.
+	Classes []RateClass `json:"classes"`
+	// SNACs assign SNACs to a rate limit class, in addition to or in place
+	// of the default assignments.
+	SNACs []struct {
+		FoodGroup uint16 `json:"food_group"`
+		SubGroup  uint16 `json:"sub_group"`
+		Class     uint16 `json:"class"`
+	} `json:"snacs"`
+}
+
+// RateLimits returns the rate limit classes and the SNAC-to-class mapping.
+// These are the defaults from wire.DefaultRateLimitClasses and
+// wire.DefaultSNACRateLimits, with the changes in the file at
+// RATE_LIMITS_FILE applied, if set.
+func (c *Config) RateLimits() (wire.RateLimitClasses, wire.SNACRateLimits, error) {
+	classes := wire.DefaultRateLimitClasses()
+	snacs := wire.DefaultSNACRateLimits()
+	if c.RateLimitsFile == "" {
+		return classes, snacs, nil
+	}
+
+	b, err := os.ReadFile(c.RateLimitsFile)
+	if err != nil {
+		return classes, snacs, fmt.Errorf("unable to read rate limits file: %w", err)
+	}
+	file := rateLimitsFile{}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return classes, snacs, fmt.Errorf("unable to parse rate limits file %s: %w", c.RateLimitsFile, err)
+	}
+
+	overrides := make([]wire.RateClass, 0, len(file.Classes))
+	for _, class := range file.Classes {
+		rc := class.WireRateClass()
+		if err := rc.Validate(); err != nil {
+			return classes, snacs, fmt.Errorf("invalid rate limits file %s: %w", c.RateLimitsFile, err)
+		}
+		overrides = append(overrides, rc)
+	}
+	classes = classes.WithOverrides(overrides)
+
+	for _, snac := range file.SNACs {
+		if snac.Class < 1 || snac.Class > 5 {
+			return classes, snacs, fmt.Errorf("invalid rate limits file %s: invalid class %d for SNAC(0x%02x,0x%02x): must be between 1 and 5",
+				c.RateLimitsFile, snac.Class, snac.FoodGroup, snac.SubGroup)
+		}
+		snacs = snacs.WithClass(snac.FoodGroup, snac.SubGroup, wire.RateLimitClassID(snac.Class))
+	}
+
+	return classes, snacs, nil
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+func TestConfig_RateLimits(t *testing.T) {
+	t.Run("no file uses defaults", func(t *testing.T) {
+		classes, snacs, err := (&Config{}).RateLimits()
+		if err != nil {
+			t.Fatalf("RateLimits() unexpected error = %v", err)
+		}
+		if classes != wire.DefaultRateLimitClasses() {
+			t.Errorf("RateLimits() classes = %v, want defaults", classes)
+		}
+		want, _ := wire.DefaultSNACRateLimits().RateClassLookup(wire.ICBM, wire.ICBMChannelMsgToHost)
+		if have, _ := snacs.RateClassLookup(wire.ICBM, wire.ICBMChannelMsgToHost); have != want {
+			t.Errorf("RateLimits() ICBM class = %d, want %d", have, want)
+		}
+	})
+
+	t.Run("file overrides classes and SNACs", func(t *testing.T) {
+		file := writeRateLimitsFile(t, `{
+			"classes": [{"id":3,"window_size":10,"clear_level":4,"alert_level":3,"limit_level":2,"disconnect_level":1,"max_level":5}],
+			"snacs": [{"food_group":4,"sub_group":6,"class":5}]
+		}`)
+
+		classes, snacs, err := (&Config{RateLimitsFile: file}).RateLimits()
+		if err != nil {
+			t.Fatalf("RateLimits() unexpected error = %v", err)
+		}
+
+		want := wire.RateClass{ID: 3, WindowSize: 10, ClearLevel: 4, AlertLevel: 3, LimitLevel: 2, DisconnectLevel: 1, MaxLevel: 5}
+		if have := classes.Get(3); have != want {
+			t.Errorf("RateLimits() class 3 = %v, want %v", have, want)
+		}
+		if have, want := classes.Get(1), wire.DefaultRateLimitClasses().Get(1); have != want {
+			t.Errorf("RateLimits() class 1 = %v, want %v", have, want)
+		}
+		if have, ok := snacs.RateClassLookup(wire.ICBM, wire.ICBMChannelMsgToHost); !ok || have != 5 {
+			t.Errorf("RateLimits() ICBM class = %d, want 5", have)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{
+			name:     "malformed file",
+			contents: `{"classes": [`,
+		},
+		{
+			name:     "invalid class",
+			contents: `{"classes": [{"id":3,"window_size":0,"clear_level":4,"alert_level":3,"limit_level":2,"disconnect_level":1,"max_level":5}]}`,
+		},
+		{
+			name:     "invalid SNAC class",
+			contents: `{"snacs": [{"food_group":4,"sub_group":6,"class":6}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeRateLimitsFile(t, tt.contents)
+			if _, _, err := (&Config{RateLimitsFile: file}).RateLimits(); err == nil {
+				t.Errorf("RateLimits() expected error but got none")
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		cfg := &Config{RateLimitsFile: filepath.Join(t.TempDir(), "missing.json")}
+		if _, _, err := cfg.RateLimits(); err == nil {
+			t.Errorf("RateLimits() expected error but got none")
+		}
+	})
+}
+
+func writeRateLimitsFile(t *testing.T, contents string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "rate_limits.json")
+	if err := os.WriteFile(file, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrInvalidConfig indicates that a configuration failed validation.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// RestartRequired returns the names of the settings that differ between c
+// and next and that only take effect when the server restarts. Settings
+// tagged reload:"true" take effect when the configuration is reloaded.
+func (c *Config) RestartRequired(next Config) []string {
+	var names []string
+	have, want := reflect.ValueOf(*c), reflect.ValueOf(next)
+	for i := 0; i < have.NumField(); i++ {
+		field := have.Type().Field(i)
+		if field.Tag.Get("reload") == "true" {
+			continue
+		}
+		if !reflect.DeepEqual(have.Field(i).Interface(), want.Field(i).Interface()) {
+			names = append(names, field.Tag.Get("envconfig"))
+		}
+	}
+	return names
+}
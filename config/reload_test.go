@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfig_RestartRequired(t *testing.T) {
+	cfg := Config{
+		BOSListeners: []string{"LOCAL://0.0.0.0:5190"},
+		DBPath:       "oscar.sqlite",
+		LogLevel:     "info",
+		DisableAuth:  true,
+		APIListener:  "127.0.0.1:8080",
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   []string
+	}{
+		{
+			name:   "no changes",
+			change: func(c *Config) {},
+		},
+		{
+			name: "reloadable settings",
+			change: func(c *Config) {
+				c.BOSListeners = []string{"LOCAL://0.0.0.0:5190", "LAN://0.0.0.0:5191"}
+				c.LogLevel = "debug"
+				c.DisableAuth = false
+			},
+		},
+		{
+			name: "settings that need a restart",
+			change: func(c *Config) {
+				c.LogLevel = "debug"
+				c.DBPath = "other.sqlite"
+				c.APIListener = "127.0.0.1:8081"
+			},
+			want: []string{"API_LISTENER", "DB_PATH"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := cfg
+			tt.change(&next)
+			if have := cfg.RestartRequired(next); !reflect.DeepEqual(have, tt.want) {
+				t.Errorf("RestartRequired() = %v, want %v", have, tt.want)
+			}
+		})
+	}
+}
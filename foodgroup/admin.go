@@ -5,7 +5,9 @@ import (
 	"errors"
 	"log/slog"
 	"net/mail"
+	"time"
 
+	"github.com/mk6i/retro-aim-server/mailer"
 	"github.com/mk6i/retro-aim-server/state"
 	"github.com/mk6i/retro-aim-server/wire"
 )
@@ -17,27 +19,42 @@ func NewAdminService(
 	relationshipFetcher RelationshipFetcher,
 	messageRelayer MessageRelayer,
 	sessionRetriever SessionRetriever,
+	mailer Mailer,
+	linkBaseURL string,
 	logger *slog.Logger,
 ) *AdminService {
 	return &AdminService{
 		accountManager:   accountManager,
 		buddyBroadcaster: newBuddyNotifier(bartItemManager, relationshipFetcher, messageRelayer, sessionRetriever),
+		linkBaseURL:      linkBaseURL,
+		mailer:           mailer,
 		messageRelayer:   messageRelayer,
 		logger:           logger,
+		timeNow:          time.Now,
 	}
 }
 
 // AdminService provides functionality for the Admin food group.
 // The Admin food group is used for client control of passwords, screen name formatting,
 // email address, and account confirmation.
+//
+// If a Mailer is set, account confirmation requests email the user a
+// confirmation link under linkBaseURL, and users are notified by email when
+// their password changes.
 type AdminService struct {
 	accountManager   AccountManager
 	buddyBroadcaster buddyBroadcaster
+	linkBaseURL      string
+	mailer           Mailer
 	messageRelayer   MessageRelayer
 	logger           *slog.Logger
+	timeNow          func() time.Time
 }
 
-// ConfirmRequest will mark the user account as confirmed if the user has an email address set
+// ConfirmRequest handles a request to confirm the user account, which
+// requires an email address on file. If a Mailer is set, the user is emailed
+// a link that confirms the account. Otherwise, the account is confirmed
+// immediately.
 func (s AdminService) ConfirmRequest(ctx context.Context, sess *state.Session, frame wire.SNACFrame) (wire.SNACMessage, error) {
 	// getAdminInfoReply returns an AdminAcctConfirmReply SNAC
 	var getAdminConfirmReply = func(status uint16) wire.SNACMessage {
@@ -53,7 +70,7 @@ func (s AdminService) ConfirmRequest(ctx context.Context, sess *state.Session, f
 		}
 	}
 
-	_, err := s.accountManager.EmailAddress(ctx, sess.IdentScreenName())
+	emailAddress, err := s.accountManager.EmailAddress(ctx, sess.IdentScreenName())
 	if errors.Is(err, state.ErrNoEmailAddress) {
 		return getAdminConfirmReply(wire.AdminAcctConfirmStatusServerError), nil
 	} else if err != nil {
@@ -67,6 +84,21 @@ func (s AdminService) ConfirmRequest(ctx context.Context, sess *state.Session, f
 	if accountConfirmed {
 		return getAdminConfirmReply(wire.AdminAcctConfirmStatusAlreadyConfirmed), nil
 	}
+
+	if s.mailer != nil {
+		token, err := s.accountManager.IssueEmailToken(ctx, sess.IdentScreenName(), state.EmailTokenConfirm, s.timeNow().Add(state.EmailConfirmTokenTTL))
+		if err != nil {
+			return wire.SNACMessage{}, err
+		}
+		link := mailer.Link(s.linkBaseURL, mailer.ConfirmPath, token)
+		msg := mailer.NewConfirmationMessage(emailAddress, sess.DisplayScreenName().String(), link, state.EmailConfirmTokenTTL)
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			s.logger.ErrorContext(ctx, "error sending confirmation email", "err", err.Error())
+			return getAdminConfirmReply(wire.AdminAcctConfirmStatusServerError), nil
+		}
+		return getAdminConfirmReply(wire.AdminAcctConfirmStatusEmailSent), nil
+	}
+
 	if err := s.accountManager.UpdateConfirmStatus(ctx, sess.IdentScreenName(), true); err != nil {
 		return wire.SNACMessage{}, err
 	}
@@ -292,6 +324,8 @@ func (s AdminService) InfoChangeRequest(ctx context.Context, sess *state.Session
 			return getAdminChangeReply(tlvList), nil
 		}
 
+		s.notifyPasswordChanged(ctx, sess)
+
 		return getAdminChangeReply(tlvList), nil
 	}
 
@@ -306,3 +340,24 @@ func (s AdminService) InfoChangeRequest(ctx context.Context, sess *state.Session
 		},
 	}, nil
 }
+
+// notifyPasswordChanged emails the user, if they have an email address on
+// file, to let them know their password changed. Failures are logged but
+// otherwise ignored, since the password change already succeeded.
+func (s AdminService) notifyPasswordChanged(ctx context.Context, sess *state.Session) {
+	if s.mailer == nil {
+		return
+	}
+	emailAddress, err := s.accountManager.EmailAddress(ctx, sess.IdentScreenName())
+	switch {
+	case errors.Is(err, state.ErrNoEmailAddress):
+		return
+	case err != nil:
+		s.logger.ErrorContext(ctx, "error looking up email address", "err", err.Error())
+		return
+	}
+	msg := mailer.NewPasswordChangedMessage(emailAddress, sess.DisplayScreenName().String())
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		s.logger.ErrorContext(ctx, "error sending password change notification", "err", err.Error())
+	}
+}
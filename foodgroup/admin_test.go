@@ -6,11 +6,13 @@ import (
 	"log/slog"
 	"net/mail"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/mk6i/retro-aim-server/config"
+	"github.com/mk6i/retro-aim-server/mailer"
 	"github.com/mk6i/retro-aim-server/state"
 	"github.com/mk6i/retro-aim-server/wire"
 )
@@ -210,6 +212,87 @@ func TestAdminService_ConfirmRequest(t *testing.T) {
 	}
 }
 
+func TestAdminService_ConfirmRequest_Mailer(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	emailAddress := &mail.Address{Address: "chuck@aol.com"}
+	frame := wire.SNACFrame{
+		FoodGroup: wire.Admin,
+		SubGroup:  wire.AdminAcctConfirmRequest,
+		RequestID: 1234,
+	}
+	confirmReply := func(status uint16) wire.SNACMessage {
+		return wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.Admin,
+				SubGroup:  wire.AdminAcctConfirmReply,
+				RequestID: 1234,
+			},
+			Body: wire.SNAC_0x07_0x07_AdminConfirmReply{
+				Status: status,
+			},
+		}
+	}
+
+	cases := []struct {
+		// name is the unit test name
+		name string
+		// sendErr is the error returned by the mailer
+		sendErr error
+		// expectOutput is the SNAC sent from the server to client
+		expectOutput wire.SNACMessage
+	}{
+		{
+			name:         "confirmation email is sent",
+			expectOutput: confirmReply(wire.AdminAcctConfirmStatusEmailSent),
+		},
+		{
+			name:         "confirmation email fails to send",
+			sendErr:      io.EOF,
+			expectOutput: confirmReply(wire.AdminAcctConfirmStatusServerError),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sess := newTestSession("chattingchuck", func(session *state.Session) {
+				session.SetUserInfoFlag(wire.OServiceUserFlagUnconfirmed)
+			})
+
+			accountManager := newMockAccountManager(t)
+			accountManager.EXPECT().
+				EmailAddress(matchContext(), sess.IdentScreenName()).
+				Return(emailAddress, nil)
+			accountManager.EXPECT().
+				ConfirmStatus(matchContext(), sess.IdentScreenName()).
+				Return(false, nil)
+			accountManager.EXPECT().
+				IssueEmailToken(matchContext(), sess.IdentScreenName(), state.EmailTokenConfirm, now.Add(state.EmailConfirmTokenTTL)).
+				Return("the-token", nil)
+
+			mailSender := newMockMailer(t)
+			mailSender.EXPECT().
+				Send(matchContext(), mailer.NewConfirmationMessage(emailAddress, "chattingchuck",
+					"https://aim.example.com/account/confirm?token=the-token", state.EmailConfirmTokenTTL)).
+				Return(tc.sendErr)
+
+			svc := AdminService{
+				accountManager: accountManager,
+				linkBaseURL:    "https://aim.example.com/",
+				logger:         slog.Default(),
+				mailer:         mailSender,
+				timeNow: func() time.Time {
+					return now
+				},
+			}
+			outputSNAC, err := svc.ConfirmRequest(context.Background(), sess, frame)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectOutput, outputSNAC)
+			// the account stays unconfirmed until the link is visited
+			assert.True(t, sess.UserInfoBitmask()&wire.OServiceUserFlagUnconfirmed == wire.OServiceUserFlagUnconfirmed)
+		})
+	}
+}
+
 func TestAdminService_InfoQuery(t *testing.T) {
 	cases := []struct {
 		// name is the unit test name
@@ -1428,3 +1511,52 @@ func TestAdminService_InfoChangeRequest_Password(t *testing.T) {
 		})
 	}
 }
+
+func TestAdminService_InfoChangeRequest_PasswordNotification(t *testing.T) {
+	sess := newTestSession("me")
+	emailAddress := &mail.Address{Address: "me@aol.com"}
+
+	accountManager := newMockAccountManager(t)
+	accountManager.EXPECT().
+		User(matchContext(), sess.IdentScreenName()).
+		Return(func() *state.User {
+			user := &state.User{AuthKey: "auth_key"}
+			assert.NoError(t, user.HashPassword("oldpass"))
+			return user
+		}(), nil)
+	accountManager.EXPECT().
+		SetUserPassword(matchContext(), sess.IdentScreenName(), "newpass").
+		Return(nil)
+	accountManager.EXPECT().
+		EmailAddress(matchContext(), sess.IdentScreenName()).
+		Return(emailAddress, nil)
+
+	mailSender := newMockMailer(t)
+	mailSender.EXPECT().
+		Send(matchContext(), mailer.NewPasswordChangedMessage(emailAddress, "me")).
+		Return(io.EOF) // send failures don't fail the password change
+
+	svc := AdminService{
+		accountManager: accountManager,
+		logger:         slog.Default(),
+		mailer:         mailSender,
+	}
+	body := wire.SNAC_0x07_0x04_AdminInfoChangeRequest{
+		TLVRestBlock: wire.TLVRestBlock{
+			TLVList: wire.TLVList{
+				wire.NewTLVBE(wire.AdminTLVOldPassword, "oldpass"),
+				wire.NewTLVBE(wire.AdminTLVNewPassword, "newpass"),
+			},
+		},
+	}
+	outputSNAC, err := svc.InfoChangeRequest(context.Background(), sess, wire.SNACFrame{RequestID: 1337}, body)
+	assert.NoError(t, err)
+	assert.Equal(t, wire.SNAC_0x07_0x05_AdminChangeReply{
+		Permissions: wire.AdminInfoPermissionsReadWrite,
+		TLVBlock: wire.TLVBlock{
+			TLVList: wire.TLVList{
+				wire.NewTLVBE(wire.AdminTLVNewPassword, []byte{}),
+			},
+		},
+	}, outputSNAC.Body)
+}
@@ -26,7 +26,9 @@ func NewAuthService(
 	cookieBaker CookieBaker,
 	chatMessageRelayer ChatMessageRelayer,
 	accountManager AccountManager,
-	classes wire.RateLimitClasses,
+	rateLimits RateLimitClassManager,
+	loginLockout LoginLockoutManager,
+	authMode AuthModeRetriever,
 ) *AuthService {
 	return &AuthService{
 		chatSessionRegistry: chatSessionRegistry,
@@ -37,7 +39,10 @@ func NewAuthService(
 		userManager:         userManager,
 		chatMessageRelayer:  chatMessageRelayer,
 		accountManager:      accountManager,
-		rateLimitClasses:    classes,
+		rateLimits:          rateLimits,
+		loginLockout:        loginLockout,
+		authMode:            authMode,
+		newCaptcha:          state.NewCaptcha,
 		timeNow:             time.Now,
 	}
 }
@@ -45,6 +50,13 @@ func NewAuthService(
 // AuthService provides client login and session management services. It
 // supports both FLAP (AIM v1.0-v3.0) and BUCP (AIM v3.5-v5.9) authentication
 // modes.
+//
+// If the AuthModeRetriever returns an AuthProvider, it checks passwords in
+// place of the password hashes kept in the user store. Accounts that exist in
+// the provider are created on their first login. Because BUCP clients only
+// send an MD5 digest, which can't be checked against the provider, BUCP
+// logins are checked against the password cached from the last successful
+// login via another method.
 type AuthService struct {
 	chatMessageRelayer  ChatMessageRelayer
 	chatSessionRegistry ChatSessionRegistry
@@ -54,24 +66,38 @@ type AuthService struct {
 	sessionRetriever    SessionRetriever
 	userManager         UserManager
 	accountManager      AccountManager
-	rateLimitClasses    wire.RateLimitClasses
+	rateLimits          RateLimitClassManager
+	loginLockout        LoginLockoutManager
+	authMode            AuthModeRetriever
+	newCaptcha          func() (state.Captcha, error)
 	timeNow             func() time.Time
 }
 
 // RegisterChatSession adds a user to a chat room. The authCookie param is an
 // opaque token returned by {{OServiceService.ServiceRequest}} that identifies
 // the user and chat room. It returns the session object registered in the
-// ChatSessionRegistry.
+// ChatSessionRegistry. The session is subject to the same rate limit classes
+// as the user's BOS session, including any per-account overrides.
 // This method does not verify that the user and chat room exist because it
 // implicitly trusts the contents of the token signed by
 // {{OServiceService.ServiceRequest}}.
 func (s AuthService) RegisterChatSession(ctx context.Context, serverCookie state.ServerCookie) (*state.Session, error) {
+	u, err := s.userManager.User(ctx, serverCookie.ScreenName.IdentScreenName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	classes := s.rateLimits.RateLimitClasses()
+	if u != nil {
+		classes = classes.WithOverrides(u.RateLimitOverrides)
+	}
+
 	sess, err := s.chatSessionRegistry.AddSession(ctx, serverCookie.ChatCookie, serverCookie.ScreenName)
 	if err != nil {
 		return nil, fmt.Errorf("AddSession: %w", err)
 	}
 
-	sess.SetRateClasses(time.Now(), s.rateLimitClasses)
+	sess.SetRateClasses(time.Now(), classes)
+	sess.SetOutboundQueue(s.config.OutboundQueueSize, state.OverflowPolicy(s.config.OutboundQueueOverflowPolicy))
 
 	return sess, err
 }
@@ -104,7 +130,9 @@ func (s AuthService) RegisterBOSSession(ctx context.Context, serverCookie state.
 	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	sess, err := s.sessionManager.AddSession(ctx, u.DisplayScreenName)
+	// let clients that support multiple sessions sign on alongside each other
+	multiSess := wire.MultiConnFlag(serverCookie.MultiConnFlag) == wire.MultiConnFlagsRecentClient
+	sess, err := s.sessionManager.AddSession(ctx, u.DisplayScreenName, multiSess)
 	if err != nil {
 		return nil, fmt.Errorf("AddSession: %w", err)
 	}
@@ -120,7 +148,8 @@ func (s AuthService) RegisterBOSSession(ctx context.Context, serverCookie state.
 		sess.SetUserInfoFlag(wire.OServiceUserFlagBot)
 	}
 
-	sess.SetRateClasses(time.Now(), s.rateLimitClasses)
+	sess.SetRateClasses(time.Now(), s.rateLimits.RateLimitClasses().WithOverrides(u.RateLimitOverrides))
+	sess.SetOutboundQueue(s.config.OutboundQueueSize, state.OverflowPolicy(s.config.OutboundQueueOverflowPolicy))
 
 	// set string containing OSCAR client name and version
 	sess.SetClientID(serverCookie.ClientID)
@@ -154,11 +183,14 @@ func (s AuthService) RetrieveBOSSession(ctx context.Context, serverCookie state.
 	return s.sessionRetriever.RetrieveSession(u.IdentScreenName), nil
 }
 
-// Signout removes this user's session and notifies users who have this user on
-// their buddy list about this user's departure. It's guaranteed that the
-// session is removed from the session pool.
-func (s AuthService) Signout(_ context.Context, sess *state.Session) {
-	s.sessionManager.RemoveSession(sess)
+// Signout removes this user's session from the session pool. Before the
+// session is removed, cleanup is called to release the user's resources.
+// lastInstance indicates whether the session is the user's last signed-on
+// instance, in which case state shared by the user's sessions, such as their
+// buddy list registration and chat rooms, must be released as well. It's
+// guaranteed that the session is removed from the session pool.
+func (s AuthService) Signout(_ context.Context, sess *state.Session, cleanup func(lastInstance bool)) {
+	s.sessionManager.SignoffSession(sess, cleanup)
 }
 
 // SignoutChat removes user from chat room and notifies remaining participants
@@ -191,7 +223,7 @@ func (s AuthService) BUCPChallenge(ctx context.Context, bodyIn wire.SNAC_0x17_0x
 	case user != nil:
 		// user lookup succeeded
 		authKey = user.AuthKey
-	case s.config.DisableAuth:
+	case s.authMode.AuthDisabled():
 		// can't find user, generate stub auth key
 		authKey = newUUIDFn().String()
 	default:
@@ -232,13 +264,55 @@ func (s AuthService) BUCPChallenge(ctx context.Context, bodyIn wire.SNAC_0x17_0x
 // (wire.LoginTLVTagsReconnectHere) and an authorization cookie
 // (wire.LoginTLVTagsAuthorizationCookie). Else, an error code is set
 // (wire.LoginTLVTagsErrorSubcode).
+// If the account is enrolled in two-factor auth and the password checks out,
+// SNAC(0x17,0x0A) is returned instead to ask the client for the one-time
+// code. Pass the client's SNAC(0x17,0x0B) along with the same login request
+// to BUCPSecurID to finish logging in.
 func (s AuthService) BUCPLogin(ctx context.Context, bodyIn wire.SNAC_0x17_0x02_BUCPLoginRequest, newUserFn func(screenName state.DisplayScreenName) (state.User, error), advertisedHost string) (wire.SNACMessage, error) {
+	props := loginProperties{}
+	if err := props.fromTLV(bodyIn.TLVList); err != nil {
+		return wire.SNACMessage{}, err
+	}
+	return s.bucpLogin(ctx, props, newUserFn, advertisedHost)
+}
 
-	block, err := s.login(ctx, bodyIn.TLVList, newUserFn, advertisedHost)
-	if err != nil {
+// BUCPSecurID finishes a BUCP login for an account enrolled in two-factor
+// auth. loginRequest is the request for which BUCPLogin returned
+// SNAC(0x17,0x0A), and bodyIn contains the one-time code entered by the user.
+// It returns SNAC(0x17,0x03).
+func (s AuthService) BUCPSecurID(ctx context.Context, loginRequest wire.SNAC_0x17_0x02_BUCPLoginRequest, bodyIn wire.SNAC_0x17_0x0B_BUCPSecuridResponse, newUserFn func(screenName state.DisplayScreenName) (state.User, error), advertisedHost string) (wire.SNACMessage, error) {
+	props := loginProperties{}
+	if err := props.fromTLV(loginRequest.TLVList); err != nil {
 		return wire.SNACMessage{}, err
 	}
+	if bodyIn.SecurID == "" {
+		// an empty code would otherwise prompt the client again
+		return bucpLoginResponse(loginFailureResponse(props, wire.LoginErrInvalidSecureID)), nil
+	}
+	props.securID = bodyIn.SecurID
+	return s.bucpLogin(ctx, props, newUserFn, advertisedHost)
+}
 
+// bucpLogin logs in a BUCP client and wraps the result in the appropriate
+// SNAC.
+func (s AuthService) bucpLogin(ctx context.Context, props loginProperties, newUserFn func(screenName state.DisplayScreenName) (state.User, error), advertisedHost string) (wire.SNACMessage, error) {
+	block, err := s.loginWithProps(ctx, props, newUserFn, advertisedHost)
+	switch {
+	case errors.Is(err, errSecurIDRequired):
+		return wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.BUCP,
+				SubGroup:  wire.BUCPSecuridRequest,
+			},
+			Body: wire.SNAC_0x17_0x0A_BUCPSecuridRequest{},
+		}, nil
+	case err != nil:
+		return wire.SNACMessage{}, err
+	}
+	return bucpLoginResponse(block), nil
+}
+
+func bucpLoginResponse(block wire.TLVRestBlock) wire.SNACMessage {
 	return wire.SNACMessage{
 		Frame: wire.SNACFrame{
 			FoodGroup: wire.BUCP,
@@ -247,9 +321,207 @@ func (s AuthService) BUCPLogin(ctx context.Context, bodyIn wire.SNAC_0x17_0x02_B
 		Body: wire.SNAC_0x17_0x03_BUCPLoginResponse{
 			TLVRestBlock: block,
 		},
+	}
+}
+
+// maxRegisterUINAttempts is the number of times BUCPRegister retries UIN
+// allocation when racing with another registration for the same UIN.
+const maxRegisterUINAttempts = 3
+
+// BUCPRegistrationImage returns SNAC(0x17,0x0D), which contains a
+// registration image (captcha) that the user must solve before BUCPRegister
+// creates an account. The captcha is returned so that the caller can pass it
+// to BUCPRegister on the same connection. If registration is disabled,
+// return SNAC(0x17,0x01).
+func (s AuthService) BUCPRegistrationImage(_ context.Context) (wire.SNACMessage, state.Captcha, error) {
+	if !s.config.RegistrationEnabled {
+		return bucpRegisterErr(wire.ErrorCodeNotSupportedByHost), state.Captcha{}, nil
+	}
+
+	captcha, err := s.newCaptcha()
+	if err != nil {
+		return wire.SNACMessage{}, state.Captcha{}, fmt.Errorf("failed to generate captcha: %w", err)
+	}
+
+	return wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.BUCP,
+			SubGroup:  wire.BUCPRegistrationImageReply,
+		},
+		Body: wire.SNAC_0x17_0x0D_BUCPRegistrationImageReply{
+			TLVRestBlock: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.BUCPRegistrationImageTLVTagsMIMEType, "image/jpeg"),
+					wire.NewTLVBE(wire.BUCPRegistrationImageTLVTagsImage, captcha.Image),
+				},
+			},
+		},
+	}, captcha, nil
+}
+
+// BUCPRegister creates an account requested from the client's registration
+// dialog. ICQ clients send an wire.ICQNewUINRequest and get the next free
+// UIN in the configured range. AIM clients choose their own screen name. If
+// registration captchas are enabled, the request must contain the solution
+// to captcha, which was issued by BUCPRegistrationImage.
+// Upon success, return SNAC(0x17,0x05), otherwise return SNAC(0x17,0x01).
+func (s AuthService) BUCPRegister(ctx context.Context, inBody wire.SNAC_0x17_0x04_BUCPRegisterRequest, captcha state.Captcha) (wire.SNACMessage, error) {
+	if !s.config.RegistrationEnabled {
+		return bucpRegisterErr(wire.ErrorCodeNotSupportedByHost), nil
+	}
+
+	if s.config.RegistrationCaptcha {
+		response, _ := inBody.String(wire.BUCPRegisterTLVTagsCaptcha)
+		if !captcha.Matches(response) {
+			return bucpRegisterErr(wire.ErrorCodeRequestDenied), nil
+		}
+	}
+
+	if inBody.HasTag(wire.LoginTLVTagsRoastedPassword) {
+		return s.registerScreenName(ctx, inBody)
+	}
+	return s.registerUIN(ctx, inBody)
+}
+
+// registerScreenName creates an AIM account with the screen name and
+// password chosen by the user. The password is roasted the same way as in a
+// FLAP login request.
+func (s AuthService) registerScreenName(ctx context.Context, inBody wire.SNAC_0x17_0x04_BUCPRegisterRequest) (wire.SNACMessage, error) {
+	screenName, _ := inBody.String(wire.LoginTLVTagsScreenName)
+	roastedPass, _ := inBody.Bytes(wire.LoginTLVTagsRoastedPassword)
+	password := string(wire.RoastOSCARPassword(roastedPass))
+
+	sn := state.DisplayScreenName(screenName)
+	if err := sn.ValidateAIMHandle(); err != nil {
+		// UINs are only handed out by the server
+		return bucpRegisterErr(wire.ErrorCodeRequestDenied), nil
+	}
+
+	newUser, err := newRegisteredUser(sn, password)
+	if err != nil {
+		if errors.Is(err, state.ErrPasswordInvalid) {
+			return bucpRegisterErr(wire.ErrorCodeRequestDenied), nil
+		}
+		return wire.SNACMessage{}, err
+	}
+
+	err = s.userManager.InsertUser(ctx, newUser)
+	switch {
+	case errors.Is(err, state.ErrDupUser):
+		return bucpRegisterErr(wire.ErrorCodeRequestDenied), nil
+	case err != nil:
+		return wire.SNACMessage{}, fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	return wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.BUCP,
+			SubGroup:  wire.BUCPRegisterResponse,
+		},
+		Body: wire.SNAC_0x17_0x05_BUCPRegisterResponse{
+			TLVRestBlock: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.LoginTLVTagsScreenName, newUser.DisplayScreenName),
+				},
+			},
+		},
 	}, nil
 }
 
+// registerUIN creates an ICQ account with the next free UIN.
+func (s AuthService) registerUIN(ctx context.Context, inBody wire.SNAC_0x17_0x04_BUCPRegisterRequest) (wire.SNACMessage, error) {
+	b, ok := inBody.Bytes(wire.BUCPRegisterTLVTagsICQInfo)
+	if !ok {
+		return bucpRegisterErr(wire.ErrorCodeInvalidSnac), nil
+	}
+	req := wire.ICQNewUINRequest{}
+	if err := wire.UnmarshalLE(&req, bytes.NewReader(b)); err != nil {
+		return bucpRegisterErr(wire.ErrorCodeInvalidSnac), nil
+	}
+
+	for i := 0; i < maxRegisterUINAttempts; i++ {
+		uin, err := s.userManager.NextUIN(ctx, s.config.RegistrationUINMin, s.config.RegistrationUINMax)
+		if err != nil {
+			if errors.Is(err, state.ErrUINRangeExhausted) {
+				return bucpRegisterErr(wire.ErrorCodeServiceUnavailable), nil
+			}
+			return wire.SNACMessage{}, fmt.Errorf("failed to allocate UIN: %w", err)
+		}
+
+		newUser, err := newRegisteredUser(state.DisplayScreenName(strconv.FormatUint(uint64(uin), 10)), req.Password)
+		if err != nil {
+			if errors.Is(err, state.ErrPasswordInvalid) {
+				return bucpRegisterErr(wire.ErrorCodeRequestDenied), nil
+			}
+			return wire.SNACMessage{}, err
+		}
+
+		err = s.userManager.InsertUser(ctx, newUser)
+		switch {
+		case errors.Is(err, state.ErrDupUser):
+			// another registration claimed this UIN first, try the next one
+			continue
+		case err != nil:
+			return wire.SNACMessage{}, fmt.Errorf("failed to insert user: %w", err)
+		}
+
+		return wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.BUCP,
+				SubGroup:  wire.BUCPRegisterResponse,
+			},
+			Body: wire.SNAC_0x17_0x05_BUCPRegisterResponse{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVLE(wire.BUCPRegisterTLVTagsICQInfo, wire.ICQNewUINResponse{
+							Unknown2: 0x002D,
+							Unknown3: 0x0003,
+							Cookie1:  req.Cookie1,
+							Cookie2:  req.Cookie2,
+							UIN:      uin,
+							Cookie3:  req.Cookie3,
+						}),
+					},
+				},
+			},
+		}, nil
+	}
+
+	return wire.SNACMessage{}, fmt.Errorf("failed to allocate UIN after %d attempts", maxRegisterUINAttempts)
+}
+
+// newRegisteredUser creates a user with a validated password.
+func newRegisteredUser(screenName state.DisplayScreenName, password string) (state.User, error) {
+	uid, err := uuid.NewRandom()
+	if err != nil {
+		return state.User{}, err
+	}
+	u := state.User{
+		AuthKey:           uid.String(),
+		DisplayScreenName: screenName,
+		IdentScreenName:   screenName.IdentScreenName(),
+		IsICQ:             screenName.IsUIN(),
+	}
+	if err := u.HashPassword(password); err != nil {
+		return state.User{}, err
+	}
+	return u, nil
+}
+
+// bucpRegisterErr returns a BUCP error SNAC in response to a registration
+// request.
+func bucpRegisterErr(code uint16) wire.SNACMessage {
+	return wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.BUCP,
+			SubGroup:  wire.BUCPErr,
+		},
+		Body: wire.SNACError{
+			Code: code,
+		},
+	}
+}
+
 // FLAPLogin processes a FLAP authentication request for AIM v1.0-v3.0. Upon
 // successful login, a session is created.
 // If login credentials are invalid and app config DisableAuth is true, a stub
@@ -310,7 +582,7 @@ func (s AuthService) KerberosLogin(ctx context.Context, inBody wire.SNAC_0x050C_
 				KerbRequestID: inBody.RequestID,
 				ScreenName:    inBody.ClientPrincipal,
 				ErrCode:       wire.KerberosErrAuthFailure,
-				Message:       "Auth failure",
+				Message:       kerberosLoginErrMessage(result),
 			},
 		}, nil
 	}
@@ -358,6 +630,20 @@ func (s AuthService) KerberosLogin(ctx context.Context, inBody wire.SNAC_0x050C_
 	}, nil
 }
 
+// kerberosLoginErrMessage returns the message displayed to Kerberos clients
+// when login fails.
+func kerberosLoginErrMessage(result wire.TLVRestBlock) string {
+	code, _ := result.Uint16BE(wire.LoginTLVTagsErrorSubcode)
+	switch code {
+	case wire.LoginErrRateLimitExceeded:
+		return "Too many failed login attempts"
+	case wire.LoginErrInvalidSecureID:
+		return "Invalid SecurID"
+	default:
+		return "Auth failure"
+	}
+}
+
 // loginProperties represents the properties sent by the client at login.
 type loginProperties struct {
 	clientID                string
@@ -372,6 +658,7 @@ type loginProperties struct {
 	plaintextPassword       []byte
 	roastedPass             []byte
 	screenName              state.DisplayScreenName
+	securID                 string
 }
 
 // fromTLV creates an instance of loginProperties from a TLV list.
@@ -427,14 +714,48 @@ func (l *loginProperties) fromTLV(list wire.TLVList) error {
 	return nil
 }
 
+// clearPassword recovers the plaintext password for auth methods that reveal
+// it. It returns nil for BUCP auth, which only sends an MD5 digest.
+func (l loginProperties) clearPassword() []byte {
+	switch {
+	case l.isFLAPAuth:
+		return wire.RoastOSCARPassword(l.roastedPass)
+	case l.isFLAPJavaAuth:
+		return wire.RoastOSCARJavaPassword(l.roastedPass)
+	case l.isTOCAuth:
+		return wire.RoastTOCPassword(l.roastedPass)
+	case l.isKerberosPlaintextAuth:
+		return l.plaintextPassword
+	case l.isKerberosRoastedAuth:
+		return wire.RoastKerberosPassword(l.roastedPass)
+	default:
+		return nil
+	}
+}
+
+// errSecurIDRequired indicates that a BUCP client sent the right password
+// for an account enrolled in two-factor auth and must be asked for the
+// one-time code.
+var errSecurIDRequired = errors.New("SecurID required")
+
 // login validates a user's credentials and creates their session. it returns
 // metadata used in both BUCP and FLAP authentication responses.
 func (s AuthService) login(ctx context.Context, tlv wire.TLVList, newUserFn func(screenName state.DisplayScreenName) (state.User, error), advertisedHost string) (wire.TLVRestBlock, error) {
-
 	props := loginProperties{}
 	if err := props.fromTLV(tlv); err != nil {
 		return wire.TLVRestBlock{}, err
 	}
+	return s.loginWithProps(ctx, props, newUserFn, advertisedHost)
+}
+
+// loginWithProps is like login, except that it takes login properties that
+// were already parsed. It returns errSecurIDRequired if a BUCP client still
+// needs to send a one-time code.
+func (s AuthService) loginWithProps(ctx context.Context, props loginProperties, newUserFn func(screenName state.DisplayScreenName) (state.User, error), advertisedHost string) (wire.TLVRestBlock, error) {
+	// read the auth settings once so that a concurrent reload doesn't
+	// change them partway through the login
+	authDisabled := s.authMode.AuthDisabled()
+	authProvider := s.authMode.AuthProvider()
 
 	user, err := s.userManager.User(ctx, props.screenName.IdentScreenName())
 	if err != nil {
@@ -443,10 +764,14 @@ func (s AuthService) login(ctx context.Context, tlv wire.TLVList, newUserFn func
 
 	if user == nil {
 		// user not found
-		if s.config.DisableAuth {
+		if authDisabled {
 			// auth disabled, create the user
 			return s.createUser(ctx, props, newUserFn, advertisedHost)
 		}
+		if authProvider != nil {
+			// the user may exist in the external auth provider
+			return s.provisionUser(ctx, props, authProvider, advertisedHost)
+		}
 		// auth enabled, return separate login errors for ICQ and AIM
 		loginErr := wire.LoginErrInvalidUsernameOrPassword
 		if props.screenName.IsUIN() {
@@ -460,29 +785,170 @@ func (s AuthService) login(ctx context.Context, tlv wire.TLVList, newUserFn func
 		return loginFailureResponse(props, user.SuspendedStatus), nil
 	}
 
-	if s.config.DisableAuth {
+	if authDisabled {
 		// user exists, but don't validate
 		return s.loginSuccessResponse(props, advertisedHost)
 	}
 
-	var loginOK bool
+	if authProvider != nil && props.isBUCPAuth {
+		// BUCP clients only send an MD5 digest of the password, which can't
+		// be checked against the provider. checking it against a cached
+		// hash would let a password changed or revoked in the provider keep
+		// working.
+		return loginFailureResponse(props, wire.LoginErrInvalidPassword), nil
+	}
+
+	// reject the attempt without checking the password if there were too
+	// many recent failures
+	if s.loginLockout.IsLocked(user.IdentScreenName) {
+		return loginFailureResponse(props, wire.LoginErrRateLimitExceeded), nil
+	}
+
+	loginOK, clearPass, code, err := s.checkPassword(ctx, props, user, authProvider)
+	if err != nil {
+		return wire.TLVRestBlock{}, fmt.Errorf("failed to check password: %w", err)
+	}
+	if !loginOK {
+		s.loginLockout.RecordFailure(user.IdentScreenName)
+		return loginFailureResponse(props, wire.LoginErrInvalidPassword), nil
+	}
+
+	if user.TOTPEnabled() {
+		if props.isBUCPAuth && code == "" {
+			// the password checked out, now ask for the one-time code. the
+			// attempt isn't counted as a success or failure until it's in.
+			return wire.TLVRestBlock{}, errSecurIDRequired
+		}
+		valid, err := s.userManager.ValidateSecondFactor(ctx, *user, code)
+		if err != nil {
+			return wire.TLVRestBlock{}, fmt.Errorf("failed to validate second factor: %w", err)
+		}
+		if !valid {
+			s.loginLockout.RecordFailure(user.IdentScreenName)
+			return loginFailureResponse(props, wire.LoginErrInvalidSecureID), nil
+		}
+	}
+	s.loginLockout.RecordSuccess(user.IdentScreenName)
+
+	switch {
+	case clearPass == nil:
+		// BUCP auth, nothing to update
+	case authProvider != nil && !user.ValidatePlaintextPass(clearPass):
+		// the provider's password changed since it was last cached. refresh
+		// the cache so that the account keeps working with its latest
+		// password if the provider is turned off.
+		if err := user.HashExternalPassword(clearPass); err != nil {
+			return wire.TLVRestBlock{}, err
+		}
+		if err := s.userManager.SetPasswordHashes(ctx, *user); err != nil {
+			return wire.TLVRestBlock{}, fmt.Errorf("failed to refresh password hashes: %w", err)
+		}
+	case user.LegacyHashOnly():
+		// the password checked out against the legacy MD5 hashes. use this
+		// opportunity to store a stronger hash.
+		if err := user.UpgradePasswordHash(clearPass); err != nil {
+			return wire.TLVRestBlock{}, err
+		}
+		if err := s.userManager.SetPasswordHash(ctx, user.IdentScreenName, user.PasswordHash); err != nil {
+			return wire.TLVRestBlock{}, fmt.Errorf("failed to upgrade password hash: %w", err)
+		}
+	}
+
+	return s.loginSuccessResponse(props, advertisedHost)
+}
+
+// checkPassword validates the password sent by the client. It returns the
+// plaintext password, if the auth method reveals it, and the one-time code
+// for accounts enrolled in two-factor auth.
+//
+// BUCP clients only send an MD5 digest of the password, which is checked on
+// its own. Their one-time code arrives separately in SNAC(0x17,0x0B).
+// Clients that send the plaintext password append the code to it instead.
+// If authProvider is set, it checks the plaintext password. BUCP logins must
+// be rejected beforehand in that case.
+func (s AuthService) checkPassword(ctx context.Context, props loginProperties, user *state.User, authProvider state.AuthProvider) (ok bool, clearPass []byte, code string, err error) {
+	if props.isBUCPAuth {
+		return user.ValidateHash(props.passwordHash), nil, props.securID, nil
+	}
+
+	clearPass = props.clearPassword()
+
+	if authProvider != nil {
+		validate := func(candidate []byte) (bool, error) {
+			return authProvider.Authenticate(ctx, user.DisplayScreenName, candidate)
+		}
+		if user.TOTPEnabled() {
+			return state.ValidatePassWithCode(clearPass, validate)
+		}
+		ok, err = validate(clearPass)
+		return ok, clearPass, "", err
+	}
+
+	if user.TOTPEnabled() {
+		ok, clearPass, code = user.ValidatePlaintextPassWithCode(clearPass)
+		return ok, clearPass, code, nil
+	}
+
 	switch {
-	case props.isBUCPAuth:
-		loginOK = user.ValidateHash(props.passwordHash)
 	case props.isFLAPAuth:
-		loginOK = user.ValidateRoastedPass(props.roastedPass)
+		ok = user.ValidateRoastedPass(props.roastedPass)
 	case props.isFLAPJavaAuth:
-		loginOK = user.ValidateRoastedJavaPass(props.roastedPass)
+		ok = user.ValidateRoastedJavaPass(props.roastedPass)
 	case props.isTOCAuth:
-		loginOK = user.ValidateRoastedTOCPass(props.roastedPass)
+		ok = user.ValidateRoastedTOCPass(props.roastedPass)
 	case props.isKerberosPlaintextAuth:
-		loginOK = user.ValidatePlaintextPass(props.plaintextPassword)
+		ok = user.ValidatePlaintextPass(props.plaintextPassword)
 	case props.isKerberosRoastedAuth:
-		loginOK = user.ValidateRoastedKerberosPass(props.roastedPass)
+		ok = user.ValidateRoastedKerberosPass(props.roastedPass)
 	}
 
-	if !loginOK {
-		return loginFailureResponse(props, wire.LoginErrInvalidPassword), nil
+	return ok, clearPass, "", nil
+}
+
+// provisionUser creates a local account for a user that exists in the
+// external auth provider but not in the user store.
+func (s AuthService) provisionUser(ctx context.Context, props loginProperties, authProvider state.AuthProvider, advertisedHost string) (wire.TLVRestBlock, error) {
+	loginErr := wire.LoginErrInvalidUsernameOrPassword
+	if props.screenName.IsUIN() {
+		loginErr = wire.LoginErrICQUserErr
+	}
+
+	clearPass := props.clearPassword()
+	if clearPass == nil {
+		// BUCP clients don't reveal the password, so there's no way to
+		// check it against the provider
+		return loginFailureResponse(props, loginErr), nil
+	}
+
+	// as with local accounts, limit how many passwords can be tried against
+	// the provider
+	identSN := props.screenName.IdentScreenName()
+	if s.loginLockout.IsLocked(identSN) {
+		return loginFailureResponse(props, wire.LoginErrRateLimitExceeded), nil
+	}
+
+	ok, err := authProvider.Authenticate(ctx, props.screenName, clearPass)
+	if err != nil {
+		return wire.TLVRestBlock{}, fmt.Errorf("failed to check password: %w", err)
+	}
+	if !ok {
+		s.loginLockout.RecordFailure(identSN)
+		return loginFailureResponse(props, loginErr), nil
+	}
+	s.loginLockout.RecordSuccess(identSN)
+
+	newUser, err := state.NewExternalUser(props.screenName, clearPass)
+	if err != nil {
+		switch {
+		case errors.Is(err, state.ErrAIMHandleInvalidFormat), errors.Is(err, state.ErrAIMHandleLength), errors.Is(err, state.ErrICQUINInvalidFormat):
+			return loginFailureResponse(props, loginErr), nil
+		default:
+			return wire.TLVRestBlock{}, err
+		}
+	}
+
+	if err := s.userManager.InsertUser(ctx, newUser); err != nil {
+		return wire.TLVRestBlock{}, fmt.Errorf("failed to provision user: %w", err)
 	}
 
 	return s.loginSuccessResponse(props, advertisedHost)
@@ -25,6 +25,9 @@ func TestAuthService_BUCPLoginRequest(t *testing.T) {
 	}
 	assert.NoError(t, user.HashPassword("the_password"))
 
+	totpUser := user
+	totpUser.TOTPSecret = "JBSWY3DPEHPK3PXP"
+
 	cases := []struct {
 		// name is the unit test name
 		name string
@@ -571,6 +574,71 @@ func TestAuthService_BUCPLoginRequest(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:           "AIM account with two-factor auth, correct password, SecurID requested",
+			advertisedHost: "127.0.0.1:5190",
+			inputSNAC: wire.SNAC_0x17_0x02_BUCPLoginRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+						wire.NewTLVBE(wire.LoginTLVTagsPasswordHash, user.StrongMD5Pass),
+					},
+				},
+			},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: user.IdentScreenName,
+							result:     &totpUser,
+						},
+					},
+				},
+			},
+			expectOutput: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.BUCP,
+					SubGroup:  wire.BUCPSecuridRequest,
+				},
+				Body: wire.SNAC_0x17_0x0A_BUCPSecuridRequest{},
+			},
+		},
+		{
+			name:           "AIM account with two-factor auth, wrong password, login failed",
+			advertisedHost: "127.0.0.1:5190",
+			inputSNAC: wire.SNAC_0x17_0x02_BUCPLoginRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+						wire.NewTLVBE(wire.LoginTLVTagsPasswordHash, []byte("bad-password-hash")),
+					},
+				},
+			},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: user.IdentScreenName,
+							result:     &totpUser,
+						},
+					},
+				},
+			},
+			expectOutput: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.BUCP,
+					SubGroup:  wire.BUCPLoginResponse,
+				},
+				Body: wire.SNAC_0x17_0x03_BUCPLoginResponse{
+					TLVRestBlock: wire.TLVRestBlock{
+						TLVList: wire.TLVList{
+							wire.NewTLVBE(wire.LoginTLVTagsScreenName, "screenName"),
+							wire.NewTLVBE(wire.LoginTLVTagsErrorSubcode, wire.LoginErrInvalidPassword),
+						},
+					},
+				},
+			},
+		},
 		{
 			name:           "login with TOC client - failed",
 			advertisedHost: "127.0.0.1:5190",
@@ -622,6 +690,13 @@ func TestAuthService_BUCPLoginRequest(t *testing.T) {
 					InsertUser(matchContext(), params.user).
 					Return(params.err)
 			}
+			for _, params := range tc.mockParams.validateSecondFactorParams {
+				userManager.EXPECT().
+					ValidateSecondFactor(matchContext(), mock.MatchedBy(func(u state.User) bool {
+						return u.IdentScreenName == params.screenName
+					}), params.code).
+					Return(params.result, params.err)
+			}
 			cookieBaker := newMockCookieBaker(t)
 			for _, params := range tc.mockParams.cookieIssueParams {
 				cookieBaker.EXPECT().
@@ -630,9 +705,11 @@ func TestAuthService_BUCPLoginRequest(t *testing.T) {
 			}
 
 			svc := AuthService{
-				config:      tc.cfg,
-				cookieBaker: cookieBaker,
-				userManager: userManager,
+				authMode:     state.NewAuthMode(tc.cfg.DisableAuth, nil),
+				config:       tc.cfg,
+				cookieBaker:  cookieBaker,
+				loginLockout: state.NewLoginLockoutTracker(0, 0, 0),
+				userManager:  userManager,
 			}
 			outputSNAC, err := svc.BUCPLogin(context.Background(), tc.inputSNAC, tc.newUserFn, tc.advertisedHost)
 			assert.ErrorIs(t, err, tc.wantErr)
@@ -641,6 +718,195 @@ func TestAuthService_BUCPLoginRequest(t *testing.T) {
 	}
 }
 
+func TestAuthService_BUCPSecurID(t *testing.T) {
+	user := state.User{
+		IdentScreenName:   state.NewIdentScreenName("screenName"),
+		DisplayScreenName: "screenName",
+		AuthKey:           "auth_key",
+		TOTPSecret:        "JBSWY3DPEHPK3PXP",
+	}
+	assert.NoError(t, user.HashPassword("the_password"))
+
+	loginRequest := wire.SNAC_0x17_0x02_BUCPLoginRequest{
+		TLVRestBlock: wire.TLVRestBlock{
+			TLVList: wire.TLVList{
+				wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+				wire.NewTLVBE(wire.LoginTLVTagsPasswordHash, user.StrongMD5Pass),
+			},
+		},
+	}
+
+	loginFailed := func(code uint16) wire.SNACMessage {
+		return wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.BUCP,
+				SubGroup:  wire.BUCPLoginResponse,
+			},
+			Body: wire.SNAC_0x17_0x03_BUCPLoginResponse{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+						wire.NewTLVBE(wire.LoginTLVTagsErrorSubcode, code),
+					},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		// name is the unit test name
+		name string
+		// loginRequest is the login request that prompted for the SecurID
+		loginRequest wire.SNAC_0x17_0x02_BUCPLoginRequest
+		// inputSNAC is the SNAC sent from the client to the server
+		inputSNAC wire.SNAC_0x17_0x0B_BUCPSecuridResponse
+		// mockParams is the list of params sent to mocks that satisfy this
+		// method's dependencies
+		mockParams mockParams
+		// expectOutput is the SNAC sent from the server to client
+		expectOutput wire.SNACMessage
+	}{
+		{
+			name:         "valid code, login OK",
+			loginRequest: loginRequest,
+			inputSNAC:    wire.SNAC_0x17_0x0B_BUCPSecuridResponse{SecurID: "123456"},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: user.IdentScreenName,
+							result:     &user,
+						},
+					},
+					validateSecondFactorParams: validateSecondFactorParams{
+						{
+							screenName: user.IdentScreenName,
+							code:       "123456",
+							result:     true,
+						},
+					},
+				},
+				cookieBakerParams: cookieBakerParams{
+					cookieIssueParams: cookieIssueParams{
+						{
+							dataIn: func() []byte {
+								loginCookie := state.ServerCookie{
+									ScreenName: user.DisplayScreenName,
+								}
+								buf := &bytes.Buffer{}
+								assert.NoError(t, wire.MarshalBE(loginCookie, buf))
+								return buf.Bytes()
+							}(),
+							cookieOut: []byte("the-cookie"),
+						},
+					},
+				},
+			},
+			expectOutput: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.BUCP,
+					SubGroup:  wire.BUCPLoginResponse,
+				},
+				Body: wire.SNAC_0x17_0x03_BUCPLoginResponse{
+					TLVRestBlock: wire.TLVRestBlock{
+						TLVList: wire.TLVList{
+							wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+							wire.NewTLVBE(wire.LoginTLVTagsReconnectHere, "127.0.0.1:5190"),
+							wire.NewTLVBE(wire.LoginTLVTagsAuthorizationCookie, []byte("the-cookie")),
+						},
+					},
+				},
+			},
+		},
+		{
+			name:         "invalid code, login failed",
+			loginRequest: loginRequest,
+			inputSNAC:    wire.SNAC_0x17_0x0B_BUCPSecuridResponse{SecurID: "654321"},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: user.IdentScreenName,
+							result:     &user,
+						},
+					},
+					validateSecondFactorParams: validateSecondFactorParams{
+						{
+							screenName: user.IdentScreenName,
+							code:       "654321",
+							result:     false,
+						},
+					},
+				},
+			},
+			expectOutput: loginFailed(wire.LoginErrInvalidSecureID),
+		},
+		{
+			name:         "empty code, login failed",
+			loginRequest: loginRequest,
+			inputSNAC:    wire.SNAC_0x17_0x0B_BUCPSecuridResponse{},
+			expectOutput: loginFailed(wire.LoginErrInvalidSecureID),
+		},
+		{
+			name: "wrong password, login failed before checking code",
+			loginRequest: wire.SNAC_0x17_0x02_BUCPLoginRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+						wire.NewTLVBE(wire.LoginTLVTagsPasswordHash, []byte("bad-password-hash")),
+					},
+				},
+			},
+			inputSNAC: wire.SNAC_0x17_0x0B_BUCPSecuridResponse{SecurID: "123456"},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: user.IdentScreenName,
+							result:     &user,
+						},
+					},
+				},
+			},
+			expectOutput: loginFailed(wire.LoginErrInvalidPassword),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userManager := newMockUserManager(t)
+			for _, params := range tc.mockParams.userManagerParams.getUserParams {
+				userManager.EXPECT().
+					User(matchContext(), params.screenName).
+					Return(params.result, params.err)
+			}
+			for _, params := range tc.mockParams.validateSecondFactorParams {
+				userManager.EXPECT().
+					ValidateSecondFactor(matchContext(), mock.MatchedBy(func(u state.User) bool {
+						return u.IdentScreenName == params.screenName
+					}), params.code).
+					Return(params.result, params.err)
+			}
+			cookieBaker := newMockCookieBaker(t)
+			for _, params := range tc.mockParams.cookieIssueParams {
+				cookieBaker.EXPECT().
+					Issue(params.dataIn).
+					Return(params.cookieOut, params.err)
+			}
+
+			svc := AuthService{
+				authMode:     state.NewAuthMode(false, nil),
+				cookieBaker:  cookieBaker,
+				loginLockout: state.NewLoginLockoutTracker(0, 0, 0),
+				userManager:  userManager,
+			}
+			outputSNAC, err := svc.BUCPSecurID(context.Background(), tc.loginRequest, tc.inputSNAC, nil, "127.0.0.1:5190")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectOutput, outputSNAC)
+		})
+	}
+}
+
 func TestAuthService_FLAPLogin(t *testing.T) {
 	user := state.User{
 		AuthKey:           "auth_key",
@@ -649,6 +915,9 @@ func TestAuthService_FLAPLogin(t *testing.T) {
 	}
 	assert.NoError(t, user.HashPassword("the_password"))
 
+	totpUser := user
+	totpUser.TOTPSecret = "JBSWY3DPEHPK3PXP"
+
 	cases := []struct {
 		// name is the unit test name
 		name string
@@ -712,6 +981,176 @@ func TestAuthService_FLAPLogin(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:           "AIM account exists with legacy password hash, correct password, login OK and hash upgraded",
+			advertisedHost: "127.0.0.1:5190",
+			inputSNAC: wire.FLAPSignonFrame{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsRoastedPassword, wire.RoastOSCARPassword([]byte("the_password"))),
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+					},
+				},
+			},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: user.IdentScreenName,
+							result: &state.User{
+								AuthKey:           user.AuthKey,
+								DisplayScreenName: user.DisplayScreenName,
+								IdentScreenName:   user.IdentScreenName,
+								StrongMD5Pass:     user.StrongMD5Pass,
+								WeakMD5Pass:       user.WeakMD5Pass,
+							},
+						},
+					},
+					setPasswordHashParams: setPasswordHashParams{
+						{
+							screenName: user.IdentScreenName,
+							password:   "the_password",
+						},
+					},
+				},
+				cookieBakerParams: cookieBakerParams{
+					cookieIssueParams: cookieIssueParams{
+						{
+							dataIn: func() []byte {
+								loginCookie := state.ServerCookie{
+									ScreenName: user.DisplayScreenName,
+								}
+								buf := &bytes.Buffer{}
+								assert.NoError(t, wire.MarshalBE(loginCookie, buf))
+								return buf.Bytes()
+							}(),
+							cookieOut: []byte("the-cookie"),
+						},
+					},
+				},
+			},
+			expectOutput: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+					wire.NewTLVBE(wire.LoginTLVTagsReconnectHere, "127.0.0.1:5190"),
+					wire.NewTLVBE(wire.LoginTLVTagsAuthorizationCookie, []byte("the-cookie")),
+				},
+			},
+		},
+		{
+			name:           "AIM account with two-factor auth, code appended to password, login OK",
+			advertisedHost: "127.0.0.1:5190",
+			inputSNAC: wire.FLAPSignonFrame{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsRoastedPassword, wire.RoastOSCARPassword([]byte("the_password123456"))),
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+					},
+				},
+			},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: user.IdentScreenName,
+							result:     &totpUser,
+						},
+					},
+					validateSecondFactorParams: validateSecondFactorParams{
+						{
+							screenName: user.IdentScreenName,
+							code:       "123456",
+							result:     true,
+						},
+					},
+				},
+				cookieBakerParams: cookieBakerParams{
+					cookieIssueParams: cookieIssueParams{
+						{
+							dataIn: func() []byte {
+								loginCookie := state.ServerCookie{
+									ScreenName: user.DisplayScreenName,
+								}
+								buf := &bytes.Buffer{}
+								assert.NoError(t, wire.MarshalBE(loginCookie, buf))
+								return buf.Bytes()
+							}(),
+							cookieOut: []byte("the-cookie"),
+						},
+					},
+				},
+			},
+			expectOutput: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+					wire.NewTLVBE(wire.LoginTLVTagsReconnectHere, "127.0.0.1:5190"),
+					wire.NewTLVBE(wire.LoginTLVTagsAuthorizationCookie, []byte("the-cookie")),
+				},
+			},
+		},
+		{
+			name:           "AIM account with two-factor auth, invalid code, login failed",
+			advertisedHost: "127.0.0.1:5190",
+			inputSNAC: wire.FLAPSignonFrame{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsRoastedPassword, wire.RoastOSCARPassword([]byte("the_password000000"))),
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+					},
+				},
+			},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: user.IdentScreenName,
+							result:     &totpUser,
+						},
+					},
+					validateSecondFactorParams: validateSecondFactorParams{
+						{
+							screenName: user.IdentScreenName,
+							code:       "000000",
+							result:     false,
+						},
+					},
+				},
+			},
+			expectOutput: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.LoginTLVTagsScreenName, "screenName"),
+					wire.NewTLVBE(wire.LoginTLVTagsErrorSubcode, wire.LoginErrInvalidSecureID),
+				},
+			},
+		},
+		{
+			name:           "AIM account with two-factor auth, wrong password, login failed",
+			advertisedHost: "127.0.0.1:5190",
+			inputSNAC: wire.FLAPSignonFrame{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsRoastedPassword, wire.RoastOSCARPassword([]byte("the_wrong_password123456"))),
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+					},
+				},
+			},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: user.IdentScreenName,
+							result:     &totpUser,
+						},
+					},
+				},
+			},
+			expectOutput: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.LoginTLVTagsScreenName, "screenName"),
+					wire.NewTLVBE(wire.LoginTLVTagsErrorSubcode, wire.LoginErrInvalidPassword),
+				},
+			},
+		},
 		{
 			name:           "ICQ account exists, correct password, login OK",
 			advertisedHost: "127.0.0.1:5190",
@@ -1060,6 +1499,21 @@ func TestAuthService_FLAPLogin(t *testing.T) {
 					InsertUser(matchContext(), params.user).
 					Return(params.err)
 			}
+			for _, params := range tc.mockParams.setPasswordHashParams {
+				userManager.EXPECT().
+					SetPasswordHash(matchContext(), params.screenName, mock.MatchedBy(func(hash string) bool {
+						u := state.User{PasswordHash: hash}
+						return u.ValidatePlaintextPass([]byte(params.password))
+					})).
+					Return(params.err)
+			}
+			for _, params := range tc.mockParams.validateSecondFactorParams {
+				userManager.EXPECT().
+					ValidateSecondFactor(matchContext(), mock.MatchedBy(func(u state.User) bool {
+						return u.IdentScreenName == params.screenName
+					}), params.code).
+					Return(params.result, params.err)
+			}
 			cookieBaker := newMockCookieBaker(t)
 			for _, params := range tc.mockParams.cookieIssueParams {
 				cookieBaker.EXPECT().
@@ -1067,9 +1521,11 @@ func TestAuthService_FLAPLogin(t *testing.T) {
 					Return(params.cookieOut, params.err)
 			}
 			svc := AuthService{
-				config:      tc.cfg,
-				cookieBaker: cookieBaker,
-				userManager: userManager,
+				authMode:     state.NewAuthMode(tc.cfg.DisableAuth, nil),
+				config:       tc.cfg,
+				cookieBaker:  cookieBaker,
+				loginLockout: state.NewLoginLockoutTracker(0, 0, 0),
+				userManager:  userManager,
 			}
 			outputSNAC, err := svc.FLAPLogin(context.Background(), tc.inputSNAC, tc.newUserFn, tc.advertisedHost)
 			assert.ErrorIs(t, err, tc.wantErr)
@@ -1078,6 +1534,266 @@ func TestAuthService_FLAPLogin(t *testing.T) {
 	}
 }
 
+func TestAuthService_FLAPLogin_LoginLockout(t *testing.T) {
+	user := state.User{
+		AuthKey:           "auth_key",
+		DisplayScreenName: "screenName",
+		IdentScreenName:   state.NewIdentScreenName("screenName"),
+	}
+	assert.NoError(t, user.HashPassword("the_password"))
+
+	loginFrame := func(password string) wire.FLAPSignonFrame {
+		return wire.FLAPSignonFrame{
+			TLVRestBlock: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.LoginTLVTagsRoastedPassword, wire.RoastOSCARPassword([]byte(password))),
+					wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+				},
+			},
+		}
+	}
+	errSubcode := func(block wire.TLVRestBlock) uint16 {
+		code, _ := block.Uint16BE(wire.LoginTLVTagsErrorSubcode)
+		return code
+	}
+
+	userManager := newMockUserManager(t)
+	userManager.EXPECT().
+		User(matchContext(), user.IdentScreenName).
+		Return(&user, nil)
+	cookieBaker := newMockCookieBaker(t)
+	cookieBaker.EXPECT().
+		Issue(mock.Anything).
+		Return([]byte("the-cookie"), nil)
+
+	lockout := state.NewLoginLockoutTracker(3, time.Minute, time.Minute)
+	svc := AuthService{
+		authMode:     state.NewAuthMode(false, nil),
+		cookieBaker:  cookieBaker,
+		loginLockout: lockout,
+		userManager:  userManager,
+	}
+
+	// a successful login resets the failure count
+	for i := 0; i < 2; i++ {
+		block, err := svc.FLAPLogin(context.Background(), loginFrame("bad_password"), state.NewStubUser, "")
+		assert.NoError(t, err)
+		assert.Equal(t, wire.LoginErrInvalidPassword, errSubcode(block))
+	}
+	block, err := svc.FLAPLogin(context.Background(), loginFrame("the_password"), state.NewStubUser, "")
+	assert.NoError(t, err)
+	assert.True(t, block.HasTag(wire.LoginTLVTagsAuthorizationCookie))
+
+	// lock the account with consecutive failures
+	for i := 0; i < 3; i++ {
+		block, err = svc.FLAPLogin(context.Background(), loginFrame("bad_password"), state.NewStubUser, "")
+		assert.NoError(t, err)
+		assert.Equal(t, wire.LoginErrInvalidPassword, errSubcode(block))
+	}
+
+	// the correct password is rejected while locked
+	block, err = svc.FLAPLogin(context.Background(), loginFrame("the_password"), state.NewStubUser, "")
+	assert.NoError(t, err)
+	assert.Equal(t, wire.LoginErrRateLimitExceeded, errSubcode(block))
+
+	// lifting the lockout allows login again
+	_, ok := lockout.ClearLockout(user.IdentScreenName)
+	assert.True(t, ok)
+	block, err = svc.FLAPLogin(context.Background(), loginFrame("the_password"), state.NewStubUser, "")
+	assert.NoError(t, err)
+	assert.True(t, block.HasTag(wire.LoginTLVTagsAuthorizationCookie))
+}
+
+func TestAuthService_FLAPLogin_AuthProvider(t *testing.T) {
+	user := state.User{
+		AuthKey:           "auth_key",
+		DisplayScreenName: "screenName",
+		IdentScreenName:   state.NewIdentScreenName("screenName"),
+	}
+	assert.NoError(t, user.HashPassword("the_old_password"))
+
+	loginFrame := func(screenName state.DisplayScreenName, password string) wire.FLAPSignonFrame {
+		return wire.FLAPSignonFrame{
+			TLVRestBlock: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.LoginTLVTagsRoastedPassword, wire.RoastOSCARPassword([]byte(password))),
+					wire.NewTLVBE(wire.LoginTLVTagsScreenName, screenName),
+				},
+			},
+		}
+	}
+	errSubcode := func(block wire.TLVRestBlock) uint16 {
+		code, _ := block.Uint16BE(wire.LoginTLVTagsErrorSubcode)
+		return code
+	}
+
+	t.Run("existing user logs in with provider password and hashes are refreshed", func(t *testing.T) {
+		userManager := newMockUserManager(t)
+		userManager.EXPECT().
+			User(matchContext(), user.IdentScreenName).
+			Return(&user, nil)
+		userManager.EXPECT().
+			SetPasswordHashes(matchContext(), mock.MatchedBy(func(u state.User) bool {
+				return u.ValidatePlaintextPass([]byte("the_new_password")) &&
+					u.ValidateHash(wire.StrongMD5PasswordHash("the_new_password", user.AuthKey))
+			})).
+			Return(nil)
+		authProvider := newMockAuthProvider(t)
+		authProvider.EXPECT().
+			Authenticate(matchContext(), user.DisplayScreenName, []byte("the_new_password")).
+			Return(true, nil)
+		cookieBaker := newMockCookieBaker(t)
+		cookieBaker.EXPECT().
+			Issue(mock.Anything).
+			Return([]byte("the-cookie"), nil)
+
+		svc := AuthService{
+			authMode:     state.NewAuthMode(false, authProvider),
+			cookieBaker:  cookieBaker,
+			loginLockout: state.NewLoginLockoutTracker(0, 0, 0),
+			userManager:  userManager,
+		}
+		block, err := svc.FLAPLogin(context.Background(), loginFrame(user.DisplayScreenName, "the_new_password"), state.NewStubUser, "")
+		assert.NoError(t, err)
+		assert.True(t, block.HasTag(wire.LoginTLVTagsAuthorizationCookie))
+	})
+
+	t.Run("existing user rejected by provider", func(t *testing.T) {
+		userManager := newMockUserManager(t)
+		userManager.EXPECT().
+			User(matchContext(), user.IdentScreenName).
+			Return(&user, nil)
+		authProvider := newMockAuthProvider(t)
+		authProvider.EXPECT().
+			Authenticate(matchContext(), user.DisplayScreenName, []byte("the_old_password")).
+			Return(false, nil)
+
+		svc := AuthService{
+			authMode:     state.NewAuthMode(false, authProvider),
+			loginLockout: state.NewLoginLockoutTracker(0, 0, 0),
+			userManager:  userManager,
+		}
+		block, err := svc.FLAPLogin(context.Background(), loginFrame(user.DisplayScreenName, "the_old_password"), state.NewStubUser, "")
+		assert.NoError(t, err)
+		assert.Equal(t, wire.LoginErrInvalidPassword, errSubcode(block))
+	})
+
+	t.Run("unknown user is provisioned from provider", func(t *testing.T) {
+		userManager := newMockUserManager(t)
+		userManager.EXPECT().
+			User(matchContext(), state.NewIdentScreenName("newUser")).
+			Return(nil, nil)
+		userManager.EXPECT().
+			InsertUser(matchContext(), mock.MatchedBy(func(u state.User) bool {
+				return u.DisplayScreenName == "newUser" &&
+					u.ValidatePlaintextPass([]byte("the_password"))
+			})).
+			Return(nil)
+		authProvider := newMockAuthProvider(t)
+		authProvider.EXPECT().
+			Authenticate(matchContext(), state.DisplayScreenName("newUser"), []byte("the_password")).
+			Return(true, nil)
+		cookieBaker := newMockCookieBaker(t)
+		cookieBaker.EXPECT().
+			Issue(mock.Anything).
+			Return([]byte("the-cookie"), nil)
+
+		svc := AuthService{
+			authMode:     state.NewAuthMode(false, authProvider),
+			cookieBaker:  cookieBaker,
+			loginLockout: state.NewLoginLockoutTracker(0, 0, 0),
+			userManager:  userManager,
+		}
+		block, err := svc.FLAPLogin(context.Background(), loginFrame("newUser", "the_password"), state.NewStubUser, "")
+		assert.NoError(t, err)
+		assert.True(t, block.HasTag(wire.LoginTLVTagsAuthorizationCookie))
+	})
+
+	t.Run("unknown user rejected by provider", func(t *testing.T) {
+		userManager := newMockUserManager(t)
+		userManager.EXPECT().
+			User(matchContext(), state.NewIdentScreenName("newUser")).
+			Return(nil, nil)
+		authProvider := newMockAuthProvider(t)
+		authProvider.EXPECT().
+			Authenticate(matchContext(), state.DisplayScreenName("newUser"), []byte("the_password")).
+			Return(false, nil)
+
+		svc := AuthService{
+			authMode:     state.NewAuthMode(false, authProvider),
+			loginLockout: state.NewLoginLockoutTracker(0, 0, 0),
+			userManager:  userManager,
+		}
+		block, err := svc.FLAPLogin(context.Background(), loginFrame("newUser", "the_password"), state.NewStubUser, "")
+		assert.NoError(t, err)
+		assert.Equal(t, wire.LoginErrInvalidUsernameOrPassword, errSubcode(block))
+	})
+
+	t.Run("unknown user locked out after provider rejections", func(t *testing.T) {
+		userManager := newMockUserManager(t)
+		userManager.EXPECT().
+			User(matchContext(), state.NewIdentScreenName("newUser")).
+			Return(nil, nil).
+			Times(2)
+		authProvider := newMockAuthProvider(t)
+		authProvider.EXPECT().
+			Authenticate(matchContext(), state.DisplayScreenName("newUser"), []byte("bad_password")).
+			Return(false, nil).
+			Once()
+
+		svc := AuthService{
+			authMode:     state.NewAuthMode(false, authProvider),
+			loginLockout: state.NewLoginLockoutTracker(1, time.Minute, time.Minute),
+			userManager:  userManager,
+		}
+		block, err := svc.FLAPLogin(context.Background(), loginFrame("newUser", "bad_password"), state.NewStubUser, "")
+		assert.NoError(t, err)
+		assert.Equal(t, wire.LoginErrInvalidUsernameOrPassword, errSubcode(block))
+
+		// the provider isn't asked again while the account is locked
+		block, err = svc.FLAPLogin(context.Background(), loginFrame("newUser", "the_password"), state.NewStubUser, "")
+		assert.NoError(t, err)
+		assert.Equal(t, wire.LoginErrRateLimitExceeded, errSubcode(block))
+	})
+}
+
+func TestAuthService_BUCPLogin_AuthProvider(t *testing.T) {
+	user := state.User{
+		AuthKey:           "auth_key",
+		DisplayScreenName: "screenName",
+		IdentScreenName:   state.NewIdentScreenName("screenName"),
+	}
+	assert.NoError(t, user.HashExternalPassword([]byte("the_cached_password")))
+
+	userManager := newMockUserManager(t)
+	userManager.EXPECT().
+		User(matchContext(), user.IdentScreenName).
+		Return(&user, nil)
+
+	svc := AuthService{
+		authMode:     state.NewAuthMode(false, newMockAuthProvider(t)),
+		loginLockout: state.NewLoginLockoutTracker(0, 0, 0),
+		userManager:  userManager,
+	}
+
+	// the cached password digest isn't accepted, since the provider can't
+	// confirm that it's still valid
+	msg, err := svc.BUCPLogin(context.Background(), wire.SNAC_0x17_0x02_BUCPLoginRequest{
+		TLVRestBlock: wire.TLVRestBlock{
+			TLVList: wire.TLVList{
+				wire.NewTLVBE(wire.LoginTLVTagsScreenName, user.DisplayScreenName),
+				wire.NewTLVBE(wire.LoginTLVTagsPasswordHash, user.StrongMD5Pass),
+			},
+		},
+	}, state.NewStubUser, "")
+	assert.NoError(t, err)
+
+	body := msg.Body.(wire.SNAC_0x17_0x03_BUCPLoginResponse)
+	code, _ := body.Uint16BE(wire.LoginTLVTagsErrorSubcode)
+	assert.Equal(t, wire.LoginErrInvalidPassword, code)
+	assert.False(t, body.HasTag(wire.LoginTLVTagsAuthorizationCookie))
+}
+
 func TestAuthService_KerberosLogin(t *testing.T) {
 	user := state.User{
 		AuthKey:           "auth_key",
@@ -1374,10 +2090,12 @@ func TestAuthService_KerberosLogin(t *testing.T) {
 					Return(params.cookieOut, params.err)
 			}
 			svc := AuthService{
-				config:      tc.cfg,
-				cookieBaker: cookieBaker,
-				userManager: userManager,
-				timeNow:     tc.timeNow,
+				authMode:     state.NewAuthMode(tc.cfg.DisableAuth, nil),
+				config:       tc.cfg,
+				cookieBaker:  cookieBaker,
+				loginLockout: state.NewLoginLockoutTracker(0, 0, 0),
+				userManager:  userManager,
+				timeNow:      tc.timeNow,
 			}
 			outputSNAC, err := svc.KerberosLogin(context.Background(), tc.inputSNAC, tc.newUserFn, tc.advertisedHost)
 			assert.ErrorIs(t, err, tc.wantErr)
@@ -1537,6 +2255,7 @@ func TestAuthService_BUCPChallengeRequest(t *testing.T) {
 					Return(params.result, params.err)
 			}
 			svc := AuthService{
+				authMode:    state.NewAuthMode(tc.cfg.DisableAuth, nil),
 				config:      tc.cfg,
 				userManager: userManager,
 			}
@@ -1551,26 +2270,50 @@ func TestAuthService_BUCPChallengeRequest(t *testing.T) {
 }
 
 func TestAuthService_RegisterChatSession_HappyPath(t *testing.T) {
-	sess := newTestSession("ScreenName")
+	override := wire.RateClass{ID: 3, WindowSize: 10, ClearLevel: 1, AlertLevel: 2, LimitLevel: 3, DisconnectLevel: 1, MaxLevel: 4}
 
-	serverCookie := state.ServerCookie{
-		ChatCookie: "the-chat-cookie",
-		ScreenName: sess.DisplayScreenName(),
+	tests := []struct {
+		name      string
+		user      *state.User
+		wantClass wire.RateClass
+	}{
+		{
+			name:      "user without overrides",
+			user:      &state.User{},
+			wantClass: wire.DefaultRateLimitClasses().Get(3),
+		},
+		{
+			name:      "user with overrides",
+			user:      &state.User{RateLimitOverrides: []wire.RateClass{override}},
+			wantClass: override,
+		},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := newTestSession("ScreenName")
 
-	chatSessionRegistry := newMockChatSessionRegistry(t)
-	chatSessionRegistry.EXPECT().
-		AddSession(mock.Anything, serverCookie.ChatCookie, sess.DisplayScreenName()).
-		Return(sess, nil)
+			serverCookie := state.ServerCookie{
+				ChatCookie: "the-chat-cookie",
+				ScreenName: sess.DisplayScreenName(),
+			}
 
-	chatCookieBuf := &bytes.Buffer{}
-	assert.NoError(t, wire.MarshalBE(serverCookie, chatCookieBuf))
+			userManager := newMockUserManager(t)
+			userManager.EXPECT().
+				User(matchContext(), sess.IdentScreenName()).
+				Return(tt.user, nil)
+			chatSessionRegistry := newMockChatSessionRegistry(t)
+			chatSessionRegistry.EXPECT().
+				AddSession(mock.Anything, serverCookie.ChatCookie, sess.DisplayScreenName()).
+				Return(sess, nil)
 
-	svc := NewAuthService(config.Config{}, nil, nil, chatSessionRegistry, nil, nil, nil, nil, wire.DefaultRateLimitClasses())
+			svc := NewAuthService(config.Config{}, nil, nil, chatSessionRegistry, userManager, nil, nil, nil, state.NewRateLimits(wire.DefaultRateLimitClasses()), nil, nil)
 
-	have, err := svc.RegisterChatSession(context.Background(), serverCookie)
-	assert.NoError(t, err)
-	assert.Equal(t, sess, have)
+			have, err := svc.RegisterChatSession(context.Background(), serverCookie)
+			assert.NoError(t, err)
+			assert.Equal(t, sess, have)
+			assert.Equal(t, tt.wantClass, have.RateLimitStates()[2].RateClass)
+		})
+	}
 }
 
 func TestAuthService_RegisterBOSSession(t *testing.T) {
@@ -1582,6 +2325,10 @@ func TestAuthService_RegisterBOSSession(t *testing.T) {
 	icqAuthCookie := state.ServerCookie{
 		ScreenName: uin,
 	}
+	multiConnAuthCookie := state.ServerCookie{
+		ScreenName:    screenName,
+		MultiConnFlag: uint8(wire.MultiConnFlagsRecentClient),
+	}
 
 	cases := []struct {
 		// name is the unit test name
@@ -1596,6 +2343,43 @@ func TestAuthService_RegisterBOSSession(t *testing.T) {
 		// wantErr is the error we expect from the method
 		wantErr error
 	}{
+		{
+			name:   "successfully register a session for a multi-session client",
+			cookie: multiConnAuthCookie,
+			mockParams: mockParams{
+				sessionRegistryParams: sessionRegistryParams{
+					addSessionParams: addSessionParams{
+						{
+							screenName: screenName,
+							multiSess:  true,
+							result:     newTestSession(screenName),
+						},
+					},
+				},
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: screenName.IdentScreenName(),
+							result: &state.User{
+								IdentScreenName:   screenName.IdentScreenName(),
+								DisplayScreenName: screenName,
+							},
+						},
+					},
+				},
+				accountManagerParams: accountManagerParams{
+					accountManagerConfirmStatusParams: accountManagerConfirmStatusParams{
+						{
+							screenName:    screenName.IdentScreenName(),
+							confirmStatus: true,
+						},
+					},
+				},
+			},
+			wantSess: func(session *state.Session) bool {
+				return session.MultiConnFlag() == wire.MultiConnFlagsRecentClient
+			},
+		},
 		{
 			name:   "successfully register an AIM session",
 			cookie: aimAuthCookie,
@@ -1714,7 +2498,7 @@ func TestAuthService_RegisterBOSSession(t *testing.T) {
 			sessionRegistry := newMockSessionRegistry(t)
 			for _, params := range tc.mockParams.addSessionParams {
 				sessionRegistry.EXPECT().
-					AddSession(mock.Anything, params.screenName).
+					AddSession(mock.Anything, params.screenName, params.multiSess).
 					Return(params.result, params.err)
 			}
 			userManager := newMockUserManager(t)
@@ -1730,7 +2514,7 @@ func TestAuthService_RegisterBOSSession(t *testing.T) {
 					Return(params.confirmStatus, nil)
 			}
 
-			svc := NewAuthService(config.Config{}, sessionRegistry, nil, nil, userManager, nil, nil, accountManager, wire.DefaultRateLimitClasses())
+			svc := NewAuthService(config.Config{}, sessionRegistry, nil, nil, userManager, nil, nil, accountManager, state.NewRateLimits(wire.DefaultRateLimitClasses()), nil, nil)
 
 			have, err := svc.RegisterBOSSession(context.Background(), tc.cookie)
 			assert.NoError(t, err)
@@ -1760,7 +2544,7 @@ func TestAuthService_RetrieveBOSSession_HappyPath(t *testing.T) {
 		User(matchContext(), sess.IdentScreenName()).
 		Return(&state.User{IdentScreenName: sess.IdentScreenName()}, nil)
 
-	svc := NewAuthService(config.Config{}, nil, sessionRetriever, nil, userManager, nil, nil, nil, wire.DefaultRateLimitClasses())
+	svc := NewAuthService(config.Config{}, nil, sessionRetriever, nil, userManager, nil, nil, nil, state.NewRateLimits(wire.DefaultRateLimitClasses()), nil, nil)
 
 	have, err := svc.RetrieveBOSSession(context.Background(), aimAuthCookie)
 	assert.NoError(t, err)
@@ -1784,7 +2568,7 @@ func TestAuthService_RetrieveBOSSession_SessionNotFound(t *testing.T) {
 		User(matchContext(), sess.IdentScreenName()).
 		Return(&state.User{IdentScreenName: sess.IdentScreenName()}, nil)
 
-	svc := NewAuthService(config.Config{}, nil, sessionRetriever, nil, userManager, nil, nil, nil, wire.DefaultRateLimitClasses())
+	svc := NewAuthService(config.Config{}, nil, sessionRetriever, nil, userManager, nil, nil, nil, state.NewRateLimits(wire.DefaultRateLimitClasses()), nil, nil)
 
 	have, err := svc.RetrieveBOSSession(context.Background(), aimAuthCookie)
 	assert.NoError(t, err)
@@ -1877,7 +2661,7 @@ func TestAuthService_SignoutChat(t *testing.T) {
 					RemoveSession(matchSession(params.screenName))
 			}
 
-			svc := NewAuthService(config.Config{}, nil, nil, sessionManager, nil, nil, chatMessageRelayer, nil, wire.DefaultRateLimitClasses())
+			svc := NewAuthService(config.Config{}, nil, nil, sessionManager, nil, nil, chatMessageRelayer, nil, state.NewRateLimits(wire.DefaultRateLimitClasses()), nil, nil)
 			svc.SignoutChat(context.Background(), tt.userSession)
 		})
 	}
@@ -1889,27 +2673,37 @@ func TestAuthService_Signout(t *testing.T) {
 		name string
 		// userSession is the session of the user signing out
 		userSession *state.Session
-		// wantErr is the error we expect from the method
-		wantErr error
+		// wantLastInstance is the value we expect the cleanup func to receive
+		wantLastInstance bool
 		// mockParams is the list of params sent to mocks that satisfy this
 		// method's dependencies
 		mockParams mockParams
 	}{
 		{
-			name:        "user signs out of chat room, room is empty after user leaves",
-			userSession: newTestSession("me", sessOptCannedSignonTime),
+			name:             "user signs out of their last session",
+			userSession:      newTestSession("me", sessOptCannedSignonTime),
+			wantLastInstance: true,
 			mockParams: mockParams{
-				buddyBroadcasterParams: buddyBroadcasterParams{
-					broadcastBuddyDepartedParams: broadcastBuddyDepartedParams{
+				sessionRegistryParams: sessionRegistryParams{
+					signoffSessionParams: signoffSessionParams{
 						{
-							screenName: state.NewIdentScreenName("me"),
+							screenName:   state.NewIdentScreenName("me"),
+							lastInstance: true,
 						},
 					},
 				},
+			},
+		},
+		{
+			name:             "user signs out while signed on elsewhere",
+			userSession:      newTestSession("me", sessOptCannedSignonTime),
+			wantLastInstance: false,
+			mockParams: mockParams{
 				sessionRegistryParams: sessionRegistryParams{
-					removeSessionParams: removeSessionParams{
+					signoffSessionParams: signoffSessionParams{
 						{
-							screenName: state.NewIdentScreenName("me"),
+							screenName:   state.NewIdentScreenName("me"),
+							lastInstance: false,
 						},
 					},
 				},
@@ -1919,12 +2713,431 @@ func TestAuthService_Signout(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			sessionManager := newMockSessionRegistry(t)
-			for _, params := range tt.mockParams.removeSessionParams {
-				sessionManager.EXPECT().RemoveSession(matchSession(params.screenName))
+			for _, params := range tt.mockParams.signoffSessionParams {
+				sessionManager.EXPECT().
+					SignoffSession(matchSession(params.screenName), mock.Anything).
+					Run(func(sess *state.Session, cleanup func(bool)) {
+						cleanup(params.lastInstance)
+					})
+			}
+			svc := NewAuthService(config.Config{}, sessionManager, nil, nil, nil, nil, nil, nil, state.NewRateLimits(wire.DefaultRateLimitClasses()), nil, nil)
+
+			called := false
+			svc.Signout(context.Background(), tt.userSession, func(lastInstance bool) {
+				called = true
+				assert.Equal(t, tt.wantLastInstance, lastInstance)
+			})
+			assert.True(t, called)
+		})
+	}
+}
+
+func TestAuthService_BUCPRegistrationImage(t *testing.T) {
+	captcha := state.Captcha{Answer: "12345", Image: []byte("jpeg-data")}
+
+	t.Run("registration enabled", func(t *testing.T) {
+		svc := AuthService{
+			config: config.Config{RegistrationEnabled: true},
+			newCaptcha: func() (state.Captcha, error) {
+				return captcha, nil
+			},
+		}
+		outputSNAC, haveCaptcha, err := svc.BUCPRegistrationImage(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, captcha, haveCaptcha)
+		assert.Equal(t, wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.BUCP,
+				SubGroup:  wire.BUCPRegistrationImageReply,
+			},
+			Body: wire.SNAC_0x17_0x0D_BUCPRegistrationImageReply{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.BUCPRegistrationImageTLVTagsMIMEType, "image/jpeg"),
+						wire.NewTLVBE(wire.BUCPRegistrationImageTLVTagsImage, []byte("jpeg-data")),
+					},
+				},
+			},
+		}, outputSNAC)
+	})
+
+	t.Run("registration disabled", func(t *testing.T) {
+		svc := AuthService{}
+		outputSNAC, haveCaptcha, err := svc.BUCPRegistrationImage(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, haveCaptcha)
+		assert.Equal(t, wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.BUCP,
+				SubGroup:  wire.BUCPErr,
+			},
+			Body: wire.SNACError{
+				Code: wire.ErrorCodeNotSupportedByHost,
+			},
+		}, outputSNAC)
+	})
+}
+
+func TestAuthService_BUCPRegister(t *testing.T) {
+	regCfg := config.Config{
+		RegistrationEnabled: true,
+		RegistrationCaptcha: true,
+		RegistrationUINMin:  100000,
+		RegistrationUINMax:  999999,
+	}
+	captcha := state.Captcha{Answer: "12345"}
+
+	icqInfo := func(password string) wire.TLV {
+		return wire.NewTLVLE(wire.BUCPRegisterTLVTagsICQInfo, wire.ICQNewUINRequest{
+			Unknown2: 0x0028,
+			Unknown3: 0x0003,
+			Cookie1:  1,
+			Cookie2:  2,
+			Password: password,
+			Cookie3:  3,
+		})
+	}
+	errSNAC := func(code uint16) wire.SNACMessage {
+		return wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.BUCP,
+				SubGroup:  wire.BUCPErr,
+			},
+			Body: wire.SNACError{
+				Code: code,
+			},
+		}
+	}
+
+	cases := []struct {
+		// name is the unit test name
+		name string
+		// cfg is the app configuration
+		cfg config.Config
+		// inputSNAC is the registration request sent by the client
+		inputSNAC wire.SNAC_0x17_0x04_BUCPRegisterRequest
+		// nextUINParams are the UINs returned by UserManager.NextUIN
+		nextUINParams []struct {
+			uin uint32
+			err error
+		}
+		// insertUserParams are the screen names and results expected at
+		// UserManager.InsertUser
+		insertUserParams []struct {
+			screenName state.DisplayScreenName
+			password   string
+			err        error
+		}
+		// expectOutput is the response sent from the server to client
+		expectOutput wire.SNACMessage
+		// wantErr is the error we expect from the method
+		wantErr error
+	}{
+		{
+			name: "ICQ registration OK",
+			cfg:  regCfg,
+			inputSNAC: wire.SNAC_0x17_0x04_BUCPRegisterRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						icqInfo("secret1"),
+						wire.NewTLVBE(wire.BUCPRegisterTLVTagsCaptcha, "12345"),
+					},
+				},
+			},
+			nextUINParams: []struct {
+				uin uint32
+				err error
+			}{
+				{uin: 100005},
+			},
+			insertUserParams: []struct {
+				screenName state.DisplayScreenName
+				password   string
+				err        error
+			}{
+				{screenName: "100005", password: "secret1"},
+			},
+			expectOutput: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.BUCP,
+					SubGroup:  wire.BUCPRegisterResponse,
+				},
+				Body: wire.SNAC_0x17_0x05_BUCPRegisterResponse{
+					TLVRestBlock: wire.TLVRestBlock{
+						TLVList: wire.TLVList{
+							wire.NewTLVLE(wire.BUCPRegisterTLVTagsICQInfo, wire.ICQNewUINResponse{
+								Unknown2: 0x002D,
+								Unknown3: 0x0003,
+								Cookie1:  1,
+								Cookie2:  2,
+								UIN:      100005,
+								Cookie3:  3,
+							}),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ICQ registration retries UIN claimed by concurrent registration",
+			cfg:  regCfg,
+			inputSNAC: wire.SNAC_0x17_0x04_BUCPRegisterRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						icqInfo("secret1"),
+						wire.NewTLVBE(wire.BUCPRegisterTLVTagsCaptcha, "12345"),
+					},
+				},
+			},
+			nextUINParams: []struct {
+				uin uint32
+				err error
+			}{
+				{uin: 100005},
+				{uin: 100006},
+			},
+			insertUserParams: []struct {
+				screenName state.DisplayScreenName
+				password   string
+				err        error
+			}{
+				{screenName: "100005", password: "secret1", err: state.ErrDupUser},
+				{screenName: "100006", password: "secret1"},
+			},
+			expectOutput: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.BUCP,
+					SubGroup:  wire.BUCPRegisterResponse,
+				},
+				Body: wire.SNAC_0x17_0x05_BUCPRegisterResponse{
+					TLVRestBlock: wire.TLVRestBlock{
+						TLVList: wire.TLVList{
+							wire.NewTLVLE(wire.BUCPRegisterTLVTagsICQInfo, wire.ICQNewUINResponse{
+								Unknown2: 0x002D,
+								Unknown3: 0x0003,
+								Cookie1:  1,
+								Cookie2:  2,
+								UIN:      100006,
+								Cookie3:  3,
+							}),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ICQ registration with UIN range exhausted",
+			cfg:  regCfg,
+			inputSNAC: wire.SNAC_0x17_0x04_BUCPRegisterRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						icqInfo("secret1"),
+						wire.NewTLVBE(wire.BUCPRegisterTLVTagsCaptcha, "12345"),
+					},
+				},
+			},
+			nextUINParams: []struct {
+				uin uint32
+				err error
+			}{
+				{err: state.ErrUINRangeExhausted},
+			},
+			expectOutput: errSNAC(wire.ErrorCodeServiceUnavailable),
+		},
+		{
+			name: "ICQ registration with invalid password",
+			cfg:  regCfg,
+			inputSNAC: wire.SNAC_0x17_0x04_BUCPRegisterRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						icqInfo("123"),
+						wire.NewTLVBE(wire.BUCPRegisterTLVTagsCaptcha, "12345"),
+					},
+				},
+			},
+			nextUINParams: []struct {
+				uin uint32
+				err error
+			}{
+				{uin: 100005},
+			},
+			expectOutput: errSNAC(wire.ErrorCodeRequestDenied),
+		},
+		{
+			name: "ICQ registration with malformed registration info",
+			cfg:  regCfg,
+			inputSNAC: wire.SNAC_0x17_0x04_BUCPRegisterRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.BUCPRegisterTLVTagsICQInfo, []byte{0x01, 0x02}),
+						wire.NewTLVBE(wire.BUCPRegisterTLVTagsCaptcha, "12345"),
+					},
+				},
+			},
+			expectOutput: errSNAC(wire.ErrorCodeInvalidSnac),
+		},
+		{
+			name: "AIM registration OK",
+			cfg:  regCfg,
+			inputSNAC: func() wire.SNAC_0x17_0x04_BUCPRegisterRequest {
+				// registration request as sent by the client
+				b := []byte{
+					0x00, 0x01, 0x00, 0x08, 'N', 'e', 'w', ' ', 'U', 's', 'e', 'r', // screen name
+					0x00, 0x02, 0x00, 0x0B, 0x87, 0x4E, 0xE4, 0xB4, 0x58, 0xF5, 0xA8, 0xE5, 0x1E, 0xD1, 0xDD, // roasted password
+					0x00, 0x09, 0x00, 0x05, '1', '2', '3', '4', '5', // captcha solution
+				}
+				snac := wire.SNAC_0x17_0x04_BUCPRegisterRequest{}
+				assert.NoError(t, wire.UnmarshalBE(&snac, bytes.NewReader(b)))
+				return snac
+			}(),
+			insertUserParams: []struct {
+				screenName state.DisplayScreenName
+				password   string
+				err        error
+			}{
+				{screenName: "New User", password: "thepassword"},
+			},
+			expectOutput: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.BUCP,
+					SubGroup:  wire.BUCPRegisterResponse,
+				},
+				Body: wire.SNAC_0x17_0x05_BUCPRegisterResponse{
+					TLVRestBlock: wire.TLVRestBlock{
+						TLVList: wire.TLVList{
+							wire.NewTLVBE(wire.LoginTLVTagsScreenName, state.DisplayScreenName("New User")),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AIM registration with taken screen name",
+			cfg:  regCfg,
+			inputSNAC: wire.SNAC_0x17_0x04_BUCPRegisterRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, "New User"),
+						wire.NewTLVBE(wire.LoginTLVTagsRoastedPassword, wire.RoastOSCARPassword([]byte("thepassword"))),
+						wire.NewTLVBE(wire.BUCPRegisterTLVTagsCaptcha, "12345"),
+					},
+				},
+			},
+			insertUserParams: []struct {
+				screenName state.DisplayScreenName
+				password   string
+				err        error
+			}{
+				{screenName: "New User", password: "thepassword", err: state.ErrDupUser},
+			},
+			expectOutput: errSNAC(wire.ErrorCodeRequestDenied),
+		},
+		{
+			name: "AIM registration with UIN as screen name",
+			cfg:  regCfg,
+			inputSNAC: wire.SNAC_0x17_0x04_BUCPRegisterRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, "100005"),
+						wire.NewTLVBE(wire.LoginTLVTagsRoastedPassword, wire.RoastOSCARPassword([]byte("thepassword"))),
+						wire.NewTLVBE(wire.BUCPRegisterTLVTagsCaptcha, "12345"),
+					},
+				},
+			},
+			expectOutput: errSNAC(wire.ErrorCodeRequestDenied),
+		},
+		{
+			name: "wrong captcha",
+			cfg:  regCfg,
+			inputSNAC: wire.SNAC_0x17_0x04_BUCPRegisterRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						icqInfo("secret1"),
+						wire.NewTLVBE(wire.BUCPRegisterTLVTagsCaptcha, "54321"),
+					},
+				},
+			},
+			expectOutput: errSNAC(wire.ErrorCodeRequestDenied),
+		},
+		{
+			name: "captcha not required",
+			cfg: config.Config{
+				RegistrationEnabled: true,
+				RegistrationUINMin:  100000,
+				RegistrationUINMax:  999999,
+			},
+			inputSNAC: wire.SNAC_0x17_0x04_BUCPRegisterRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.LoginTLVTagsScreenName, "New User"),
+						wire.NewTLVBE(wire.LoginTLVTagsRoastedPassword, wire.RoastOSCARPassword([]byte("thepassword"))),
+					},
+				},
+			},
+			insertUserParams: []struct {
+				screenName state.DisplayScreenName
+				password   string
+				err        error
+			}{
+				{screenName: "New User", password: "thepassword"},
+			},
+			expectOutput: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.BUCP,
+					SubGroup:  wire.BUCPRegisterResponse,
+				},
+				Body: wire.SNAC_0x17_0x05_BUCPRegisterResponse{
+					TLVRestBlock: wire.TLVRestBlock{
+						TLVList: wire.TLVList{
+							wire.NewTLVBE(wire.LoginTLVTagsScreenName, state.DisplayScreenName("New User")),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "registration disabled",
+			cfg:  config.Config{},
+			inputSNAC: wire.SNAC_0x17_0x04_BUCPRegisterRequest{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						icqInfo("secret1"),
+						wire.NewTLVBE(wire.BUCPRegisterTLVTagsCaptcha, "12345"),
+					},
+				},
+			},
+			expectOutput: errSNAC(wire.ErrorCodeNotSupportedByHost),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userManager := newMockUserManager(t)
+			for _, params := range tc.nextUINParams {
+				userManager.EXPECT().
+					NextUIN(matchContext(), tc.cfg.RegistrationUINMin, tc.cfg.RegistrationUINMax).
+					Return(params.uin, params.err).
+					Once()
+			}
+			for _, params := range tc.insertUserParams {
+				userManager.EXPECT().
+					InsertUser(matchContext(), mock.MatchedBy(func(u state.User) bool {
+						return u.DisplayScreenName == params.screenName &&
+							u.IdentScreenName == params.screenName.IdentScreenName() &&
+							u.IsICQ == params.screenName.IsUIN() &&
+							u.ValidatePlaintextPass([]byte(params.password))
+					})).
+					Return(params.err).
+					Once()
 			}
-			svc := NewAuthService(config.Config{}, sessionManager, nil, nil, nil, nil, nil, nil, wire.DefaultRateLimitClasses())
 
-			svc.Signout(context.Background(), tt.userSession)
+			svc := AuthService{
+				config:      tc.cfg,
+				userManager: userManager,
+			}
+			outputSNAC, err := svc.BUCPRegister(context.Background(), tc.inputSNAC, captcha)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.expectOutput, outputSNAC)
 		})
 	}
 }
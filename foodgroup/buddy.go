@@ -168,7 +168,26 @@ func (s buddyNotifier) BroadcastBuddyArrived(ctx context.Context, screenName sta
 	return nil
 }
 
+// BroadcastBuddyDeparted tells the user's adjacent users that the user went
+// offline or invisible. If the user is still visible from another session,
+// the adjacent users instead get that session's user info, since the user
+// remains online.
+//
+// When sess is signing off, call this from the session manager's sign-off
+// cleanup, which hides sess and the user's other departing instances from
+// the session pool. If the other session signs off while its arrival is
+// being sent, the departure is sent after all, so that the user isn't left
+// showing as online.
 func (s buddyNotifier) BroadcastBuddyDeparted(ctx context.Context, sess *state.Session) error {
+	if other := s.otherVisibleSession(sess); other != nil {
+		if err := s.BroadcastBuddyArrived(ctx, other.IdentScreenName(), other.TLVUserInfo()); err != nil {
+			return err
+		}
+		if s.otherVisibleSession(sess) != nil {
+			return nil
+		}
+	}
+
 	users, err := s.relationshipFetcher.AllRelationships(ctx, sess.IdentScreenName(), nil)
 	if err != nil {
 		return err
@@ -208,6 +227,17 @@ func (s buddyNotifier) BroadcastBuddyDeparted(ctx context.Context, sess *state.S
 	return nil
 }
 
+// otherVisibleSession returns a signed-on, visible instance of the user other
+// than sess, or nil if there is none.
+func (s buddyNotifier) otherVisibleSession(sess *state.Session) *state.Session {
+	for _, other := range s.sessionRetriever.RetrieveSessions(sess.IdentScreenName()) {
+		if other != sess && !other.Invisible() {
+			return other
+		}
+	}
+	return nil
+}
+
 // BroadcastVisibility sends you and related users arrival/departure
 // notifications that reflect your buddy list and privacy preferences.
 //
@@ -373,6 +373,13 @@ func TestBuddyService_BroadcastDeparture(t *testing.T) {
 			name:        "happy path",
 			userSession: newTestSession("me"),
 			mockParams: mockParams{
+				sessionRetrieverParams: sessionRetrieverParams{
+					retrieveSessionsParams: retrieveSessionsParams{
+						{
+							screenName: state.NewIdentScreenName("me"),
+						},
+					},
+				},
 				relationshipFetcherParams: relationshipFetcherParams{
 					allRelationshipsParams: allRelationshipsParams{
 						{
@@ -448,6 +455,146 @@ func TestBuddyService_BroadcastDeparture(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "user is still signed on from another client, send their arrival instead",
+			userSession: newTestSession("me"),
+			mockParams: mockParams{
+				sessionRetrieverParams: sessionRetrieverParams{
+					retrieveSessionsParams: retrieveSessionsParams{
+						{
+							screenName: state.NewIdentScreenName("me"),
+							result: []*state.Session{
+								newTestSession("me"),
+							},
+						},
+						{
+							screenName: state.NewIdentScreenName("me"),
+							result: []*state.Session{
+								newTestSession("me"),
+							},
+						},
+					},
+				},
+				bartItemManagerParams: bartItemManagerParams{
+					buddyIconMetadataParams: buddyIconMetadataParams{
+						{
+							screenName: state.NewIdentScreenName("me"),
+						},
+					},
+				},
+				relationshipFetcherParams: relationshipFetcherParams{
+					allRelationshipsParams: allRelationshipsParams{
+						{
+							screenName: state.NewIdentScreenName("me"),
+							filter:     nil,
+							result: []state.Relationship{
+								{
+									User:          state.NewIdentScreenName("friend1-visible"),
+									IsOnYourList:  true,
+									IsOnTheirList: true,
+								},
+							},
+						},
+					},
+				},
+				messageRelayerParams: messageRelayerParams{
+					relayToScreenNamesParams: relayToScreenNamesParams{
+						{
+							screenNames: []state.IdentScreenName{
+								state.NewIdentScreenName("friend1-visible"),
+							},
+							message: newBuddyArrivedNotif(newTestSession("me").TLVUserInfo()),
+						},
+					},
+				},
+			},
+		},
+		{
+			name:        "other client signs off while its arrival is sent, send departure after all",
+			userSession: newTestSession("me"),
+			mockParams: mockParams{
+				sessionRetrieverParams: sessionRetrieverParams{
+					retrieveSessionsParams: retrieveSessionsParams{
+						{
+							screenName: state.NewIdentScreenName("me"),
+							result: []*state.Session{
+								newTestSession("me"),
+							},
+						},
+						{
+							screenName: state.NewIdentScreenName("me"),
+							result:     []*state.Session{},
+						},
+					},
+				},
+				bartItemManagerParams: bartItemManagerParams{
+					buddyIconMetadataParams: buddyIconMetadataParams{
+						{
+							screenName: state.NewIdentScreenName("me"),
+						},
+					},
+				},
+				relationshipFetcherParams: relationshipFetcherParams{
+					allRelationshipsParams: allRelationshipsParams{
+						{
+							screenName: state.NewIdentScreenName("me"),
+							filter:     nil,
+							result: []state.Relationship{
+								{
+									User:          state.NewIdentScreenName("friend1-visible"),
+									IsOnYourList:  true,
+									IsOnTheirList: true,
+								},
+							},
+						},
+						{
+							screenName: state.NewIdentScreenName("me"),
+							filter:     nil,
+							result: []state.Relationship{
+								{
+									User:          state.NewIdentScreenName("friend1-visible"),
+									IsOnYourList:  true,
+									IsOnTheirList: true,
+								},
+							},
+						},
+					},
+				},
+				messageRelayerParams: messageRelayerParams{
+					relayToScreenNamesParams: relayToScreenNamesParams{
+						{
+							screenNames: []state.IdentScreenName{
+								state.NewIdentScreenName("friend1-visible"),
+							},
+							message: newBuddyArrivedNotif(newTestSession("me").TLVUserInfo()),
+						},
+						{
+							screenNames: []state.IdentScreenName{
+								state.NewIdentScreenName("friend1-visible"),
+							},
+							message: wire.SNACMessage{
+								Frame: wire.SNACFrame{
+									FoodGroup: wire.Buddy,
+									SubGroup:  wire.BuddyDeparted,
+									RequestID: wire.ReqIDFromServer,
+								},
+								Body: wire.SNAC_0x03_0x0C_BuddyDeparted{
+									TLVUserInfo: wire.TLVUserInfo{
+										ScreenName:   "me",
+										WarningLevel: 0,
+										TLVBlock: wire.TLVBlock{
+											TLVList: wire.TLVList{
+												wire.NewTLVBE(wire.OServiceUserInfoUserFlags, uint16(0)),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -470,10 +617,19 @@ func TestBuddyService_BroadcastDeparture(t *testing.T) {
 				messageRelayer.EXPECT().
 					RelayToScreenNames(mock.Anything, params.screenNames, params.message)
 			}
+			sessionRetriever := newMockSessionRetriever(t)
+			for _, params := range tc.mockParams.retrieveSessionsParams {
+				sessionRetriever.EXPECT().
+					RetrieveSessions(params.screenName).
+					Return(params.result).
+					Once()
+			}
 
 			svc := buddyNotifier{
+				bartItemManager:     bartItemManager,
 				relationshipFetcher: relationshipFetcher,
 				messageRelayer:      messageRelayer,
+				sessionRetriever:    sessionRetriever,
 			}
 
 			err := svc.BroadcastBuddyDeparted(context.Background(), tc.userSession)
@@ -173,7 +173,8 @@ func (s FeedbagService) QueryIfModified(ctx context.Context, sess *state.Session
 // confirmation.
 // UpdateItem updates items in the user's feedbag (aka buddy list). Sends user
 // buddy arrival notifications for each online & visible buddy added to the
-// feedbag. It returns wire.FeedbagStatus, which contains update confirmation.
+// feedbag. The change is relayed to the user's other signed-on clients. It
+// returns wire.FeedbagStatus, which contains update confirmation.
 func (s FeedbagService) UpsertItem(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, items []wire.FeedbagItem) (wire.SNACMessage, error) {
 	for _, item := range items {
 		// don't let users block themselves, it causes the AIM client to go
@@ -219,6 +220,21 @@ func (s FeedbagService) UpsertItem(ctx context.Context, sess *state.Session, inF
 		}
 	}
 
+	// keep the user's other signed-on clients in sync
+	syncMsg := wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.Feedbag,
+			SubGroup:  wire.FeedbagInsertItem,
+			RequestID: wire.ReqIDFromServer,
+		},
+		Body: wire.SNAC_0x13_0x08_FeedbagInsertItem{Items: items},
+	}
+	if inFrame.SubGroup == wire.FeedbagUpdateItem {
+		syncMsg.Frame.SubGroup = wire.FeedbagUpdateItem
+		syncMsg.Body = wire.SNAC_0x13_0x09_FeedbagUpdateItem{Items: items}
+	}
+	s.messageRelayer.RelayToOtherInstances(ctx, sess, syncMsg)
+
 	snacPayloadOut := wire.SNAC_0x13_0x0E_FeedbagStatus{}
 	for range items {
 		snacPayloadOut.Results = append(snacPayloadOut.Results, 0x0000)
@@ -293,7 +309,8 @@ func (s FeedbagService) broadcastIconUpdate(ctx context.Context, sess *state.Ses
 // DeleteItem removes items from feedbag (aka buddy list). Sends user buddy
 // arrival notifications for each online & visible buddy added to the feedbag.
 // Sends buddy arrival notifications to each unblocked buddy if current user is
-// visible. It returns wire.FeedbagStatus, which contains update confirmation.
+// visible. The change is relayed to the user's other signed-on clients. It
+// returns wire.FeedbagStatus, which contains update confirmation.
 func (s FeedbagService) DeleteItem(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x13_0x0A_FeedbagDeleteItem) (wire.SNACMessage, error) {
 	if err := s.feedbagManager.FeedbagDelete(ctx, sess.IdentScreenName(), inBody.Items); err != nil {
 		return wire.SNACMessage{}, err
@@ -312,6 +329,16 @@ func (s FeedbagService) DeleteItem(ctx context.Context, sess *state.Session, inF
 		return wire.SNACMessage{}, err
 	}
 
+	// keep the user's other signed-on clients in sync
+	s.messageRelayer.RelayToOtherInstances(ctx, sess, wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.Feedbag,
+			SubGroup:  wire.FeedbagDeleteItem,
+			RequestID: wire.ReqIDFromServer,
+		},
+		Body: inBody,
+	})
+
 	snacPayloadOut := wire.SNAC_0x13_0x0E_FeedbagStatus{}
 	for range inBody.Items {
 		snacPayloadOut.Results = append(snacPayloadOut.Results, 0x0000) // success by default
@@ -999,6 +999,18 @@ func TestFeedbagService_UpsertItem(t *testing.T) {
 				messageRelayer.EXPECT().
 					RelayToScreenName(mock.Anything, params.screenName, params.message)
 			}
+			if tc.expectOutput.Frame.SubGroup == wire.FeedbagStatus {
+				// successful changes are synced to the user's other clients
+				messageRelayer.EXPECT().
+					RelayToOtherInstances(matchContext(), tc.userSession, wire.SNACMessage{
+						Frame: wire.SNACFrame{
+							FoodGroup: wire.Feedbag,
+							SubGroup:  wire.FeedbagInsertItem,
+							RequestID: wire.ReqIDFromServer,
+						},
+						Body: tc.inputSNAC.Body,
+					})
+			}
 			bartItemManager := newMockBARTItemManager(t)
 			for _, params := range tc.mockParams.bartItemManagerParams.bartItemManagerRetrieveParams {
 				bartItemManager.EXPECT().
@@ -1130,10 +1142,21 @@ func TestFeedbagService_DeleteItem(t *testing.T) {
 					Return(params.err)
 			}
 
+			messageRelayer := newMockMessageRelayer(t)
+			messageRelayer.EXPECT().
+				RelayToOtherInstances(matchContext(), tc.userSession, wire.SNACMessage{
+					Frame: wire.SNACFrame{
+						FoodGroup: wire.Feedbag,
+						SubGroup:  wire.FeedbagDeleteItem,
+						RequestID: wire.ReqIDFromServer,
+					},
+					Body: tc.inputSNAC.Body,
+				})
+
 			svc := FeedbagService{
 				buddyBroadcaster: buddyUpdateBroadcast,
 				feedbagManager:   feedbagManager,
-				messageRelayer:   nil,
+				messageRelayer:   messageRelayer,
 			}
 			output, err := svc.DeleteItem(context.Background(), tc.userSession, tc.inputSNAC.Frame,
 				tc.inputSNAC.Body.(wire.SNAC_0x13_0x0A_FeedbagDeleteItem))
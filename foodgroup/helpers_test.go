@@ -25,6 +25,7 @@ type mockParams struct {
 	feedbagManagerParams
 	icqUserFinderParams
 	icqUserUpdaterParams
+	imArchiverParams
 	clientSideBuddyListManagerParams
 	messageRelayerParams
 	offlineMessageManagerParams
@@ -63,6 +64,7 @@ type relationshipParams []struct {
 // OfflineMessageManager methods
 type offlineMessageManagerParams struct {
 	deleteMessagesParams
+	deleteMessagesByIDParams
 	retrieveMessagesParams
 	saveMessageParams
 }
@@ -74,6 +76,14 @@ type deleteMessagesParams []struct {
 	err     error
 }
 
+// deleteMessagesByIDParams is the list of parameters passed at the mock
+// OfflineMessageManager.DeleteMessagesByID call site
+type deleteMessagesByIDParams []struct {
+	recipIn state.IdentScreenName
+	idsIn   []int64
+	err     error
+}
+
 // deleteMessagesParams is the list of parameters passed at the mock
 // OfflineMessageManager.RetrieveMessages call site
 type retrieveMessagesParams []struct {
@@ -86,13 +96,28 @@ type retrieveMessagesParams []struct {
 // OfflineMessageManager.SaveMessage call site
 type saveMessageParams []struct {
 	offlineMessageIn state.OfflineMessage
+	maxQueuedIn      int
 	err              error
 }
 
+// imArchiverParams is a helper struct that contains mock parameters for
+// IMArchiver methods
+type imArchiverParams struct {
+	archiveIMParams
+}
+
+// archiveIMParams is the list of parameters passed at the mock
+// IMArchiver.ArchiveIM call site
+type archiveIMParams []struct {
+	im  state.ArchivedIM
+	err error
+}
+
 // sessionRetrieverParams is a helper struct that contains mock parameters for
 // SessionRetriever methods
 type sessionRetrieverParams struct {
 	retrieveSessionParams
+	retrieveSessionsParams
 }
 
 // retrieveSessionParams is the list of parameters passed at the mock
@@ -102,6 +127,13 @@ type retrieveSessionParams []struct {
 	result     *state.Session
 }
 
+// retrieveSessionsParams is the list of parameters passed at the mock
+// SessionRetriever.RetrieveSessions call site
+type retrieveSessionsParams []struct {
+	screenName state.IdentScreenName
+	result     []*state.Session
+}
+
 // icqUserFinderParams is a helper struct that contains mock parameters for
 // ICQUserFinder methods
 type icqUserFinderParams struct {
@@ -251,6 +283,8 @@ type buddyIconMetadataParams []struct {
 type userManagerParams struct {
 	getUserParams
 	insertUserParams
+	setPasswordHashParams
+	validateSecondFactorParams
 }
 
 // getUserParams is the list of parameters passed at the mock
@@ -268,17 +302,36 @@ type insertUserParams []struct {
 	err  error
 }
 
+// setPasswordHashParams is the list of parameters passed at the mock
+// UserManager.SetPasswordHash call site
+type setPasswordHashParams []struct {
+	screenName state.IdentScreenName
+	password   string
+	err        error
+}
+
+// validateSecondFactorParams is the list of parameters passed at the mock
+// UserManager.ValidateSecondFactor call site
+type validateSecondFactorParams []struct {
+	screenName state.IdentScreenName
+	code       string
+	result     bool
+	err        error
+}
+
 // sessionRegistryParams is a helper struct that contains mock parameters for
 // SessionRegistry methods
 type sessionRegistryParams struct {
 	addSessionParams
 	removeSessionParams
+	signoffSessionParams
 }
 
 // addSessionParams is the list of parameters passed at the mock
 // SessionRegistry.AddSession call site
 type addSessionParams []struct {
 	screenName state.DisplayScreenName
+	multiSess  bool
 	result     *state.Session
 	err        error
 }
@@ -289,6 +342,13 @@ type removeSessionParams []struct {
 	screenName state.IdentScreenName
 }
 
+// signoffSessionParams is the list of parameters passed at the mock
+// SessionRegistry.SignoffSession call site
+type signoffSessionParams []struct {
+	screenName   state.IdentScreenName
+	lastInstance bool
+}
+
 // feedbagManagerParams is a helper struct that contains mock parameters for
 // FeedbagManager methods
 type feedbagManagerParams struct {
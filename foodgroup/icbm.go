@@ -3,6 +3,7 @@ package foodgroup
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -21,29 +22,35 @@ const (
 	rateDecayInterval = 5 * time.Minute
 )
 
-// NewICBMService returns a new instance of ICBMService.
+// NewICBMService returns a new instance of ICBMService. If imArchiver is
+// nil, instant messages aren't archived. Up to maxOfflineMsgs offline
+// messages are queued for each recipient, or any number if it's 0.
 func NewICBMService(
 	bartItemManager BARTItemManager,
+	imArchiver IMArchiver,
 	messageRelayer MessageRelayer,
-	offlineMessageSaver OfflineMessageManager,
+	offlineMessageManager OfflineMessageManager,
 	relationshipFetcher RelationshipFetcher,
 	sessionRetriever SessionRetriever,
 	userManager UserManager,
 	snacRateLimits wire.SNACRateLimits,
+	maxOfflineMsgs int,
 	logger *slog.Logger,
 ) *ICBMService {
 	return &ICBMService{
-		relationshipFetcher: relationshipFetcher,
-		buddyBroadcaster:    newBuddyNotifier(bartItemManager, relationshipFetcher, messageRelayer, sessionRetriever),
-		messageRelayer:      messageRelayer,
-		offlineMessageSaver: offlineMessageSaver,
-		userManager:         userManager,
-		timeNow:             time.Now,
-		sessionRetriever:    sessionRetriever,
-		snacRateLimits:      snacRateLimits,
-		convoTracker:        newConvoTracker(),
-		logger:              logger,
-		interval:            rateDecayInterval,
+		relationshipFetcher:   relationshipFetcher,
+		buddyBroadcaster:      newBuddyNotifier(bartItemManager, relationshipFetcher, messageRelayer, sessionRetriever),
+		imArchiver:            imArchiver,
+		messageRelayer:        messageRelayer,
+		offlineMessageManager: offlineMessageManager,
+		maxOfflineMsgs:        maxOfflineMsgs,
+		userManager:           userManager,
+		timeNow:               time.Now,
+		sessionRetriever:      sessionRetriever,
+		snacRateLimits:        snacRateLimits,
+		convoTracker:          newConvoTracker(),
+		logger:                logger,
+		interval:              rateDecayInterval,
 	}
 }
 
@@ -51,17 +58,19 @@ func NewICBMService(
 // responsible for sending and receiving instant messages and associated
 // functionality such as warning, typing events, etc.
 type ICBMService struct {
-	relationshipFetcher RelationshipFetcher
-	buddyBroadcaster    buddyBroadcaster
-	messageRelayer      MessageRelayer
-	offlineMessageSaver OfflineMessageManager
-	userManager         UserManager
-	timeNow             func() time.Time
-	sessionRetriever    SessionRetriever
-	snacRateLimits      wire.SNACRateLimits
-	convoTracker        *convoTracker
-	logger              *slog.Logger
-	interval            time.Duration
+	relationshipFetcher   RelationshipFetcher
+	buddyBroadcaster      buddyBroadcaster
+	imArchiver            IMArchiver
+	messageRelayer        MessageRelayer
+	offlineMessageManager OfflineMessageManager
+	maxOfflineMsgs        int
+	userManager           UserManager
+	timeNow               func() time.Time
+	sessionRetriever      SessionRetriever
+	snacRateLimits        wire.SNACRateLimits
+	convoTracker          *convoTracker
+	logger                *slog.Logger
+	interval              time.Duration
 }
 
 // ParameterQuery returns ICBM service parameters.
@@ -117,7 +126,6 @@ func (s ICBMService) ChannelMsgToHost(ctx context.Context, sess *state.Session,
 
 	recipSess := s.sessionRetriever.RetrieveSession(recip)
 	if recipSess == nil {
-		// todo: verify user exists, otherwise this could save a bunch of garbage records
 		if _, saveOffline := inBody.Bytes(wire.ICBMTLVStore); saveOffline {
 			offlineMsg := state.OfflineMessage{
 				Message:   inBody,
@@ -125,8 +133,16 @@ func (s ICBMService) ChannelMsgToHost(ctx context.Context, sess *state.Session,
 				Sender:    sess.IdentScreenName(),
 				Sent:      s.timeNow().UTC(),
 			}
-			if err := s.offlineMessageSaver.SaveMessage(ctx, offlineMsg); err != nil {
+			err := s.offlineMessageManager.SaveMessage(ctx, offlineMsg, s.maxOfflineMsgs)
+			switch {
+			case errors.Is(err, state.ErrNoUser):
+				// there's nobody to deliver the message to
+			case errors.Is(err, state.ErrOfflineQueueFull):
+				return newICBMErr(inFrame.RequestID, wire.ErrorCodeQueueFull), nil
+			case err != nil:
 				return nil, fmt.Errorf("save ICBM offline message failed: %w", err)
+			default:
+				s.archiveIM(ctx, sess.IdentScreenName(), recip, inBody)
 			}
 		}
 		return &wire.SNACMessage{
@@ -177,6 +193,7 @@ func (s ICBMService) ChannelMsgToHost(ctx context.Context, sess *state.Session,
 	})
 
 	s.convoTracker.trackConvo(time.Now(), sess.IdentScreenName(), recipSess.IdentScreenName())
+	s.archiveIM(ctx, sess.IdentScreenName(), recipSess.IdentScreenName(), inBody)
 
 	if _, requestedConfirmation := inBody.TLVRestBlock.Bytes(wire.ICBMTLVRequestHostAck); !requestedConfirmation {
 		// don't ack message
@@ -198,6 +215,126 @@ func (s ICBMService) ChannelMsgToHost(ctx context.Context, sess *state.Session,
 	}, nil
 }
 
+// archiveIM records the text of an instant message in the IM archive, if
+// archiving is enabled. Failures are logged rather than returned, because
+// the message has already been delivered.
+func (s ICBMService) archiveIM(ctx context.Context, sender, recip state.IdentScreenName, inBody wire.SNAC_0x04_0x06_ICBMChannelMsgToHost) {
+	if s.imArchiver == nil || inBody.ChannelID != wire.ICBMChannelIM {
+		return
+	}
+	payload, hasIM := inBody.Bytes(wire.ICBMTLVAOLIMData)
+	if !hasIM {
+		return
+	}
+	text, err := wire.UnmarshalICBMMessageText(payload)
+	if err != nil {
+		s.logger.DebugContext(ctx, "unable to extract IM text for archive", "err", err.Error())
+		return
+	}
+	im := state.ArchivedIM{
+		SentAt:    s.timeNow().UTC(),
+		Sender:    sender,
+		Recipient: recip,
+		Text:      text,
+	}
+	if err := s.imArchiver.ArchiveIM(ctx, im); err != nil {
+		s.logger.ErrorContext(ctx, "unable to archive IM", "err", err.Error())
+	}
+}
+
+// OfflineRetrieve handles the wire.ICBMSinRetrieve SNAC, which AIM 6+
+// clients send after signing on to fetch the messages they were sent while
+// offline. The messages are sent to the client, followed by
+// wire.ICBMSinReply.
+func (s ICBMService) OfflineRetrieve(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame) error {
+	if err := s.DeliverOfflineMessages(ctx, sess); err != nil {
+		return err
+	}
+	// relay the reply rather than returning it so that it's sent after the
+	// messages
+	s.messageRelayer.RelayToScreenName(ctx, sess.IdentScreenName(), wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.ICBM,
+			SubGroup:  wire.ICBMSinReply,
+			RequestID: inFrame.RequestID,
+		},
+		Body: wire.SNAC_0x04_0x17_ICBMSinReply{},
+	})
+	return nil
+}
+
+// DeliverOfflineMessages sends the offline messages queued for the session's
+// user to the session as instant messages, then removes the delivered ones
+// from the queue. TOC and Web AIM clients, which can't ask for their offline
+// messages, receive them this way when they sign on.
+//
+// Delivery stops at the first message the session doesn't accept, for
+// example because its outbound queue is full, so that it and the messages
+// after it stay queued for the next sign-on. Messages queued while delivery
+// is under way are left for the next sign-on as well.
+func (s ICBMService) DeliverOfflineMessages(ctx context.Context, sess *state.Session) error {
+	messages, err := s.offlineMessageManager.RetrieveMessages(ctx, sess.IdentScreenName())
+	if err != nil {
+		return fmt.Errorf("retrieving offline messages: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	delivered := make([]int64, 0, len(messages))
+	for _, msg := range messages {
+		clientIM := wire.SNAC_0x04_0x07_ICBMChannelMsgToClient{
+			Cookie:    msg.Message.Cookie,
+			ChannelID: msg.Message.ChannelID,
+			TLVUserInfo: wire.TLVUserInfo{
+				ScreenName: s.displayScreenName(ctx, msg.Sender).String(),
+			},
+			TLVRestBlock: wire.TLVRestBlock{},
+		}
+		for _, tlv := range msg.Message.TLVList {
+			if tlv.Tag == wire.ICBMTLVRequestHostAck || tlv.Tag == wire.ICBMTLVStore {
+				continue
+			}
+			clientIM.Append(tlv)
+		}
+		// tell the client when the message was sent
+		clientIM.Append(wire.NewTLVBE(wire.ICBMTLVSendTime, uint32(msg.Sent.Unix())))
+
+		status := sess.RelayMessage(wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.ICBM,
+				SubGroup:  wire.ICBMChannelMsgToClient,
+				RequestID: wire.ReqIDFromServer,
+			},
+			Body: clientIM,
+		})
+		if status != state.SessSendOK {
+			s.logger.WarnContext(ctx, "unable to deliver offline message, keeping it queued",
+				"recipient", sess.IdentScreenName(), "status", status)
+			break
+		}
+		delivered = append(delivered, msg.ID)
+	}
+
+	if err := s.offlineMessageManager.DeleteMessagesByID(ctx, sess.IdentScreenName(), delivered); err != nil {
+		return fmt.Errorf("deleting offline messages: %w", err)
+	}
+	return nil
+}
+
+// displayScreenName returns the display screen name of the user, falling
+// back to their identifier if the user can't be found.
+func (s ICBMService) displayScreenName(ctx context.Context, screenName state.IdentScreenName) state.DisplayScreenName {
+	user, err := s.userManager.User(ctx, screenName)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "unable to look up offline message sender", "err", err.Error())
+	}
+	if user == nil {
+		return state.DisplayScreenName(screenName.String())
+	}
+	return user.DisplayScreenName
+}
+
 // addExternalIP appends the client's IP address to the TLV if it's an ICBM
 // rendezvous proposal/accept message.
 func addExternalIP(sess *state.Session, tlv wire.TLV) (wire.TLV, error) {
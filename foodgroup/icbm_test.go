@@ -18,6 +18,9 @@ import (
 )
 
 func TestICBMService_ChannelMsgToHost(t *testing.T) {
+	imText, err := wire.ICBMFragmentList("<HTML>hello</HTML>")
+	assert.NoError(t, err)
+
 	cases := []struct {
 		// name is the unit test name
 		name string
@@ -33,6 +36,8 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 		mockParams mockParams
 		// timeNow returns the current time
 		timeNow func() time.Time
+		// maxOfflineMsgs is the offline message limit for each recipient
+		maxOfflineMsgs int
 	}{
 		{
 			name:          "transmit message from sender to recipient, ack message back to sender",
@@ -54,7 +59,7 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     newTestSession("recipient-screen-name", sessOptWarning(20)),
@@ -145,7 +150,7 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     newTestSession("recipient-screen-name", sessOptWarning(20)),
@@ -222,7 +227,7 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     newTestSession("recipient-screen-name", sessOptWarning(20)),
@@ -295,7 +300,7 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{},
+					retrieveSessionParams: retrieveSessionParams{},
 				},
 				messageRelayerParams: messageRelayerParams{
 					relayToScreenNameParams: relayToScreenNameParams{},
@@ -349,7 +354,7 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{},
+					retrieveSessionParams: retrieveSessionParams{},
 				},
 				messageRelayerParams: messageRelayerParams{
 					relayToScreenNameParams: relayToScreenNameParams{},
@@ -403,7 +408,7 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     nil,
@@ -490,7 +495,7 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("22222222"),
 							result:     nil,
@@ -523,6 +528,325 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:           "send offline message to user that doesn't exist, don't archive message text",
+			senderSession:  newTestSession("sender-screen-name"),
+			maxOfflineMsgs: 10,
+			timeNow: func() time.Time {
+				return time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC)
+			},
+			mockParams: mockParams{
+				relationshipFetcherParams: relationshipFetcherParams{
+					relationshipParams: relationshipParams{
+						{
+							me:   state.NewIdentScreenName("sender-screen-name"),
+							them: state.NewIdentScreenName("recipient-screen-name"),
+							result: state.Relationship{
+								User: state.NewIdentScreenName("recipient-screen-name"),
+							},
+						},
+					},
+				},
+				sessionRetrieverParams: sessionRetrieverParams{
+					retrieveSessionParams: retrieveSessionParams{
+						{
+							screenName: state.NewIdentScreenName("recipient-screen-name"),
+							result:     nil,
+						},
+					},
+				},
+				offlineMessageManagerParams: offlineMessageManagerParams{
+					saveMessageParams: saveMessageParams{
+						{
+							offlineMessageIn: state.OfflineMessage{
+								Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+									ChannelID:  wire.ICBMChannelIM,
+									ScreenName: "recipient-screen-name",
+									TLVRestBlock: wire.TLVRestBlock{
+										TLVList: wire.TLVList{
+											wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+											wire.NewTLVBE(wire.ICBMTLVStore, []byte{}),
+										},
+									},
+								},
+								Recipient: state.NewIdentScreenName("recipient-screen-name"),
+								Sender:    state.NewIdentScreenName("sender-screen-name"),
+								Sent:      time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC),
+							},
+							maxQueuedIn: 10,
+							err:         state.ErrNoUser,
+						},
+					},
+				},
+			},
+			inputSNAC: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					RequestID: 1234,
+				},
+				Body: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+					ChannelID:  wire.ICBMChannelIM,
+					ScreenName: "recipient-screen-name",
+					TLVRestBlock: wire.TLVRestBlock{
+						TLVList: wire.TLVList{
+							wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+							wire.NewTLVBE(wire.ICBMTLVStore, []byte{}),
+						},
+					},
+				},
+			},
+			expectOutput: &wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.ICBM,
+					SubGroup:  wire.ICBMErr,
+					RequestID: 1234,
+				},
+				Body: wire.SNACError{
+					Code: wire.ErrorCodeNotLoggedOn,
+				},
+			},
+		},
+		{
+			name:           "send offline message to recipient with full queue",
+			senderSession:  newTestSession("sender-screen-name"),
+			maxOfflineMsgs: 10,
+			timeNow: func() time.Time {
+				return time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC)
+			},
+			mockParams: mockParams{
+				relationshipFetcherParams: relationshipFetcherParams{
+					relationshipParams: relationshipParams{
+						{
+							me:   state.NewIdentScreenName("sender-screen-name"),
+							them: state.NewIdentScreenName("recipient-screen-name"),
+							result: state.Relationship{
+								User: state.NewIdentScreenName("recipient-screen-name"),
+							},
+						},
+					},
+				},
+				sessionRetrieverParams: sessionRetrieverParams{
+					retrieveSessionParams: retrieveSessionParams{
+						{
+							screenName: state.NewIdentScreenName("recipient-screen-name"),
+							result:     nil,
+						},
+					},
+				},
+				offlineMessageManagerParams: offlineMessageManagerParams{
+					saveMessageParams: saveMessageParams{
+						{
+							offlineMessageIn: state.OfflineMessage{
+								Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+									ChannelID:  wire.ICBMChannelIM,
+									ScreenName: "recipient-screen-name",
+									TLVRestBlock: wire.TLVRestBlock{
+										TLVList: wire.TLVList{
+											wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+											wire.NewTLVBE(wire.ICBMTLVStore, []byte{}),
+										},
+									},
+								},
+								Recipient: state.NewIdentScreenName("recipient-screen-name"),
+								Sender:    state.NewIdentScreenName("sender-screen-name"),
+								Sent:      time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC),
+							},
+							maxQueuedIn: 10,
+							err:         state.ErrOfflineQueueFull,
+						},
+					},
+				},
+			},
+			inputSNAC: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					RequestID: 1234,
+				},
+				Body: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+					ChannelID:  wire.ICBMChannelIM,
+					ScreenName: "recipient-screen-name",
+					TLVRestBlock: wire.TLVRestBlock{
+						TLVList: wire.TLVList{
+							wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+							wire.NewTLVBE(wire.ICBMTLVStore, []byte{}),
+						},
+					},
+				},
+			},
+			expectOutput: &wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.ICBM,
+					SubGroup:  wire.ICBMErr,
+					RequestID: 1234,
+				},
+				Body: wire.SNACError{
+					Code: wire.ErrorCodeQueueFull,
+				},
+			},
+		},
+		{
+			name:          "transmit message from sender to recipient, archive message text",
+			senderSession: newTestSession("sender-screen-name"),
+			timeNow: func() time.Time {
+				return time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC)
+			},
+			mockParams: mockParams{
+				relationshipFetcherParams: relationshipFetcherParams{
+					relationshipParams: relationshipParams{
+						{
+							me:   state.NewIdentScreenName("sender-screen-name"),
+							them: state.NewIdentScreenName("recipient-screen-name"),
+							result: state.Relationship{
+								User: state.NewIdentScreenName("recipient-screen-name"),
+							},
+						},
+					},
+				},
+				sessionRetrieverParams: sessionRetrieverParams{
+					retrieveSessionParams: retrieveSessionParams{
+						{
+							screenName: state.NewIdentScreenName("recipient-screen-name"),
+							result:     newTestSession("recipient-screen-name"),
+						},
+					},
+				},
+				messageRelayerParams: messageRelayerParams{
+					relayToScreenNameParams: relayToScreenNameParams{
+						{
+							screenName: state.NewIdentScreenName("recipient-screen-name"),
+							message: wire.SNACMessage{
+								Frame: wire.SNACFrame{
+									FoodGroup: wire.ICBM,
+									SubGroup:  wire.ICBMChannelMsgToClient,
+									RequestID: wire.ReqIDFromServer,
+								},
+								Body: wire.SNAC_0x04_0x07_ICBMChannelMsgToClient{
+									ChannelID:   wire.ICBMChannelIM,
+									TLVUserInfo: newTestSession("sender-screen-name").TLVUserInfo(),
+									TLVRestBlock: wire.TLVRestBlock{
+										TLVList: wire.TLVList{
+											wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				imArchiverParams: imArchiverParams{
+					archiveIMParams: archiveIMParams{
+						{
+							im: state.ArchivedIM{
+								SentAt:    time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC),
+								Sender:    state.NewIdentScreenName("sender-screen-name"),
+								Recipient: state.NewIdentScreenName("recipient-screen-name"),
+								Text:      "<HTML>hello</HTML>",
+							},
+						},
+					},
+				},
+			},
+			inputSNAC: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					RequestID: 1234,
+				},
+				Body: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+					ChannelID:  wire.ICBMChannelIM,
+					ScreenName: "recipient-screen-name",
+					TLVRestBlock: wire.TLVRestBlock{
+						TLVList: wire.TLVList{
+							wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+						},
+					},
+				},
+			},
+			expectOutput: nil,
+		},
+		{
+			name:          "send offline message, archive message text despite archive failure",
+			senderSession: newTestSession("sender-screen-name"),
+			timeNow: func() time.Time {
+				return time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC)
+			},
+			mockParams: mockParams{
+				relationshipFetcherParams: relationshipFetcherParams{
+					relationshipParams: relationshipParams{
+						{
+							me:   state.NewIdentScreenName("sender-screen-name"),
+							them: state.NewIdentScreenName("recipient-screen-name"),
+							result: state.Relationship{
+								User: state.NewIdentScreenName("recipient-screen-name"),
+							},
+						},
+					},
+				},
+				sessionRetrieverParams: sessionRetrieverParams{
+					retrieveSessionParams: retrieveSessionParams{
+						{
+							screenName: state.NewIdentScreenName("recipient-screen-name"),
+							result:     nil,
+						},
+					},
+				},
+				offlineMessageManagerParams: offlineMessageManagerParams{
+					saveMessageParams: saveMessageParams{
+						{
+							offlineMessageIn: state.OfflineMessage{
+								Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+									ChannelID:  wire.ICBMChannelIM,
+									ScreenName: "recipient-screen-name",
+									TLVRestBlock: wire.TLVRestBlock{
+										TLVList: wire.TLVList{
+											wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+											wire.NewTLVBE(wire.ICBMTLVStore, []byte{}),
+										},
+									},
+								},
+								Recipient: state.NewIdentScreenName("recipient-screen-name"),
+								Sender:    state.NewIdentScreenName("sender-screen-name"),
+								Sent:      time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC),
+							},
+						},
+					},
+				},
+				imArchiverParams: imArchiverParams{
+					archiveIMParams: archiveIMParams{
+						{
+							im: state.ArchivedIM{
+								SentAt:    time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC),
+								Sender:    state.NewIdentScreenName("sender-screen-name"),
+								Recipient: state.NewIdentScreenName("recipient-screen-name"),
+								Text:      "<HTML>hello</HTML>",
+							},
+							err: errors.New("database is locked"),
+						},
+					},
+				},
+			},
+			inputSNAC: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					RequestID: 1234,
+				},
+				Body: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+					ChannelID:  wire.ICBMChannelIM,
+					ScreenName: "recipient-screen-name",
+					TLVRestBlock: wire.TLVRestBlock{
+						TLVList: wire.TLVList{
+							wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+							wire.NewTLVBE(wire.ICBMTLVStore, []byte{}),
+						},
+					},
+				},
+			},
+			expectOutput: &wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.ICBM,
+					SubGroup:  wire.ICBMErr,
+					RequestID: 1234,
+				},
+				Body: wire.SNACError{
+					Code: wire.ErrorCodeNotLoggedOn,
+				},
+			},
+		},
 		{
 			name: "send rendezvous request for file transfer, expect IP TLV override",
 			senderSession: newTestSession("sender-screen-name", sessOptWarning(10),
@@ -544,7 +868,7 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     newTestSession("recipient-screen-name", sessOptWarning(20)),
@@ -631,7 +955,7 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     newTestSession("recipient-screen-name", sessOptWarning(20)),
@@ -704,7 +1028,7 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     newTestSession("recipient-screen-name", sessOptWarning(20)),
@@ -793,17 +1117,27 @@ func TestICBMService_ChannelMsgToHost(t *testing.T) {
 			offlineMessageManager := newMockOfflineMessageManager(t)
 			for _, params := range tc.mockParams.saveMessageParams {
 				offlineMessageManager.EXPECT().
-					SaveMessage(matchContext(), params.offlineMessageIn).
+					SaveMessage(matchContext(), params.offlineMessageIn, params.maxQueuedIn).
+					Return(params.err)
+			}
+
+			imArchiver := newMockIMArchiver(t)
+			for _, params := range tc.mockParams.archiveIMParams {
+				imArchiver.EXPECT().
+					ArchiveIM(matchContext(), params.im).
 					Return(params.err)
 			}
 
 			svc := ICBMService{
-				relationshipFetcher: relationshipFetcher,
-				messageRelayer:      messageRelayer,
-				offlineMessageSaver: offlineMessageManager,
-				sessionRetriever:    sessionRetriever,
-				timeNow:             tc.timeNow,
-				convoTracker:        newConvoTracker(),
+				relationshipFetcher:   relationshipFetcher,
+				imArchiver:            imArchiver,
+				messageRelayer:        messageRelayer,
+				offlineMessageManager: offlineMessageManager,
+				maxOfflineMsgs:        tc.maxOfflineMsgs,
+				sessionRetriever:      sessionRetriever,
+				timeNow:               tc.timeNow,
+				convoTracker:          newConvoTracker(),
+				logger:                slog.Default(),
 			}
 
 			outputSNAC, err := svc.ChannelMsgToHost(context.Background(), tc.senderSession, tc.inputSNAC.Frame,
@@ -996,7 +1330,7 @@ func TestICBMService_EvilRequest(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     newTestSession("recipient-screen-name", sessOptCannedSignonTime),
@@ -1063,7 +1397,7 @@ func TestICBMService_EvilRequest(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     newTestSession("recipient-screen-name", sessOptCannedSignonTime),
@@ -1219,7 +1553,7 @@ func TestICBMService_EvilRequest(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     newTestSession("recipient-screen-name", sessOptBot),
@@ -1273,7 +1607,7 @@ func TestICBMService_EvilRequest(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     nil,
@@ -1322,7 +1656,7 @@ func TestICBMService_EvilRequest(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("recipient-screen-name"),
 							result:     nil,
@@ -1374,17 +1708,17 @@ func TestICBMService_EvilRequest(t *testing.T) {
 			offlineMessageManager := newMockOfflineMessageManager(t)
 			for _, params := range tc.mockParams.saveMessageParams {
 				offlineMessageManager.EXPECT().
-					SaveMessage(matchContext(), params.offlineMessageIn).
+					SaveMessage(matchContext(), params.offlineMessageIn, params.maxQueuedIn).
 					Return(params.err)
 			}
 
 			svc := ICBMService{
-				relationshipFetcher: relationshipFetcher,
-				messageRelayer:      messageRelayer,
-				offlineMessageSaver: offlineMessageManager,
-				sessionRetriever:    sessionRetriever,
-				convoTracker:        newConvoTracker(),
-				snacRateLimits:      wire.DefaultSNACRateLimits(),
+				relationshipFetcher:   relationshipFetcher,
+				messageRelayer:        messageRelayer,
+				offlineMessageManager: offlineMessageManager,
+				sessionRetriever:      sessionRetriever,
+				convoTracker:          newConvoTracker(),
+				snacRateLimits:        wire.DefaultSNACRateLimits(),
 			}
 
 			for i := 0; i < tc.msgsReceived; i++ {
@@ -1414,7 +1748,7 @@ func TestICBMService_EvilRequest(t *testing.T) {
 }
 
 func TestICBMService_ParameterQuery(t *testing.T) {
-	svc := NewICBMService(nil, nil, nil, nil, nil, nil, wire.DefaultSNACRateLimits(), slog.Default())
+	svc := NewICBMService(nil, nil, nil, nil, nil, nil, nil, wire.DefaultSNACRateLimits(), 0, slog.Default())
 
 	have := svc.ParameterQuery(nil, wire.SNACFrame{RequestID: 1234})
 	want := wire.SNACMessage{
@@ -1466,12 +1800,313 @@ func TestICBMService_ClientErr(t *testing.T) {
 	messageRelayer.EXPECT().
 		RelayToScreenName(mock.Anything, state.NewIdentScreenName("recipientScreenName"), expect)
 
-	svc := NewICBMService(nil, messageRelayer, nil, nil, nil, nil, wire.DefaultSNACRateLimits(), slog.Default())
+	svc := NewICBMService(nil, nil, messageRelayer, nil, nil, nil, nil, wire.DefaultSNACRateLimits(), 0, slog.Default())
 
 	err := svc.ClientErr(context.Background(), sess, wire.SNACFrame{RequestID: 1234}, inBody)
 	assert.NoError(t, err)
 }
 
+func TestICBMService_OfflineRetrieve(t *testing.T) {
+	imText, err := wire.ICBMFragmentList("<HTML>hello</HTML>")
+	assert.NoError(t, err)
+
+	sent := time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC)
+	errRetrieve := errors.New("database is locked")
+
+	cases := []struct {
+		// name is the unit test name
+		name string
+		// recipSession is the session of the user retrieving their messages
+		recipSession *state.Session
+		// wantDelivered are the messages sent to recipSession
+		wantDelivered []wire.SNACMessage
+		// mockParams is the list of params sent to mocks that satisfy this
+		// method's dependencies
+		mockParams mockParams
+		// wantErr is the expected error
+		wantErr error
+	}{
+		{
+			name:         "deliver offline messages, then reply",
+			recipSession: newTestSession("recipient-screen-name"),
+			wantDelivered: []wire.SNACMessage{
+				{
+					Frame: wire.SNACFrame{
+						FoodGroup: wire.ICBM,
+						SubGroup:  wire.ICBMChannelMsgToClient,
+						RequestID: wire.ReqIDFromServer,
+					},
+					Body: wire.SNAC_0x04_0x07_ICBMChannelMsgToClient{
+						Cookie:      1234,
+						ChannelID:   wire.ICBMChannelIM,
+						TLVUserInfo: wire.TLVUserInfo{ScreenName: "SenderSN"},
+						TLVRestBlock: wire.TLVRestBlock{
+							TLVList: wire.TLVList{
+								wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+								wire.NewTLVBE(wire.ICBMTLVSendTime, uint32(sent.Unix())),
+							},
+						},
+					},
+				},
+				{
+					Frame: wire.SNACFrame{
+						FoodGroup: wire.ICBM,
+						SubGroup:  wire.ICBMChannelMsgToClient,
+						RequestID: wire.ReqIDFromServer,
+					},
+					Body: wire.SNAC_0x04_0x07_ICBMChannelMsgToClient{
+						Cookie:      5678,
+						ChannelID:   wire.ICBMChannelIM,
+						TLVUserInfo: wire.TLVUserInfo{ScreenName: "deletedsn"},
+						TLVRestBlock: wire.TLVRestBlock{
+							TLVList: wire.TLVList{
+								wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+								wire.NewTLVBE(wire.ICBMTLVSendTime, uint32(sent.Unix())),
+							},
+						},
+					},
+				},
+			},
+			mockParams: mockParams{
+				offlineMessageManagerParams: offlineMessageManagerParams{
+					retrieveMessagesParams: retrieveMessagesParams{
+						{
+							recipIn: state.NewIdentScreenName("recipient-screen-name"),
+							messagesOut: []state.OfflineMessage{
+								{
+									ID:        1,
+									Sender:    state.NewIdentScreenName("sendersn"),
+									Recipient: state.NewIdentScreenName("recipient-screen-name"),
+									Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+										Cookie:     1234,
+										ChannelID:  wire.ICBMChannelIM,
+										ScreenName: "recipient-screen-name",
+										TLVRestBlock: wire.TLVRestBlock{
+											TLVList: wire.TLVList{
+												wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+												wire.NewTLVBE(wire.ICBMTLVRequestHostAck, []byte{}),
+												wire.NewTLVBE(wire.ICBMTLVStore, []byte{}),
+											},
+										},
+									},
+									Sent: sent,
+								},
+								{
+									ID:        2,
+									Sender:    state.NewIdentScreenName("deletedsn"),
+									Recipient: state.NewIdentScreenName("recipient-screen-name"),
+									Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+										Cookie:     5678,
+										ChannelID:  wire.ICBMChannelIM,
+										ScreenName: "recipient-screen-name",
+										TLVRestBlock: wire.TLVRestBlock{
+											TLVList: wire.TLVList{
+												wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+												wire.NewTLVBE(wire.ICBMTLVStore, []byte{}),
+											},
+										},
+									},
+									Sent: sent,
+								},
+							},
+						},
+					},
+					deleteMessagesByIDParams: deleteMessagesByIDParams{
+						{
+							recipIn: state.NewIdentScreenName("recipient-screen-name"),
+							idsIn:   []int64{1, 2},
+						},
+					},
+				},
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: state.NewIdentScreenName("sendersn"),
+							result: &state.User{
+								IdentScreenName:   state.NewIdentScreenName("sendersn"),
+								DisplayScreenName: "SenderSN",
+							},
+						},
+						{
+							screenName: state.NewIdentScreenName("deletedsn"),
+							result:     nil,
+						},
+					},
+				},
+				messageRelayerParams: messageRelayerParams{
+					relayToScreenNameParams: relayToScreenNameParams{
+						{
+							screenName: state.NewIdentScreenName("recipient-screen-name"),
+							message: wire.SNACMessage{
+								Frame: wire.SNACFrame{
+									FoodGroup: wire.ICBM,
+									SubGroup:  wire.ICBMSinReply,
+									RequestID: 1234,
+								},
+								Body: wire.SNAC_0x04_0x17_ICBMSinReply{},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "session closed, keep offline messages queued, then reply",
+			recipSession: newTestSession("recipient-screen-name", func(session *state.Session) {
+				session.Close()
+			}),
+			mockParams: mockParams{
+				offlineMessageManagerParams: offlineMessageManagerParams{
+					retrieveMessagesParams: retrieveMessagesParams{
+						{
+							recipIn: state.NewIdentScreenName("recipient-screen-name"),
+							messagesOut: []state.OfflineMessage{
+								{
+									ID:        1,
+									Sender:    state.NewIdentScreenName("sendersn"),
+									Recipient: state.NewIdentScreenName("recipient-screen-name"),
+									Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+										Cookie:     1234,
+										ChannelID:  wire.ICBMChannelIM,
+										ScreenName: "recipient-screen-name",
+									},
+									Sent: sent,
+								},
+							},
+						},
+					},
+					deleteMessagesByIDParams: deleteMessagesByIDParams{
+						{
+							recipIn: state.NewIdentScreenName("recipient-screen-name"),
+							idsIn:   []int64{},
+						},
+					},
+				},
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: state.NewIdentScreenName("sendersn"),
+							result: &state.User{
+								IdentScreenName:   state.NewIdentScreenName("sendersn"),
+								DisplayScreenName: "SenderSN",
+							},
+						},
+					},
+				},
+				messageRelayerParams: messageRelayerParams{
+					relayToScreenNameParams: relayToScreenNameParams{
+						{
+							screenName: state.NewIdentScreenName("recipient-screen-name"),
+							message: wire.SNACMessage{
+								Frame: wire.SNACFrame{
+									FoodGroup: wire.ICBM,
+									SubGroup:  wire.ICBMSinReply,
+									RequestID: 1234,
+								},
+								Body: wire.SNAC_0x04_0x17_ICBMSinReply{},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:         "no offline messages, reply",
+			recipSession: newTestSession("recipient-screen-name"),
+			mockParams: mockParams{
+				offlineMessageManagerParams: offlineMessageManagerParams{
+					retrieveMessagesParams: retrieveMessagesParams{
+						{
+							recipIn: state.NewIdentScreenName("recipient-screen-name"),
+						},
+					},
+				},
+				messageRelayerParams: messageRelayerParams{
+					relayToScreenNameParams: relayToScreenNameParams{
+						{
+							screenName: state.NewIdentScreenName("recipient-screen-name"),
+							message: wire.SNACMessage{
+								Frame: wire.SNACFrame{
+									FoodGroup: wire.ICBM,
+									SubGroup:  wire.ICBMSinReply,
+									RequestID: 1234,
+								},
+								Body: wire.SNAC_0x04_0x17_ICBMSinReply{},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:         "retrieve messages runtime error",
+			recipSession: newTestSession("recipient-screen-name"),
+			mockParams: mockParams{
+				offlineMessageManagerParams: offlineMessageManagerParams{
+					retrieveMessagesParams: retrieveMessagesParams{
+						{
+							recipIn: state.NewIdentScreenName("recipient-screen-name"),
+							err:     errRetrieve,
+						},
+					},
+				},
+			},
+			wantErr: errRetrieve,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			offlineMessageManager := newMockOfflineMessageManager(t)
+			for _, params := range tc.mockParams.retrieveMessagesParams {
+				offlineMessageManager.EXPECT().
+					RetrieveMessages(matchContext(), params.recipIn).
+					Return(params.messagesOut, params.err)
+			}
+			for _, params := range tc.mockParams.deleteMessagesByIDParams {
+				offlineMessageManager.EXPECT().
+					DeleteMessagesByID(matchContext(), params.recipIn, params.idsIn).
+					Return(params.err)
+			}
+			userManager := newMockUserManager(t)
+			for _, params := range tc.mockParams.userManagerParams.getUserParams {
+				userManager.EXPECT().
+					User(matchContext(), params.screenName).
+					Return(params.result, params.err)
+			}
+			messageRelayer := newMockMessageRelayer(t)
+			var relayed []wire.SNACMessage
+			for _, params := range tc.mockParams.relayToScreenNameParams {
+				messageRelayer.EXPECT().
+					RelayToScreenName(matchContext(), params.screenName, params.message).
+					Run(func(ctx context.Context, screenName state.IdentScreenName, msg wire.SNACMessage) {
+						relayed = append(relayed, msg)
+					})
+			}
+
+			svc := NewICBMService(nil, nil, messageRelayer, offlineMessageManager, nil, nil, userManager,
+				wire.DefaultSNACRateLimits(), 0, slog.Default())
+
+			err := svc.OfflineRetrieve(context.Background(), tc.recipSession, wire.SNACFrame{RequestID: 1234})
+			assert.ErrorIs(t, err, tc.wantErr)
+
+			// the messages must be delivered to the session in order
+			var delivered []wire.SNACMessage
+			for len(tc.recipSession.ReceiveMessage()) > 0 {
+				delivered = append(delivered, <-tc.recipSession.ReceiveMessage())
+			}
+			assert.Equal(t, tc.wantDelivered, delivered)
+
+			// the reply must be relayed after delivery
+			var want []wire.SNACMessage
+			for _, params := range tc.mockParams.relayToScreenNameParams {
+				want = append(want, params.message)
+			}
+			assert.Equal(t, want, relayed)
+		})
+	}
+}
+
 func TestRingBuffer(t *testing.T) {
 	t.Run("new ringBuffer should have zero values", func(t *testing.T) {
 		rb := &ringBuffer{}
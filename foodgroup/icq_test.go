@@ -207,7 +207,7 @@ func TestICQService_FindByICQName(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("987654321"),
 							result:     nil,
@@ -347,7 +347,7 @@ func TestICQService_FindByICQEmail(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("123456789"),
 							result:     &state.Session{},
@@ -489,7 +489,7 @@ func TestICQService_FindByEmail3(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("123456789"),
 							result:     &state.Session{},
@@ -625,7 +625,7 @@ func TestICQService_FindByUIN(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("123456789"),
 							result:     &state.Session{},
@@ -765,7 +765,7 @@ func TestICQService_FindByUIN2(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("123456789"),
 							result:     &state.Session{},
@@ -960,7 +960,7 @@ func TestICQService_FindByWhitePages(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("987654321"),
 							result:     nil,
@@ -1165,7 +1165,7 @@ func TestICQService_FindByWhitePages2(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("987654321"),
 							result:     nil,
@@ -1283,7 +1283,7 @@ func TestICQService_FindByWhitePages2(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("987654321"),
 							result:     nil,
@@ -1752,7 +1752,7 @@ func TestICQService_FullUserInfo(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("123456789"),
 							result:     &state.Session{},
@@ -2870,7 +2870,7 @@ func TestICQService_ShortUserInfo(t *testing.T) {
 					},
 				},
 				sessionRetrieverParams: sessionRetrieverParams{
-					retrieveSessionParams{
+					retrieveSessionParams: retrieveSessionParams{
 						{
 							screenName: state.NewIdentScreenName("123456789"),
 							result:     &state.Session{},
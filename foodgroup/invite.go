@@ -0,0 +1,106 @@
+package foodgroup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"time"
+
+	"github.com/mk6i/retro-aim-server/mailer"
+	"github.com/mk6i/retro-aim-server/state"
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// inviteWindow is the period over which invitations count towards the
+// daily limit.
+const inviteWindow = 24 * time.Hour
+
+// NewInviteService creates a new instance of InviteService. If mailer is
+// nil or dailyLimit is 0, all invitation requests are rejected.
+func NewInviteService(inviteManager InviteManager, mailer Mailer, linkBaseURL string, dailyLimit int, logger *slog.Logger) InviteService {
+	return InviteService{
+		dailyLimit:    dailyLimit,
+		inviteManager: inviteManager,
+		linkBaseURL:   linkBaseURL,
+		logger:        logger,
+		mailer:        mailer,
+		timeNow:       time.Now,
+	}
+}
+
+// InviteService provides functionality for the Invite food group, which
+// lets users invite friends to join AIM by email.
+type InviteService struct {
+	dailyLimit    int
+	inviteManager InviteManager
+	linkBaseURL   string
+	logger        *slog.Logger
+	mailer        Mailer
+	timeNow       func() time.Time
+}
+
+// RequestQuery emails an invitation to the address in the request and
+// records it. Each user may send up to dailyLimit invitations in a 24-hour
+// period. The invitation is recorded before the email is sent, so that
+// concurrent requests can't exceed the limit, and removed again if the email
+// can't be sent.
+func (s InviteService) RequestQuery(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x06_0x02_InviteRequestQuery) (wire.SNACMessage, error) {
+	inviteErr := func(code uint16) wire.SNACMessage {
+		return wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.Invite,
+				SubGroup:  wire.InviteErr,
+				RequestID: inFrame.RequestID,
+			},
+			Body: wire.SNACError{
+				Code: code,
+			},
+		}
+	}
+
+	if s.mailer == nil || s.dailyLimit == 0 {
+		return inviteErr(wire.ErrorCodeNotSupportedByHost), nil
+	}
+
+	rawEmail, _ := inBody.String(wire.InviteTLVEmailAddress)
+	to, err := mail.ParseAddress(rawEmail)
+	if err != nil {
+		return inviteErr(wire.ErrorCodeInvalidSnac), nil
+	}
+	note, _ := inBody.String(wire.InviteTLVMessage)
+
+	now := s.timeNow()
+	invite := state.Invite{
+		Sender:    sess.IdentScreenName(),
+		Email:     to.Address,
+		Message:   note,
+		CreatedAt: now,
+	}
+	id, err := s.inviteManager.InsertInvite(ctx, invite, now.Add(-inviteWindow), s.dailyLimit)
+	switch {
+	case errors.Is(err, state.ErrInviteLimitReached):
+		return inviteErr(wire.ErrorCodeRateToHost), nil
+	case err != nil:
+		return wire.SNACMessage{}, err
+	}
+
+	msg := mailer.NewInviteMessage(to, sess.DisplayScreenName().String(), note, s.linkBaseURL)
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		s.logger.ErrorContext(ctx, "error sending invitation email", "err", err.Error())
+		// give the invitation back so it doesn't count towards the limit
+		if err := s.inviteManager.DeleteInvite(ctx, id); err != nil {
+			s.logger.ErrorContext(ctx, "error removing unsent invitation", "err", err.Error())
+		}
+		return inviteErr(wire.ErrorCodeServiceUnavailable), nil
+	}
+
+	return wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.Invite,
+			SubGroup:  wire.InviteRequestReply,
+			RequestID: inFrame.RequestID,
+		},
+		Body: wire.SNAC_0x06_0x03_InviteRequestReply{},
+	}, nil
+}
@@ -0,0 +1,165 @@
+package foodgroup
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mk6i/retro-aim-server/mailer"
+	"github.com/mk6i/retro-aim-server/state"
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+func TestInviteService_RequestQuery(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	frame := wire.SNACFrame{
+		FoodGroup: wire.Invite,
+		SubGroup:  wire.InviteRequestQuery,
+		RequestID: 1234,
+	}
+	inviteErr := func(code uint16) wire.SNACMessage {
+		return wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.Invite,
+				SubGroup:  wire.InviteErr,
+				RequestID: 1234,
+			},
+			Body: wire.SNACError{
+				Code: code,
+			},
+		}
+	}
+	newBody := func(email string) wire.SNAC_0x06_0x02_InviteRequestQuery {
+		return wire.SNAC_0x06_0x02_InviteRequestQuery{
+			TLVRestBlock: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.InviteTLVEmailAddress, email),
+					wire.NewTLVBE(wire.InviteTLVMessage, "come chat with me"),
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		// name is the unit test name
+		name string
+		// inputBody is the SNAC body sent from the client to the server
+		inputBody wire.SNAC_0x06_0x02_InviteRequestQuery
+		// noMailer indicates that email delivery is not configured
+		noMailer bool
+		// dailyLimit is the number of invites a user may send per day
+		dailyLimit int
+		// expectInsert indicates whether the invite is recorded
+		expectInsert bool
+		// insertErr is the error returned when recording the invite
+		insertErr error
+		// expectSend indicates whether the invite email is sent
+		expectSend bool
+		// sendErr is the error returned by the mailer
+		sendErr error
+		// expectDelete indicates whether the recorded invite is removed
+		expectDelete bool
+		// expectOutput is the SNAC sent from the server to client
+		expectOutput wire.SNACMessage
+	}{
+		{
+			name:         "send invite",
+			inputBody:    newBody("friend@example.com"),
+			dailyLimit:   5,
+			expectInsert: true,
+			expectSend:   true,
+			expectOutput: wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.Invite,
+					SubGroup:  wire.InviteRequestReply,
+					RequestID: 1234,
+				},
+				Body: wire.SNAC_0x06_0x03_InviteRequestReply{},
+			},
+		},
+		{
+			name:         "daily limit reached",
+			inputBody:    newBody("friend@example.com"),
+			dailyLimit:   5,
+			expectInsert: true,
+			insertErr:    state.ErrInviteLimitReached,
+			expectOutput: inviteErr(wire.ErrorCodeRateToHost),
+		},
+		{
+			name:         "invalid email address",
+			inputBody:    newBody("not an email address"),
+			dailyLimit:   5,
+			expectOutput: inviteErr(wire.ErrorCodeInvalidSnac),
+		},
+		{
+			name:         "email fails to send, invite is removed",
+			inputBody:    newBody("friend@example.com"),
+			dailyLimit:   5,
+			expectInsert: true,
+			expectSend:   true,
+			sendErr:      io.EOF,
+			expectDelete: true,
+			expectOutput: inviteErr(wire.ErrorCodeServiceUnavailable),
+		},
+		{
+			name:         "email delivery is not configured",
+			inputBody:    newBody("friend@example.com"),
+			noMailer:     true,
+			dailyLimit:   5,
+			expectOutput: inviteErr(wire.ErrorCodeNotSupportedByHost),
+		},
+		{
+			name:         "invitations are disabled",
+			inputBody:    newBody("friend@example.com"),
+			expectOutput: inviteErr(wire.ErrorCodeNotSupportedByHost),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sess := newTestSession("ChattingChuck")
+
+			inviteManager := newMockInviteManager(t)
+			if tc.expectInsert {
+				inviteManager.EXPECT().
+					InsertInvite(matchContext(), state.Invite{
+						Sender:    sess.IdentScreenName(),
+						Email:     "friend@example.com",
+						Message:   "come chat with me",
+						CreatedAt: now,
+					}, now.Add(-24*time.Hour), tc.dailyLimit).
+					Return(1, tc.insertErr)
+			}
+			if tc.expectDelete {
+				inviteManager.EXPECT().
+					DeleteInvite(matchContext(), int64(1)).
+					Return(nil)
+			}
+
+			mailSender := newMockMailer(t)
+			if tc.expectSend {
+				mailSender.EXPECT().
+					Send(matchContext(), mailer.NewInviteMessage(&mail.Address{Address: "friend@example.com"},
+						"ChattingChuck", "come chat with me", "https://aim.example.com")).
+					Return(tc.sendErr)
+			}
+
+			svc := NewInviteService(inviteManager, mailSender, "https://aim.example.com", tc.dailyLimit, slog.Default())
+			if tc.noMailer {
+				svc.mailer = nil
+			}
+			svc.timeNow = func() time.Time {
+				return now
+			}
+
+			outputSNAC, err := svc.RequestQuery(context.Background(), sess, frame, tc.inputBody)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectOutput, outputSNAC)
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	state "github.com/mk6i/retro-aim-server/state"
+
+	time "time"
 )
 
 // mockAccountManager is an autogenerated mock type for the AccountManager type
@@ -140,6 +142,65 @@ func (_c *mockAccountManager_EmailAddress_Call) RunAndReturn(run func(context.Co
 	return _c
 }
 
+// IssueEmailToken provides a mock function with given fields: ctx, screenName, purpose, expiresAt
+func (_m *mockAccountManager) IssueEmailToken(ctx context.Context, screenName state.IdentScreenName, purpose state.EmailTokenPurpose, expiresAt time.Time) (string, error) {
+	ret := _m.Called(ctx, screenName, purpose, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IssueEmailToken")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, state.IdentScreenName, state.EmailTokenPurpose, time.Time) (string, error)); ok {
+		return rf(ctx, screenName, purpose, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, state.IdentScreenName, state.EmailTokenPurpose, time.Time) string); ok {
+		r0 = rf(ctx, screenName, purpose, expiresAt)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, state.IdentScreenName, state.EmailTokenPurpose, time.Time) error); ok {
+		r1 = rf(ctx, screenName, purpose, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockAccountManager_IssueEmailToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IssueEmailToken'
+type mockAccountManager_IssueEmailToken_Call struct {
+	*mock.Call
+}
+
+// IssueEmailToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - screenName state.IdentScreenName
+//   - purpose state.EmailTokenPurpose
+//   - expiresAt time.Time
+func (_e *mockAccountManager_Expecter) IssueEmailToken(ctx interface{}, screenName interface{}, purpose interface{}, expiresAt interface{}) *mockAccountManager_IssueEmailToken_Call {
+	return &mockAccountManager_IssueEmailToken_Call{Call: _e.mock.On("IssueEmailToken", ctx, screenName, purpose, expiresAt)}
+}
+
+func (_c *mockAccountManager_IssueEmailToken_Call) Run(run func(ctx context.Context, screenName state.IdentScreenName, purpose state.EmailTokenPurpose, expiresAt time.Time)) *mockAccountManager_IssueEmailToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(state.IdentScreenName), args[2].(state.EmailTokenPurpose), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *mockAccountManager_IssueEmailToken_Call) Return(_a0 string, _a1 error) *mockAccountManager_IssueEmailToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockAccountManager_IssueEmailToken_Call) RunAndReturn(run func(context.Context, state.IdentScreenName, state.EmailTokenPurpose, time.Time) (string, error)) *mockAccountManager_IssueEmailToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RegStatus provides a mock function with given fields: ctx, screenName
 func (_m *mockAccountManager) RegStatus(ctx context.Context, screenName state.IdentScreenName) (uint16, error) {
 	ret := _m.Called(ctx, screenName)
@@ -24,6 +24,75 @@ func (_m *mockMessageRelayer) EXPECT() *mockMessageRelayer_Expecter {
 	return &mockMessageRelayer_Expecter{mock: &_m.Mock}
 }
 
+// RelayToAll provides a mock function with given fields: ctx, msg
+func (_m *mockMessageRelayer) RelayToAll(ctx context.Context, msg wire.SNACMessage) {
+	_m.Called(ctx, msg)
+}
+
+// mockMessageRelayer_RelayToAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RelayToAll'
+type mockMessageRelayer_RelayToAll_Call struct {
+	*mock.Call
+}
+
+// RelayToAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - msg wire.SNACMessage
+func (_e *mockMessageRelayer_Expecter) RelayToAll(ctx interface{}, msg interface{}) *mockMessageRelayer_RelayToAll_Call {
+	return &mockMessageRelayer_RelayToAll_Call{Call: _e.mock.On("RelayToAll", ctx, msg)}
+}
+
+func (_c *mockMessageRelayer_RelayToAll_Call) Run(run func(ctx context.Context, msg wire.SNACMessage)) *mockMessageRelayer_RelayToAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(wire.SNACMessage))
+	})
+	return _c
+}
+
+func (_c *mockMessageRelayer_RelayToAll_Call) Return() *mockMessageRelayer_RelayToAll_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *mockMessageRelayer_RelayToAll_Call) RunAndReturn(run func(context.Context, wire.SNACMessage)) *mockMessageRelayer_RelayToAll_Call {
+	_c.Run(run)
+	return _c
+}
+
+// RelayToOtherInstances provides a mock function with given fields: ctx, sess, msg
+func (_m *mockMessageRelayer) RelayToOtherInstances(ctx context.Context, sess *state.Session, msg wire.SNACMessage) {
+	_m.Called(ctx, sess, msg)
+}
+
+// mockMessageRelayer_RelayToOtherInstances_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RelayToOtherInstances'
+type mockMessageRelayer_RelayToOtherInstances_Call struct {
+	*mock.Call
+}
+
+// RelayToOtherInstances is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sess *state.Session
+//   - msg wire.SNACMessage
+func (_e *mockMessageRelayer_Expecter) RelayToOtherInstances(ctx interface{}, sess interface{}, msg interface{}) *mockMessageRelayer_RelayToOtherInstances_Call {
+	return &mockMessageRelayer_RelayToOtherInstances_Call{Call: _e.mock.On("RelayToOtherInstances", ctx, sess, msg)}
+}
+
+func (_c *mockMessageRelayer_RelayToOtherInstances_Call) Run(run func(ctx context.Context, sess *state.Session, msg wire.SNACMessage)) *mockMessageRelayer_RelayToOtherInstances_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*state.Session), args[2].(wire.SNACMessage))
+	})
+	return _c
+}
+
+func (_c *mockMessageRelayer_RelayToOtherInstances_Call) Return() *mockMessageRelayer_RelayToOtherInstances_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *mockMessageRelayer_RelayToOtherInstances_Call) RunAndReturn(run func(context.Context, *state.Session, wire.SNACMessage)) *mockMessageRelayer_RelayToOtherInstances_Call {
+	_c.Run(run)
+	return _c
+}
+
 // RelayToScreenName provides a mock function with given fields: ctx, screenName, msg
 func (_m *mockMessageRelayer) RelayToScreenName(ctx context.Context, screenName state.IdentScreenName, msg wire.SNACMessage) {
 	_m.Called(ctx, screenName, msg)
@@ -69,6 +69,54 @@ func (_c *mockOfflineMessageManager_DeleteMessages_Call) RunAndReturn(run func(c
 	return _c
 }
 
+// DeleteMessagesByID provides a mock function with given fields: ctx, recip, ids
+func (_m *mockOfflineMessageManager) DeleteMessagesByID(ctx context.Context, recip state.IdentScreenName, ids []int64) error {
+	ret := _m.Called(ctx, recip, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMessagesByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, state.IdentScreenName, []int64) error); ok {
+		r0 = rf(ctx, recip, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// mockOfflineMessageManager_DeleteMessagesByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMessagesByID'
+type mockOfflineMessageManager_DeleteMessagesByID_Call struct {
+	*mock.Call
+}
+
+// DeleteMessagesByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - recip state.IdentScreenName
+//   - ids []int64
+func (_e *mockOfflineMessageManager_Expecter) DeleteMessagesByID(ctx interface{}, recip interface{}, ids interface{}) *mockOfflineMessageManager_DeleteMessagesByID_Call {
+	return &mockOfflineMessageManager_DeleteMessagesByID_Call{Call: _e.mock.On("DeleteMessagesByID", ctx, recip, ids)}
+}
+
+func (_c *mockOfflineMessageManager_DeleteMessagesByID_Call) Run(run func(ctx context.Context, recip state.IdentScreenName, ids []int64)) *mockOfflineMessageManager_DeleteMessagesByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(state.IdentScreenName), args[2].([]int64))
+	})
+	return _c
+}
+
+func (_c *mockOfflineMessageManager_DeleteMessagesByID_Call) Return(_a0 error) *mockOfflineMessageManager_DeleteMessagesByID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *mockOfflineMessageManager_DeleteMessagesByID_Call) RunAndReturn(run func(context.Context, state.IdentScreenName, []int64) error) *mockOfflineMessageManager_DeleteMessagesByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RetrieveMessages provides a mock function with given fields: ctx, recip
 func (_m *mockOfflineMessageManager) RetrieveMessages(ctx context.Context, recip state.IdentScreenName) ([]state.OfflineMessage, error) {
 	ret := _m.Called(ctx, recip)
@@ -128,17 +176,17 @@ func (_c *mockOfflineMessageManager_RetrieveMessages_Call) RunAndReturn(run func
 	return _c
 }
 
-// SaveMessage provides a mock function with given fields: ctx, offlineMessage
-func (_m *mockOfflineMessageManager) SaveMessage(ctx context.Context, offlineMessage state.OfflineMessage) error {
-	ret := _m.Called(ctx, offlineMessage)
+// SaveMessage provides a mock function with given fields: ctx, offlineMessage, maxQueued
+func (_m *mockOfflineMessageManager) SaveMessage(ctx context.Context, offlineMessage state.OfflineMessage, maxQueued int) error {
+	ret := _m.Called(ctx, offlineMessage, maxQueued)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SaveMessage")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, state.OfflineMessage) error); ok {
-		r0 = rf(ctx, offlineMessage)
+	if rf, ok := ret.Get(0).(func(context.Context, state.OfflineMessage, int) error); ok {
+		r0 = rf(ctx, offlineMessage, maxQueued)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -154,13 +202,14 @@ type mockOfflineMessageManager_SaveMessage_Call struct {
 // SaveMessage is a helper method to define mock.On call
 //   - ctx context.Context
 //   - offlineMessage state.OfflineMessage
-func (_e *mockOfflineMessageManager_Expecter) SaveMessage(ctx interface{}, offlineMessage interface{}) *mockOfflineMessageManager_SaveMessage_Call {
-	return &mockOfflineMessageManager_SaveMessage_Call{Call: _e.mock.On("SaveMessage", ctx, offlineMessage)}
+//   - maxQueued int
+func (_e *mockOfflineMessageManager_Expecter) SaveMessage(ctx interface{}, offlineMessage interface{}, maxQueued interface{}) *mockOfflineMessageManager_SaveMessage_Call {
+	return &mockOfflineMessageManager_SaveMessage_Call{Call: _e.mock.On("SaveMessage", ctx, offlineMessage, maxQueued)}
 }
 
-func (_c *mockOfflineMessageManager_SaveMessage_Call) Run(run func(ctx context.Context, offlineMessage state.OfflineMessage)) *mockOfflineMessageManager_SaveMessage_Call {
+func (_c *mockOfflineMessageManager_SaveMessage_Call) Run(run func(ctx context.Context, offlineMessage state.OfflineMessage, maxQueued int)) *mockOfflineMessageManager_SaveMessage_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(state.OfflineMessage))
+		run(args[0].(context.Context), args[1].(state.OfflineMessage), args[2].(int))
 	})
 	return _c
 }
@@ -170,7 +219,7 @@ func (_c *mockOfflineMessageManager_SaveMessage_Call) Return(_a0 error) *mockOff
 	return _c
 }
 
-func (_c *mockOfflineMessageManager_SaveMessage_Call) RunAndReturn(run func(context.Context, state.OfflineMessage) error) *mockOfflineMessageManager_SaveMessage_Call {
+func (_c *mockOfflineMessageManager_SaveMessage_Call) RunAndReturn(run func(context.Context, state.OfflineMessage, int) error) *mockOfflineMessageManager_SaveMessage_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -20,6 +20,53 @@ func (_m *mockSessionRetriever) EXPECT() *mockSessionRetriever_Expecter {
 	return &mockSessionRetriever_Expecter{mock: &_m.Mock}
 }
 
+// AllSessions provides a mock function with no fields
+func (_m *mockSessionRetriever) AllSessions() []*state.Session {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AllSessions")
+	}
+
+	var r0 []*state.Session
+	if rf, ok := ret.Get(0).(func() []*state.Session); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*state.Session)
+		}
+	}
+
+	return r0
+}
+
+// mockSessionRetriever_AllSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AllSessions'
+type mockSessionRetriever_AllSessions_Call struct {
+	*mock.Call
+}
+
+// AllSessions is a helper method to define mock.On call
+func (_e *mockSessionRetriever_Expecter) AllSessions() *mockSessionRetriever_AllSessions_Call {
+	return &mockSessionRetriever_AllSessions_Call{Call: _e.mock.On("AllSessions")}
+}
+
+func (_c *mockSessionRetriever_AllSessions_Call) Run(run func()) *mockSessionRetriever_AllSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *mockSessionRetriever_AllSessions_Call) Return(_a0 []*state.Session) *mockSessionRetriever_AllSessions_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *mockSessionRetriever_AllSessions_Call) RunAndReturn(run func() []*state.Session) *mockSessionRetriever_AllSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RetrieveSession provides a mock function with given fields: screenName
 func (_m *mockSessionRetriever) RetrieveSession(screenName state.IdentScreenName) *state.Session {
 	ret := _m.Called(screenName)
@@ -68,6 +115,54 @@ func (_c *mockSessionRetriever_RetrieveSession_Call) RunAndReturn(run func(state
 	return _c
 }
 
+// RetrieveSessions provides a mock function with given fields: screenName
+func (_m *mockSessionRetriever) RetrieveSessions(screenName state.IdentScreenName) []*state.Session {
+	ret := _m.Called(screenName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveSessions")
+	}
+
+	var r0 []*state.Session
+	if rf, ok := ret.Get(0).(func(state.IdentScreenName) []*state.Session); ok {
+		r0 = rf(screenName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*state.Session)
+		}
+	}
+
+	return r0
+}
+
+// mockSessionRetriever_RetrieveSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetrieveSessions'
+type mockSessionRetriever_RetrieveSessions_Call struct {
+	*mock.Call
+}
+
+// RetrieveSessions is a helper method to define mock.On call
+//   - screenName state.IdentScreenName
+func (_e *mockSessionRetriever_Expecter) RetrieveSessions(screenName interface{}) *mockSessionRetriever_RetrieveSessions_Call {
+	return &mockSessionRetriever_RetrieveSessions_Call{Call: _e.mock.On("RetrieveSessions", screenName)}
+}
+
+func (_c *mockSessionRetriever_RetrieveSessions_Call) Run(run func(screenName state.IdentScreenName)) *mockSessionRetriever_RetrieveSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(state.IdentScreenName))
+	})
+	return _c
+}
+
+func (_c *mockSessionRetriever_RetrieveSessions_Call) Return(_a0 []*state.Session) *mockSessionRetriever_RetrieveSessions_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *mockSessionRetriever_RetrieveSessions_Call) RunAndReturn(run func(state.IdentScreenName) []*state.Session) *mockSessionRetriever_RetrieveSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // newMockSessionRetriever creates a new instance of mockSessionRetriever. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func newMockSessionRetriever(t interface {
@@ -71,6 +71,159 @@ func (_c *mockUserManager_InsertUser_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// NextUIN provides a mock function with given fields: ctx, min, max
+func (_m *mockUserManager) NextUIN(ctx context.Context, min uint32, max uint32) (uint32, error) {
+	ret := _m.Called(ctx, min, max)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NextUIN")
+	}
+
+	var r0 uint32
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint32, uint32) (uint32, error)); ok {
+		return rf(ctx, min, max)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint32, uint32) uint32); ok {
+		r0 = rf(ctx, min, max)
+	} else {
+		r0 = ret.Get(0).(uint32)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint32, uint32) error); ok {
+		r1 = rf(ctx, min, max)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockUserManager_NextUIN_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'NextUIN'
+type mockUserManager_NextUIN_Call struct {
+	*mock.Call
+}
+
+// NextUIN is a helper method to define mock.On call
+//   - ctx context.Context
+//   - min uint32
+//   - max uint32
+func (_e *mockUserManager_Expecter) NextUIN(ctx interface{}, min interface{}, max interface{}) *mockUserManager_NextUIN_Call {
+	return &mockUserManager_NextUIN_Call{Call: _e.mock.On("NextUIN", ctx, min, max)}
+}
+
+func (_c *mockUserManager_NextUIN_Call) Run(run func(ctx context.Context, min uint32, max uint32)) *mockUserManager_NextUIN_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint32), args[2].(uint32))
+	})
+	return _c
+}
+
+func (_c *mockUserManager_NextUIN_Call) Return(_a0 uint32, _a1 error) *mockUserManager_NextUIN_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockUserManager_NextUIN_Call) RunAndReturn(run func(context.Context, uint32, uint32) (uint32, error)) *mockUserManager_NextUIN_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPasswordHash provides a mock function with given fields: ctx, screenName, passwordHash
+func (_m *mockUserManager) SetPasswordHash(ctx context.Context, screenName state.IdentScreenName, passwordHash string) error {
+	ret := _m.Called(ctx, screenName, passwordHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPasswordHash")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, state.IdentScreenName, string) error); ok {
+		r0 = rf(ctx, screenName, passwordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// mockUserManager_SetPasswordHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPasswordHash'
+type mockUserManager_SetPasswordHash_Call struct {
+	*mock.Call
+}
+
+// SetPasswordHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - screenName state.IdentScreenName
+//   - passwordHash string
+func (_e *mockUserManager_Expecter) SetPasswordHash(ctx interface{}, screenName interface{}, passwordHash interface{}) *mockUserManager_SetPasswordHash_Call {
+	return &mockUserManager_SetPasswordHash_Call{Call: _e.mock.On("SetPasswordHash", ctx, screenName, passwordHash)}
+}
+
+func (_c *mockUserManager_SetPasswordHash_Call) Run(run func(ctx context.Context, screenName state.IdentScreenName, passwordHash string)) *mockUserManager_SetPasswordHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(state.IdentScreenName), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *mockUserManager_SetPasswordHash_Call) Return(_a0 error) *mockUserManager_SetPasswordHash_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *mockUserManager_SetPasswordHash_Call) RunAndReturn(run func(context.Context, state.IdentScreenName, string) error) *mockUserManager_SetPasswordHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPasswordHashes provides a mock function with given fields: ctx, u
+func (_m *mockUserManager) SetPasswordHashes(ctx context.Context, u state.User) error {
+	ret := _m.Called(ctx, u)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPasswordHashes")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, state.User) error); ok {
+		r0 = rf(ctx, u)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// mockUserManager_SetPasswordHashes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPasswordHashes'
+type mockUserManager_SetPasswordHashes_Call struct {
+	*mock.Call
+}
+
+// SetPasswordHashes is a helper method to define mock.On call
+//   - ctx context.Context
+//   - u state.User
+func (_e *mockUserManager_Expecter) SetPasswordHashes(ctx interface{}, u interface{}) *mockUserManager_SetPasswordHashes_Call {
+	return &mockUserManager_SetPasswordHashes_Call{Call: _e.mock.On("SetPasswordHashes", ctx, u)}
+}
+
+func (_c *mockUserManager_SetPasswordHashes_Call) Run(run func(ctx context.Context, u state.User)) *mockUserManager_SetPasswordHashes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(state.User))
+	})
+	return _c
+}
+
+func (_c *mockUserManager_SetPasswordHashes_Call) Return(_a0 error) *mockUserManager_SetPasswordHashes_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *mockUserManager_SetPasswordHashes_Call) RunAndReturn(run func(context.Context, state.User) error) *mockUserManager_SetPasswordHashes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetWarnLevel provides a mock function with given fields: ctx, user, lastWarnUpdate, lastWarnLevel
 func (_m *mockUserManager) SetWarnLevel(ctx context.Context, user state.IdentScreenName, lastWarnUpdate time.Time, lastWarnLevel uint16) error {
 	ret := _m.Called(ctx, user, lastWarnUpdate, lastWarnLevel)
@@ -179,6 +332,64 @@ func (_c *mockUserManager_User_Call) RunAndReturn(run func(context.Context, stat
 	return _c
 }
 
+// ValidateSecondFactor provides a mock function with given fields: ctx, u, code
+func (_m *mockUserManager) ValidateSecondFactor(ctx context.Context, u state.User, code string) (bool, error) {
+	ret := _m.Called(ctx, u, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateSecondFactor")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, state.User, string) (bool, error)); ok {
+		return rf(ctx, u, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, state.User, string) bool); ok {
+		r0 = rf(ctx, u, code)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, state.User, string) error); ok {
+		r1 = rf(ctx, u, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockUserManager_ValidateSecondFactor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateSecondFactor'
+type mockUserManager_ValidateSecondFactor_Call struct {
+	*mock.Call
+}
+
+// ValidateSecondFactor is a helper method to define mock.On call
+//   - ctx context.Context
+//   - u state.User
+//   - code string
+func (_e *mockUserManager_Expecter) ValidateSecondFactor(ctx interface{}, u interface{}, code interface{}) *mockUserManager_ValidateSecondFactor_Call {
+	return &mockUserManager_ValidateSecondFactor_Call{Call: _e.mock.On("ValidateSecondFactor", ctx, u, code)}
+}
+
+func (_c *mockUserManager_ValidateSecondFactor_Call) Run(run func(ctx context.Context, u state.User, code string)) *mockUserManager_ValidateSecondFactor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(state.User), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *mockUserManager_ValidateSecondFactor_Call) Return(_a0 bool, _a1 error) *mockUserManager_ValidateSecondFactor_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockUserManager_ValidateSecondFactor_Call) RunAndReturn(run func(context.Context, state.User, string) (bool, error)) *mockUserManager_ValidateSecondFactor_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // newMockUserManager creates a new instance of mockUserManager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func newMockUserManager(t interface {
@@ -0,0 +1,57 @@
+package foodgroup
+
+import (
+	"context"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// NewMOTDService creates a new instance of MOTDService.
+func NewMOTDService(motdManager MOTDManager, messageRelayer MessageRelayer) MOTDService {
+	return MOTDService{
+		messageRelayer: messageRelayer,
+		motdManager:    motdManager,
+	}
+}
+
+// MOTDService manages the message of the day, which is sent to users when
+// they sign on.
+type MOTDService struct {
+	messageRelayer MessageRelayer
+	motdManager    MOTDManager
+}
+
+// Message returns the message of the day, or an empty string if none is set.
+func (s MOTDService) Message() string {
+	return s.motdManager.Message()
+}
+
+// SetMessage replaces the message of the day and pushes it to all online
+// users. An empty message clears the message of the day without notifying
+// anyone.
+func (s MOTDService) SetMessage(ctx context.Context, message string) {
+	s.motdManager.SetMessage(message)
+	if message != "" {
+		s.messageRelayer.RelayToAll(ctx, newMOTDMessage(message))
+	}
+}
+
+// newMOTDMessage constructs SNAC(0x01,0x13), which displays message in the
+// client's system message window.
+func newMOTDMessage(message string) wire.SNACMessage {
+	return wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.OService,
+			SubGroup:  wire.OServiceMotd,
+			RequestID: wire.ReqIDFromServer,
+		},
+		Body: wire.SNAC_0x01_0x13_OServiceMotd{
+			MotdType: wire.OServiceMotdTypeNormal,
+			TLVRestBlock: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.OServiceTLVTagsMotdMessage, message),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package foodgroup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mk6i/retro-aim-server/state"
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+func TestMOTDService_SetMessage(t *testing.T) {
+	motd := state.NewMOTD("")
+
+	messageRelayer := newMockMessageRelayer(t)
+	messageRelayer.EXPECT().
+		RelayToAll(matchContext(), wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.OService,
+				SubGroup:  wire.OServiceMotd,
+				RequestID: wire.ReqIDFromServer,
+			},
+			Body: wire.SNAC_0x01_0x13_OServiceMotd{
+				MotdType: wire.OServiceMotdTypeNormal,
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.OServiceTLVTagsMotdMessage, "maintenance at 10pm"),
+					},
+				},
+			},
+		})
+
+	svc := NewMOTDService(motd, messageRelayer)
+	svc.SetMessage(context.Background(), "maintenance at 10pm")
+	assert.Equal(t, "maintenance at 10pm", svc.Message())
+
+	// clearing the message doesn't notify anyone
+	svc.SetMessage(context.Background(), "")
+	assert.Empty(t, svc.Message())
+}
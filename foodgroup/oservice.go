@@ -26,6 +26,7 @@ type OServiceService struct {
 	cookieIssuer       CookieBaker
 	messageRelayer     MessageRelayer
 	chatMessageRelayer ChatMessageRelayer
+	motdManager        MOTDManager
 }
 
 // NewOServiceService creates a new instance of NewOServiceService.
@@ -40,6 +41,7 @@ func NewOServiceService(
 	bartItemManager BARTItemManager,
 	snacRateLimits wire.SNACRateLimits,
 	chatMessageRelayer ChatMessageRelayer,
+	motdManager MOTDManager,
 ) *OServiceService {
 	return &OServiceService{
 		cookieIssuer:       cookieIssuer,
@@ -51,6 +53,7 @@ func NewOServiceService(
 		timeNow:            time.Now,
 		chatRoomManager:    chatRoomManager,
 		chatMessageRelayer: chatMessageRelayer,
+		motdManager:        motdManager,
 	}
 }
 
@@ -652,6 +655,10 @@ func (s OServiceService) ClientOnline(ctx context.Context, service uint16, bodyI
 			},
 		}
 		s.messageRelayer.RelayToScreenName(ctx, sess.IdentScreenName(), msg)
+
+		if motd := s.motdManager.Message(); motd != "" {
+			s.messageRelayer.RelayToScreenName(ctx, sess.IdentScreenName(), newMOTDMessage(motd))
+		}
 	case wire.Chat:
 		room, err := s.chatRoomManager.ChatRoomByCookie(ctx, sess.ChatRoomCookie())
 		if err != nil {
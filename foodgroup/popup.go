@@ -0,0 +1,44 @@
+package foodgroup
+
+import (
+	"context"
+
+	"github.com/mk6i/retro-aim-server/state"
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// NewPopupService creates a new instance of PopupService.
+func NewPopupService(messageRelayer MessageRelayer) PopupService {
+	return PopupService{
+		messageRelayer: messageRelayer,
+	}
+}
+
+// PopupService provides functionality for the Popup food group, which lets
+// the server display popup windows on clients, such as announcements of
+// upcoming maintenance.
+type PopupService struct {
+	messageRelayer MessageRelayer
+}
+
+// Display shows a popup on the clients of the given users. Users that are
+// offline are skipped.
+func (s PopupService) Display(ctx context.Context, screenNames []state.IdentScreenName, body wire.SNAC_0x08_0x02_PopupDisplay) {
+	s.messageRelayer.RelayToScreenNames(ctx, screenNames, popupDisplay(body))
+}
+
+// DisplayAll shows a popup on the clients of all online users.
+func (s PopupService) DisplayAll(ctx context.Context, body wire.SNAC_0x08_0x02_PopupDisplay) {
+	s.messageRelayer.RelayToAll(ctx, popupDisplay(body))
+}
+
+func popupDisplay(body wire.SNAC_0x08_0x02_PopupDisplay) wire.SNACMessage {
+	return wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.Popup,
+			SubGroup:  wire.PopupDisplay,
+			RequestID: wire.ReqIDFromServer,
+		},
+		Body: body,
+	}
+}
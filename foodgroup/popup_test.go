@@ -0,0 +1,62 @@
+package foodgroup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mk6i/retro-aim-server/state"
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+func TestPopupService_Display(t *testing.T) {
+	body := wire.SNAC_0x08_0x02_PopupDisplay{
+		TLVRestBlock: wire.TLVRestBlock{
+			TLVList: wire.TLVList{
+				wire.NewTLVBE(wire.PopupTLVMessage, "<b>Maintenance at 10pm</b>"),
+			},
+		},
+	}
+	expect := wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.Popup,
+			SubGroup:  wire.PopupDisplay,
+			RequestID: wire.ReqIDFromServer,
+		},
+		Body: body,
+	}
+	recipients := []state.IdentScreenName{
+		state.NewIdentScreenName("userA"),
+		state.NewIdentScreenName("userB"),
+	}
+
+	messageRelayer := newMockMessageRelayer(t)
+	messageRelayer.EXPECT().
+		RelayToScreenNames(matchContext(), recipients, expect)
+
+	svc := NewPopupService(messageRelayer)
+	svc.Display(context.Background(), recipients, body)
+}
+
+func TestPopupService_DisplayAll(t *testing.T) {
+	body := wire.SNAC_0x08_0x02_PopupDisplay{
+		TLVRestBlock: wire.TLVRestBlock{
+			TLVList: wire.TLVList{
+				wire.NewTLVBE(wire.PopupTLVMessage, "<b>Maintenance at 10pm</b>"),
+			},
+		},
+	}
+
+	messageRelayer := newMockMessageRelayer(t)
+	messageRelayer.EXPECT().
+		RelayToAll(matchContext(), wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.Popup,
+				SubGroup:  wire.PopupDisplay,
+				RequestID: wire.ReqIDFromServer,
+			},
+			Body: body,
+		})
+
+	svc := NewPopupService(messageRelayer)
+	svc.DisplayAll(context.Background(), body)
+}
@@ -0,0 +1,104 @@
+package foodgroup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mk6i/retro-aim-server/state"
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// NewRateLimitService creates a new instance of RateLimitService.
+func NewRateLimitService(
+	classes RateLimitClassManager,
+	overrideManager RateLimitOverrideManager,
+	sessionRetriever SessionRetriever,
+) RateLimitService {
+	return RateLimitService{
+		classes:          classes,
+		overrideManager:  overrideManager,
+		sessionRetriever: sessionRetriever,
+	}
+}
+
+// RateLimitService manages the server's rate limit classes and per-account
+// overrides of them.
+type RateLimitService struct {
+	classes          RateLimitClassManager
+	overrideManager  RateLimitOverrideManager
+	sessionRetriever SessionRetriever
+}
+
+// Overrides returns the rate limit classes that override the server's
+// classes for a user. It returns state.ErrNoUser if the user does not exist.
+func (s RateLimitService) Overrides(ctx context.Context, screenName state.IdentScreenName) ([]wire.RateClass, error) {
+	u, err := s.overrideManager.User(ctx, screenName)
+	if err != nil {
+		return nil, fmt.Errorf("User: %w", err)
+	}
+	if u == nil {
+		return nil, state.ErrNoUser
+	}
+	return u.RateLimitOverrides, nil
+}
+
+// SetOverrides replaces the rate limit classes that override the server's
+// classes for a user. An empty list restores the server's classes. The
+// new classes take effect immediately for every active session of the user;
+// [OServiceService.RateLimitUpdates] notifies the clients of the change.
+// It returns state.ErrNoUser if the user does not exist.
+func (s RateLimitService) SetOverrides(ctx context.Context, screenName state.IdentScreenName, classes []wire.RateClass) error {
+	if err := s.overrideManager.SetRateLimitOverrides(ctx, screenName, classes); err != nil {
+		return fmt.Errorf("SetRateLimitOverrides: %w", err)
+	}
+
+	effective := s.classes.RateLimitClasses().WithOverrides(classes)
+	for _, sess := range s.sessionRetriever.RetrieveSessions(screenName) {
+		sess.SetRateClasses(time.Now(), effective)
+	}
+
+	return nil
+}
+
+// SetClasses replaces the rate limit classes that apply to accounts without
+// overrides, such as when the file at RATE_LIMITS_FILE changes. The new
+// classes take effect immediately for every active session whose classes
+// change; [OServiceService.RateLimitUpdates] notifies the clients of the
+// change. Chat sessions pick up the new classes when they next join a room.
+func (s RateLimitService) SetClasses(ctx context.Context, classes wire.RateLimitClasses) error {
+	s.classes.SetRateLimitClasses(classes)
+
+	overrides := make(map[state.IdentScreenName][]wire.RateClass)
+	for _, sess := range s.sessionRetriever.AllSessions() {
+		screenName := sess.IdentScreenName()
+		userOverrides, ok := overrides[screenName]
+		if !ok {
+			u, err := s.overrideManager.User(ctx, screenName)
+			if err != nil {
+				return fmt.Errorf("User: %w", err)
+			}
+			if u != nil {
+				userOverrides = u.RateLimitOverrides
+			}
+			overrides[screenName] = userOverrides
+		}
+
+		effective := classes.WithOverrides(userOverrides)
+		if rateClasses(sess) == effective.All() {
+			continue
+		}
+		sess.SetRateClasses(time.Now(), effective)
+	}
+
+	return nil
+}
+
+// rateClasses returns the rate limit classes that apply to a session.
+func rateClasses(sess *state.Session) [5]wire.RateClass {
+	var classes [5]wire.RateClass
+	for i, rateState := range sess.RateLimitStates() {
+		classes[i] = rateState.RateClass
+	}
+	return classes
+}
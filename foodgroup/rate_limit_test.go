@@ -0,0 +1,177 @@
+package foodgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mk6i/retro-aim-server/state"
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+func TestRateLimitService_Overrides(t *testing.T) {
+	errLookup := errors.New("lookup failed")
+	override := wire.RateClass{ID: 3, WindowSize: 10, ClearLevel: 1, AlertLevel: 2, LimitLevel: 3, DisconnectLevel: 1, MaxLevel: 4}
+
+	tests := []struct {
+		name    string
+		user    *state.User
+		userErr error
+		want    []wire.RateClass
+		wantErr error
+	}{
+		{
+			name: "user with overrides",
+			user: &state.User{RateLimitOverrides: []wire.RateClass{override}},
+			want: []wire.RateClass{override},
+		},
+		{
+			name: "user without overrides",
+			user: &state.User{},
+		},
+		{
+			name:    "user not found",
+			wantErr: state.ErrNoUser,
+		},
+		{
+			name:    "lookup error",
+			userErr: errLookup,
+			wantErr: errLookup,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrideManager := newMockRateLimitOverrideManager(t)
+			overrideManager.EXPECT().
+				User(matchContext(), state.NewIdentScreenName("them")).
+				Return(tt.user, tt.userErr)
+
+			svc := NewRateLimitService(state.NewRateLimits(wire.DefaultRateLimitClasses()), overrideManager, nil)
+			have, err := svc.Overrides(context.Background(), state.NewIdentScreenName("them"))
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, have)
+		})
+	}
+}
+
+func TestRateLimitService_SetOverrides(t *testing.T) {
+	override := wire.RateClass{ID: 3, WindowSize: 10, ClearLevel: 1, AlertLevel: 2, LimitLevel: 3, DisconnectLevel: 1, MaxLevel: 4}
+
+	t.Run("pushes new classes to active sessions", func(t *testing.T) {
+		sess := newTestSession("them")
+		sess.SubscribeRateLimits([]wire.RateLimitClassID{1, 2, 3, 4, 5})
+		sess.ObserveRateChanges(time.Now())
+
+		overrideManager := newMockRateLimitOverrideManager(t)
+		overrideManager.EXPECT().
+			SetRateLimitOverrides(matchContext(), state.NewIdentScreenName("them"), []wire.RateClass{override}).
+			Return(nil)
+		sessionRetriever := newMockSessionRetriever(t)
+		sessionRetriever.EXPECT().
+			RetrieveSessions(state.NewIdentScreenName("them")).
+			Return([]*state.Session{sess})
+
+		svc := NewRateLimitService(state.NewRateLimits(wire.DefaultRateLimitClasses()), overrideManager, sessionRetriever)
+		err := svc.SetOverrides(context.Background(), state.NewIdentScreenName("them"), []wire.RateClass{override})
+		assert.NoError(t, err)
+
+		assert.Equal(t, override, sess.RateLimitStates()[2].RateClass)
+		classDelta, _ := sess.ObserveRateChanges(time.Now())
+		if assert.Len(t, classDelta, 1) {
+			assert.Equal(t, override, classDelta[0].RateClass)
+		}
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		overrideManager := newMockRateLimitOverrideManager(t)
+		overrideManager.EXPECT().
+			SetRateLimitOverrides(matchContext(), state.NewIdentScreenName("them"), []wire.RateClass(nil)).
+			Return(state.ErrNoUser)
+
+		svc := NewRateLimitService(state.NewRateLimits(wire.DefaultRateLimitClasses()), overrideManager, nil)
+		err := svc.SetOverrides(context.Background(), state.NewIdentScreenName("them"), nil)
+		assert.ErrorIs(t, err, state.ErrNoUser)
+	})
+}
+
+func TestRateLimitService_SetClasses(t *testing.T) {
+	override := wire.RateClass{ID: 3, WindowSize: 10, ClearLevel: 1, AlertLevel: 2, LimitLevel: 3, DisconnectLevel: 1, MaxLevel: 4}
+	changed := wire.RateClass{ID: 1, WindowSize: 20, ClearLevel: 5100, AlertLevel: 5000, LimitLevel: 4000, DisconnectLevel: 3000, MaxLevel: 6000}
+	classes := wire.DefaultRateLimitClasses().WithOverrides([]wire.RateClass{changed})
+
+	t.Run("pushes new classes to active sessions", func(t *testing.T) {
+		plain := newTestSession("plain")
+		plain.SubscribeRateLimits([]wire.RateLimitClassID{1, 2, 3, 4, 5})
+		plain.ObserveRateChanges(time.Now())
+		withOverrides := newTestSession("bot")
+		withOverrides.SetRateClasses(time.Now(), wire.DefaultRateLimitClasses().WithOverrides([]wire.RateClass{override}))
+
+		overrideManager := newMockRateLimitOverrideManager(t)
+		overrideManager.EXPECT().
+			User(matchContext(), state.NewIdentScreenName("plain")).
+			Return(&state.User{}, nil)
+		overrideManager.EXPECT().
+			User(matchContext(), state.NewIdentScreenName("bot")).
+			Return(&state.User{RateLimitOverrides: []wire.RateClass{override}}, nil)
+		sessionRetriever := newMockSessionRetriever(t)
+		sessionRetriever.EXPECT().
+			AllSessions().
+			Return([]*state.Session{plain, withOverrides})
+
+		rateLimits := state.NewRateLimits(wire.DefaultRateLimitClasses())
+		svc := NewRateLimitService(rateLimits, overrideManager, sessionRetriever)
+		err := svc.SetClasses(context.Background(), classes)
+		assert.NoError(t, err)
+
+		assert.Equal(t, classes, rateLimits.RateLimitClasses())
+		assert.Equal(t, changed, plain.RateLimitStates()[0].RateClass)
+		classDelta, _ := plain.ObserveRateChanges(time.Now())
+		if assert.Len(t, classDelta, 1) {
+			assert.Equal(t, changed, classDelta[0].RateClass)
+		}
+		assert.Equal(t, changed, withOverrides.RateLimitStates()[0].RateClass)
+		assert.Equal(t, override, withOverrides.RateLimitStates()[2].RateClass)
+	})
+
+	t.Run("leaves sessions with unchanged classes alone", func(t *testing.T) {
+		sess := newTestSession("them")
+		sess.SetRateClasses(time.Now(), classes)
+		sess.SubscribeRateLimits([]wire.RateLimitClassID{1, 2, 3, 4, 5})
+		sess.ObserveRateChanges(time.Now())
+
+		overrideManager := newMockRateLimitOverrideManager(t)
+		overrideManager.EXPECT().
+			User(matchContext(), state.NewIdentScreenName("them")).
+			Return(&state.User{}, nil)
+		sessionRetriever := newMockSessionRetriever(t)
+		sessionRetriever.EXPECT().
+			AllSessions().
+			Return([]*state.Session{sess})
+
+		svc := NewRateLimitService(state.NewRateLimits(wire.DefaultRateLimitClasses()), overrideManager, sessionRetriever)
+		err := svc.SetClasses(context.Background(), classes)
+		assert.NoError(t, err)
+
+		classDelta, _ := sess.ObserveRateChanges(time.Now())
+		assert.Empty(t, classDelta)
+	})
+
+	t.Run("user lookup error", func(t *testing.T) {
+		errLookup := errors.New("lookup failed")
+		overrideManager := newMockRateLimitOverrideManager(t)
+		overrideManager.EXPECT().
+			User(matchContext(), state.NewIdentScreenName("them")).
+			Return(nil, errLookup)
+		sessionRetriever := newMockSessionRetriever(t)
+		sessionRetriever.EXPECT().
+			AllSessions().
+			Return([]*state.Session{newTestSession("them")})
+
+		svc := NewRateLimitService(state.NewRateLimits(wire.DefaultRateLimitClasses()), overrideManager, sessionRetriever)
+		err := svc.SetClasses(context.Background(), classes)
+		assert.ErrorIs(t, err, errLookup)
+	})
+}
@@ -38,6 +38,7 @@ import (
 	"net/mail"
 	"time"
 
+	"github.com/mk6i/retro-aim-server/mailer"
 	"github.com/mk6i/retro-aim-server/state"
 	"github.com/mk6i/retro-aim-server/wire"
 )
@@ -60,6 +61,10 @@ type AccountManager interface {
 	// SetUserPassword sets the user's password hashes and auth key.
 	SetUserPassword(ctx context.Context, screenName state.IdentScreenName, newPassword string) error
 
+	// IssueEmailToken creates a single-use token that can be redeemed for
+	// purpose until expiresAt.
+	IssueEmailToken(ctx context.Context, screenName state.IdentScreenName, purpose state.EmailTokenPurpose, expiresAt time.Time) (string, error)
+
 	// UpdateConfirmStatus sets whether a user account has been confirmed.
 	UpdateConfirmStatus(ctx context.Context, screenName state.IdentScreenName, confirmStatus bool) error
 
@@ -289,6 +294,20 @@ type MessageRelayer interface {
 
 	// RelayToScreenName sends the given SNAC message to a single screen name.
 	RelayToScreenName(ctx context.Context, screenName state.IdentScreenName, msg wire.SNACMessage)
+
+	// RelayToOtherInstances sends the given SNAC message to the sessions of
+	// sess's user other than sess.
+	RelayToOtherInstances(ctx context.Context, sess *state.Session, msg wire.SNACMessage)
+
+	// RelayToAll sends the given SNAC message to all online users.
+	RelayToAll(ctx context.Context, msg wire.SNACMessage)
+}
+
+// IMArchiver records instant messages in the server-side IM archive.
+type IMArchiver interface {
+	// ArchiveIM records an instant message, unless the sender or recipient
+	// opted out of archiving.
+	ArchiveIM(ctx context.Context, im state.ArchivedIM) error
 }
 
 // OfflineMessageManager defines operations for managing offline messages.
@@ -296,14 +315,25 @@ type MessageRelayer interface {
 // and are retrieved once the recipient comes online. Offline messages are
 // available in all ICQ versions and AIM 6+.
 type OfflineMessageManager interface {
-	// DeleteMessages removes all offline messages for the specified recipient.
+	// DeleteMessages removes all offline messages for the specified
+	// recipient.
 	DeleteMessages(ctx context.Context, recip state.IdentScreenName) error
 
-	// RetrieveMessages returns all offline messages for the specified recipient.
+	// DeleteMessagesByID removes the offline messages for the specified
+	// recipient that have the given IDs, leaving alone any that were queued
+	// since they were retrieved.
+	DeleteMessagesByID(ctx context.Context, recip state.IdentScreenName, ids []int64) error
+
+	// RetrieveMessages returns all offline messages for the specified
+	// recipient.
 	RetrieveMessages(ctx context.Context, recip state.IdentScreenName) ([]state.OfflineMessage, error)
 
-	// SaveMessage stores a new offline message for delivery when the recipient comes online.
-	SaveMessage(ctx context.Context, offlineMessage state.OfflineMessage) error
+	// SaveMessage stores a new offline message for delivery when the
+	// recipient comes online. Returns state.ErrNoUser if the recipient
+	// doesn't exist, or state.ErrOfflineQueueFull if maxQueued messages are
+	// already queued for the recipient. A maxQueued of 0 means there is no
+	// limit.
+	SaveMessage(ctx context.Context, offlineMessage state.OfflineMessage, maxQueued int) error
 }
 
 // ProfileManager defines methods for managing and querying AIM user profiles,
@@ -340,27 +370,130 @@ type ProfileManager interface {
 }
 
 // SessionRegistry defines methods for managing active user sessions.
-// It ensures that only one session is active per screen name at any given time.
+// A screen name may have several active sessions only if every one of them
+// allows multiple sessions.
 type SessionRegistry interface {
-	// AddSession adds a new session to the pool, enforcing a one-session-per-screen-name policy.
-	// If a session for the given screen name is already active, this call blocks until the active
-	// session is removed via [SessionRegistry.RemoveSession] or the context is canceled.
+	// AddSession adds a new session to the pool. multiSess indicates whether
+	// the client can be signed on alongside other clients using the same
+	// screen name. If a session for the given screen name is already active
+	// and either it or the new session doesn't allow multiple sessions, this
+	// call blocks until the active session is removed via
+	// [SessionRegistry.RemoveSession] or the context is canceled.
 	//
 	// When multiple concurrent calls are made for the same screen name, only one will succeed;
 	// the others will return an error once the context is done.
-	AddSession(ctx context.Context, screenName state.DisplayScreenName) (*state.Session, error)
+	AddSession(ctx context.Context, screenName state.DisplayScreenName, multiSess bool) (*state.Session, error)
 
 	// RemoveSession removes the given session from the registry, allowing future sessions
 	// to be created for the same screen name.
 	RemoveSession(sess *state.Session)
+
+	// SignoffSession removes the given session from the registry after
+	// calling cleanup. lastInstance indicates whether the session is the
+	// user's last signed-on instance. The decision is atomic, so that of
+	// several sessions signing off at once, exactly one is the last. Until
+	// cleanup returns, the session is no longer retrievable and new sessions
+	// for the user are held back.
+	SignoffSession(sess *state.Session, cleanup func(lastInstance bool))
 }
 
-// SessionRetriever defines a method for retrieving an active session
+// SessionRetriever defines methods for retrieving the active sessions
 // associated with a given screen name.
 type SessionRetriever interface {
+	// AllSessions returns every active session.
+	AllSessions() []*state.Session
+
 	// RetrieveSession returns the session associated with the given screen name,
-	// or nil if no active session exists.
+	// or nil if no active session exists. If the user is signed on from
+	// several places, it returns the most recent session.
 	RetrieveSession(screenName state.IdentScreenName) *state.Session
+
+	// RetrieveSessions returns every active session associated with the
+	// given screen name, or nil if no active session exists.
+	RetrieveSessions(screenName state.IdentScreenName) []*state.Session
+}
+
+// AuthProvider verifies plaintext passwords against an external identity
+// source, such as a directory server, in place of the password hashes kept
+// in the user store.
+type AuthProvider interface {
+	// Authenticate indicates whether password is correct for screenName.
+	Authenticate(ctx context.Context, screenName state.DisplayScreenName, password []byte) (bool, error)
+}
+
+// AuthModeRetriever provides the authentication settings that can change
+// while the server runs.
+type AuthModeRetriever interface {
+	// AuthDisabled indicates whether password checks are disabled.
+	AuthDisabled() bool
+
+	// AuthProvider returns the provider that checks passwords, or nil if
+	// passwords are checked against the user store.
+	AuthProvider() state.AuthProvider
+}
+
+// InviteManager records the invitations users send to their friends.
+type InviteManager interface {
+	// DeleteInvite removes an invitation. It returns state.ErrNoInvite if
+	// the invitation does not exist.
+	DeleteInvite(ctx context.Context, id int64) error
+
+	// InsertInvite records an invitation and returns its ID. If limit is
+	// greater than 0 and the sender already sent limit invitations since the
+	// given time, the invitation isn't recorded and
+	// state.ErrInviteLimitReached is returned.
+	InsertInvite(ctx context.Context, invite state.Invite, since time.Time, limit int) (int64, error)
+}
+
+// Mailer delivers email notifications to users.
+type Mailer interface {
+	// Send delivers msg to its recipient.
+	Send(ctx context.Context, msg mailer.Message) error
+}
+
+// MOTDManager stores the message of the day.
+type MOTDManager interface {
+	// Message returns the message of the day, or an empty string if none is
+	// set.
+	Message() string
+
+	// SetMessage replaces the message of the day. An empty message clears
+	// it.
+	SetMessage(message string)
+}
+
+// LoginLockoutManager tracks failed login attempts per account and decides
+// whether an account is temporarily locked out.
+type LoginLockoutManager interface {
+	// IsLocked indicates whether the account is currently locked out.
+	IsLocked(screenName state.IdentScreenName) bool
+
+	// RecordFailure registers a failed login attempt for the account.
+	RecordFailure(screenName state.IdentScreenName)
+
+	// RecordSuccess clears the failed login attempts for the account.
+	RecordSuccess(screenName state.IdentScreenName)
+}
+
+// RateLimitClassManager stores the rate limit classes that apply to accounts
+// without overrides.
+type RateLimitClassManager interface {
+	// RateLimitClasses returns the rate limit classes.
+	RateLimitClasses() wire.RateLimitClasses
+
+	// SetRateLimitClasses replaces the rate limit classes.
+	SetRateLimitClasses(classes wire.RateLimitClasses)
+}
+
+// RateLimitOverrideManager defines methods for accessing and updating the
+// rate limit classes that override the server's classes for a user.
+type RateLimitOverrideManager interface {
+	// User returns the user record associated with the given screen name.
+	User(ctx context.Context, screenName state.IdentScreenName) (*state.User, error)
+
+	// SetRateLimitOverrides replaces the user's rate limit overrides. Return
+	// state.ErrNoUser if the user does not exist.
+	SetRateLimitOverrides(ctx context.Context, screenName state.IdentScreenName, classes []wire.RateClass) error
 }
 
 // UserManager defines methods for accessing and inserting AIM user records.
@@ -372,6 +505,22 @@ type UserManager interface {
 	// User returns the user record associated with the given screen name.
 	User(ctx context.Context, screenName state.IdentScreenName) (*state.User, error)
 
+	// NextUIN returns the next unassigned UIN in the range [min, max].
+	// Returns state.ErrUINRangeExhausted if the range is used up.
+	NextUIN(ctx context.Context, min uint32, max uint32) (uint32, error)
+
 	// SetWarnLevel updates the last warn update time and warning level for a user.
 	SetWarnLevel(ctx context.Context, user state.IdentScreenName, lastWarnUpdate time.Time, lastWarnLevel uint16) error
+
+	// SetPasswordHash stores a bcrypt password hash for a user that
+	// previously only had legacy MD5 password hashes.
+	SetPasswordHash(ctx context.Context, screenName state.IdentScreenName, passwordHash string) error
+
+	// SetPasswordHashes replaces a user's bcrypt and MD5 password hashes
+	// with those held by u.
+	SetPasswordHashes(ctx context.Context, u state.User) error
+
+	// ValidateSecondFactor checks a TOTP or recovery code for a user
+	// enrolled in two-factor auth.
+	ValidateSecondFactor(ctx context.Context, u state.User, code string) (bool, error)
 }
@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// ConfirmPath is the HTTP path that redeems account confirmation
+	// tokens.
+	ConfirmPath = "/account/confirm"
+	// PasswordResetPath is the HTTP path that redeems password reset tokens.
+	PasswordResetPath = "/account/password-reset"
+)
+
+// Link returns the URL under baseURL at which token is redeemed.
+func Link(baseURL string, path string, token string) string {
+	return strings.TrimRight(baseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// NewConfirmationMessage creates the email that asks a user to confirm
+// their account by visiting link.
+func NewConfirmationMessage(to *mail.Address, screenName string, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "Confirm your account",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Please confirm your account by visiting the following link:\n\n"+
+			"%s\n\n"+
+			"The link expires in %s. If you didn't request this, you can ignore this email.\n",
+			screenName, link, formatTTL(ttl)),
+	}
+}
+
+// NewPasswordResetMessage creates the email that lets a user choose a new
+// password by visiting link.
+func NewPasswordResetMessage(to *mail.Address, screenName string, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password for your account. To choose a new password, visit the following link:\n\n"+
+			"%s\n\n"+
+			"The link expires in %s. If you didn't request this, you can ignore this email and your password will stay the same.\n",
+			screenName, link, formatTTL(ttl)),
+	}
+}
+
+// NewPasswordChangedMessage creates the email that notifies a user that
+// their password was changed.
+func NewPasswordChangedMessage(to *mail.Address, screenName string) Message {
+	return Message{
+		To:      to,
+		Subject: "Your password was changed",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"The password for your account was just changed. If you didn't do this, contact the server operator right away.\n",
+			screenName),
+	}
+}
+
+// NewInviteMessage creates the email that invites the recipient to join the
+// service on behalf of sender. note is the sender's personal message, if
+// any, and link points the recipient to where they can learn more.
+func NewInviteMessage(to *mail.Address, sender string, note string, link string) Message {
+	body := &strings.Builder{}
+	fmt.Fprintf(body, "Hi,\n\n%s has invited you to chat with them on AIM.\n\n", sender)
+	if note != "" {
+		fmt.Fprintf(body, "They wrote:\n\n%s\n\n", note)
+	}
+	fmt.Fprintf(body, "To get started, visit the following link:\n\n%s\n", link)
+	return Message{
+		To:      to,
+		Subject: fmt.Sprintf("%s has invited you to AIM", sender),
+		Body:    body.String(),
+	}
+}
+
+// formatTTL renders a token lifetime in whole hours or minutes.
+func formatTTL(ttl time.Duration) string {
+	switch {
+	case ttl >= time.Hour && ttl%time.Hour == 0:
+		if ttl == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", ttl/time.Hour)
+	case ttl == time.Minute:
+		return "1 minute"
+	default:
+		return fmt.Sprintf("%d minutes", ttl/time.Minute)
+	}
+}
@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// OutboxMailer writes each message to a .eml file in a directory instead of
+// delivering it. It's useful for local development and for operators that
+// hand off mail to another process.
+type OutboxMailer struct {
+	dir     string
+	from    *mail.Address
+	timeNow func() time.Time
+}
+
+// NewOutboxMailer creates a new instance of OutboxMailer that writes
+// messages to dir, which is created if it doesn't exist.
+func NewOutboxMailer(dir string, from *mail.Address) *OutboxMailer {
+	return &OutboxMailer{
+		dir:     dir,
+		from:    from,
+		timeNow: time.Now,
+	}
+}
+
+// Send writes msg to a new file in the outbox directory. File names sort in
+// the order messages were sent.
+func (m *OutboxMailer) Send(_ context.Context, msg Message) error {
+	now := m.timeNow()
+	data, err := msg.encode(m.from, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o750); err != nil {
+		return fmt.Errorf("error creating outbox directory: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	// write to a temp file first so that readers of the outbox never see a
+	// partially written message
+	tmp, err := os.CreateTemp(m.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating outbox file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("error writing outbox file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("error writing outbox file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(m.dir, name)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("error writing outbox file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+
+	m := NewOutboxMailer(dir, &mail.Address{Address: "noreply@aim.example.com"})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.timeNow = func() time.Time {
+		return now
+	}
+
+	to := &mail.Address{Name: "Joe", Address: "joe@example.com"}
+	require.NoError(t, m.Send(context.Background(), NewPasswordChangedMessage(to, "Joe Smith")))
+	now = now.Add(time.Second)
+	require.NoError(t, m.Send(context.Background(), NewConfirmationMessage(to, "Joe Smith", "https://aim.example.com/account/confirm?token=abc", 72*time.Hour)))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// files sort in the order they were sent
+	b, err := os.ReadFile(filepath.Join(dir, entries[1].Name()))
+	require.NoError(t, err)
+	msg, err := mail.ReadMessage(bytes.NewReader(b))
+	require.NoError(t, err)
+	assert.Equal(t, `"Joe" <joe@example.com>`, msg.Header.Get("To"))
+	assert.Equal(t, "Confirm your account", msg.Header.Get("Subject"))
+
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "Hi Joe Smith,")
+	assert.Contains(t, string(body), "https://aim.example.com/account/confirm?token=abc")
+	assert.Contains(t, string(body), "The link expires in 72 hours.")
+}
@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// smtpTimeout is how long to wait for the SMTP server to deliver a message
+// when the context has no deadline.
+const smtpTimeout = 30 * time.Second
+
+// SMTPMailer delivers messages via an SMTP relay. It upgrades the
+// connection with STARTTLS when the server supports it.
+type SMTPMailer struct {
+	addr     string
+	from     *mail.Address
+	password string
+	timeNow  func() time.Time
+	username string
+}
+
+// NewSMTPMailer creates a new instance of SMTPMailer. addr is the host:port
+// of the SMTP server. If username is set, the mailer authenticates with
+// PLAIN auth, which net/smtp only permits over TLS or to localhost.
+func NewSMTPMailer(addr string, username string, password string, from *mail.Address) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     addr,
+		from:     from,
+		password: password,
+		timeNow:  time.Now,
+		username: username,
+	}
+}
+
+// Send delivers msg via the SMTP server.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, err := msg.encode(m.from, m.timeNow())
+	if err != nil {
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(m.addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %q: %w", m.addr, err)
+	}
+
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return fmt.Errorf("error connecting to SMTP server: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("error starting SMTP session: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("error starting TLS: %w", err)
+		}
+	}
+	if m.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.username, m.password, host)); err != nil {
+			return fmt.Errorf("error authenticating to SMTP server: %w", err)
+		}
+	}
+	if err := c.Mail(m.from.Address); err != nil {
+		return fmt.Errorf("error setting sender: %w", err)
+	}
+	if err := c.Rcpt(msg.To.Address); err != nil {
+		return fmt.Errorf("error setting recipient: %w", err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("error starting message body: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("error writing message body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+
+	return c.Quit()
+}
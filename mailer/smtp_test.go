@@ -0,0 +1,121 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts a single SMTP session and records the envelope and
+// message data.
+type fakeSMTPServer struct {
+	listener net.Listener
+	done     chan struct{}
+	from     string
+	rcpt     string
+	data     string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSMTPServer{listener: l, done: make(chan struct{})}
+	go s.serve(t)
+	return s
+}
+
+func (s *fakeSMTPServer) serve(t *testing.T) {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	reply := func(line string) {
+		assert.NoError(t, tp.PrintfLine("%s", line))
+	}
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.Fields(line)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			s.from = line
+			reply("250 OK")
+		case "RCPT":
+			s.rcpt = line
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.data = string(data)
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	srv := newFakeSMTPServer(t)
+	defer srv.listener.Close()
+
+	from := &mail.Address{Name: "Retro AIM Server", Address: "noreply@aim.example.com"}
+	m := NewSMTPMailer(srv.listener.Addr().String(), "", "", from)
+	m.timeNow = func() time.Time {
+		return time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	msg := Message{
+		To:      &mail.Address{Address: "user@example.com"},
+		Subject: "Confirm your account",
+		Body:    "Hi there,\nvisit https://aim.example.com/account/confirm?token=abc=\n",
+	}
+	require.NoError(t, m.Send(context.Background(), msg))
+	<-srv.done
+
+	assert.Equal(t, "MAIL FROM:<noreply@aim.example.com>", srv.from)
+	assert.Equal(t, "RCPT TO:<user@example.com>", srv.rcpt)
+
+	parsed, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(srv.data)))
+	require.NoError(t, err)
+	assert.Equal(t, `"Retro AIM Server" <noreply@aim.example.com>`, parsed.Header.Get("From"))
+	assert.Equal(t, "<user@example.com>", parsed.Header.Get("To"))
+	assert.Equal(t, "Confirm your account", parsed.Header.Get("Subject"))
+	assert.Equal(t, "Wed, 01 Jan 2025 12:00:00 +0000", parsed.Header.Get("Date"))
+	assert.True(t, strings.HasSuffix(parsed.Header.Get("Message-ID"), "@aim.example.com>"))
+	assert.Equal(t, "quoted-printable", parsed.Header.Get("Content-Transfer-Encoding"))
+}
+
+func TestSMTPMailer_Send_ConnectionRefused(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	m := NewSMTPMailer(addr, "", "", &mail.Address{Address: "noreply@aim.example.com"})
+	err = m.Send(context.Background(), Message{To: &mail.Address{Address: "user@example.com"}})
+	assert.ErrorContains(t, err, "error connecting to SMTP server")
+}
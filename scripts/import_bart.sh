@@ -16,6 +16,7 @@ fi
 
 # Default values
 API_BASE_URL="http://localhost:8080"
+API_AUTH=""
 VERBOSE=false
 DRY_RUN=false
 BART_TYPE=""
@@ -46,12 +47,14 @@ usage() {
     echo "                   im_sound, im_chrome_xml, im_chrome_immers, emoticon_set,"
     echo "                   encr_cert_chain, sign_cert_chain, gateway_cert"
     echo "  -u, --url URL     API base URL (default: http://localhost:8080)"
+    echo "  -a, --auth USER:PASSWORD"
+    echo "                   Management API account credentials"
     echo "  -v, --verbose     Enable verbose output"
     echo "  -d, --dry-run     Show what would be uploaded without actually uploading"
     echo "  -h, --help        Show this help message"
     echo ""
     echo "Examples:"
-    echo "  $0 -t buddy_icon /path/to/bart/abc123def456"
+    echo "  $0 -t buddy_icon -a admin:thepassword /path/to/bart/abc123def456"
     echo "  $0 --type status_str --verbose --dry-run /path/to/file1 /path/to/file2"
     echo "  $0 -t arrive_sound /path/to/files/*"
 }
@@ -156,17 +159,29 @@ check_prerequisites() {
     fi
 }
 
+# api_curl runs curl with the management API credentials, if any.
+api_curl() {
+    if [ -n "$API_AUTH" ]; then
+        curl -u "$API_AUTH" "$@"
+    else
+        curl "$@"
+    fi
+}
+
 test_api() {
     log_info "Testing API connectivity..."
 
     local response
     local http_code
-    if response=$(curl -s -w "%{http_code}" "$API_BASE_URL/bart?type=0" 2>/dev/null); then
+    if response=$(api_curl -s -w "%{http_code}" "$API_BASE_URL/bart?type=0" 2>/dev/null); then
         # Extract HTTP code from response (last 3 characters)
         http_code=$(echo "$response" | tail -c 4)
         if [ "$http_code" = "200" ]; then
             log_success "API is accessible"
             return 0
+        elif [ "$http_code" = "401" ]; then
+            log_error "API rejected the credentials; pass them with --auth USER:PASSWORD"
+            return 1
         else
             log_error "API returned HTTP $http_code"
             return 1
@@ -193,7 +208,7 @@ upload_bart_asset() {
     local http_code
 
     # Upload the file
-    if response=$(curl -s -w "%{http_code}" \
+    if response=$(api_curl -s -w "%{http_code}" \
         -X POST \
         -H "Content-Type: application/octet-stream" \
         --data-binary "@$file_path" \
@@ -277,6 +292,10 @@ while [[ $# -gt 0 ]]; do
             API_BASE_URL="$2"
             shift 2
             ;;
+        -a|--auth)
+            API_AUTH="$2"
+            shift 2
+            ;;
         -v|--verbose)
             VERBOSE=true
             shift
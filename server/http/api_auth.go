@@ -0,0 +1,498 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mk6i/retro-aim-server/state"
+)
+
+// APIAdminManager defines methods for authenticating and managing the
+// accounts that may access the management API.
+type APIAdminManager interface {
+	// AuthenticateAPIAdmin returns the account identified by username and
+	// password, or nil if the credentials are invalid.
+	AuthenticateAPIAdmin(ctx context.Context, username, password string) (*state.APIAdmin, error)
+
+	// AuthenticateAPIAdminToken returns the account that a bearer token was
+	// issued for, or nil if the token is invalid.
+	AuthenticateAPIAdminToken(ctx context.Context, token string) (*state.APIAdmin, error)
+
+	// APIAdmins returns all accounts.
+	APIAdmins(ctx context.Context) ([]state.APIAdmin, error)
+
+	// InsertAPIAdmin creates an account. Return state.ErrDupAPIAdmin if the
+	// username is taken.
+	InsertAPIAdmin(ctx context.Context, username, password string, role state.APIRole, createdAt time.Time) error
+
+	// SetAPIAdminRole changes the role of an account. Return
+	// state.ErrNoAPIAdmin if the account does not exist.
+	SetAPIAdminRole(ctx context.Context, username string, role state.APIRole) error
+
+	// SetAPIAdminPassword changes the password of an account. Return
+	// state.ErrNoAPIAdmin if the account does not exist.
+	SetAPIAdminPassword(ctx context.Context, username, password string) error
+
+	// DeleteAPIAdmin removes an account. Return state.ErrNoAPIAdmin if the
+	// account does not exist.
+	DeleteAPIAdmin(ctx context.Context, username string) error
+
+	// IssueAPIAdminToken creates a bearer token for an account, replacing
+	// its previous token. Return state.ErrNoAPIAdmin if the account does not
+	// exist.
+	IssueAPIAdminToken(ctx context.Context, username string) (string, error)
+
+	// RevokeAPIAdminToken removes the bearer token of an account. Return
+	// state.ErrNoAPIAdmin if the account does not exist.
+	RevokeAPIAdminToken(ctx context.Context, username string) error
+}
+
+// maxAPIAdminUsernameLen is the longest username a management API account
+// may have.
+const maxAPIAdminUsernameLen = 64
+
+// publicRoutes are the routes that don't require a management API account.
+// They are visited by AIM users following emailed links, requesting a
+// password reset or checking their own credentials and settings, and
+// authenticate with their own screen name and password where needed.
+var publicRoutes = map[string]bool{
+	"GET /user/login":               true,
+	"GET /account/confirm":          true,
+	"GET /account/forgot-password":  true,
+	"POST /account/forgot-password": true,
+	"GET /account/password-reset":   true,
+	"POST /account/password-reset":  true,
+	"GET /account/im-archive":       true,
+	"PUT /account/im-archive":       true,
+}
+
+// routeRoles maps each route to the role required to call it. Routes that
+// aren't listed here or in publicRoutes require state.APIRoleAdmin.
+var routeRoles = map[string]state.APIRole{
+	"GET /user":                                  state.APIRoleReadOnly,
+	"POST /user":                                 state.APIRoleAdmin,
+	"DELETE /user":                               state.APIRoleAdmin,
+	"PUT /user/password":                         state.APIRoleAdmin,
+	"GET /user/password/legacy":                  state.APIRoleAdmin,
+	"GET /user/lockout":                          state.APIRoleReadOnly,
+	"GET /user/{screenname}/account":             state.APIRoleReadOnly,
+	"PATCH /user/{screenname}/account":           state.APIRoleModerator,
+	"DELETE /user/{screenname}/lockout":          state.APIRoleModerator,
+	"POST /user/{screenname}/password-reset":     state.APIRoleModerator,
+	"POST /user/{screenname}/totp":               state.APIRoleAdmin,
+	"DELETE /user/{screenname}/totp":             state.APIRoleAdmin,
+	"GET /user/{screenname}/rate-limits":         state.APIRoleReadOnly,
+	"PUT /user/{screenname}/rate-limits":         state.APIRoleAdmin,
+	"DELETE /user/{screenname}/rate-limits":      state.APIRoleAdmin,
+	"GET /user/{screenname}/im-archive":          state.APIRoleReadOnly,
+	"PUT /user/{screenname}/im-archive":          state.APIRoleAdmin,
+	"GET /user/{screenname}/offline-messages":    state.APIRoleAdmin,
+	"DELETE /user/{screenname}/offline-messages": state.APIRoleModerator,
+	"GET /user/{screenname}/icon":                state.APIRoleReadOnly,
+	"GET /invite":                                state.APIRoleReadOnly,
+	"DELETE /invite/{id}":                        state.APIRoleModerator,
+	"GET /session":                               state.APIRoleReadOnly,
+	"GET /session/{screenname}":                  state.APIRoleReadOnly,
+	"DELETE /session/{screenname}":               state.APIRoleModerator,
+	"GET /chat/room/public":                      state.APIRoleReadOnly,
+	"POST /chat/room/public":                     state.APIRoleModerator,
+	"DELETE /chat/room/public":                   state.APIRoleModerator,
+	"GET /chat/room/private":                     state.APIRoleReadOnly,
+	"POST /instant-message":                      state.APIRoleModerator,
+	"POST /popup":                                state.APIRoleModerator,
+	"GET /motd":                                  state.APIRoleReadOnly,
+	"PUT /motd":                                  state.APIRoleModerator,
+	"DELETE /motd":                               state.APIRoleModerator,
+	"GET /stats/relationship-cache":              state.APIRoleReadOnly,
+	"GET /stats/keepalive":                       state.APIRoleReadOnly,
+	"GET /stats/outbound-queue":                  state.APIRoleReadOnly,
+	"POST /config/reload":                        state.APIRoleAdmin,
+	"GET /version":                               state.APIRoleReadOnly,
+	"GET /directory/category":                    state.APIRoleReadOnly,
+	"POST /directory/category":                   state.APIRoleModerator,
+	"DELETE /directory/category/{id}":            state.APIRoleModerator,
+	"GET /directory/category/{id}/keyword":       state.APIRoleReadOnly,
+	"POST /directory/keyword":                    state.APIRoleModerator,
+	"DELETE /directory/keyword/{id}":             state.APIRoleModerator,
+	"GET /bart":                                  state.APIRoleReadOnly,
+	"GET /bart/{hash}":                           state.APIRoleReadOnly,
+	"POST /bart/{hash}":                          state.APIRoleModerator,
+	"DELETE /bart/{hash}":                        state.APIRoleModerator,
+	"POST /admin/webapi/keys":                    state.APIRoleAdmin,
+	"GET /admin/webapi/keys":                     state.APIRoleAdmin,
+	"GET /admin/webapi/keys/{id}":                state.APIRoleAdmin,
+	"PUT /admin/webapi/keys/{id}":                state.APIRoleAdmin,
+	"DELETE /admin/webapi/keys/{id}":             state.APIRoleAdmin,
+	"GET /admin/account":                         state.APIRoleAdmin,
+	"POST /admin/account":                        state.APIRoleAdmin,
+	"PATCH /admin/account/{username}":            state.APIRoleAdmin,
+	"DELETE /admin/account/{username}":           state.APIRoleAdmin,
+	"POST /admin/account/{username}/token":       state.APIRoleAdmin,
+	"DELETE /admin/account/{username}/token":     state.APIRoleAdmin,
+	"GET /audit":                                 state.APIRoleAdmin,
+	"GET /im/history":                            state.APIRoleAdmin,
+}
+
+// apiAdminCtxKey is the request context key of the authenticated account.
+type apiAdminCtxKey struct{}
+
+// apiAdminFromContext returns the account that made the request, or nil if
+// the route is public.
+func apiAdminFromContext(ctx context.Context) *state.APIAdmin {
+	admin, _ := ctx.Value(apiAdminCtxKey{}).(*state.APIAdmin)
+	return admin
+}
+
+// requireRole authenticates requests with HTTP basic auth or a bearer token
+// and passes them to next, rejecting those whose account lacks the role
+// required by the route they match in mux. Failed attempts count toward the
+// lockout of the account in adminLockout and of the client IP address in
+// clientLockout.
+func requireRole(mux *http.ServeMux, next http.Handler, adminManager APIAdminManager, adminLockout LoginLockoutManager, clientLockout LoginLockoutManager, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := mux.Handler(r)
+		if publicRoutes[pattern] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		admin, err := authenticateAPIAdmin(r, adminManager, adminLockout, clientLockout, logger)
+		if err != nil {
+			logger.Error("error authenticating management API request", "err", err.Error())
+			errorMsg(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		if admin == nil {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Retro AIM Server Management API"`)
+			errorMsg(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		required, ok := routeRoles[pattern]
+		switch {
+		case pattern == "":
+			// let the mux respond with 404 or 405
+			required = state.APIRoleReadOnly
+		case !ok:
+			required = state.APIRoleAdmin
+		}
+		if !admin.Role.Includes(required) {
+			logger.Info("management API request denied", "username", admin.Username, "role", admin.Role, "route", pattern)
+			errorMsg(w, "this operation requires the "+string(required)+" role", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiAdminCtxKey{}, admin)))
+	})
+}
+
+// authenticateAPIAdmin returns the account identified by the request's
+// Authorization header, or nil if the header is missing or invalid. Requests
+// from a locked out client, or for a locked out account, are rejected
+// without checking the credentials. The lockout trackers are keyed by screen
+// name, so usernames and client IPs are normalized the same way.
+func authenticateAPIAdmin(r *http.Request, adminManager APIAdminManager, adminLockout LoginLockoutManager, clientLockout LoginLockoutManager, logger *slog.Logger) (*state.APIAdmin, error) {
+	client := state.NewIdentScreenName(clientIP(r))
+
+	if username, password, ok := r.BasicAuth(); ok {
+		account := state.NewIdentScreenName(username)
+		if clientLockout.IsLocked(client) || adminLockout.IsLocked(account) {
+			logger.Info("management API login rejected, locked out", "username", username, "ip", client.String())
+			return nil, nil
+		}
+		admin, err := adminManager.AuthenticateAPIAdmin(r.Context(), username, password)
+		switch {
+		case err != nil:
+			return nil, err
+		case admin == nil:
+			adminLockout.RecordFailure(account)
+			clientLockout.RecordFailure(client)
+		default:
+			adminLockout.RecordSuccess(account)
+		}
+		return admin, nil
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		if clientLockout.IsLocked(client) {
+			logger.Info("management API login rejected, locked out", "ip", client.String())
+			return nil, nil
+		}
+		admin, err := adminManager.AuthenticateAPIAdminToken(r.Context(), strings.TrimSpace(token))
+		if err == nil && admin == nil {
+			clientLockout.RecordFailure(client)
+		}
+		return admin, err
+	}
+	return nil, nil
+}
+
+// getAPIAdminHandler handles the GET /admin/account endpoint.
+func getAPIAdminHandler(w http.ResponseWriter, r *http.Request, adminManager APIAdminManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	admins, err := adminManager.APIAdmins(r.Context())
+	if err != nil {
+		logger.Error("error in GET /admin/account", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	out := make([]apiAdminHandle, len(admins))
+	for i, admin := range admins {
+		out[i] = newAPIAdminHandle(admin)
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		logger.Error("error in GET /admin/account", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// postAPIAdminHandler handles the POST /admin/account endpoint.
+func postAPIAdminHandler(w http.ResponseWriter, r *http.Request, adminManager APIAdminManager, now func() time.Time, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var input apiAdminCreate
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		errorMsg(w, "malformed input", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case input.Username == "" || len(input.Username) > maxAPIAdminUsernameLen:
+		errorMsg(w, "username must be between 1 and 64 characters", http.StatusBadRequest)
+		return
+	case input.Password == "":
+		errorMsg(w, "password is required", http.StatusBadRequest)
+		return
+	case !state.APIRole(input.Role).Valid():
+		errorMsg(w, "invalid role. valid values: readonly, moderator, admin", http.StatusBadRequest)
+		return
+	}
+
+	err := adminManager.InsertAPIAdmin(r.Context(), input.Username, input.Password, state.APIRole(input.Role), now())
+	switch {
+	case errors.Is(err, state.ErrDupAPIAdmin):
+		errorMsg(w, "account already exists", http.StatusConflict)
+		return
+	case err != nil:
+		logger.Error("error in POST /admin/account", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	auditTarget(r, input.Username)
+	auditChange(r, nil, apiAdminCreate{Username: input.Username, Role: input.Role})
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(messageBody{Message: "Account created successfully."})
+}
+
+// patchAPIAdminHandler handles the PATCH /admin/account/{username} endpoint.
+func patchAPIAdminHandler(w http.ResponseWriter, r *http.Request, adminManager APIAdminManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	username := r.PathValue("username")
+
+	var input apiAdminPatch
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		errorMsg(w, "malformed input", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case input.Role == nil && input.Password == nil:
+		errorMsg(w, "at least one of role or password is required", http.StatusBadRequest)
+		return
+	case input.Role != nil && !state.APIRole(*input.Role).Valid():
+		errorMsg(w, "invalid role. valid values: readonly, moderator, admin", http.StatusBadRequest)
+		return
+	case input.Password != nil && *input.Password == "":
+		errorMsg(w, "password must not be empty", http.StatusBadRequest)
+		return
+	case input.Role != nil && isSelf(r, username):
+		// keep admins from locking themselves out
+		errorMsg(w, "an account can't change its own role", http.StatusConflict)
+		return
+	}
+
+	before, err := findAPIAdmin(r.Context(), adminManager, username)
+	switch {
+	case err != nil:
+		logger.Error("error in PATCH /admin/account/{username}", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	case before == nil:
+		errorMsg(w, "account not found", http.StatusNotFound)
+		return
+	}
+
+	if input.Role != nil {
+		err = adminManager.SetAPIAdminRole(r.Context(), username, state.APIRole(*input.Role))
+	}
+	if err == nil && input.Password != nil {
+		err = adminManager.SetAPIAdminPassword(r.Context(), username, *input.Password)
+	}
+	switch {
+	case errors.Is(err, state.ErrNoAPIAdmin):
+		errorMsg(w, "account not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in PATCH /admin/account/{username}", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// the password is left out of the audit log, only the role is recorded
+	after := newAPIAdminHandle(*before)
+	if input.Role != nil {
+		after.Role = *input.Role
+	}
+	auditChange(r, newAPIAdminHandle(*before), after)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteAPIAdminHandler handles the DELETE /admin/account/{username}
+// endpoint.
+func deleteAPIAdminHandler(w http.ResponseWriter, r *http.Request, adminManager APIAdminManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	username := r.PathValue("username")
+	if isSelf(r, username) {
+		errorMsg(w, "an account can't delete itself", http.StatusConflict)
+		return
+	}
+
+	before, err := findAPIAdmin(r.Context(), adminManager, username)
+	switch {
+	case err != nil:
+		logger.Error("error in DELETE /admin/account/{username}", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	case before == nil:
+		errorMsg(w, "account not found", http.StatusNotFound)
+		return
+	}
+
+	err = adminManager.DeleteAPIAdmin(r.Context(), username)
+	switch {
+	case errors.Is(err, state.ErrNoAPIAdmin):
+		errorMsg(w, "account not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in DELETE /admin/account/{username}", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	auditChange(r, newAPIAdminHandle(*before), nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// postAPIAdminTokenHandler handles the POST /admin/account/{username}/token
+// endpoint.
+func postAPIAdminTokenHandler(w http.ResponseWriter, r *http.Request, adminManager APIAdminManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	before, err := findAPIAdmin(r.Context(), adminManager, r.PathValue("username"))
+	switch {
+	case err != nil:
+		logger.Error("error in POST /admin/account/{username}/token", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	case before == nil:
+		errorMsg(w, "account not found", http.StatusNotFound)
+		return
+	}
+
+	token, err := adminManager.IssueAPIAdminToken(r.Context(), r.PathValue("username"))
+	switch {
+	case errors.Is(err, state.ErrNoAPIAdmin):
+		errorMsg(w, "account not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in POST /admin/account/{username}/token", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// record whether the account has a token, never the token itself
+	after := newAPIAdminHandle(*before)
+	after.HasToken = true
+	auditChange(r, newAPIAdminHandle(*before), after)
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(apiAdminToken{Token: token})
+}
+
+// deleteAPIAdminTokenHandler handles the DELETE
+// /admin/account/{username}/token endpoint.
+func deleteAPIAdminTokenHandler(w http.ResponseWriter, r *http.Request, adminManager APIAdminManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	before, err := findAPIAdmin(r.Context(), adminManager, r.PathValue("username"))
+	switch {
+	case err != nil:
+		logger.Error("error in DELETE /admin/account/{username}/token", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	case before == nil:
+		errorMsg(w, "account not found", http.StatusNotFound)
+		return
+	}
+
+	err = adminManager.RevokeAPIAdminToken(r.Context(), r.PathValue("username"))
+	switch {
+	case errors.Is(err, state.ErrNoAPIAdmin):
+		errorMsg(w, "account not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in DELETE /admin/account/{username}/token", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	after := newAPIAdminHandle(*before)
+	after.HasToken = false
+	auditChange(r, newAPIAdminHandle(*before), after)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findAPIAdmin returns the account identified by username, or nil if it
+// doesn't exist. The audit log records it as the state before a change.
+func findAPIAdmin(ctx context.Context, adminManager APIAdminManager, username string) (*state.APIAdmin, error) {
+	admins, err := adminManager.APIAdmins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, admin := range admins {
+		if strings.EqualFold(admin.Username, username) {
+			return &admin, nil
+		}
+	}
+	return nil, nil
+}
+
+// newAPIAdminHandle converts an account to its API representation.
+func newAPIAdminHandle(admin state.APIAdmin) apiAdminHandle {
+	return apiAdminHandle{
+		Username:  admin.Username,
+		Role:      string(admin.Role),
+		HasToken:  admin.HasToken,
+		CreatedAt: admin.CreatedAt.UTC(),
+	}
+}
+
+// isSelf indicates whether username is the account that made the request.
+func isSelf(r *http.Request, username string) bool {
+	admin := apiAdminFromContext(r.Context())
+	return admin != nil && strings.EqualFold(admin.Username, username)
+}
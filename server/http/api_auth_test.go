@@ -0,0 +1,653 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mk6i/retro-aim-server/state"
+)
+
+func TestRequireRole(t *testing.T) {
+	moderator := &state.APIAdmin{Username: "mod", Role: state.APIRoleModerator}
+
+	tt := []struct {
+		name          string
+		method        string
+		path          string
+		basicAuth     []string
+		bearerToken   string
+		authResult    *state.APIAdmin
+		authErr       error
+		statusCode    int
+		wantAdmin     *state.APIAdmin
+		wantChallenge bool
+		// lockedAccount and lockedClient lock out the account and the
+		// client before the request is sent
+		lockedAccount bool
+		lockedClient  bool
+		// wantAccountLocked and wantClientLocked indicate whether the
+		// account and the client are locked out after the request
+		wantAccountLocked bool
+		wantClientLocked  bool
+	}{
+		{
+			name:          "missing credentials",
+			method:        http.MethodGet,
+			path:          "/session",
+			statusCode:    http.StatusUnauthorized,
+			wantChallenge: true,
+		},
+		{
+			name:              "invalid password",
+			method:            http.MethodGet,
+			path:              "/session",
+			basicAuth:         []string{"mod", "wrongpass"},
+			statusCode:        http.StatusUnauthorized,
+			wantChallenge:     true,
+			wantAccountLocked: true,
+			wantClientLocked:  true,
+		},
+		{
+			name:              "locked out account",
+			method:            http.MethodGet,
+			path:              "/session",
+			basicAuth:         []string{"mod", "modpass"},
+			lockedAccount:     true,
+			statusCode:        http.StatusUnauthorized,
+			wantChallenge:     true,
+			wantAccountLocked: true,
+		},
+		{
+			name:             "locked out client",
+			method:           http.MethodGet,
+			path:             "/session",
+			basicAuth:        []string{"mod", "modpass"},
+			lockedClient:     true,
+			statusCode:       http.StatusUnauthorized,
+			wantChallenge:    true,
+			wantClientLocked: true,
+		},
+		{
+			name:             "locked out client with bearer token",
+			method:           http.MethodGet,
+			path:             "/session",
+			bearerToken:      "the-token",
+			lockedClient:     true,
+			statusCode:       http.StatusUnauthorized,
+			wantClientLocked: true,
+		},
+		{
+			name:       "basic auth with sufficient role",
+			method:     http.MethodDelete,
+			path:       "/session/someuser",
+			basicAuth:  []string{"mod", "modpass"},
+			authResult: moderator,
+			statusCode: http.StatusNoContent,
+			wantAdmin:  moderator,
+		},
+		{
+			name:        "bearer token with sufficient role",
+			method:      http.MethodGet,
+			path:        "/session",
+			bearerToken: "the-token",
+			authResult:  moderator,
+			statusCode:  http.StatusNoContent,
+			wantAdmin:   moderator,
+		},
+		{
+			name:             "invalid bearer token",
+			method:           http.MethodGet,
+			path:             "/session",
+			bearerToken:      "the-token",
+			statusCode:       http.StatusUnauthorized,
+			wantClientLocked: true,
+		},
+		{
+			name:        "insufficient role",
+			method:      http.MethodPost,
+			path:        "/config/reload",
+			bearerToken: "the-token",
+			authResult:  moderator,
+			statusCode:  http.StatusForbidden,
+		},
+		{
+			name:        "unlisted route requires admin",
+			method:      http.MethodGet,
+			path:        "/unlisted",
+			bearerToken: "the-token",
+			authResult:  moderator,
+			statusCode:  http.StatusForbidden,
+		},
+		{
+			name:        "unknown route",
+			method:      http.MethodGet,
+			path:        "/nonexistent",
+			bearerToken: "the-token",
+			authResult:  moderator,
+			statusCode:  http.StatusNotFound,
+		},
+		{
+			name:       "public route",
+			method:     http.MethodGet,
+			path:       "/account/confirm",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "authentication failure",
+			method:     http.MethodGet,
+			path:       "/session",
+			basicAuth:  []string{"mod", "modpass"},
+			authErr:    errors.New("database is locked"),
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotAdmin *state.APIAdmin
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				gotAdmin = apiAdminFromContext(r.Context())
+				w.WriteHeader(http.StatusNoContent)
+			}
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /session", handler)
+			mux.HandleFunc("DELETE /session/{screenname}", handler)
+			mux.HandleFunc("POST /config/reload", handler)
+			mux.HandleFunc("GET /unlisted", handler)
+			mux.HandleFunc("GET /account/confirm", handler)
+
+			// lock out on the first failure
+			adminLockout := state.NewLoginLockoutTracker(1, time.Minute, time.Minute)
+			clientLockout := state.NewLoginLockoutTracker(1, time.Minute, time.Minute)
+			account := state.NewIdentScreenName("mod")
+			client := state.NewIdentScreenName("192.0.2.1")
+			if tc.lockedAccount {
+				adminLockout.RecordFailure(account)
+			}
+			if tc.lockedClient {
+				clientLockout.RecordFailure(client)
+			}
+			locked := tc.lockedAccount || tc.lockedClient
+
+			adminManager := newMockAPIAdminManager(t)
+			request := httptest.NewRequest(tc.method, tc.path, nil)
+			switch {
+			case tc.basicAuth != nil:
+				request.SetBasicAuth(tc.basicAuth[0], tc.basicAuth[1])
+				if !locked {
+					adminManager.EXPECT().
+						AuthenticateAPIAdmin(matchContext(), tc.basicAuth[0], tc.basicAuth[1]).
+						Return(tc.authResult, tc.authErr)
+				}
+			case tc.bearerToken != "":
+				request.Header.Set("Authorization", "Bearer "+tc.bearerToken)
+				if !locked {
+					adminManager.EXPECT().
+						AuthenticateAPIAdminToken(matchContext(), tc.bearerToken).
+						Return(tc.authResult, tc.authErr)
+				}
+			}
+			responseRecorder := httptest.NewRecorder()
+
+			requireRole(mux, mux, adminManager, adminLockout, clientLockout, slog.Default()).ServeHTTP(responseRecorder, request)
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.wantAdmin, gotAdmin)
+			if tc.wantChallenge {
+				assert.Contains(t, responseRecorder.Header().Get("WWW-Authenticate"), "Basic")
+			}
+			assert.Equal(t, tc.wantAccountLocked, adminLockout.IsLocked(account))
+			assert.Equal(t, tc.wantClientLocked, clientLockout.IsLocked(client))
+		})
+	}
+}
+
+func TestAPIAdminHandler_GET(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name       string
+		admins     []state.APIAdmin
+		err        error
+		want       string
+		statusCode int
+	}{
+		{
+			name: "list accounts",
+			admins: []state.APIAdmin{
+				{Username: "alice", Role: state.APIRoleAdmin, HasToken: true, CreatedAt: createdAt},
+				{Username: "bob", Role: state.APIRoleReadOnly, CreatedAt: createdAt},
+			},
+			want:       `[{"username":"alice","role":"admin","has_token":true,"created_at":"2025-01-01T12:00:00Z"},{"username":"bob","role":"readonly","has_token":false,"created_at":"2025-01-01T12:00:00Z"}]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "no accounts",
+			want:       `[]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "store failure",
+			err:        errors.New("database is locked"),
+			want:       `{"message":"internal server error"}`,
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/admin/account", nil)
+			responseRecorder := httptest.NewRecorder()
+
+			adminManager := newMockAPIAdminManager(t)
+			adminManager.EXPECT().
+				APIAdmins(matchContext()).
+				Return(tc.admins, tc.err)
+
+			getAPIAdminHandler(responseRecorder, request, adminManager, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestAPIAdminHandler_POST(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name         string
+		body         string
+		expectInsert bool
+		err          error
+		want         string
+		statusCode   int
+	}{
+		{
+			name:         "create account",
+			body:         `{"username":"alice","password":"thepass","role":"moderator"}`,
+			expectInsert: true,
+			want:         `{"message":"Account created successfully."}`,
+			statusCode:   http.StatusCreated,
+		},
+		{
+			name:         "duplicate account",
+			body:         `{"username":"alice","password":"thepass","role":"moderator"}`,
+			expectInsert: true,
+			err:          state.ErrDupAPIAdmin,
+			want:         `{"message":"account already exists"}`,
+			statusCode:   http.StatusConflict,
+		},
+		{
+			name:       "invalid role",
+			body:       `{"username":"alice","password":"thepass","role":"root"}`,
+			want:       `{"message":"invalid role. valid values: readonly, moderator, admin"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "missing password",
+			body:       `{"username":"alice","role":"admin"}`,
+			want:       `{"message":"password is required"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "missing username",
+			body:       `{"password":"thepass","role":"admin"}`,
+			want:       `{"message":"username must be between 1 and 64 characters"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed body",
+			body:       `{`,
+			want:       `{"message":"malformed input"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/admin/account", bytes.NewBufferString(tc.body))
+			responseRecorder := httptest.NewRecorder()
+
+			adminManager := newMockAPIAdminManager(t)
+			if tc.expectInsert {
+				adminManager.EXPECT().
+					InsertAPIAdmin(matchContext(), "alice", "thepass", state.APIRoleModerator, now).
+					Return(tc.err)
+			}
+
+			postAPIAdminHandler(responseRecorder, request, adminManager, func() time.Time { return now }, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestAPIAdminHandler_PATCH(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	admins := []state.APIAdmin{
+		{Username: "alice", Role: state.APIRoleAdmin, CreatedAt: createdAt},
+		{Username: "bob", Role: state.APIRoleReadOnly, HasToken: true, CreatedAt: createdAt},
+	}
+
+	tt := []struct {
+		name           string
+		username       string
+		caller         string
+		body           string
+		expectLookup   bool
+		expectRole     bool
+		expectPassword bool
+		err            error
+		statusCode     int
+		wantBefore     any
+		wantAfter      any
+	}{
+		{
+			name:           "change role and password",
+			username:       "bob",
+			caller:         "alice",
+			body:           `{"role":"moderator","password":"newpass"}`,
+			expectLookup:   true,
+			expectRole:     true,
+			expectPassword: true,
+			statusCode:     http.StatusNoContent,
+			wantBefore:     apiAdminHandle{Username: "bob", Role: "readonly", HasToken: true, CreatedAt: createdAt},
+			wantAfter:      apiAdminHandle{Username: "bob", Role: "moderator", HasToken: true, CreatedAt: createdAt},
+		},
+		{
+			name:           "change own password",
+			username:       "alice",
+			caller:         "alice",
+			body:           `{"password":"newpass"}`,
+			expectLookup:   true,
+			expectPassword: true,
+			statusCode:     http.StatusNoContent,
+			wantBefore:     apiAdminHandle{Username: "alice", Role: "admin", CreatedAt: createdAt},
+			wantAfter:      apiAdminHandle{Username: "alice", Role: "admin", CreatedAt: createdAt},
+		},
+		{
+			name:       "change own role",
+			username:   "Alice",
+			caller:     "alice",
+			body:       `{"role":"readonly"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:         "account not found",
+			username:     "carol",
+			caller:       "alice",
+			body:         `{"role":"moderator"}`,
+			expectLookup: true,
+			statusCode:   http.StatusNotFound,
+		},
+		{
+			name:         "account deleted during update",
+			username:     "bob",
+			caller:       "alice",
+			body:         `{"role":"moderator"}`,
+			expectLookup: true,
+			expectRole:   true,
+			err:          state.ErrNoAPIAdmin,
+			statusCode:   http.StatusNotFound,
+		},
+		{
+			name:       "no changes",
+			username:   "bob",
+			caller:     "alice",
+			body:       `{}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid role",
+			username:   "bob",
+			caller:     "alice",
+			body:       `{"role":"root"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPatch, "/admin/account/"+tc.username, bytes.NewBufferString(tc.body))
+			request.SetPathValue("username", tc.username)
+			caller := &state.APIAdmin{Username: tc.caller, Role: state.APIRoleAdmin}
+			request = request.WithContext(context.WithValue(request.Context(), apiAdminCtxKey{}, caller))
+			request, rec := withAuditRecord(request)
+			responseRecorder := httptest.NewRecorder()
+
+			adminManager := newMockAPIAdminManager(t)
+			if tc.expectLookup {
+				adminManager.EXPECT().
+					APIAdmins(matchContext()).
+					Return(admins, nil)
+			}
+			if tc.expectRole {
+				adminManager.EXPECT().
+					SetAPIAdminRole(matchContext(), tc.username, state.APIRoleModerator).
+					Return(tc.err)
+			}
+			if tc.expectPassword {
+				adminManager.EXPECT().
+					SetAPIAdminPassword(matchContext(), tc.username, "newpass").
+					Return(tc.err)
+			}
+
+			patchAPIAdminHandler(responseRecorder, request, adminManager, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.wantBefore, rec.before)
+			assert.Equal(t, tc.wantAfter, rec.after)
+		})
+	}
+}
+
+func TestAPIAdminHandler_DELETE(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	admins := []state.APIAdmin{
+		{Username: "alice", Role: state.APIRoleAdmin, CreatedAt: createdAt},
+		{Username: "bob", Role: state.APIRoleModerator, CreatedAt: createdAt},
+	}
+
+	tt := []struct {
+		name         string
+		username     string
+		expectLookup bool
+		expectDelete bool
+		err          error
+		statusCode   int
+		wantBefore   any
+	}{
+		{
+			name:         "delete account",
+			username:     "bob",
+			expectLookup: true,
+			expectDelete: true,
+			statusCode:   http.StatusNoContent,
+			wantBefore:   apiAdminHandle{Username: "bob", Role: "moderator", CreatedAt: createdAt},
+		},
+		{
+			name:         "account not found",
+			username:     "carol",
+			expectLookup: true,
+			statusCode:   http.StatusNotFound,
+		},
+		{
+			name:         "account deleted concurrently",
+			username:     "bob",
+			expectLookup: true,
+			expectDelete: true,
+			err:          state.ErrNoAPIAdmin,
+			statusCode:   http.StatusNotFound,
+		},
+		{
+			name:       "delete own account",
+			username:   "alice",
+			statusCode: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodDelete, "/admin/account/"+tc.username, nil)
+			request.SetPathValue("username", tc.username)
+			caller := &state.APIAdmin{Username: "alice", Role: state.APIRoleAdmin}
+			request = request.WithContext(context.WithValue(request.Context(), apiAdminCtxKey{}, caller))
+			request, rec := withAuditRecord(request)
+			responseRecorder := httptest.NewRecorder()
+
+			adminManager := newMockAPIAdminManager(t)
+			if tc.expectLookup {
+				adminManager.EXPECT().
+					APIAdmins(matchContext()).
+					Return(admins, nil)
+			}
+			if tc.expectDelete {
+				adminManager.EXPECT().
+					DeleteAPIAdmin(matchContext(), tc.username).
+					Return(tc.err)
+			}
+
+			deleteAPIAdminHandler(responseRecorder, request, adminManager, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.wantBefore, rec.before)
+			assert.Nil(t, rec.after)
+		})
+	}
+}
+
+func TestAPIAdminTokenHandler_POST(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name        string
+		admins      []state.APIAdmin
+		expectIssue bool
+		token       string
+		err         error
+		want        string
+		statusCode  int
+		wantBefore  any
+		wantAfter   any
+	}{
+		{
+			name:        "issue token",
+			admins:      []state.APIAdmin{{Username: "bob", Role: state.APIRoleReadOnly, CreatedAt: createdAt}},
+			expectIssue: true,
+			token:       "the-token",
+			want:        `{"token":"the-token"}`,
+			statusCode:  http.StatusCreated,
+			wantBefore:  apiAdminHandle{Username: "bob", Role: "readonly", CreatedAt: createdAt},
+			wantAfter:   apiAdminHandle{Username: "bob", Role: "readonly", HasToken: true, CreatedAt: createdAt},
+		},
+		{
+			name:       "account not found",
+			want:       `{"message":"account not found"}`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:        "account deleted concurrently",
+			admins:      []state.APIAdmin{{Username: "bob", Role: state.APIRoleReadOnly, CreatedAt: createdAt}},
+			expectIssue: true,
+			err:         state.ErrNoAPIAdmin,
+			want:        `{"message":"account not found"}`,
+			statusCode:  http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/admin/account/bob/token", nil)
+			request.SetPathValue("username", "bob")
+			request, rec := withAuditRecord(request)
+			responseRecorder := httptest.NewRecorder()
+
+			adminManager := newMockAPIAdminManager(t)
+			adminManager.EXPECT().
+				APIAdmins(matchContext()).
+				Return(tc.admins, nil)
+			if tc.expectIssue {
+				adminManager.EXPECT().
+					IssueAPIAdminToken(matchContext(), "bob").
+					Return(tc.token, tc.err)
+			}
+
+			postAPIAdminTokenHandler(responseRecorder, request, adminManager, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+			assert.Equal(t, tc.wantBefore, rec.before)
+			assert.Equal(t, tc.wantAfter, rec.after)
+		})
+	}
+}
+
+func TestAPIAdminTokenHandler_DELETE(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name         string
+		admins       []state.APIAdmin
+		expectRevoke bool
+		err          error
+		statusCode   int
+		wantBefore   any
+		wantAfter    any
+	}{
+		{
+			name:         "revoke token",
+			admins:       []state.APIAdmin{{Username: "bob", Role: state.APIRoleReadOnly, HasToken: true, CreatedAt: createdAt}},
+			expectRevoke: true,
+			statusCode:   http.StatusNoContent,
+			wantBefore:   apiAdminHandle{Username: "bob", Role: "readonly", HasToken: true, CreatedAt: createdAt},
+			wantAfter:    apiAdminHandle{Username: "bob", Role: "readonly", CreatedAt: createdAt},
+		},
+		{
+			name:       "account not found",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:         "account deleted concurrently",
+			admins:       []state.APIAdmin{{Username: "bob", Role: state.APIRoleReadOnly, HasToken: true, CreatedAt: createdAt}},
+			expectRevoke: true,
+			err:          state.ErrNoAPIAdmin,
+			statusCode:   http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodDelete, "/admin/account/bob/token", nil)
+			request.SetPathValue("username", "bob")
+			request, rec := withAuditRecord(request)
+			responseRecorder := httptest.NewRecorder()
+
+			adminManager := newMockAPIAdminManager(t)
+			adminManager.EXPECT().
+				APIAdmins(matchContext()).
+				Return(tc.admins, nil)
+			if tc.expectRevoke {
+				adminManager.EXPECT().
+					RevokeAPIAdminToken(matchContext(), "bob").
+					Return(tc.err)
+			}
+
+			deleteAPIAdminTokenHandler(responseRecorder, request, adminManager, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.wantBefore, rec.before)
+			assert.Equal(t, tc.wantAfter, rec.after)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mk6i/retro-aim-server/state"
+)
+
+const (
+	// defaultAuditLimit is the number of entries GET /audit returns when
+	// the limit query parameter is omitted.
+	defaultAuditLimit = 100
+	// maxAuditLimit is the most entries GET /audit returns.
+	maxAuditLimit = 1000
+)
+
+// AuditLogManager defines methods for recording and retrieving the
+// administrative actions performed through the management API.
+type AuditLogManager interface {
+	// InsertAuditEntry appends an entry to the audit log.
+	InsertAuditEntry(ctx context.Context, entry state.AuditEntry) error
+
+	// AuditEntries returns the entries that match filter, newest first.
+	AuditEntries(ctx context.Context, filter state.AuditFilter) ([]state.AuditEntry, error)
+}
+
+// auditRecord collects the details of an administrative action while its
+// handler runs.
+type auditRecord struct {
+	target    string
+	hasTarget bool
+	before    any
+	after     any
+}
+
+// auditRecordCtxKey is the request context key of the request's
+// auditRecord.
+type auditRecordCtxKey struct{}
+
+// auditTarget sets what the request's action was performed on. By default,
+// the target is the route's path values, e.g. the screen name in
+// DELETE /session/{screenname}.
+func auditTarget(r *http.Request, target string) {
+	if rec, ok := r.Context().Value(auditRecordCtxKey{}).(*auditRecord); ok {
+		rec.target = target
+		rec.hasTarget = true
+	}
+}
+
+// auditChange sets the state of the request's target before and after the
+// action. Either may be nil if not applicable. Don't pass secrets such as
+// passwords.
+func auditChange(r *http.Request, before, after any) {
+	if rec, ok := r.Context().Value(auditRecordCtxKey{}).(*auditRecord); ok {
+		rec.before = before
+		rec.after = after
+	}
+}
+
+// statusRecorder is a http.ResponseWriter that remembers the response
+// status code.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// recordAudit writes an audit log entry for each mutating request to next
+// that an authenticated account makes and that succeeds. Handlers describe
+// the action with auditTarget and auditChange.
+func recordAudit(next http.Handler, auditLog AuditLogManager, timeNow func() time.Time, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin := apiAdminFromContext(r.Context())
+		if admin == nil || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &auditRecord{}
+		sw := &statusRecorder{ResponseWriter: w}
+		// the mux sets the matched pattern on this request
+		r = r.WithContext(context.WithValue(r.Context(), auditRecordCtxKey{}, rec))
+		next.ServeHTTP(sw, r)
+
+		// only record actions that succeeded and changed something
+		if r.Pattern == "" || sw.status >= http.StatusMultipleChoices {
+			return
+		}
+
+		entry := state.AuditEntry{
+			CreatedAt: timeNow(),
+			Actor:     admin.Username,
+			Action:    r.Pattern,
+			Target:    rec.target,
+			Before:    auditJSON(rec.before, logger),
+			After:     auditJSON(rec.after, logger),
+			SourceIP:  clientIP(r),
+		}
+		if !rec.hasTarget {
+			entry.Target = patternTarget(r)
+		}
+
+		// the request already succeeded, so a failure can only be logged
+		if err := auditLog.InsertAuditEntry(context.WithoutCancel(r.Context()), entry); err != nil {
+			logger.Error("error recording audit log entry", "action", entry.Action, "actor", entry.Actor,
+				"target", entry.Target, "err", err.Error())
+		}
+	})
+}
+
+// patternTarget returns the values of the path wildcards in the request's
+// matched pattern, separated by slashes.
+func patternTarget(r *http.Request) string {
+	var values []string
+	for _, seg := range strings.Split(r.Pattern, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			name := strings.TrimSuffix(strings.Trim(seg, "{}"), "...")
+			values = append(values, r.PathValue(name))
+		}
+	}
+	return strings.Join(values, "/")
+}
+
+// auditJSON encodes v for the audit log, or returns an empty string if v is
+// nil.
+func auditJSON(v any, logger *slog.Logger) string {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		logger.Error("error encoding audit log value", "err", err.Error())
+		return ""
+	}
+	return string(b)
+}
+
+// getAuditHandler handles the GET /audit endpoint. The optional actor,
+// target, since, until and limit query parameters filter the entries.
+func getAuditHandler(w http.ResponseWriter, r *http.Request, auditLog AuditLogManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	filter := state.AuditFilter{
+		Actor:  query.Get("actor"),
+		Target: query.Get("target"),
+		Limit:  defaultAuditLimit,
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{name: "since", dst: &filter.Since},
+		{name: "until", dst: &filter.Until},
+	} {
+		if v := query.Get(param.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				errorMsg(w, param.name+" must be an RFC 3339 timestamp", http.StatusBadRequest)
+				return
+			}
+			*param.dst = t
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			errorMsg(w, "limit must be between 1 and "+strconv.Itoa(maxAuditLimit), http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := auditLog.AuditEntries(r.Context(), filter)
+	if err != nil {
+		logger.Error("error in GET /audit", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	out := make([]auditEntryHandle, len(entries))
+	for i, entry := range entries {
+		out[i] = auditEntryHandle{
+			ID:        entry.ID,
+			CreatedAt: entry.CreatedAt.UTC(),
+			Actor:     entry.Actor,
+			Action:    entry.Action,
+			Target:    entry.Target,
+			SourceIP:  entry.SourceIP,
+		}
+		if entry.Before != "" {
+			out[i].Before = json.RawMessage(entry.Before)
+		}
+		if entry.After != "" {
+			out[i].After = json.RawMessage(entry.After)
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		logger.Error("error in GET /audit", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,225 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mk6i/retro-aim-server/state"
+)
+
+func TestRecordAudit(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	admin := &state.APIAdmin{Username: "alice", Role: state.APIRoleAdmin}
+
+	tt := []struct {
+		name       string
+		method     string
+		path       string
+		admin      *state.APIAdmin
+		entry      *state.AuditEntry
+		insertErr  error
+		statusCode int
+	}{
+		{
+			name:   "target from path",
+			method: http.MethodDelete,
+			path:   "/session/userA",
+			admin:  admin,
+			entry: &state.AuditEntry{
+				CreatedAt: now,
+				Actor:     "alice",
+				Action:    "DELETE /session/{screenname}",
+				Target:    "userA",
+				SourceIP:  "10.0.0.1",
+			},
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:   "target and change set by handler",
+			method: http.MethodPut,
+			path:   "/motd",
+			admin:  admin,
+			entry: &state.AuditEntry{
+				CreatedAt: now,
+				Actor:     "alice",
+				Action:    "PUT /motd",
+				Target:    "motd",
+				Before:    `{"message":"old"}`,
+				After:     `{"message":"new"}`,
+				SourceIP:  "10.0.0.1",
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:   "failure to record doesn't affect response",
+			method: http.MethodDelete,
+			path:   "/session/userA",
+			admin:  admin,
+			entry: &state.AuditEntry{
+				CreatedAt: now,
+				Actor:     "alice",
+				Action:    "DELETE /session/{screenname}",
+				Target:    "userA",
+				SourceIP:  "10.0.0.1",
+			},
+			insertErr:  errors.New("database is locked"),
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "failed request isn't recorded",
+			method:     http.MethodDelete,
+			path:       "/bart/abcd",
+			admin:      admin,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "read request isn't recorded",
+			method:     http.MethodGet,
+			path:       "/motd",
+			admin:      admin,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "unauthenticated request isn't recorded",
+			method:     http.MethodPut,
+			path:       "/motd",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "unknown route isn't recorded",
+			method:     http.MethodPost,
+			path:       "/nonexistent",
+			admin:      admin,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("DELETE /session/{screenname}", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})
+			mux.HandleFunc("PUT /motd", func(w http.ResponseWriter, r *http.Request) {
+				auditTarget(r, "motd")
+				auditChange(r, motd{Message: "old"}, motd{Message: "new"})
+				_, _ = w.Write([]byte("ok"))
+			})
+			mux.HandleFunc("GET /motd", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			})
+			mux.HandleFunc("DELETE /bart/{hash}", func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "not found", http.StatusNotFound)
+			})
+
+			auditLog := newMockAuditLogManager(t)
+			if tc.entry != nil {
+				auditLog.EXPECT().
+					InsertAuditEntry(matchContext(), *tc.entry).
+					Return(tc.insertErr)
+			}
+
+			request := httptest.NewRequest(tc.method, tc.path, nil)
+			request.RemoteAddr = "10.0.0.1:5190"
+			if tc.admin != nil {
+				request = request.WithContext(context.WithValue(request.Context(), apiAdminCtxKey{}, tc.admin))
+			}
+			responseRecorder := httptest.NewRecorder()
+
+			handler := recordAudit(mux, auditLog, func() time.Time { return now }, slog.Default())
+			handler.ServeHTTP(responseRecorder, request)
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+		})
+	}
+}
+
+func TestAuditHandler_GET(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name         string
+		query        string
+		expectFilter *state.AuditFilter
+		entries      []state.AuditEntry
+		err          error
+		want         string
+		statusCode   int
+	}{
+		{
+			name:         "all entries",
+			expectFilter: &state.AuditFilter{Limit: defaultAuditLimit},
+			entries: []state.AuditEntry{
+				{ID: 2, CreatedAt: createdAt, Actor: "alice", Action: "PUT /motd", Before: `{"message":"old"}`, After: `{"message":"new"}`, SourceIP: "10.0.0.1"},
+				{ID: 1, CreatedAt: createdAt, Actor: "alice", Action: "DELETE /user", Target: "userA", SourceIP: "10.0.0.1"},
+			},
+			want:       `[{"id":2,"created_at":"2025-01-01T12:00:00Z","actor":"alice","action":"PUT /motd","target":"","before":{"message":"old"},"after":{"message":"new"},"source_ip":"10.0.0.1"},{"id":1,"created_at":"2025-01-01T12:00:00Z","actor":"alice","action":"DELETE /user","target":"userA","source_ip":"10.0.0.1"}]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:  "filtered entries",
+			query: "?actor=alice&target=userA&since=2025-01-01T00:00:00Z&until=2025-01-02T00:00:00-05:00&limit=10",
+			expectFilter: &state.AuditFilter{
+				Actor:  "alice",
+				Target: "userA",
+				Since:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				Until:  time.Date(2025, 1, 2, 0, 0, 0, 0, time.FixedZone("", -5*60*60)),
+				Limit:  10,
+			},
+			want:       `[]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "invalid since",
+			query:      "?since=yesterday",
+			want:       `{"message":"since must be an RFC 3339 timestamp"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid until",
+			query:      "?until=2025-01-01",
+			want:       `{"message":"until must be an RFC 3339 timestamp"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "limit out of range",
+			query:      "?limit=1001",
+			want:       `{"message":"limit must be between 1 and 1000"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:         "store failure",
+			expectFilter: &state.AuditFilter{Limit: defaultAuditLimit},
+			err:          errors.New("database is locked"),
+			want:         `{"message":"internal server error"}`,
+			statusCode:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/audit"+tc.query, nil)
+			responseRecorder := httptest.NewRecorder()
+
+			auditLog := newMockAuditLogManager(t)
+			if tc.expectFilter != nil {
+				auditLog.EXPECT().
+					AuditEntries(matchContext(), *tc.expectFilter).
+					Return(tc.entries, tc.err)
+			}
+
+			getAuditHandler(responseRecorder, request, auditLog, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"net/http"
 	"net/mail"
 
 	"github.com/stretchr/testify/mock"
@@ -18,6 +19,7 @@ type mockParams struct {
 	chatSessionRetrieverParams
 	directoryManagerParams
 	feedBagRetrieverParams
+	loginLockoutManagerParams
 	profileRetrieverParams
 	sessionRetrieverParams
 	userManagerParams
@@ -161,6 +163,7 @@ type directoryManagerParams struct {
 	createKeywordParams
 	deleteCategoryParams
 	deleteKeywordParams
+	keywordByIDParams
 	keywordsByCategoryParams
 }
 
@@ -202,6 +205,14 @@ type deleteKeywordParams []struct {
 	err error
 }
 
+// keywordByIDParams is the list of parameters passed at the mock
+// DirectoryManager.KeywordByID call site
+type keywordByIDParams []struct {
+	id     uint8
+	result state.Keyword
+	err    error
+}
+
 // keywordsByCategoryParams is the list of parameters passed at the mock
 // DirectoryManager.KeywordsByCategory call site
 type keywordsByCategoryParams []struct {
@@ -238,6 +249,27 @@ type retrieveProfileParams []struct {
 	err        error
 }
 
+// loginLockoutManagerParams is a helper struct that contains mock parameters
+// for LoginLockoutManager methods
+type loginLockoutManagerParams struct {
+	lockoutsParams
+	clearLockoutParams
+}
+
+// lockoutsParams is the list of parameters passed at the mock
+// LoginLockoutManager.Lockouts call site
+type lockoutsParams []struct {
+	result []state.LoginLockout
+}
+
+// clearLockoutParams is the list of parameters passed at the mock
+// LoginLockoutManager.ClearLockout call site
+type clearLockoutParams []struct {
+	screenName state.IdentScreenName
+	lockout    state.LoginLockout
+	result     bool
+}
+
 // sessionRetrieverParams is a helper struct that contains mock parameters for
 // SessionRetriever methods
 type sessionRetrieverParams struct {
@@ -266,6 +298,8 @@ type userManagerParams struct {
 	getUserParams
 	insertUserParams
 	setUserPasswordParams
+	setTOTPParams
+	clearTOTPParams
 }
 
 // allUsersParams is the list of parameters passed at the mock
@@ -305,6 +339,22 @@ type setUserPasswordParams []struct {
 	err         error
 }
 
+// setTOTPParams is the list of parameters passed at the mock
+// UserManager.SetTOTP call site
+type setTOTPParams []struct {
+	screenName    state.IdentScreenName
+	secret        string
+	recoveryCodes []string
+	err           error
+}
+
+// clearTOTPParams is the list of parameters passed at the mock
+// UserManager.ClearTOTP call site
+type clearTOTPParams []struct {
+	screenName state.IdentScreenName
+	err        error
+}
+
 // matchContext matches any instance of Context interface.
 func matchContext() interface{} {
 	return mock.MatchedBy(func(ctx any) bool {
@@ -312,3 +362,10 @@ func matchContext() interface{} {
 		return ok
 	})
 }
+
+// withAuditRecord returns a copy of r that collects the values its handler
+// passes to auditTarget and auditChange.
+func withAuditRecord(r *http.Request) (*http.Request, *auditRecord) {
+	rec := &auditRecord{}
+	return r.WithContext(context.WithValue(r.Context(), auditRecordCtxKey{}, rec)), rec
+}
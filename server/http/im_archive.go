@@ -0,0 +1,268 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mk6i/retro-aim-server/state"
+)
+
+const (
+	// defaultIMHistoryLimit is the number of messages GET /im/history
+	// returns when the limit query parameter is omitted.
+	defaultIMHistoryLimit = 100
+	// maxIMHistoryLimit is the most messages GET /im/history returns.
+	maxIMHistoryLimit = 1000
+)
+
+// IMArchiveManager defines methods for searching the server-side IM archive
+// and managing users' archive settings.
+type IMArchiveManager interface {
+	// IMArchive returns the archived messages that match filter, newest
+	// first.
+	IMArchive(ctx context.Context, filter state.IMArchiveFilter) ([]state.ArchivedIM, error)
+
+	// IMArchiveSettings returns a user's IM archive settings.
+	IMArchiveSettings(ctx context.Context, screenName state.IdentScreenName) (state.IMArchiveSettings, error)
+
+	// SetIMArchiveSettings replaces a user's IM archive settings.
+	SetIMArchiveSettings(ctx context.Context, screenName state.IdentScreenName, settings state.IMArchiveSettings) error
+}
+
+// getIMHistoryHandler handles the GET /im/history endpoint. The optional q
+// query parameter searches the message text, and the sender, recipient,
+// since, until and limit query parameters filter the messages.
+func getIMHistoryHandler(w http.ResponseWriter, r *http.Request, imArchive IMArchiveManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	filter := state.IMArchiveFilter{
+		Query:     query.Get("q"),
+		Sender:    state.NewIdentScreenName(query.Get("sender")),
+		Recipient: state.NewIdentScreenName(query.Get("recipient")),
+		Limit:     defaultIMHistoryLimit,
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{name: "since", dst: &filter.Since},
+		{name: "until", dst: &filter.Until},
+	} {
+		if v := query.Get(param.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				errorMsg(w, param.name+" must be an RFC 3339 timestamp", http.StatusBadRequest)
+				return
+			}
+			*param.dst = t
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxIMHistoryLimit {
+			errorMsg(w, "limit must be between 1 and "+strconv.Itoa(maxIMHistoryLimit), http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	ims, err := imArchive.IMArchive(r.Context(), filter)
+	if err != nil {
+		logger.Error("error in GET /im/history", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	out := make([]archivedIMHandle, len(ims))
+	for i, im := range ims {
+		out[i] = archivedIMHandle{
+			ID:        im.ID,
+			SentAt:    im.SentAt.UTC(),
+			Sender:    im.Sender.String(),
+			Recipient: im.Recipient.String(),
+			Text:      im.Text,
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		logger.Error("error in GET /im/history", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// getUserIMArchiveHandler handles the GET /user/{screenname}/im-archive
+// endpoint.
+func getUserIMArchiveHandler(w http.ResponseWriter, r *http.Request, imArchive IMArchiveManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	settings, err := imArchive.IMArchiveSettings(r.Context(), state.NewIdentScreenName(r.PathValue("screenname")))
+	switch {
+	case errors.Is(err, state.ErrNoUser):
+		errorMsg(w, "user not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in GET /user/{screenname}/im-archive", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(newIMArchiveSettings(settings)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// putUserIMArchiveHandler handles the PUT /user/{screenname}/im-archive
+// endpoint. It replaces the user's opt-out and retention period.
+func putUserIMArchiveHandler(w http.ResponseWriter, r *http.Request, imArchive IMArchiveManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	input := imArchiveSettings{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&input); err != nil {
+		errorMsg(w, "malformed input", http.StatusBadRequest)
+		return
+	}
+	if input.RetentionDays != nil && *input.RetentionDays < 0 {
+		errorMsg(w, "retention_days must be 0 or greater", http.StatusBadRequest)
+		return
+	}
+
+	screenName := state.NewIdentScreenName(r.PathValue("screenname"))
+	before, err := imArchive.IMArchiveSettings(r.Context(), screenName)
+	switch {
+	case errors.Is(err, state.ErrNoUser):
+		errorMsg(w, "user not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in PUT /user/{screenname}/im-archive", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	after := state.IMArchiveSettings{
+		OptOut:        input.OptOut,
+		RetentionDays: input.RetentionDays,
+	}
+	err = imArchive.SetIMArchiveSettings(r.Context(), screenName, after)
+	switch {
+	case errors.Is(err, state.ErrNoUser):
+		errorMsg(w, "user not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in PUT /user/{screenname}/im-archive", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	auditChange(r, newIMArchiveSettings(before), input)
+
+	if err := json.NewEncoder(w).Encode(messageBody{Message: "IM archive settings updated."}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getAccountIMArchiveHandler handles the GET /account/im-archive endpoint,
+// which lets users check their own IM archive settings. Users authenticate
+// with their screen name and password using HTTP basic authentication.
+func getAccountIMArchiveHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, authMode AuthModeRetriever, loginLockout LoginLockoutManager, imArchive IMArchiveManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user := authenticateUser(w, r, userManager, authMode, loginLockout, logger)
+	if user == nil {
+		return
+	}
+
+	settings, err := imArchive.IMArchiveSettings(r.Context(), user.IdentScreenName)
+	if err != nil {
+		logger.Error("error in GET /account/im-archive", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(newIMArchiveSettings(settings)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// putAccountIMArchiveHandler handles the PUT /account/im-archive endpoint,
+// which lets users opt out of, or back into, IM archiving. Users
+// authenticate with their screen name and password using HTTP basic
+// authentication.
+func putAccountIMArchiveHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, authMode AuthModeRetriever, loginLockout LoginLockoutManager, imArchive IMArchiveManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user := authenticateUser(w, r, userManager, authMode, loginLockout, logger)
+	if user == nil {
+		return
+	}
+
+	input := imArchiveOptOut{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&input); err != nil || input.OptOut == nil {
+		errorMsg(w, "malformed input", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := imArchive.IMArchiveSettings(r.Context(), user.IdentScreenName)
+	if err != nil {
+		logger.Error("error in PUT /account/im-archive", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	settings.OptOut = *input.OptOut
+	if err := imArchive.SetIMArchiveSettings(r.Context(), user.IdentScreenName, settings); err != nil {
+		logger.Error("error in PUT /account/im-archive", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(messageBody{Message: "IM archive settings updated."}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// authenticateUser checks the screen name and password sent using HTTP basic
+// authentication, subject to the same checks and lockout as client logins.
+// It returns the authenticated user, or writes an error response and returns
+// nil.
+func authenticateUser(w http.ResponseWriter, r *http.Request, userManager UserManager, authMode AuthModeRetriever, loginLockout LoginLockoutManager, logger *slog.Logger) *state.User {
+	screenName, password, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="User Login"`)
+		errorMsg(w, "authentication required", http.StatusUnauthorized)
+		return nil
+	}
+
+	user, err := checkUserCredentials(r.Context(), userManager, authMode, loginLockout, screenName, password)
+	switch {
+	case errors.Is(err, errInvalidCredentials):
+		w.Header().Set("WWW-Authenticate", `Basic realm="User Login"`)
+		errorMsg(w, "invalid credentials", http.StatusUnauthorized)
+		return nil
+	case err != nil:
+		logger.Error("error getting user", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return nil
+	}
+
+	return user
+}
+
+// newIMArchiveSettings converts IM archive settings to their API
+// representation.
+func newIMArchiveSettings(settings state.IMArchiveSettings) imArchiveSettings {
+	return imArchiveSettings{
+		OptOut:        settings.OptOut,
+		RetentionDays: settings.RetentionDays,
+	}
+}
@@ -0,0 +1,412 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mk6i/retro-aim-server/state"
+)
+
+func TestIMHistoryHandler_GET(t *testing.T) {
+	sentAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name         string
+		query        string
+		expectFilter *state.IMArchiveFilter
+		ims          []state.ArchivedIM
+		err          error
+		want         string
+		statusCode   int
+	}{
+		{
+			name:         "all messages",
+			expectFilter: &state.IMArchiveFilter{Limit: defaultIMHistoryLimit},
+			ims: []state.ArchivedIM{
+				{ID: 2, SentAt: sentAt, Sender: state.NewIdentScreenName("userB"), Recipient: state.NewIdentScreenName("userA"), Text: "hey"},
+				{ID: 1, SentAt: sentAt, Sender: state.NewIdentScreenName("userA"), Recipient: state.NewIdentScreenName("userB"), Text: "<HTML>hello</HTML>"},
+			},
+			want:       `[{"id":2,"sent_at":"2025-01-01T12:00:00Z","sender":"userb","recipient":"usera","text":"hey"},{"id":1,"sent_at":"2025-01-01T12:00:00Z","sender":"usera","recipient":"userb","text":"\u003cHTML\u003ehello\u003c/HTML\u003e"}]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:  "searched and filtered messages",
+			query: "?q=lunch+plans&sender=User+A&recipient=userB&since=2025-01-01T00:00:00Z&until=2025-01-02T00:00:00-05:00&limit=10",
+			expectFilter: &state.IMArchiveFilter{
+				Query:     "lunch plans",
+				Sender:    state.NewIdentScreenName("usera"),
+				Recipient: state.NewIdentScreenName("userb"),
+				Since:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				Until:     time.Date(2025, 1, 2, 0, 0, 0, 0, time.FixedZone("", -5*60*60)),
+				Limit:     10,
+			},
+			want:       `[]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "invalid since",
+			query:      "?since=yesterday",
+			want:       `{"message":"since must be an RFC 3339 timestamp"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "limit out of range",
+			query:      "?limit=0",
+			want:       `{"message":"limit must be between 1 and 1000"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:         "store failure",
+			expectFilter: &state.IMArchiveFilter{Limit: defaultIMHistoryLimit},
+			err:          errors.New("database is locked"),
+			want:         `{"message":"internal server error"}`,
+			statusCode:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/im/history"+tc.query, nil)
+			responseRecorder := httptest.NewRecorder()
+
+			imArchive := newMockIMArchiveManager(t)
+			if tc.expectFilter != nil {
+				imArchive.EXPECT().
+					IMArchive(matchContext(), *tc.expectFilter).
+					Return(tc.ims, tc.err)
+			}
+
+			getIMHistoryHandler(responseRecorder, request, imArchive, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestUserIMArchiveHandler_GET(t *testing.T) {
+	days := 30
+
+	tt := []struct {
+		name       string
+		settings   state.IMArchiveSettings
+		err        error
+		want       string
+		statusCode int
+	}{
+		{
+			name:       "server default retention",
+			settings:   state.IMArchiveSettings{OptOut: true},
+			want:       `{"opt_out":true,"retention_days":null}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "account retention",
+			settings:   state.IMArchiveSettings{RetentionDays: &days},
+			want:       `{"opt_out":false,"retention_days":30}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "user not found",
+			err:        state.ErrNoUser,
+			want:       `{"message":"user not found"}`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "runtime error",
+			err:        errors.New("database is locked"),
+			want:       `{"message":"internal server error"}`,
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/user/userA/im-archive", nil)
+			request.SetPathValue("screenname", "userA")
+			responseRecorder := httptest.NewRecorder()
+
+			imArchive := newMockIMArchiveManager(t)
+			imArchive.EXPECT().
+				IMArchiveSettings(matchContext(), state.NewIdentScreenName("userA")).
+				Return(tc.settings, tc.err)
+
+			getUserIMArchiveHandler(responseRecorder, request, imArchive, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestUserIMArchiveHandler_PUT(t *testing.T) {
+	days := 7
+
+	tt := []struct {
+		name           string
+		body           string
+		expectSettings *state.IMArchiveSettings
+		settingsErr    error
+		setErr         error
+		want           string
+		statusCode     int
+	}{
+		{
+			name:           "set opt-out and retention",
+			body:           `{"opt_out":true,"retention_days":7}`,
+			expectSettings: &state.IMArchiveSettings{OptOut: true, RetentionDays: &days},
+			want:           `{"message":"IM archive settings updated."}`,
+			statusCode:     http.StatusOK,
+		},
+		{
+			name:           "restore server default retention",
+			body:           `{"opt_out":false,"retention_days":null}`,
+			expectSettings: &state.IMArchiveSettings{},
+			want:           `{"message":"IM archive settings updated."}`,
+			statusCode:     http.StatusOK,
+		},
+		{
+			name:       "negative retention",
+			body:       `{"opt_out":false,"retention_days":-1}`,
+			want:       `{"message":"retention_days must be 0 or greater"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed input",
+			body:       `{"opt_out":"yes"}`,
+			want:       `{"message":"malformed input"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "user not found",
+			body:        `{"opt_out":true}`,
+			settingsErr: state.ErrNoUser,
+			want:        `{"message":"user not found"}`,
+			statusCode:  http.StatusNotFound,
+		},
+		{
+			name:           "runtime error",
+			body:           `{"opt_out":true}`,
+			expectSettings: &state.IMArchiveSettings{OptOut: true},
+			setErr:         errors.New("database is locked"),
+			want:           `{"message":"internal server error"}`,
+			statusCode:     http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPut, "/user/userA/im-archive", strings.NewReader(tc.body))
+			request.SetPathValue("screenname", "userA")
+			responseRecorder := httptest.NewRecorder()
+
+			imArchive := newMockIMArchiveManager(t)
+			if tc.expectSettings != nil || tc.settingsErr != nil {
+				imArchive.EXPECT().
+					IMArchiveSettings(matchContext(), state.NewIdentScreenName("userA")).
+					Return(state.IMArchiveSettings{}, tc.settingsErr)
+			}
+			if tc.expectSettings != nil {
+				imArchive.EXPECT().
+					SetIMArchiveSettings(matchContext(), state.NewIdentScreenName("userA"), *tc.expectSettings).
+					Return(tc.setErr)
+			}
+
+			putUserIMArchiveHandler(responseRecorder, request, imArchive, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestAccountIMArchiveHandler(t *testing.T) {
+	user := &state.User{IdentScreenName: state.NewIdentScreenName("userA")}
+	provider := state.NewLDAPAuthProvider("ldap://localhost", "uid=%s,dc=example,dc=com")
+	days := 30
+
+	tt := []struct {
+		name           string
+		method         string
+		body           string
+		username       string
+		password       string
+		locked         bool
+		authResult     *state.User
+		authErr        error
+		wantFailure    bool
+		settings       *state.IMArchiveSettings
+		expectSettings *state.IMArchiveSettings
+		want           string
+		statusCode     int
+	}{
+		{
+			name:       "get settings",
+			method:     http.MethodGet,
+			username:   "userA",
+			password:   "thepassword",
+			authResult: user,
+			settings:   &state.IMArchiveSettings{RetentionDays: &days},
+			want:       `{"opt_out":false,"retention_days":30}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:           "opt out, keeping retention set by an admin",
+			method:         http.MethodPut,
+			body:           `{"opt_out":true}`,
+			username:       "userA",
+			password:       "thepassword",
+			authResult:     user,
+			settings:       &state.IMArchiveSettings{RetentionDays: &days},
+			expectSettings: &state.IMArchiveSettings{OptOut: true, RetentionDays: &days},
+			want:           `{"message":"IM archive settings updated."}`,
+			statusCode:     http.StatusOK,
+		},
+		{
+			name:       "users can't change their retention",
+			method:     http.MethodPut,
+			body:       `{"opt_out":true,"retention_days":0}`,
+			username:   "userA",
+			password:   "thepassword",
+			authResult: user,
+			want:       `{"message":"malformed input"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "missing opt_out",
+			method:     http.MethodPut,
+			body:       `{}`,
+			username:   "userA",
+			password:   "thepassword",
+			authResult: user,
+			want:       `{"message":"malformed input"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "wrong password",
+			method:      http.MethodPut,
+			body:        `{"opt_out":true}`,
+			username:    "userA",
+			password:    "wrongpassword",
+			authErr:     state.ErrBadCredentials,
+			wantFailure: true,
+			want:        `{"message":"invalid credentials"}`,
+			statusCode:  http.StatusUnauthorized,
+		},
+		{
+			name:        "missing one-time code for two-factor account",
+			method:      http.MethodPut,
+			body:        `{"opt_out":true}`,
+			username:    "userA",
+			password:    "thepassword",
+			authErr:     state.ErrBadSecurID,
+			wantFailure: true,
+			want:        `{"message":"invalid credentials"}`,
+			statusCode:  http.StatusUnauthorized,
+		},
+		{
+			name:       "suspended account",
+			method:     http.MethodGet,
+			username:   "userA",
+			password:   "thepassword",
+			authErr:    state.ErrUserSuspended,
+			want:       `{"message":"invalid credentials"}`,
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "user not found",
+			method:      http.MethodGet,
+			username:    "userB",
+			password:    "thepassword",
+			authErr:     state.ErrNoUser,
+			wantFailure: true,
+			want:        `{"message":"invalid credentials"}`,
+			statusCode:  http.StatusUnauthorized,
+		},
+		{
+			name:       "authentication runtime error",
+			method:     http.MethodGet,
+			username:   "userA",
+			password:   "thepassword",
+			authErr:    errors.New("database is locked"),
+			want:       `{"message":"internal server error"}`,
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name:       "locked out account is rejected without checking the password",
+			method:     http.MethodGet,
+			username:   "userA",
+			password:   "thepassword",
+			locked:     true,
+			want:       `{"message":"invalid credentials"}`,
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "no credentials",
+			method:     http.MethodGet,
+			want:       `{"message":"authentication required"}`,
+			statusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(tc.method, "/account/im-archive", strings.NewReader(tc.body))
+			if tc.username != "" {
+				request.SetBasicAuth(tc.username, tc.password)
+			}
+			responseRecorder := httptest.NewRecorder()
+
+			screenName := state.NewIdentScreenName(tc.username)
+			userManager := newMockUserManager(t)
+			loginLockout := newMockLoginLockoutManager(t)
+			if tc.username != "" {
+				loginLockout.EXPECT().
+					IsLocked(screenName).
+					Return(tc.locked)
+			}
+			if tc.username != "" && !tc.locked {
+				userManager.EXPECT().
+					AuthenticateUser(matchContext(), provider, tc.username, tc.password, "").
+					Return(tc.authResult, tc.authErr)
+			}
+			if tc.authResult != nil {
+				loginLockout.EXPECT().RecordSuccess(screenName)
+			}
+			if tc.wantFailure {
+				loginLockout.EXPECT().RecordFailure(screenName)
+			}
+			imArchive := newMockIMArchiveManager(t)
+			if tc.settings != nil {
+				imArchive.EXPECT().
+					IMArchiveSettings(matchContext(), user.IdentScreenName).
+					Return(*tc.settings, nil)
+			}
+			if tc.expectSettings != nil {
+				imArchive.EXPECT().
+					SetIMArchiveSettings(matchContext(), user.IdentScreenName, *tc.expectSettings).
+					Return(nil)
+			}
+			authMode := state.NewAuthMode(false, provider)
+
+			if tc.method == http.MethodGet {
+				getAccountIMArchiveHandler(responseRecorder, request, userManager, authMode, loginLockout, imArchive, slog.Default())
+			} else {
+				putAccountIMArchiveHandler(responseRecorder, request, userManager, authMode, loginLockout, imArchive, slog.Default())
+			}
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+			if tc.statusCode == http.StatusUnauthorized {
+				assert.Equal(t, `Basic realm="User Login"`, responseRecorder.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
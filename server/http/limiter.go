@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"golang.org/x/time/rate"
+)
+
+// keyRateLimiter enforces a token bucket rate limit per key, such as a
+// client's IP address or a screen name. The limiter for a key is discarded
+// once it's older than the TTL, so the TTL should be long enough for the
+// bucket to refill. A keyRateLimiter is safe for concurrent use.
+type keyRateLimiter struct {
+	cache *cache.Cache
+	rate  rate.Limit
+	burst int
+}
+
+// newKeyRateLimiter creates a new instance of keyRateLimiter that allows
+// burst requests per key at once, refilled at rate.
+func newKeyRateLimiter(rate rate.Limit, burst int, ttl time.Duration) *keyRateLimiter {
+	return &keyRateLimiter{
+		cache: cache.New(ttl, 2*ttl),
+		rate:  rate,
+		burst: burst,
+	}
+}
+
+// Allow indicates whether a request for key may proceed now.
+func (l *keyRateLimiter) Allow(key string) bool {
+	// Add is a no-op if another request already created the limiter
+	_ = l.cache.Add(key, rate.NewLimiter(l.rate, l.burst), cache.DefaultExpiration)
+	limiter, found := l.cache.Get(key)
+	if !found {
+		// expired between Add and Get
+		return true
+	}
+	return limiter.(*rate.Limiter).Allow()
+}
+
+// clientIP returns the IP address of the client that sent r.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestKeyRateLimiter_Allow(t *testing.T) {
+	limiter := newKeyRateLimiter(rate.Every(time.Hour), 2, time.Hour)
+
+	assert.True(t, limiter.Allow("userA"))
+	assert.True(t, limiter.Allow("userA"))
+	assert.False(t, limiter.Allow("userA"))
+
+	// each key has its own bucket
+	assert.True(t, limiter.Allow("userB"))
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+
+	r.RemoteAddr = "203.0.113.7:5190"
+	assert.Equal(t, "203.0.113.7", clientIP(r))
+
+	r.RemoteAddr = "203.0.113.7"
+	assert.Equal(t, "203.0.113.7", clientIP(r))
+}
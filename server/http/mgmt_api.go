@@ -3,28 +3,45 @@ package http
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html/template"
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/time/rate"
 
 	"github.com/mk6i/retro-aim-server/config"
+	"github.com/mk6i/retro-aim-server/mailer"
 	"github.com/mk6i/retro-aim-server/state"
 	"github.com/mk6i/retro-aim-server/wire"
 )
 
-func NewManagementAPI(bld config.Build, listener string, userManager UserManager, sessionRetriever SessionRetriever, chatRoomRetriever ChatRoomRetriever, chatRoomCreator ChatRoomCreator, chatRoomDeleter ChatRoomDeleter, chatSessionRetriever ChatSessionRetriever, directoryManager DirectoryManager, messageRelayer MessageRelayer, bartAssetManager BARTAssetManager, feedbagRetriever FeedBagRetriever, accountManager AccountManager, profileRetriever ProfileRetriever, webAPIKeyManager WebAPIKeyManager, logger *slog.Logger) *Server {
+func NewManagementAPI(bld config.Build, listener string, tlsConfig *tls.Config, userManager UserManager, sessionRetriever SessionRetriever, chatRoomRetriever ChatRoomRetriever, chatRoomCreator ChatRoomCreator, chatRoomDeleter ChatRoomDeleter, chatSessionRetriever ChatSessionRetriever, directoryManager DirectoryManager, messageRelayer MessageRelayer, bartAssetManager BARTAssetManager, feedbagRetriever FeedBagRetriever, accountManager AccountManager, profileRetriever ProfileRetriever, webAPIKeyManager WebAPIKeyManager, loginLockoutManager LoginLockoutManager, authMode AuthModeRetriever, emailTokenManager EmailTokenManager, inviteManager InviteManager, popupService PopupService, motdService MOTDService, rateLimitService RateLimitService, relationshipCacheStats RelationshipCacheStatsRetriever, keepAliveStats KeepAliveStatsRetriever, outboundQueueStats OutboundQueueStatsRetriever, configReloader ConfigReloader, apiAdminManager APIAdminManager, auditLog AuditLogManager, imArchive IMArchiveManager, offlineMessages OfflineMessageManager, mailSender Mailer, linkBaseURL string, logger *slog.Logger) *Server {
 	mux := http.NewServeMux()
 
+	// allow each client a handful of reset requests and each account one
+	// reset email every 15 minutes, so the public endpoint can't be used to
+	// flood a mailbox
+	forgotPasswordClientLimiter := newKeyRateLimiter(rate.Every(time.Minute), 5, 5*time.Minute)
+	forgotPasswordAccountLimiter := newKeyRateLimiter(rate.Every(15*time.Minute), 1, 15*time.Minute)
+
+	// lock out a management API account after a handful of wrong passwords,
+	// and a client after enough failures to cover a few mistyped accounts,
+	// so that admin passwords can't be guessed at bcrypt speed
+	apiAdminLockout := state.NewLoginLockoutTracker(5, 15*time.Minute, 15*time.Minute)
+	apiClientLockout := state.NewLoginLockoutTracker(20, 15*time.Minute, 15*time.Minute)
+
 	// Handlers for '/user' route
 	mux.HandleFunc("DELETE /user", func(w http.ResponseWriter, r *http.Request) {
 		deleteUserHandler(w, r, userManager, logger)
@@ -41,9 +58,19 @@ func NewManagementAPI(bld config.Build, listener string, userManager UserManager
 		putUserPasswordHandler(w, r, userManager, logger)
 	})
 
+	// Handlers for '/user/password/legacy' route
+	mux.HandleFunc("GET /user/password/legacy", func(w http.ResponseWriter, r *http.Request) {
+		getUserPasswordLegacyHandler(w, r, userManager, logger)
+	})
+
+	// Handlers for '/user/lockout' route
+	mux.HandleFunc("GET /user/lockout", func(w http.ResponseWriter, r *http.Request) {
+		getUserLockoutHandler(w, r, loginLockoutManager, logger)
+	})
+
 	// Handlers for '/user/login' route
 	mux.HandleFunc("GET /user/login", func(w http.ResponseWriter, r *http.Request) {
-		getUserLoginHandler(w, r, userManager, logger)
+		getUserLoginHandler(w, r, userManager, authMode, loginLockoutManager, logger)
 	})
 
 	// Handlers for '/user/{screenname}/account' route
@@ -54,11 +81,95 @@ func NewManagementAPI(bld config.Build, listener string, userManager UserManager
 		patchUserAccountHandler(w, r, userManager, accountManager, logger)
 	})
 
+	// Handlers for '/user/{screenname}/lockout' route
+	mux.HandleFunc("DELETE /user/{screenname}/lockout", func(w http.ResponseWriter, r *http.Request) {
+		deleteUserLockoutHandler(w, r, loginLockoutManager)
+	})
+
+	// Handlers for '/user/{screenname}/password-reset' route
+	mux.HandleFunc("POST /user/{screenname}/password-reset", func(w http.ResponseWriter, r *http.Request) {
+		postUserPasswordResetHandler(w, r, userManager, accountManager, emailTokenManager, mailSender, linkBaseURL, time.Now, logger)
+	})
+
+	// Handlers for '/account/confirm' route
+	mux.HandleFunc("GET /account/confirm", func(w http.ResponseWriter, r *http.Request) {
+		getAccountConfirmHandler(w, r, accountManager, emailTokenManager, sessionRetriever, time.Now, logger)
+	})
+
+	// Handlers for '/account/forgot-password' route
+	mux.HandleFunc("GET /account/forgot-password", func(w http.ResponseWriter, r *http.Request) {
+		getAccountForgotPasswordHandler(w, r)
+	})
+	mux.HandleFunc("POST /account/forgot-password", func(w http.ResponseWriter, r *http.Request) {
+		postAccountForgotPasswordHandler(w, r, userManager, accountManager, emailTokenManager, mailSender, linkBaseURL, forgotPasswordClientLimiter, forgotPasswordAccountLimiter, time.Now, logger)
+	})
+
+	// Handlers for '/account/password-reset' route
+	mux.HandleFunc("GET /account/password-reset", func(w http.ResponseWriter, r *http.Request) {
+		getAccountPasswordResetHandler(w, r)
+	})
+	mux.HandleFunc("POST /account/password-reset", func(w http.ResponseWriter, r *http.Request) {
+		postAccountPasswordResetHandler(w, r, userManager, accountManager, emailTokenManager, mailSender, time.Now, logger)
+	})
+
+	// Handlers for '/account/im-archive' route
+	mux.HandleFunc("GET /account/im-archive", func(w http.ResponseWriter, r *http.Request) {
+		getAccountIMArchiveHandler(w, r, userManager, authMode, loginLockoutManager, imArchive, logger)
+	})
+	mux.HandleFunc("PUT /account/im-archive", func(w http.ResponseWriter, r *http.Request) {
+		putAccountIMArchiveHandler(w, r, userManager, authMode, loginLockoutManager, imArchive, logger)
+	})
+
+	// Handlers for '/user/{screenname}/totp' route
+	mux.HandleFunc("POST /user/{screenname}/totp", func(w http.ResponseWriter, r *http.Request) {
+		postUserTOTPHandler(w, r, userManager, state.NewTOTPSecret, state.NewTOTPRecoveryCodes, logger)
+	})
+	mux.HandleFunc("DELETE /user/{screenname}/totp", func(w http.ResponseWriter, r *http.Request) {
+		deleteUserTOTPHandler(w, r, userManager, logger)
+	})
+
+	// Handlers for '/user/{screenname}/rate-limits' route
+	mux.HandleFunc("GET /user/{screenname}/rate-limits", func(w http.ResponseWriter, r *http.Request) {
+		getUserRateLimitsHandler(w, r, rateLimitService, logger)
+	})
+	mux.HandleFunc("PUT /user/{screenname}/rate-limits", func(w http.ResponseWriter, r *http.Request) {
+		putUserRateLimitsHandler(w, r, rateLimitService, logger)
+	})
+	mux.HandleFunc("DELETE /user/{screenname}/rate-limits", func(w http.ResponseWriter, r *http.Request) {
+		deleteUserRateLimitsHandler(w, r, rateLimitService, logger)
+	})
+
+	// Handlers for '/user/{screenname}/im-archive' route
+	mux.HandleFunc("GET /user/{screenname}/im-archive", func(w http.ResponseWriter, r *http.Request) {
+		getUserIMArchiveHandler(w, r, imArchive, logger)
+	})
+	mux.HandleFunc("PUT /user/{screenname}/im-archive", func(w http.ResponseWriter, r *http.Request) {
+		putUserIMArchiveHandler(w, r, imArchive, logger)
+	})
+
+	// Handlers for '/user/{screenname}/offline-messages' route
+	mux.HandleFunc("GET /user/{screenname}/offline-messages", func(w http.ResponseWriter, r *http.Request) {
+		getUserOfflineMessagesHandler(w, r, offlineMessages, logger)
+	})
+	mux.HandleFunc("DELETE /user/{screenname}/offline-messages", func(w http.ResponseWriter, r *http.Request) {
+		deleteUserOfflineMessagesHandler(w, r, offlineMessages, logger)
+	})
+
 	// Handlers for '/user/{screenname}/icon' route
 	mux.HandleFunc("GET /user/{screenname}/icon", func(w http.ResponseWriter, r *http.Request) {
 		getUserBuddyIconHandler(w, r, userManager, feedbagRetriever, bartAssetManager, logger)
 	})
 
+	// Handlers for '/invite' route
+	mux.HandleFunc("GET /invite", func(w http.ResponseWriter, r *http.Request) {
+		getInviteHandler(w, r, inviteManager, logger)
+	})
+
+	// Handlers for '/invite/{id}' route
+	mux.HandleFunc("DELETE /invite/{id}", func(w http.ResponseWriter, r *http.Request) {
+		deleteInviteHandler(w, r, inviteManager, logger)
+	})
+
 	// Handlers for '/session' route
 	mux.HandleFunc("GET /session", func(w http.ResponseWriter, r *http.Request) {
 		getSessionHandler(w, r, sessionRetriever, time.Since)
@@ -93,6 +204,38 @@ func NewManagementAPI(bld config.Build, listener string, userManager UserManager
 		postInstantMessageHandler(w, r, messageRelayer, logger)
 	})
 
+	// Handlers for '/popup' route
+	mux.HandleFunc("POST /popup", func(w http.ResponseWriter, r *http.Request) {
+		postPopupHandler(w, r, popupService)
+	})
+
+	// Handlers for '/motd' route
+	mux.HandleFunc("GET /motd", func(w http.ResponseWriter, r *http.Request) {
+		getMOTDHandler(w, motdService)
+	})
+	mux.HandleFunc("PUT /motd", func(w http.ResponseWriter, r *http.Request) {
+		putMOTDHandler(w, r, motdService)
+	})
+	mux.HandleFunc("DELETE /motd", func(w http.ResponseWriter, r *http.Request) {
+		deleteMOTDHandler(w, r, motdService)
+	})
+
+	// Handlers for '/stats' route
+	mux.HandleFunc("GET /stats/relationship-cache", func(w http.ResponseWriter, r *http.Request) {
+		getRelationshipCacheStatsHandler(w, relationshipCacheStats)
+	})
+	mux.HandleFunc("GET /stats/keepalive", func(w http.ResponseWriter, r *http.Request) {
+		getKeepAliveStatsHandler(w, keepAliveStats)
+	})
+	mux.HandleFunc("GET /stats/outbound-queue", func(w http.ResponseWriter, r *http.Request) {
+		getOutboundQueueStatsHandler(w, outboundQueueStats)
+	})
+
+	// Handlers for '/config/reload' route
+	mux.HandleFunc("POST /config/reload", func(w http.ResponseWriter, r *http.Request) {
+		postConfigReloadHandler(w, r, configReloader, logger)
+	})
+
 	// Handlers for '/version' route
 	mux.HandleFunc("GET /version", func(w http.ResponseWriter, r *http.Request) {
 		getVersionHandler(w, bld)
@@ -115,6 +258,36 @@ func NewManagementAPI(bld config.Build, listener string, userManager UserManager
 		deleteWebAPIKeyHandler(w, r, webAPIKeyManager, logger)
 	})
 
+	// Handlers for '/admin/account' route - management API account management
+	mux.HandleFunc("GET /admin/account", func(w http.ResponseWriter, r *http.Request) {
+		getAPIAdminHandler(w, r, apiAdminManager, logger)
+	})
+	mux.HandleFunc("POST /admin/account", func(w http.ResponseWriter, r *http.Request) {
+		postAPIAdminHandler(w, r, apiAdminManager, time.Now, logger)
+	})
+	mux.HandleFunc("PATCH /admin/account/{username}", func(w http.ResponseWriter, r *http.Request) {
+		patchAPIAdminHandler(w, r, apiAdminManager, logger)
+	})
+	mux.HandleFunc("DELETE /admin/account/{username}", func(w http.ResponseWriter, r *http.Request) {
+		deleteAPIAdminHandler(w, r, apiAdminManager, logger)
+	})
+	mux.HandleFunc("POST /admin/account/{username}/token", func(w http.ResponseWriter, r *http.Request) {
+		postAPIAdminTokenHandler(w, r, apiAdminManager, logger)
+	})
+	mux.HandleFunc("DELETE /admin/account/{username}/token", func(w http.ResponseWriter, r *http.Request) {
+		deleteAPIAdminTokenHandler(w, r, apiAdminManager, logger)
+	})
+
+	// Handlers for '/audit' route
+	mux.HandleFunc("GET /audit", func(w http.ResponseWriter, r *http.Request) {
+		getAuditHandler(w, r, auditLog, logger)
+	})
+
+	// Handlers for '/im/history' route
+	mux.HandleFunc("GET /im/history", func(w http.ResponseWriter, r *http.Request) {
+		getIMHistoryHandler(w, r, imArchive, logger)
+	})
+
 	// Handlers for '/directory/category' route
 	mux.HandleFunc("GET /directory/category", func(w http.ResponseWriter, r *http.Request) {
 		getDirectoryCategoryHandler(w, r, directoryManager, logger)
@@ -161,8 +334,9 @@ func NewManagementAPI(bld config.Build, listener string, userManager UserManager
 
 	return &Server{
 		server: http.Server{
-			Addr:    listener,
-			Handler: mux,
+			Addr:      listener,
+			Handler:   requireRole(mux, recordAudit(mux, auditLog, time.Now, logger), apiAdminManager, apiAdminLockout, apiClientLockout, logger),
+			TLSConfig: tlsConfig,
 		},
 		logger: logger,
 	}
@@ -174,9 +348,16 @@ type Server struct {
 }
 
 func (s *Server) ListenAndServe() error {
-	s.logger.Info("starting server", "addr", s.server.Addr)
+	s.logger.Info("starting server", "addr", s.server.Addr, "tls", s.server.TLSConfig != nil)
 
-	if err := s.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+	var err error
+	if s.server.TLSConfig != nil {
+		// the certificate is provided by the TLS config
+		err = s.server.ListenAndServeTLS("", "")
+	} else {
+		err = s.server.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("unable to start management API server: %w", err)
 	}
 
@@ -196,6 +377,7 @@ func deleteUserHandler(w http.ResponseWriter, r *http.Request, manager UserManag
 		return
 	}
 
+	auditTarget(r, user.ScreenName)
 	err = manager.DeleteUser(r.Context(), state.NewIdentScreenName(user.ScreenName))
 	switch {
 	case errors.Is(err, state.ErrNoUser):
@@ -219,126 +401,765 @@ func putUserPasswordHandler(w http.ResponseWriter, r *http.Request, userManager
 		return
 	}
 
-	sn := state.NewIdentScreenName(input.ScreenName)
-
+	sn := state.NewIdentScreenName(input.ScreenName)
+	auditTarget(r, input.ScreenName)
+
+	if err := userManager.SetUserPassword(r.Context(), sn, input.Password); err != nil {
+		switch {
+		case errors.Is(err, state.ErrNoUser):
+			http.Error(w, "user does not exist", http.StatusNotFound)
+			return
+		case errors.Is(err, state.ErrPasswordInvalid):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		default:
+			logger.Error("error updating user password PUT /user/password", "err", err.Error())
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	_, _ = fmt.Fprintln(w, "Password successfully reset.")
+}
+
+// getSessionHandler handles GET /session
+func getSessionHandler(w http.ResponseWriter, r *http.Request, sessionRetriever SessionRetriever, funcTimeSince func(t time.Time) time.Duration) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var allUsers []*state.Session
+
+	if screenName := r.PathValue("screenname"); screenName != "" {
+		session := sessionRetriever.RetrieveSession(state.NewIdentScreenName(screenName))
+		if session == nil {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		allUsers = append(allUsers, session)
+	} else {
+		allUsers = sessionRetriever.AllSessions()
+	}
+
+	ou := onlineUsers{
+		Count:    len(allUsers),
+		Sessions: make([]sessionHandle, len(allUsers)),
+	}
+
+	for i, s := range allUsers {
+		// report 0 if the user is not idle
+		idleSeconds := funcTimeSince(s.IdleTime()).Seconds()
+		if !s.Idle() {
+			idleSeconds = 0
+		}
+		onlineSeconds := funcTimeSince(s.SignonTime()).Seconds()
+		queueStats := s.OutboundQueueStats()
+
+		ou.Sessions[i] = sessionHandle{
+			ID:            s.IdentScreenName().String(),
+			ScreenName:    s.DisplayScreenName().String(),
+			OnlineSeconds: onlineSeconds,
+			AwayMessage:   s.AwayMessage(),
+			IdleSeconds:   idleSeconds,
+			IsICQ:         s.UIN() > 0,
+			Queued:        queueStats.Queued,
+			Dropped:       queueStats.Dropped,
+			Coalesced:     queueStats.Coalesced,
+		}
+		ra := s.RemoteAddr()
+		if ra != nil {
+			ou.Sessions[i].RemoteAddr = ra.Addr().String()
+			ou.Sessions[i].RemotePort = ra.Port()
+		}
+
+	}
+
+	if err := json.NewEncoder(w).Encode(ou); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// deleteSessionHandler handles DELETE /session/{screenname}
+func deleteSessionHandler(w http.ResponseWriter, r *http.Request, sessionRetriever SessionRetriever) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if screenName := r.PathValue("screenname"); screenName != "" {
+		session := sessionRetriever.RetrieveSession(state.NewIdentScreenName(screenName))
+		if session == nil {
+			errorMsg(w, "session not found", http.StatusNotFound)
+			return
+		}
+		session.Close()
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getUserHandler handles the GET /user endpoint.
+func getUserHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	users, err := userManager.AllUsers(r.Context())
+	if err != nil {
+		logger.Error("error in GET /user", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	out := make([]userHandle, len(users))
+	for i, u := range users {
+		suspendedStatus, err := getSuspendedStatusErrCodeToText(u.SuspendedStatus)
+		if err != nil {
+			logger.Error("error getting suspended status in GET /user", "err", err.Error())
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		out[i] = userHandle{
+			ID:              u.IdentScreenName.String(),
+			ScreenName:      u.DisplayScreenName.String(),
+			IsICQ:           u.IsICQ,
+			SuspendedStatus: suspendedStatus,
+			IsBot:           u.IsBot,
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// getUserPasswordLegacyHandler handles the GET /user/password/legacy endpoint.
+// It reports the users whose passwords are only stored as MD5 hashes.
+func getUserPasswordLegacyHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	users, err := userManager.LegacyHashUsers(r.Context())
+	if err != nil {
+		logger.Error("error in GET /user/password/legacy", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	out := make([]legacyPasswordUserHandle, len(users))
+	for i, u := range users {
+		out[i] = legacyPasswordUserHandle{
+			ID:         u.IdentScreenName.String(),
+			ScreenName: u.DisplayScreenName.String(),
+			IsICQ:      u.IsICQ,
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// postUserTOTPHandler handles the POST /user/{screenname}/totp endpoint. It
+// enrolls the user in two-factor auth and returns the TOTP secret and
+// recovery codes, which are only revealed this one time.
+func postUserTOTPHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, newSecret func() (string, error), newRecoveryCodes func() ([]string, error), logger *slog.Logger) {
+	user, err := userManager.User(r.Context(), state.NewIdentScreenName(r.PathValue("screenname")))
+	if err != nil {
+		logger.Error("error in POST /user/{screenname}/totp", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	if user.TOTPEnabled() {
+		http.Error(w, "two-factor auth is already enabled", http.StatusConflict)
+		return
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		logger.Error("error in POST /user/{screenname}/totp", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	recoveryCodes, err := newRecoveryCodes()
+	if err != nil {
+		logger.Error("error in POST /user/{screenname}/totp", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := userManager.SetTOTP(r.Context(), user.IdentScreenName, secret, recoveryCodes); err != nil {
+		logger.Error("error in POST /user/{screenname}/totp", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	auditChange(r, totpStatus{Enabled: false}, totpStatus{Enabled: true})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	out := totpEnrollmentHandle{
+		Secret:        secret,
+		URI:           state.TOTPKeyURI(user.DisplayScreenName, secret),
+		RecoveryCodes: recoveryCodes,
+	}
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		logger.Error("error in POST /user/{screenname}/totp", "err", err.Error())
+		return
+	}
+}
+
+// deleteUserTOTPHandler handles the DELETE /user/{screenname}/totp endpoint.
+// It disables two-factor auth for the user.
+func deleteUserTOTPHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, logger *slog.Logger) {
+	user, err := userManager.User(r.Context(), state.NewIdentScreenName(r.PathValue("screenname")))
+	switch {
+	case err != nil:
+		logger.Error("error in DELETE /user/{screenname}/totp", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	case user == nil:
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	err = userManager.ClearTOTP(r.Context(), user.IdentScreenName)
+	switch {
+	case errors.Is(err, state.ErrNoUser):
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in DELETE /user/{screenname}/totp", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	auditChange(r, totpStatus{Enabled: user.TOTPEnabled()}, totpStatus{Enabled: false})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getUserRateLimitsHandler handles the GET /user/{screenname}/rate-limits
+// endpoint. It returns the rate limit classes that override the server's
+// classes for the user.
+func getUserRateLimitsHandler(w http.ResponseWriter, r *http.Request, rateLimitService RateLimitService, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	classes, err := rateLimitService.Overrides(r.Context(), state.NewIdentScreenName(r.PathValue("screenname")))
+	switch {
+	case errors.Is(err, state.ErrNoUser):
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in GET /user/{screenname}/rate-limits", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(newRateLimitOverrides(classes)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// putUserRateLimitsHandler handles the PUT /user/{screenname}/rate-limits
+// endpoint. It replaces the user's rate limit overrides and applies them to
+// the user's active sessions.
+func putUserRateLimitsHandler(w http.ResponseWriter, r *http.Request, rateLimitService RateLimitService, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	input := rateLimitOverrides{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		errorMsg(w, "malformed input", http.StatusBadRequest)
+		return
+	}
+
+	classes := make([]wire.RateClass, 0, len(input.Classes))
+	seen := make(map[wire.RateLimitClassID]bool)
+	for _, class := range input.Classes {
+		rc := class.WireRateClass()
+		if err := rc.Validate(); err != nil {
+			errorMsg(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if seen[rc.ID] {
+			errorMsg(w, fmt.Sprintf("duplicate rate class %d", rc.ID), http.StatusBadRequest)
+			return
+		}
+		seen[rc.ID] = true
+		classes = append(classes, rc)
+	}
+
+	screenName := state.NewIdentScreenName(r.PathValue("screenname"))
+	before, err := rateLimitService.Overrides(r.Context(), screenName)
+	switch {
+	case errors.Is(err, state.ErrNoUser):
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in PUT /user/{screenname}/rate-limits", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	err = rateLimitService.SetOverrides(r.Context(), screenName, classes)
+	switch {
+	case errors.Is(err, state.ErrNoUser):
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in PUT /user/{screenname}/rate-limits", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	auditChange(r, newRateLimitOverrides(before), input)
+
+	if err := json.NewEncoder(w).Encode(messageBody{Message: "Rate limits updated."}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// newRateLimitOverrides converts rate limit classes to their API
+// representation.
+func newRateLimitOverrides(classes []wire.RateClass) rateLimitOverrides {
+	out := rateLimitOverrides{Classes: make([]config.RateClass, len(classes))}
+	for i, class := range classes {
+		out.Classes[i] = config.NewRateClass(class)
+	}
+	return out
+}
+
+// deleteUserRateLimitsHandler handles the DELETE
+// /user/{screenname}/rate-limits endpoint. It removes the user's rate limit
+// overrides, restoring the server's classes.
+func deleteUserRateLimitsHandler(w http.ResponseWriter, r *http.Request, rateLimitService RateLimitService, logger *slog.Logger) {
+	screenName := state.NewIdentScreenName(r.PathValue("screenname"))
+	before, err := rateLimitService.Overrides(r.Context(), screenName)
+	if err == nil {
+		err = rateLimitService.SetOverrides(r.Context(), screenName, nil)
+	}
+	switch {
+	case errors.Is(err, state.ErrNoUser):
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in DELETE /user/{screenname}/rate-limits", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	auditChange(r, newRateLimitOverrides(before), nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getUserLockoutHandler handles the GET /user/lockout endpoint. It reports the
+// accounts that are locked out after too many failed login attempts.
+func getUserLockoutHandler(w http.ResponseWriter, r *http.Request, loginLockoutManager LoginLockoutManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	lockouts := loginLockoutManager.Lockouts()
+
+	out := make([]loginLockoutHandle, len(lockouts))
+	for i, l := range lockouts {
+		out[i] = loginLockoutHandle{
+			ScreenName:     l.ScreenName.String(),
+			FailedAttempts: l.FailedAttempts,
+			LockedUntil:    l.LockedUntil.UTC(),
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		logger.Error("error in GET /user/lockout", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// deleteUserLockoutHandler handles the DELETE /user/{screenname}/lockout
+// endpoint. It lifts the lockout for an account.
+func deleteUserLockoutHandler(w http.ResponseWriter, r *http.Request, loginLockoutManager LoginLockoutManager) {
+	screenName := state.NewIdentScreenName(r.PathValue("screenname"))
+	before, ok := loginLockoutManager.ClearLockout(screenName)
+	if !ok {
+		http.Error(w, "account has no failed login attempts", http.StatusNotFound)
+		return
+	}
+	auditChange(r, loginLockoutHandle{
+		ScreenName:     before.ScreenName.String(),
+		FailedAttempts: before.FailedAttempts,
+		LockedUntil:    before.LockedUntil.UTC(),
+	}, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getInviteHandler handles the GET /invite endpoint. The optional status
+// query parameter filters invitations by status.
+func getInviteHandler(w http.ResponseWriter, r *http.Request, inviteManager InviteManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	status := state.InviteStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", state.InviteStatusPending, state.InviteStatusAccepted:
+	default:
+		errorMsg(w, "invalid status. valid values: pending, accepted", http.StatusBadRequest)
+		return
+	}
+
+	invites, err := inviteManager.Invites(r.Context(), status)
+	if err != nil {
+		logger.Error("error in GET /invite", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	out := make([]inviteHandle, len(invites))
+	for i, inv := range invites {
+		out[i] = newInviteHandle(inv)
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		logger.Error("error in GET /invite", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// newInviteHandle converts an invitation to its API representation.
+func newInviteHandle(inv state.Invite) inviteHandle {
+	return inviteHandle{
+		ID:         inv.ID,
+		Sender:     inv.Sender.String(),
+		Email:      inv.Email,
+		Message:    inv.Message,
+		CreatedAt:  inv.CreatedAt.UTC(),
+		Status:     string(inv.Status()),
+		AcceptedBy: inv.AcceptedBy.String(),
+	}
+}
+
+// deleteInviteHandler handles the DELETE /invite/{id} endpoint.
+func deleteInviteHandler(w http.ResponseWriter, r *http.Request, inviteManager InviteManager, logger *slog.Logger) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		errorMsg(w, "invalid invite ID", http.StatusBadRequest)
+		return
+	}
+
+	// find the invitation as it was before the deletion for the audit log
+	invites, err := inviteManager.Invites(r.Context(), "")
+	if err != nil {
+		logger.Error("error in DELETE /invite/{id}", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	idx := slices.IndexFunc(invites, func(inv state.Invite) bool { return inv.ID == id })
+	if idx < 0 {
+		errorMsg(w, "invite not found", http.StatusNotFound)
+		return
+	}
+
+	if err := inviteManager.DeleteInvite(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, state.ErrNoInvite):
+			errorMsg(w, "invite not found", http.StatusNotFound)
+		default:
+			logger.Error("error in DELETE /invite/{id}", "err", err.Error())
+			errorMsg(w, "internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	auditChange(r, newInviteHandle(invites[idx]), nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// postUserPasswordResetHandler handles the POST /user/{screenname}/password-reset
+// endpoint. It emails the user a link for choosing a new password.
+func postUserPasswordResetHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, accountManager AccountManager, emailTokenManager EmailTokenManager, mailSender Mailer, linkBaseURL string, timeNow func() time.Time, logger *slog.Logger) {
+	if mailSender == nil {
+		http.Error(w, "email delivery is not configured", http.StatusNotImplemented)
+		return
+	}
+
+	sn := state.NewIdentScreenName(r.PathValue("screenname"))
+
+	user, err := userManager.User(r.Context(), sn)
+	if err != nil {
+		logger.Error("error retrieving user", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	emailAddress, err := accountManager.EmailAddress(r.Context(), sn)
+	switch {
+	case errors.Is(err, state.ErrNoEmailAddress):
+		http.Error(w, "user has no email address", http.StatusConflict)
+		return
+	case err != nil:
+		logger.Error("error retrieving email address", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	token, err := emailTokenManager.IssueEmailToken(r.Context(), sn, state.EmailTokenPasswordReset, timeNow().Add(state.PasswordResetTokenTTL))
+	if err != nil {
+		logger.Error("error issuing password reset token", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	link := mailer.Link(linkBaseURL, mailer.PasswordResetPath, token)
+	msg := mailer.NewPasswordResetMessage(emailAddress, user.DisplayScreenName.String(), link, state.PasswordResetTokenTTL)
+	if err := mailSender.Send(r.Context(), msg); err != nil {
+		logger.Error("error sending password reset email", "err", err.Error())
+		http.Error(w, "error sending email", http.StatusBadGateway)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintln(w, "Password reset email sent.")
+}
+
+// getAccountConfirmHandler handles the GET /account/confirm endpoint. It
+// redeems the token from an account confirmation email.
+func getAccountConfirmHandler(w http.ResponseWriter, r *http.Request, accountManager AccountManager, emailTokenManager EmailTokenManager, sessionRetriever SessionRetriever, timeNow func() time.Time, logger *slog.Logger) {
+	sn, err := emailTokenManager.RedeemEmailToken(r.Context(), r.URL.Query().Get("token"), state.EmailTokenConfirm, timeNow())
+	switch {
+	case errors.Is(err, state.ErrInvalidEmailToken):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		logger.Error("error redeeming confirmation token", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := accountManager.UpdateConfirmStatus(r.Context(), sn, true); err != nil {
+		logger.Error("error updating confirm status", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// buddies see the change the next time the user signs on
+	if sess := sessionRetriever.RetrieveSession(sn); sess != nil {
+		sess.ClearUserInfoFlag(wire.OServiceUserFlagUnconfirmed)
+	}
+
+	_, _ = fmt.Fprintln(w, "Your account is confirmed.")
+}
+
+// passwordResetForm is the page served by GET /account/password-reset.
+var passwordResetForm = template.Must(template.New("password-reset").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Reset your password</title></head>
+<body>
+<h1>Reset your password</h1>
+<form method="post" action="/account/password-reset">
+<input type="hidden" name="token" value="{{.}}">
+<label>New password <input type="password" name="password" required></label>
+<button type="submit">Reset password</button>
+</form>
+</body>
+</html>
+`))
+
+// getAccountPasswordResetHandler handles the GET /account/password-reset
+// endpoint. It serves a form that lets the user choose a new password.
+func getAccountPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = passwordResetForm.Execute(w, r.URL.Query().Get("token"))
+}
+
+// postAccountPasswordResetHandler handles the POST /account/password-reset
+// endpoint. It accepts either a JSON body or the form served by
+// getAccountPasswordResetHandler. The token is only spent once the new
+// password is accepted, so a user who picks an invalid password can try again
+// with the same link.
+func postAccountPasswordResetHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, accountManager AccountManager, emailTokenManager EmailTokenManager, mailSender Mailer, timeNow func() time.Time, logger *slog.Logger) {
+	var input passwordReset
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		input.Token = r.PostFormValue("token")
+		input.Password = r.PostFormValue("password")
+	} else if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "malformed input", http.StatusBadRequest)
+		return
+	}
+
+	sn, err := emailTokenManager.LookupEmailToken(r.Context(), input.Token, state.EmailTokenPasswordReset, timeNow())
+	switch {
+	case errors.Is(err, state.ErrInvalidEmailToken):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		logger.Error("error looking up password reset token", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := userManager.User(r.Context(), sn)
+	if err != nil {
+		logger.Error("error retrieving user", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "user does not exist", http.StatusNotFound)
+		return
+	}
+
+	if err := user.ValidatePassword(input.Password); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// redeem the token before changing the password so that a concurrent
+	// request with the same token can't also succeed
+	_, err = emailTokenManager.RedeemEmailToken(r.Context(), input.Token, state.EmailTokenPasswordReset, timeNow())
+	switch {
+	case errors.Is(err, state.ErrInvalidEmailToken):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		logger.Error("error redeeming password reset token", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	if err := userManager.SetUserPassword(r.Context(), sn, input.Password); err != nil {
 		switch {
 		case errors.Is(err, state.ErrNoUser):
 			http.Error(w, "user does not exist", http.StatusNotFound)
-			return
 		case errors.Is(err, state.ErrPasswordInvalid):
 			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
 		default:
-			logger.Error("error updating user password PUT /user/password", "err", err.Error())
+			logger.Error("error updating user password POST /account/password-reset", "err", err.Error())
 			http.Error(w, "internal server error", http.StatusInternalServerError)
-			return
+		}
+		return
+	}
+
+	if mailSender != nil {
+		emailAddress, err := accountManager.EmailAddress(r.Context(), sn)
+		if err == nil {
+			err = mailSender.Send(r.Context(), mailer.NewPasswordChangedMessage(emailAddress, sn.String()))
+		}
+		if err != nil && !errors.Is(err, state.ErrNoEmailAddress) {
+			logger.Error("error sending password change notification", "err", err.Error())
 		}
 	}
 
 	w.WriteHeader(http.StatusNoContent)
-	_, _ = fmt.Fprintln(w, "Password successfully reset.")
 }
 
-// getSessionHandler handles GET /session
-func getSessionHandler(w http.ResponseWriter, r *http.Request, sessionRetriever SessionRetriever, funcTimeSince func(t time.Time) time.Duration) {
-	w.Header().Set("Content-Type", "application/json")
-
-	var allUsers []*state.Session
+// forgotPasswordResponse is the response to every well-formed
+// POST /account/forgot-password request, so that the endpoint doesn't reveal
+// which accounts exist or have an email address.
+const forgotPasswordResponse = "If the account exists and has an email address, a password reset link was sent to it."
+
+// forgotPasswordForm is the page served by GET /account/forgot-password.
+var forgotPasswordForm = template.Must(template.New("forgot-password").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Forgot your password?</title></head>
+<body>
+<h1>Forgot your password?</h1>
+<form method="post" action="/account/forgot-password">
+<label>Screen name or email address <input type="text" name="account" required></label>
+<button type="submit">Send reset link</button>
+</form>
+</body>
+</html>
+`))
+
+// getAccountForgotPasswordHandler handles the GET /account/forgot-password
+// endpoint. It serves a form that lets the user request a password reset
+// email.
+func getAccountForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = forgotPasswordForm.Execute(w, nil)
+}
 
-	if screenName := r.PathValue("screenname"); screenName != "" {
-		session := sessionRetriever.RetrieveSession(state.NewIdentScreenName(screenName))
-		if session == nil {
-			http.Error(w, "session not found", http.StatusNotFound)
-			return
-		}
-		allUsers = append(allUsers, session)
-	} else {
-		allUsers = sessionRetriever.AllSessions()
+// postAccountForgotPasswordHandler handles the POST /account/forgot-password
+// endpoint. It accepts either a JSON body or the form served by
+// getAccountForgotPasswordHandler and emails a password reset link to the
+// account identified by screen name or email address. Requests are rate
+// limited per client IP; reset emails are rate limited per account without
+// telling the client.
+func postAccountForgotPasswordHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, accountManager AccountManager, emailTokenManager EmailTokenManager, mailSender Mailer, linkBaseURL string, clientLimiter *keyRateLimiter, accountLimiter *keyRateLimiter, timeNow func() time.Time, logger *slog.Logger) {
+	if mailSender == nil {
+		http.Error(w, "email delivery is not configured", http.StatusNotImplemented)
+		return
 	}
 
-	ou := onlineUsers{
-		Count:    len(allUsers),
-		Sessions: make([]sessionHandle, len(allUsers)),
+	if !clientLimiter.Allow(clientIP(r)) {
+		http.Error(w, "too many requests, try again later", http.StatusTooManyRequests)
+		return
 	}
 
-	for i, s := range allUsers {
-		// report 0 if the user is not idle
-		idleSeconds := funcTimeSince(s.IdleTime()).Seconds()
-		if !s.Idle() {
-			idleSeconds = 0
-		}
-		onlineSeconds := funcTimeSince(s.SignonTime()).Seconds()
-
-		ou.Sessions[i] = sessionHandle{
-			ID:            s.IdentScreenName().String(),
-			ScreenName:    s.DisplayScreenName().String(),
-			OnlineSeconds: onlineSeconds,
-			AwayMessage:   s.AwayMessage(),
-			IdleSeconds:   idleSeconds,
-			IsICQ:         s.UIN() > 0,
-		}
-		ra := s.RemoteAddr()
-		if ra != nil {
-			ou.Sessions[i].RemoteAddr = ra.Addr().String()
-			ou.Sessions[i].RemotePort = ra.Port()
-		}
-
+	var input forgotPassword
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		input.Account = r.PostFormValue("account")
+	} else if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "malformed input", http.StatusBadRequest)
+		return
 	}
-
-	if err := json.NewEncoder(w).Encode(ou); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	input.Account = strings.TrimSpace(input.Account)
+	if input.Account == "" {
+		http.Error(w, "account is required", http.StatusBadRequest)
 		return
 	}
-}
-
-// deleteSessionHandler handles DELETE /session/{screenname}
-func deleteSessionHandler(w http.ResponseWriter, r *http.Request, sessionRetriever SessionRetriever) {
-	w.Header().Set("Content-Type", "application/json")
 
-	if screenName := r.PathValue("screenname"); screenName != "" {
-		session := sessionRetriever.RetrieveSession(state.NewIdentScreenName(screenName))
-		if session == nil {
-			errorMsg(w, "session not found", http.StatusNotFound)
-			return
-		}
-		session.Close()
+	if err := sendForgotPasswordEmail(r.Context(), input.Account, userManager, accountManager, emailTokenManager, mailSender, linkBaseURL, accountLimiter, timeNow); err != nil {
+		logger.Error("error sending password reset email", "err", err.Error())
 	}
-	w.WriteHeader(http.StatusNoContent)
-}
-
-// getUserHandler handles the GET /user endpoint.
-func getUserHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, logger *slog.Logger) {
-	w.Header().Set("Content-Type", "application/json")
 
-	users, err := userManager.AllUsers(r.Context())
-	if err != nil {
-		logger.Error("error in GET /user", "err", err.Error())
-		http.Error(w, "internal server error", http.StatusInternalServerError)
-		return
-	}
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintln(w, forgotPasswordResponse)
+}
 
-	out := make([]userHandle, len(users))
-	for i, u := range users {
-		suspendedStatus, err := getSuspendedStatusErrCodeToText(u.SuspendedStatus)
+// sendForgotPasswordEmail emails a password reset link to the account
+// identified by screen name or email address. It returns nil without sending
+// anything if there is no such account, the account has no email address, or
+// the account has requested too many resets.
+func sendForgotPasswordEmail(ctx context.Context, account string, userManager UserManager, accountManager AccountManager, emailTokenManager EmailTokenManager, mailSender Mailer, linkBaseURL string, accountLimiter *keyRateLimiter, timeNow func() time.Time) error {
+	var user *state.User
+	if strings.Contains(account, "@") {
+		u, err := userManager.FindByAIMEmail(ctx, account)
+		switch {
+		case errors.Is(err, state.ErrNoUser):
+			return nil
+		case err != nil:
+			return fmt.Errorf("error retrieving user by email: %w", err)
+		}
+		user = &u
+	} else {
+		u, err := userManager.User(ctx, state.NewIdentScreenName(account))
 		if err != nil {
-			logger.Error("error getting suspended status in GET /user", "err", err.Error())
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-			return
+			return fmt.Errorf("error retrieving user: %w", err)
 		}
-		out[i] = userHandle{
-			ID:              u.IdentScreenName.String(),
-			ScreenName:      u.DisplayScreenName.String(),
-			IsICQ:           u.IsICQ,
-			SuspendedStatus: suspendedStatus,
-			IsBot:           u.IsBot,
+		if u == nil {
+			return nil
 		}
+		user = u
 	}
 
-	if err := json.NewEncoder(w).Encode(out); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if !accountLimiter.Allow(user.IdentScreenName.String()) {
+		return nil
+	}
+
+	emailAddress, err := accountManager.EmailAddress(ctx, user.IdentScreenName)
+	switch {
+	case errors.Is(err, state.ErrNoEmailAddress):
+		return nil
+	case err != nil:
+		return fmt.Errorf("error retrieving email address: %w", err)
+	}
+
+	token, err := emailTokenManager.IssueEmailToken(ctx, user.IdentScreenName, state.EmailTokenPasswordReset, timeNow().Add(state.PasswordResetTokenTTL))
+	if err != nil {
+		return fmt.Errorf("error issuing password reset token: %w", err)
 	}
+
+	link := mailer.Link(linkBaseURL, mailer.PasswordResetPath, token)
+	msg := mailer.NewPasswordResetMessage(emailAddress, user.DisplayScreenName.String(), link, state.PasswordResetTokenTTL)
+	return mailSender.Send(ctx, msg)
 }
 
 // postUserHandler handles the POST /user endpoint.
@@ -386,6 +1207,9 @@ func postUserHandler(w http.ResponseWriter, r *http.Request, userManager UserMan
 		return
 	}
 
+	auditTarget(r, user.DisplayScreenName.String())
+	auditChange(r, nil, userHandle{ID: user.IdentScreenName.String(), ScreenName: user.DisplayScreenName.String(), IsICQ: user.IsICQ})
+
 	w.WriteHeader(http.StatusCreated)
 	_, _ = fmt.Fprintln(w, "User account created successfully.")
 }
@@ -398,9 +1222,41 @@ func userFromBody(r *http.Request) (userWithPassword, error) {
 	return user, nil
 }
 
+// errInvalidCredentials indicates that a screen name and password sent by a
+// user were rejected.
+var errInvalidCredentials = errors.New("invalid credentials")
+
+// checkUserCredentials checks a screen name and password sent by a user the
+// same way as client logins: against the configured auth provider, with the
+// one-time code of users enrolled in two-factor auth appended to the
+// password, and rejecting suspended accounts. Each failure counts toward the
+// account's lockout, and a locked out account is rejected without checking
+// the password. It returns errInvalidCredentials whenever the user can't
+// sign on, so that callers respond the same way.
+func checkUserCredentials(ctx context.Context, userManager UserManager, authMode AuthModeRetriever, loginLockout LoginLockoutManager, screenName string, password string) (*state.User, error) {
+	identSN := state.NewIdentScreenName(screenName)
+	if loginLockout.IsLocked(identSN) {
+		return nil, errInvalidCredentials
+	}
+
+	user, err := userManager.AuthenticateUser(ctx, authMode.AuthProvider(), screenName, password, "")
+	switch {
+	case errors.Is(err, state.ErrNoUser), errors.Is(err, state.ErrBadCredentials), errors.Is(err, state.ErrBadSecurID):
+		loginLockout.RecordFailure(identSN)
+		return nil, errInvalidCredentials
+	case errors.Is(err, state.ErrUserSuspended):
+		return nil, errInvalidCredentials
+	case err != nil:
+		return nil, err
+	}
+	loginLockout.RecordSuccess(identSN)
+
+	return user, nil
+}
+
 // getUserLoginHandler is a temporary endpoint for validating user credentials for
 // chivanet. do not rely on this endpoint, as it will be eventually removed.
-func getUserLoginHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, logger *slog.Logger) {
+func getUserLoginHandler(w http.ResponseWriter, r *http.Request, userManager UserManager, authMode AuthModeRetriever, loginLockout LoginLockoutManager, logger *slog.Logger) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
 		// No authentication header found
@@ -431,20 +1287,20 @@ func getUserLoginHandler(w http.ResponseWriter, r *http.Request, userManager Use
 		return
 	}
 
-	username, password := state.NewIdentScreenName(pair[0]), pair[1]
+	username, password := pair[0], pair[1]
 
-	user, err := userManager.User(r.Context(), username)
-	if err != nil {
+	_, err = checkUserCredentials(r.Context(), userManager, authMode, loginLockout, username, password)
+	switch {
+	case errors.Is(err, errInvalidCredentials):
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("401 Unauthorized: Invalid Credentials\n"))
+		return
+	case err != nil:
 		w.WriteHeader(http.StatusInternalServerError)
 		_, _ = w.Write([]byte("500 InternalServerError\n"))
 		logger.Error("error getting user", "err", err.Error())
 		return
 	}
-	if user == nil || !user.ValidateHash(wire.StrongMD5PasswordHash(password, user.AuthKey)) {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = w.Write([]byte("401 Unauthorized: Invalid Credentials\n"))
-		return
-	}
 
 	// Successfully authenticated
 	w.WriteHeader(http.StatusOK)
@@ -510,6 +1366,8 @@ func postPublicChatHandler(w http.ResponseWriter, r *http.Request, chatRoomCreat
 		return
 	}
 
+	auditTarget(r, cr.Name())
+
 	w.WriteHeader(http.StatusCreated)
 	_, _ = fmt.Fprintln(w, "Chat room created successfully.")
 }
@@ -567,6 +1425,8 @@ func deletePublicChatHandler(w http.ResponseWriter, r *http.Request, chatRoomDel
 		return
 	}
 
+	auditTarget(r, strings.Join(input.Names, ", "))
+
 	w.WriteHeader(http.StatusNoContent)
 	_, _ = fmt.Fprintln(w, "Chat rooms deleted successfully.")
 }
@@ -626,11 +1486,155 @@ func postInstantMessageHandler(w http.ResponseWriter, r *http.Request, messageRe
 		},
 	}
 	messageRelayer.RelayToScreenName(context.Background(), state.NewIdentScreenName(input.To), msg)
+	auditTarget(r, input.To)
+	auditChange(r, nil, input)
 
 	w.WriteHeader(http.StatusOK)
 	_, _ = fmt.Fprintln(w, "Message sent successfully.")
 }
 
+// postPopupHandler handles the POST /popup endpoint. It displays a popup
+// window on the clients of the given users, or of everyone online.
+func postPopupHandler(w http.ResponseWriter, r *http.Request, popupService PopupService) {
+	w.Header().Set("Content-Type", "application/json")
+
+	input := popupRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		errorMsg(w, "malformed input", http.StatusBadRequest)
+		return
+	}
+
+	if input.Message == "" && input.URL == "" {
+		errorMsg(w, "message or url is required", http.StatusBadRequest)
+		return
+	}
+	if input.Everyone == (len(input.ScreenNames) > 0) {
+		errorMsg(w, "either screen_names or everyone must be set", http.StatusBadRequest)
+		return
+	}
+
+	body := wire.SNAC_0x08_0x02_PopupDisplay{}
+	if input.Message != "" {
+		body.Append(wire.NewTLVBE(wire.PopupTLVMessage, input.Message))
+	}
+	if input.URL != "" {
+		body.Append(wire.NewTLVBE(wire.PopupTLVURL, input.URL))
+	}
+	if input.Width > 0 {
+		body.Append(wire.NewTLVBE(wire.PopupTLVWidth, input.Width))
+	}
+	if input.Height > 0 {
+		body.Append(wire.NewTLVBE(wire.PopupTLVHeight, input.Height))
+	}
+	if input.Delay > 0 {
+		body.Append(wire.NewTLVBE(wire.PopupTLVDelay, input.Delay))
+	}
+
+	if input.Everyone {
+		popupService.DisplayAll(r.Context(), body)
+		auditTarget(r, "everyone")
+	} else {
+		screenNames := make([]state.IdentScreenName, len(input.ScreenNames))
+		for i, sn := range input.ScreenNames {
+			screenNames[i] = state.NewIdentScreenName(sn)
+		}
+		popupService.Display(r.Context(), screenNames, body)
+		auditTarget(r, strings.Join(input.ScreenNames, ", "))
+	}
+	auditChange(r, nil, input)
+
+	if err := json.NewEncoder(w).Encode(messageBody{Message: "Popup sent."}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getMOTDHandler handles the GET /motd endpoint.
+func getMOTDHandler(w http.ResponseWriter, motdService MOTDService) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(motd{Message: motdService.Message()}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// putMOTDHandler handles the PUT /motd endpoint. It replaces the message of
+// the day and pushes it to everyone online.
+func putMOTDHandler(w http.ResponseWriter, r *http.Request, motdService MOTDService) {
+	w.Header().Set("Content-Type", "application/json")
+
+	input := motd{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		errorMsg(w, "malformed input", http.StatusBadRequest)
+		return
+	}
+	if input.Message == "" {
+		errorMsg(w, "message is required", http.StatusBadRequest)
+		return
+	}
+
+	before := motdService.Message()
+	motdService.SetMessage(r.Context(), input.Message)
+	auditChange(r, motd{Message: before}, input)
+
+	if err := json.NewEncoder(w).Encode(messageBody{Message: "Message of the day updated."}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// deleteMOTDHandler handles the DELETE /motd endpoint. It clears the message
+// of the day.
+func deleteMOTDHandler(w http.ResponseWriter, r *http.Request, motdService MOTDService) {
+	before := motdService.Message()
+	motdService.SetMessage(r.Context(), "")
+	auditChange(r, motd{Message: before}, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getRelationshipCacheStatsHandler handles the GET
+// /stats/relationship-cache endpoint.
+func getRelationshipCacheStatsHandler(w http.ResponseWriter, retriever RelationshipCacheStatsRetriever) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(retriever.RelationshipCacheStats()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getKeepAliveStatsHandler handles the GET /stats/keepalive endpoint.
+func getKeepAliveStatsHandler(w http.ResponseWriter, retriever KeepAliveStatsRetriever) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(retriever.KeepAliveStats()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getOutboundQueueStatsHandler handles the GET /stats/outbound-queue endpoint.
+func getOutboundQueueStatsHandler(w http.ResponseWriter, retriever OutboundQueueStatsRetriever) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(retriever.OutboundQueueTotals()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// postConfigReloadHandler handles the POST /config/reload endpoint. It
+// reloads the settings file and applies the settings that can change while
+// the server runs.
+func postConfigReloadHandler(w http.ResponseWriter, r *http.Request, configReloader ConfigReloader, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := configReloader.Reload(r.Context()); err != nil {
+		if errors.Is(err, config.ErrInvalidConfig) {
+			errorMsg(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.Error("error reloading configuration", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(messageBody{Message: "Configuration reloaded."}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // getUserBuddyIconHandler handles the GET /user/{screenname}/icon endpoint.
 func getUserBuddyIconHandler(w http.ResponseWriter, r *http.Request, u UserManager, f FeedBagRetriever, b BARTAssetManager, logger *slog.Logger) {
 	screenName := state.NewIdentScreenName(r.PathValue("screenname"))
@@ -753,6 +1757,14 @@ func patchUserAccountHandler(w http.ResponseWriter, r *http.Request, userManager
 		return
 	}
 	modifiedUser := false
+	suspendedStatusText, err := getSuspendedStatusErrCodeToText(user.SuspendedStatus)
+	if err != nil {
+		logger.Error("error in PATCH /user/{screenname}/account", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	before := userAccountState{SuspendedStatus: suspendedStatusText, IsBot: user.IsBot}
+	after := before
 
 	if input.SuspendedStatusText != nil {
 		switch *input.SuspendedStatusText {
@@ -772,6 +1784,7 @@ func patchUserAccountHandler(w http.ResponseWriter, r *http.Request, userManager
 					return
 				}
 				modifiedUser = true
+				after.SuspendedStatus = *input.SuspendedStatusText
 			}
 		default:
 			errorMsg(w, "suspended_status must be empty str or one of deleted,expired,suspended,suspended_age", http.StatusBadRequest)
@@ -786,12 +1799,14 @@ func patchUserAccountHandler(w http.ResponseWriter, r *http.Request, userManager
 			return
 		}
 		modifiedUser = true
+		after.IsBot = *input.IsBot
 	}
 
 	if !modifiedUser {
 		w.WriteHeader(http.StatusNotModified)
 		return
 	}
+	auditChange(r, before, after)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -880,11 +1895,14 @@ func postDirectoryCategoryHandler(w http.ResponseWriter, r *http.Request, manage
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
 	dc := directoryCategory{
 		ID:   category.ID,
 		Name: category.Name,
 	}
+	auditTarget(r, strconv.Itoa(int(category.ID)))
+	auditChange(r, nil, dc)
+
+	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(dc); err != nil {
 		errorMsg(w, err.Error(), http.StatusBadRequest)
 	}
@@ -898,6 +1916,19 @@ func deleteDirectoryCategoryHandler(w http.ResponseWriter, r *http.Request, mana
 		return
 	}
 
+	// find the category as it was before the deletion for the audit log
+	categories, err := manager.Categories(r.Context())
+	if err != nil {
+		logger.Error("error in DELETE /directory/category/{id}", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	idx := slices.IndexFunc(categories, func(c state.Category) bool { return c.ID == uint8(categoryID) })
+	if idx < 0 {
+		errorMsg(w, "category not found", http.StatusNotFound)
+		return
+	}
+
 	if err := manager.DeleteCategory(r.Context(), uint8(categoryID)); err != nil {
 		switch {
 		case errors.Is(err, state.ErrKeywordCategoryNotFound):
@@ -912,6 +1943,7 @@ func deleteDirectoryCategoryHandler(w http.ResponseWriter, r *http.Request, mana
 			return
 		}
 	}
+	auditChange(r, directoryCategory{ID: categories[idx].ID, Name: categories[idx].Name}, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -976,12 +2008,14 @@ func postDirectoryKeywordHandler(w http.ResponseWriter, r *http.Request, manager
 		}
 	}
 
-	w.WriteHeader(http.StatusCreated)
-
 	dc := directoryKeyword{
 		ID:   kw.ID,
 		Name: kw.Name,
 	}
+	auditTarget(r, strconv.Itoa(int(kw.ID)))
+	auditChange(r, nil, directoryKeywordCreate{CategoryID: input.CategoryID, Name: kw.Name})
+
+	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(dc); err != nil {
 		errorMsg(w, err.Error(), http.StatusBadRequest)
 	}
@@ -995,6 +2029,18 @@ func deleteDirectoryKeywordHandler(w http.ResponseWriter, r *http.Request, manag
 		return
 	}
 
+	// retrieve the keyword as it was before the deletion for the audit log
+	before, err := manager.KeywordByID(r.Context(), uint8(keywordID))
+	switch {
+	case errors.Is(err, state.ErrKeywordNotFound):
+		errorMsg(w, "keyword not found", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Error("error in DELETE /directory/keyword/{id}", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	if err := manager.DeleteKeyword(r.Context(), uint8(keywordID)); err != nil {
 		switch {
 		case errors.Is(err, state.ErrKeywordInUse):
@@ -1009,6 +2055,7 @@ func deleteDirectoryKeywordHandler(w http.ResponseWriter, r *http.Request, manag
 			return
 		}
 	}
+	auditChange(r, directoryKeyword{ID: before.ID, Name: before.Name}, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -1028,6 +2075,13 @@ type BARTAsset struct {
 	Type uint16 `json:"type"`
 }
 
+// bartAssetDeletion is the audit log representation of a deleted BART
+// asset.
+type bartAssetDeletion struct {
+	Hash string `json:"hash"`
+	Size int    `json:"size"`
+}
+
 // getBARTByTypeHandler handles the GET /bart endpoint.
 func getBARTByTypeHandler(w http.ResponseWriter, r *http.Request, bartAssetManager BARTAssetManager, logger *slog.Logger) {
 	w.Header().Set("Content-Type", "application/json")
@@ -1142,11 +2196,13 @@ func postBARTHandler(w http.ResponseWriter, r *http.Request, bartAssetManager BA
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
 	response := BARTAsset{
 		Hash: hex.EncodeToString(hashBytes),
 		Type: bartType,
 	}
+	auditChange(r, nil, response)
+
+	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -1167,6 +2223,18 @@ func deleteBARTHandler(w http.ResponseWriter, r *http.Request, bartAssetManager
 		return
 	}
 
+	// retrieve the asset before the deletion for the audit log
+	body, err := bartAssetManager.BARTItem(r.Context(), hashBytes)
+	if err != nil {
+		logger.Error("error in DELETE /bart", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if body == nil {
+		errorMsg(w, "BART asset not found", http.StatusNotFound)
+		return
+	}
+
 	if err := bartAssetManager.DeleteBARTItem(r.Context(), hashBytes); err != nil {
 		if errors.Is(err, state.ErrBARTItemNotFound) {
 			errorMsg(w, "BART asset not found", http.StatusNotFound)
@@ -1176,6 +2244,7 @@ func deleteBARTHandler(w http.ResponseWriter, r *http.Request, bartAssetManager
 		errorMsg(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
+	auditChange(r, bartAssetDeletion{Hash: hex.EncodeToString(hashBytes), Size: len(body)}, nil)
 
 	msg := messageBody{Message: "BART asset deleted successfully."}
 	json.NewEncoder(w).Encode(msg)
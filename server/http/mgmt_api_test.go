@@ -16,8 +16,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/time/rate"
 
 	"github.com/mk6i/retro-aim-server/config"
+	"github.com/mk6i/retro-aim-server/mailer"
 	"github.com/mk6i/retro-aim-server/state"
 	"github.com/mk6i/retro-aim-server/wire"
 )
@@ -55,7 +57,7 @@ func TestSessionHandler_GET(t *testing.T) {
 		},
 		{
 			name:          "with sessions",
-			want:          `{"count":3,"sessions":[{"id":"usera","screen_name":"userA","online_seconds":0,"away_message":"","idle_seconds":0,"is_icq":false,"remote_addr":"1.2.3.4","remote_port":1234},{"id":"userb","screen_name":"userB","online_seconds":0,"away_message":"","idle_seconds":0,"is_icq":false,"remote_addr":"1.2.3.4","remote_port":1234},{"id":"100003","screen_name":"100003","online_seconds":0,"away_message":"","idle_seconds":0,"is_icq":true,"remote_addr":"1.2.3.4","remote_port":1234}]}`,
+			want:          `{"count":3,"sessions":[{"id":"usera","screen_name":"userA","online_seconds":0,"away_message":"","idle_seconds":0,"is_icq":false,"remote_addr":"1.2.3.4","remote_port":1234,"queued_messages":0,"dropped_messages":0,"coalesced_messages":0},{"id":"userb","screen_name":"userB","online_seconds":0,"away_message":"","idle_seconds":0,"is_icq":false,"remote_addr":"1.2.3.4","remote_port":1234,"queued_messages":0,"dropped_messages":0,"coalesced_messages":0},{"id":"100003","screen_name":"100003","online_seconds":0,"away_message":"","idle_seconds":0,"is_icq":true,"remote_addr":"1.2.3.4","remote_port":1234,"queued_messages":0,"dropped_messages":0,"coalesced_messages":0}]}`,
 			statusCode:    http.StatusOK,
 			timeSinceFunc: func(t time.Time) time.Duration { t0 := time.Now(); return t0.Sub(t0) },
 			mockParams: mockParams{
@@ -138,7 +140,7 @@ func TestSessionHandlerScreenname_GET(t *testing.T) {
 		{
 			name:              "active session found for screenname",
 			requestScreenName: state.NewIdentScreenName("userA"),
-			want:              `{"count":1,"sessions":[{"id":"usera","screen_name":"userA","online_seconds":0,"away_message":"","idle_seconds":0,"is_icq":false,"remote_addr":"1.2.3.4","remote_port":1234}]}`,
+			want:              `{"count":1,"sessions":[{"id":"usera","screen_name":"userA","online_seconds":0,"away_message":"","idle_seconds":0,"is_icq":false,"remote_addr":"1.2.3.4","remote_port":1234,"queued_messages":0,"dropped_messages":0,"coalesced_messages":0}]}`,
 			statusCode:        http.StatusOK,
 			timeSinceFunc:     func(t time.Time) time.Duration { t0 := time.Now(); return t0.Sub(t0) },
 			mockParams: mockParams{
@@ -1221,8 +1223,16 @@ func TestUserHandler_POST(t *testing.T) {
 			userManager := newMockUserManager(t)
 			for _, params := range tc.mockParams.userManagerParams.insertUserParams {
 				assert.NoError(t, params.u.HashPassword(tc.password))
+				want := params.u
 				userManager.EXPECT().
-					InsertUser(matchContext(), params.u).
+					InsertUser(matchContext(), mock.MatchedBy(func(have state.User) bool {
+						// the bcrypt hash is salted, so verify it separately
+						if !have.ValidatePlaintextPass([]byte(tc.password)) {
+							return false
+						}
+						have.PasswordHash = want.PasswordHash
+						return assert.ObjectsAreEqual(want, have)
+					})).
 					Return(params.err)
 			}
 
@@ -1436,6 +1446,1154 @@ func TestUserPasswordHandler_PUT(t *testing.T) {
 	}
 }
 
+func TestUserTOTPHandler_POST(t *testing.T) {
+	tt := []struct {
+		name              string
+		requestScreenName state.IdentScreenName
+		want              string
+		statusCode        int
+		wantBefore        any
+		wantAfter         any
+		mockParams        mockParams
+	}{
+		{
+			name:              "enroll user in two-factor auth",
+			requestScreenName: state.NewIdentScreenName("userA"),
+			want:              `{"secret":"JBSWY3DPEHPK3PXP","uri":"otpauth://totp/Retro%20AIM%20Server:userA?issuer=Retro+AIM+Server\u0026secret=JBSWY3DPEHPK3PXP","recovery_codes":["aaaaaaaaaa","bbbbbbbbbb"]}`,
+			statusCode:        http.StatusCreated,
+			wantBefore:        totpStatus{Enabled: false},
+			wantAfter:         totpStatus{Enabled: true},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+							result: &state.User{
+								IdentScreenName:   state.NewIdentScreenName("userA"),
+								DisplayScreenName: "userA",
+							},
+						},
+					},
+					setTOTPParams: setTOTPParams{
+						{
+							screenName:    state.NewIdentScreenName("userA"),
+							secret:        "JBSWY3DPEHPK3PXP",
+							recoveryCodes: []string{"aaaaaaaaaa", "bbbbbbbbbb"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:              "user already enrolled",
+			requestScreenName: state.NewIdentScreenName("userA"),
+			want:              `two-factor auth is already enabled`,
+			statusCode:        http.StatusConflict,
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+							result: &state.User{
+								IdentScreenName:   state.NewIdentScreenName("userA"),
+								DisplayScreenName: "userA",
+								TOTPSecret:        "JBSWY3DPEHPK3PXP",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:              "user not found",
+			requestScreenName: state.NewIdentScreenName("userA"),
+			want:              `user not found`,
+			statusCode:        http.StatusNotFound,
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+							result:     nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:              "error storing secret",
+			requestScreenName: state.NewIdentScreenName("userA"),
+			want:              `internal server error`,
+			statusCode:        http.StatusInternalServerError,
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+							result: &state.User{
+								IdentScreenName:   state.NewIdentScreenName("userA"),
+								DisplayScreenName: "userA",
+							},
+						},
+					},
+					setTOTPParams: setTOTPParams{
+						{
+							screenName:    state.NewIdentScreenName("userA"),
+							secret:        "JBSWY3DPEHPK3PXP",
+							recoveryCodes: []string{"aaaaaaaaaa", "bbbbbbbbbb"},
+							err:           io.EOF,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/user/"+tc.requestScreenName.String()+"/totp", nil)
+			request.SetPathValue("screenname", tc.requestScreenName.String())
+			request, rec := withAuditRecord(request)
+			responseRecorder := httptest.NewRecorder()
+
+			userManager := newMockUserManager(t)
+			for _, params := range tc.mockParams.userManagerParams.getUserParams {
+				userManager.EXPECT().
+					User(matchContext(), params.screenName).
+					Return(params.result, params.err)
+			}
+			for _, params := range tc.mockParams.userManagerParams.setTOTPParams {
+				userManager.EXPECT().
+					SetTOTP(matchContext(), params.screenName, params.secret, params.recoveryCodes).
+					Return(params.err)
+			}
+
+			newSecret := func() (string, error) {
+				return "JBSWY3DPEHPK3PXP", nil
+			}
+			newRecoveryCodes := func() ([]string, error) {
+				return []string{"aaaaaaaaaa", "bbbbbbbbbb"}, nil
+			}
+
+			postUserTOTPHandler(responseRecorder, request, userManager, newSecret, newRecoveryCodes, slog.Default())
+
+			if responseRecorder.Code != tc.statusCode {
+				t.Errorf("Want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
+			}
+
+			if strings.TrimSpace(responseRecorder.Body.String()) != tc.want {
+				t.Errorf("Want '%s', got '%s'", tc.want, responseRecorder.Body)
+			}
+			// the secret and recovery codes must stay out of the audit log
+			assert.Equal(t, tc.wantBefore, rec.before)
+			assert.Equal(t, tc.wantAfter, rec.after)
+		})
+	}
+}
+
+func TestUserTOTPHandler_DELETE(t *testing.T) {
+	tt := []struct {
+		name              string
+		requestScreenName state.IdentScreenName
+		statusCode        int
+		wantBefore        any
+		mockParams        mockParams
+	}{
+		{
+			name:              "disable two-factor auth",
+			requestScreenName: state.NewIdentScreenName("userA"),
+			statusCode:        http.StatusNoContent,
+			wantBefore:        totpStatus{Enabled: true},
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+							result: &state.User{
+								IdentScreenName: state.NewIdentScreenName("userA"),
+								TOTPSecret:      "JBSWY3DPEHPK3PXP",
+							},
+						},
+					},
+					clearTOTPParams: clearTOTPParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+						},
+					},
+				},
+			},
+		},
+		{
+			name:              "user not found",
+			requestScreenName: state.NewIdentScreenName("userA"),
+			statusCode:        http.StatusNotFound,
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+							result:     nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:              "user deleted concurrently",
+			requestScreenName: state.NewIdentScreenName("userA"),
+			statusCode:        http.StatusNotFound,
+			mockParams: mockParams{
+				userManagerParams: userManagerParams{
+					getUserParams: getUserParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+							result: &state.User{
+								IdentScreenName: state.NewIdentScreenName("userA"),
+							},
+						},
+					},
+					clearTOTPParams: clearTOTPParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+							err:        state.ErrNoUser,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodDelete, "/user/"+tc.requestScreenName.String()+"/totp", nil)
+			request.SetPathValue("screenname", tc.requestScreenName.String())
+			request, rec := withAuditRecord(request)
+			responseRecorder := httptest.NewRecorder()
+
+			userManager := newMockUserManager(t)
+			for _, params := range tc.mockParams.userManagerParams.getUserParams {
+				userManager.EXPECT().
+					User(matchContext(), params.screenName).
+					Return(params.result, params.err)
+			}
+			for _, params := range tc.mockParams.userManagerParams.clearTOTPParams {
+				userManager.EXPECT().
+					ClearTOTP(matchContext(), params.screenName).
+					Return(params.err)
+			}
+
+			deleteUserTOTPHandler(responseRecorder, request, userManager, slog.Default())
+
+			if responseRecorder.Code != tc.statusCode {
+				t.Errorf("Want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
+			}
+			assert.Equal(t, tc.wantBefore, rec.before)
+		})
+	}
+}
+
+func TestUserLockoutHandler_GET(t *testing.T) {
+	tt := []struct {
+		name       string
+		want       string
+		statusCode int
+		mockParams mockParams
+	}{
+		{
+			name:       "no locked out accounts",
+			want:       `[]`,
+			statusCode: http.StatusOK,
+			mockParams: mockParams{
+				loginLockoutManagerParams: loginLockoutManagerParams{
+					lockoutsParams: lockoutsParams{
+						{
+							result: []state.LoginLockout{},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:       "2 locked out accounts",
+			want:       `[{"screen_name":"usera","failed_attempts":5,"locked_until":"2025-01-01T12:15:00Z"},{"screen_name":"userb","failed_attempts":7,"locked_until":"2025-01-01T12:30:00Z"}]`,
+			statusCode: http.StatusOK,
+			mockParams: mockParams{
+				loginLockoutManagerParams: loginLockoutManagerParams{
+					lockoutsParams: lockoutsParams{
+						{
+							result: []state.LoginLockout{
+								{
+									ScreenName:     state.NewIdentScreenName("userA"),
+									FailedAttempts: 5,
+									LockedUntil:    time.Date(2025, 1, 1, 12, 15, 0, 0, time.UTC),
+								},
+								{
+									ScreenName:     state.NewIdentScreenName("userB"),
+									FailedAttempts: 7,
+									LockedUntil:    time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/user/lockout", nil)
+			responseRecorder := httptest.NewRecorder()
+
+			loginLockoutManager := newMockLoginLockoutManager(t)
+			for _, params := range tc.mockParams.loginLockoutManagerParams.lockoutsParams {
+				loginLockoutManager.EXPECT().
+					Lockouts().
+					Return(params.result)
+			}
+
+			getUserLockoutHandler(responseRecorder, request, loginLockoutManager, slog.Default())
+
+			if responseRecorder.Code != tc.statusCode {
+				t.Errorf("Want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
+			}
+
+			if strings.TrimSpace(responseRecorder.Body.String()) != tc.want {
+				t.Errorf("Want '%s', got '%s'", tc.want, responseRecorder.Body)
+			}
+		})
+	}
+}
+
+func TestUserLockoutHandler_DELETE(t *testing.T) {
+	lockedUntil := time.Date(2025, 1, 1, 12, 15, 0, 0, time.UTC)
+
+	tt := []struct {
+		name              string
+		requestScreenName state.IdentScreenName
+		statusCode        int
+		wantBefore        any
+		mockParams        mockParams
+	}{
+		{
+			name:              "clear a locked out account",
+			requestScreenName: state.NewIdentScreenName("userA"),
+			statusCode:        http.StatusNoContent,
+			wantBefore: loginLockoutHandle{
+				ScreenName:     "usera",
+				FailedAttempts: 5,
+				LockedUntil:    lockedUntil,
+			},
+			mockParams: mockParams{
+				loginLockoutManagerParams: loginLockoutManagerParams{
+					clearLockoutParams: clearLockoutParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+							lockout: state.LoginLockout{
+								ScreenName:     state.NewIdentScreenName("userA"),
+								FailedAttempts: 5,
+								LockedUntil:    lockedUntil,
+							},
+							result: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:              "clear an account with no failed login attempts",
+			requestScreenName: state.NewIdentScreenName("userA"),
+			statusCode:        http.StatusNotFound,
+			mockParams: mockParams{
+				loginLockoutManagerParams: loginLockoutManagerParams{
+					clearLockoutParams: clearLockoutParams{
+						{
+							screenName: state.NewIdentScreenName("userA"),
+							result:     false,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodDelete, "/user/"+tc.requestScreenName.String()+"/lockout", nil)
+			request.SetPathValue("screenname", tc.requestScreenName.String())
+			request, rec := withAuditRecord(request)
+			responseRecorder := httptest.NewRecorder()
+
+			loginLockoutManager := newMockLoginLockoutManager(t)
+			for _, params := range tc.mockParams.loginLockoutManagerParams.clearLockoutParams {
+				loginLockoutManager.EXPECT().
+					ClearLockout(params.screenName).
+					Return(params.lockout, params.result)
+			}
+
+			deleteUserLockoutHandler(responseRecorder, request, loginLockoutManager)
+
+			if responseRecorder.Code != tc.statusCode {
+				t.Errorf("Want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
+			}
+			assert.Equal(t, tc.wantBefore, rec.before)
+		})
+	}
+}
+
+func TestUserLoginHandler_GET(t *testing.T) {
+	user := &state.User{IdentScreenName: state.NewIdentScreenName("userA")}
+
+	tt := []struct {
+		name          string
+		username      string
+		password      string
+		locked        bool
+		authResult    *state.User
+		authErr       error
+		expectFailure bool
+		expectSuccess bool
+		want          string
+		statusCode    int
+	}{
+		{
+			name:          "valid credentials",
+			username:      "userA",
+			password:      "thepassword",
+			authResult:    user,
+			expectSuccess: true,
+			want:          "200 OK: Successfully Authenticated",
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "wrong password",
+			username:      "userA",
+			password:      "wrongpassword",
+			authErr:       state.ErrBadCredentials,
+			expectFailure: true,
+			want:          "401 Unauthorized: Invalid Credentials",
+			statusCode:    http.StatusUnauthorized,
+		},
+		{
+			name:          "wrong one-time code for two-factor account",
+			username:      "userA",
+			password:      "thepassword123456",
+			authErr:       state.ErrBadSecurID,
+			expectFailure: true,
+			want:          "401 Unauthorized: Invalid Credentials",
+			statusCode:    http.StatusUnauthorized,
+		},
+		{
+			name:       "suspended account",
+			username:   "userA",
+			password:   "thepassword",
+			authErr:    state.ErrUserSuspended,
+			want:       "401 Unauthorized: Invalid Credentials",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "user not found",
+			username:      "userB",
+			password:      "thepassword",
+			authErr:       state.ErrNoUser,
+			expectFailure: true,
+			want:          "401 Unauthorized: Invalid Credentials",
+			statusCode:    http.StatusUnauthorized,
+		},
+		{
+			name:       "locked out account is rejected without checking the password",
+			username:   "userA",
+			password:   "thepassword",
+			locked:     true,
+			want:       "401 Unauthorized: Invalid Credentials",
+			statusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/user/login", nil)
+			request.SetBasicAuth(tc.username, tc.password)
+			responseRecorder := httptest.NewRecorder()
+
+			screenName := state.NewIdentScreenName(tc.username)
+			loginLockoutManager := newMockLoginLockoutManager(t)
+			loginLockoutManager.EXPECT().
+				IsLocked(screenName).
+				Return(tc.locked)
+			if tc.expectFailure {
+				loginLockoutManager.EXPECT().
+					RecordFailure(screenName)
+			}
+			if tc.expectSuccess {
+				loginLockoutManager.EXPECT().
+					RecordSuccess(screenName)
+			}
+			userManager := newMockUserManager(t)
+			if !tc.locked {
+				userManager.EXPECT().
+					AuthenticateUser(matchContext(), nil, tc.username, tc.password, "").
+					Return(tc.authResult, tc.authErr)
+			}
+
+			getUserLoginHandler(responseRecorder, request, userManager, state.NewAuthMode(false, nil), loginLockoutManager, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestUserPasswordResetHandler_POST(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	emailAddress := &mail.Address{Address: "usera@example.com"}
+
+	tt := []struct {
+		name       string
+		noMailer   bool
+		user       *state.User
+		email      *mail.Address
+		emailErr   error
+		sendErr    error
+		want       string
+		statusCode int
+	}{
+		{
+			name:       "send password reset email",
+			user:       &state.User{DisplayScreenName: "UserA"},
+			email:      emailAddress,
+			want:       "Password reset email sent.",
+			statusCode: http.StatusAccepted,
+		},
+		{
+			name:       "email delivery is not configured",
+			noMailer:   true,
+			want:       "email delivery is not configured",
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			name:       "user doesn't exist",
+			want:       "user not found",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "user has no email address",
+			user:       &state.User{DisplayScreenName: "UserA"},
+			emailErr:   state.ErrNoEmailAddress,
+			want:       "user has no email address",
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "mailer fails to send",
+			user:       &state.User{DisplayScreenName: "UserA"},
+			email:      emailAddress,
+			sendErr:    io.EOF,
+			want:       "error sending email",
+			statusCode: http.StatusBadGateway,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/user/usera/password-reset", nil)
+			request.SetPathValue("screenname", "usera")
+			responseRecorder := httptest.NewRecorder()
+
+			sn := state.NewIdentScreenName("usera")
+			userManager := newMockUserManager(t)
+			accountManager := newMockAccountManager(t)
+			emailTokenManager := newMockEmailTokenManager(t)
+			mailSender := newMockMailer(t)
+
+			if !tc.noMailer {
+				userManager.EXPECT().
+					User(matchContext(), sn).
+					Return(tc.user, nil)
+			}
+			if tc.user != nil {
+				accountManager.EXPECT().
+					EmailAddress(matchContext(), sn).
+					Return(tc.email, tc.emailErr)
+			}
+			if tc.email != nil {
+				emailTokenManager.EXPECT().
+					IssueEmailToken(matchContext(), sn, state.EmailTokenPasswordReset, now.Add(state.PasswordResetTokenTTL)).
+					Return("the-token", nil)
+				mailSender.EXPECT().
+					Send(matchContext(), mailer.NewPasswordResetMessage(emailAddress, "UserA",
+						"https://aim.example.com/account/password-reset?token=the-token", state.PasswordResetTokenTTL)).
+					Return(tc.sendErr)
+			}
+
+			var m Mailer = mailSender
+			if tc.noMailer {
+				m = nil
+			}
+			timeNow := func() time.Time {
+				return now
+			}
+			postUserPasswordResetHandler(responseRecorder, request, userManager, accountManager, emailTokenManager, m, "https://aim.example.com", timeNow, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestAccountConfirmHandler_GET(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name       string
+		redeemErr  error
+		online     bool
+		want       string
+		statusCode int
+	}{
+		{
+			name:       "confirm account of offline user",
+			want:       "Your account is confirmed.",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "confirm account of online user",
+			online:     true,
+			want:       "Your account is confirmed.",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "invalid token",
+			redeemErr:  state.ErrInvalidEmailToken,
+			want:       state.ErrInvalidEmailToken.Error(),
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/account/confirm?token=the-token", nil)
+			responseRecorder := httptest.NewRecorder()
+
+			sn := state.NewIdentScreenName("usera")
+			accountManager := newMockAccountManager(t)
+			emailTokenManager := newMockEmailTokenManager(t)
+			sessionRetriever := newMockSessionRetriever(t)
+
+			emailTokenManager.EXPECT().
+				RedeemEmailToken(matchContext(), "the-token", state.EmailTokenConfirm, now).
+				Return(sn, tc.redeemErr)
+
+			var sess *state.Session
+			if tc.online {
+				sess = state.NewSession()
+				sess.SetUserInfoFlag(wire.OServiceUserFlagUnconfirmed)
+			}
+			if tc.redeemErr == nil {
+				accountManager.EXPECT().
+					UpdateConfirmStatus(matchContext(), sn, true).
+					Return(nil)
+				sessionRetriever.EXPECT().
+					RetrieveSession(sn).
+					Return(sess)
+			}
+
+			timeNow := func() time.Time {
+				return now
+			}
+			getAccountConfirmHandler(responseRecorder, request, accountManager, emailTokenManager, sessionRetriever, timeNow, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+			if sess != nil {
+				assert.Zero(t, sess.UserInfoBitmask()&wire.OServiceUserFlagUnconfirmed)
+			}
+		})
+	}
+}
+
+func TestAccountPasswordResetHandler_GET(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, `/account/password-reset?token="><script>`, nil)
+	responseRecorder := httptest.NewRecorder()
+
+	getAccountPasswordResetHandler(responseRecorder, request)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, "text/html; charset=utf-8", responseRecorder.Header().Get("Content-Type"))
+	assert.Contains(t, responseRecorder.Body.String(), `name="token" value="&#34;&gt;&lt;script&gt;"`)
+}
+
+func TestAccountPasswordResetHandler_POST(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	emailAddress := &mail.Address{Address: "usera@example.com"}
+	sn := state.NewIdentScreenName("usera")
+
+	tt := []struct {
+		name         string
+		contentType  string
+		body         string
+		lookupErr    error
+		user         *state.User
+		expectRedeem bool
+		redeemErr    error
+		expectSet    bool
+		want         string
+		statusCode   int
+	}{
+		{
+			name:         "reset password with JSON body",
+			contentType:  "application/json",
+			body:         `{"token":"the-token","password":"thenewpassword"}`,
+			user:         &state.User{IdentScreenName: sn},
+			expectRedeem: true,
+			expectSet:    true,
+			statusCode:   http.StatusNoContent,
+		},
+		{
+			name:         "reset password with form body",
+			contentType:  "application/x-www-form-urlencoded",
+			body:         `token=the-token&password=thenewpassword`,
+			user:         &state.User{IdentScreenName: sn},
+			expectRedeem: true,
+			expectSet:    true,
+			statusCode:   http.StatusNoContent,
+		},
+		{
+			name:        "malformed body",
+			contentType: "application/json",
+			body:        `{"token":"the-token"`,
+			want:        "malformed input",
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "invalid token",
+			contentType: "application/json",
+			body:        `{"token":"the-token","password":"thenewpassword"}`,
+			lookupErr:   state.ErrInvalidEmailToken,
+			want:        state.ErrInvalidEmailToken.Error(),
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "user no longer exists",
+			contentType: "application/json",
+			body:        `{"token":"the-token","password":"thenewpassword"}`,
+			want:        "user does not exist",
+			statusCode:  http.StatusNotFound,
+		},
+		{
+			name:        "invalid password doesn't spend the token",
+			contentType: "application/json",
+			body:        `{"token":"the-token","password":"pw"}`,
+			user:        &state.User{IdentScreenName: sn},
+			want:        "invalid password length: password length must be between 4-16 characters",
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:         "token spent by a concurrent request",
+			contentType:  "application/json",
+			body:         `{"token":"the-token","password":"thenewpassword"}`,
+			user:         &state.User{IdentScreenName: sn},
+			expectRedeem: true,
+			redeemErr:    state.ErrInvalidEmailToken,
+			want:         state.ErrInvalidEmailToken.Error(),
+			statusCode:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/account/password-reset", strings.NewReader(tc.body))
+			request.Header.Set("Content-Type", tc.contentType)
+			responseRecorder := httptest.NewRecorder()
+
+			userManager := newMockUserManager(t)
+			accountManager := newMockAccountManager(t)
+			emailTokenManager := newMockEmailTokenManager(t)
+			mailSender := newMockMailer(t)
+
+			if tc.want != "malformed input" {
+				emailTokenManager.EXPECT().
+					LookupEmailToken(matchContext(), "the-token", state.EmailTokenPasswordReset, now).
+					Return(sn, tc.lookupErr)
+			}
+			if tc.lookupErr == nil && tc.want != "malformed input" {
+				userManager.EXPECT().
+					User(matchContext(), sn).
+					Return(tc.user, nil)
+			}
+			if tc.expectRedeem {
+				emailTokenManager.EXPECT().
+					RedeemEmailToken(matchContext(), "the-token", state.EmailTokenPasswordReset, now).
+					Return(sn, tc.redeemErr)
+			}
+			if tc.expectSet {
+				userManager.EXPECT().
+					SetUserPassword(matchContext(), sn, "thenewpassword").
+					Return(nil)
+				accountManager.EXPECT().
+					EmailAddress(matchContext(), sn).
+					Return(emailAddress, nil)
+				mailSender.EXPECT().
+					Send(matchContext(), mailer.NewPasswordChangedMessage(emailAddress, "usera")).
+					Return(nil)
+			}
+
+			timeNow := func() time.Time {
+				return now
+			}
+			postAccountPasswordResetHandler(responseRecorder, request, userManager, accountManager, emailTokenManager, mailSender, timeNow, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestAccountForgotPasswordHandler_POST(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	emailAddress := &mail.Address{Address: "usera@example.com"}
+	user := state.User{
+		IdentScreenName:   state.NewIdentScreenName("usera"),
+		DisplayScreenName: "UserA",
+	}
+	link := "https://aim.example.com/account/password-reset?token=the-token"
+	resetMsg := mailer.NewPasswordResetMessage(emailAddress, "UserA", link, state.PasswordResetTokenTTL)
+
+	tt := []struct {
+		name           string
+		contentType    string
+		body           string
+		noMailer       bool
+		clientLimited  bool
+		accountLimited bool
+		byEmail        bool
+		user           *state.User
+		emailErr       error
+		sendErr        error
+		expectSend     bool
+		want           string
+		statusCode     int
+	}{
+		{
+			name:        "send reset email by screen name",
+			contentType: "application/json",
+			body:        `{"account":"UserA"}`,
+			user:        &user,
+			expectSend:  true,
+			want:        forgotPasswordResponse,
+			statusCode:  http.StatusAccepted,
+		},
+		{
+			name:        "send reset email by email address with form body",
+			contentType: "application/x-www-form-urlencoded",
+			body:        `account=usera%40example.com`,
+			byEmail:     true,
+			user:        &user,
+			expectSend:  true,
+			want:        forgotPasswordResponse,
+			statusCode:  http.StatusAccepted,
+		},
+		{
+			name:        "unknown screen name",
+			contentType: "application/json",
+			body:        `{"account":"UserA"}`,
+			want:        forgotPasswordResponse,
+			statusCode:  http.StatusAccepted,
+		},
+		{
+			name:        "unknown email address",
+			contentType: "application/json",
+			body:        `{"account":"usera@example.com"}`,
+			byEmail:     true,
+			want:        forgotPasswordResponse,
+			statusCode:  http.StatusAccepted,
+		},
+		{
+			name:        "account has no email address",
+			contentType: "application/json",
+			body:        `{"account":"UserA"}`,
+			user:        &user,
+			emailErr:    state.ErrNoEmailAddress,
+			want:        forgotPasswordResponse,
+			statusCode:  http.StatusAccepted,
+		},
+		{
+			name:        "email delivery fails",
+			contentType: "application/json",
+			body:        `{"account":"UserA"}`,
+			user:        &user,
+			expectSend:  true,
+			sendErr:     io.EOF,
+			want:        forgotPasswordResponse,
+			statusCode:  http.StatusAccepted,
+		},
+		{
+			name:           "account rate limited",
+			contentType:    "application/json",
+			body:           `{"account":"UserA"}`,
+			accountLimited: true,
+			user:           &user,
+			want:           forgotPasswordResponse,
+			statusCode:     http.StatusAccepted,
+		},
+		{
+			name:          "client rate limited",
+			contentType:   "application/json",
+			body:          `{"account":"UserA"}`,
+			clientLimited: true,
+			want:          "too many requests, try again later",
+			statusCode:    http.StatusTooManyRequests,
+		},
+		{
+			name:        "missing account",
+			contentType: "application/json",
+			body:        `{"account":" "}`,
+			want:        "account is required",
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "malformed body",
+			contentType: "application/json",
+			body:        `{"account":`,
+			want:        "malformed input",
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "email delivery not configured",
+			contentType: "application/json",
+			body:        `{"account":"UserA"}`,
+			noMailer:    true,
+			want:        "email delivery is not configured",
+			statusCode:  http.StatusNotImplemented,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/account/forgot-password", strings.NewReader(tc.body))
+			request.Header.Set("Content-Type", tc.contentType)
+			responseRecorder := httptest.NewRecorder()
+
+			userManager := newMockUserManager(t)
+			accountManager := newMockAccountManager(t)
+			emailTokenManager := newMockEmailTokenManager(t)
+			mailSender := newMockMailer(t)
+
+			clientLimiter := newKeyRateLimiter(rate.Every(time.Hour), 1, time.Hour)
+			if tc.clientLimited {
+				clientLimiter.Allow(clientIP(request))
+			}
+			accountLimiter := newKeyRateLimiter(rate.Every(time.Hour), 1, time.Hour)
+			if tc.accountLimited {
+				accountLimiter.Allow("usera")
+			}
+
+			lookup := tc.statusCode == http.StatusAccepted
+			if lookup && tc.byEmail {
+				found := state.User{}
+				var err error
+				if tc.user != nil {
+					found = *tc.user
+				} else {
+					err = state.ErrNoUser
+				}
+				userManager.EXPECT().
+					FindByAIMEmail(matchContext(), "usera@example.com").
+					Return(found, err)
+			} else if lookup {
+				userManager.EXPECT().
+					User(matchContext(), state.NewIdentScreenName("UserA")).
+					Return(tc.user, nil)
+			}
+			if lookup && tc.user != nil && !tc.accountLimited {
+				accountManager.EXPECT().
+					EmailAddress(matchContext(), user.IdentScreenName).
+					Return(emailAddress, tc.emailErr)
+			}
+			if tc.expectSend {
+				emailTokenManager.EXPECT().
+					IssueEmailToken(matchContext(), user.IdentScreenName, state.EmailTokenPasswordReset, now.Add(state.PasswordResetTokenTTL)).
+					Return("the-token", nil)
+				mailSender.EXPECT().
+					Send(matchContext(), resetMsg).
+					Return(tc.sendErr)
+			}
+
+			var sender Mailer = mailSender
+			if tc.noMailer {
+				sender = nil
+			}
+			timeNow := func() time.Time {
+				return now
+			}
+			postAccountForgotPasswordHandler(responseRecorder, request, userManager, accountManager, emailTokenManager, sender, "https://aim.example.com", clientLimiter, accountLimiter, timeNow, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestInviteHandler_GET(t *testing.T) {
+	tt := []struct {
+		name         string
+		query        string
+		status       state.InviteStatus
+		expectLookup bool
+		invites      []state.Invite
+		err          error
+		want         string
+		statusCode   int
+	}{
+		{
+			name:         "list all invites",
+			expectLookup: true,
+			invites: []state.Invite{
+				{
+					ID:        1,
+					Sender:    state.NewIdentScreenName("userA"),
+					Email:     "friend1@example.com",
+					Message:   "join me",
+					CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+				},
+				{
+					ID:         2,
+					Sender:     state.NewIdentScreenName("userA"),
+					Email:      "friend2@example.com",
+					CreatedAt:  time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC),
+					AcceptedBy: "Friend2",
+				},
+			},
+			want:       `[{"id":1,"sender":"usera","email":"friend1@example.com","message":"join me","created_at":"2025-01-01T12:00:00Z","status":"pending"},{"id":2,"sender":"usera","email":"friend2@example.com","message":"","created_at":"2025-01-02T12:00:00Z","status":"accepted","accepted_by":"Friend2"}]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:         "list pending invites",
+			query:        "?status=pending",
+			status:       state.InviteStatusPending,
+			expectLookup: true,
+			want:         `[]`,
+			statusCode:   http.StatusOK,
+		},
+		{
+			name:       "invalid status",
+			query:      "?status=expired",
+			want:       `{"message":"invalid status. valid values: pending, accepted"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:         "runtime error",
+			expectLookup: true,
+			err:          io.EOF,
+			want:         `{"message":"internal server error"}`,
+			statusCode:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/invite"+tc.query, nil)
+			responseRecorder := httptest.NewRecorder()
+
+			inviteManager := newMockInviteManager(t)
+			if tc.expectLookup {
+				inviteManager.EXPECT().
+					Invites(matchContext(), tc.status).
+					Return(tc.invites, tc.err)
+			}
+
+			getInviteHandler(responseRecorder, request, inviteManager, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestInviteHandler_DELETE(t *testing.T) {
+	invite := state.Invite{
+		ID:        1,
+		Sender:    state.NewIdentScreenName("userA"),
+		Email:     "friend1@example.com",
+		Message:   "join me",
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tt := []struct {
+		name         string
+		id           string
+		invites      []state.Invite
+		expectLookup bool
+		expectDelete bool
+		err          error
+		statusCode   int
+		wantBefore   any
+	}{
+		{
+			name:         "delete invite",
+			id:           "1",
+			invites:      []state.Invite{invite},
+			expectLookup: true,
+			expectDelete: true,
+			statusCode:   http.StatusNoContent,
+			wantBefore: inviteHandle{
+				ID:        1,
+				Sender:    "usera",
+				Email:     "friend1@example.com",
+				Message:   "join me",
+				CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+				Status:    "pending",
+			},
+		},
+		{
+			name:         "invite not found",
+			id:           "2",
+			invites:      []state.Invite{invite},
+			expectLookup: true,
+			statusCode:   http.StatusNotFound,
+		},
+		{
+			name:         "invite deleted concurrently",
+			id:           "1",
+			invites:      []state.Invite{invite},
+			expectLookup: true,
+			expectDelete: true,
+			err:          state.ErrNoInvite,
+			statusCode:   http.StatusNotFound,
+		},
+		{
+			name:       "invalid invite ID",
+			id:         "abc",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodDelete, "/invite/"+tc.id, nil)
+			request.SetPathValue("id", tc.id)
+			request, rec := withAuditRecord(request)
+			responseRecorder := httptest.NewRecorder()
+
+			inviteManager := newMockInviteManager(t)
+			if tc.expectLookup {
+				inviteManager.EXPECT().
+					Invites(matchContext(), state.InviteStatus("")).
+					Return(tc.invites, nil)
+			}
+			if tc.expectDelete {
+				inviteManager.EXPECT().
+					DeleteInvite(matchContext(), int64(1)).
+					Return(tc.err)
+			}
+
+			deleteInviteHandler(responseRecorder, request, inviteManager, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.wantBefore, rec.before)
+		})
+	}
+}
+
 func TestPublicChatHandler_GET(t *testing.T) {
 	fnNewSess := func(screenName string) *state.Session {
 		sess := state.NewSession()
@@ -1444,8 +2602,231 @@ func TestPublicChatHandler_GET(t *testing.T) {
 		return sess
 	}
 
-	chatRoom1 := state.NewChatRoom("chat-room-1-name", state.NewIdentScreenName("chat-room-1-creator"), state.PublicExchange)
-	chatRoom2 := state.NewChatRoom("chat-room-2-name", state.NewIdentScreenName("chat-room-1-creator"), state.PublicExchange)
+	chatRoom1 := state.NewChatRoom("chat-room-1-name", state.NewIdentScreenName("chat-room-1-creator"), state.PublicExchange)
+	chatRoom2 := state.NewChatRoom("chat-room-2-name", state.NewIdentScreenName("chat-room-1-creator"), state.PublicExchange)
+
+	tt := []struct {
+		name       string
+		want       string
+		statusCode int
+		mockParams mockParams
+	}{
+		{
+			name:       "multiple chat rooms with participants",
+			want:       `[{"name":"chat-room-1-name","create_time":"0001-01-01T00:00:00Z","url":"aim:gochat?roomname=chat-room-1-name&exchange=5","participants":[{"id":"usera","screen_name":"userA"},{"id":"userb","screen_name":"userB"}]},{"name":"chat-room-2-name","create_time":"0001-01-01T00:00:00Z","url":"aim:gochat?roomname=chat-room-2-name&exchange=5","participants":[{"id":"userc","screen_name":"userC"},{"id":"userd","screen_name":"userD"}]}]`,
+			statusCode: http.StatusOK,
+			mockParams: mockParams{
+				chatRoomRetrieverParams: chatRoomRetrieverParams{
+					allChatRoomsParams: allChatRoomsParams{
+						{
+							exchange: state.PublicExchange,
+							result: []state.ChatRoom{
+								chatRoom1,
+								chatRoom2,
+							},
+						},
+					},
+				},
+				chatSessionRetrieverParams: chatSessionRetrieverParams{
+					chatSessionRetrieverAllSessionsParams: chatSessionRetrieverAllSessionsParams{
+						{
+							cookie: chatRoom1.Cookie(),
+							result: []*state.Session{
+								fnNewSess("userA"),
+								fnNewSess("userB"),
+							},
+						},
+						{
+							cookie: chatRoom2.Cookie(),
+							result: []*state.Session{
+								fnNewSess("userC"),
+								fnNewSess("userD"),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:       "chat room without participants",
+			want:       `[{"name":"chat-room-1-name","create_time":"0001-01-01T00:00:00Z","url":"aim:gochat?roomname=chat-room-1-name&exchange=5","participants":[]}]`,
+			statusCode: http.StatusOK,
+			mockParams: mockParams{
+				chatRoomRetrieverParams: chatRoomRetrieverParams{
+					allChatRoomsParams: allChatRoomsParams{
+						{
+							exchange: state.PublicExchange,
+							result: []state.ChatRoom{
+								chatRoom1,
+							},
+						},
+					},
+				},
+				chatSessionRetrieverParams: chatSessionRetrieverParams{
+					chatSessionRetrieverAllSessionsParams: chatSessionRetrieverAllSessionsParams{
+						{
+							cookie: chatRoom1.Cookie(),
+							result: []*state.Session{},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:       "no chat rooms",
+			want:       `[]`,
+			statusCode: http.StatusOK,
+			mockParams: mockParams{
+				chatRoomRetrieverParams: chatRoomRetrieverParams{
+					allChatRoomsParams: allChatRoomsParams{
+						{
+							exchange: state.PublicExchange,
+							result:   []state.ChatRoom{},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/chat/room/public", nil)
+			responseRecorder := httptest.NewRecorder()
+
+			chatRoomRetriever := newMockChatRoomRetriever(t)
+			for _, params := range tc.mockParams.chatRoomRetrieverParams.allChatRoomsParams {
+				chatRoomRetriever.EXPECT().
+					AllChatRooms(matchContext(), params.exchange).
+					Return(params.result, params.err)
+			}
+
+			chatSessionRetriever := newMockChatSessionRetriever(t)
+			for _, params := range tc.mockParams.chatSessionRetrieverParams.chatSessionRetrieverAllSessionsParams {
+				chatSessionRetriever.EXPECT().
+					AllSessions(params.cookie).
+					Return(params.result)
+			}
+
+			getPublicChatHandler(responseRecorder, request, chatRoomRetriever, chatSessionRetriever, slog.Default())
+
+			if responseRecorder.Code != tc.statusCode {
+				t.Errorf("Want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
+			}
+
+			if strings.TrimSpace(responseRecorder.Body.String()) != tc.want {
+				t.Errorf("Want '%s', got '%s'", tc.want, responseRecorder.Body)
+			}
+		})
+	}
+}
+
+func TestDeletePublicChatHandler(t *testing.T) {
+	tt := []struct {
+		name       string
+		body       string
+		want       string
+		statusCode int
+		mockParams mockParams
+	}{
+		{
+			name:       "successful deletion of single chat room",
+			body:       `{"names":["TestRoom"]}`,
+			want:       `Chat rooms deleted successfully.`,
+			statusCode: http.StatusNoContent,
+			mockParams: mockParams{
+				chatRoomDeleterParams: chatRoomDeleterParams{
+					deleteChatRoomsParams: deleteChatRoomsParams{
+						{
+							exchange: state.PublicExchange,
+							names:    []string{"TestRoom"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:       "successful deletion of multiple chat rooms",
+			body:       `{"names":["Room1", "Room2", "Room3"]}`,
+			want:       `Chat rooms deleted successfully.`,
+			statusCode: http.StatusNoContent,
+			mockParams: mockParams{
+				chatRoomDeleterParams: chatRoomDeleterParams{
+					deleteChatRoomsParams: deleteChatRoomsParams{
+						{
+							exchange: state.PublicExchange,
+							names:    []string{"Room1", "Room2", "Room3"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:       "empty names array",
+			body:       `{"names":[]}`,
+			want:       `no chat room names provided`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed JSON",
+			body:       `{"names":["Room1"`, // missing closing brackets
+			want:       `malformed input`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "deletion error",
+			body:       `{"names":["TestRoom"]}`,
+			want:       `internal server error`,
+			statusCode: http.StatusInternalServerError,
+			mockParams: mockParams{
+				chatRoomDeleterParams: chatRoomDeleterParams{
+					deleteChatRoomsParams: deleteChatRoomsParams{
+						{
+							exchange: state.PublicExchange,
+							names:    []string{"TestRoom"},
+							err:      errors.New("database error"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodDelete, "/chat/room/public", strings.NewReader(tc.body))
+			responseRecorder := httptest.NewRecorder()
+
+			chatRoomDeleter := newMockChatRoomDeleter(t)
+			for _, params := range tc.mockParams.chatRoomDeleterParams.deleteChatRoomsParams {
+				chatRoomDeleter.EXPECT().
+					DeleteChatRooms(matchContext(), params.exchange, params.names).
+					Return(params.err)
+			}
+
+			deletePublicChatHandler(responseRecorder, request, chatRoomDeleter, slog.Default())
+
+			if responseRecorder.Code != tc.statusCode {
+				t.Errorf("want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
+			}
+
+			if strings.TrimSpace(responseRecorder.Body.String()) != tc.want {
+				t.Errorf("want '%s', got '%s'", tc.want, responseRecorder.Body)
+			}
+		})
+	}
+}
+
+func TestPrivateChatHandler_GET(t *testing.T) {
+	fnNewSess := func(screenName string) *state.Session {
+		sess := state.NewSession()
+		sess.SetIdentScreenName(state.NewIdentScreenName(screenName))
+		sess.SetDisplayScreenName(state.DisplayScreenName(screenName))
+		return sess
+	}
+
+	chatRoom1 := state.NewChatRoom("chat-room-1-name", state.NewIdentScreenName("chat-room-1-creator"), state.PrivateExchange)
+	chatRoom2 := state.NewChatRoom("chat-room-2-name", state.NewIdentScreenName("chat-room-2-creator"), state.PrivateExchange)
 
 	tt := []struct {
 		name       string
@@ -1455,13 +2836,13 @@ func TestPublicChatHandler_GET(t *testing.T) {
 	}{
 		{
 			name:       "multiple chat rooms with participants",
-			want:       `[{"name":"chat-room-1-name","create_time":"0001-01-01T00:00:00Z","url":"aim:gochat?roomname=chat-room-1-name&exchange=5","participants":[{"id":"usera","screen_name":"userA"},{"id":"userb","screen_name":"userB"}]},{"name":"chat-room-2-name","create_time":"0001-01-01T00:00:00Z","url":"aim:gochat?roomname=chat-room-2-name&exchange=5","participants":[{"id":"userc","screen_name":"userC"},{"id":"userd","screen_name":"userD"}]}]`,
+			want:       `[{"name":"chat-room-1-name","create_time":"0001-01-01T00:00:00Z","creator_id":"chat-room-1-creator","url":"aim:gochat?roomname=chat-room-1-name&exchange=4","participants":[{"id":"usera","screen_name":"userA"},{"id":"userb","screen_name":"userB"}]},{"name":"chat-room-2-name","create_time":"0001-01-01T00:00:00Z","creator_id":"chat-room-2-creator","url":"aim:gochat?roomname=chat-room-2-name&exchange=4","participants":[{"id":"userc","screen_name":"userC"},{"id":"userd","screen_name":"userD"}]}]`,
 			statusCode: http.StatusOK,
 			mockParams: mockParams{
 				chatRoomRetrieverParams: chatRoomRetrieverParams{
 					allChatRoomsParams: allChatRoomsParams{
 						{
-							exchange: state.PublicExchange,
+							exchange: state.PrivateExchange,
 							result: []state.ChatRoom{
 								chatRoom1,
 								chatRoom2,
@@ -1491,13 +2872,13 @@ func TestPublicChatHandler_GET(t *testing.T) {
 		},
 		{
 			name:       "chat room without participants",
-			want:       `[{"name":"chat-room-1-name","create_time":"0001-01-01T00:00:00Z","url":"aim:gochat?roomname=chat-room-1-name&exchange=5","participants":[]}]`,
+			want:       `[{"name":"chat-room-1-name","create_time":"0001-01-01T00:00:00Z","creator_id":"chat-room-1-creator","url":"aim:gochat?roomname=chat-room-1-name&exchange=4","participants":[]}]`,
 			statusCode: http.StatusOK,
 			mockParams: mockParams{
 				chatRoomRetrieverParams: chatRoomRetrieverParams{
 					allChatRoomsParams: allChatRoomsParams{
 						{
-							exchange: state.PublicExchange,
+							exchange: state.PrivateExchange,
 							result: []state.ChatRoom{
 								chatRoom1,
 							},
@@ -1522,7 +2903,7 @@ func TestPublicChatHandler_GET(t *testing.T) {
 				chatRoomRetrieverParams: chatRoomRetrieverParams{
 					allChatRoomsParams: allChatRoomsParams{
 						{
-							exchange: state.PublicExchange,
+							exchange: state.PrivateExchange,
 							result:   []state.ChatRoom{},
 						},
 					},
@@ -1533,7 +2914,7 @@ func TestPublicChatHandler_GET(t *testing.T) {
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			request := httptest.NewRequest(http.MethodGet, "/chat/room/public", nil)
+			request := httptest.NewRequest(http.MethodGet, "/chat/room/private", nil)
 			responseRecorder := httptest.NewRecorder()
 
 			chatRoomRetriever := newMockChatRoomRetriever(t)
@@ -1550,7 +2931,7 @@ func TestPublicChatHandler_GET(t *testing.T) {
 					Return(params.result)
 			}
 
-			getPublicChatHandler(responseRecorder, request, chatRoomRetriever, chatSessionRetriever, slog.Default())
+			getPrivateChatHandler(responseRecorder, request, chatRoomRetriever, chatSessionRetriever, slog.Default())
 
 			if responseRecorder.Code != tc.statusCode {
 				t.Errorf("Want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
@@ -1563,297 +2944,528 @@ func TestPublicChatHandler_GET(t *testing.T) {
 	}
 }
 
-func TestDeletePublicChatHandler(t *testing.T) {
+func TestInstantMessageHandler_POST(t *testing.T) {
+	type relayToScreenNameInputs struct {
+		sender    state.IdentScreenName
+		recipient state.IdentScreenName
+		msg       string
+	}
+
 	tt := []struct {
-		name       string
-		body       string
-		want       string
-		statusCode int
-		mockParams mockParams
+		name                    string
+		relayToScreenNameInputs []relayToScreenNameInputs
+		body                    string
+		want                    string
+		statusCode              int
 	}{
 		{
-			name:       "successful deletion of single chat room",
-			body:       `{"names":["TestRoom"]}`,
-			want:       `Chat rooms deleted successfully.`,
-			statusCode: http.StatusNoContent,
-			mockParams: mockParams{
-				chatRoomDeleterParams: chatRoomDeleterParams{
-					deleteChatRoomsParams: deleteChatRoomsParams{
-						{
-							exchange: state.PublicExchange,
-							names:    []string{"TestRoom"},
-						},
+			name: "send an instant message",
+			relayToScreenNameInputs: []relayToScreenNameInputs{
+				{
+					sender:    state.NewIdentScreenName("sender_sn"),
+					recipient: state.NewIdentScreenName("recip_sn"),
+					msg:       "hello world!",
+				},
+			},
+			body:       `{"from":"sender_sn","to":"recip_sn","text":"hello world!"}`,
+			want:       `Message sent successfully.`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "with malformed body",
+			body:       `{"screen_name":"userA", "password":"thepassword"`,
+			want:       `malformed input`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(tc.body))
+			responseRecorder := httptest.NewRecorder()
+
+			messageRelayer := newMockMessageRelayer(t)
+
+			for _, params := range tc.relayToScreenNameInputs {
+				validateSNAC := func(msg wire.SNACMessage) bool {
+					body := msg.Body.(wire.SNAC_0x04_0x07_ICBMChannelMsgToClient)
+					assert.Equal(t, params.sender.String(), body.TLVUserInfo.ScreenName)
+
+					b, ok := body.Bytes(wire.ICBMTLVAOLIMData)
+					assert.True(t, ok)
+
+					txt, err := wire.UnmarshalICBMMessageText(b)
+					assert.NoError(t, err)
+					assert.Equal(t, params.msg, txt)
+					return true
+				}
+				messageRelayer.EXPECT().
+					RelayToScreenName(mock.Anything, params.recipient, mock.MatchedBy(validateSNAC))
+			}
+
+			postInstantMessageHandler(responseRecorder, request, messageRelayer, slog.Default())
+
+			if responseRecorder.Code != tc.statusCode {
+				t.Errorf("want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
+			}
+
+			if strings.TrimSpace(responseRecorder.Body.String()) != tc.want {
+				t.Errorf("want '%s', got '%s'", tc.want, responseRecorder.Body)
+			}
+		})
+	}
+}
+
+func TestPopupHandler_POST(t *testing.T) {
+	tt := []struct {
+		name             string
+		body             string
+		expectRecipients []state.IdentScreenName
+		expectEveryone   bool
+		expectBody       wire.SNAC_0x08_0x02_PopupDisplay
+		want             string
+		statusCode       int
+	}{
+		{
+			name: "display popup to users",
+			body: `{"message":"<b>Maintenance at 10pm</b>","screen_names":["UserA","userB"],"width":320,"height":240,"delay":5}`,
+			expectRecipients: []state.IdentScreenName{
+				state.NewIdentScreenName("usera"),
+				state.NewIdentScreenName("userb"),
+			},
+			expectBody: wire.SNAC_0x08_0x02_PopupDisplay{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.PopupTLVMessage, "<b>Maintenance at 10pm</b>"),
+						wire.NewTLVBE(wire.PopupTLVWidth, uint16(320)),
+						wire.NewTLVBE(wire.PopupTLVHeight, uint16(240)),
+						wire.NewTLVBE(wire.PopupTLVDelay, uint16(5)),
 					},
 				},
 			},
+			want:       `{"message":"Popup sent."}`,
+			statusCode: http.StatusOK,
 		},
 		{
-			name:       "successful deletion of multiple chat rooms",
-			body:       `{"names":["Room1", "Room2", "Room3"]}`,
-			want:       `Chat rooms deleted successfully.`,
-			statusCode: http.StatusNoContent,
-			mockParams: mockParams{
-				chatRoomDeleterParams: chatRoomDeleterParams{
-					deleteChatRoomsParams: deleteChatRoomsParams{
-						{
-							exchange: state.PublicExchange,
-							names:    []string{"Room1", "Room2", "Room3"},
-						},
+			name:           "display popup to everyone",
+			body:           `{"url":"https://aim.example.com/maintenance","everyone":true}`,
+			expectEveryone: true,
+			expectBody: wire.SNAC_0x08_0x02_PopupDisplay{
+				TLVRestBlock: wire.TLVRestBlock{
+					TLVList: wire.TLVList{
+						wire.NewTLVBE(wire.PopupTLVURL, "https://aim.example.com/maintenance"),
 					},
 				},
 			},
+			want:       `{"message":"Popup sent."}`,
+			statusCode: http.StatusOK,
 		},
 		{
-			name:       "empty names array",
-			body:       `{"names":[]}`,
-			want:       `no chat room names provided`,
+			name:       "missing message and url",
+			body:       `{"everyone":true}`,
+			want:       `{"message":"message or url is required"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "missing recipients",
+			body:       `{"message":"hello"}`,
+			want:       `{"message":"either screen_names or everyone must be set"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "both recipients and everyone",
+			body:       `{"message":"hello","screen_names":["userA"],"everyone":true}`,
+			want:       `{"message":"either screen_names or everyone must be set"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed body",
+			body:       `{"message":"hello"`,
+			want:       `{"message":"malformed input"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/popup", strings.NewReader(tc.body))
+			responseRecorder := httptest.NewRecorder()
+
+			popupService := newMockPopupService(t)
+			if tc.expectRecipients != nil {
+				popupService.EXPECT().
+					Display(matchContext(), tc.expectRecipients, tc.expectBody)
+			}
+			if tc.expectEveryone {
+				popupService.EXPECT().
+					DisplayAll(matchContext(), tc.expectBody)
+			}
+
+			postPopupHandler(responseRecorder, request, popupService)
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestMOTDHandler_GET(t *testing.T) {
+	responseRecorder := httptest.NewRecorder()
+
+	motdService := newMockMOTDService(t)
+	motdService.EXPECT().
+		Message().
+		Return("welcome!")
+
+	getMOTDHandler(responseRecorder, motdService)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, `{"message":"welcome!"}`, strings.TrimSpace(responseRecorder.Body.String()))
+}
+
+func TestRelationshipCacheStatsHandler_GET(t *testing.T) {
+	responseRecorder := httptest.NewRecorder()
+
+	retriever := newMockRelationshipCacheStatsRetriever(t)
+	retriever.EXPECT().
+		RelationshipCacheStats().
+		Return(state.RelationshipCacheStats{
+			Enabled: true,
+			Users:   3,
+			Lookups: 40,
+			Loads:   5,
+			Updates: 12,
+		})
+
+	getRelationshipCacheStatsHandler(responseRecorder, retriever)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, `{"enabled":true,"users":3,"lookups":40,"loads":5,"updates":12}`, strings.TrimSpace(responseRecorder.Body.String()))
+}
+
+func TestKeepAliveStatsHandler_GET(t *testing.T) {
+	responseRecorder := httptest.NewRecorder()
+
+	retriever := newMockKeepAliveStatsRetriever(t)
+	retriever.EXPECT().
+		KeepAliveStats().
+		Return(state.KeepAliveStats{
+			IntervalSeconds:    60,
+			IdleTimeoutSeconds: 600,
+			ProbesSent:         25,
+			Reaped:             2,
+		})
+
+	getKeepAliveStatsHandler(responseRecorder, retriever)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, `{"interval_seconds":60,"idle_timeout_seconds":600,"probes_sent":25,"reaped":2}`, strings.TrimSpace(responseRecorder.Body.String()))
+}
+
+func TestOutboundQueueStatsHandler_GET(t *testing.T) {
+	responseRecorder := httptest.NewRecorder()
+
+	retriever := newMockOutboundQueueStatsRetriever(t)
+	retriever.EXPECT().
+		OutboundQueueTotals().
+		Return(state.OutboundQueueTotals{
+			Dropped:     12,
+			Coalesced:   340,
+			Disconnects: 1,
+		})
+
+	getOutboundQueueStatsHandler(responseRecorder, retriever)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, `{"dropped_messages":12,"coalesced_messages":340,"overflow_disconnects":1}`, strings.TrimSpace(responseRecorder.Body.String()))
+}
+
+func TestMOTDHandler_PUT(t *testing.T) {
+	tt := []struct {
+		name          string
+		body          string
+		expectMessage string
+		want          string
+		statusCode    int
+	}{
+		{
+			name:          "set message of the day",
+			body:          `{"message":"maintenance at 10pm"}`,
+			expectMessage: "maintenance at 10pm",
+			want:          `{"message":"Message of the day updated."}`,
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:       "missing message",
+			body:       `{"message":""}`,
+			want:       `{"message":"message is required"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed body",
+			body:       `{"message":"hello"`,
+			want:       `{"message":"malformed input"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPut, "/motd", strings.NewReader(tc.body))
+			responseRecorder := httptest.NewRecorder()
+
+			motdService := newMockMOTDService(t)
+			if tc.expectMessage != "" {
+				motdService.EXPECT().
+					Message().
+					Return("the old message")
+				motdService.EXPECT().
+					SetMessage(matchContext(), tc.expectMessage)
+			}
+
+			putMOTDHandler(responseRecorder, request, motdService)
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestMOTDHandler_DELETE(t *testing.T) {
+	request := httptest.NewRequest(http.MethodDelete, "/motd", nil)
+	responseRecorder := httptest.NewRecorder()
+
+	motdService := newMockMOTDService(t)
+	motdService.EXPECT().
+		Message().
+		Return("the old message")
+	motdService.EXPECT().
+		SetMessage(matchContext(), "")
+
+	deleteMOTDHandler(responseRecorder, request, motdService)
+
+	assert.Equal(t, http.StatusNoContent, responseRecorder.Code)
+}
+
+func TestUserRateLimitsHandler_GET(t *testing.T) {
+	tt := []struct {
+		name       string
+		classes    []wire.RateClass
+		err        error
+		want       string
+		statusCode int
+	}{
+		{
+			name: "user with overrides",
+			classes: []wire.RateClass{
+				{ID: 3, WindowSize: 10, ClearLevel: 4, AlertLevel: 3, LimitLevel: 2, DisconnectLevel: 1, MaxLevel: 5},
+			},
+			want:       `{"classes":[{"id":3,"window_size":10,"clear_level":4,"alert_level":3,"limit_level":2,"disconnect_level":1,"max_level":5}]}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "user without overrides",
+			want:       `{"classes":[]}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "user not found",
+			err:        state.ErrNoUser,
+			want:       `user not found`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "runtime error",
+			err:        io.EOF,
+			want:       `internal server error`,
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/user/userA/rate-limits", nil)
+			request.SetPathValue("screenname", "userA")
+			responseRecorder := httptest.NewRecorder()
+
+			rateLimitService := newMockRateLimitService(t)
+			rateLimitService.EXPECT().
+				Overrides(matchContext(), state.NewIdentScreenName("userA")).
+				Return(tc.classes, tc.err)
+
+			getUserRateLimitsHandler(responseRecorder, request, rateLimitService, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestUserRateLimitsHandler_PUT(t *testing.T) {
+	tt := []struct {
+		name          string
+		body          string
+		expectClasses []wire.RateClass
+		overridesErr  error
+		err           error
+		want          string
+		statusCode    int
+	}{
+		{
+			name: "set overrides",
+			body: `{"classes":[{"id":3,"window_size":10,"clear_level":4,"alert_level":3,"limit_level":2,"disconnect_level":1,"max_level":5}]}`,
+			expectClasses: []wire.RateClass{
+				{ID: 3, WindowSize: 10, ClearLevel: 4, AlertLevel: 3, LimitLevel: 2, DisconnectLevel: 1, MaxLevel: 5},
+			},
+			want:       `{"message":"Rate limits updated."}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "user not found",
+			body: `{"classes":[{"id":3,"window_size":10,"clear_level":4,"alert_level":3,"limit_level":2,"disconnect_level":1,"max_level":5}]}`,
+			expectClasses: []wire.RateClass{
+				{ID: 3, WindowSize: 10, ClearLevel: 4, AlertLevel: 3, LimitLevel: 2, DisconnectLevel: 1, MaxLevel: 5},
+			},
+			overridesErr: state.ErrNoUser,
+			want:         `user not found`,
+			statusCode:   http.StatusNotFound,
+		},
+		{
+			name: "runtime error",
+			body: `{"classes":[{"id":3,"window_size":10,"clear_level":4,"alert_level":3,"limit_level":2,"disconnect_level":1,"max_level":5}]}`,
+			expectClasses: []wire.RateClass{
+				{ID: 3, WindowSize: 10, ClearLevel: 4, AlertLevel: 3, LimitLevel: 2, DisconnectLevel: 1, MaxLevel: 5},
+			},
+			err:        io.EOF,
+			want:       `internal server error`,
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name:       "invalid class",
+			body:       `{"classes":[{"id":6,"window_size":10,"clear_level":4,"alert_level":3,"limit_level":2,"disconnect_level":1,"max_level":5}]}`,
+			want:       `{"message":"invalid rate class ID 6: must be between 1 and 5"}`,
 			statusCode: http.StatusBadRequest,
 		},
 		{
-			name:       "malformed JSON",
-			body:       `{"names":["Room1"`, // missing closing brackets
-			want:       `malformed input`,
+			name:       "duplicate class",
+			body:       `{"classes":[{"id":3,"window_size":10,"clear_level":4,"alert_level":3,"limit_level":2,"disconnect_level":1,"max_level":5},{"id":3,"window_size":10,"clear_level":4,"alert_level":3,"limit_level":2,"disconnect_level":1,"max_level":5}]}`,
+			want:       `{"message":"duplicate rate class 3"}`,
 			statusCode: http.StatusBadRequest,
 		},
 		{
-			name:       "deletion error",
-			body:       `{"names":["TestRoom"]}`,
-			want:       `internal server error`,
-			statusCode: http.StatusInternalServerError,
-			mockParams: mockParams{
-				chatRoomDeleterParams: chatRoomDeleterParams{
-					deleteChatRoomsParams: deleteChatRoomsParams{
-						{
-							exchange: state.PublicExchange,
-							names:    []string{"TestRoom"},
-							err:      errors.New("database error"),
-						},
-					},
-				},
-			},
+			name:       "malformed body",
+			body:       `{"classes":[`,
+			want:       `{"message":"malformed input"}`,
+			statusCode: http.StatusBadRequest,
 		},
 	}
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			request := httptest.NewRequest(http.MethodDelete, "/chat/room/public", strings.NewReader(tc.body))
+			request := httptest.NewRequest(http.MethodPut, "/user/userA/rate-limits", strings.NewReader(tc.body))
+			request.SetPathValue("screenname", "userA")
 			responseRecorder := httptest.NewRecorder()
 
-			chatRoomDeleter := newMockChatRoomDeleter(t)
-			for _, params := range tc.mockParams.chatRoomDeleterParams.deleteChatRoomsParams {
-				chatRoomDeleter.EXPECT().
-					DeleteChatRooms(matchContext(), params.exchange, params.names).
-					Return(params.err)
+			rateLimitService := newMockRateLimitService(t)
+			if tc.expectClasses != nil {
+				rateLimitService.EXPECT().
+					Overrides(matchContext(), state.NewIdentScreenName("userA")).
+					Return(nil, tc.overridesErr)
+				if tc.overridesErr == nil {
+					rateLimitService.EXPECT().
+						SetOverrides(matchContext(), state.NewIdentScreenName("userA"), tc.expectClasses).
+						Return(tc.err)
+				}
 			}
 
-			deletePublicChatHandler(responseRecorder, request, chatRoomDeleter, slog.Default())
-
-			if responseRecorder.Code != tc.statusCode {
-				t.Errorf("want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
-			}
+			putUserRateLimitsHandler(responseRecorder, request, rateLimitService, slog.Default())
 
-			if strings.TrimSpace(responseRecorder.Body.String()) != tc.want {
-				t.Errorf("want '%s', got '%s'", tc.want, responseRecorder.Body)
-			}
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
 		})
 	}
 }
 
-func TestPrivateChatHandler_GET(t *testing.T) {
-	fnNewSess := func(screenName string) *state.Session {
-		sess := state.NewSession()
-		sess.SetIdentScreenName(state.NewIdentScreenName(screenName))
-		sess.SetDisplayScreenName(state.DisplayScreenName(screenName))
-		return sess
-	}
-
-	chatRoom1 := state.NewChatRoom("chat-room-1-name", state.NewIdentScreenName("chat-room-1-creator"), state.PrivateExchange)
-	chatRoom2 := state.NewChatRoom("chat-room-2-name", state.NewIdentScreenName("chat-room-2-creator"), state.PrivateExchange)
-
+func TestUserRateLimitsHandler_DELETE(t *testing.T) {
 	tt := []struct {
 		name       string
-		want       string
+		err        error
 		statusCode int
-		mockParams mockParams
 	}{
 		{
-			name:       "multiple chat rooms with participants",
-			want:       `[{"name":"chat-room-1-name","create_time":"0001-01-01T00:00:00Z","creator_id":"chat-room-1-creator","url":"aim:gochat?roomname=chat-room-1-name&exchange=4","participants":[{"id":"usera","screen_name":"userA"},{"id":"userb","screen_name":"userB"}]},{"name":"chat-room-2-name","create_time":"0001-01-01T00:00:00Z","creator_id":"chat-room-2-creator","url":"aim:gochat?roomname=chat-room-2-name&exchange=4","participants":[{"id":"userc","screen_name":"userC"},{"id":"userd","screen_name":"userD"}]}]`,
-			statusCode: http.StatusOK,
-			mockParams: mockParams{
-				chatRoomRetrieverParams: chatRoomRetrieverParams{
-					allChatRoomsParams: allChatRoomsParams{
-						{
-							exchange: state.PrivateExchange,
-							result: []state.ChatRoom{
-								chatRoom1,
-								chatRoom2,
-							},
-						},
-					},
-				},
-				chatSessionRetrieverParams: chatSessionRetrieverParams{
-					chatSessionRetrieverAllSessionsParams: chatSessionRetrieverAllSessionsParams{
-						{
-							cookie: chatRoom1.Cookie(),
-							result: []*state.Session{
-								fnNewSess("userA"),
-								fnNewSess("userB"),
-							},
-						},
-						{
-							cookie: chatRoom2.Cookie(),
-							result: []*state.Session{
-								fnNewSess("userC"),
-								fnNewSess("userD"),
-							},
-						},
-					},
-				},
-			},
-		},
-		{
-			name:       "chat room without participants",
-			want:       `[{"name":"chat-room-1-name","create_time":"0001-01-01T00:00:00Z","creator_id":"chat-room-1-creator","url":"aim:gochat?roomname=chat-room-1-name&exchange=4","participants":[]}]`,
-			statusCode: http.StatusOK,
-			mockParams: mockParams{
-				chatRoomRetrieverParams: chatRoomRetrieverParams{
-					allChatRoomsParams: allChatRoomsParams{
-						{
-							exchange: state.PrivateExchange,
-							result: []state.ChatRoom{
-								chatRoom1,
-							},
-						},
-					},
-				},
-				chatSessionRetrieverParams: chatSessionRetrieverParams{
-					chatSessionRetrieverAllSessionsParams: chatSessionRetrieverAllSessionsParams{
-						{
-							cookie: chatRoom1.Cookie(),
-							result: []*state.Session{},
-						},
-					},
-				},
-			},
+			name:       "clear overrides",
+			statusCode: http.StatusNoContent,
 		},
 		{
-			name:       "no chat rooms",
-			want:       `[]`,
-			statusCode: http.StatusOK,
-			mockParams: mockParams{
-				chatRoomRetrieverParams: chatRoomRetrieverParams{
-					allChatRoomsParams: allChatRoomsParams{
-						{
-							exchange: state.PrivateExchange,
-							result:   []state.ChatRoom{},
-						},
-					},
-				},
-			},
+			name:       "user not found",
+			err:        state.ErrNoUser,
+			statusCode: http.StatusNotFound,
 		},
 	}
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			request := httptest.NewRequest(http.MethodGet, "/chat/room/private", nil)
+			request := httptest.NewRequest(http.MethodDelete, "/user/userA/rate-limits", nil)
+			request.SetPathValue("screenname", "userA")
 			responseRecorder := httptest.NewRecorder()
 
-			chatRoomRetriever := newMockChatRoomRetriever(t)
-			for _, params := range tc.mockParams.chatRoomRetrieverParams.allChatRoomsParams {
-				chatRoomRetriever.EXPECT().
-					AllChatRooms(matchContext(), params.exchange).
-					Return(params.result, params.err)
-			}
-
-			chatSessionRetriever := newMockChatSessionRetriever(t)
-			for _, params := range tc.mockParams.chatSessionRetrieverParams.chatSessionRetrieverAllSessionsParams {
-				chatSessionRetriever.EXPECT().
-					AllSessions(params.cookie).
-					Return(params.result)
+			rateLimitService := newMockRateLimitService(t)
+			rateLimitService.EXPECT().
+				Overrides(matchContext(), state.NewIdentScreenName("userA")).
+				Return(nil, tc.err)
+			if tc.err == nil {
+				rateLimitService.EXPECT().
+					SetOverrides(matchContext(), state.NewIdentScreenName("userA"), []wire.RateClass(nil)).
+					Return(nil)
 			}
 
-			getPrivateChatHandler(responseRecorder, request, chatRoomRetriever, chatSessionRetriever, slog.Default())
-
-			if responseRecorder.Code != tc.statusCode {
-				t.Errorf("Want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
-			}
+			deleteUserRateLimitsHandler(responseRecorder, request, rateLimitService, slog.Default())
 
-			if strings.TrimSpace(responseRecorder.Body.String()) != tc.want {
-				t.Errorf("Want '%s', got '%s'", tc.want, responseRecorder.Body)
-			}
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
 		})
 	}
 }
 
-func TestInstantMessageHandler_POST(t *testing.T) {
-	type relayToScreenNameInputs struct {
-		sender    state.IdentScreenName
-		recipient state.IdentScreenName
-		msg       string
-	}
-
+func TestConfigReloadHandler_POST(t *testing.T) {
 	tt := []struct {
-		name                    string
-		relayToScreenNameInputs []relayToScreenNameInputs
-		body                    string
-		want                    string
-		statusCode              int
+		name       string
+		err        error
+		want       string
+		statusCode int
 	}{
 		{
-			name: "send an instant message",
-			relayToScreenNameInputs: []relayToScreenNameInputs{
-				{
-					sender:    state.NewIdentScreenName("sender_sn"),
-					recipient: state.NewIdentScreenName("recip_sn"),
-					msg:       "hello world!",
-				},
-			},
-			body:       `{"from":"sender_sn","to":"recip_sn","text":"hello world!"}`,
-			want:       `Message sent successfully.`,
+			name:       "reload configuration",
+			want:       `{"message":"Configuration reloaded."}`,
 			statusCode: http.StatusOK,
 		},
 		{
-			name:       "with malformed body",
-			body:       `{"screen_name":"userA", "password":"thepassword"`,
-			want:       `malformed input`,
+			name:       "invalid configuration",
+			err:        fmt.Errorf("%w: invalid TOC listener", config.ErrInvalidConfig),
+			want:       `{"message":"invalid configuration: invalid TOC listener"}`,
 			statusCode: http.StatusBadRequest,
 		},
+		{
+			name:       "reload failure",
+			err:        errors.New("address already in use"),
+			want:       `{"message":"internal server error"}`,
+			statusCode: http.StatusInternalServerError,
+		},
 	}
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			request := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(tc.body))
+			request := httptest.NewRequest(http.MethodPost, "/config/reload", nil)
 			responseRecorder := httptest.NewRecorder()
 
-			messageRelayer := newMockMessageRelayer(t)
-
-			for _, params := range tc.relayToScreenNameInputs {
-				validateSNAC := func(msg wire.SNACMessage) bool {
-					body := msg.Body.(wire.SNAC_0x04_0x07_ICBMChannelMsgToClient)
-					assert.Equal(t, params.sender.String(), body.TLVUserInfo.ScreenName)
-
-					b, ok := body.Bytes(wire.ICBMTLVAOLIMData)
-					assert.True(t, ok)
-
-					txt, err := wire.UnmarshalICBMMessageText(b)
-					assert.NoError(t, err)
-					assert.Equal(t, params.msg, txt)
-					return true
-				}
-				messageRelayer.EXPECT().
-					RelayToScreenName(mock.Anything, params.recipient, mock.MatchedBy(validateSNAC))
-			}
-
-			postInstantMessageHandler(responseRecorder, request, messageRelayer, slog.Default())
+			configReloader := newMockConfigReloader(t)
+			configReloader.EXPECT().
+				Reload(matchContext()).
+				Return(tc.err)
 
-			if responseRecorder.Code != tc.statusCode {
-				t.Errorf("want status '%d', got '%d'", tc.statusCode, responseRecorder.Code)
-			}
+			postConfigReloadHandler(responseRecorder, request, configReloader, slog.Default())
 
-			if strings.TrimSpace(responseRecorder.Body.String()) != tc.want {
-				t.Errorf("want '%s', got '%s'", tc.want, responseRecorder.Body)
-			}
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
 		})
 	}
 }
@@ -2108,6 +3720,7 @@ func TestDirectoryCategoryHandler_DELETE(t *testing.T) {
 		categoryID int
 		want       string
 		statusCode int
+		wantBefore any
 		mockParams mockParams
 	}{
 		{
@@ -2117,10 +3730,9 @@ func TestDirectoryCategoryHandler_DELETE(t *testing.T) {
 			statusCode: http.StatusNotFound,
 			mockParams: mockParams{
 				directoryManagerParams: directoryManagerParams{
-					deleteCategoryParams: deleteCategoryParams{
+					categoriesParams: categoriesParams{
 						{
-							categoryID: 1,
-							err:        state.ErrKeywordCategoryNotFound,
+							result: []state.Category{{ID: 2, Name: "another_category"}},
 						},
 					},
 				},
@@ -2133,6 +3745,11 @@ func TestDirectoryCategoryHandler_DELETE(t *testing.T) {
 			statusCode: http.StatusConflict,
 			mockParams: mockParams{
 				directoryManagerParams: directoryManagerParams{
+					categoriesParams: categoriesParams{
+						{
+							result: []state.Category{{ID: 1, Name: "the_category"}},
+						},
+					},
 					deleteCategoryParams: deleteCategoryParams{
 						{
 							categoryID: 1,
@@ -2149,6 +3766,11 @@ func TestDirectoryCategoryHandler_DELETE(t *testing.T) {
 			statusCode: http.StatusInternalServerError,
 			mockParams: mockParams{
 				directoryManagerParams: directoryManagerParams{
+					categoriesParams: categoriesParams{
+						{
+							result: []state.Category{{ID: 1, Name: "the_category"}},
+						},
+					},
 					deleteCategoryParams: deleteCategoryParams{
 						{
 							categoryID: 1,
@@ -2163,8 +3785,14 @@ func TestDirectoryCategoryHandler_DELETE(t *testing.T) {
 			categoryID: 1,
 			want:       ``,
 			statusCode: http.StatusNoContent,
+			wantBefore: directoryCategory{ID: 1, Name: "the_category"},
 			mockParams: mockParams{
 				directoryManagerParams: directoryManagerParams{
+					categoriesParams: categoriesParams{
+						{
+							result: []state.Category{{ID: 1, Name: "the_category"}},
+						},
+					},
 					deleteCategoryParams: deleteCategoryParams{
 						{
 							categoryID: 1,
@@ -2190,9 +3818,15 @@ func TestDirectoryCategoryHandler_DELETE(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			request := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/directory/category/%d/keyword", tc.categoryID), nil)
 			request.SetPathValue("id", fmt.Sprintf("%d", tc.categoryID))
+			request, rec := withAuditRecord(request)
 			responseRecorder := httptest.NewRecorder()
 
 			directoryManager := newMockDirectoryManager(t)
+			for _, params := range tc.mockParams.categoriesParams {
+				directoryManager.EXPECT().
+					Categories(matchContext()).
+					Return(params.result, params.err)
+			}
 			for _, params := range tc.mockParams.deleteCategoryParams {
 				directoryManager.EXPECT().
 					DeleteCategory(matchContext(), params.categoryID).
@@ -2208,6 +3842,7 @@ func TestDirectoryCategoryHandler_DELETE(t *testing.T) {
 			if strings.TrimSpace(responseRecorder.Body.String()) != tc.want {
 				t.Errorf("Want '%s', got '%s'", tc.want, responseRecorder.Body)
 			}
+			assert.Equal(t, tc.wantBefore, rec.before)
 		})
 	}
 }
@@ -2432,6 +4067,7 @@ func TestDirectoryKeywordHandler_DELETE(t *testing.T) {
 		categoryID int
 		want       string
 		statusCode int
+		wantBefore any
 		mockParams mockParams
 	}{
 		{
@@ -2441,7 +4077,7 @@ func TestDirectoryKeywordHandler_DELETE(t *testing.T) {
 			statusCode: http.StatusNotFound,
 			mockParams: mockParams{
 				directoryManagerParams: directoryManagerParams{
-					deleteKeywordParams: deleteKeywordParams{
+					keywordByIDParams: keywordByIDParams{
 						{
 							id:  1,
 							err: state.ErrKeywordNotFound,
@@ -2457,6 +4093,12 @@ func TestDirectoryKeywordHandler_DELETE(t *testing.T) {
 			statusCode: http.StatusConflict,
 			mockParams: mockParams{
 				directoryManagerParams: directoryManagerParams{
+					keywordByIDParams: keywordByIDParams{
+						{
+							id:     1,
+							result: state.Keyword{ID: 1, Name: "the_keyword"},
+						},
+					},
 					deleteKeywordParams: deleteKeywordParams{
 						{
 							id:  1,
@@ -2473,6 +4115,12 @@ func TestDirectoryKeywordHandler_DELETE(t *testing.T) {
 			statusCode: http.StatusInternalServerError,
 			mockParams: mockParams{
 				directoryManagerParams: directoryManagerParams{
+					keywordByIDParams: keywordByIDParams{
+						{
+							id:     1,
+							result: state.Keyword{ID: 1, Name: "the_keyword"},
+						},
+					},
 					deleteKeywordParams: deleteKeywordParams{
 						{
 							id:  1,
@@ -2487,8 +4135,15 @@ func TestDirectoryKeywordHandler_DELETE(t *testing.T) {
 			categoryID: 1,
 			want:       ``,
 			statusCode: http.StatusNoContent,
+			wantBefore: directoryKeyword{ID: 1, Name: "the_keyword"},
 			mockParams: mockParams{
 				directoryManagerParams: directoryManagerParams{
+					keywordByIDParams: keywordByIDParams{
+						{
+							id:     1,
+							result: state.Keyword{ID: 1, Name: "the_keyword"},
+						},
+					},
 					deleteKeywordParams: deleteKeywordParams{
 						{
 							id: 1,
@@ -2514,9 +4169,15 @@ func TestDirectoryKeywordHandler_DELETE(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			request := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/directory/keyword/%d", tc.categoryID), nil)
 			request.SetPathValue("id", fmt.Sprintf("%d", tc.categoryID))
+			request, rec := withAuditRecord(request)
 			responseRecorder := httptest.NewRecorder()
 
 			directoryManager := newMockDirectoryManager(t)
+			for _, params := range tc.mockParams.keywordByIDParams {
+				directoryManager.EXPECT().
+					KeywordByID(matchContext(), params.id).
+					Return(params.result, params.err)
+			}
 			for _, params := range tc.mockParams.deleteKeywordParams {
 				directoryManager.EXPECT().
 					DeleteKeyword(matchContext(), params.id).
@@ -2532,6 +4193,7 @@ func TestDirectoryKeywordHandler_DELETE(t *testing.T) {
 			if strings.TrimSpace(responseRecorder.Body.String()) != tc.want {
 				t.Errorf("Want '%s', got '%s'", tc.want, responseRecorder.Body)
 			}
+			assert.Equal(t, tc.wantBefore, rec.before)
 		})
 	}
 }
@@ -2891,6 +4553,7 @@ func TestBARTHandler_DELETE(t *testing.T) {
 		hash           string
 		wantStatusCode int
 		wantResponse   string
+		wantBefore     any
 		mockParams     mockParams
 	}{
 		{
@@ -2898,8 +4561,15 @@ func TestBARTHandler_DELETE(t *testing.T) {
 			hash:           "2B000001E4",
 			wantStatusCode: http.StatusOK,
 			wantResponse:   `{"message":"BART asset deleted successfully."}`,
+			wantBefore:     bartAssetDeletion{Hash: "2b000001e4", Size: 3},
 			mockParams: mockParams{
 				bartAssetManagerParams: bartAssetManagerParams{
+					bartItemParams: bartItemParams{
+						{
+							hash:   []byte{0x2B, 0x00, 0x00, 0x01, 0xE4},
+							result: []byte{1, 2, 3},
+						},
+					},
 					deleteBARTItemParams: deleteBARTItemParams{
 						{
 							hash: []byte{0x2B, 0x00, 0x00, 0x01, 0xE4},
@@ -2928,10 +4598,10 @@ func TestBARTHandler_DELETE(t *testing.T) {
 			wantResponse:   `{"message":"BART asset not found"}`,
 			mockParams: mockParams{
 				bartAssetManagerParams: bartAssetManagerParams{
-					deleteBARTItemParams: deleteBARTItemParams{
+					bartItemParams: bartItemParams{
 						{
-							hash: []byte{0x2B, 0x00, 0x00, 0x01, 0xE4},
-							err:  state.ErrBARTItemNotFound,
+							hash:   []byte{0x2B, 0x00, 0x00, 0x01, 0xE4},
+							result: nil,
 						},
 					},
 				},
@@ -2944,6 +4614,12 @@ func TestBARTHandler_DELETE(t *testing.T) {
 			wantResponse:   `{"message":"internal server error"}`,
 			mockParams: mockParams{
 				bartAssetManagerParams: bartAssetManagerParams{
+					bartItemParams: bartItemParams{
+						{
+							hash:   []byte{0x2B, 0x00, 0x00, 0x01, 0xE4},
+							result: []byte{1, 2, 3},
+						},
+					},
 					deleteBARTItemParams: deleteBARTItemParams{
 						{
 							hash: []byte{0x2B, 0x00, 0x00, 0x01, 0xE4},
@@ -2962,9 +4638,15 @@ func TestBARTHandler_DELETE(t *testing.T) {
 			if tc.hash != "" {
 				request.SetPathValue("hash", tc.hash)
 			}
+			request, rec := withAuditRecord(request)
 			responseRecorder := httptest.NewRecorder()
 
 			mockBARTManager := newMockBARTAssetManager(t)
+			for _, params := range tc.mockParams.bartAssetManagerParams.bartItemParams {
+				mockBARTManager.EXPECT().
+					BARTItem(matchContext(), params.hash).
+					Return(params.result, params.err)
+			}
 			for _, params := range tc.mockParams.bartAssetManagerParams.deleteBARTItemParams {
 				mockBARTManager.EXPECT().
 					DeleteBARTItem(matchContext(), params.hash).
@@ -2975,6 +4657,7 @@ func TestBARTHandler_DELETE(t *testing.T) {
 
 			assert.Equal(t, tc.wantStatusCode, responseRecorder.Code)
 			assert.JSONEq(t, tc.wantResponse, responseRecorder.Body.String())
+			assert.Equal(t, tc.wantBefore, rec.before)
 		})
 	}
 }
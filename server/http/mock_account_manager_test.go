@@ -245,6 +245,54 @@ func (_c *mockAccountManager_SetBotStatus_Call) RunAndReturn(run func(context.Co
 	return _c
 }
 
+// UpdateConfirmStatus provides a mock function with given fields: ctx, screenName, confirmStatus
+func (_m *mockAccountManager) UpdateConfirmStatus(ctx context.Context, screenName state.IdentScreenName, confirmStatus bool) error {
+	ret := _m.Called(ctx, screenName, confirmStatus)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateConfirmStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, state.IdentScreenName, bool) error); ok {
+		r0 = rf(ctx, screenName, confirmStatus)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// mockAccountManager_UpdateConfirmStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateConfirmStatus'
+type mockAccountManager_UpdateConfirmStatus_Call struct {
+	*mock.Call
+}
+
+// UpdateConfirmStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - screenName state.IdentScreenName
+//   - confirmStatus bool
+func (_e *mockAccountManager_Expecter) UpdateConfirmStatus(ctx interface{}, screenName interface{}, confirmStatus interface{}) *mockAccountManager_UpdateConfirmStatus_Call {
+	return &mockAccountManager_UpdateConfirmStatus_Call{Call: _e.mock.On("UpdateConfirmStatus", ctx, screenName, confirmStatus)}
+}
+
+func (_c *mockAccountManager_UpdateConfirmStatus_Call) Run(run func(ctx context.Context, screenName state.IdentScreenName, confirmStatus bool)) *mockAccountManager_UpdateConfirmStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(state.IdentScreenName), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *mockAccountManager_UpdateConfirmStatus_Call) Return(_a0 error) *mockAccountManager_UpdateConfirmStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *mockAccountManager_UpdateConfirmStatus_Call) RunAndReturn(run func(context.Context, state.IdentScreenName, bool) error) *mockAccountManager_UpdateConfirmStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateSuspendedStatus provides a mock function with given fields: ctx, suspendedStatus, screenName
 func (_m *mockAccountManager) UpdateSuspendedStatus(ctx context.Context, suspendedStatus uint16, screenName state.IdentScreenName) error {
 	ret := _m.Called(ctx, suspendedStatus, screenName)
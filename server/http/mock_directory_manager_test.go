@@ -289,6 +289,63 @@ func (_c *mockDirectoryManager_DeleteKeyword_Call) RunAndReturn(run func(context
 	return _c
 }
 
+// KeywordByID provides a mock function with given fields: ctx, id
+func (_m *mockDirectoryManager) KeywordByID(ctx context.Context, id uint8) (state.Keyword, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for KeywordByID")
+	}
+
+	var r0 state.Keyword
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint8) (state.Keyword, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint8) state.Keyword); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(state.Keyword)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint8) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockDirectoryManager_KeywordByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'KeywordByID'
+type mockDirectoryManager_KeywordByID_Call struct {
+	*mock.Call
+}
+
+// KeywordByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint8
+func (_e *mockDirectoryManager_Expecter) KeywordByID(ctx interface{}, id interface{}) *mockDirectoryManager_KeywordByID_Call {
+	return &mockDirectoryManager_KeywordByID_Call{Call: _e.mock.On("KeywordByID", ctx, id)}
+}
+
+func (_c *mockDirectoryManager_KeywordByID_Call) Run(run func(ctx context.Context, id uint8)) *mockDirectoryManager_KeywordByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint8))
+	})
+	return _c
+}
+
+func (_c *mockDirectoryManager_KeywordByID_Call) Return(_a0 state.Keyword, _a1 error) *mockDirectoryManager_KeywordByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockDirectoryManager_KeywordByID_Call) RunAndReturn(run func(context.Context, uint8) (state.Keyword, error)) *mockDirectoryManager_KeywordByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // KeywordsByCategory provides a mock function with given fields: ctx, categoryID
 func (_m *mockDirectoryManager) KeywordsByCategory(ctx context.Context, categoryID uint8) ([]state.Keyword, error) {
 	ret := _m.Called(ctx, categoryID)
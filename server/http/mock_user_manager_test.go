@@ -80,6 +80,115 @@ func (_c *mockUserManager_AllUsers_Call) RunAndReturn(run func(context.Context)
 	return _c
 }
 
+// AuthenticateUser provides a mock function with given fields: ctx, provider, username, password, securID
+func (_m *mockUserManager) AuthenticateUser(ctx context.Context, provider state.AuthProvider, username string, password string, securID string) (*state.User, error) {
+	ret := _m.Called(ctx, provider, username, password, securID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthenticateUser")
+	}
+
+	var r0 *state.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, state.AuthProvider, string, string, string) (*state.User, error)); ok {
+		return rf(ctx, provider, username, password, securID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, state.AuthProvider, string, string, string) *state.User); ok {
+		r0 = rf(ctx, provider, username, password, securID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*state.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, state.AuthProvider, string, string, string) error); ok {
+		r1 = rf(ctx, provider, username, password, securID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockUserManager_AuthenticateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthenticateUser'
+type mockUserManager_AuthenticateUser_Call struct {
+	*mock.Call
+}
+
+// AuthenticateUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - provider state.AuthProvider
+//   - username string
+//   - password string
+//   - securID string
+func (_e *mockUserManager_Expecter) AuthenticateUser(ctx interface{}, provider interface{}, username interface{}, password interface{}, securID interface{}) *mockUserManager_AuthenticateUser_Call {
+	return &mockUserManager_AuthenticateUser_Call{Call: _e.mock.On("AuthenticateUser", ctx, provider, username, password, securID)}
+}
+
+func (_c *mockUserManager_AuthenticateUser_Call) Run(run func(ctx context.Context, provider state.AuthProvider, username string, password string, securID string)) *mockUserManager_AuthenticateUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(state.AuthProvider), args[2].(string), args[3].(string), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *mockUserManager_AuthenticateUser_Call) Return(_a0 *state.User, _a1 error) *mockUserManager_AuthenticateUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockUserManager_AuthenticateUser_Call) RunAndReturn(run func(context.Context, state.AuthProvider, string, string, string) (*state.User, error)) *mockUserManager_AuthenticateUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ClearTOTP provides a mock function with given fields: ctx, screenName
+func (_m *mockUserManager) ClearTOTP(ctx context.Context, screenName state.IdentScreenName) error {
+	ret := _m.Called(ctx, screenName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClearTOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, state.IdentScreenName) error); ok {
+		r0 = rf(ctx, screenName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// mockUserManager_ClearTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClearTOTP'
+type mockUserManager_ClearTOTP_Call struct {
+	*mock.Call
+}
+
+// ClearTOTP is a helper method to define mock.On call
+//   - ctx context.Context
+//   - screenName state.IdentScreenName
+func (_e *mockUserManager_Expecter) ClearTOTP(ctx interface{}, screenName interface{}) *mockUserManager_ClearTOTP_Call {
+	return &mockUserManager_ClearTOTP_Call{Call: _e.mock.On("ClearTOTP", ctx, screenName)}
+}
+
+func (_c *mockUserManager_ClearTOTP_Call) Run(run func(ctx context.Context, screenName state.IdentScreenName)) *mockUserManager_ClearTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(state.IdentScreenName))
+	})
+	return _c
+}
+
+func (_c *mockUserManager_ClearTOTP_Call) Return(_a0 error) *mockUserManager_ClearTOTP_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *mockUserManager_ClearTOTP_Call) RunAndReturn(run func(context.Context, state.IdentScreenName) error) *mockUserManager_ClearTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteUser provides a mock function with given fields: ctx, screenName
 func (_m *mockUserManager) DeleteUser(ctx context.Context, screenName state.IdentScreenName) error {
 	ret := _m.Called(ctx, screenName)
@@ -127,6 +236,63 @@ func (_c *mockUserManager_DeleteUser_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// FindByAIMEmail provides a mock function with given fields: ctx, email
+func (_m *mockUserManager) FindByAIMEmail(ctx context.Context, email string) (state.User, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByAIMEmail")
+	}
+
+	var r0 state.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (state.User, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) state.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Get(0).(state.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockUserManager_FindByAIMEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindByAIMEmail'
+type mockUserManager_FindByAIMEmail_Call struct {
+	*mock.Call
+}
+
+// FindByAIMEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *mockUserManager_Expecter) FindByAIMEmail(ctx interface{}, email interface{}) *mockUserManager_FindByAIMEmail_Call {
+	return &mockUserManager_FindByAIMEmail_Call{Call: _e.mock.On("FindByAIMEmail", ctx, email)}
+}
+
+func (_c *mockUserManager_FindByAIMEmail_Call) Run(run func(ctx context.Context, email string)) *mockUserManager_FindByAIMEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *mockUserManager_FindByAIMEmail_Call) Return(_a0 state.User, _a1 error) *mockUserManager_FindByAIMEmail_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockUserManager_FindByAIMEmail_Call) RunAndReturn(run func(context.Context, string) (state.User, error)) *mockUserManager_FindByAIMEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // InsertUser provides a mock function with given fields: ctx, u
 func (_m *mockUserManager) InsertUser(ctx context.Context, u state.User) error {
 	ret := _m.Called(ctx, u)
@@ -174,6 +340,113 @@ func (_c *mockUserManager_InsertUser_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// LegacyHashUsers provides a mock function with given fields: ctx
+func (_m *mockUserManager) LegacyHashUsers(ctx context.Context) ([]state.User, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LegacyHashUsers")
+	}
+
+	var r0 []state.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]state.User, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []state.User); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]state.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockUserManager_LegacyHashUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LegacyHashUsers'
+type mockUserManager_LegacyHashUsers_Call struct {
+	*mock.Call
+}
+
+// LegacyHashUsers is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *mockUserManager_Expecter) LegacyHashUsers(ctx interface{}) *mockUserManager_LegacyHashUsers_Call {
+	return &mockUserManager_LegacyHashUsers_Call{Call: _e.mock.On("LegacyHashUsers", ctx)}
+}
+
+func (_c *mockUserManager_LegacyHashUsers_Call) Run(run func(ctx context.Context)) *mockUserManager_LegacyHashUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *mockUserManager_LegacyHashUsers_Call) Return(_a0 []state.User, _a1 error) *mockUserManager_LegacyHashUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockUserManager_LegacyHashUsers_Call) RunAndReturn(run func(context.Context) ([]state.User, error)) *mockUserManager_LegacyHashUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetTOTP provides a mock function with given fields: ctx, screenName, secret, recoveryCodes
+func (_m *mockUserManager) SetTOTP(ctx context.Context, screenName state.IdentScreenName, secret string, recoveryCodes []string) error {
+	ret := _m.Called(ctx, screenName, secret, recoveryCodes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, state.IdentScreenName, string, []string) error); ok {
+		r0 = rf(ctx, screenName, secret, recoveryCodes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// mockUserManager_SetTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTOTP'
+type mockUserManager_SetTOTP_Call struct {
+	*mock.Call
+}
+
+// SetTOTP is a helper method to define mock.On call
+//   - ctx context.Context
+//   - screenName state.IdentScreenName
+//   - secret string
+//   - recoveryCodes []string
+func (_e *mockUserManager_Expecter) SetTOTP(ctx interface{}, screenName interface{}, secret interface{}, recoveryCodes interface{}) *mockUserManager_SetTOTP_Call {
+	return &mockUserManager_SetTOTP_Call{Call: _e.mock.On("SetTOTP", ctx, screenName, secret, recoveryCodes)}
+}
+
+func (_c *mockUserManager_SetTOTP_Call) Run(run func(ctx context.Context, screenName state.IdentScreenName, secret string, recoveryCodes []string)) *mockUserManager_SetTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(state.IdentScreenName), args[2].(string), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *mockUserManager_SetTOTP_Call) Return(_a0 error) *mockUserManager_SetTOTP_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *mockUserManager_SetTOTP_Call) RunAndReturn(run func(context.Context, state.IdentScreenName, string, []string) error) *mockUserManager_SetTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetUserPassword provides a mock function with given fields: ctx, screenName, newPassword
 func (_m *mockUserManager) SetUserPassword(ctx context.Context, screenName state.IdentScreenName, newPassword string) error {
 	ret := _m.Called(ctx, screenName, newPassword)
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/mk6i/retro-aim-server/state"
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// OfflineMessageManager defines methods for inspecting and purging the
+// messages queued for users who are offline.
+type OfflineMessageManager interface {
+	// RetrieveMessages returns the offline messages queued for recip, oldest
+	// first.
+	RetrieveMessages(ctx context.Context, recip state.IdentScreenName) ([]state.OfflineMessage, error)
+
+	// DeleteMessages deletes the offline messages queued for recip.
+	DeleteMessages(ctx context.Context, recip state.IdentScreenName) error
+}
+
+// getUserOfflineMessagesHandler handles the GET
+// /user/{screenname}/offline-messages endpoint. It lists the messages
+// waiting to be delivered to the user.
+func getUserOfflineMessagesHandler(w http.ResponseWriter, r *http.Request, offlineMessages OfflineMessageManager, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+
+	messages, err := offlineMessages.RetrieveMessages(r.Context(), state.NewIdentScreenName(r.PathValue("screenname")))
+	if err != nil {
+		logger.Error("error in GET /user/{screenname}/offline-messages", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	out := make([]offlineMessageHandle, len(messages))
+	for i, msg := range messages {
+		out[i] = offlineMessageHandle{
+			Sender:  msg.Sender.String(),
+			SentAt:  msg.Sent.UTC(),
+			Channel: msg.Message.ChannelID,
+			Text:    offlineMessageText(msg.Message),
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		logger.Error("error in GET /user/{screenname}/offline-messages", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// deleteUserOfflineMessagesHandler handles the DELETE
+// /user/{screenname}/offline-messages endpoint. It discards the messages
+// waiting to be delivered to the user.
+func deleteUserOfflineMessagesHandler(w http.ResponseWriter, r *http.Request, offlineMessages OfflineMessageManager, logger *slog.Logger) {
+	if err := offlineMessages.DeleteMessages(r.Context(), state.NewIdentScreenName(r.PathValue("screenname"))); err != nil {
+		logger.Error("error in DELETE /user/{screenname}/offline-messages", "err", err.Error())
+		errorMsg(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// offlineMessageText returns the text of an offline instant message, or an
+// empty string if the message isn't a plain instant message.
+func offlineMessageText(msg wire.SNAC_0x04_0x06_ICBMChannelMsgToHost) string {
+	if msg.ChannelID != wire.ICBMChannelIM {
+		return ""
+	}
+	payload, ok := msg.Bytes(wire.ICBMTLVAOLIMData)
+	if !ok {
+		return ""
+	}
+	text, err := wire.UnmarshalICBMMessageText(payload)
+	if err != nil {
+		return ""
+	}
+	return text
+}
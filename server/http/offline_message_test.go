@@ -0,0 +1,134 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mk6i/retro-aim-server/state"
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+func TestUserOfflineMessagesHandler_GET(t *testing.T) {
+	sentAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	imText, err := wire.ICBMFragmentList("hello")
+	assert.NoError(t, err)
+
+	tt := []struct {
+		name       string
+		messages   []state.OfflineMessage
+		err        error
+		want       string
+		statusCode int
+	}{
+		{
+			name: "instant message",
+			messages: []state.OfflineMessage{
+				{
+					Sender:    state.NewIdentScreenName("userB"),
+					Recipient: state.NewIdentScreenName("userA"),
+					Sent:      sentAt,
+					Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+						ChannelID: wire.ICBMChannelIM,
+						TLVRestBlock: wire.TLVRestBlock{
+							TLVList: wire.TLVList{
+								wire.NewTLVBE(wire.ICBMTLVAOLIMData, imText),
+							},
+						},
+					},
+				},
+			},
+			want:       `[{"sender":"userb","sent_at":"2025-01-01T12:00:00Z","channel":1,"text":"hello"}]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "non-IM channel has no text",
+			messages: []state.OfflineMessage{
+				{
+					Sender:    state.NewIdentScreenName("userB"),
+					Recipient: state.NewIdentScreenName("userA"),
+					Sent:      sentAt,
+					Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+						ChannelID: wire.ICBMChannelRendezvous,
+					},
+				},
+			},
+			want:       `[{"sender":"userb","sent_at":"2025-01-01T12:00:00Z","channel":2,"text":""}]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "no messages",
+			want:       `[]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "runtime error",
+			err:        errors.New("database is locked"),
+			want:       `{"message":"internal server error"}`,
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/user/userA/offline-messages", nil)
+			request.SetPathValue("screenname", "userA")
+			responseRecorder := httptest.NewRecorder()
+
+			offlineMessages := newMockOfflineMessageManager(t)
+			offlineMessages.EXPECT().
+				RetrieveMessages(matchContext(), state.NewIdentScreenName("userA")).
+				Return(tc.messages, tc.err)
+
+			getUserOfflineMessagesHandler(responseRecorder, request, offlineMessages, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
+
+func TestUserOfflineMessagesHandler_DELETE(t *testing.T) {
+	tt := []struct {
+		name       string
+		err        error
+		want       string
+		statusCode int
+	}{
+		{
+			name:       "messages purged",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "runtime error",
+			err:        errors.New("database is locked"),
+			want:       `{"message":"internal server error"}`,
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodDelete, "/user/userA/offline-messages", nil)
+			request.SetPathValue("screenname", "userA")
+			responseRecorder := httptest.NewRecorder()
+
+			offlineMessages := newMockOfflineMessageManager(t)
+			offlineMessages.EXPECT().
+				DeleteMessages(matchContext(), state.NewIdentScreenName("userA")).
+				Return(tc.err)
+
+			deleteUserOfflineMessagesHandler(responseRecorder, request, offlineMessages, slog.Default())
+
+			assert.Equal(t, tc.statusCode, responseRecorder.Code)
+			assert.Equal(t, tc.want, strings.TrimSpace(responseRecorder.Body.String()))
+		})
+	}
+}
@@ -2,9 +2,12 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"net/mail"
 	"time"
 
+	"github.com/mk6i/retro-aim-server/config"
+	"github.com/mk6i/retro-aim-server/mailer"
 	"github.com/mk6i/retro-aim-server/state"
 	"github.com/mk6i/retro-aim-server/wire"
 )
@@ -30,6 +33,9 @@ type AccountManager interface {
 
 	// SetBotStatus updates the flag that indicates whether the user is a bot.
 	SetBotStatus(ctx context.Context, isBot bool, screenName state.IdentScreenName) error
+
+	// UpdateConfirmStatus sets whether a user account has been confirmed.
+	UpdateConfirmStatus(ctx context.Context, screenName state.IdentScreenName, confirmStatus bool) error
 }
 
 // BARTAssetManager defines methods for managing BART (Buddy ART) assets.
@@ -91,10 +97,32 @@ type DirectoryManager interface {
 	// DeleteKeyword removes a keyword by ID.
 	DeleteKeyword(ctx context.Context, id uint8) error
 
+	// KeywordByID returns the keyword identified by id. It returns
+	// state.ErrKeywordNotFound if the keyword does not exist.
+	KeywordByID(ctx context.Context, id uint8) (state.Keyword, error)
+
 	// KeywordsByCategory returns all keywords under the specified category.
 	KeywordsByCategory(ctx context.Context, categoryID uint8) ([]state.Keyword, error)
 }
 
+// EmailTokenManager issues and redeems the single-use tokens that are
+// emailed to users for account confirmation and password resets.
+type EmailTokenManager interface {
+	// IssueEmailToken creates a token that can be redeemed for purpose
+	// until expiresAt.
+	IssueEmailToken(ctx context.Context, screenName state.IdentScreenName, purpose state.EmailTokenPurpose, expiresAt time.Time) (string, error)
+
+	// LookupEmailToken returns the screen name a token was issued to without
+	// consuming it. It returns state.ErrInvalidEmailToken under the same
+	// conditions as RedeemEmailToken.
+	LookupEmailToken(ctx context.Context, token string, purpose state.EmailTokenPurpose, now time.Time) (state.IdentScreenName, error)
+
+	// RedeemEmailToken consumes a token and returns the screen name it was
+	// issued to. It returns state.ErrInvalidEmailToken if the token is
+	// unknown, expired, or was issued for another purpose.
+	RedeemEmailToken(ctx context.Context, token string, purpose state.EmailTokenPurpose, now time.Time) (state.IdentScreenName, error)
+}
+
 // FeedBagRetriever defines methods for retrieving buddy list metadata.
 type FeedBagRetriever interface {
 	// BuddyIconMetadata retrieves a user's buddy icon metadata. It returns nil
@@ -102,12 +130,123 @@ type FeedBagRetriever interface {
 	BuddyIconMetadata(ctx context.Context, screenName state.IdentScreenName) (*wire.BARTID, error)
 }
 
+// InviteManager defines methods for tracking the invitations users send to
+// their friends.
+type InviteManager interface {
+	// Invites returns all invitations with the given status. If status is
+	// empty, all invitations are returned.
+	Invites(ctx context.Context, status state.InviteStatus) ([]state.Invite, error)
+
+	// DeleteInvite removes an invitation. It returns state.ErrNoInvite if the
+	// invitation does not exist.
+	DeleteInvite(ctx context.Context, id int64) error
+}
+
+// AuthModeRetriever provides the authentication settings that can change
+// while the server runs.
+type AuthModeRetriever interface {
+	// AuthProvider returns the provider that checks passwords, or nil if
+	// passwords are checked against the user store.
+	AuthProvider() state.AuthProvider
+}
+
+// LoginLockoutManager defines methods for tracking, reviewing and clearing
+// accounts locked out after too many failed login attempts.
+type LoginLockoutManager interface {
+	// Lockouts returns all accounts that are currently locked out.
+	Lockouts() []state.LoginLockout
+
+	// ClearLockout lifts the lockout for the given screen name and resets
+	// its failed login attempts. It returns the cleared state, or false if
+	// there were no failed login attempts.
+	ClearLockout(screenName state.IdentScreenName) (state.LoginLockout, bool)
+
+	// IsLocked indicates whether the account is currently locked out.
+	IsLocked(screenName state.IdentScreenName) bool
+
+	// RecordFailure registers a failed login attempt for the account.
+	RecordFailure(screenName state.IdentScreenName)
+
+	// RecordSuccess clears the failed login attempts for the account.
+	RecordSuccess(screenName state.IdentScreenName)
+}
+
+// Mailer delivers email notifications to users.
+type Mailer interface {
+	// Send delivers msg to its recipient.
+	Send(ctx context.Context, msg mailer.Message) error
+}
+
 // MessageRelayer defines a method for sending a SNAC message to a specific screen name.
 type MessageRelayer interface {
 	// RelayToScreenName sends the given SNAC message to the specified screen name.
 	RelayToScreenName(ctx context.Context, screenName state.IdentScreenName, msg wire.SNACMessage)
 }
 
+// MOTDService defines methods for managing the message of the day.
+type MOTDService interface {
+	// Message returns the message of the day, or an empty string if none is
+	// set.
+	Message() string
+
+	// SetMessage replaces the message of the day and pushes it to all online
+	// users. An empty message clears it.
+	SetMessage(ctx context.Context, message string)
+}
+
+// RelationshipCacheStatsRetriever defines methods for retrieving the
+// activity counters of the relationship cache.
+type RelationshipCacheStatsRetriever interface {
+	// RelationshipCacheStats returns the relationship cache's activity
+	// counters.
+	RelationshipCacheStats() state.RelationshipCacheStats
+}
+
+// KeepAliveStatsRetriever defines methods for retrieving the keepalive
+// activity of client connections.
+type KeepAliveStatsRetriever interface {
+	// KeepAliveStats returns the keepalive settings and activity counters.
+	KeepAliveStats() state.KeepAliveStats
+}
+
+// OutboundQueueStatsRetriever defines methods for retrieving outbound queue
+// activity across all sessions.
+type OutboundQueueStatsRetriever interface {
+	// OutboundQueueTotals returns the dropped and coalesced message counts.
+	OutboundQueueTotals() state.OutboundQueueTotals
+}
+
+// ConfigReloader reloads the server configuration.
+type ConfigReloader interface {
+	// Reload re-reads the settings file and applies the settings that can
+	// change while the server runs. It returns an error wrapping
+	// config.ErrInvalidConfig if the new configuration is invalid.
+	Reload(ctx context.Context) error
+}
+
+// RateLimitService defines methods for managing per-user overrides of the
+// server's rate limit classes.
+type RateLimitService interface {
+	// Overrides returns the rate limit classes that override the server's
+	// classes for a user. Return state.ErrNoUser if the user does not exist.
+	Overrides(ctx context.Context, screenName state.IdentScreenName) ([]wire.RateClass, error)
+
+	// SetOverrides replaces a user's rate limit overrides and applies them to
+	// the user's active sessions. Return state.ErrNoUser if the user does not
+	// exist.
+	SetOverrides(ctx context.Context, screenName state.IdentScreenName, classes []wire.RateClass) error
+}
+
+// PopupService defines methods for displaying popup windows on clients.
+type PopupService interface {
+	// Display shows a popup on the clients of the given users. Users that
+	// are offline are skipped.
+	Display(ctx context.Context, screenNames []state.IdentScreenName, body wire.SNAC_0x08_0x02_PopupDisplay)
+
+	// DisplayAll shows a popup on the clients of all online users.
+	DisplayAll(ctx context.Context, body wire.SNAC_0x08_0x02_PopupDisplay)
+}
+
 // ProfileRetriever defines a method for retrieving a user's free-form profile.
 type ProfileRetriever interface {
 	// Profile returns the free-form profile body for the given screen name.
@@ -130,9 +269,23 @@ type UserManager interface {
 	// AllUsers returns all registered users.
 	AllUsers(ctx context.Context) ([]state.User, error)
 
+	// AuthenticateUser checks the credentials a user signs on with against
+	// provider, or the stored password hashes if provider is nil. It returns
+	// state.ErrNoUser, state.ErrUserSuspended, state.ErrBadCredentials or
+	// state.ErrBadSecurID if the user can't sign on.
+	AuthenticateUser(ctx context.Context, provider state.AuthProvider, username, password, securID string) (*state.User, error)
+
+	// LegacyHashUsers returns all users whose passwords are only stored as
+	// MD5 hashes.
+	LegacyHashUsers(ctx context.Context) ([]state.User, error)
+
 	// DeleteUser removes a user from the system by screen name.
 	DeleteUser(ctx context.Context, screenName state.IdentScreenName) error
 
+	// FindByAIMEmail returns the AIM user registered with an email address.
+	// It returns state.ErrNoUser if there is no such user.
+	FindByAIMEmail(ctx context.Context, email string) (state.User, error)
+
 	// InsertUser inserts a new user into the system. Return state.ErrDupUser
 	// if a user with the same screen name already exists.
 	InsertUser(ctx context.Context, u state.User) error
@@ -140,6 +293,13 @@ type UserManager interface {
 	// SetUserPassword sets the user's password hashes and auth key.
 	SetUserPassword(ctx context.Context, screenName state.IdentScreenName, newPassword string) error
 
+	// SetTOTP enrolls a user in two-factor auth with the given TOTP secret
+	// and recovery codes.
+	SetTOTP(ctx context.Context, screenName state.IdentScreenName, secret string, recoveryCodes []string) error
+
+	// ClearTOTP disables two-factor auth for a user.
+	ClearTOTP(ctx context.Context, screenName state.IdentScreenName) error
+
 	// User returns all attributes for a user.
 	User(ctx context.Context, screenName state.IdentScreenName) (*state.User, error)
 }
@@ -149,6 +309,16 @@ type userWithPassword struct {
 	Password   string `json:"password,omitempty"`
 }
 
+type forgotPassword struct {
+	// Account is a screen name or email address.
+	Account string `json:"account"`
+}
+
+type passwordReset struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
 type onlineUsers struct {
 	Count    int             `json:"count"`
 	Sessions []sessionHandle `json:"sessions"`
@@ -162,6 +332,40 @@ type userHandle struct {
 	IsBot           bool   `json:"is_bot"`
 }
 
+type legacyPasswordUserHandle struct {
+	ID         string `json:"id"`
+	ScreenName string `json:"screen_name"`
+	IsICQ      bool   `json:"is_icq"`
+}
+
+type totpEnrollmentHandle struct {
+	Secret        string   `json:"secret"`
+	URI           string   `json:"uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// totpStatus is the audit log representation of a user's two-factor auth
+// settings. It leaves out the secret and recovery codes.
+type totpStatus struct {
+	Enabled bool `json:"enabled"`
+}
+
+type loginLockoutHandle struct {
+	ScreenName     string    `json:"screen_name"`
+	FailedAttempts int       `json:"failed_attempts"`
+	LockedUntil    time.Time `json:"locked_until"`
+}
+
+type inviteHandle struct {
+	ID         int64     `json:"id"`
+	Sender     string    `json:"sender"`
+	Email      string    `json:"email"`
+	Message    string    `json:"message"`
+	CreatedAt  time.Time `json:"created_at"`
+	Status     string    `json:"status"`
+	AcceptedBy string    `json:"accepted_by,omitempty"`
+}
+
 type aimChatUserHandle struct {
 	ID         string `json:"id"`
 	ScreenName string `json:"screen_name"`
@@ -179,6 +383,13 @@ type userAccountHandle struct {
 	IsBot           bool   `json:"is_bot"`
 }
 
+// userAccountState is the part of a user account that PATCH
+// /user/{screenname}/account changes.
+type userAccountState struct {
+	SuspendedStatus string `json:"suspended_status"`
+	IsBot           bool   `json:"is_bot"`
+}
+
 type userAccountPatch struct {
 	SuspendedStatusText *string `json:"suspended_status"`
 	IsBot               *bool   `json:"is_bot"`
@@ -193,6 +404,9 @@ type sessionHandle struct {
 	IsICQ         bool    `json:"is_icq"`
 	RemoteAddr    string  `json:"remote_addr,omitempty"`
 	RemotePort    uint16  `json:"remote_port,omitempty"`
+	Queued        int     `json:"queued_messages"`
+	Dropped       uint64  `json:"dropped_messages"`
+	Coalesced     uint64  `json:"coalesced_messages"`
 }
 
 type chatRoomCreate struct {
@@ -211,6 +425,24 @@ type chatRoom struct {
 	Participants []aimChatUserHandle `json:"participants"`
 }
 
+type motd struct {
+	Message string `json:"message"`
+}
+
+type rateLimitOverrides struct {
+	Classes []config.RateClass `json:"classes"`
+}
+
+type popupRequest struct {
+	Message     string   `json:"message"`
+	URL         string   `json:"url"`
+	ScreenNames []string `json:"screen_names"`
+	Everyone    bool     `json:"everyone"`
+	Width       uint16   `json:"width"`
+	Height      uint16   `json:"height"`
+	Delay       uint16   `json:"delay"`
+}
+
 type instantMessage struct {
 	From string `json:"from"`
 	To   string `json:"to"`
@@ -240,6 +472,65 @@ type messageBody struct {
 	Message string `json:"message"`
 }
 
+type apiAdminHandle struct {
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	HasToken  bool      `json:"has_token"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type apiAdminCreate struct {
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+	Role     string `json:"role"`
+}
+
+type apiAdminPatch struct {
+	Role     *string `json:"role,omitempty"`
+	Password *string `json:"password,omitempty"`
+}
+
+type apiAdminToken struct {
+	Token string `json:"token"`
+}
+
+type auditEntryHandle struct {
+	ID        int64           `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	SourceIP  string          `json:"source_ip"`
+}
+
+type offlineMessageHandle struct {
+	Sender  string    `json:"sender"`
+	SentAt  time.Time `json:"sent_at"`
+	Channel uint16    `json:"channel"`
+	Text    string    `json:"text"`
+}
+
+type archivedIMHandle struct {
+	ID        int64     `json:"id"`
+	SentAt    time.Time `json:"sent_at"`
+	Sender    string    `json:"sender"`
+	Recipient string    `json:"recipient"`
+	Text      string    `json:"text"`
+}
+
+// imArchiveSettings are a user's IM archive settings. A nil RetentionDays
+// means the server's default retention period applies.
+type imArchiveSettings struct {
+	OptOut        bool `json:"opt_out"`
+	RetentionDays *int `json:"retention_days"`
+}
+
+type imArchiveOptOut struct {
+	OptOut *bool `json:"opt_out"`
+}
+
 // Web API key management types
 
 type createWebAPIKeyRequest struct {
@@ -99,6 +99,12 @@ func postWebAPIKeyHandler(w http.ResponseWriter, r *http.Request, keyManager Web
 		Capabilities:   apiKey.Capabilities,
 	}
 
+	// never record the key itself
+	audited := resp
+	audited.DevKey = ""
+	auditTarget(r, apiKey.DevID)
+	auditChange(r, nil, audited)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -196,6 +202,18 @@ func putWebAPIKeyHandler(w http.ResponseWriter, r *http.Request, keyManager WebA
 		Capabilities:   req.Capabilities,
 	}
 
+	// Retrieve the key as it was before the update for the audit log
+	before, err := keyManager.GetAPIKeyByDevID(r.Context(), devID)
+	if err != nil {
+		if err == state.ErrNoAPIKey {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get API key", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	// Update the key
 	if err := keyManager.UpdateAPIKey(r.Context(), devID, updates); err != nil {
 		if err == state.ErrNoAPIKey {
@@ -216,7 +234,19 @@ func putWebAPIKeyHandler(w http.ResponseWriter, r *http.Request, keyManager WebA
 	}
 
 	// Convert to response format (without dev_key)
-	resp := webAPIKeyResponse{
+	resp := newWebAPIKeyResponse(*key)
+	auditChange(r, newWebAPIKeyResponse(*before), resp)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Error("failed to encode response", "err", err.Error())
+	}
+}
+
+// newWebAPIKeyResponse converts key to its response format without the
+// secret dev_key.
+func newWebAPIKeyResponse(key state.WebAPIKey) webAPIKeyResponse {
+	return webAPIKeyResponse{
 		DevID:          key.DevID,
 		AppName:        key.AppName,
 		CreatedAt:      key.CreatedAt,
@@ -226,11 +256,6 @@ func putWebAPIKeyHandler(w http.ResponseWriter, r *http.Request, keyManager WebA
 		AllowedOrigins: key.AllowedOrigins,
 		Capabilities:   key.Capabilities,
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		logger.Error("failed to encode response", "err", err.Error())
-	}
 }
 
 // deleteWebAPIKeyHandler handles DELETE /admin/webapi/keys/{id} requests.
@@ -241,6 +266,18 @@ func deleteWebAPIKeyHandler(w http.ResponseWriter, r *http.Request, keyManager W
 		return
 	}
 
+	// Retrieve the key as it was before the deletion for the audit log
+	before, err := keyManager.GetAPIKeyByDevID(r.Context(), devID)
+	if err != nil {
+		if err == state.ErrNoAPIKey {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return
+		}
+		logger.Error("failed to get API key", "err", err.Error())
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	if err := keyManager.DeleteAPIKey(r.Context(), devID); err != nil {
 		if err == state.ErrNoAPIKey {
 			http.Error(w, "API key not found", http.StatusNotFound)
@@ -250,6 +287,7 @@ func deleteWebAPIKeyHandler(w http.ResponseWriter, r *http.Request, keyManager W
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
+	auditChange(r, newWebAPIKeyResponse(*before), nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
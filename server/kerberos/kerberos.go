@@ -3,90 +3,253 @@ package kerberos
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
-
-	"golang.org/x/sync/errgroup"
+	"sync"
 
 	"github.com/mk6i/retro-aim-server/config"
 	"github.com/mk6i/retro-aim-server/state"
 	"github.com/mk6i/retro-aim-server/wire"
 )
 
+var errServerShutdown = errors.New("server is shut down")
+
 type AuthService interface {
 	KerberosLogin(ctx context.Context, inBody wire.SNAC_0x050C_0x0002_KerberosLoginRequest, newUserFn func(screenName state.DisplayScreenName) (state.User, error), advertisedHost string) (wire.SNACMessage, error)
 }
 
 func NewKerberosServer(listeners []config.Listener, logger *slog.Logger, authService AuthService) *Server {
-	servers := make([]*http.Server, 0, len(listeners))
-
-	for _, l := range listeners {
-		if l.KerberosListenAddress == "" {
-			continue
-		}
-
-		mux := http.NewServeMux()
-
-		mux.HandleFunc("POST /", func(writer http.ResponseWriter, request *http.Request) {
-			postHandler(writer, request, authService, logger, l.BOSAdvertisedHostSSL)
-		})
-
-		servers = append(servers, &http.Server{
-			Addr:    l.KerberosListenAddress,
-			Handler: mux,
-		})
-	}
-
 	return &Server{
-		servers: servers,
-		logger:  logger,
+		authService: authService,
+		closed:      make(chan struct{}),
+		listenerCfg: listeners,
+		listeners:   make(map[string]*listener),
+		logger:      logger,
 	}
 }
 
 // Server hosts an HTTP endpoint capable of handling AIM-style Kerberos
 // authentication. The messages are structured as SNACs transmitted over HTTP.
 type Server struct {
-	servers []*http.Server
-	logger  *slog.Logger
+	authService AuthService
+	closed      chan struct{}
+	listenerCfg []config.Listener
+	logger      *slog.Logger
+
+	mutex     sync.Mutex
+	listeners map[string]*listener // keyed by listen address
+	shutdown  bool
+}
+
+// listener is an HTTP server bound to a Kerberos listen address.
+type listener struct {
+	ln             net.Listener
+	server         *http.Server
+	advertisedHost string
+	// tlsSettings and tlsConfig are set if the listener serves HTTPS
+	tlsSettings config.TLS
+	tlsConfig   *tls.Config
+}
+
+// listenerSettings are the settings of a Kerberos listen address.
+type listenerSettings struct {
+	advertisedHost string
+	hasTLS         bool
+	tls            config.TLS
 }
 
 func (s *Server) ListenAndServe() error {
-	if len(s.servers) == 0 {
-		s.logger.Debug("no kerberos listeners defined")
-		return nil
+	err := s.SetListeners(s.listenerCfg)
+	switch {
+	case errors.Is(err, errServerShutdown):
+		return nil // shut down before it started
+	case err != nil:
+		return err
+	}
+
+	<-s.closed // block until Shutdown is called
+	return nil
+}
+
+// SetListeners starts serving on the Kerberos listen addresses in
+// listenerCfg that aren't served yet and stops serving on those that aren't
+// in listenerCfg. Requests in progress on a removed address are allowed to
+// finish. HTTPS addresses pick up changed certificate settings without
+// being reopened. If a new address can't be opened or a certificate can't
+// be loaded, the served addresses are left as they were.
+func (s *Server) SetListeners(listenerCfg []config.Listener) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.shutdown {
+		return errServerShutdown
+	}
+
+	want := make(map[string]listenerSettings)
+	for _, l := range listenerCfg {
+		if l.KerberosListenAddress != "" {
+			want[l.KerberosListenAddress] = listenerSettings{advertisedHost: l.BOSAdvertisedHostSSL}
+		}
+		if l.KerberosListenAddressTLS != "" {
+			want[l.KerberosListenAddressTLS] = listenerSettings{
+				advertisedHost: l.BOSAdvertisedHostSSL,
+				hasTLS:         true,
+				tls:            l.TLS,
+			}
+		}
+	}
+
+	// load the certificates of new HTTPS listeners and of HTTPS listeners
+	// whose certificate settings changed
+	tlsConfigs := make(map[string]*tls.Config)
+	for addr, settings := range want {
+		if !settings.hasTLS {
+			continue
+		}
+		if l, ok := s.listeners[addr]; ok && l.tlsConfig != nil && l.tlsSettings == settings.tls {
+			continue
+		}
+		tlsConfig, err := state.NewTLSConfig(settings.tls.CertFile, settings.tls.KeyFile, settings.tls.LegacyCiphers)
+		if err != nil {
+			return fmt.Errorf("unable to start kerberos server: %w", err)
+		}
+		tlsConfigs[addr] = tlsConfig
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// open the new addresses first so that a failure leaves the server as
+	// it was
+	opened := make(map[string]net.Listener)
+	for addr := range want {
+		if _, ok := s.listeners[addr]; ok {
+			continue
+		}
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, ln := range opened {
+				_ = ln.Close()
+			}
+			return fmt.Errorf("unable to start kerberos server: %w", err)
+		}
+		opened[addr] = ln
+	}
 
-	g, ctx := errgroup.WithContext(ctx)
-	for _, server := range s.servers {
-		g.Go(func() error {
-			s.logger.Info("starting server", "addr", server.Addr)
-			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-				cancel()
-				return fmt.Errorf("unable to start kerberos server: %w", err)
+	for addr, l := range s.listeners {
+		settings, ok := want[addr]
+		switch {
+		case !ok, settings.hasTLS != (l.tlsConfig != nil):
+			// stop accepting connections right away, but let requests in
+			// progress finish
+			_ = l.ln.Close()
+			go func() {
+				_ = l.server.Shutdown(context.Background())
+			}()
+			delete(s.listeners, addr)
+			s.logger.Info("stopped listener", "addr", addr)
+			if ok {
+				// serve the address again with the new scheme
+				ln, err := net.Listen("tcp", addr)
+				if err != nil {
+					s.logger.Error("unable to restart listener", "addr", addr, "err", err.Error())
+					continue
+				}
+				opened[addr] = ln
+			}
+		case settings.advertisedHost != l.advertisedHost || tlsConfigs[addr] != nil:
+			l.advertisedHost = settings.advertisedHost
+			if tlsConfig, ok := tlsConfigs[addr]; ok {
+				l.tlsSettings = settings.tls
+				l.tlsConfig = tlsConfig
 			}
-			return nil
+			s.logger.Info("updated listener", "addr", addr)
+		}
+	}
+
+	for addr, ln := range opened {
+		settings := want[addr]
+		l := &listener{
+			ln:             ln,
+			advertisedHost: settings.advertisedHost,
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /", func(writer http.ResponseWriter, request *http.Request) {
+			s.mutex.Lock()
+			advertisedHost := l.advertisedHost
+			s.mutex.Unlock()
+			postHandler(writer, request, s.authService, s.logger, advertisedHost)
 		})
+		l.server = &http.Server{
+			Addr:    addr,
+			Handler: mux,
+		}
+		if settings.hasTLS {
+			l.tlsSettings = settings.tls
+			l.tlsConfig = tlsConfigs[addr]
+			// look up the TLS config on each connection so that it can be
+			// replaced when the certificate settings change
+			ln = &tlsListener{Listener: ln, config: func() *tls.Config {
+				s.mutex.Lock()
+				defer s.mutex.Unlock()
+				return l.tlsConfig
+			}}
+		}
+		s.listeners[addr] = l
+
+		s.logger.Info("starting server", "addr", addr, "tls", settings.hasTLS)
+		go func() {
+			if err := l.server.Serve(ln); !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+				s.logger.Error("kerberos server failed", "addr", addr, "err", err.Error())
+			}
+		}()
 	}
 
-	return g.Wait()
+	if len(s.listeners) == 0 {
+		s.logger.Debug("no kerberos listeners defined")
+	}
+
+	return nil
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
-	if len(s.servers) > 0 {
-		for _, srv := range s.servers {
-			_ = srv.Shutdown(ctx)
+	s.mutex.Lock()
+	if s.shutdown {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.shutdown = true
+	listeners := s.listeners
+	s.listeners = make(map[string]*listener)
+	s.mutex.Unlock()
+
+	if len(listeners) > 0 {
+		for _, l := range listeners {
+			_ = l.server.Shutdown(ctx)
 		}
 		s.logger.Info("shutdown complete")
 	}
+	close(s.closed)
 	return nil
 }
 
+// tlsListener is a net.Listener that serves the connections it accepts over
+// TLS.
+type tlsListener struct {
+	net.Listener
+	config func() *tls.Config
+}
+
+func (l *tlsListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return tls.Server(conn, l.config()), nil
+}
+
 // postHandler handles AIM-style Kerberos authentication for AIM 6.0+.
 func postHandler(w http.ResponseWriter, r *http.Request, authService AuthService, logger *slog.Logger, listenAddress string) {
 	b, err := io.ReadAll(r.Body)
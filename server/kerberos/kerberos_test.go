@@ -3,11 +3,21 @@ package kerberos
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"log/slog"
+	"math/big"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -17,6 +27,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestKerberosLoginHandler(t *testing.T) {
@@ -274,3 +285,157 @@ func TestKerberosLoginHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_SetListeners(t *testing.T) {
+	oldCfg := config.Listener{KerberosListenAddress: "127.0.0.1:15020", BOSAdvertisedHostSSL: "old-host"}
+	keptCfg := config.Listener{KerberosListenAddress: "127.0.0.1:15021", BOSAdvertisedHostSSL: "kept-host"}
+
+	mockAuth := newMockAuthService(t)
+	mockAuth.EXPECT().
+		KerberosLogin(mock.Anything, mock.Anything, mock.Anything, "changed-host").
+		Return(wire.SNACMessage{}, io.EOF)
+	mockAuth.EXPECT().
+		KerberosLogin(mock.Anything, mock.Anything, mock.Anything, "new-host").
+		Return(wire.SNACMessage{}, io.EOF)
+
+	srv := NewKerberosServer([]config.Listener{oldCfg, keptCfg}, slog.Default(), mockAuth)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, srv.ListenAndServe())
+	}()
+
+	post := func(addr string) {
+		b := &bytes.Buffer{}
+		assert.NoError(t, wire.MarshalBE(wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.Kerberos,
+				SubGroup:  wire.KerberosLoginRequest,
+			},
+			Body: wire.SNAC_0x050C_0x0002_KerberosLoginRequest{},
+		}, b))
+		resp, err := http.Post("http://"+addr, "application/x-snac", b)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		}
+	}
+
+	// wait for the server to start listening
+	for attempt := 0; attempt < 10; attempt++ {
+		if conn, err := net.Dial("tcp", oldCfg.KerberosListenAddress); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(time.Duration(5<<attempt) * time.Millisecond)
+	}
+
+	// remove one listener, change another and add a third
+	keptCfg.BOSAdvertisedHostSSL = "changed-host"
+	newCfg := config.Listener{KerberosListenAddress: "127.0.0.1:15022", BOSAdvertisedHostSSL: "new-host"}
+	assert.NoError(t, srv.SetListeners([]config.Listener{keptCfg, newCfg, {BOSListenAddress: ":5190"}}))
+
+	_, err := net.Dial("tcp", oldCfg.KerberosListenAddress)
+	assert.Error(t, err)
+	post(keptCfg.KerberosListenAddress)
+	post(newCfg.KerberosListenAddress)
+
+	// a listener that can't be opened leaves the listeners as they were
+	busy, err := net.Listen("tcp", "127.0.0.1:15023")
+	assert.NoError(t, err)
+	defer busy.Close()
+	err = srv.SetListeners([]config.Listener{{KerberosListenAddress: "127.0.0.1:15023"}})
+	assert.Error(t, err)
+	post(keptCfg.KerberosListenAddress)
+
+	assert.NoError(t, srv.Shutdown(context.Background()))
+	wg.Wait()
+}
+
+// writeTestCertificate writes a self-signed certificate and its key to a
+// temporary directory and returns their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ras.dev"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestServer_TLSListener(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	cfg := config.Listener{
+		KerberosListenAddressTLS: "127.0.0.1:15024",
+		BOSAdvertisedHostSSL:     "ras.dev:5193",
+		TLS:                      config.TLS{CertFile: certFile, KeyFile: keyFile},
+	}
+
+	mockAuth := newMockAuthService(t)
+	mockAuth.EXPECT().
+		KerberosLogin(mock.Anything, mock.Anything, mock.Anything, "ras.dev:5193").
+		Return(wire.SNACMessage{}, io.EOF)
+
+	srv := NewKerberosServer([]config.Listener{cfg}, slog.Default(), mockAuth)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, srv.ListenAndServe())
+	}()
+
+	// wait for the server to start listening
+	for attempt := 0; attempt < 10; attempt++ {
+		if conn, err := net.Dial("tcp", cfg.KerberosListenAddressTLS); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(time.Duration(5<<attempt) * time.Millisecond)
+	}
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, wire.MarshalBE(wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.Kerberos,
+			SubGroup:  wire.KerberosLoginRequest,
+		},
+		Body: wire.SNAC_0x050C_0x0002_KerberosLoginRequest{},
+	}, b))
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	resp, err := client.Post("https://"+cfg.KerberosListenAddressTLS, "application/x-snac", b)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	// a certificate that can't be loaded leaves the listeners as they were
+	badCfg := cfg
+	badCfg.TLS.CertFile = filepath.Join(t.TempDir(), "missing.pem")
+	assert.Error(t, srv.SetListeners([]config.Listener{badCfg}))
+	conn, err := tls.Dial("tcp", cfg.KerberosListenAddressTLS, &tls.Config{InsecureSkipVerify: true})
+	if assert.NoError(t, err) {
+		conn.Close()
+	}
+
+	assert.NoError(t, srv.Shutdown(context.Background()))
+	wg.Wait()
+}
@@ -40,6 +40,7 @@ type Handler struct {
 	FeedbagService
 	ICBMService
 	ICQService
+	InviteService
 	LocateService
 	ODirService
 	OServiceService
@@ -394,6 +395,15 @@ func (rt Handler) ICBMClientEvent(ctx context.Context, sess *state.Session, inFr
 	return rt.ICBMService.ClientEvent(ctx, sess, inFrame, inBody)
 }
 
+func (rt Handler) ICBMSinRetrieve(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, r io.Reader, _ ResponseWriter) error {
+	inBody := wire.SNAC_0x04_0x10_ICBMSinRetrieve{}
+	if err := wire.UnmarshalBE(&inBody, r); err != nil {
+		return err
+	}
+	rt.LogRequest(ctx, inFrame, inBody)
+	return rt.ICBMService.OfflineRetrieve(ctx, sess, inFrame)
+}
+
 func (rt Handler) ICQDBQuery(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, r io.Reader, rw ResponseWriter) error {
 	inBody := wire.SNAC_0x15_0x02_BQuery{}
 	if err := wire.UnmarshalBE(&inBody, r); err != nil {
@@ -598,6 +608,19 @@ func (rt Handler) ICQDBQuery(ctx context.Context, sess *state.Session, inFrame w
 	return nil
 }
 
+func (rt Handler) InviteRequestQuery(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, r io.Reader, rw ResponseWriter) error {
+	inBody := wire.SNAC_0x06_0x02_InviteRequestQuery{}
+	if err := wire.UnmarshalBE(&inBody, r); err != nil {
+		return err
+	}
+	outSNAC, err := rt.InviteService.RequestQuery(ctx, sess, inFrame, inBody)
+	if err != nil {
+		return err
+	}
+	rt.LogRequestAndResponse(ctx, inFrame, inBody, outSNAC.Frame, outSNAC.Body)
+	return rw.SendSNAC(outSNAC.Frame, outSNAC.Body)
+}
+
 func (rt Handler) LocateRightsQuery(ctx context.Context, _ *state.Session, inFrame wire.SNACFrame, _ io.Reader, rw ResponseWriter) error {
 	outSNAC := rt.LocateService.RightsQuery(ctx, inFrame)
 	rt.LogRequestAndResponse(ctx, inFrame, nil, outSNAC.Frame, outSNAC.Body)
@@ -1024,6 +1047,13 @@ func (rt Handler) Handle(ctx context.Context, server uint16, sess *state.Session
 			return rt.ICBMEvilRequest(ctx, sess, inFrame, r, rw)
 		case wire.ICBMParameterQuery:
 			return rt.ICBMParameterQuery(ctx, sess, inFrame, r, rw)
+		case wire.ICBMSinRetrieve:
+			return rt.ICBMSinRetrieve(ctx, sess, inFrame, r, rw)
+		}
+	case wire.Invite:
+		switch inFrame.SubGroup {
+		case wire.InviteRequestQuery:
+			return rt.InviteRequestQuery(ctx, sess, inFrame, r, rw)
 		}
 	case wire.Locate:
 		switch inFrame.SubGroup {
@@ -2324,6 +2324,59 @@ func TestHandler_ICBMClientEvent(t *testing.T) {
 	}
 }
 
+func TestHandler_ICBMSinRetrieve(t *testing.T) {
+	tests := []struct {
+		name          string
+		serviceError  error
+		expectedError error
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:          "service error",
+			serviceError:  assert.AnError,
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := wire.SNACMessage{
+				Frame: wire.SNACFrame{
+					FoodGroup: wire.ICBM,
+					SubGroup:  wire.ICBMSinRetrieve,
+				},
+				Body: wire.SNAC_0x04_0x10_ICBMSinRetrieve{},
+			}
+
+			svc := newMockICBMService(t)
+			svc.EXPECT().
+				OfflineRetrieve(mock.Anything, mock.Anything, input.Frame).
+				Return(tt.serviceError)
+
+			h := Handler{
+				ICBMService: svc,
+				RouteLogger: middleware.RouteLogger{
+					Logger: slog.Default(),
+				},
+			}
+
+			responseWriter := newMockResponseWriter(t)
+
+			buf := &bytes.Buffer{}
+			assert.NoError(t, wire.MarshalBE(input.Body, buf))
+
+			err := h.Handle(context.TODO(), wire.BOS, nil, input.Frame, buf, responseWriter, config.Listener{})
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestHandler_ICBMEvilRequest(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -4984,6 +5037,51 @@ func TestHandler_LocateGetDirInfo(t *testing.T) {
 	}
 }
 
+func TestHandler_InviteRequestQuery(t *testing.T) {
+	input := wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.Invite,
+			SubGroup:  wire.InviteRequestQuery,
+		},
+		Body: wire.SNAC_0x06_0x02_InviteRequestQuery{
+			TLVRestBlock: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.InviteTLVEmailAddress, "friend@example.com"),
+				},
+			},
+		},
+	}
+	output := wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.Invite,
+			SubGroup:  wire.InviteRequestReply,
+		},
+		Body: wire.SNAC_0x06_0x03_InviteRequestReply{},
+	}
+
+	svc := newMockInviteService(t)
+	svc.EXPECT().
+		RequestQuery(mock.Anything, mock.Anything, input.Frame, input.Body).
+		Return(output, nil)
+
+	h := Handler{
+		InviteService: svc,
+		RouteLogger: middleware.RouteLogger{
+			Logger: slog.Default(),
+		},
+	}
+
+	ss := newMockResponseWriter(t)
+	ss.EXPECT().
+		SendSNAC(output.Frame, output.Body).
+		Return(nil)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, wire.MarshalBE(input.Body, buf))
+
+	assert.NoError(t, h.Handle(context.TODO(), wire.BOS, nil, input.Frame, buf, ss, config.Listener{}))
+}
+
 func TestHandler_LocateRightsQuery(t *testing.T) {
 	tests := []struct {
 		name          string
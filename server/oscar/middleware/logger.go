@@ -7,7 +7,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/mk6i/retro-aim-server/config"
 	"github.com/mk6i/retro-aim-server/wire"
 )
 
@@ -19,23 +18,26 @@ var levelNames = map[slog.Leveler]string{
 	LevelTrace: "TRACE",
 }
 
-func NewLogger(cfg config.Config) *slog.Logger {
-	var level slog.Level
-	switch strings.ToLower(cfg.LogLevel) {
+// ParseLevel returns the log level named by a LOG_LEVEL value. Unrecognized
+// values map to slog.LevelInfo.
+func ParseLevel(name string) slog.Level {
+	switch strings.ToLower(name) {
 	case "trace":
-		level = LevelTrace
+		return LevelTrace
 	case "debug":
-		level = slog.LevelDebug
+		return slog.LevelDebug
 	case "warn":
-		level = slog.LevelWarn
+		return slog.LevelWarn
 	case "error":
-		level = slog.LevelError
-	case "info":
-		fallthrough
+		return slog.LevelError
 	default:
-		level = slog.LevelInfo
+		return slog.LevelInfo
 	}
+}
 
+// NewLogger creates a logger that writes records at or above level to
+// stdout. Pass a *slog.LevelVar to change the level while the server runs.
+func NewLogger(level slog.Leveler) *slog.Logger {
 	opts := &slog.HandlerOptions{
 		Level: level,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
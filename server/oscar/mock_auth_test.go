@@ -143,6 +143,187 @@ func (_c *mockAuthService_BUCPLogin_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// BUCPRegister provides a mock function with given fields: ctx, inBody, captcha
+func (_m *mockAuthService) BUCPRegister(ctx context.Context, inBody wire.SNAC_0x17_0x04_BUCPRegisterRequest, captcha state.Captcha) (wire.SNACMessage, error) {
+	ret := _m.Called(ctx, inBody, captcha)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BUCPRegister")
+	}
+
+	var r0 wire.SNACMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, wire.SNAC_0x17_0x04_BUCPRegisterRequest, state.Captcha) (wire.SNACMessage, error)); ok {
+		return rf(ctx, inBody, captcha)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, wire.SNAC_0x17_0x04_BUCPRegisterRequest, state.Captcha) wire.SNACMessage); ok {
+		r0 = rf(ctx, inBody, captcha)
+	} else {
+		r0 = ret.Get(0).(wire.SNACMessage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, wire.SNAC_0x17_0x04_BUCPRegisterRequest, state.Captcha) error); ok {
+		r1 = rf(ctx, inBody, captcha)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockAuthService_BUCPRegister_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BUCPRegister'
+type mockAuthService_BUCPRegister_Call struct {
+	*mock.Call
+}
+
+// BUCPRegister is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inBody wire.SNAC_0x17_0x04_BUCPRegisterRequest
+//   - captcha state.Captcha
+func (_e *mockAuthService_Expecter) BUCPRegister(ctx interface{}, inBody interface{}, captcha interface{}) *mockAuthService_BUCPRegister_Call {
+	return &mockAuthService_BUCPRegister_Call{Call: _e.mock.On("BUCPRegister", ctx, inBody, captcha)}
+}
+
+func (_c *mockAuthService_BUCPRegister_Call) Run(run func(ctx context.Context, inBody wire.SNAC_0x17_0x04_BUCPRegisterRequest, captcha state.Captcha)) *mockAuthService_BUCPRegister_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(wire.SNAC_0x17_0x04_BUCPRegisterRequest), args[2].(state.Captcha))
+	})
+	return _c
+}
+
+func (_c *mockAuthService_BUCPRegister_Call) Return(_a0 wire.SNACMessage, _a1 error) *mockAuthService_BUCPRegister_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockAuthService_BUCPRegister_Call) RunAndReturn(run func(context.Context, wire.SNAC_0x17_0x04_BUCPRegisterRequest, state.Captcha) (wire.SNACMessage, error)) *mockAuthService_BUCPRegister_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BUCPRegistrationImage provides a mock function with given fields: ctx
+func (_m *mockAuthService) BUCPRegistrationImage(ctx context.Context) (wire.SNACMessage, state.Captcha, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BUCPRegistrationImage")
+	}
+
+	var r0 wire.SNACMessage
+	var r1 state.Captcha
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context) (wire.SNACMessage, state.Captcha, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) wire.SNACMessage); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(wire.SNACMessage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) state.Captcha); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Get(1).(state.Captcha)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context) error); ok {
+		r2 = rf(ctx)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// mockAuthService_BUCPRegistrationImage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BUCPRegistrationImage'
+type mockAuthService_BUCPRegistrationImage_Call struct {
+	*mock.Call
+}
+
+// BUCPRegistrationImage is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *mockAuthService_Expecter) BUCPRegistrationImage(ctx interface{}) *mockAuthService_BUCPRegistrationImage_Call {
+	return &mockAuthService_BUCPRegistrationImage_Call{Call: _e.mock.On("BUCPRegistrationImage", ctx)}
+}
+
+func (_c *mockAuthService_BUCPRegistrationImage_Call) Run(run func(ctx context.Context)) *mockAuthService_BUCPRegistrationImage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *mockAuthService_BUCPRegistrationImage_Call) Return(_a0 wire.SNACMessage, _a1 state.Captcha, _a2 error) *mockAuthService_BUCPRegistrationImage_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *mockAuthService_BUCPRegistrationImage_Call) RunAndReturn(run func(context.Context) (wire.SNACMessage, state.Captcha, error)) *mockAuthService_BUCPRegistrationImage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BUCPSecurID provides a mock function with given fields: ctx, loginRequest, bodyIn, newUserFn, advertisedHost
+func (_m *mockAuthService) BUCPSecurID(ctx context.Context, loginRequest wire.SNAC_0x17_0x02_BUCPLoginRequest, bodyIn wire.SNAC_0x17_0x0B_BUCPSecuridResponse, newUserFn func(state.DisplayScreenName) (state.User, error), advertisedHost string) (wire.SNACMessage, error) {
+	ret := _m.Called(ctx, loginRequest, bodyIn, newUserFn, advertisedHost)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BUCPSecurID")
+	}
+
+	var r0 wire.SNACMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, wire.SNAC_0x17_0x02_BUCPLoginRequest, wire.SNAC_0x17_0x0B_BUCPSecuridResponse, func(state.DisplayScreenName) (state.User, error), string) (wire.SNACMessage, error)); ok {
+		return rf(ctx, loginRequest, bodyIn, newUserFn, advertisedHost)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, wire.SNAC_0x17_0x02_BUCPLoginRequest, wire.SNAC_0x17_0x0B_BUCPSecuridResponse, func(state.DisplayScreenName) (state.User, error), string) wire.SNACMessage); ok {
+		r0 = rf(ctx, loginRequest, bodyIn, newUserFn, advertisedHost)
+	} else {
+		r0 = ret.Get(0).(wire.SNACMessage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, wire.SNAC_0x17_0x02_BUCPLoginRequest, wire.SNAC_0x17_0x0B_BUCPSecuridResponse, func(state.DisplayScreenName) (state.User, error), string) error); ok {
+		r1 = rf(ctx, loginRequest, bodyIn, newUserFn, advertisedHost)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockAuthService_BUCPSecurID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BUCPSecurID'
+type mockAuthService_BUCPSecurID_Call struct {
+	*mock.Call
+}
+
+// BUCPSecurID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - loginRequest wire.SNAC_0x17_0x02_BUCPLoginRequest
+//   - bodyIn wire.SNAC_0x17_0x0B_BUCPSecuridResponse
+//   - newUserFn func(state.DisplayScreenName) (state.User, error)
+//   - advertisedHost string
+func (_e *mockAuthService_Expecter) BUCPSecurID(ctx interface{}, loginRequest interface{}, bodyIn interface{}, newUserFn interface{}, advertisedHost interface{}) *mockAuthService_BUCPSecurID_Call {
+	return &mockAuthService_BUCPSecurID_Call{Call: _e.mock.On("BUCPSecurID", ctx, loginRequest, bodyIn, newUserFn, advertisedHost)}
+}
+
+func (_c *mockAuthService_BUCPSecurID_Call) Run(run func(ctx context.Context, loginRequest wire.SNAC_0x17_0x02_BUCPLoginRequest, bodyIn wire.SNAC_0x17_0x0B_BUCPSecuridResponse, newUserFn func(state.DisplayScreenName) (state.User, error), advertisedHost string)) *mockAuthService_BUCPSecurID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(wire.SNAC_0x17_0x02_BUCPLoginRequest), args[2].(wire.SNAC_0x17_0x0B_BUCPSecuridResponse), args[3].(func(state.DisplayScreenName) (state.User, error)), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *mockAuthService_BUCPSecurID_Call) Return(_a0 wire.SNACMessage, _a1 error) *mockAuthService_BUCPSecurID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockAuthService_BUCPSecurID_Call) RunAndReturn(run func(context.Context, wire.SNAC_0x17_0x02_BUCPLoginRequest, wire.SNAC_0x17_0x0B_BUCPSecuridResponse, func(state.DisplayScreenName) (state.User, error), string) (wire.SNACMessage, error)) *mockAuthService_BUCPSecurID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CrackCookie provides a mock function with given fields: authCookie
 func (_m *mockAuthService) CrackCookie(authCookie []byte) (state.ServerCookie, error) {
 	ret := _m.Called(authCookie)
@@ -494,9 +675,9 @@ func (_c *mockAuthService_RetrieveBOSSession_Call) RunAndReturn(run func(context
 	return _c
 }
 
-// Signout provides a mock function with given fields: ctx, sess
-func (_m *mockAuthService) Signout(ctx context.Context, sess *state.Session) {
-	_m.Called(ctx, sess)
+// Signout provides a mock function with given fields: ctx, sess, cleanup
+func (_m *mockAuthService) Signout(ctx context.Context, sess *state.Session, cleanup func(bool)) {
+	_m.Called(ctx, sess, cleanup)
 }
 
 // mockAuthService_Signout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Signout'
@@ -507,13 +688,14 @@ type mockAuthService_Signout_Call struct {
 // Signout is a helper method to define mock.On call
 //   - ctx context.Context
 //   - sess *state.Session
-func (_e *mockAuthService_Expecter) Signout(ctx interface{}, sess interface{}) *mockAuthService_Signout_Call {
-	return &mockAuthService_Signout_Call{Call: _e.mock.On("Signout", ctx, sess)}
+//   - cleanup func(bool)
+func (_e *mockAuthService_Expecter) Signout(ctx interface{}, sess interface{}, cleanup interface{}) *mockAuthService_Signout_Call {
+	return &mockAuthService_Signout_Call{Call: _e.mock.On("Signout", ctx, sess, cleanup)}
 }
 
-func (_c *mockAuthService_Signout_Call) Run(run func(ctx context.Context, sess *state.Session)) *mockAuthService_Signout_Call {
+func (_c *mockAuthService_Signout_Call) Run(run func(ctx context.Context, sess *state.Session, cleanup func(bool))) *mockAuthService_Signout_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*state.Session))
+		run(args[0].(context.Context), args[1].(*state.Session), args[2].(func(bool)))
 	})
 	return _c
 }
@@ -523,7 +705,7 @@ func (_c *mockAuthService_Signout_Call) Return() *mockAuthService_Signout_Call {
 	return _c
 }
 
-func (_c *mockAuthService_Signout_Call) RunAndReturn(run func(context.Context, *state.Session)) *mockAuthService_Signout_Call {
+func (_c *mockAuthService_Signout_Call) RunAndReturn(run func(context.Context, *state.Session, func(bool))) *mockAuthService_Signout_Call {
 	_c.Run(run)
 	return _c
 }
@@ -1,14 +1,15 @@
 package oscar
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net"
 	"net/netip"
+	"os"
 	"sync"
 	"time"
 
@@ -22,10 +23,12 @@ import (
 	"github.com/mk6i/retro-aim-server/wire"
 )
 
+var errServerShutdown = errors.New("server is shut down")
+
 func NewServer(
 	authService AuthService,
 	buddyListRegistry BuddyListRegistry,
-	chatSessionManager *state.InMemoryChatSessionManager,
+	chatSessionManager ChatSessionManager,
 	departureNotifier DepartureNotifier,
 	logger *slog.Logger,
 	onlineNotifier OnlineNotifier,
@@ -36,6 +39,7 @@ func NewServer(
 	listenerCfg []config.Listener,
 	recalcWarning func(ctx context.Context, sess *state.Session) error,
 	lowerWarnLevel func(ctx context.Context, sess *state.Session),
+	keepAlive *state.KeepAliveMonitor,
 ) *Server {
 	oscarSvc := oscarServer{
 		AuthService:        authService,
@@ -50,6 +54,7 @@ func NewServer(
 		IPRateLimiter:      limiter,
 		recalcWarning:      recalcWarning,
 		lowerWarnLevel:     lowerWarnLevel,
+		KeepAlive:          keepAlive,
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -59,6 +64,7 @@ func NewServer(
 		conns:          make(map[net.Conn]struct{}),
 		handler:        oscarSvc.routeConnection,
 		listenerCfg:    listenerCfg,
+		listeners:      make(map[string]*activeListener),
 		logger:         logger,
 		shutdownCancel: cancel,
 		shutdownCtx:    ctx,
@@ -69,7 +75,8 @@ type Server struct {
 	logger *slog.Logger
 
 	listenerCfg []config.Listener
-	listeners   []net.Listener
+	listenMu    sync.Mutex
+	listeners   map[string]*activeListener // keyed by listen address
 
 	connMu sync.Mutex
 	conns  map[net.Conn]struct{}
@@ -84,33 +91,129 @@ type Server struct {
 	handler func(ctx context.Context, conn net.Conn, listener config.Listener) error
 }
 
+// activeListener is a socket that accepts OSCAR connections and the settings
+// passed to the connections it accepts.
+type activeListener struct {
+	ln  net.Listener
+	cfg config.Listener
+	// tlsConfig is set if the socket accepts connections over TLS
+	tlsConfig *tls.Config
+}
+
 func (s *Server) ListenAndServe() error {
-	for _, listenCfg := range s.listenerCfg {
-		ln, err := net.Listen("tcp", listenCfg.BOSListenAddress)
+	err := s.SetListeners(s.listenerCfg)
+	switch {
+	case errors.Is(err, errServerShutdown):
+		return nil // shut down before it started
+	case err != nil:
+		s.shutdownCancel()
+		return err
+	}
+
+	<-s.closed // block until Shutdown is called
+	return nil
+}
+
+// SetListeners starts accepting connections on the listeners in listenerCfg
+// that aren't open yet and stops accepting connections on open listeners
+// that aren't in listenerCfg. Connections accepted by a closed listener stay
+// open. Open listeners whose settings changed apply the new settings to
+// connections accepted from then on. If a new listener can't be opened, the
+// open listeners are left as they were.
+func (s *Server) SetListeners(listenerCfg []config.Listener) error {
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+
+	if s.shutdownCtx.Err() != nil {
+		return errServerShutdown
+	}
+
+	// the plain and TLS listen addresses of each listener
+	want := make(map[string]config.Listener, len(listenerCfg))
+	wantTLS := make(map[string]bool)
+	for _, cfg := range listenerCfg {
+		want[cfg.BOSListenAddress] = cfg
+		if cfg.BOSListenAddressTLS != "" {
+			want[cfg.BOSListenAddressTLS] = cfg
+			wantTLS[cfg.BOSListenAddressTLS] = true
+		}
+	}
+
+	// load the certificates of new TLS listeners and of TLS listeners whose
+	// certificate settings changed
+	tlsConfigs := make(map[string]*tls.Config)
+	for addr := range wantTLS {
+		if l, ok := s.listeners[addr]; ok && l.tlsConfig != nil && l.cfg.TLS == want[addr].TLS {
+			continue
+		}
+		settings := want[addr].TLS
+		tlsConfig, err := state.NewTLSConfig(settings.CertFile, settings.KeyFile, settings.LegacyCiphers)
 		if err != nil {
-			s.cleanupListeners()
-			s.shutdownCancel()
-			return fmt.Errorf("failed to listen on %s: %w", listenCfg.BOSListenAddress, err)
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
 		}
+		tlsConfigs[addr] = tlsConfig
+	}
 
-		args := []any{
-			"listen_address", listenCfg.BOSListenAddress,
-			"advertised_host_plain", listenCfg.BOSAdvertisedHostPlain,
+	// open the new listeners first so that a failure leaves the server as
+	// it was
+	opened := make(map[string]*activeListener)
+	for addr, cfg := range want {
+		if _, ok := s.listeners[addr]; ok {
+			continue
 		}
-		if listenCfg.HasSSL {
-			args = append(args, "advertised_host_ssl", listenCfg.BOSAdvertisedHostSSL)
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, l := range opened {
+				_ = l.ln.Close()
+			}
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
 		}
-		s.logger.Info("starting server", args...)
+		opened[addr] = &activeListener{ln: ln, cfg: cfg, tlsConfig: tlsConfigs[addr]}
+	}
+
+	for addr, l := range s.listeners {
+		cfg, ok := want[addr]
+		switch {
+		case !ok:
+			_ = l.ln.Close()
+			delete(s.listeners, addr)
+			s.logger.Info("stopped listener", "listen_address", addr)
+		case cfg != l.cfg || wantTLS[addr] != (l.tlsConfig != nil):
+			l.cfg = cfg
+			if !wantTLS[addr] {
+				l.tlsConfig = nil
+			} else if tlsConfig, ok := tlsConfigs[addr]; ok {
+				l.tlsConfig = tlsConfig
+			}
+			s.logger.Info("updated listener", listenerLogArgs(addr, l)...)
+		}
+	}
 
-		s.listeners = append(s.listeners, ln)
+	for addr, l := range opened {
+		s.listeners[addr] = l
+		s.logger.Info("starting server", listenerLogArgs(addr, l)...)
 		s.listenWg.Add(1)
-		go s.acceptLoop(ln, listenCfg)
+		go s.acceptLoop(l)
 	}
 
-	<-s.closed // block until Shutdown is called
 	return nil
 }
 
+// listenerLogArgs returns the log attributes that describe a listener.
+func listenerLogArgs(addr string, l *activeListener) []any {
+	args := []any{
+		"listen_address", addr,
+		"advertised_host_plain", l.cfg.BOSAdvertisedHostPlain,
+	}
+	if l.cfg.HasSSL {
+		args = append(args, "advertised_host_ssl", l.cfg.BOSAdvertisedHostSSL)
+	}
+	if l.tlsConfig != nil {
+		args = append(args, "tls", true)
+	}
+	return args
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Debug("Initiating graceful shutdown...")
 	s.shutdownCancel()
@@ -136,11 +239,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-func (s *Server) acceptLoop(ln net.Listener, listener config.Listener) {
+func (s *Server) acceptLoop(l *activeListener) {
 	defer s.listenWg.Done()
 
 	for {
-		conn, err := ln.Accept()
+		conn, err := l.ln.Accept()
 		if err != nil {
 			if errors.Is(err, net.ErrClosed) {
 				return
@@ -149,13 +252,23 @@ func (s *Server) acceptLoop(ln net.Listener, listener config.Listener) {
 			continue
 		}
 
+		// read the settings under lock because SetListeners may update them
+		s.listenMu.Lock()
+		cfg := l.cfg
+		tlsConfig := l.tlsConfig
+		s.listenMu.Unlock()
+
+		if tlsConfig != nil {
+			conn = tls.Server(conn, tlsConfig)
+		}
+
 		// track connection
 		s.connMu.Lock()
 		s.conns[conn] = struct{}{}
 		s.connMu.Unlock()
 
 		s.connWg.Add(1)
-		go s.handleConnection(s.shutdownCtx, conn, listener)
+		go s.handleConnection(s.shutdownCtx, conn, cfg)
 	}
 }
 
@@ -175,10 +288,12 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn, listener c
 }
 
 func (s *Server) cleanupListeners() {
-	for _, ln := range s.listeners {
-		_ = ln.Close()
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+	for addr, l := range s.listeners {
+		_ = l.ln.Close()
+		delete(s.listeners, addr)
 	}
-	s.listeners = nil
 }
 
 type oscarServer struct {
@@ -194,6 +309,7 @@ type oscarServer struct {
 	*IPRateLimiter
 	recalcWarning  func(ctx context.Context, sess *state.Session) error
 	lowerWarnLevel func(ctx context.Context, sess *state.Session)
+	KeepAlive      *state.KeepAliveMonitor
 }
 
 func (s oscarServer) routeConnection(ctx context.Context, conn net.Conn, listener config.Listener) error {
@@ -203,7 +319,7 @@ func (s oscarServer) routeConnection(ctx context.Context, conn net.Conn, listene
 		return err
 	}
 
-	flapc := wire.NewFlapClient(100, conn, conn)
+	flapc := wire.NewFlapClient(100, wire.WithDecodeLimits(conn, listener.DecodeLimits), conn)
 
 	if err := flapc.SendSignonFrame(nil); err != nil {
 		return err
@@ -258,17 +374,22 @@ func (s oscarServer) connectToOSCARService(
 			sess.Close()
 			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 			defer cancel()
-			if err := s.DepartureNotifier.BroadcastBuddyDeparted(ctx, sess); err != nil {
-				s.Logger.ErrorContext(ctx, "error sending buddy departure notifications", "err", err.Error())
-			}
-			// buddy list must be cleared before session is closed, otherwise
-			// there will be a race condition that could cause the buddy list
-			// be prematurely deleted.
-			if err := s.BuddyListRegistry.UnregisterBuddyList(ctx, sess.IdentScreenName()); err != nil {
-				s.Logger.ErrorContext(ctx, "error removing buddy list entry", "err", err.Error())
-			}
-			s.ChatSessionManager.RemoveUserFromAllChats(sess.IdentScreenName())
-			s.Signout(ctx, sess)
+			s.Signout(ctx, sess, func(lastInstance bool) {
+				if err := s.DepartureNotifier.BroadcastBuddyDeparted(ctx, sess); err != nil {
+					s.Logger.ErrorContext(ctx, "error sending buddy departure notifications", "err", err.Error())
+				}
+				// the buddy list and chat rooms belong to the user's other
+				// sessions if they're still signed on elsewhere
+				if lastInstance {
+					// buddy list must be cleared before session is removed,
+					// otherwise there will be a race condition that could
+					// cause the buddy list be prematurely deleted.
+					if err := s.BuddyListRegistry.UnregisterBuddyList(ctx, sess.IdentScreenName()); err != nil {
+						s.Logger.ErrorContext(ctx, "error removing buddy list entry", "err", err.Error())
+					}
+					s.ChatSessionManager.RemoveUserFromAllChats(sess.IdentScreenName())
+				}
+			})
 		}()
 		remoteAddr, ok := ctx.Value("ip").(string)
 		if ok {
@@ -404,6 +525,10 @@ func (s oscarServer) processFLAPAuth(
 
 func (s oscarServer) processBUCPAuth(ctx context.Context, flapc *wire.FlapClient, advertisedHost string) error {
 	frames := 0
+	// the registration image most recently sent on this connection
+	var captcha state.Captcha
+	// the login request awaiting a SecurID code on this connection
+	var securIDLogin *wire.SNAC_0x17_0x02_BUCPLoginRequest
 
 	for {
 		frame, err := flapc.ReceiveFLAP()
@@ -424,7 +549,7 @@ func (s oscarServer) processBUCPAuth(ctx context.Context, flapc *wire.FlapClient
 		case wire.FLAPFrameKeepAlive:
 			s.Logger.Debug("received flap keepalive frame")
 		case wire.FLAPFrameData:
-			buf := bytes.NewReader(frame.Payload)
+			buf := flapc.PayloadReader(frame)
 			fr := wire.SNACFrame{}
 			if err := wire.UnmarshalBE(&fr, buf); err != nil {
 				return err
@@ -457,8 +582,56 @@ func (s oscarServer) processBUCPAuth(ctx context.Context, flapc *wire.FlapClient
 				if err != nil {
 					return err
 				}
+				if err := flapc.SendSNAC(outSNAC.Frame, outSNAC.Body); err != nil {
+					return err
+				}
+				if outSNAC.Frame.SubGroup != wire.BUCPSecuridRequest {
+					return nil
+				}
+				// wait for the client to send the one-time code
+				securIDLogin = &loginRequest
+			case fr.FoodGroup == wire.BUCP && fr.SubGroup == wire.BUCPSecuridResponse:
+				if securIDLogin == nil {
+					s.Logger.Debug("received SecurID without a pending login")
+					return io.EOF
+				}
+				securIDResponse := wire.SNAC_0x17_0x0B_BUCPSecuridResponse{}
+				if err := wire.UnmarshalBE(&securIDResponse, buf); err != nil {
+					return err
+				}
+				outSNAC, err := s.BUCPSecurID(ctx, *securIDLogin, securIDResponse, state.NewStubUser, advertisedHost)
+				if err != nil {
+					return err
+				}
 
 				return flapc.SendSNAC(outSNAC.Frame, outSNAC.Body)
+			case fr.FoodGroup == wire.BUCP && fr.SubGroup == wire.BUCPRegistrationImageRequest:
+				imageRequest := wire.SNAC_0x17_0x0C_BUCPRegistrationImageRequest{}
+				if err := wire.UnmarshalBE(&imageRequest, buf); err != nil {
+					return err
+				}
+				outSNAC, newCaptcha, err := s.BUCPRegistrationImage(ctx)
+				if err != nil {
+					return err
+				}
+				captcha = newCaptcha
+				if err := flapc.SendSNAC(outSNAC.Frame, outSNAC.Body); err != nil {
+					return err
+				}
+			case fr.FoodGroup == wire.BUCP && fr.SubGroup == wire.BUCPRegisterRequest:
+				registerRequest := wire.SNAC_0x17_0x04_BUCPRegisterRequest{}
+				if err := wire.UnmarshalBE(&registerRequest, buf); err != nil {
+					return err
+				}
+				outSNAC, err := s.BUCPRegister(ctx, registerRequest, captcha)
+				if err != nil {
+					return err
+				}
+				// each registration image is good for one attempt
+				captcha = state.Captcha{}
+				if err := flapc.SendSNAC(outSNAC.Frame, outSNAC.Body); err != nil {
+					return err
+				}
 			default:
 				s.Logger.Debug("unexpected SNAC received during login",
 					"foodgroup", wire.FoodGroupName(fr.FoodGroup),
@@ -484,12 +657,17 @@ func sendInvalidSNACErr(frameIn wire.SNACFrame, rw ResponseWriter) error {
 	return rw.SendSNAC(frameOut, bodyOut)
 }
 
+// readDeadliner is implemented by connections that support read timeouts.
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
 // dispatchIncomingMessages receives incoming messages and sends them to the
 // appropriate message handler. Messages from the client are sent to the
 // router. Messages relayed from the user session are forwarded to the client.
 // This function ensures that the same sequence number is incremented for both
-// types of messages. The function terminates upon receiving a connection error
-// or when the session closes.
+// types of messages. The function terminates upon receiving a connection error,
+// when the client stops responding, or when the session closes.
 func (s oscarServer) dispatchIncomingMessages(
 	ctx context.Context,
 	fg uint16,
@@ -511,9 +689,18 @@ func (s oscarServer) dispatchIncomingMessages(
 		defer close(msgCh)
 		defer close(errCh)
 
+		rd, canTimeout := r.(readDeadliner)
 		for {
-			frame := wire.FLAPFrame{}
-			if err := wire.UnmarshalBE(&frame, r); err != nil {
+			// close the connection if the client sends nothing, not even a
+			// keepalive, before the idle timeout
+			if deadline := s.KeepAlive.ReadDeadline(time.Now()); canTimeout && !deadline.IsZero() {
+				if err := rd.SetReadDeadline(deadline); err != nil {
+					errCh <- err
+					return
+				}
+			}
+			frame, err := flapc.ReceiveFLAP()
+			if err != nil {
 				errCh <- err
 				return
 			}
@@ -521,15 +708,25 @@ func (s oscarServer) dispatchIncomingMessages(
 		}
 	}()
 
+	// periodically probe the client so that a dead connection surfaces as a
+	// write error
+	var keepAliveCh <-chan time.Time
+	if interval := s.KeepAlive.Interval(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		keepAliveCh = ticker.C
+	}
+
 	for {
 		select {
 		case flap, ok := <-msgCh:
 			if !ok {
-				return nil
+				// the reader stopped, the reason is waiting in errCh
+				return s.clientReadErr(ctx, <-errCh)
 			}
 			switch flap.FrameType {
 			case wire.FLAPFrameData:
-				flapBuf := bytes.NewBuffer(flap.Payload)
+				flapBuf := flapc.PayloadReader(flap)
 
 				inFrame := wire.SNACFrame{}
 				if err := wire.UnmarshalBE(&inFrame, flapBuf); err != nil {
@@ -572,6 +769,11 @@ func (s oscarServer) dispatchIncomingMessages(
 			default:
 				return fmt.Errorf("got unknown FLAP frame type. flap: %v", flap)
 			}
+		case <-keepAliveCh:
+			if err := flapc.SendKeepAliveFrame(); err != nil {
+				return fmt.Errorf("unable to send keepalive: %w", err)
+			}
+			s.KeepAlive.ProbeSent()
 		case <-time.After(1 * time.Second):
 			updates := s.RateLimitUpdater.RateLimitUpdates(ctx, sess, time.Now())
 			for _, update := range updates {
@@ -609,14 +811,25 @@ func (s oscarServer) dispatchIncomingMessages(
 			}
 			return nil
 		case err := <-errCh:
-			if !errors.Is(io.EOF, err) {
-				s.Logger.ErrorContext(ctx, "client disconnected with error", "err", err)
-			}
-			return nil
+			return s.clientReadErr(ctx, err)
 		}
 	}
 }
 
+// clientReadErr logs the reason the client connection could no longer be
+// read, counting connections closed because the client stopped responding.
+func (s oscarServer) clientReadErr(ctx context.Context, err error) error {
+	switch {
+	case err == nil, errors.Is(err, io.EOF):
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		s.Logger.InfoContext(ctx, "closing connection because the client stopped responding")
+		s.KeepAlive.Reaped()
+	default:
+		s.Logger.ErrorContext(ctx, "client disconnected with error", "err", err)
+	}
+	return nil
+}
+
 // IPRateLimiter enforces a per-IP rate limit using a token bucket algorithm.
 // It caches individual rate limiters by IP address and supports tagging requests
 // as originating from the BUCP or FLAP auth.
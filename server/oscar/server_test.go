@@ -4,9 +4,20 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
 	"io"
 	"log/slog"
+	"math/big"
 	"net"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -14,6 +25,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/time/rate"
 
 	"github.com/mk6i/retro-aim-server/config"
@@ -57,6 +69,7 @@ func TestServer_ListenAndServeAndShutdown(t *testing.T) {
 		cfg,
 		func(ctx context.Context, sess *state.Session) error { return nil },
 		func(ctx context.Context, sess *state.Session) {},
+		nil,
 	)
 
 	server.handler = func(ctx context.Context, conn net.Conn, listener config.Listener) error {
@@ -131,6 +144,181 @@ func TestServer_ListenAndServeAndShutdown(t *testing.T) {
 	assert.ElementsMatch(t, received, responses)
 }
 
+func TestServer_SetListeners(t *testing.T) {
+	received := make(chan string, 10)
+
+	oldCfg := config.Listener{BOSListenAddress: "127.0.0.1:15010", BOSAdvertisedHostPlain: "old-host"}
+	keptCfg := config.Listener{BOSListenAddress: "127.0.0.1:15011", BOSAdvertisedHostPlain: "kept-host"}
+
+	server := NewServer(nil, nil, nil, nil, slog.Default(), nil, nil, nil, wire.DefaultSNACRateLimits(), nil,
+		[]config.Listener{oldCfg, keptCfg}, nil, nil, nil)
+	server.handler = func(ctx context.Context, conn net.Conn, listener config.Listener) error {
+		go func() {
+			<-ctx.Done()
+			_ = conn.Close()
+		}()
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return nil
+			}
+			received <- listener.BOSAdvertisedHostPlain + ":" + strings.TrimSpace(line)
+		}
+	}
+
+	shutdownCh := make(chan struct{})
+	go func() {
+		defer close(shutdownCh)
+		assert.NoError(t, server.ListenAndServe())
+	}()
+
+	dial := func(addr string) net.Conn {
+		for attempt := 0; attempt < 10; attempt++ {
+			if conn, err := net.Dial("tcp", addr); err == nil {
+				return conn
+			}
+			time.Sleep(time.Duration(5<<attempt) * time.Millisecond)
+		}
+		t.Fatalf("server not listening on %s", addr)
+		return nil
+	}
+
+	// open a connection on the listener that's about to be removed
+	oldConn := dial(oldCfg.BOSListenAddress)
+	defer oldConn.Close()
+	_, err := oldConn.Write([]byte("hello\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "old-host:hello", <-received)
+
+	// remove one listener, change another and add a third
+	keptCfg.BOSAdvertisedHostPlain = "changed-host"
+	newCfg := config.Listener{BOSListenAddress: "127.0.0.1:15012", BOSAdvertisedHostPlain: "new-host"}
+	assert.NoError(t, server.SetListeners([]config.Listener{keptCfg, newCfg}))
+
+	// the connection accepted by the removed listener stays open
+	_, err = oldConn.Write([]byte("still here\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "old-host:still here", <-received)
+
+	// the removed listener no longer accepts connections
+	_, err = net.Dial("tcp", oldCfg.BOSListenAddress)
+	assert.Error(t, err)
+
+	// new connections get the new settings
+	for _, addr := range []string{keptCfg.BOSListenAddress, newCfg.BOSListenAddress} {
+		conn := dial(addr)
+		_, err = conn.Write([]byte("hi\n"))
+		assert.NoError(t, err)
+		conn.Close()
+	}
+	assert.ElementsMatch(t, []string{"changed-host:hi", "new-host:hi"}, []string{<-received, <-received})
+
+	// a listener that can't be opened leaves the listeners as they were
+	busy, err := net.Listen("tcp", "127.0.0.1:15013")
+	assert.NoError(t, err)
+	defer busy.Close()
+	err = server.SetListeners([]config.Listener{{BOSListenAddress: "127.0.0.1:15013"}})
+	assert.Error(t, err)
+	conn := dial(keptCfg.BOSListenAddress)
+	_, err = conn.Write([]byte("still open\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "changed-host:still open", <-received)
+	conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	assert.NoError(t, server.Shutdown(ctx))
+	<-shutdownCh
+}
+
+// writeTestCertificate writes a self-signed certificate and its key to
+// files in a temporary directory and returns their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ras.dev"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestServer_TLSListener(t *testing.T) {
+	received := make(chan string, 10)
+
+	certFile, keyFile := writeTestCertificate(t)
+	cfg := config.Listener{
+		BOSListenAddress:     "127.0.0.1:15016",
+		BOSListenAddressTLS:  "127.0.0.1:15017",
+		BOSAdvertisedHostSSL: "ras.dev:15017",
+		HasSSL:               true,
+		TLS:                  config.TLS{CertFile: certFile, KeyFile: keyFile},
+	}
+
+	server := NewServer(nil, nil, nil, nil, slog.Default(), nil, nil, nil, wire.DefaultSNACRateLimits(), nil,
+		[]config.Listener{cfg}, nil, nil, nil)
+	server.handler = func(ctx context.Context, conn net.Conn, listener config.Listener) error {
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return nil
+		}
+		_, isTLS := conn.(*tls.Conn)
+		received <- fmt.Sprintf("%s tls=%t", strings.TrimSpace(line), isTLS)
+		return nil
+	}
+
+	shutdownCh := make(chan struct{})
+	go func() {
+		defer close(shutdownCh)
+		assert.NoError(t, server.ListenAndServe())
+	}()
+
+	var conn net.Conn
+	var err error
+	for attempt := 0; attempt < 10; attempt++ {
+		conn, err = tls.Dial("tcp", cfg.BOSListenAddressTLS, &tls.Config{InsecureSkipVerify: true})
+		if err == nil {
+			break
+		}
+		time.Sleep(time.Duration(5<<attempt) * time.Millisecond)
+	}
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("hello\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello tls=true", <-received)
+	conn.Close()
+
+	conn, err = net.Dial("tcp", cfg.BOSListenAddress)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("hello\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello tls=false", <-received)
+	conn.Close()
+
+	// a missing certificate leaves the listeners as they were
+	cfg.TLS.CertFile = filepath.Join(t.TempDir(), "missing.pem")
+	assert.Error(t, server.SetListeners([]config.Listener{cfg}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	assert.NoError(t, server.Shutdown(ctx))
+	<-shutdownCh
+}
+
 type fakeConn struct {
 	net.Conn // embed the real connection
 	local    net.Addr
@@ -236,6 +424,198 @@ func TestOscarServer_RouteConnection_Auth_BUCP(t *testing.T) {
 	wg.Wait()
 }
 
+func TestOscarServer_RouteConnection_Auth_BUCPSecurID(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	addr, err := net.ResolveTCPAddr("tcp", "127.0.0.1:8080")
+	assert.NoError(t, err)
+
+	clientFake := fakeConn{
+		Conn:   serverConn,
+		local:  addr,
+		remote: addr,
+	}
+
+	loginRequest := wire.SNAC_0x17_0x02_BUCPLoginRequest{
+		TLVRestBlock: wire.TLVRestBlock{
+			TLVList: wire.TLVList{
+				wire.NewTLVBE(wire.LoginTLVTagsScreenName, "screenName"),
+			},
+		},
+	}
+
+	go func() {
+		defer func() {
+			_ = clientConn.Close()
+		}()
+
+		// < receive FLAPSignonFrame
+		flap := wire.FLAPFrame{}
+		assert.NoError(t, wire.UnmarshalBE(&flap, clientConn))
+		flapSignonFrame := wire.FLAPSignonFrame{}
+		assert.NoError(t, wire.UnmarshalBE(&flapSignonFrame, bytes.NewBuffer(flap.Payload)))
+
+		// > send FLAPSignonFrame
+		flapSignonFrame = wire.FLAPSignonFrame{
+			FLAPVersion: 1,
+		}
+		buf := &bytes.Buffer{}
+		assert.NoError(t, wire.MarshalBE(flapSignonFrame, buf))
+		flap = wire.FLAPFrame{
+			StartMarker: 42,
+			FrameType:   wire.FLAPFrameSignon,
+			Payload:     buf.Bytes(),
+		}
+		assert.NoError(t, wire.MarshalBE(flap, clientConn))
+
+		// > send SNAC_0x17_0x02_BUCPLoginRequest
+		flapc := wire.NewFlapClient(0, clientConn, clientConn)
+		frame := wire.SNACFrame{
+			FoodGroup: wire.BUCP,
+			SubGroup:  wire.BUCPLoginRequest,
+		}
+		assert.NoError(t, flapc.SendSNAC(frame, loginRequest))
+
+		// < receive SNAC_0x17_0x0A_BUCPSecuridRequest
+		frame = wire.SNACFrame{}
+		assert.NoError(t, flapc.ReceiveSNAC(&frame, &wire.SNAC_0x17_0x0A_BUCPSecuridRequest{}))
+		assert.Equal(t, wire.SNACFrame{FoodGroup: wire.BUCP, SubGroup: wire.BUCPSecuridRequest}, frame)
+
+		// > send SNAC_0x17_0x0B_BUCPSecuridResponse
+		frame = wire.SNACFrame{
+			FoodGroup: wire.BUCP,
+			SubGroup:  wire.BUCPSecuridResponse,
+		}
+		assert.NoError(t, flapc.SendSNAC(frame, wire.SNAC_0x17_0x0B_BUCPSecuridResponse{SecurID: "123456"}))
+
+		// < receive SNAC_0x17_0x03_BUCPLoginResponse
+		frame = wire.SNACFrame{}
+		assert.NoError(t, flapc.ReceiveSNAC(&frame, &wire.SNAC_0x17_0x03_BUCPLoginResponse{}))
+		assert.Equal(t, wire.SNACFrame{FoodGroup: wire.BUCP, SubGroup: wire.BUCPLoginResponse}, frame)
+	}()
+
+	authService := newMockAuthService(t)
+	authService.EXPECT().
+		BUCPLogin(matchContext(), loginRequest, mock.Anything, "localhost:5190").
+		Return(wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.BUCP,
+				SubGroup:  wire.BUCPSecuridRequest,
+			},
+			Body: wire.SNAC_0x17_0x0A_BUCPSecuridRequest{},
+		}, nil)
+	authService.EXPECT().
+		BUCPSecurID(matchContext(), loginRequest, wire.SNAC_0x17_0x0B_BUCPSecuridResponse{SecurID: "123456"}, mock.Anything, "localhost:5190").
+		Return(wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.BUCP,
+				SubGroup:  wire.BUCPLoginResponse,
+			},
+			Body: wire.SNAC_0x17_0x03_BUCPLoginResponse{},
+		}, nil)
+
+	rt := oscarServer{
+		AuthService:   authService,
+		Logger:        slog.Default(),
+		IPRateLimiter: NewIPRateLimiter(rate.Every(1*time.Minute), 10, 1*time.Minute),
+	}
+	assert.NoError(t, rt.routeConnection(context.Background(), clientFake, config.Listener{BOSAdvertisedHostPlain: "localhost:5190"}))
+}
+
+func TestOscarServer_RouteConnection_Auth_BUCPRegister(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	addr, err := net.ResolveTCPAddr("tcp", "127.0.0.1:8080")
+	assert.NoError(t, err)
+
+	clientFake := fakeConn{
+		Conn:   serverConn,
+		local:  addr,
+		remote: addr,
+	}
+
+	captcha := state.Captcha{Answer: "12345", Image: []byte("jpeg-data")}
+
+	go func() {
+		defer func() {
+			_ = clientConn.Close()
+		}()
+
+		// < receive FLAPSignonFrame
+		flap := wire.FLAPFrame{}
+		assert.NoError(t, wire.UnmarshalBE(&flap, clientConn))
+		flapSignonFrame := wire.FLAPSignonFrame{}
+		assert.NoError(t, wire.UnmarshalBE(&flapSignonFrame, bytes.NewBuffer(flap.Payload)))
+
+		// > send FLAPSignonFrame
+		flapSignonFrame = wire.FLAPSignonFrame{
+			FLAPVersion: 1,
+		}
+		buf := &bytes.Buffer{}
+		assert.NoError(t, wire.MarshalBE(flapSignonFrame, buf))
+		flap = wire.FLAPFrame{
+			StartMarker: 42,
+			FrameType:   wire.FLAPFrameSignon,
+			Payload:     buf.Bytes(),
+		}
+		assert.NoError(t, wire.MarshalBE(flap, clientConn))
+
+		// > send SNAC_0x17_0x0C_BUCPRegistrationImageRequest
+		flapc := wire.NewFlapClient(0, clientConn, clientConn)
+		frame := wire.SNACFrame{
+			FoodGroup: wire.BUCP,
+			SubGroup:  wire.BUCPRegistrationImageRequest,
+		}
+		assert.NoError(t, flapc.SendSNAC(frame, wire.SNAC_0x17_0x0C_BUCPRegistrationImageRequest{}))
+
+		// < receive SNAC_0x17_0x0D_BUCPRegistrationImageReply
+		frame = wire.SNACFrame{}
+		assert.NoError(t, flapc.ReceiveSNAC(&frame, &wire.SNAC_0x17_0x0D_BUCPRegistrationImageReply{}))
+		assert.Equal(t, wire.SNACFrame{FoodGroup: wire.BUCP, SubGroup: wire.BUCPRegistrationImageReply}, frame)
+
+		// > send SNAC_0x17_0x04_BUCPRegisterRequest
+		frame = wire.SNACFrame{
+			FoodGroup: wire.BUCP,
+			SubGroup:  wire.BUCPRegisterRequest,
+		}
+		assert.NoError(t, flapc.SendSNAC(frame, wire.SNAC_0x17_0x04_BUCPRegisterRequest{}))
+
+		// < receive SNAC_0x17_0x05_BUCPRegisterResponse
+		frame = wire.SNACFrame{}
+		assert.NoError(t, flapc.ReceiveSNAC(&frame, &wire.SNAC_0x17_0x05_BUCPRegisterResponse{}))
+		assert.Equal(t, wire.SNACFrame{FoodGroup: wire.BUCP, SubGroup: wire.BUCPRegisterResponse}, frame)
+
+		// > client disconnects after registering
+		assert.NoError(t, flapc.NewSignoff(wire.TLVRestBlock{}))
+	}()
+
+	authService := newMockAuthService(t)
+	authService.EXPECT().
+		BUCPRegistrationImage(matchContext()).
+		Return(wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.BUCP,
+				SubGroup:  wire.BUCPRegistrationImageReply,
+			},
+			Body: wire.SNAC_0x17_0x0D_BUCPRegistrationImageReply{},
+		}, captcha, nil)
+	// the captcha sent on this connection is checked against the answer
+	authService.EXPECT().
+		BUCPRegister(matchContext(), mock.Anything, captcha).
+		Return(wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.BUCP,
+				SubGroup:  wire.BUCPRegisterResponse,
+			},
+			Body: wire.SNAC_0x17_0x05_BUCPRegisterResponse{},
+		}, nil)
+
+	rt := oscarServer{
+		AuthService:   authService,
+		Logger:        slog.Default(),
+		IPRateLimiter: NewIPRateLimiter(rate.Every(1*time.Minute), 10, 1*time.Minute),
+	}
+	assert.ErrorIs(t, rt.routeConnection(context.Background(), clientFake, config.Listener{BOSAdvertisedHostPlain: "localhost:5190"}), io.EOF)
+}
+
 func TestOscarServer_RouteConnection_Auth_FLAP(t *testing.T) {
 	serverConn, clientConn := net.Pipe()
 	addr, err := net.ResolveTCPAddr("tcp", "127.0.0.1:8080")
@@ -369,9 +749,10 @@ func TestOscarServer_RouteConnection_BOS(t *testing.T) {
 		Return(sess, nil)
 	wg.Add(1)
 	authService.EXPECT().
-		Signout(mock.Anything, sess).
-		Run(func(ctx context.Context, s *state.Session) {
+		Signout(mock.Anything, sess, mock.Anything).
+		Run(func(ctx context.Context, s *state.Session, cleanup func(bool)) {
 			defer wg.Done()
+			cleanup(true)
 		})
 
 	authService.EXPECT().
@@ -717,6 +1098,43 @@ func Test_oscarServer_dispatchIncomingMessages_disconnect_new_client(t *testing.
 	wg.Wait()
 }
 
+// Make sure the server probes the client with keepalives and closes the
+// connection once the client goes quiet for longer than the idle timeout.
+func Test_oscarServer_dispatchIncomingMessages_idleTimeout(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	keepAlive := state.NewKeepAliveMonitor(10*time.Millisecond, 100*time.Millisecond)
+
+	// the client reads keepalives, but never sends anything
+	probes := make(chan struct{}, 100)
+	go func() {
+		flapc := wire.NewFlapClient(0, clientConn, clientConn)
+		for {
+			frame, err := flapc.ReceiveFLAP()
+			if err != nil {
+				return
+			}
+			if frame.FrameType == wire.FLAPFrameKeepAlive {
+				probes <- struct{}{}
+			}
+		}
+	}()
+
+	srv := oscarServer{
+		Logger:    slog.Default(),
+		KeepAlive: keepAlive,
+	}
+	sess := state.NewSession()
+	flapc := wire.NewFlapClient(0, serverConn, serverConn)
+	err := srv.dispatchIncomingMessages(context.Background(), wire.BOS, sess, flapc, serverConn, config.Listener{})
+	assert.NoError(t, err)
+	_ = serverConn.Close()
+
+	stats := keepAlive.KeepAliveStats()
+	assert.Equal(t, uint64(1), stats.Reaped)
+	assert.Greater(t, stats.ProbesSent, uint64(0))
+	assert.NotEmpty(t, probes)
+}
+
 func Test_oscarServer_receiveSessMessages_BOS_integration(t *testing.T) {
 	serverConn, clientConn := net.Pipe()
 	defer serverConn.Close()
@@ -736,8 +1154,11 @@ func Test_oscarServer_receiveSessMessages_BOS_integration(t *testing.T) {
 	var signoutWG sync.WaitGroup
 	signoutWG.Add(1)
 	authService.EXPECT().
-		Signout(mock.Anything, sess).
-		Run(func(ctx context.Context, s *state.Session) { signoutWG.Done() })
+		Signout(mock.Anything, sess, mock.Anything).
+		Run(func(ctx context.Context, s *state.Session, cleanup func(bool)) {
+			defer signoutWG.Done()
+			cleanup(true)
+		})
 
 	onlineNotifier := newMockOnlineNotifier(t)
 	onlineNotifier.EXPECT().
@@ -48,13 +48,16 @@ type RateLimitUpdater interface {
 type AuthService interface {
 	BUCPChallenge(ctx context.Context, bodyIn wire.SNAC_0x17_0x06_BUCPChallengeRequest, newUUID func() uuid.UUID) (wire.SNACMessage, error)
 	BUCPLogin(ctx context.Context, bodyIn wire.SNAC_0x17_0x02_BUCPLoginRequest, newUserFn func(screenName state.DisplayScreenName) (state.User, error), here string) (wire.SNACMessage, error)
+	BUCPRegister(ctx context.Context, inBody wire.SNAC_0x17_0x04_BUCPRegisterRequest, captcha state.Captcha) (wire.SNACMessage, error)
+	BUCPRegistrationImage(ctx context.Context) (wire.SNACMessage, state.Captcha, error)
+	BUCPSecurID(ctx context.Context, loginRequest wire.SNAC_0x17_0x02_BUCPLoginRequest, bodyIn wire.SNAC_0x17_0x0B_BUCPSecuridResponse, newUserFn func(screenName state.DisplayScreenName) (state.User, error), advertisedHost string) (wire.SNACMessage, error)
 	CrackCookie(authCookie []byte) (state.ServerCookie, error)
 	FLAPLogin(ctx context.Context, frame wire.FLAPSignonFrame, newUserFn func(screenName state.DisplayScreenName) (state.User, error), here string) (wire.TLVRestBlock, error)
 	KerberosLogin(ctx context.Context, inBody wire.SNAC_0x050C_0x0002_KerberosLoginRequest, newUserFn func(screenName state.DisplayScreenName) (state.User, error), advertisedHost string) (wire.SNACMessage, error)
 	RegisterBOSSession(ctx context.Context, authCookie state.ServerCookie) (*state.Session, error)
 	RegisterChatSession(ctx context.Context, authCookie state.ServerCookie) (*state.Session, error)
 	RetrieveBOSSession(ctx context.Context, authCookie state.ServerCookie) (*state.Session, error)
-	Signout(ctx context.Context, sess *state.Session)
+	Signout(ctx context.Context, sess *state.Session, cleanup func(lastInstance bool))
 	SignoutChat(ctx context.Context, sess *state.Session)
 }
 
@@ -104,6 +107,7 @@ type ICBMService interface {
 	EvilRequest(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x04_0x08_ICBMEvilRequest) (wire.SNACMessage, error)
 	ParameterQuery(ctx context.Context, inFrame wire.SNACFrame) wire.SNACMessage
 	ClientErr(ctx context.Context, sess *state.Session, frame wire.SNACFrame, body wire.SNAC_0x04_0x0B_ICBMClientErr) error
+	OfflineRetrieve(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame) error
 	RestoreWarningLevel(ctx context.Context, sess *state.Session) error
 	UpdateWarnLevel(ctx context.Context, sess *state.Session)
 }
@@ -131,6 +135,10 @@ type ICQService interface {
 	XMLReqData(ctx context.Context, sess *state.Session, req wire.ICQ_0x07D0_0x0898_DBQueryMetaReqXMLReq, seq uint16) error
 }
 
+type InviteService interface {
+	RequestQuery(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x06_0x02_InviteRequestQuery) (wire.SNACMessage, error)
+}
+
 type LocateService interface {
 	DirInfo(ctx context.Context, frame wire.SNACFrame, body wire.SNAC_0x02_0x0B_LocateGetDirInfo) (wire.SNACMessage, error)
 	RightsQuery(ctx context.Context, inFrame wire.SNACFrame) wire.SNACMessage
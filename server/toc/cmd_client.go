@@ -1168,6 +1168,10 @@ func (s OSCARProxy) InitDone(ctx context.Context, sess *state.Session) string {
 	if err := s.OServiceService.ClientOnline(ctx, wire.BOS, wire.SNAC_0x01_0x02_OServiceClientOnline{}, sess); err != nil {
 		return s.runtimeErr(ctx, fmt.Errorf("OServiceServiceBOS.ClientOnliney: %w", err))
 	}
+	// TOC clients can't ask for their offline messages, so send them now
+	if err := s.ICBMService.DeliverOfflineMessages(ctx, sess); err != nil {
+		return s.runtimeErr(ctx, fmt.Errorf("ICBMService.DeliverOfflineMessages: %w", err))
+	}
 	return ""
 }
 
@@ -1679,8 +1683,14 @@ func (s OSCARProxy) Signon(ctx context.Context, args []byte) (*state.Session, []
 		return nil, []string{s.runtimeErr(ctx, fmt.Errorf("AuthService.FLAPLogin: %w", err))}
 	}
 
-	if block.HasTag(wire.LoginTLVTagsErrorSubcode) {
-		s.Logger.DebugContext(ctx, "login failed")
+	if code, ok := block.Uint16BE(wire.LoginTLVTagsErrorSubcode); ok {
+		s.Logger.DebugContext(ctx, "login failed", "code", code)
+		if code == wire.LoginErrRateLimitExceeded {
+			return nil, []string{"ERROR:983"} // connecting too frequently
+		}
+		// TOC has no error for a bad two-factor code. users enrolled in
+		// two-factor auth append the code to the password, so a bad code
+		// is reported the same as a bad password.
 		return nil, []string{"ERROR:980"} // bad username/password
 	}
 
@@ -1716,15 +1726,19 @@ func (s OSCARProxy) Signon(ctx context.Context, args []byte) (*state.Session, []
 }
 
 // Signout terminates a TOC session. It sends departure notifications to
-// buddies, de-registers buddy list and session.
+// buddies, de-registers buddy list and session. The buddy list stays
+// registered if the user is still signed on elsewhere.
 func (s OSCARProxy) Signout(ctx context.Context, me *state.Session, chatRegistry *ChatRegistry) {
-	if err := s.BuddyService.BroadcastBuddyDeparted(ctx, me); err != nil {
-		s.Logger.ErrorContext(ctx, "error sending departure notifications", "err", err.Error())
-	}
-	if err := s.BuddyListRegistry.UnregisterBuddyList(ctx, me.IdentScreenName()); err != nil {
-		s.Logger.ErrorContext(ctx, "error removing buddy list entry", "err", err.Error())
-	}
-	s.AuthService.Signout(ctx, me)
+	s.AuthService.Signout(ctx, me, func(lastInstance bool) {
+		if err := s.BuddyService.BroadcastBuddyDeparted(ctx, me); err != nil {
+			s.Logger.ErrorContext(ctx, "error sending departure notifications", "err", err.Error())
+		}
+		if lastInstance {
+			if err := s.BuddyListRegistry.UnregisterBuddyList(ctx, me.IdentScreenName()); err != nil {
+				s.Logger.ErrorContext(ctx, "error removing buddy list entry", "err", err.Error())
+			}
+		}
+	})
 
 	for _, sess := range chatRegistry.Sessions() {
 		s.AuthService.SignoutChat(ctx, sess)
@@ -2623,6 +2623,13 @@ func TestOSCARProxy_RecvClientCmd_InitDone(t *testing.T) {
 						},
 					},
 				},
+				icbmParams: icbmParams{
+					deliverOfflineMessagesParams: deliverOfflineMessagesParams{
+						{
+							me: state.NewIdentScreenName("me"),
+						},
+					},
+				},
 			},
 		},
 		{
@@ -2642,6 +2649,30 @@ func TestOSCARProxy_RecvClientCmd_InitDone(t *testing.T) {
 			},
 			wantMsg: cmdInternalSvcErr,
 		},
+		{
+			name:     "initialize connection, receive err delivering offline messages",
+			me:       newTestSession("me"),
+			givenCmd: []byte(`toc_init_done`),
+			mockParams: mockParams{
+				oServiceParams: oServiceParams{
+					clientOnlineParams: clientOnlineParams{
+						{
+							me:   state.NewIdentScreenName("me"),
+							body: wire.SNAC_0x01_0x02_OServiceClientOnline{},
+						},
+					},
+				},
+				icbmParams: icbmParams{
+					deliverOfflineMessagesParams: deliverOfflineMessagesParams{
+						{
+							me:  state.NewIdentScreenName("me"),
+							err: io.EOF,
+						},
+					},
+				},
+			},
+			wantMsg: cmdInternalSvcErr,
+		},
 	}
 
 	for _, tc := range cases {
@@ -2655,8 +2686,16 @@ func TestOSCARProxy_RecvClientCmd_InitDone(t *testing.T) {
 					Return(params.err)
 			}
 
+			icbmSvc := newMockICBMService(t)
+			for _, params := range tc.mockParams.icbmParams.deliverOfflineMessagesParams {
+				icbmSvc.EXPECT().
+					DeliverOfflineMessages(ctx, matchSession(params.me)).
+					Return(params.err)
+			}
+
 			svc := OSCARProxy{
 				Logger:          slog.Default(),
+				ICBMService:     icbmSvc,
 				OServiceService: oSvc,
 			}
 			msg := svc.RecvClientCmd(ctx, tc.me, nil, tc.givenCmd, nil, nil)
@@ -4149,6 +4188,33 @@ func TestOSCARProxy_Signon(t *testing.T) {
 			},
 			wantMsg: []string{"ERROR:980"},
 		},
+		{
+			name:     "login with locked account",
+			givenCmd: []byte(`"" "" me "xx` + hex.EncodeToString(roastedPass) + `"`),
+			mockParams: mockParams{
+				authParams: authParams{
+					flapLoginParams: flapLoginParams{
+						{
+							frame: wire.FLAPSignonFrame{
+								TLVRestBlock: wire.TLVRestBlock{
+									TLVList: wire.TLVList{
+										wire.NewTLVBE(wire.LoginTLVTagsScreenName, "me"),
+										wire.NewTLVBE(wire.LoginTLVTagsRoastedTOCPassword, roastedPass),
+									},
+								},
+							},
+							newUserFn: state.NewStubUser,
+							tlv: wire.TLVRestBlock{
+								TLVList: wire.TLVList{
+									wire.NewTLVBE(wire.LoginTLVTagsErrorSubcode, wire.LoginErrRateLimitExceeded),
+								},
+							},
+						},
+					},
+				},
+			},
+			wantMsg: []string{"ERROR:983"},
+		},
 		{
 			name:     "bad command",
 			givenCmd: []byte(`"" ""`),
@@ -4254,7 +4320,8 @@ func TestOSCARProxy_Signout(t *testing.T) {
 				authParams: authParams{
 					signoutParams: signoutParams{
 						{
-							me: state.NewIdentScreenName("me"),
+							me:           state.NewIdentScreenName("me"),
+							lastInstance: true,
 						},
 					},
 					signoutChatParams: signoutChatParams{
@@ -4293,7 +4360,8 @@ func TestOSCARProxy_Signout(t *testing.T) {
 				authParams: authParams{
 					signoutParams: signoutParams{
 						{
-							me: state.NewIdentScreenName("me"),
+							me:           state.NewIdentScreenName("me"),
+							lastInstance: true,
 						},
 					},
 				},
@@ -4323,11 +4391,36 @@ func TestOSCARProxy_Signout(t *testing.T) {
 				},
 				authParams: authParams{
 					signoutParams: signoutParams{
+						{
+							me:           state.NewIdentScreenName("me"),
+							lastInstance: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "sign out while signed on elsewhere, keep buddy list registered",
+			me:   newTestSession("me"),
+			chatRegistry: func() *ChatRegistry {
+				return NewChatRegistry()
+			}(),
+			mockParams: mockParams{
+				buddyParams: buddyParams{
+					broadcastBuddyDepartedParams: broadcastBuddyDepartedParams{
 						{
 							me: state.NewIdentScreenName("me"),
 						},
 					},
 				},
+				authParams: authParams{
+					signoutParams: signoutParams{
+						{
+							me:           state.NewIdentScreenName("me"),
+							lastInstance: false,
+						},
+					},
+				},
 			},
 		},
 	}
@@ -4352,7 +4445,11 @@ func TestOSCARProxy_Signout(t *testing.T) {
 
 			authSvc := newMockAuthService(t)
 			for _, params := range tc.mockParams.signoutParams {
-				authSvc.EXPECT().Signout(ctx, matchSession(params.me))
+				authSvc.EXPECT().
+					Signout(ctx, matchSession(params.me), mock.Anything).
+					Run(func(ctx context.Context, sess *state.Session, cleanup func(bool)) {
+						cleanup(params.lastInstance)
+					})
 			}
 			for _, params := range tc.mockParams.signoutChatParams {
 				authSvc.EXPECT().SignoutChat(ctx, matchSession(params.me))
@@ -47,6 +47,8 @@ func (s OSCARProxy) RecvBOS(ctx context.Context, me *state.Session, chatRegistry
 				sendOrCancel(ctx, ch, s.IMIn(ctx, chatRegistry, v))
 			case wire.SNAC_0x01_0x10_OServiceEvilNotification:
 				sendOrCancel(ctx, ch, s.Eviled(v))
+			case wire.SNAC_0x01_0x13_OServiceMotd:
+				sendOrCancel(ctx, ch, s.MOTD(ctx, v))
 			default:
 				s.Logger.DebugContext(ctx, fmt.Sprintf("unsupported snac. foodgroup: %s subgroup: %s",
 					wire.FoodGroupName(snac.Frame.FoodGroup),
@@ -290,6 +292,17 @@ func (s OSCARProxy) convertICBMRendezvous(ctx context.Context, chatRegistry *Cha
 	}
 }
 
+// MOTD delivers the message of the day as an IM_IN TOC command, since TOC
+// has no equivalent of the OSCAR message of the day. The message appears to
+// come from state.MOTDScreenName.
+func (s OSCARProxy) MOTD(ctx context.Context, snac wire.SNAC_0x01_0x13_OServiceMotd) string {
+	txt, ok := snac.String(wire.OServiceTLVTagsMotdMessage)
+	if !ok {
+		return s.runtimeErr(ctx, errors.New("snac.String: missing wire.OServiceTLVTagsMotdMessage"))
+	}
+	return fmt.Sprintf("IM_IN:%s:F:%s", state.MOTDScreenName, txt)
+}
+
 // UpdateBuddyArrival handles the UPDATE_BUDDY TOC command for buddy arrival events.
 //
 // From the TiK documentation:
@@ -264,6 +264,41 @@ func TestOSCARProxy_RecvBOS_Eviled(t *testing.T) {
 	}
 }
 
+func TestOSCARProxy_RecvBOS_MOTD(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	svc := testOSCARProxy(t)
+	me := newTestSession("me")
+
+	ch := make(chan []byte)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		err := svc.RecvBOS(ctx, me, NewChatRegistry(), ch)
+		assert.NoError(t, err)
+	}()
+
+	status := me.RelayMessage(wire.SNACMessage{
+		Body: wire.SNAC_0x01_0x13_OServiceMotd{
+			MotdType: wire.OServiceMotdTypeNormal,
+			TLVRestBlock: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.OServiceTLVTagsMotdMessage, "maintenance at 10pm: back soon"),
+				},
+			},
+		},
+	})
+	assert.Equal(t, state.SessSendOK, status)
+
+	gotCmd := <-ch
+	assert.Equal(t, "IM_IN:MOTD:F:maintenance at 10pm: back soon", string(gotCmd))
+
+	cancel()
+	wg.Wait()
+}
+
 func TestOSCARProxy_RecvBOS_IMIn(t *testing.T) {
 	cases := []struct {
 		// name is the unit test name
@@ -601,7 +636,10 @@ func testOSCARProxy(t *testing.T) OSCARProxy {
 		Return(nil)
 	authService := newMockAuthService(t)
 	authService.EXPECT().
-		Signout(mock.Anything, mock.Anything).
+		Signout(mock.Anything, mock.Anything, mock.Anything).
+		Run(func(ctx context.Context, sess *state.Session, cleanup func(bool)) {
+			cleanup(true)
+		}).
 		Maybe()
 	authService.EXPECT().
 		SignoutChat(mock.Anything, mock.Anything).
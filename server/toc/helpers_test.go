@@ -90,9 +90,15 @@ type evilRequestParams []struct {
 
 type icbmParams struct {
 	channelMsgToHostParamsICBM
+	deliverOfflineMessagesParams
 	evilRequestParams
 }
 
+type deliverOfflineMessagesParams []struct {
+	me  state.IdentScreenName
+	err error
+}
+
 type clientOnlineParams []struct {
 	body wire.SNAC_0x01_0x02_OServiceClientOnline
 	me   state.IdentScreenName
@@ -138,7 +144,8 @@ type registerChatSessionParams []struct {
 }
 
 type signoutParams []struct {
-	me state.IdentScreenName
+	me           state.IdentScreenName
+	lastInstance bool
 }
 
 type signoutChatParams []struct {
@@ -435,9 +435,9 @@ func (_c *mockAuthService_RetrieveBOSSession_Call) RunAndReturn(run func(context
 	return _c
 }
 
-// Signout provides a mock function with given fields: ctx, sess
-func (_m *mockAuthService) Signout(ctx context.Context, sess *state.Session) {
-	_m.Called(ctx, sess)
+// Signout provides a mock function with given fields: ctx, sess, cleanup
+func (_m *mockAuthService) Signout(ctx context.Context, sess *state.Session, cleanup func(bool)) {
+	_m.Called(ctx, sess, cleanup)
 }
 
 // mockAuthService_Signout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Signout'
@@ -448,13 +448,14 @@ type mockAuthService_Signout_Call struct {
 // Signout is a helper method to define mock.On call
 //   - ctx context.Context
 //   - sess *state.Session
-func (_e *mockAuthService_Expecter) Signout(ctx interface{}, sess interface{}) *mockAuthService_Signout_Call {
-	return &mockAuthService_Signout_Call{Call: _e.mock.On("Signout", ctx, sess)}
+//   - cleanup func(bool)
+func (_e *mockAuthService_Expecter) Signout(ctx interface{}, sess interface{}, cleanup interface{}) *mockAuthService_Signout_Call {
+	return &mockAuthService_Signout_Call{Call: _e.mock.On("Signout", ctx, sess, cleanup)}
 }
 
-func (_c *mockAuthService_Signout_Call) Run(run func(ctx context.Context, sess *state.Session)) *mockAuthService_Signout_Call {
+func (_c *mockAuthService_Signout_Call) Run(run func(ctx context.Context, sess *state.Session, cleanup func(bool))) *mockAuthService_Signout_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*state.Session))
+		run(args[0].(context.Context), args[1].(*state.Session), args[2].(func(bool)))
 	})
 	return _c
 }
@@ -464,7 +465,7 @@ func (_c *mockAuthService_Signout_Call) Return() *mockAuthService_Signout_Call {
 	return _c
 }
 
-func (_c *mockAuthService_Signout_Call) RunAndReturn(run func(context.Context, *state.Session)) *mockAuthService_Signout_Call {
+func (_c *mockAuthService_Signout_Call) RunAndReturn(run func(context.Context, *state.Session, func(bool))) *mockAuthService_Signout_Call {
 	_c.Run(run)
 	return _c
 }
@@ -183,6 +183,53 @@ func (_c *mockICBMService_ClientEvent_Call) RunAndReturn(run func(context.Contex
 	return _c
 }
 
+// DeliverOfflineMessages provides a mock function with given fields: ctx, sess
+func (_m *mockICBMService) DeliverOfflineMessages(ctx context.Context, sess *state.Session) error {
+	ret := _m.Called(ctx, sess)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeliverOfflineMessages")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *state.Session) error); ok {
+		r0 = rf(ctx, sess)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// mockICBMService_DeliverOfflineMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeliverOfflineMessages'
+type mockICBMService_DeliverOfflineMessages_Call struct {
+	*mock.Call
+}
+
+// DeliverOfflineMessages is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sess *state.Session
+func (_e *mockICBMService_Expecter) DeliverOfflineMessages(ctx interface{}, sess interface{}) *mockICBMService_DeliverOfflineMessages_Call {
+	return &mockICBMService_DeliverOfflineMessages_Call{Call: _e.mock.On("DeliverOfflineMessages", ctx, sess)}
+}
+
+func (_c *mockICBMService_DeliverOfflineMessages_Call) Run(run func(ctx context.Context, sess *state.Session)) *mockICBMService_DeliverOfflineMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*state.Session))
+	})
+	return _c
+}
+
+func (_c *mockICBMService_DeliverOfflineMessages_Call) Return(_a0 error) *mockICBMService_DeliverOfflineMessages_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *mockICBMService_DeliverOfflineMessages_Call) RunAndReturn(run func(context.Context, *state.Session) error) *mockICBMService_DeliverOfflineMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // EvilRequest provides a mock function with given fields: ctx, sess, inFrame, inBody
 func (_m *mockICBMService) EvilRequest(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x04_0x08_ICBMEvilRequest) (wire.SNACMessage, error) {
 	ret := _m.Called(ctx, sess, inFrame, inBody)
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"net"
 	"net/http"
 	"net/netip"
+	"os"
 	"sync"
 	"syscall"
 	"time"
@@ -19,6 +21,7 @@ import (
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/time/rate"
 
+	"github.com/mk6i/retro-aim-server/config"
 	"github.com/mk6i/retro-aim-server/state"
 	"github.com/mk6i/retro-aim-server/wire"
 )
@@ -32,6 +35,9 @@ var (
 
 	// errTOCProcessing indicates that an error occurred in the TOC handler
 	errTOCProcessing = errors.New("failed to process TOC request")
+
+	// errServerShutdown indicates that the server has been shut down
+	errServerShutdown = errors.New("server is shut down")
 )
 
 // bufferedConn is a wrapper around net.Conn that allows peeking into the
@@ -44,6 +50,25 @@ type bufferedConn struct {
 	net.Conn
 }
 
+// idleTimeoutConn is a wrapper around net.Conn that closes the connection if
+// the client sends nothing for longer than the idle timeout. Each read pushes
+// the read deadline forward.
+type idleTimeoutConn struct {
+	net.Conn
+	keepAlive *state.KeepAliveMonitor
+}
+
+// Read reads data into p, failing with os.ErrDeadlineExceeded if nothing
+// arrives before the idle timeout.
+func (c idleTimeoutConn) Read(p []byte) (int, error) {
+	if deadline := c.keepAlive.ReadDeadline(time.Now()); !deadline.IsZero() {
+		if err := c.Conn.SetReadDeadline(deadline); err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Read(p)
+}
+
 // newBufferedConn wraps a net.Conn with buffered reading capabilities.
 func newBufferedConn(c net.Conn) bufferedConn {
 	return bufferedConn{bufio.NewReader(c), c}
@@ -123,39 +148,31 @@ func (l *IPRateLimiter) Allow(ip string) (allowed bool) {
 }
 
 func NewServer(
-	listenerCfg []string,
+	listenerCfg []config.TOCListener,
 	logger *slog.Logger,
 	BOSProxy OSCARProxy,
 	ipRateLimiter *IPRateLimiter,
 	recalcWarning func(ctx context.Context, sess *state.Session) error,
 	lowerWarnLevel func(ctx context.Context, sess *state.Session),
+	keepAlive *state.KeepAliveMonitor,
 ) *Server {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	s := &Server{
+	return &Server{
 		bosProxy:           BOSProxy,
 		conns:              make(map[net.Conn]struct{}),
+		keepAlive:          keepAlive,
 		listenerCfg:        listenerCfg,
+		listeners:          make(map[string]*tocListener),
 		logger:             logger,
 		loginIPRateLimiter: ipRateLimiter,
 		recalcWarning:      recalcWarning,
 		lowerWarnLevel:     lowerWarnLevel,
-		servers:            make([]*http.Server, 0, len(listenerCfg)),
+		serveErr:           make(chan error, 1),
 		shutdownCancel:     cancel,
 		shutdownCtx:        ctx,
 	}
-
-	for range listenerCfg {
-		s.servers = append(s.servers, &http.Server{
-			Handler: BOSProxy.NewServeMux(),
-			BaseContext: func(net.Listener) context.Context {
-				return s.shutdownCtx
-			},
-		})
-	}
-
-	return s
 }
 
 // Server implements a TOC protocol server that multiplexes TOC/HTTP and
@@ -163,14 +180,16 @@ func NewServer(
 // to the OSCAR server for processing.
 type Server struct {
 	bosProxy           OSCARProxy
+	keepAlive          *state.KeepAliveMonitor
 	logger             *slog.Logger
 	loginIPRateLimiter *IPRateLimiter
 	recalcWarning      func(ctx context.Context, sess *state.Session) error
 	lowerWarnLevel     func(ctx context.Context, sess *state.Session)
 
-	listenerCfg []string
-	listeners   []net.Listener
-	servers     []*http.Server
+	listenerCfg []config.TOCListener
+	listenMu    sync.Mutex
+	listeners   map[string]*tocListener // keyed by listen address
+	serveErr    chan error
 
 	connMu sync.Mutex
 	conns  map[net.Conn]struct{}
@@ -182,49 +201,163 @@ type Server struct {
 	shutdownCancel context.CancelFunc
 }
 
+// tocListener accepts TOC connections on a listen address and hands off
+// TOC/HTTP connections to its HTTP server.
+type tocListener struct {
+	ln     net.Listener
+	cfg    config.TOCListener
+	server *http.Server
+	cancel context.CancelFunc // stops the HTTP server from accepting connections
+	// tlsConfig is set if the listener accepts connections over TLS
+	tlsConfig *tls.Config
+}
+
 func (s *Server) ListenAndServe() error {
-	g, ctx := errgroup.WithContext(s.shutdownCtx)
+	err := s.SetListeners(s.listenerCfg)
+	switch {
+	case errors.Is(err, errServerShutdown):
+		return nil // shut down before it started
+	case err != nil:
+		s.shutdownCancel()
+		return err
+	}
+
+	select {
+	case <-s.shutdownCtx.Done():
+		return nil
+	case err := <-s.serveErr:
+		s.shutdownCancel()
+		return err
+	}
+}
+
+// SetListeners starts accepting connections on the addresses in listenerCfg
+// that aren't open yet and stops accepting connections on open addresses
+// that aren't in listenerCfg. TOC/FLAP sessions accepted on a removed
+// address stay connected. Open addresses pick up changed TLS settings and
+// decode limits without being reopened. If a new address can't be opened or
+// a certificate can't be loaded, the open addresses are left as they were.
+func (s *Server) SetListeners(listenerCfg []config.TOCListener) error {
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+
+	if s.shutdownCtx.Err() != nil {
+		return errServerShutdown
+	}
+
+	want := make(map[string]config.TOCListener, len(listenerCfg))
+	for _, cfg := range listenerCfg {
+		want[cfg.ListenAddress] = cfg
+	}
+
+	// load the certificates of new TLS listeners and of TLS listeners whose
+	// certificate settings changed
+	tlsConfigs := make(map[string]*tls.Config)
+	for addr, cfg := range want {
+		if !cfg.HasTLS {
+			continue
+		}
+		if l, ok := s.listeners[addr]; ok && l.tlsConfig != nil && l.cfg.TLS == cfg.TLS {
+			continue
+		}
+		tlsConfig, err := state.NewTLSConfig(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.LegacyCiphers)
+		if err != nil {
+			return fmt.Errorf("unable to start TOC server: %w", err)
+		}
+		tlsConfigs[addr] = tlsConfig
+	}
 
-	for i, cfg := range s.listenerCfg {
-		ln, err := net.Listen("tcp", cfg)
+	// open the new addresses first so that a failure leaves the server as
+	// it was
+	opened := make(map[string]net.Listener)
+	for addr := range want {
+		if _, ok := s.listeners[addr]; ok {
+			continue
+		}
+		ln, err := net.Listen("tcp", addr)
 		if err != nil {
-			s.cleanupListeners()
-			s.shutdownCancel()
+			for _, ln := range opened {
+				_ = ln.Close()
+			}
 			return fmt.Errorf("unable to start TOC server: %w", err)
 		}
+		opened[addr] = ln
+	}
 
-		s.logger.InfoContext(ctx, "starting server", "listen_host", cfg)
+	for addr, l := range s.listeners {
+		cfg, ok := want[addr]
+		switch {
+		case !ok:
+			_ = l.ln.Close()
+			l.cancel()
+			// let HTTP requests in progress finish
+			go func() {
+				_ = l.server.Shutdown(context.Background())
+			}()
+			delete(s.listeners, addr)
+			s.logger.Info("stopped listener", "listen_host", addr)
+		case cfg != l.cfg:
+			l.cfg = cfg
+			if !cfg.HasTLS {
+				l.tlsConfig = nil
+			} else if tlsConfig, ok := tlsConfigs[addr]; ok {
+				l.tlsConfig = tlsConfig
+			}
+			s.logger.Info("updated listener", "listen_host", addr, "tls", cfg.HasTLS)
+		}
+	}
 
-		s.listeners = append(s.listeners, ln)
-		s.listenWg.Add(1)
+	for addr, ln := range opened {
+		s.listeners[addr] = s.serve(want[addr], tlsConfigs[addr], ln)
+	}
 
-		httpCh := make(chan net.Conn)
+	return nil
+}
 
-		g.Go(func() error {
-			cl := &channelListener{
-				ch:  httpCh,
-				ctx: s.shutdownCtx,
-			}
-			if err := s.servers[i].Serve(cl); !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, io.EOF) {
-				s.shutdownCancel()
-				return err
+// serve starts accepting connections on ln. If tlsConfig is set, the
+// connections are served over TLS.
+func (s *Server) serve(cfg config.TOCListener, tlsConfig *tls.Config, ln net.Listener) *tocListener {
+	ctx, cancel := context.WithCancel(s.shutdownCtx)
+	l := &tocListener{
+		ln:        ln,
+		cfg:       cfg,
+		tlsConfig: tlsConfig,
+		server: &http.Server{
+			Handler: s.bosProxy.NewServeMux(),
+			BaseContext: func(net.Listener) context.Context {
+				return s.shutdownCtx
+			},
+		},
+		cancel: cancel,
+	}
+
+	s.logger.InfoContext(ctx, "starting server", "listen_host", cfg.ListenAddress, "tls", cfg.HasTLS)
+
+	httpCh := make(chan net.Conn)
+
+	go func() {
+		cl := &channelListener{
+			ch:  httpCh,
+			ctx: ctx,
+		}
+		if err := l.server.Serve(cl); !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, io.EOF) {
+			select {
+			case s.serveErr <- err:
+			default:
 			}
-			return nil
-		})
+		}
+	}()
 
-		g.Go(func() error {
-			s.acceptLoop(ctx, ln, httpCh)
-			return nil
-		})
-	}
+	s.listenWg.Add(1)
+	go s.acceptLoop(ctx, l, httpCh)
 
-	return g.Wait()
+	return l
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Debug("Initiating graceful shutdown...")
 	s.shutdownCancel()
-	s.cleanupListeners()
+	servers := s.cleanupListeners()
 
 	// Wait for handlers to complete
 	done := make(chan struct{})
@@ -234,7 +367,7 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		close(done)
 	}()
 
-	for _, srv := range s.servers {
+	for _, srv := range servers {
 		_ = srv.Shutdown(ctx)
 	}
 
@@ -248,18 +381,26 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-func (s *Server) cleanupListeners() {
-	for _, ln := range s.listeners {
-		_ = ln.Close()
+// cleanupListeners closes all listeners and returns their HTTP servers.
+func (s *Server) cleanupListeners() []*http.Server {
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+
+	servers := make([]*http.Server, 0, len(s.listeners))
+	for addr, l := range s.listeners {
+		_ = l.ln.Close()
+		l.cancel()
+		servers = append(servers, l.server)
+		delete(s.listeners, addr)
 	}
-	s.listeners = nil
+	return servers
 }
 
-func (s *Server) acceptLoop(ctx context.Context, ln net.Listener, httpCh chan net.Conn) {
+func (s *Server) acceptLoop(ctx context.Context, l *tocListener, httpCh chan net.Conn) {
 	defer s.listenWg.Done()
 
 	for {
-		conn, err := ln.Accept()
+		conn, err := l.ln.Accept()
 		if err != nil {
 			if errors.Is(err, net.ErrClosed) {
 				return
@@ -268,8 +409,19 @@ func (s *Server) acceptLoop(ctx context.Context, ln net.Listener, httpCh chan ne
 			continue
 		}
 
+		// read the listener config under lock because SetListeners may
+		// update it
+		s.listenMu.Lock()
+		tlsConfig := l.tlsConfig
+		limits := l.cfg.DecodeLimits
+		s.listenMu.Unlock()
+
+		if tlsConfig != nil {
+			conn = tls.Server(conn, tlsConfig)
+		}
+
 		go func() {
-			if err := s.handleConnection(conn, ctx, httpCh); err != nil {
+			if err := s.handleConnection(conn, ctx, httpCh, limits); err != nil {
 				s.logger.InfoContext(ctx, "user session failed", "err", err.Error())
 			}
 		}()
@@ -278,8 +430,10 @@ func (s *Server) acceptLoop(ctx context.Context, ln net.Listener, httpCh chan ne
 
 // handleConnection inspects and routes an incoming connection. If the connection
 // starts with "FLAP", handle as TOC/FLAP; otherwise, dispatch for HTTP
-// processing.
-func (s *Server) handleConnection(conn net.Conn, ctx context.Context, httpCh chan net.Conn) error {
+// processing. ctx is the listener's context; TOC/FLAP sessions run under the
+// server's context so that they outlive the listener. limits bounds the
+// frames decoded from a TOC/FLAP client.
+func (s *Server) handleConnection(conn net.Conn, ctx context.Context, httpCh chan net.Conn, limits wire.DecodeLimits) error {
 	bufCon := newBufferedConn(conn)
 
 	doFlap := "FLAP"
@@ -307,7 +461,7 @@ func (s *Server) handleConnection(conn net.Conn, ctx context.Context, httpCh cha
 
 		s.connWg.Add(1)
 
-		if err = s.dispatchFLAP(ctx, bufCon); err != nil {
+		if err = s.dispatchFLAP(s.shutdownCtx, bufCon, limits); err != nil {
 			switch {
 			case errors.Is(err, io.EOF):
 			case errors.Is(err, net.ErrClosed):
@@ -329,7 +483,7 @@ func (s *Server) handleConnection(conn net.Conn, ctx context.Context, httpCh cha
 	}
 }
 
-func (s *Server) dispatchFLAP(ctx context.Context, conn net.Conn) error {
+func (s *Server) dispatchFLAP(ctx context.Context, conn net.Conn, limits wire.DecodeLimits) error {
 	var once sync.Once
 
 	closeConn := func() {
@@ -341,7 +495,9 @@ func (s *Server) dispatchFLAP(ctx context.Context, conn net.Conn) error {
 
 	ctx = context.WithValue(ctx, "ip", conn.RemoteAddr().String())
 
-	clientFlap, err := s.initFLAP(conn)
+	conn = idleTimeoutConn{Conn: conn, keepAlive: s.keepAlive}
+
+	clientFlap, err := s.initFLAP(conn, limits)
 	if err != nil {
 		return err
 	}
@@ -380,7 +536,13 @@ func (s *Server) dispatchFLAP(ctx context.Context, conn net.Conn) error {
 
 	chatRegistry := NewChatRegistry()
 
-	return s.handleTOCRequest(ctx, closeConn, sessBOS, chatRegistry, clientFlap)
+	err = s.handleTOCRequest(ctx, closeConn, sessBOS, chatRegistry, clientFlap)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		s.logger.InfoContext(ctx, "closing connection because the client stopped responding")
+		s.keepAlive.Reaped()
+		return nil
+	}
+	return err
 }
 
 // handleTOCRequest processes incoming TOC requests and coordinates their handling.
@@ -446,8 +608,8 @@ func (s *Server) runClientCommands(ctx context.Context, doAsync func(f func() er
 		case wire.FLAPFrameSignoff:
 			return io.EOF // client disconnected
 		case wire.FLAPFrameKeepAlive:
-			// keep alive heartbeat, do nothing for now.
-			// todo set connection deadline to future time
+			// keep alive heartbeat, receiving it pushed the idle timeout
+			// forward
 		case wire.FLAPFrameData:
 			clientFrame.Payload = bytes.TrimRight(clientFrame.Payload, "\x00") // trim null terminator
 
@@ -473,10 +635,24 @@ func (s *Server) runClientCommands(ctx context.Context, doAsync func(f func() er
 }
 
 func (s *Server) sendToClient(ctx context.Context, toClient <-chan []byte, clientFlap *wire.FlapClient) error {
+	// periodically probe the client so that a dead connection surfaces as a
+	// write error
+	var keepAliveCh <-chan time.Time
+	if interval := s.keepAlive.Interval(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		keepAliveCh = ticker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-keepAliveCh:
+			if err := clientFlap.SendKeepAliveFrame(); err != nil {
+				return fmt.Errorf("clientFlap.SendKeepAliveFrame: %w", err)
+			}
+			s.keepAlive.ProbeSent()
 		case msg := <-toClient:
 			if err := clientFlap.SendDataFrame(msg); err != nil {
 				return fmt.Errorf("clientFlap.SendDataFrame: %w", err)
@@ -526,7 +702,7 @@ func (s *Server) login(ctx context.Context, clientFlap *wire.FlapClient) (*state
 
 // initFLAP sets up a new FLAP connection. It returns a flap client if the
 // connection successfully initialized.
-func (s *Server) initFLAP(rw io.ReadWriter) (*wire.FlapClient, error) {
+func (s *Server) initFLAP(rw io.ReadWriter, limits wire.DecodeLimits) (*wire.FlapClient, error) {
 	expected := "FLAPON\r\n\r\n"
 	buf := make([]byte, len(expected))
 
@@ -538,7 +714,7 @@ func (s *Server) initFLAP(rw io.ReadWriter) (*wire.FlapClient, error) {
 		return nil, fmt.Errorf("expected FLAPON, got %s", buf)
 	}
 
-	clientFlap := wire.NewFlapClient(0, rw, rw)
+	clientFlap := wire.NewFlapClient(0, wire.WithDecodeLimits(rw, limits), rw)
 
 	if err := clientFlap.SendSignonFrame(nil); err != nil {
 		return nil, fmt.Errorf("clientFlap.SendSignonFrame: %w", err)
@@ -2,16 +2,30 @@ package toc
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"io"
 	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/mk6i/retro-aim-server/config"
 	"github.com/mk6i/retro-aim-server/state"
 	"github.com/mk6i/retro-aim-server/wire"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // ensure correct behavior during global context cancellation (server shutdown)
@@ -203,3 +217,176 @@ func TestServer_handleTOCRequest_happyPath(t *testing.T) {
 	// wait for handleTOCRequest to return
 	wg.Wait()
 }
+
+// ensure the server probes the client with keepalives and disconnects it once
+// it goes quiet for longer than the idle timeout
+func TestServer_handleTOCRequest_idleTimeout(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	keepAlive := state.NewKeepAliveMonitor(10*time.Millisecond, 100*time.Millisecond)
+
+	// the client reads keepalives, but never sends anything
+	probes := make(chan struct{}, 100)
+	go func() {
+		fc := wire.NewFlapClient(0, clientConn, clientConn)
+		for {
+			frame, err := fc.ReceiveFLAP()
+			if err != nil {
+				return
+			}
+			if frame.FrameType == wire.FLAPFrameKeepAlive {
+				probes <- struct{}{}
+			}
+		}
+	}()
+
+	conn := idleTimeoutConn{Conn: serverConn, keepAlive: keepAlive}
+	closeConn := func() {
+		_ = conn.Close()
+	}
+	sv := Server{
+		bosProxy:       testOSCARProxy(t),
+		keepAlive:      keepAlive,
+		logger:         slog.Default(),
+		recalcWarning:  func(ctx context.Context, sess *state.Session) error { return nil },
+		lowerWarnLevel: func(ctx context.Context, sess *state.Session) {},
+	}
+	fc := wire.NewFlapClient(0, conn, conn)
+	err := sv.handleTOCRequest(context.Background(), closeConn, newTestSession("me"), NewChatRegistry(), fc)
+	assert.ErrorIs(t, err, errClientReq)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	closeConn()
+
+	assert.Greater(t, keepAlive.KeepAliveStats().ProbesSent, uint64(0))
+	assert.NotEmpty(t, probes)
+}
+
+func TestServer_SetListeners(t *testing.T) {
+	sv := NewServer(tocListeners("127.0.0.1:15030", "127.0.0.1:15031"), slog.Default(), OSCARProxy{},
+		nil, nil, nil, nil)
+
+	shutdownCh := make(chan struct{})
+	go func() {
+		defer close(shutdownCh)
+		assert.NoError(t, sv.ListenAndServe())
+	}()
+
+	dial := func(addr string) {
+		for attempt := 0; attempt < 10; attempt++ {
+			if conn, err := net.Dial("tcp", addr); err == nil {
+				conn.Close()
+				return
+			}
+			time.Sleep(time.Duration(5<<attempt) * time.Millisecond)
+		}
+		t.Fatalf("server not listening on %s", addr)
+	}
+	dial("127.0.0.1:15030")
+	dial("127.0.0.1:15031")
+
+	// remove one listener and add another
+	assert.NoError(t, sv.SetListeners(tocListeners("127.0.0.1:15031", "127.0.0.1:15032")))
+	_, err := net.Dial("tcp", "127.0.0.1:15030")
+	assert.Error(t, err)
+	dial("127.0.0.1:15031")
+	dial("127.0.0.1:15032")
+
+	// a listener that can't be opened leaves the listeners as they were
+	busy, err := net.Listen("tcp", "127.0.0.1:15033")
+	assert.NoError(t, err)
+	defer busy.Close()
+	assert.Error(t, sv.SetListeners(tocListeners("127.0.0.1:15033")))
+	dial("127.0.0.1:15031")
+	dial("127.0.0.1:15032")
+
+	assert.NoError(t, sv.Shutdown(context.Background()))
+	<-shutdownCh
+}
+
+// tocListeners returns plaintext listener configs for addrs.
+func tocListeners(addrs ...string) []config.TOCListener {
+	listeners := make([]config.TOCListener, 0, len(addrs))
+	for _, addr := range addrs {
+		listeners = append(listeners, config.TOCListener{ListenAddress: addr})
+	}
+	return listeners
+}
+
+// writeTestCertificate writes a self-signed certificate and its key to a
+// temporary directory and returns their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ras.dev"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestServer_TLSListener(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	tlsCfg := config.TOCListener{
+		ListenAddress: "127.0.0.1:15035",
+		HasTLS:        true,
+		TLS:           config.TLS{CertFile: certFile, KeyFile: keyFile},
+	}
+	sv := NewServer([]config.TOCListener{tlsCfg}, slog.Default(), OSCARProxy{},
+		nil, nil, nil, nil)
+
+	shutdownCh := make(chan struct{})
+	go func() {
+		defer close(shutdownCh)
+		assert.NoError(t, sv.ListenAndServe())
+	}()
+
+	// handshake retries until the server is listening
+	handshake := func() error {
+		var err error
+		for attempt := 0; attempt < 10; attempt++ {
+			var conn *tls.Conn
+			conn, err = tls.Dial("tcp", tlsCfg.ListenAddress, &tls.Config{InsecureSkipVerify: true})
+			if err == nil {
+				conn.Close()
+				return nil
+			}
+			time.Sleep(time.Duration(5<<attempt) * time.Millisecond)
+		}
+		return err
+	}
+	assert.NoError(t, handshake())
+
+	// a certificate that can't be loaded leaves the listeners as they were
+	badCfg := tlsCfg
+	badCfg.TLS.CertFile = filepath.Join(t.TempDir(), "missing.pem")
+	assert.Error(t, sv.SetListeners([]config.TOCListener{badCfg}))
+	assert.NoError(t, handshake())
+
+	// switch the listener to plaintext
+	assert.NoError(t, sv.SetListeners(tocListeners(tlsCfg.ListenAddress)))
+	conn, err := net.Dial("tcp", tlsCfg.ListenAddress)
+	if assert.NoError(t, err) {
+		_, err = conn.Write([]byte("FLAPON\r\n\r\n"))
+		assert.NoError(t, err)
+		buf := make([]byte, 1)
+		_, err = conn.Read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, byte('*'), buf[0])
+		conn.Close()
+	}
+
+	assert.NoError(t, sv.Shutdown(context.Background()))
+	<-shutdownCh
+}
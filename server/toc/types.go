@@ -31,6 +31,7 @@ type ChatNavService interface {
 type ICBMService interface {
 	ChannelMsgToHost(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x04_0x06_ICBMChannelMsgToHost) (*wire.SNACMessage, error)
 	ClientEvent(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x04_0x14_ICBMClientEvent) error
+	DeliverOfflineMessages(ctx context.Context, sess *state.Session) error
 	EvilRequest(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x04_0x08_ICBMEvilRequest) (wire.SNACMessage, error)
 	ParameterQuery(ctx context.Context, inFrame wire.SNACFrame) wire.SNACMessage
 	ClientErr(ctx context.Context, sess *state.Session, frame wire.SNACFrame, body wire.SNAC_0x04_0x0B_ICBMClientErr) error
@@ -50,7 +51,7 @@ type AuthService interface {
 	RegisterBOSSession(ctx context.Context, authCookie state.ServerCookie) (*state.Session, error)
 	RegisterChatSession(ctx context.Context, authCookie state.ServerCookie) (*state.Session, error)
 	RetrieveBOSSession(ctx context.Context, authCookie state.ServerCookie) (*state.Session, error)
-	Signout(ctx context.Context, sess *state.Session)
+	Signout(ctx context.Context, sess *state.Session, cleanup func(lastInstance bool))
 	SignoutChat(ctx context.Context, sess *state.Session)
 }
 
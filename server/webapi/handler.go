@@ -33,14 +33,19 @@ type Handler struct {
 	// Phase 2 additions
 	MessageRelayer        MessageRelayer
 	OfflineMessageManager OfflineMessageManager
+	MaxOfflineMessages    int        // 0 means no limit
+	IMArchiver            IMArchiver // nil if IM archiving is disabled
 	BuddyBroadcaster      BuddyBroadcaster
 	ProfileManager        ProfileManager
 	RelationshipFetcher   interface {
 		Relationship(ctx context.Context, me state.IdentScreenName, them state.IdentScreenName) (state.Relationship, error)
 	}
 	// Authentication support
-	UserManager UserManager
-	TokenStore  TokenStore
+	UserManager  UserManager
+	TokenStore   TokenStore
+	LoginLockout LoginLockoutManager
+	AuthMode     AuthMode
+	MOTDManager  MOTDManager
 	// Phase 3 additions
 	PreferenceManager PreferenceManager
 	PermitDenyManager PermitDenyManager
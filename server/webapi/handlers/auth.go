@@ -5,6 +5,8 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
@@ -14,22 +16,45 @@ import (
 
 // AuthHandler handles Web AIM API authentication endpoints.
 type AuthHandler struct {
-	UserManager UserManager
-	TokenStore  TokenStore
-	Logger      *slog.Logger
-	DisableAuth bool
+	UserManager  UserManager
+	TokenStore   TokenStore
+	LoginLockout LoginLockoutManager
+	AuthMode     AuthMode
+	Logger       *slog.Logger
+}
+
+// AuthMode provides the authentication settings, which can change when the
+// configuration is reloaded.
+type AuthMode interface {
+	// AuthDisabled indicates whether password checks are skipped
+	AuthDisabled() bool
+	// AuthProvider returns the external provider that checks passwords, or
+	// nil if passwords are checked locally
+	AuthProvider() state.AuthProvider
 }
 
 // UserManager defines methods for user authentication.
 type UserManager interface {
-	// AuthenticateUser verifies username and password
-	AuthenticateUser(ctx context.Context, username, password string) (*state.User, error)
+	// AuthenticateUser verifies username and password, along with a
+	// one-time code for users enrolled in two-factor auth. The password is
+	// checked against provider if set.
+	AuthenticateUser(ctx context.Context, provider state.AuthProvider, username, password, securID string) (*state.User, error)
 	// FindUserByScreenName finds a user by their screen name
 	FindUserByScreenName(ctx context.Context, screenName state.IdentScreenName) (*state.User, error)
 	// InsertUser creates a new user (for DISABLE_AUTH mode)
 	InsertUser(ctx context.Context, u state.User) error
 }
 
+// LoginLockoutManager tracks failed login attempts per account.
+type LoginLockoutManager interface {
+	// IsLocked indicates whether the account is currently locked out
+	IsLocked(screenName state.IdentScreenName) bool
+	// RecordFailure registers a failed login attempt for the account
+	RecordFailure(screenName state.IdentScreenName)
+	// RecordSuccess clears the failed login attempts for the account
+	RecordSuccess(screenName state.IdentScreenName)
+}
+
 // TokenStore manages authentication tokens.
 type TokenStore interface {
 	// StoreToken saves an authentication token for a user
@@ -40,17 +65,32 @@ type TokenStore interface {
 	DeleteToken(ctx context.Context, token string) error
 }
 
+// clientLogin status and detail codes returned by the WebAPI when a login is
+// rejected.
+const (
+	loginStatusAuthRequired       = 330
+	loginStatusNotAllowed         = 401
+	loginDetailInvalidCredentials = 3011
+	loginDetailInvalidSecurID     = 3012
+	loginDetailNotAllowed         = 3019
+)
+
+// errLoginLocked indicates that an account is locked out after too many
+// failed login attempts.
+var errLoginLocked = errors.New("account is locked out")
+
 // ClientLoginRequest represents the request body for clientLogin.
 type ClientLoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	DevID    string `json:"devId"`
+	SecurID  string `json:"securid"`
 }
 
 // ClientLogin handles POST /auth/clientLogin requests.
 // This endpoint authenticates users and returns an authentication token.
 func (h *AuthHandler) ClientLogin(w http.ResponseWriter, r *http.Request) {
-	var username, password, devID string
+	var username, password, devID, securID string
 
 	// Check Content-Type to determine how to parse the request
 	contentType := r.Header.Get("Content-Type")
@@ -66,6 +106,7 @@ func (h *AuthHandler) ClientLogin(w http.ResponseWriter, r *http.Request) {
 		username = req.Username
 		password = req.Password
 		devID = req.DevID
+		securID = req.SecurID
 	} else {
 		// Parse form-encoded or URL parameters
 		if err := r.ParseForm(); err != nil {
@@ -84,12 +125,12 @@ func (h *AuthHandler) ClientLogin(w http.ResponseWriter, r *http.Request) {
 			password = r.FormValue("password")
 		}
 		devID = r.FormValue("devId")
+		securID = r.FormValue("securid")
 
 		h.Logger.Debug("form-encoded login attempt",
 			"username", username,
 			"has_password", password != "",
-			"devId", devID,
-			"form", r.Form)
+			"devId", devID)
 	}
 
 	// Validate required fields
@@ -98,45 +139,30 @@ func (h *AuthHandler) ClientLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Authenticate user
-	user, err := h.UserManager.AuthenticateUser(r.Context(), username, password)
-	if err != nil {
-		// If DISABLE_AUTH is enabled and user doesn't exist, create the user
-		if h.DisableAuth && err.Error() == "user not found" {
-			h.Logger.Info("DISABLE_AUTH: Creating new user",
-				"username", username)
-
-			// Create new user with the provided username
-			newUser := state.User{
-				IdentScreenName:   state.NewIdentScreenName(username),
-				DisplayScreenName: state.DisplayScreenName(username),
-			}
-
-			// Insert the new user
-			if err := h.UserManager.InsertUser(r.Context(), newUser); err != nil {
-				h.Logger.Error("failed to create user",
-					"username", username,
-					"error", err)
-				SendError(w, http.StatusInternalServerError, "failed to create user")
-				return
-			}
-
-			// Try to authenticate again after creating the user
-			user, err = h.UserManager.AuthenticateUser(r.Context(), username, password)
-			if err != nil {
-				h.Logger.Error("failed to authenticate after creating user",
-					"username", username,
-					"error", err)
-				SendError(w, http.StatusInternalServerError, "internal server error")
-				return
-			}
-		} else {
-			h.Logger.Warn("authentication failed",
-				"username", username,
-				"error", err)
-			SendError(w, http.StatusUnauthorized, "authentication failed")
-			return
-		}
+	user, err := h.authenticate(r.Context(), username, password, securID)
+	switch {
+	case errors.Is(err, errLoginLocked):
+		h.Logger.Debug("locked account attempted login", "username", username)
+		// Web AIM clients don't recognize a dedicated lockout code, so report
+		// it as a rejected password and explain in the status text.
+		sendLoginError(w, r, loginStatusAuthRequired, loginDetailInvalidCredentials, "too many failed login attempts, try again later", h.Logger)
+		return
+	case errors.Is(err, state.ErrNoUser), errors.Is(err, state.ErrBadCredentials):
+		h.Logger.Debug("authentication failed", "username", username, "err", err.Error())
+		sendLoginError(w, r, loginStatusAuthRequired, loginDetailInvalidCredentials, "invalid username or password", h.Logger)
+		return
+	case errors.Is(err, state.ErrBadSecurID):
+		h.Logger.Debug("two-factor authentication failed", "username", username)
+		sendLoginError(w, r, loginStatusAuthRequired, loginDetailInvalidSecurID, "invalid or missing SecurID code", h.Logger)
+		return
+	case errors.Is(err, state.ErrUserSuspended):
+		h.Logger.Debug("suspended user attempted login", "username", username)
+		sendLoginError(w, r, loginStatusNotAllowed, loginDetailNotAllowed, "account is suspended", h.Logger)
+		return
+	case err != nil:
+		h.Logger.Error("failed to authenticate user", "username", username, "err", err.Error())
+		SendError(w, http.StatusInternalServerError, "internal server error")
+		return
 	}
 
 	// Generate authentication token
@@ -187,6 +213,70 @@ func (h *AuthHandler) ClientLogin(w http.ResponseWriter, r *http.Request) {
 		"screenName", user.DisplayScreenName)
 }
 
+// authenticate verifies the user's credentials. When auth is disabled, the
+// password is not checked and unknown users are created on the fly, mirroring
+// the behavior of the OSCAR login flow.
+func (h *AuthHandler) authenticate(ctx context.Context, username, password, securID string) (*state.User, error) {
+	if !h.AuthMode.AuthDisabled() {
+		identSN := state.NewIdentScreenName(username)
+		if h.LoginLockout.IsLocked(identSN) {
+			return nil, errLoginLocked
+		}
+		user, err := h.UserManager.AuthenticateUser(ctx, h.AuthMode.AuthProvider(), username, password, securID)
+		switch {
+		case errors.Is(err, state.ErrBadCredentials), errors.Is(err, state.ErrBadSecurID):
+			h.LoginLockout.RecordFailure(identSN)
+		case err == nil:
+			h.LoginLockout.RecordSuccess(identSN)
+		}
+		return user, err
+	}
+
+	user, err := h.UserManager.FindUserByScreenName(ctx, state.NewIdentScreenName(username))
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		displaySN := state.DisplayScreenName(username)
+		if displaySN.IsUIN() {
+			err = displaySN.ValidateUIN()
+		} else {
+			err = displaySN.ValidateAIMHandle()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", state.ErrBadCredentials, err)
+		}
+
+		newUser, err := state.NewStubUser(displaySN)
+		if err != nil {
+			return nil, err
+		}
+		if err := h.UserManager.InsertUser(ctx, newUser); err != nil {
+			return nil, err
+		}
+		h.Logger.Info("DISABLE_AUTH: created new user", "username", username)
+		return &newUser, nil
+	}
+
+	if user.SuspendedStatus > 0 {
+		return nil, state.ErrUserSuspended
+	}
+
+	return user, nil
+}
+
+// sendLoginError reports a rejected clientLogin request. Like the original
+// WebAPI, the failure reason is conveyed by the status and detail codes in
+// the response body rather than the HTTP status.
+func sendLoginError(w http.ResponseWriter, r *http.Request, statusCode int, detailCode int, statusText string, logger *slog.Logger) {
+	resp := BaseResponse{}
+	resp.Response.StatusCode = statusCode
+	resp.Response.StatusText = statusText
+	resp.Response.StatusDetailCode = detailCode
+	SendResponse(w, r, resp, logger)
+}
+
 // generateToken generates a secure random token.
 func (h *AuthHandler) generateToken() (string, error) {
 	b := make([]byte, 32)
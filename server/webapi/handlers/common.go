@@ -41,9 +41,12 @@ type BaseResponse struct {
 
 // ResponseBody contains the status and data for API responses.
 type ResponseBody struct {
-	StatusCode int         `json:"statusCode" xml:"statusCode"`
-	StatusText string      `json:"statusText" xml:"statusText"`
-	Data       interface{} `json:"data,omitempty" xml:"data,omitempty"`
+	StatusCode int    `json:"statusCode" xml:"statusCode"`
+	StatusText string `json:"statusText" xml:"statusText"`
+	// StatusDetailCode further qualifies a non-200 StatusCode, e.g. the
+	// reason a clientLogin request was rejected.
+	StatusDetailCode int         `json:"statusDetailCode,omitempty" xml:"statusDetailCode,omitempty"`
+	Data             interface{} `json:"data,omitempty" xml:"data,omitempty"`
 }
 
 // ErrorResponse represents an error response with proper XML/JSON support.
@@ -60,10 +63,11 @@ type ErrorResponse struct {
 
 // XMLMapResponse is a helper struct for converting map-based responses to XML
 type XMLMapResponse struct {
-	XMLName    xml.Name `xml:"response"`
-	StatusCode int      `xml:"statusCode"`
-	StatusText string   `xml:"statusText"`
-	Data       XMLData  `xml:"data,omitempty"`
+	XMLName          xml.Name `xml:"response"`
+	StatusCode       int      `xml:"statusCode"`
+	StatusText       string   `xml:"statusText"`
+	StatusDetailCode int      `xml:"statusDetailCode,omitempty"`
+	Data             XMLData  `xml:"data,omitempty"`
 }
 
 // XMLData wraps the data for XML responses
@@ -315,8 +319,9 @@ func SendAMF(w http.ResponseWriter, r *http.Request, data interface{}, logger *s
 // convertBaseResponseForXML converts a BaseResponse with map data to XMLMapResponse
 func convertBaseResponseForXML(resp BaseResponse) XMLMapResponse {
 	xmlResp := XMLMapResponse{
-		StatusCode: resp.Response.StatusCode,
-		StatusText: resp.Response.StatusText,
+		StatusCode:       resp.Response.StatusCode,
+		StatusText:       resp.Response.StatusText,
+		StatusDetailCode: resp.Response.StatusDetailCode,
 	}
 
 	// Convert map data to XMLData struct
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -23,7 +24,12 @@ type MessageRelayer interface {
 
 // OfflineMessageManager defines methods for managing offline messages
 type OfflineMessageManager interface {
-	SaveMessage(ctx context.Context, msg state.OfflineMessage) error
+	SaveMessage(ctx context.Context, msg state.OfflineMessage, maxQueued int) error
+}
+
+// IMArchiver defines methods for recording instant messages in the IM archive
+type IMArchiver interface {
+	ArchiveIM(ctx context.Context, im state.ArchivedIM) error
 }
 
 // RelationshipFetcher defines methods for fetching user relationships
@@ -36,6 +42,8 @@ type MessagingHandler struct {
 	SessionManager        *state.WebAPISessionManager
 	MessageRelayer        MessageRelayer
 	OfflineMessageManager OfflineMessageManager
+	MaxOfflineMessages    int        // 0 means no limit
+	IMArchiver            IMArchiver // nil if IM archiving is disabled
 	SessionRetriever      SessionRetriever
 	RelationshipFetcher   RelationshipFetcher
 	Logger                *slog.Logger
@@ -163,7 +171,15 @@ func (h *MessagingHandler) SendIM(w http.ResponseWriter, r *http.Request) {
 				Sent:      time.Now().UTC(),
 			}
 
-			if err := h.OfflineMessageManager.SaveMessage(ctx, offlineMsg); err != nil {
+			err := h.OfflineMessageManager.SaveMessage(ctx, offlineMsg, h.MaxOfflineMessages)
+			switch {
+			case errors.Is(err, state.ErrNoUser):
+				h.sendErrorResponse(w, http.StatusNotFound, "recipient does not exist")
+				return
+			case errors.Is(err, state.ErrOfflineQueueFull):
+				h.sendErrorResponse(w, http.StatusTooManyRequests, "recipient's offline message queue is full")
+				return
+			case err != nil:
 				h.Logger.ErrorContext(ctx, "failed to save offline message",
 					"from", sess.ScreenName.String(),
 					"to", recipient,
@@ -175,6 +191,8 @@ func (h *MessagingHandler) SendIM(w http.ResponseWriter, r *http.Request) {
 			h.Logger.DebugContext(ctx, "saved offline message",
 				"from", sess.ScreenName.String(),
 				"to", recipient)
+
+			h.archiveIM(ctx, sess.ScreenName.IdentScreenName(), recipientIdent, message)
 		} else {
 			// Recipient is offline and offline delivery is disabled
 			h.sendErrorResponse(w, http.StatusNotFound, "recipient is not online")
@@ -245,6 +263,8 @@ func (h *MessagingHandler) SendIM(w http.ResponseWriter, r *http.Request) {
 		h.Logger.DebugContext(ctx, "delivered instant message",
 			"from", sess.ScreenName.String(),
 			"to", recipient)
+
+		h.archiveIM(ctx, sess.ScreenName.IdentScreenName(), recipientIdent, message)
 	}
 
 	// Send success response
@@ -259,6 +279,26 @@ func (h *MessagingHandler) SendIM(w http.ResponseWriter, r *http.Request) {
 	SendResponse(w, r, response, h.Logger)
 }
 
+// archiveIM records a sent message in the IM archive, if IM archiving is
+// enabled. Failures are only logged since the message was already sent.
+func (h *MessagingHandler) archiveIM(ctx context.Context, sender, recipient state.IdentScreenName, text string) {
+	if h.IMArchiver == nil {
+		return
+	}
+	im := state.ArchivedIM{
+		SentAt:    time.Now().UTC(),
+		Sender:    sender,
+		Recipient: recipient,
+		Text:      text,
+	}
+	if err := h.IMArchiver.ArchiveIM(ctx, im); err != nil {
+		h.Logger.ErrorContext(ctx, "failed to archive instant message",
+			"from", sender.String(),
+			"to", recipient.String(),
+			"error", err)
+	}
+}
+
 // encodeIMMessage encodes a text message into the OSCAR IM format
 func (h *MessagingHandler) encodeIMMessage(text string, autoResponse bool) []byte {
 	// Create ICBM fragment list for the message
@@ -34,7 +34,7 @@ type OSCARAuthService interface {
 	// RetrieveBOSSession retrieves an existing BOS session
 	RetrieveBOSSession(ctx context.Context, authCookie state.ServerCookie) (*state.Session, error)
 	// Signout ends an OSCAR session
-	Signout(ctx context.Context, sess *state.Session)
+	Signout(ctx context.Context, sess *state.Session, cleanup func(lastInstance bool))
 }
 
 // CookieBaker issues and validates authentication cookies for OSCAR services.
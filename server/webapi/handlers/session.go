@@ -27,6 +27,8 @@ type SessionHandler struct {
 	BuddyBroadcaster    BuddyBroadcaster
 	BuddyListManager    *BuddyListManager
 	TokenStore          TokenStore
+	MOTDManager         MOTDManager
+	OfflineMessages     OfflineMessageDeliverer
 	Logger              *slog.Logger
 }
 
@@ -39,9 +41,10 @@ type AuthService interface {
 
 // SessionManager defines methods for OSCAR session management.
 type SessionManager interface {
-	AddSession(ctx context.Context, screenName state.DisplayScreenName) (*state.Session, error)
+	AddSession(ctx context.Context, screenName state.DisplayScreenName, multiSess bool) (*state.Session, error)
 	RemoveSession(sess *state.Session)
 	RelayToScreenName(ctx context.Context, screenName state.IdentScreenName, msg wire.SNACMessage)
+	SignoffSession(sess *state.Session, cleanup func(lastInstance bool))
 }
 
 // BuddyListRegistry defines methods for buddy list management.
@@ -50,6 +53,16 @@ type BuddyListRegistry interface {
 	UnregisterBuddyList(ctx context.Context, screenName state.IdentScreenName) error
 }
 
+// MOTDManager provides the message of the day.
+type MOTDManager interface {
+	Message() string
+}
+
+// OfflineMessageDeliverer sends users the messages they received while offline.
+type OfflineMessageDeliverer interface {
+	DeliverOfflineMessages(ctx context.Context, sess *state.Session) error
+}
+
 // BuddyListService defines methods for buddy list operations.
 type BuddyListService interface {
 	GetBuddyList(ctx context.Context, screenName state.IdentScreenName) ([]BuddyGroup, error)
@@ -213,8 +226,9 @@ func (h *SessionHandler) StartSession(w http.ResponseWriter, r *http.Request) {
 	var oscarSession *state.Session
 	var err error
 	if authToken != "" && h.OSCARSessionManager != nil {
-		// Create OSCAR session
-		oscarSession, err = h.OSCARSessionManager.AddSession(ctx, screenName)
+		// Create OSCAR session. Web clients can stay signed on alongside the
+		// user's other multi-session clients.
+		oscarSession, err = h.OSCARSessionManager.AddSession(ctx, screenName, true)
 		if err != nil {
 			h.Logger.ErrorContext(ctx, "failed to create OSCAR session", "err", err.Error())
 			// Continue without OSCAR session - WebAPI can work standalone
@@ -275,6 +289,19 @@ func (h *SessionHandler) StartSession(w http.ResponseWriter, r *http.Request) {
 				break
 			}
 		}
+
+		if h.MOTDManager != nil {
+			if motd := h.MOTDManager.Message(); motd != "" {
+				session.PushMOTD(motd)
+			}
+		}
+
+		// Deliver messages received while offline
+		if h.OfflineMessages != nil && oscarSession != nil {
+			if err := h.OfflineMessages.DeliverOfflineMessages(ctx, oscarSession); err != nil {
+				h.Logger.ErrorContext(ctx, "failed to deliver offline messages", "err", err.Error())
+			}
+		}
 	}
 
 	// Prepare response
@@ -509,22 +536,22 @@ func (h *SessionHandler) EndSession(w http.ResponseWriter, r *http.Request) {
 
 	// Clean up OSCAR session if present
 	if session.OSCARSession != nil && h.OSCARSessionManager != nil {
-		// Broadcast departure to OSCAR clients
-		if h.BuddyBroadcaster != nil {
-			if err := h.BuddyBroadcaster.BroadcastBuddyDeparted(ctx, session.OSCARSession); err != nil {
-				h.Logger.ErrorContext(ctx, "failed to broadcast buddy departure", "err", err.Error())
+		// Remove OSCAR session after cleaning up
+		h.OSCARSessionManager.SignoffSession(session.OSCARSession, func(lastInstance bool) {
+			// Broadcast departure to OSCAR clients
+			if h.BuddyBroadcaster != nil {
+				if err := h.BuddyBroadcaster.BroadcastBuddyDeparted(ctx, session.OSCARSession); err != nil {
+					h.Logger.ErrorContext(ctx, "failed to broadcast buddy departure", "err", err.Error())
+				}
 			}
-		}
 
-		// Unregister buddy list
-		if h.BuddyListRegistry != nil {
-			if err := h.BuddyListRegistry.UnregisterBuddyList(ctx, session.ScreenName.IdentScreenName()); err != nil {
-				h.Logger.ErrorContext(ctx, "failed to unregister buddy list", "err", err.Error())
+			// Unregister buddy list, unless the user is still signed on elsewhere
+			if h.BuddyListRegistry != nil && lastInstance {
+				if err := h.BuddyListRegistry.UnregisterBuddyList(ctx, session.ScreenName.IdentScreenName()); err != nil {
+					h.Logger.ErrorContext(ctx, "failed to unregister buddy list", "err", err.Error())
+				}
 			}
-		}
-
-		// Remove OSCAR session
-		h.OSCARSessionManager.RemoveSession(session.OSCARSession)
+		})
 		session.OSCARSession = nil
 	}
 
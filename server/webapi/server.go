@@ -22,10 +22,11 @@ func NewServer(listeners []string, logger *slog.Logger, handler Handler, apiKeyV
 
 	// Create handlers
 	authHandler := &handlers.AuthHandler{
-		UserManager: handler.UserManager,
-		TokenStore:  handler.TokenStore,
-		Logger:      logger,
-		DisableAuth: handler.OSCARConfig.IsAuthDisabled(),
+		UserManager:  handler.UserManager,
+		TokenStore:   handler.TokenStore,
+		LoginLockout: handler.LoginLockout,
+		AuthMode:     handler.AuthMode,
+		Logger:       logger,
 	}
 
 	sessionHandler := &handlers.SessionHandler{
@@ -37,6 +38,8 @@ func NewServer(listeners []string, logger *slog.Logger, handler Handler, apiKeyV
 		BuddyBroadcaster:    handler.BuddyBroadcaster,
 		BuddyListManager:    handler.BuddyListManager.(*handlers.BuddyListManager),
 		TokenStore:          handler.TokenStore,
+		MOTDManager:         handler.MOTDManager,
+		OfflineMessages:     handler.ICBMService,
 		Logger:              logger,
 	}
 
@@ -66,6 +69,8 @@ func NewServer(listeners []string, logger *slog.Logger, handler Handler, apiKeyV
 		SessionManager:        sessionManager,
 		MessageRelayer:        handler.MessageRelayer,
 		OfflineMessageManager: handler.OfflineMessageManager,
+		MaxOfflineMessages:    handler.MaxOfflineMessages,
+		IMArchiver:            handler.IMArchiver,
 		SessionRetriever:      handler.SessionRetriever,
 		RelationshipFetcher:   handler.RelationshipFetcher,
 		Logger:                logger,
@@ -32,6 +32,7 @@ type ChatNavService interface {
 type ICBMService interface {
 	ChannelMsgToHost(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x04_0x06_ICBMChannelMsgToHost) (*wire.SNACMessage, error)
 	ClientEvent(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x04_0x14_ICBMClientEvent) error
+	DeliverOfflineMessages(ctx context.Context, sess *state.Session) error
 	EvilRequest(ctx context.Context, sess *state.Session, inFrame wire.SNACFrame, inBody wire.SNAC_0x04_0x08_ICBMEvilRequest) (wire.SNACMessage, error)
 	ParameterQuery(ctx context.Context, inFrame wire.SNACFrame) wire.SNACMessage
 	ClientErr(ctx context.Context, sess *state.Session, frame wire.SNACFrame, body wire.SNAC_0x04_0x0B_ICBMClientErr) error
@@ -51,7 +52,7 @@ type AuthService interface {
 	RegisterBOSSession(ctx context.Context, authCookie state.ServerCookie) (*state.Session, error)
 	RegisterChatSession(ctx context.Context, authCookie state.ServerCookie) (*state.Session, error)
 	RetrieveBOSSession(ctx context.Context, authCookie state.ServerCookie) (*state.Session, error)
-	Signout(ctx context.Context, sess *state.Session)
+	Signout(ctx context.Context, sess *state.Session, cleanup func(lastInstance bool))
 	SignoutChat(ctx context.Context, sess *state.Session)
 }
 
@@ -135,11 +136,16 @@ type MessageRelayer interface {
 
 // OfflineMessageManager manages offline message storage and retrieval
 type OfflineMessageManager interface {
-	SaveMessage(ctx context.Context, msg state.OfflineMessage) error
+	SaveMessage(ctx context.Context, msg state.OfflineMessage, maxQueued int) error
 	RetrieveMessages(ctx context.Context, recipient state.IdentScreenName) ([]state.OfflineMessage, error)
 	DeleteMessages(ctx context.Context, recipient state.IdentScreenName) error
 }
 
+// IMArchiver records instant messages in the server-side IM archive
+type IMArchiver interface {
+	ArchiveIM(ctx context.Context, im state.ArchivedIM) error
+}
+
 // BuddyBroadcaster broadcasts buddy presence updates
 type BuddyBroadcaster interface {
 	BroadcastBuddyArrived(ctx context.Context, screenName state.IdentScreenName, userInfo wire.TLVUserInfo) error
@@ -154,14 +160,44 @@ type ProfileManager interface {
 
 // UserManager defines methods for user authentication.
 type UserManager interface {
-	// AuthenticateUser verifies username and password
-	AuthenticateUser(ctx context.Context, username, password string) (*state.User, error)
+	// AuthenticateUser verifies username and password, along with a
+	// one-time code for users enrolled in two-factor auth. The password is
+	// checked against provider if set.
+	AuthenticateUser(ctx context.Context, provider state.AuthProvider, username, password, securID string) (*state.User, error)
 	// FindUserByScreenName finds a user by their screen name
 	FindUserByScreenName(ctx context.Context, screenName state.IdentScreenName) (*state.User, error)
 	// InsertUser creates a new user (for DISABLE_AUTH mode)
 	InsertUser(ctx context.Context, u state.User) error
 }
 
+// AuthMode provides the authentication settings, which can change when the
+// configuration is reloaded.
+type AuthMode interface {
+	// AuthDisabled indicates whether password checks are skipped
+	AuthDisabled() bool
+	// AuthProvider returns the external provider that checks passwords, or
+	// nil if passwords are checked locally
+	AuthProvider() state.AuthProvider
+}
+
+// MOTDManager provides the message of the day shown to users when they sign
+// on.
+type MOTDManager interface {
+	// Message returns the message of the day, or an empty string if none is
+	// set.
+	Message() string
+}
+
+// LoginLockoutManager tracks failed login attempts per account.
+type LoginLockoutManager interface {
+	// IsLocked indicates whether the account is currently locked out
+	IsLocked(screenName state.IdentScreenName) bool
+	// RecordFailure registers a failed login attempt for the account
+	RecordFailure(screenName state.IdentScreenName)
+	// RecordSuccess clears the failed login attempts for the account
+	RecordSuccess(screenName state.IdentScreenName)
+}
+
 // TokenStore manages authentication tokens.
 type TokenStore interface {
 	// StoreToken saves an authentication token for a user
@@ -0,0 +1,305 @@
+package state
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrNoAPIAdmin indicates that a management API account does not exist.
+	ErrNoAPIAdmin = errors.New("management API account does not exist")
+	// ErrDupAPIAdmin indicates that a management API account already exists.
+	ErrDupAPIAdmin = errors.New("management API account already exists")
+)
+
+// apiTokenLen is the size of a management API bearer token in bytes.
+const apiTokenLen = 32
+
+// dummyAPIAdminHash is compared against the password when authenticating an
+// account that doesn't exist, so that unknown usernames take as long to
+// reject as wrong passwords.
+var dummyAPIAdminHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), PasswordHashCost)
+	return hash
+})
+
+// APIRole is the set of management API operations an account may perform.
+// Each role includes the operations of the roles below it.
+type APIRole string
+
+const (
+	// APIRoleReadOnly accounts may view users, sessions, and server state.
+	APIRoleReadOnly APIRole = "readonly"
+	// APIRoleModerator accounts may also moderate users and content, for
+	// example by ending sessions, clearing lockouts, and sending messages.
+	APIRoleModerator APIRole = "moderator"
+	// APIRoleAdmin accounts may perform any operation, including managing
+	// user accounts, credentials, and management API accounts.
+	APIRoleAdmin APIRole = "admin"
+)
+
+// level returns the rank of the role, or 0 if the role is unknown.
+func (r APIRole) level() int {
+	switch r {
+	case APIRoleReadOnly:
+		return 1
+	case APIRoleModerator:
+		return 2
+	case APIRoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Valid indicates whether r is a known role.
+func (r APIRole) Valid() bool {
+	return r.level() > 0
+}
+
+// Includes indicates whether an account with role r may perform operations
+// that require role other.
+func (r APIRole) Includes(other APIRole) bool {
+	return r.Valid() && r.level() >= other.level()
+}
+
+// APIAdmin is an account that may access the management API.
+type APIAdmin struct {
+	// Username identifies the account. It's case-insensitive.
+	Username string
+	// Role determines which operations the account may perform.
+	Role APIRole
+	// HasToken indicates whether a bearer token has been issued for the
+	// account.
+	HasToken bool
+	// CreatedAt is when the account was created.
+	CreatedAt time.Time
+}
+
+// hashAPIToken returns the digest under which a bearer token is stored.
+// Tokens are random and high-entropy, so an unsalted hash suffices.
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// InsertAPIAdmin creates a management API account with a bcrypt hash of
+// password. It returns ErrDupAPIAdmin if the username is taken.
+func (f SQLiteUserStore) InsertAPIAdmin(ctx context.Context, username, password string, role APIRole, createdAt time.Time) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), PasswordHashCost)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	q := `
+		INSERT INTO apiAdmin (username, passwordHash, role, createdAt)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (username) DO NOTHING
+	`
+	res, err := f.db.ExecContext(ctx, q, username, string(hash), role, createdAt.Unix())
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrDupAPIAdmin
+	}
+	return nil
+}
+
+// BootstrapAPIAdmin creates an "admin" account with the admin role and a
+// random password if no management API accounts exist, so that a fresh
+// installation can be managed. It returns the generated password, or an
+// empty string if accounts already exist.
+func (f SQLiteUserStore) BootstrapAPIAdmin(ctx context.Context, createdAt time.Time) (string, error) {
+	var count int
+	if err := f.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM apiAdmin`).Scan(&count); err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", nil
+	}
+
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating password: %w", err)
+	}
+	password := base64.RawURLEncoding.EncodeToString(buf)
+
+	if err := f.InsertAPIAdmin(ctx, "admin", password, APIRoleAdmin, createdAt); err != nil {
+		if errors.Is(err, ErrDupAPIAdmin) {
+			// another instance sharing the database got there first
+			return "", nil
+		}
+		return "", err
+	}
+	return password, nil
+}
+
+// APIAdmins returns all management API accounts ordered by username.
+func (f SQLiteUserStore) APIAdmins(ctx context.Context) ([]APIAdmin, error) {
+	q := `
+		SELECT username, role, tokenHash != '', createdAt
+		FROM apiAdmin
+		ORDER BY username
+	`
+	rows, err := f.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var admins []APIAdmin
+	for rows.Next() {
+		var createdAt int64
+		admin := APIAdmin{}
+		if err := rows.Scan(&admin.Username, &admin.Role, &admin.HasToken, &createdAt); err != nil {
+			return nil, err
+		}
+		admin.CreatedAt = time.Unix(createdAt, 0).UTC()
+		admins = append(admins, admin)
+	}
+
+	return admins, rows.Err()
+}
+
+// SetAPIAdminRole changes the role of a management API account. It returns
+// ErrNoAPIAdmin if the account does not exist.
+func (f SQLiteUserStore) SetAPIAdminRole(ctx context.Context, username string, role APIRole) error {
+	res, err := f.db.ExecContext(ctx, `UPDATE apiAdmin SET role = ? WHERE username = ?`, role, username)
+	if err != nil {
+		return err
+	}
+	return apiAdminAffected(res)
+}
+
+// SetAPIAdminPassword changes the password of a management API account. It
+// returns ErrNoAPIAdmin if the account does not exist.
+func (f SQLiteUserStore) SetAPIAdminPassword(ctx context.Context, username, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), PasswordHashCost)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+	res, err := f.db.ExecContext(ctx, `UPDATE apiAdmin SET passwordHash = ? WHERE username = ?`, string(hash), username)
+	if err != nil {
+		return err
+	}
+	return apiAdminAffected(res)
+}
+
+// DeleteAPIAdmin removes a management API account. It returns ErrNoAPIAdmin
+// if the account does not exist.
+func (f SQLiteUserStore) DeleteAPIAdmin(ctx context.Context, username string) error {
+	res, err := f.db.ExecContext(ctx, `DELETE FROM apiAdmin WHERE username = ?`, username)
+	if err != nil {
+		return err
+	}
+	return apiAdminAffected(res)
+}
+
+// IssueAPIAdminToken creates a bearer token for a management API account,
+// replacing the account's previous token. Only a hash of the token is
+// stored, so the returned value can't be recovered later. It returns
+// ErrNoAPIAdmin if the account does not exist.
+func (f SQLiteUserStore) IssueAPIAdminToken(ctx context.Context, username string) (string, error) {
+	buf := make([]byte, apiTokenLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	res, err := f.db.ExecContext(ctx, `UPDATE apiAdmin SET tokenHash = ? WHERE username = ?`, hashAPIToken(token), username)
+	if err != nil {
+		return "", err
+	}
+	if err := apiAdminAffected(res); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RevokeAPIAdminToken removes the bearer token of a management API
+// account. It returns ErrNoAPIAdmin if the account does not exist.
+func (f SQLiteUserStore) RevokeAPIAdminToken(ctx context.Context, username string) error {
+	res, err := f.db.ExecContext(ctx, `UPDATE apiAdmin SET tokenHash = '' WHERE username = ?`, username)
+	if err != nil {
+		return err
+	}
+	return apiAdminAffected(res)
+}
+
+// AuthenticateAPIAdmin returns the management API account identified by
+// username and password, or nil if the credentials are invalid.
+func (f SQLiteUserStore) AuthenticateAPIAdmin(ctx context.Context, username, password string) (*APIAdmin, error) {
+	q := `
+		SELECT username, passwordHash, role, tokenHash != '', createdAt
+		FROM apiAdmin
+		WHERE username = ?
+	`
+	var passwordHash string
+	var createdAt int64
+	admin := APIAdmin{}
+	err := f.db.QueryRowContext(ctx, q, username).Scan(&admin.Username, &passwordHash, &admin.Role, &admin.HasToken, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = bcrypt.CompareHashAndPassword(dummyAPIAdminHash(), []byte(password))
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)) != nil {
+		return nil, nil
+	}
+	admin.CreatedAt = time.Unix(createdAt, 0).UTC()
+	return &admin, nil
+}
+
+// AuthenticateAPIAdminToken returns the management API account that a
+// bearer token was issued for, or nil if the token is invalid.
+func (f SQLiteUserStore) AuthenticateAPIAdminToken(ctx context.Context, token string) (*APIAdmin, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, nil
+	}
+	q := `
+		SELECT username, role, createdAt
+		FROM apiAdmin
+		WHERE tokenHash = ?
+	`
+	var createdAt int64
+	admin := APIAdmin{HasToken: true}
+	err := f.db.QueryRowContext(ctx, q, hashAPIToken(token)).Scan(&admin.Username, &admin.Role, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	admin.CreatedAt = time.Unix(createdAt, 0).UTC()
+	return &admin, nil
+}
+
+// apiAdminAffected returns ErrNoAPIAdmin if res affected no rows.
+func apiAdminAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoAPIAdmin
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package state
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIRole_Includes(t *testing.T) {
+	assert.True(t, APIRoleAdmin.Includes(APIRoleAdmin))
+	assert.True(t, APIRoleAdmin.Includes(APIRoleReadOnly))
+	assert.True(t, APIRoleModerator.Includes(APIRoleReadOnly))
+	assert.False(t, APIRoleModerator.Includes(APIRoleAdmin))
+	assert.False(t, APIRoleReadOnly.Includes(APIRoleModerator))
+	assert.False(t, APIRole("root").Includes(APIRoleReadOnly))
+	assert.False(t, APIRole("").Valid())
+}
+
+func TestSQLiteUserStore_APIAdmins(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, userStore.InsertAPIAdmin(ctx, "alice", "alicepass", APIRoleAdmin, now))
+	require.NoError(t, userStore.InsertAPIAdmin(ctx, "bob", "bobpass", APIRoleReadOnly, now))
+	assert.ErrorIs(t, userStore.InsertAPIAdmin(ctx, "Alice", "otherpass", APIRoleReadOnly, now), ErrDupAPIAdmin)
+
+	t.Run("password auth", func(t *testing.T) {
+		admin, err := userStore.AuthenticateAPIAdmin(ctx, "ALICE", "alicepass")
+		require.NoError(t, err)
+		assert.Equal(t, &APIAdmin{Username: "alice", Role: APIRoleAdmin, CreatedAt: now}, admin)
+
+		admin, err = userStore.AuthenticateAPIAdmin(ctx, "alice", "wrongpass")
+		require.NoError(t, err)
+		assert.Nil(t, admin)
+
+		admin, err = userStore.AuthenticateAPIAdmin(ctx, "carol", "alicepass")
+		require.NoError(t, err)
+		assert.Nil(t, admin)
+	})
+
+	t.Run("token auth", func(t *testing.T) {
+		token, err := userStore.IssueAPIAdminToken(ctx, "bob")
+		require.NoError(t, err)
+
+		admin, err := userStore.AuthenticateAPIAdminToken(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, &APIAdmin{Username: "bob", Role: APIRoleReadOnly, HasToken: true, CreatedAt: now}, admin)
+
+		// issuing a new token invalidates the old one
+		newToken, err := userStore.IssueAPIAdminToken(ctx, "bob")
+		require.NoError(t, err)
+		admin, err = userStore.AuthenticateAPIAdminToken(ctx, token)
+		require.NoError(t, err)
+		assert.Nil(t, admin)
+
+		require.NoError(t, userStore.RevokeAPIAdminToken(ctx, "bob"))
+		admin, err = userStore.AuthenticateAPIAdminToken(ctx, newToken)
+		require.NoError(t, err)
+		assert.Nil(t, admin)
+
+		admin, err = userStore.AuthenticateAPIAdminToken(ctx, "")
+		require.NoError(t, err)
+		assert.Nil(t, admin)
+
+		_, err = userStore.IssueAPIAdminToken(ctx, "carol")
+		assert.ErrorIs(t, err, ErrNoAPIAdmin)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		require.NoError(t, userStore.SetAPIAdminRole(ctx, "bob", APIRoleModerator))
+		require.NoError(t, userStore.SetAPIAdminPassword(ctx, "bob", "newpass"))
+		admin, err := userStore.AuthenticateAPIAdmin(ctx, "bob", "newpass")
+		require.NoError(t, err)
+		require.NotNil(t, admin)
+		assert.Equal(t, APIRoleModerator, admin.Role)
+
+		assert.ErrorIs(t, userStore.SetAPIAdminRole(ctx, "carol", APIRoleModerator), ErrNoAPIAdmin)
+		assert.ErrorIs(t, userStore.SetAPIAdminPassword(ctx, "carol", "pass"), ErrNoAPIAdmin)
+
+		require.NoError(t, userStore.DeleteAPIAdmin(ctx, "BOB"))
+		assert.ErrorIs(t, userStore.DeleteAPIAdmin(ctx, "bob"), ErrNoAPIAdmin)
+
+		admins, err := userStore.APIAdmins(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []APIAdmin{{Username: "alice", Role: APIRoleAdmin, CreatedAt: now}}, admins)
+	})
+}
+
+func TestSQLiteUserStore_BootstrapAPIAdmin(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	password, err := userStore.BootstrapAPIAdmin(ctx, now)
+	require.NoError(t, err)
+	require.NotEmpty(t, password)
+
+	admin, err := userStore.AuthenticateAPIAdmin(ctx, "admin", password)
+	require.NoError(t, err)
+	assert.Equal(t, &APIAdmin{Username: "admin", Role: APIRoleAdmin, CreatedAt: now}, admin)
+
+	// accounts already exist, so nothing is created
+	password, err = userStore.BootstrapAPIAdmin(ctx, now)
+	require.NoError(t, err)
+	assert.Empty(t, password)
+}
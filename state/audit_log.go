@@ -0,0 +1,116 @@
+package state
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// AuditEntry records an administrative action performed through the
+// management API.
+type AuditEntry struct {
+	// ID uniquely identifies the entry. It's assigned when the entry is
+	// recorded.
+	ID int64
+	// CreatedAt is when the action was performed.
+	CreatedAt time.Time
+	// Actor is the management API account that performed the action.
+	Actor string
+	// Action identifies the operation, e.g. "DELETE /user".
+	Action string
+	// Target identifies what the action was performed on, e.g. a screen
+	// name. It's empty if the action has no target.
+	Target string
+	// Before is the JSON-encoded state of the target before the action, or
+	// empty if unknown.
+	Before string
+	// After is the JSON-encoded state of the target after the action, or
+	// empty if unknown.
+	After string
+	// SourceIP is the address the request came from.
+	SourceIP string
+}
+
+// AuditFilter narrows the entries returned by AuditEntries. Zero-valued
+// fields match every entry.
+type AuditFilter struct {
+	// Actor matches entries performed by this account.
+	Actor string
+	// Target matches entries performed on this target.
+	Target string
+	// Since matches entries created at or after this time.
+	Since time.Time
+	// Until matches entries created before this time.
+	Until time.Time
+	// Limit is the maximum number of entries to return.
+	Limit int
+}
+
+// InsertAuditEntry appends an entry to the audit log. The entry's ID is
+// ignored. Recorded entries can't be modified or deleted.
+func (f SQLiteUserStore) InsertAuditEntry(ctx context.Context, entry AuditEntry) error {
+	q := `
+		INSERT INTO auditLog (createdAt, actor, action, target, oldValue, newValue, sourceIP)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := f.db.ExecContext(ctx, q, entry.CreatedAt.Unix(), entry.Actor, entry.Action, entry.Target,
+		entry.Before, entry.After, entry.SourceIP)
+	return err
+}
+
+// AuditEntries returns the audit log entries that match filter, newest
+// first.
+func (f SQLiteUserStore) AuditEntries(ctx context.Context, filter AuditFilter) ([]AuditEntry, error) {
+	var args []any
+	clauses := []string{"1 = 1"}
+
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		clauses = append(clauses, `actor = ?`)
+	}
+	if filter.Target != "" {
+		args = append(args, filter.Target)
+		clauses = append(clauses, `target = ?`)
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since.Unix())
+		clauses = append(clauses, `createdAt >= ?`)
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until.Unix())
+		clauses = append(clauses, `createdAt < ?`)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	args = append(args, limit)
+
+	q := `
+		SELECT id, createdAt, actor, action, target, oldValue, newValue, sourceIP
+		FROM auditLog
+		WHERE ` + strings.Join(clauses, " AND ") + `
+		ORDER BY id DESC
+		LIMIT ?
+	`
+	rows, err := f.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var createdAt int64
+		entry := AuditEntry{}
+		if err := rows.Scan(&entry.ID, &createdAt, &entry.Actor, &entry.Action, &entry.Target, &entry.Before,
+			&entry.After, &entry.SourceIP); err != nil {
+			return nil, err
+		}
+		entry.CreatedAt = time.Unix(createdAt, 0).UTC()
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,87 @@
+package state
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteUserStore_AuditLog(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	entries := []AuditEntry{
+		{CreatedAt: t0, Actor: "alice", Action: "DELETE /user", Target: "bob", SourceIP: "127.0.0.1"},
+		{CreatedAt: t0.Add(time.Hour), Actor: "carol", Action: "PUT /motd", Before: `{"message":"hi"}`, After: `{"message":"hello"}`, SourceIP: "10.0.0.1"},
+		{CreatedAt: t0.Add(2 * time.Hour), Actor: "alice", Action: "DELETE /session/{screenname}", Target: "Bob", SourceIP: "127.0.0.1"},
+	}
+	for _, entry := range entries {
+		require.NoError(t, userStore.InsertAuditEntry(ctx, entry))
+	}
+	for i := range entries {
+		entries[i].ID = int64(i + 1)
+	}
+
+	tt := []struct {
+		name   string
+		filter AuditFilter
+		want   []AuditEntry
+	}{
+		{
+			name:   "all entries, newest first",
+			filter: AuditFilter{},
+			want:   []AuditEntry{entries[2], entries[1], entries[0]},
+		},
+		{
+			name:   "by actor",
+			filter: AuditFilter{Actor: "ALICE"},
+			want:   []AuditEntry{entries[2], entries[0]},
+		},
+		{
+			name:   "by target",
+			filter: AuditFilter{Target: "bob"},
+			want:   []AuditEntry{entries[2], entries[0]},
+		},
+		{
+			name:   "by time range",
+			filter: AuditFilter{Since: t0.Add(time.Hour), Until: t0.Add(2 * time.Hour)},
+			want:   []AuditEntry{entries[1]},
+		},
+		{
+			name:   "with limit",
+			filter: AuditFilter{Limit: 1},
+			want:   []AuditEntry{entries[2]},
+		},
+		{
+			name:   "no matches",
+			filter: AuditFilter{Actor: "dave"},
+			want:   nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := userStore.AuditEntries(ctx, tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("entries are append-only", func(t *testing.T) {
+		_, err := userStore.db.ExecContext(ctx, `UPDATE auditLog SET actor = 'mallory'`)
+		assert.ErrorContains(t, err, "audit log entries can not be modified")
+		_, err = userStore.db.ExecContext(ctx, `DELETE FROM auditLog`)
+		assert.ErrorContains(t, err, "audit log entries can not be deleted")
+	})
+}
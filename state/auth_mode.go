@@ -0,0 +1,45 @@
+package state
+
+import "sync"
+
+// AuthMode holds the authentication settings that can change while the
+// server runs: whether password checks are disabled and the AuthProvider, if
+// any, that checks passwords in place of the user store. An AuthMode is safe
+// for concurrent use by multiple goroutines.
+type AuthMode struct {
+	mutex    sync.RWMutex
+	disabled bool
+	provider AuthProvider
+}
+
+// NewAuthMode creates a new instance of AuthMode. A nil provider means
+// passwords are checked against the user store.
+func NewAuthMode(disabled bool, provider AuthProvider) *AuthMode {
+	return &AuthMode{
+		disabled: disabled,
+		provider: provider,
+	}
+}
+
+// AuthDisabled indicates whether password checks are disabled.
+func (m *AuthMode) AuthDisabled() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.disabled
+}
+
+// AuthProvider returns the provider that checks passwords, or nil if
+// passwords are checked against the user store.
+func (m *AuthMode) AuthProvider() AuthProvider {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.provider
+}
+
+// Set replaces the authentication settings.
+func (m *AuthMode) Set(disabled bool, provider AuthProvider) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.disabled = disabled
+	m.provider = provider
+}
@@ -0,0 +1,18 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMode(t *testing.T) {
+	mode := NewAuthMode(true, nil)
+	assert.True(t, mode.AuthDisabled())
+	assert.Nil(t, mode.AuthProvider())
+
+	provider := NewLDAPAuthProvider("ldap://127.0.0.1:389", "uid=%s,ou=people,dc=example,dc=org")
+	mode.Set(false, provider)
+	assert.False(t, mode.AuthDisabled())
+	assert.Equal(t, provider, mode.AuthProvider())
+}
@@ -0,0 +1,33 @@
+package state
+
+import (
+	"bytes"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCaptcha(t *testing.T) {
+	captcha, err := NewCaptcha()
+	require.NoError(t, err)
+
+	assert.Len(t, captcha.Answer, captchaLen)
+	for _, c := range captcha.Answer {
+		assert.True(t, c >= '0' && c <= '9')
+	}
+
+	_, err = jpeg.Decode(bytes.NewReader(captcha.Image))
+	assert.NoError(t, err)
+}
+
+func TestCaptcha_Matches(t *testing.T) {
+	captcha := Captcha{Answer: "12345"}
+	assert.True(t, captcha.Matches("12345"))
+	assert.True(t, captcha.Matches(" 12345 "))
+	assert.False(t, captcha.Matches("54321"))
+	assert.False(t, captcha.Matches(""))
+	// a connection that never requested an image can't match
+	assert.False(t, Captcha{}.Matches(""))
+}
@@ -0,0 +1,241 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// clusterSNACBodies maps the food group and subgroup of SNACs that are
+// relayed between users to a constructor for their body type. Bodies of
+// relayed SNACs are decoded back into their concrete type so that TOC and
+// Web API sessions can interpret them. SNACs not listed here are delivered
+// as raw bytes, which is sufficient for OSCAR clients.
+var clusterSNACBodies = map[uint16]map[uint16]func() any{
+	wire.OService: {
+		wire.OServiceUserInfoUpdate:   func() any { return &wire.SNAC_0x01_0x0F_OServiceUserInfoUpdate{} },
+		wire.OServiceEvilNotification: func() any { return &wire.SNAC_0x01_0x10_OServiceEvilNotification{} },
+		wire.OServiceMotd:             func() any { return &wire.SNAC_0x01_0x13_OServiceMotd{} },
+		wire.OServiceBartReply:        func() any { return &wire.SNAC_0x01_0x21_OServiceBARTReply{} },
+	},
+	wire.Buddy: {
+		wire.BuddyArrived:  func() any { return &wire.SNAC_0x03_0x0B_BuddyArrived{} },
+		wire.BuddyDeparted: func() any { return &wire.SNAC_0x03_0x0C_BuddyDeparted{} },
+	},
+	wire.ICBM: {
+		wire.ICBMChannelMsgToClient: func() any { return &wire.SNAC_0x04_0x07_ICBMChannelMsgToClient{} },
+		wire.ICBMClientErr:          func() any { return &wire.SNAC_0x04_0x0B_ICBMClientErr{} },
+		wire.ICBMClientEvent:        func() any { return &wire.SNAC_0x04_0x14_ICBMClientEvent{} },
+	},
+	wire.Popup: {
+		wire.PopupDisplay: func() any { return &wire.SNAC_0x08_0x02_PopupDisplay{} },
+	},
+	wire.Stats: {
+		wire.StatsSetMinReportInterval: func() any { return &wire.SNAC_0x0B_0x02_StatsSetMinReportInterval{} },
+	},
+	wire.Chat: {
+		wire.ChatRoomInfoUpdate:     func() any { return &wire.SNAC_0x0E_0x02_ChatRoomInfoUpdate{} },
+		wire.ChatUsersJoined:        func() any { return &wire.SNAC_0x0E_0x03_ChatUsersJoined{} },
+		wire.ChatUsersLeft:          func() any { return &wire.SNAC_0x0E_0x04_ChatUsersLeft{} },
+		wire.ChatChannelMsgToClient: func() any { return &wire.SNAC_0x0E_0x06_ChatChannelMsgToClient{} },
+	},
+	wire.Feedbag: {
+		wire.FeedbagInsertItem: func() any { return &wire.SNAC_0x13_0x08_FeedbagInsertItem{} },
+		wire.FeedbagUpdateItem: func() any { return &wire.SNAC_0x13_0x09_FeedbagUpdateItem{} },
+		wire.FeedbagDeleteItem: func() any { return &wire.SNAC_0x13_0x0A_FeedbagDeleteItem{} },
+	},
+	wire.ICQ: {
+		wire.ICQDBReply: func() any { return &wire.SNAC_0x15_0x02_DBReply{} },
+	},
+}
+
+// encodeClusterSNAC serializes a SNAC body in its wire format.
+func encodeClusterSNAC(msg wire.SNACMessage) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := wire.MarshalBE(msg.Body, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeClusterSNAC reconstructs a SNAC message relayed from another node.
+// The body is decoded into its concrete type if it's known, otherwise the
+// raw body bytes are used.
+func decodeClusterSNAC(frame wire.SNACFrame, body []byte) wire.SNACMessage {
+	msg := wire.SNACMessage{Frame: frame, Body: body}
+	newBody, ok := clusterSNACBodies[frame.FoodGroup][frame.SubGroup]
+	if !ok {
+		return msg
+	}
+	v := newBody()
+	if err := wire.UnmarshalBE(v, bytes.NewReader(body)); err != nil {
+		return msg
+	}
+	// dereference the pointer so that the body matches what local
+	// producers relay
+	msg.Body = reflect.ValueOf(v).Elem().Interface()
+	return msg
+}
+
+// clusterSession is a snapshot of a session owned by another node. It holds
+// the state needed to present the user to sessions on this node.
+type clusterSession struct {
+	AwayMessage       string            `json:"awayMessage,omitempty"`
+	Caps              [][16]byte        `json:"caps,omitempty"`
+	ChatRoomCookie    string            `json:"chatRoomCookie,omitempty"`
+	DisplayScreenName DisplayScreenName `json:"displayScreenName"`
+	IdleTime          time.Time         `json:"idleTime,omitzero"`
+	SignonTime        time.Time         `json:"signonTime"`
+	UIN               uint32            `json:"uin,omitempty"`
+	UserInfoBitmask   uint16            `json:"userInfoBitmask"`
+	UserStatusBitmask uint32            `json:"userStatusBitmask"`
+	Warning           uint16            `json:"warning,omitempty"`
+}
+
+// newClusterSession takes a snapshot of sess.
+func newClusterSession(sess *Session) clusterSession {
+	cs := clusterSession{
+		AwayMessage:       sess.AwayMessage(),
+		Caps:              sess.Caps(),
+		ChatRoomCookie:    sess.ChatRoomCookie(),
+		DisplayScreenName: sess.DisplayScreenName(),
+		SignonTime:        sess.SignonTime(),
+		UIN:               sess.UIN(),
+		UserInfoBitmask:   sess.UserInfoBitmask(),
+		UserStatusBitmask: sess.UserStatusBitmask(),
+		Warning:           sess.Warning(),
+	}
+	if sess.Idle() {
+		cs.IdleTime = sess.IdleTime()
+	}
+	return cs
+}
+
+// newRemoteSession creates a signed-on session that mirrors a session owned
+// by another node. Messages can't be sent through it directly; they must be
+// relayed through the cluster session manager. Changes made to the mirror
+// are sent to the owning node once the caller sets its owner.
+func newRemoteSession(cs clusterSession) *Session {
+	sess := NewSession()
+	sess.awayMessage = cs.AwayMessage
+	if cs.Caps != nil {
+		sess.caps = cs.Caps
+	}
+	sess.chatRoomCookie = cs.ChatRoomCookie
+	sess.displayScreenName = cs.DisplayScreenName
+	sess.identScreenName = cs.DisplayScreenName.IdentScreenName()
+	sess.idle = !cs.IdleTime.IsZero()
+	sess.idleTime = cs.IdleTime
+	sess.signonComplete = true
+	sess.signonTime = cs.SignonTime
+	sess.uin = cs.UIN
+	sess.userInfoBitmask = cs.UserInfoBitmask
+	sess.userStatusBitmask = cs.UserStatusBitmask
+	sess.warning = cs.Warning
+	return sess
+}
+
+// clusterUpdateOp identifies a change to a session owned by another node.
+type clusterUpdateOp string
+
+const (
+	clusterOpClose             clusterUpdateOp = "close"
+	clusterOpWarn              clusterUpdateOp = "warn"
+	clusterOpSetWarning        clusterUpdateOp = "setWarning"
+	clusterOpSetIdle           clusterUpdateOp = "setIdle"
+	clusterOpUnsetIdle         clusterUpdateOp = "unsetIdle"
+	clusterOpSetAwayMessage    clusterUpdateOp = "setAwayMessage"
+	clusterOpSetUserInfoFlag   clusterUpdateOp = "setUserInfoFlag"
+	clusterOpClearUserInfoFlag clusterUpdateOp = "clearUserInfoFlag"
+	clusterOpSetUserStatus     clusterUpdateOp = "setUserStatus"
+	clusterOpSetRateClasses    clusterUpdateOp = "setRateClasses"
+)
+
+// clusterUpdate is a change made to a mirror of a session owned by another
+// node. Only the fields used by Op are set.
+type clusterUpdate struct {
+	Op            clusterUpdateOp       `json:"op"`
+	AwayMessage   string                `json:"awayMessage,omitempty"`
+	ClassID       wire.RateLimitClassID `json:"classID,omitempty"`
+	Flag          uint16                `json:"flag,omitempty"`
+	Idle          time.Duration         `json:"idle,omitempty"`
+	Incr          int16                 `json:"incr,omitempty"`
+	RateClasses   [5]wire.RateClass     `json:"rateClasses,omitzero"`
+	StatusBitmask uint32                `json:"statusBitmask,omitempty"`
+	Warning       uint16                `json:"warning,omitempty"`
+}
+
+// applyClusterUpdate makes the change requested by env to the session in
+// sessions that it identifies. The result isn't OK if there is no such
+// session.
+func applyClusterUpdate(env clusterEnvelope, sessions []*Session) clusterResult {
+	if env.Update == nil {
+		return clusterResult{}
+	}
+	for _, sess := range sessions {
+		if sess.IdentScreenName() == NewIdentScreenName(env.ScreenName) && sess.SignonTime().Equal(env.SignonTime) {
+			return env.Update.apply(sess)
+		}
+	}
+	return clusterResult{}
+}
+
+// apply makes the change to sess.
+func (u clusterUpdate) apply(sess *Session) clusterResult {
+	switch u.Op {
+	case clusterOpClose:
+		sess.Close()
+	case clusterOpWarn:
+		ok, warning := sess.ScaleWarningAndRateLimit(u.Incr, u.ClassID)
+		return clusterResult{OK: ok, Warning: warning}
+	case clusterOpSetWarning:
+		sess.SetWarning(u.Warning)
+	case clusterOpSetIdle:
+		sess.SetIdle(u.Idle)
+	case clusterOpUnsetIdle:
+		sess.UnsetIdle()
+	case clusterOpSetAwayMessage:
+		sess.SetAwayMessage(u.AwayMessage)
+	case clusterOpSetUserInfoFlag:
+		sess.SetUserInfoFlag(u.Flag)
+	case clusterOpClearUserInfoFlag:
+		sess.ClearUserInfoFlag(u.Flag)
+	case clusterOpSetUserStatus:
+		sess.SetUserStatusBitmask(u.StatusBitmask)
+	case clusterOpSetRateClasses:
+		sess.SetRateClasses(time.Now(), wire.NewRateLimitClasses(u.RateClasses))
+	default:
+		return clusterResult{}
+	}
+	return clusterResult{OK: true}
+}
+
+// remoteOwner sends changes made to a mirror of a session to the node that
+// owns the session.
+type remoteOwner struct {
+	node       *clusterNode
+	peer       string
+	room       string
+	screenName IdentScreenName
+	signonTime time.Time
+}
+
+// update asks the owning node to make a change to the session and waits for
+// it to reply. Errors are logged, since the session methods that make
+// changes don't return them.
+func (o *remoteOwner) update(u clusterUpdate) (clusterResult, error) {
+	res, err := o.node.request(context.Background(), o.peer, clusterEnvelope{
+		Type:       clusterMsgUpdate,
+		Room:       o.room,
+		ScreenName: o.screenName.String(),
+		SignonTime: o.signonTime,
+		Update:     &u,
+	})
+	if err != nil {
+		o.node.logger.Error("unable to update session on another node", "screenName", o.screenName, "op", u.Op, "err", err.Error())
+	}
+	return res, err
+}
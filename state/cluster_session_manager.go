@@ -0,0 +1,851 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+const (
+	// clusterSessionsChannel is the message bus channel that carries BOS
+	// session traffic between nodes.
+	clusterSessionsChannel = "ras:sessions"
+	// clusterChatChannel is the message bus channel that carries chat
+	// session traffic between nodes.
+	clusterChatChannel = "ras:chat"
+	// clusterSyncInterval is how often a node checks whether its sessions
+	// changed and shares them with the other nodes.
+	clusterSyncInterval = time.Second
+	// clusterNodeTTL is how long a node's sessions are kept after it was last
+	// heard from. Nodes re-share their sessions at least 3 times per TTL.
+	clusterNodeTTL = 15 * time.Second
+	// clusterPublishTimeout is how long to wait for the message bus to
+	// accept a message.
+	clusterPublishTimeout = 5 * time.Second
+	// clusterKickTimeout is how long a node waits for kicked sessions to
+	// sign off before replying to the node that asked.
+	clusterKickTimeout = 5 * time.Second
+	// clusterReplyTimeout is how long to wait for another node to reply to
+	// a request. It leaves room for the other node to wait out
+	// clusterKickTimeout.
+	clusterReplyTimeout = 10 * time.Second
+	// clusterSignonKeyPrefix prefixes the message bus key that a node claims
+	// while it signs on a screen name.
+	clusterSignonKeyPrefix = "ras:signon:"
+	// clusterClaimTTL is how long a claim lasts if the node that holds it
+	// never releases it, such as when the node crashes. It leaves room for
+	// other nodes to wait out clusterReplyTimeout.
+	clusterClaimTTL = 30 * time.Second
+	// clusterClaimRetryDelay is how long to wait before trying again to
+	// claim a key that's held by someone else.
+	clusterClaimRetryDelay = 50 * time.Millisecond
+)
+
+// clusterMsgType identifies the purpose of a message sent between nodes.
+type clusterMsgType string
+
+const (
+	// clusterMsgHello announces a node that just joined the cluster. Nodes
+	// that receive it share their sessions right away.
+	clusterMsgHello clusterMsgType = "hello"
+	// clusterMsgLeave announces a node that is shutting down. Nodes that
+	// receive it forget its sessions.
+	clusterMsgLeave clusterMsgType = "leave"
+	// clusterMsgPresence carries the full list of a node's sessions.
+	clusterMsgPresence clusterMsgType = "presence"
+	// clusterMsgRelay carries a SNAC to deliver to sessions on other nodes.
+	clusterMsgRelay clusterMsgType = "relay"
+	// clusterMsgKick asks a node to sign off sessions that conflict with a
+	// new session.
+	clusterMsgKick clusterMsgType = "kick"
+	// clusterMsgUpdate asks a node to change one of its sessions.
+	clusterMsgUpdate clusterMsgType = "update"
+	// clusterMsgReply carries the result of a kick or update request.
+	clusterMsgReply clusterMsgType = "reply"
+)
+
+// clusterEnvelope is a message sent between nodes.
+type clusterEnvelope struct {
+	// Node is the ID of the sending node.
+	Node string `json:"node"`
+	// Type indicates what the message is for.
+	Type clusterMsgType `json:"type"`
+	// Room is the chat room cookie the message applies to. It's empty for
+	// BOS sessions.
+	Room string `json:"room,omitempty"`
+	// ScreenNames are the recipients of a relayed SNAC.
+	ScreenNames []string `json:"screenNames,omitempty"`
+	// All indicates that a relayed SNAC goes to every session.
+	All bool `json:"all,omitempty"`
+	// Except is a screen name excluded from a relayed SNAC sent to all.
+	Except string `json:"except,omitempty"`
+	// Frame is the frame of a relayed SNAC.
+	Frame wire.SNACFrame `json:"frame"`
+	// Body is the body of a relayed SNAC in wire format.
+	Body []byte `json:"body,omitempty"`
+	// Sessions are the sending node's sessions.
+	Sessions []clusterSession `json:"sessions,omitempty"`
+	// ScreenName is the user whose conflicting sessions are kicked.
+	ScreenName string `json:"screenName,omitempty"`
+	// MultiSess indicates whether the new session that triggered a kick
+	// allows other instances to stay signed on.
+	MultiSess bool `json:"multiSess,omitempty"`
+	// To is the ID of the node a request or reply is for. Other nodes
+	// ignore the message.
+	To string `json:"to,omitempty"`
+	// RequestID matches a reply to its request.
+	RequestID string `json:"requestID,omitempty"`
+	// SignonTime identifies the session an update applies to, together
+	// with ScreenName and Room.
+	SignonTime time.Time `json:"signonTime,omitzero"`
+	// Update is the change to make to a session.
+	Update *clusterUpdate `json:"update,omitempty"`
+	// Result is the outcome of a request.
+	Result *clusterResult `json:"result,omitempty"`
+}
+
+// clusterResult is a node's reply to a kick or update request.
+type clusterResult struct {
+	// OK indicates whether the request succeeded.
+	OK bool `json:"ok"`
+	// Warning is the session's warning level after a warning increase.
+	Warning uint16 `json:"warning,omitempty"`
+}
+
+// clusterKey identifies a user's sessions within a chat room, or their BOS
+// sessions if room is empty.
+type clusterKey struct {
+	room       string
+	screenName IdentScreenName
+}
+
+// remoteNode holds the sessions owned by another node.
+type remoteNode struct {
+	lastSeen time.Time
+	sessions map[clusterKey][]*Session
+}
+
+// clusterNode connects a session manager to the other nodes of a cluster. It
+// shares the node's sessions with its peers, tracks the sessions they own,
+// and hands relay and kick requests from peers to the session manager.
+type clusterNode struct {
+	bus          MessageBus
+	channel      string
+	handle       func(ctx context.Context, env clusterEnvelope)
+	id           string
+	logger       *slog.Logger
+	mutex        sync.RWMutex
+	nodeTTL      time.Duration
+	peers        map[string]*remoteNode
+	pending      map[string]chan clusterResult
+	pendingMutex sync.Mutex
+	replyTimeout time.Duration
+	snapshot     func() []clusterSession
+	syncInterval time.Duration
+	timeNow      func() time.Time
+	wake         chan struct{}
+}
+
+func newClusterNode(bus MessageBus, channel string, logger *slog.Logger, snapshot func() []clusterSession, handle func(ctx context.Context, env clusterEnvelope)) *clusterNode {
+	return &clusterNode{
+		bus:          bus,
+		channel:      channel,
+		handle:       handle,
+		id:           uuid.NewString(),
+		logger:       logger,
+		nodeTTL:      clusterNodeTTL,
+		peers:        make(map[string]*remoteNode),
+		pending:      make(map[string]chan clusterResult),
+		replyTimeout: clusterReplyTimeout,
+		snapshot:     snapshot,
+		syncInterval: clusterSyncInterval,
+		timeNow:      time.Now,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// run exchanges messages with the other nodes until ctx is done.
+func (n *clusterNode) run(ctx context.Context) error {
+	msgs, err := n.bus.Subscribe(ctx, n.channel)
+	if err != nil {
+		return err
+	}
+
+	// find out who else is in the cluster
+	n.publish(ctx, clusterEnvelope{Type: clusterMsgHello})
+
+	ticker := time.NewTicker(n.syncInterval)
+	defer ticker.Stop()
+
+	var lastSnapshot []byte
+	var lastSync time.Time
+	forceSync := true
+
+	share := func() {
+		sessions := n.snapshot()
+		slices.SortFunc(sessions, func(a, b clusterSession) int {
+			if c := strings.Compare(a.ChatRoomCookie, b.ChatRoomCookie); c != 0 {
+				return c
+			}
+			if c := strings.Compare(string(a.DisplayScreenName), string(b.DisplayScreenName)); c != 0 {
+				return c
+			}
+			return a.SignonTime.Compare(b.SignonTime)
+		})
+		b, err := json.Marshal(sessions)
+		if err != nil {
+			n.logger.ErrorContext(ctx, "unable to encode sessions for cluster", "err", err.Error())
+			return
+		}
+		now := n.timeNow()
+		if !forceSync && bytes.Equal(b, lastSnapshot) && now.Sub(lastSync) < n.nodeTTL/3 {
+			return
+		}
+		if n.publish(ctx, clusterEnvelope{Type: clusterMsgPresence, Sessions: sessions}) {
+			lastSnapshot, lastSync, forceSync = b, now, false
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// let the other nodes know right away that our sessions are gone
+			leaveCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+			n.publish(leaveCtx, clusterEnvelope{Type: clusterMsgLeave})
+			cancel()
+			return nil
+		case payload, ok := <-msgs:
+			if !ok {
+				msgs = nil // wait for ctx to be done
+				continue
+			}
+			if n.receive(ctx, payload) {
+				forceSync = true
+				share()
+			}
+		case <-ticker.C:
+			n.expirePeers()
+			share()
+		case <-n.wake:
+			share()
+		}
+	}
+}
+
+// receive processes a message from the bus. It returns true if the sender
+// asked to receive this node's sessions.
+func (n *clusterNode) receive(ctx context.Context, payload []byte) bool {
+	env := clusterEnvelope{}
+	if err := json.Unmarshal(payload, &env); err != nil {
+		n.logger.ErrorContext(ctx, "unable to decode cluster message", "err", err.Error())
+		return false
+	}
+	if env.Node == n.id || (env.To != "" && env.To != n.id) {
+		return false
+	}
+
+	switch env.Type {
+	case clusterMsgHello:
+		return true
+	case clusterMsgReply:
+		n.pendingMutex.Lock()
+		replyCh, ok := n.pending[env.RequestID]
+		n.pendingMutex.Unlock()
+		if ok && env.Result != nil {
+			select {
+			case replyCh <- *env.Result:
+			default: // duplicate reply
+			}
+		}
+	case clusterMsgLeave:
+		n.mutex.Lock()
+		delete(n.peers, env.Node)
+		n.mutex.Unlock()
+	case clusterMsgPresence:
+		n.setPeerSessions(env.Node, env.Sessions)
+	default:
+		n.handle(ctx, env)
+	}
+	return false
+}
+
+// setPeerSessions replaces the sessions known for a peer.
+func (n *clusterNode) setPeerSessions(node string, sessions []clusterSession) {
+	peer := &remoteNode{
+		lastSeen: n.timeNow(),
+		sessions: make(map[clusterKey][]*Session),
+	}
+	for _, cs := range sessions {
+		key := clusterKey{
+			room:       cs.ChatRoomCookie,
+			screenName: cs.DisplayScreenName.IdentScreenName(),
+		}
+		sess := newRemoteSession(cs)
+		sess.owner = &remoteOwner{
+			node:       n,
+			peer:       node,
+			room:       cs.ChatRoomCookie,
+			screenName: key.screenName,
+			signonTime: cs.SignonTime,
+		}
+		peer.sessions[key] = append(peer.sessions[key], sess)
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.peers[node] = peer
+}
+
+// expirePeers forgets the sessions of peers that haven't been heard from
+// within the node TTL, such as nodes that crashed.
+func (n *clusterNode) expirePeers() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	for id, peer := range n.peers {
+		if n.timeNow().Sub(peer.lastSeen) > n.nodeTTL {
+			n.logger.Info("lost contact with cluster node, dropping its sessions", "node", id)
+			delete(n.peers, id)
+		}
+	}
+}
+
+// nudge asks the node to share its sessions as soon as possible.
+func (n *clusterNode) nudge() {
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
+
+// publish sends a message to the other nodes. It returns false if the
+// message could not be sent.
+func (n *clusterNode) publish(ctx context.Context, env clusterEnvelope) bool {
+	env.Node = n.id
+	b, err := json.Marshal(env)
+	if err != nil {
+		n.logger.ErrorContext(ctx, "unable to encode cluster message", "err", err.Error())
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, clusterPublishTimeout)
+	defer cancel()
+	if err := n.bus.Publish(ctx, n.channel, b); err != nil {
+		n.logger.ErrorContext(ctx, "unable to publish cluster message", "type", env.Type, "err", err.Error())
+		return false
+	}
+	return true
+}
+
+// request sends a message to another node and waits for its reply.
+func (n *clusterNode) request(ctx context.Context, to string, env clusterEnvelope) (clusterResult, error) {
+	env.To = to
+	env.RequestID = uuid.NewString()
+
+	replyCh := make(chan clusterResult, 1)
+	n.pendingMutex.Lock()
+	n.pending[env.RequestID] = replyCh
+	n.pendingMutex.Unlock()
+	defer func() {
+		n.pendingMutex.Lock()
+		delete(n.pending, env.RequestID)
+		n.pendingMutex.Unlock()
+	}()
+
+	if !n.publish(ctx, env) {
+		return clusterResult{}, fmt.Errorf("unable to send %s request to node %s", env.Type, to)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.replyTimeout)
+	defer cancel()
+	select {
+	case res := <-replyCh:
+		return res, nil
+	case <-ctx.Done():
+		return clusterResult{}, fmt.Errorf("waiting for node %s to reply to %s request: %w", to, env.Type, ctx.Err())
+	}
+}
+
+// reply answers a request from another node.
+func (n *clusterNode) reply(ctx context.Context, req clusterEnvelope, res clusterResult) {
+	n.publish(ctx, clusterEnvelope{
+		Type:      clusterMsgReply,
+		To:        req.Node,
+		RequestID: req.RequestID,
+		Result:    &res,
+	})
+}
+
+// kick asks each node that owns sessions for a user in a room to sign off
+// the ones that conflict with a new session, and waits for the nodes to
+// confirm. If allPeers is true, every other node is asked, since a node may
+// have added a session that this node hasn't heard about yet. Nodes that
+// aren't known to own sessions for the user don't have to confirm, so that
+// a node that stopped responding doesn't hold up every sign-on until it
+// expires.
+func (n *clusterNode) kick(ctx context.Context, room string, screenName IdentScreenName, multiSess bool, allPeers bool) error {
+	owners := n.peersWith(room, screenName)
+	peers := owners
+	if allPeers {
+		peers = n.peerIDs()
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, peer := range peers {
+		g.Go(func() error {
+			res, err := n.request(ctx, peer, clusterEnvelope{
+				Type:       clusterMsgKick,
+				Room:       room,
+				ScreenName: screenName.String(),
+				MultiSess:  multiSess,
+			})
+			if err == nil && !res.OK {
+				err = fmt.Errorf("node %s could not sign off the previous session", peer)
+			}
+			if err != nil && !slices.Contains(owners, peer) {
+				n.logger.WarnContext(ctx, "cluster node did not confirm sign-off", "node", peer, "screenName", screenName, "err", err.Error())
+				return nil
+			}
+			return err
+		})
+	}
+	return g.Wait()
+}
+
+// claim claims a message bus key, waiting for as long as someone else holds
+// it or until ctx is done. The returned func releases the claim.
+func (n *clusterNode) claim(ctx context.Context, key string) (func(), error) {
+	owner := n.id + "/" + uuid.NewString()
+	for {
+		ok, err := n.bus.Claim(ctx, key, owner, clusterClaimTTL)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-time.After(clusterClaimRetryDelay):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for claim on %s: %w", key, ctx.Err())
+		}
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), clusterPublishTimeout)
+		defer cancel()
+		if err := n.bus.Release(ctx, key, owner); err != nil {
+			n.logger.ErrorContext(ctx, "unable to release cluster claim", "key", key, "err", err.Error())
+		}
+	}, nil
+}
+
+// relay sends a SNAC to sessions on other nodes. env selects the recipients.
+func (n *clusterNode) relay(ctx context.Context, env clusterEnvelope, msg wire.SNACMessage) {
+	body, err := encodeClusterSNAC(msg)
+	if err != nil {
+		n.logger.ErrorContext(ctx, "unable to encode SNAC for cluster relay", "message", msg, "err", err.Error())
+		return
+	}
+	env.Type = clusterMsgRelay
+	env.Frame = msg.Frame
+	env.Body = body
+	n.publish(ctx, env)
+}
+
+// sessions returns the sessions owned by other nodes for a user in a room.
+func (n *clusterNode) sessions(room string, screenName IdentScreenName) []*Session {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	var ret []*Session
+	for _, peer := range n.peers {
+		ret = append(ret, peer.sessions[clusterKey{room: room, screenName: screenName}]...)
+	}
+	return ret
+}
+
+// peersWith returns the IDs of the nodes that own sessions for a user in a
+// room.
+func (n *clusterNode) peersWith(room string, screenName IdentScreenName) []string {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	var ret []string
+	for id, peer := range n.peers {
+		if len(peer.sessions[clusterKey{room: room, screenName: screenName}]) > 0 {
+			ret = append(ret, id)
+		}
+	}
+	return ret
+}
+
+// peerIDs returns the IDs of the other nodes.
+func (n *clusterNode) peerIDs() []string {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	ret := make([]string, 0, len(n.peers))
+	for id := range n.peers {
+		ret = append(ret, id)
+	}
+	return ret
+}
+
+// hasSessions indicates whether another node owns a session for a user in a
+// room.
+func (n *clusterNode) hasSessions(room string, screenName IdentScreenName) bool {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	for _, peer := range n.peers {
+		if len(peer.sessions[clusterKey{room: room, screenName: screenName}]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// roomSessions returns all sessions owned by other nodes in a room.
+func (n *clusterNode) roomSessions(room string) []*Session {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	var ret []*Session
+	for _, peer := range n.peers {
+		for key, sessions := range peer.sessions {
+			if key.room == room {
+				ret = append(ret, sessions...)
+			}
+		}
+	}
+	return ret
+}
+
+// ClusterSessionManager is a session manager for a node in a cluster of
+// servers. Each node owns the sessions of the clients connected to it, while
+// presence and messages are shared with the other nodes through a
+// MessageBus, so that users on different nodes can see and message each
+// other. Sessions owned by other nodes are returned as mirrors that are
+// refreshed as the owning node reports changes. Changes made to a mirror,
+// such as warnings, status updates and Close, are sent to the owning node,
+// which applies them to the session and confirms; a change the owner doesn't
+// confirm is logged and not applied to the mirror. A ClusterSessionManager is
+// safe for concurrent use by multiple goroutines.
+type ClusterSessionManager struct {
+	local  *InMemorySessionManager
+	logger *slog.Logger
+	node   *clusterNode
+}
+
+// NewClusterSessionManager creates a new instance of ClusterSessionManager
+// that exchanges sessions with the other nodes via bus. Run must be called to
+// connect it to the cluster.
+func NewClusterSessionManager(bus MessageBus, logger *slog.Logger) *ClusterSessionManager {
+	s := &ClusterSessionManager{
+		local:  NewInMemorySessionManager(logger),
+		logger: logger,
+	}
+	s.node = newClusterNode(bus, clusterSessionsChannel, logger, s.snapshot, s.handle)
+	return s
+}
+
+// Run exchanges sessions and messages with the other nodes until ctx is
+// done.
+func (s *ClusterSessionManager) Run(ctx context.Context) error {
+	return s.node.run(ctx)
+}
+
+// AddSession adds a new session for screenName to the pool. Conflicting
+// instances on this node and on other nodes are signed off before the
+// session is added. Sign-ons of the same screen name are serialized across
+// the cluster by claiming the screen name on the message bus. It returns an
+// error if the claim fails or if another node doesn't confirm that its
+// conflicting instances signed off. See InMemorySessionManager.AddSession
+// for the conflict rules.
+func (s *ClusterSessionManager) AddSession(ctx context.Context, screenName DisplayScreenName, multiSess bool) (*Session, error) {
+	// keep other nodes from signing on the screen name at the same time.
+	// the node that claims it next asks every node, including this one, to
+	// sign off conflicting instances, so it can't miss the session added
+	// here even if it hasn't heard about it yet.
+	release, err := s.node.claim(ctx, clusterSignonKeyPrefix+screenName.IdentScreenName().String())
+	if err != nil {
+		return nil, fmt.Errorf("claiming screen name: %w", err)
+	}
+	defer release()
+
+	if err := s.node.kick(ctx, "", screenName.IdentScreenName(), multiSess, true); err != nil {
+		return nil, fmt.Errorf("signing off session on another node: %w", err)
+	}
+	sess, err := s.local.AddSession(ctx, screenName, multiSess)
+	if err != nil {
+		return nil, err
+	}
+	s.node.nudge()
+	return sess, nil
+}
+
+// RemoveSession takes a session out of the session pool.
+func (s *ClusterSessionManager) RemoveSession(sess *Session) {
+	s.local.RemoveSession(sess)
+	s.node.nudge()
+}
+
+// SignoffSession takes sess out of the session pool after calling cleanup.
+// See InMemorySessionManager.SignoffSession. sess is the user's last
+// instance if no other instance is signed on at this node or, as of the last
+// presence update, at any other node.
+func (s *ClusterSessionManager) SignoffSession(sess *Session, cleanup func(lastInstance bool)) {
+	s.local.SignoffSession(sess, func(lastInstance bool) {
+		cleanup(lastInstance && !s.node.hasSessions("", sess.IdentScreenName()))
+	})
+	s.node.nudge()
+}
+
+// RetrieveSession finds a session with a matching screen name on any node.
+// Sessions on this node take precedence. Returns nil if session is not
+// found.
+func (s *ClusterSessionManager) RetrieveSession(screenName IdentScreenName) *Session {
+	if sess := s.local.RetrieveSession(screenName); sess != nil {
+		return sess
+	}
+	var latest *Session
+	for _, sess := range s.node.sessions("", screenName) {
+		if latest == nil || sess.SignonTime().After(latest.SignonTime()) {
+			latest = sess
+		}
+	}
+	return latest
+}
+
+// RetrieveSessions returns every signed-on instance of a screen name across
+// all nodes, starting with the instances on this node.
+func (s *ClusterSessionManager) RetrieveSessions(screenName IdentScreenName) []*Session {
+	return append(s.local.RetrieveSessions(screenName), s.node.sessions("", screenName)...)
+}
+
+// AllSessions returns all sessions in the cluster.
+func (s *ClusterSessionManager) AllSessions() []*Session {
+	return append(s.local.AllSessions(), s.node.roomSessions("")...)
+}
+
+// RelayToAll relays a message to all sessions in the cluster.
+func (s *ClusterSessionManager) RelayToAll(ctx context.Context, msg wire.SNACMessage) {
+	s.local.RelayToAll(ctx, msg)
+	s.node.relay(ctx, clusterEnvelope{All: true}, msg)
+}
+
+// RelayToScreenName relays a message to every instance of a screen name in
+// the cluster.
+func (s *ClusterSessionManager) RelayToScreenName(ctx context.Context, screenName IdentScreenName, msg wire.SNACMessage) {
+	remote := s.node.hasSessions("", screenName)
+	local := s.local.RetrieveSessions(screenName)
+	if !remote && len(local) == 0 {
+		s.logger.WarnContext(ctx, "can't send notification because user is not online", "recipient", screenName, "message", msg)
+		return
+	}
+	for _, sess := range local {
+		s.local.maybeRelayMessage(ctx, msg, sess)
+	}
+	if remote {
+		s.node.relay(ctx, clusterEnvelope{ScreenNames: []string{screenName.String()}}, msg)
+	}
+}
+
+// RelayToScreenNames relays a message to every instance of the given screen
+// names in the cluster.
+func (s *ClusterSessionManager) RelayToScreenNames(ctx context.Context, screenNames []IdentScreenName, msg wire.SNACMessage) {
+	s.local.RelayToScreenNames(ctx, screenNames, msg)
+
+	var remote []string
+	for _, screenName := range screenNames {
+		if s.node.hasSessions("", screenName) {
+			remote = append(remote, screenName.String())
+		}
+	}
+	if len(remote) > 0 {
+		s.node.relay(ctx, clusterEnvelope{ScreenNames: remote}, msg)
+	}
+}
+
+// RelayToOtherInstances relays a message to every instance of sess's screen
+// name in the cluster except sess itself.
+func (s *ClusterSessionManager) RelayToOtherInstances(ctx context.Context, sess *Session, msg wire.SNACMessage) {
+	s.local.RelayToOtherInstances(ctx, sess, msg)
+	if s.node.hasSessions("", sess.IdentScreenName()) {
+		s.node.relay(ctx, clusterEnvelope{ScreenNames: []string{sess.IdentScreenName().String()}}, msg)
+	}
+}
+
+// snapshot captures the sessions owned by this node.
+func (s *ClusterSessionManager) snapshot() []clusterSession {
+	var sessions []clusterSession
+	for _, sess := range s.local.AllSessions() {
+		sessions = append(sessions, newClusterSession(sess))
+	}
+	return sessions
+}
+
+// handle processes relay, kick and update requests from other nodes.
+func (s *ClusterSessionManager) handle(ctx context.Context, env clusterEnvelope) {
+	switch env.Type {
+	case clusterMsgRelay:
+		msg := decodeClusterSNAC(env.Frame, env.Body)
+		if env.All {
+			s.local.RelayToAll(ctx, msg)
+			return
+		}
+		screenNames := make([]IdentScreenName, 0, len(env.ScreenNames))
+		for _, screenName := range env.ScreenNames {
+			screenNames = append(screenNames, NewIdentScreenName(screenName))
+		}
+		s.local.RelayToScreenNames(ctx, screenNames, msg)
+	case clusterMsgKick:
+		// wait for the sessions to sign off without holding up messages
+		// from other nodes
+		go func() {
+			kickCtx, cancel := context.WithTimeout(ctx, clusterKickTimeout)
+			defer cancel()
+			err := s.local.kick(kickCtx, NewIdentScreenName(env.ScreenName), env.MultiSess)
+			if err != nil {
+				s.logger.WarnContext(ctx, "unable to sign off session for cluster node", "screenName", env.ScreenName, "err", err.Error())
+			}
+			s.node.reply(ctx, env, clusterResult{OK: err == nil})
+		}()
+	case clusterMsgUpdate:
+		s.node.reply(ctx, env, applyClusterUpdate(env, s.local.RetrieveSessions(NewIdentScreenName(env.ScreenName))))
+	}
+}
+
+// ClusterChatSessionManager is a chat session manager for a node in a
+// cluster of servers. Chat room participants may be connected to different
+// nodes; each node owns the sessions of its own participants and shares
+// them with the other nodes through a MessageBus. A
+// ClusterChatSessionManager is safe for concurrent use by multiple
+// goroutines.
+type ClusterChatSessionManager struct {
+	local  *InMemoryChatSessionManager
+	logger *slog.Logger
+	node   *clusterNode
+}
+
+// NewClusterChatSessionManager creates a new instance of
+// ClusterChatSessionManager that exchanges chat sessions with the other
+// nodes via bus. Run must be called to connect it to the cluster.
+func NewClusterChatSessionManager(bus MessageBus, logger *slog.Logger) *ClusterChatSessionManager {
+	s := &ClusterChatSessionManager{
+		local:  NewInMemoryChatSessionManager(logger),
+		logger: logger,
+	}
+	s.node = newClusterNode(bus, clusterChatChannel, logger, s.snapshot, s.handle)
+	return s
+}
+
+// Run exchanges chat sessions and messages with the other nodes until ctx
+// is done.
+func (s *ClusterChatSessionManager) Run(ctx context.Context) error {
+	return s.node.run(ctx)
+}
+
+// AddSession adds a user to a chat room. If the user is already in the room
+// on another node, that node is asked to remove them first.
+func (s *ClusterChatSessionManager) AddSession(ctx context.Context, chatCookie string, screenName DisplayScreenName) (*Session, error) {
+	if err := s.node.kick(ctx, chatCookie, screenName.IdentScreenName(), false, false); err != nil {
+		return nil, fmt.Errorf("removing chat session on another node: %w", err)
+	}
+	sess, err := s.local.AddSession(ctx, chatCookie, screenName)
+	if err != nil {
+		return nil, err
+	}
+	s.node.nudge()
+	return sess, nil
+}
+
+// RemoveSession removes a user session from a chat room.
+func (s *ClusterChatSessionManager) RemoveSession(sess *Session) {
+	s.local.RemoveSession(sess)
+	s.node.nudge()
+}
+
+// RemoveUserFromAllChats removes a user's session from all chat rooms on
+// this node.
+func (s *ClusterChatSessionManager) RemoveUserFromAllChats(user IdentScreenName) {
+	s.local.RemoveUserFromAllChats(user)
+	s.node.nudge()
+}
+
+// AllSessions returns all chat room participants across the cluster.
+func (s *ClusterChatSessionManager) AllSessions(cookie string) []*Session {
+	return append(s.local.AllSessions(cookie), s.node.roomSessions(cookie)...)
+}
+
+// RelayToAllExcept sends a message to all chat room participants across the
+// cluster except for the participant with a particular screen name.
+func (s *ClusterChatSessionManager) RelayToAllExcept(ctx context.Context, cookie string, except IdentScreenName, msg wire.SNACMessage) {
+	if len(s.local.AllSessions(cookie)) > 0 {
+		s.local.RelayToAllExcept(ctx, cookie, except, msg)
+	}
+	if len(s.node.roomSessions(cookie)) > 0 {
+		s.node.relay(ctx, clusterEnvelope{Room: cookie, All: true, Except: except.String()}, msg)
+	}
+}
+
+// RelayToScreenName sends a message to a chat room participant on any node.
+func (s *ClusterChatSessionManager) RelayToScreenName(ctx context.Context, cookie string, recipient IdentScreenName, msg wire.SNACMessage) {
+	if s.node.hasSessions(cookie, recipient) {
+		s.node.relay(ctx, clusterEnvelope{Room: cookie, ScreenNames: []string{recipient.String()}}, msg)
+		return
+	}
+	s.local.RelayToScreenName(ctx, cookie, recipient, msg)
+}
+
+// snapshot captures the chat sessions owned by this node.
+func (s *ClusterChatSessionManager) snapshot() []clusterSession {
+	s.local.mapMutex.RLock()
+	defer s.local.mapMutex.RUnlock()
+	var sessions []clusterSession
+	for _, sessionManager := range s.local.store {
+		for _, sess := range sessionManager.AllSessions() {
+			sessions = append(sessions, newClusterSession(sess))
+		}
+	}
+	return sessions
+}
+
+// handle processes relay, kick and update requests from other nodes.
+func (s *ClusterChatSessionManager) handle(ctx context.Context, env clusterEnvelope) {
+	participants := s.local.AllSessions(env.Room)
+
+	switch env.Type {
+	case clusterMsgRelay:
+		if len(participants) == 0 {
+			return
+		}
+		msg := decodeClusterSNAC(env.Frame, env.Body)
+		if env.All {
+			s.local.RelayToAllExcept(ctx, env.Room, NewIdentScreenName(env.Except), msg)
+			return
+		}
+		for _, screenName := range env.ScreenNames {
+			recipient := NewIdentScreenName(screenName)
+			if slices.ContainsFunc(participants, func(sess *Session) bool {
+				return sess.IdentScreenName() == recipient
+			}) {
+				s.local.RelayToScreenName(ctx, env.Room, recipient, msg)
+			}
+		}
+	case clusterMsgKick:
+		for _, sess := range participants {
+			if sess.IdentScreenName() == NewIdentScreenName(env.ScreenName) {
+				sess.Close()
+			}
+		}
+		s.node.reply(ctx, env, clusterResult{OK: true})
+	case clusterMsgUpdate:
+		s.node.reply(ctx, env, applyClusterUpdate(env, participants))
+	}
+}
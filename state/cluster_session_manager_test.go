@@ -0,0 +1,532 @@
+package state
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// newTestClusterSessionManager creates a ClusterSessionManager that syncs
+// quickly and runs until the test ends.
+func newTestClusterSessionManager(t *testing.T, bus MessageBus) *ClusterSessionManager {
+	sm := NewClusterSessionManager(bus, slog.Default())
+	sm.node.syncInterval = 10 * time.Millisecond
+	runClusterNode(t, sm.Run)
+	return sm
+}
+
+// newTestClusterChatSessionManager creates a ClusterChatSessionManager that
+// syncs quickly and runs until the test ends.
+func newTestClusterChatSessionManager(t *testing.T, bus MessageBus) *ClusterChatSessionManager {
+	sm := NewClusterChatSessionManager(bus, slog.Default())
+	sm.node.syncInterval = 10 * time.Millisecond
+	runClusterNode(t, sm.Run)
+	return sm
+}
+
+func runClusterNode(t *testing.T, run func(ctx context.Context) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+}
+
+// receiveMessage waits for a message relayed to sess.
+func receiveMessage(t *testing.T, sess *Session) wire.SNACMessage {
+	select {
+	case msg := <-sess.ReceiveMessage():
+		return msg
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for message to %s", sess.IdentScreenName())
+		return wire.SNACMessage{}
+	}
+}
+
+func TestClusterSessionManager_Presence(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+	node2 := newTestClusterSessionManager(t, bus)
+
+	sess, err := node1.AddSession(context.Background(), "User A", false)
+	require.NoError(t, err)
+	sess.SetAwayMessage("be right back")
+	sess.SetSignonComplete()
+
+	// node 2 sees the user signed on to node 1
+	require.Eventually(t, func() bool {
+		return node2.RetrieveSession(NewIdentScreenName("usera")) != nil
+	}, time.Second, 10*time.Millisecond)
+
+	remote := node2.RetrieveSession(NewIdentScreenName("usera"))
+	assert.Equal(t, DisplayScreenName("User A"), remote.DisplayScreenName())
+	assert.Equal(t, "be right back", remote.AwayMessage())
+	assert.Equal(t, sess.TLVUserInfo(), remote.TLVUserInfo())
+	assert.Len(t, node2.RetrieveSessions(NewIdentScreenName("usera")), 1)
+	assert.Len(t, node2.AllSessions(), 1)
+
+	// node 2 sees changes made to the session
+	sess.SetAwayMessage("")
+	require.Eventually(t, func() bool {
+		return node2.RetrieveSession(NewIdentScreenName("usera")).AwayMessage() == ""
+	}, time.Second, 10*time.Millisecond)
+
+	// node 2 sees the user sign off
+	node1.RemoveSession(sess)
+	require.Eventually(t, func() bool {
+		return node2.RetrieveSession(NewIdentScreenName("usera")) == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, node2.AllSessions())
+}
+
+func TestClusterSessionManager_Presence_NodeJoinsLater(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+
+	sess, err := node1.AddSession(context.Background(), "User A", false)
+	require.NoError(t, err)
+	sess.SetSignonComplete()
+
+	// let node 1 share its sessions before node 2 joins
+	time.Sleep(50 * time.Millisecond)
+
+	node2 := newTestClusterSessionManager(t, bus)
+	require.Eventually(t, func() bool {
+		return node2.RetrieveSession(NewIdentScreenName("usera")) != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestClusterSessionManager_Presence_NodeLeaves(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := NewClusterSessionManager(bus, slog.Default())
+	node1.node.syncInterval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- node1.Run(ctx)
+	}()
+
+	node2 := newTestClusterSessionManager(t, bus)
+
+	sess, err := node1.AddSession(context.Background(), "User A", false)
+	require.NoError(t, err)
+	sess.SetSignonComplete()
+
+	require.Eventually(t, func() bool {
+		return node2.RetrieveSession(NewIdentScreenName("usera")) != nil
+	}, time.Second, 10*time.Millisecond)
+
+	// node 2 forgets node 1's sessions once it shuts down
+	cancel()
+	assert.NoError(t, <-done)
+	require.Eventually(t, func() bool {
+		return node2.RetrieveSession(NewIdentScreenName("usera")) == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestClusterSessionManager_Presence_NodeExpires(t *testing.T) {
+	node := newClusterNode(NewInProcessMessageBus(), clusterSessionsChannel, slog.Default(), nil, nil)
+	now := time.Now()
+	node.timeNow = func() time.Time { return now }
+
+	node.setPeerSessions("node-1", []clusterSession{{DisplayScreenName: "User A"}})
+	assert.True(t, node.hasSessions("", NewIdentScreenName("usera")))
+
+	now = now.Add(clusterNodeTTL)
+	node.expirePeers()
+	assert.True(t, node.hasSessions("", NewIdentScreenName("usera")))
+
+	now = now.Add(time.Second)
+	node.expirePeers()
+	assert.False(t, node.hasSessions("", NewIdentScreenName("usera")))
+}
+
+func TestClusterSessionManager_Relay(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+	node2 := newTestClusterSessionManager(t, bus)
+
+	userA, err := node1.AddSession(context.Background(), "usera", false)
+	require.NoError(t, err)
+	userA.SetSignonComplete()
+	userB, err := node2.AddSession(context.Background(), "userb", false)
+	require.NoError(t, err)
+	userB.SetSignonComplete()
+
+	require.Eventually(t, func() bool {
+		return node1.RetrieveSession(NewIdentScreenName("userb")) != nil &&
+			node2.RetrieveSession(NewIdentScreenName("usera")) != nil
+	}, time.Second, 10*time.Millisecond)
+
+	im := wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.ICBM,
+			SubGroup:  wire.ICBMChannelMsgToClient,
+		},
+		Body: wire.SNAC_0x04_0x07_ICBMChannelMsgToClient{
+			Cookie:      1234,
+			ChannelID:   wire.ICBMChannelIM,
+			TLVUserInfo: userA.TLVUserInfo(),
+			TLVRestBlock: wire.TLVRestBlock{
+				TLVList: wire.TLVList{
+					wire.NewTLVBE(wire.ICBMTLVAOLIMData, []byte("hello")),
+				},
+			},
+		},
+	}
+
+	t.Run("relay to screen name on other node", func(t *testing.T) {
+		node1.RelayToScreenName(context.Background(), NewIdentScreenName("userb"), im)
+		assert.Equal(t, im, receiveMessage(t, userB))
+	})
+
+	t.Run("relay to screen names across nodes", func(t *testing.T) {
+		node1.RelayToScreenNames(context.Background(), []IdentScreenName{
+			NewIdentScreenName("usera"),
+			NewIdentScreenName("userb"),
+		}, im)
+		assert.Equal(t, im, receiveMessage(t, userA))
+		assert.Equal(t, im, receiveMessage(t, userB))
+	})
+
+	t.Run("relay to all across nodes", func(t *testing.T) {
+		node2.RelayToAll(context.Background(), im)
+		assert.Equal(t, im, receiveMessage(t, userA))
+		assert.Equal(t, im, receiveMessage(t, userB))
+	})
+
+	t.Run("relay SNAC with unknown body type", func(t *testing.T) {
+		msg := wire.SNACMessage{
+			Frame: wire.SNACFrame{
+				FoodGroup: wire.Stats,
+				SubGroup:  wire.StatsReportAck,
+			},
+			Body: wire.SNAC_0x0B_0x04_StatsReportAck{},
+		}
+		node2.RelayToScreenName(context.Background(), NewIdentScreenName("usera"), msg)
+		assert.Equal(t, wire.SNACMessage{Frame: msg.Frame, Body: []byte(nil)}, receiveMessage(t, userA))
+	})
+}
+
+func TestClusterSessionManager_RelayToOtherInstances(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+	node2 := newTestClusterSessionManager(t, bus)
+
+	sess1, err := node1.AddSession(context.Background(), "usera", true)
+	require.NoError(t, err)
+	sess1.SetSignonComplete()
+	sess2, err := node2.AddSession(context.Background(), "usera", true)
+	require.NoError(t, err)
+	sess2.SetSignonComplete()
+
+	require.Eventually(t, func() bool {
+		return len(node1.RetrieveSessions(NewIdentScreenName("usera"))) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	msg := wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.Feedbag,
+			SubGroup:  wire.FeedbagDeleteItem,
+		},
+		Body: wire.SNAC_0x13_0x0A_FeedbagDeleteItem{
+			Items: []wire.FeedbagItem{
+				{Name: "buddy", ClassID: wire.FeedbagClassIdBuddy},
+			},
+		},
+	}
+	node1.RelayToOtherInstances(context.Background(), sess1, msg)
+	assert.Equal(t, msg, receiveMessage(t, sess2))
+
+	select {
+	case msg := <-sess1.ReceiveMessage():
+		t.Fatalf("unexpected message to originating session: %v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestClusterSessionManager_SignoffSession(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+	node2 := newTestClusterSessionManager(t, bus)
+
+	sess1, err := node1.AddSession(context.Background(), "usera", true)
+	require.NoError(t, err)
+	sess1.SetSignonComplete()
+	sess2, err := node2.AddSession(context.Background(), "usera", true)
+	require.NoError(t, err)
+	sess2.SetSignonComplete()
+
+	require.Eventually(t, func() bool {
+		return len(node1.RetrieveSessions(NewIdentScreenName("usera"))) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// the instance on node 2 is still signed on
+	node1.SignoffSession(sess1, func(lastInstance bool) {
+		assert.False(t, lastInstance)
+	})
+
+	require.Eventually(t, func() bool {
+		return len(node2.RetrieveSessions(NewIdentScreenName("usera"))) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	node2.SignoffSession(sess2, func(lastInstance bool) {
+		assert.True(t, lastInstance)
+	})
+}
+
+func TestClusterSessionManager_AddSession_KicksOtherNode(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+	node2 := newTestClusterSessionManager(t, bus)
+
+	sess1, err := node1.AddSession(context.Background(), "usera", false)
+	require.NoError(t, err)
+	sess1.SetSignonComplete()
+
+	require.Eventually(t, func() bool {
+		return node2.RetrieveSession(NewIdentScreenName("usera")) != nil
+	}, time.Second, 10*time.Millisecond)
+
+	// sign off the session once it's kicked, as the server does
+	go func() {
+		<-sess1.Closed()
+		node1.RemoveSession(sess1)
+	}()
+
+	// AddSession returns once node 1 confirms the session signed off
+	sess2, err := node2.AddSession(context.Background(), "usera", false)
+	require.NoError(t, err)
+	sess2.SetSignonComplete()
+
+	select {
+	case <-sess1.Closed():
+	default:
+		t.Fatal("session on other node was not signed off")
+	}
+	assert.Nil(t, node1.RetrieveSession(NewIdentScreenName("usera")))
+
+	select {
+	case <-sess2.Closed():
+		t.Fatal("new session was signed off")
+	default:
+	}
+}
+
+func TestClusterSessionManager_AddSession_KickNotConfirmed(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+	node2 := newTestClusterSessionManager(t, bus)
+	node2.node.replyTimeout = 50 * time.Millisecond
+
+	sess1, err := node1.AddSession(context.Background(), "usera", false)
+	require.NoError(t, err)
+	sess1.SetSignonComplete()
+
+	require.Eventually(t, func() bool {
+		return node2.RetrieveSession(NewIdentScreenName("usera")) != nil
+	}, time.Second, 10*time.Millisecond)
+
+	// the kicked session never signs off, so node 1 doesn't reply in time
+	_, err = node2.AddSession(context.Background(), "usera", false)
+	assert.Error(t, err)
+	assert.Nil(t, node2.local.RetrieveSession(NewIdentScreenName("usera")))
+}
+
+func TestClusterSessionManager_AddSession_Concurrent(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+	node2 := newTestClusterSessionManager(t, bus)
+
+	require.Eventually(t, func() bool {
+		return len(node1.node.peerIDs()) == 1 && len(node2.node.peerIDs()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// sign on from both nodes at once, before either node hears about the
+	// other's session
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	sessions := make([]*Session, 2)
+	for i, node := range []*ClusterSessionManager{node1, node2} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			sess, err := node.AddSession(context.Background(), "usera", false)
+			require.NoError(t, err)
+			sess.SetSignonComplete()
+			sessions[i] = sess
+			// sign off the session once it's kicked, as the server does
+			go func() {
+				<-sess.Closed()
+				node.RemoveSession(sess)
+			}()
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	var open int
+	for _, sess := range sessions {
+		select {
+		case <-sess.Closed():
+		default:
+			open++
+		}
+	}
+	assert.Equal(t, 1, open)
+}
+
+func TestClusterSessionManager_AddSession_UnresponsivePeer(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+	node1.node.replyTimeout = 50 * time.Millisecond
+
+	// a node that stopped responding, but hasn't expired yet, doesn't hold
+	// up sign-ons of users it has no sessions for
+	node1.node.setPeerSessions("node-2", nil)
+
+	_, err := node1.AddSession(context.Background(), "usera", false)
+	assert.NoError(t, err)
+}
+
+func TestClusterSessionManager_UpdateRemoteSession(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+	node2 := newTestClusterSessionManager(t, bus)
+
+	sess, err := node1.AddSession(context.Background(), "usera", false)
+	require.NoError(t, err)
+	sess.SetRateClasses(time.Now(), wire.DefaultRateLimitClasses())
+	sess.SetSignonComplete()
+
+	require.Eventually(t, func() bool {
+		return node2.RetrieveSession(NewIdentScreenName("usera")) != nil
+	}, time.Second, 10*time.Millisecond)
+	remote := node2.RetrieveSession(NewIdentScreenName("usera"))
+
+	t.Run("warn", func(t *testing.T) {
+		ok, warning := remote.ScaleWarningAndRateLimit(100, 3)
+		assert.True(t, ok)
+		assert.Equal(t, uint16(100), warning)
+		assert.Equal(t, uint16(100), sess.Warning())
+		assert.Equal(t, uint16(100), <-sess.WarningCh())
+		assert.Equal(t, uint16(100), remote.Warning())
+	})
+
+	t.Run("set away message", func(t *testing.T) {
+		remote.SetAwayMessage("gone fishing")
+		assert.Equal(t, "gone fishing", sess.AwayMessage())
+		assert.Equal(t, "gone fishing", remote.AwayMessage())
+	})
+
+	t.Run("set idle", func(t *testing.T) {
+		remote.SetIdle(time.Minute)
+		assert.True(t, sess.Idle())
+		remote.UnsetIdle()
+		assert.False(t, sess.Idle())
+	})
+
+	t.Run("clear user info flag", func(t *testing.T) {
+		sess.SetUserInfoFlag(wire.OServiceUserFlagUnconfirmed)
+		remote.ClearUserInfoFlag(wire.OServiceUserFlagUnconfirmed)
+		assert.Zero(t, sess.UserInfoBitmask()&wire.OServiceUserFlagUnconfirmed)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		remote.Close()
+		select {
+		case <-sess.Closed():
+		default:
+			t.Fatal("session on other node was not closed")
+		}
+	})
+}
+
+func TestClusterSessionManager_UpdateRemoteSession_SignedOff(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterSessionManager(t, bus)
+	node2 := newTestClusterSessionManager(t, bus)
+
+	sess, err := node1.AddSession(context.Background(), "usera", false)
+	require.NoError(t, err)
+	sess.SetSignonComplete()
+
+	require.Eventually(t, func() bool {
+		return node2.RetrieveSession(NewIdentScreenName("usera")) != nil
+	}, time.Second, 10*time.Millisecond)
+	remote := node2.RetrieveSession(NewIdentScreenName("usera"))
+
+	// the user signs off before node 2 finds out
+	node1.RemoveSession(sess)
+
+	ok, _ := remote.ScaleWarningAndRateLimit(100, 3)
+	assert.False(t, ok)
+	remote.SetAwayMessage("gone fishing")
+	assert.Empty(t, remote.AwayMessage())
+}
+
+func TestClusterChatSessionManager(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	node1 := newTestClusterChatSessionManager(t, bus)
+	node2 := newTestClusterChatSessionManager(t, bus)
+
+	userA, err := node1.AddSession(context.Background(), "the-room", "usera")
+	require.NoError(t, err)
+	userA.SetSignonComplete()
+	userB, err := node2.AddSession(context.Background(), "the-room", "userb")
+	require.NoError(t, err)
+	userB.SetSignonComplete()
+	userC, err := node2.AddSession(context.Background(), "other-room", "userc")
+	require.NoError(t, err)
+	userC.SetSignonComplete()
+
+	require.Eventually(t, func() bool {
+		return len(node1.AllSessions("the-room")) == 2 && len(node2.AllSessions("the-room")) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(t, node1.AllSessions("other-room"), 1)
+
+	msg := wire.SNACMessage{
+		Frame: wire.SNACFrame{
+			FoodGroup: wire.Chat,
+			SubGroup:  wire.ChatUsersJoined,
+		},
+		Body: wire.SNAC_0x0E_0x03_ChatUsersJoined{
+			Users: []wire.TLVUserInfo{userA.TLVUserInfo()},
+		},
+	}
+
+	t.Run("relay to all except sender", func(t *testing.T) {
+		node1.RelayToAllExcept(context.Background(), "the-room", NewIdentScreenName("usera"), msg)
+		assert.Equal(t, msg, receiveMessage(t, userB))
+	})
+
+	t.Run("relay to screen name on other node", func(t *testing.T) {
+		node2.RelayToScreenName(context.Background(), "the-room", NewIdentScreenName("usera"), msg)
+		assert.Equal(t, msg, receiveMessage(t, userA))
+	})
+
+	assert.Empty(t, userA.ReceiveMessage())
+	assert.Empty(t, userC.ReceiveMessage())
+
+	t.Run("joining from another node kicks the old session", func(t *testing.T) {
+		_, err := node2.AddSession(context.Background(), "the-room", "usera")
+		require.NoError(t, err)
+		select {
+		case <-userA.Closed():
+		case <-time.After(time.Second):
+			t.Fatal("session on other node was not removed from the room")
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package state
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EmailTokenPurpose identifies what an emailed token may be redeemed for.
+type EmailTokenPurpose string
+
+const (
+	// EmailTokenConfirm tokens confirm a user's account.
+	EmailTokenConfirm EmailTokenPurpose = "confirm"
+	// EmailTokenPasswordReset tokens let a user set a new password.
+	EmailTokenPasswordReset EmailTokenPurpose = "password_reset"
+)
+
+const (
+	// EmailConfirmTokenTTL is how long an account confirmation link is
+	// valid.
+	EmailConfirmTokenTTL = 72 * time.Hour
+	// PasswordResetTokenTTL is how long a password reset link is valid.
+	PasswordResetTokenTTL = time.Hour
+
+	// emailTokenLen is the size of an email token in bytes.
+	emailTokenLen = 32
+)
+
+// ErrInvalidEmailToken indicates that an email token does not exist, has
+// expired, was already redeemed, or was issued for another purpose.
+var ErrInvalidEmailToken = errors.New("invalid or expired token")
+
+// hashEmailToken returns the digest under which an email token is stored.
+// Tokens are random and high-entropy, so an unsalted hash suffices.
+func hashEmailToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueEmailToken creates a single-use token that can be redeemed for
+// purpose until expiresAt. Only the most recently issued token for a given
+// user and purpose is valid. Only a hash of the token is stored, so the
+// returned value can't be recovered later.
+func (f SQLiteUserStore) IssueEmailToken(ctx context.Context, screenName IdentScreenName, purpose EmailTokenPurpose, expiresAt time.Time) (token string, err error) {
+	buf := make([]byte, emailTokenLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+
+	q := `DELETE FROM emailToken WHERE screenName = ? AND purpose = ?`
+	if _, err = tx.ExecContext(ctx, q, screenName.String(), purpose); err != nil {
+		return "", err
+	}
+
+	q = `
+		INSERT INTO emailToken (tokenHash, screenName, purpose, expiresAt)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err = tx.ExecContext(ctx, q, hashEmailToken(token), screenName.String(), purpose, expiresAt.Unix()); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// LookupEmailToken returns the screen name a token was issued to without
+// consuming it. It returns ErrInvalidEmailToken under the same conditions as
+// RedeemEmailToken.
+func (f SQLiteUserStore) LookupEmailToken(ctx context.Context, token string, purpose EmailTokenPurpose, now time.Time) (IdentScreenName, error) {
+	q := `
+		SELECT screenName, expiresAt
+		FROM emailToken
+		WHERE tokenHash = ? AND purpose = ?
+	`
+	var screenName string
+	var expiresAt int64
+	err := f.db.QueryRowContext(ctx, q, hashEmailToken(token), purpose).Scan(&screenName, &expiresAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return IdentScreenName{}, ErrInvalidEmailToken
+	case err != nil:
+		return IdentScreenName{}, err
+	}
+
+	if !now.Before(time.Unix(expiresAt, 0)) {
+		return IdentScreenName{}, ErrInvalidEmailToken
+	}
+
+	return NewIdentScreenName(screenName), nil
+}
+
+// RedeemEmailToken consumes a token issued for purpose and returns the
+// screen name it was issued to. It returns ErrInvalidEmailToken if the token
+// is unknown, expired as of now, or was issued for a different purpose.
+func (f SQLiteUserStore) RedeemEmailToken(ctx context.Context, token string, purpose EmailTokenPurpose, now time.Time) (IdentScreenName, error) {
+	q := `
+		DELETE FROM emailToken
+		WHERE tokenHash = ? AND purpose = ?
+		RETURNING screenName, expiresAt
+	`
+	var screenName string
+	var expiresAt int64
+	err := f.db.QueryRowContext(ctx, q, hashEmailToken(token), purpose).Scan(&screenName, &expiresAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return IdentScreenName{}, ErrInvalidEmailToken
+	case err != nil:
+		return IdentScreenName{}, err
+	}
+
+	if !now.Before(time.Unix(expiresAt, 0)) {
+		return IdentScreenName{}, ErrInvalidEmailToken
+	}
+
+	return NewIdentScreenName(screenName), nil
+}
@@ -0,0 +1,126 @@
+package state
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteUserStore_EmailToken(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	userA := NewIdentScreenName("userA")
+	userB := NewIdentScreenName("userB")
+
+	t.Run("redeem token once", func(t *testing.T) {
+		defer func() {
+			assert.NoError(t, os.Remove(testFile))
+		}()
+		userStore, err := NewSQLiteUserStore(testFile)
+		require.NoError(t, err)
+
+		token, err := userStore.IssueEmailToken(context.Background(), userA, EmailTokenConfirm, now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.NotEmpty(t, token)
+
+		have, err := userStore.RedeemEmailToken(context.Background(), token, EmailTokenConfirm, now)
+		require.NoError(t, err)
+		assert.Equal(t, userA, have)
+
+		_, err = userStore.RedeemEmailToken(context.Background(), token, EmailTokenConfirm, now)
+		assert.ErrorIs(t, err, ErrInvalidEmailToken)
+	})
+
+	t.Run("look up token without consuming it", func(t *testing.T) {
+		defer func() {
+			assert.NoError(t, os.Remove(testFile))
+		}()
+		userStore, err := NewSQLiteUserStore(testFile)
+		require.NoError(t, err)
+
+		token, err := userStore.IssueEmailToken(context.Background(), userA, EmailTokenPasswordReset, now.Add(time.Hour))
+		require.NoError(t, err)
+
+		have, err := userStore.LookupEmailToken(context.Background(), token, EmailTokenPasswordReset, now)
+		require.NoError(t, err)
+		assert.Equal(t, userA, have)
+
+		_, err = userStore.LookupEmailToken(context.Background(), token, EmailTokenConfirm, now)
+		assert.ErrorIs(t, err, ErrInvalidEmailToken)
+		_, err = userStore.LookupEmailToken(context.Background(), token, EmailTokenPasswordReset, now.Add(time.Hour))
+		assert.ErrorIs(t, err, ErrInvalidEmailToken)
+
+		have, err = userStore.RedeemEmailToken(context.Background(), token, EmailTokenPasswordReset, now)
+		require.NoError(t, err)
+		assert.Equal(t, userA, have)
+
+		_, err = userStore.LookupEmailToken(context.Background(), token, EmailTokenPasswordReset, now)
+		assert.ErrorIs(t, err, ErrInvalidEmailToken)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		defer func() {
+			assert.NoError(t, os.Remove(testFile))
+		}()
+		userStore, err := NewSQLiteUserStore(testFile)
+		require.NoError(t, err)
+
+		token, err := userStore.IssueEmailToken(context.Background(), userA, EmailTokenConfirm, now.Add(time.Hour))
+		require.NoError(t, err)
+
+		_, err = userStore.RedeemEmailToken(context.Background(), token, EmailTokenConfirm, now.Add(time.Hour))
+		assert.ErrorIs(t, err, ErrInvalidEmailToken)
+	})
+
+	t.Run("token issued for another purpose", func(t *testing.T) {
+		defer func() {
+			assert.NoError(t, os.Remove(testFile))
+		}()
+		userStore, err := NewSQLiteUserStore(testFile)
+		require.NoError(t, err)
+
+		token, err := userStore.IssueEmailToken(context.Background(), userA, EmailTokenConfirm, now.Add(time.Hour))
+		require.NoError(t, err)
+
+		_, err = userStore.RedeemEmailToken(context.Background(), token, EmailTokenPasswordReset, now)
+		assert.ErrorIs(t, err, ErrInvalidEmailToken)
+
+		// the failed attempt doesn't consume the token
+		have, err := userStore.RedeemEmailToken(context.Background(), token, EmailTokenConfirm, now)
+		require.NoError(t, err)
+		assert.Equal(t, userA, have)
+	})
+
+	t.Run("new token revokes previous token for same user and purpose", func(t *testing.T) {
+		defer func() {
+			assert.NoError(t, os.Remove(testFile))
+		}()
+		userStore, err := NewSQLiteUserStore(testFile)
+		require.NoError(t, err)
+
+		oldToken, err := userStore.IssueEmailToken(context.Background(), userA, EmailTokenPasswordReset, now.Add(time.Hour))
+		require.NoError(t, err)
+		confirmToken, err := userStore.IssueEmailToken(context.Background(), userA, EmailTokenConfirm, now.Add(time.Hour))
+		require.NoError(t, err)
+		otherUserToken, err := userStore.IssueEmailToken(context.Background(), userB, EmailTokenPasswordReset, now.Add(time.Hour))
+		require.NoError(t, err)
+		newToken, err := userStore.IssueEmailToken(context.Background(), userA, EmailTokenPasswordReset, now.Add(time.Hour))
+		require.NoError(t, err)
+
+		_, err = userStore.RedeemEmailToken(context.Background(), oldToken, EmailTokenPasswordReset, now)
+		assert.ErrorIs(t, err, ErrInvalidEmailToken)
+
+		have, err := userStore.RedeemEmailToken(context.Background(), newToken, EmailTokenPasswordReset, now)
+		require.NoError(t, err)
+		assert.Equal(t, userA, have)
+		have, err = userStore.RedeemEmailToken(context.Background(), confirmToken, EmailTokenConfirm, now)
+		require.NoError(t, err)
+		assert.Equal(t, userA, have)
+		have, err = userStore.RedeemEmailToken(context.Background(), otherUserToken, EmailTokenPasswordReset, now)
+		require.NoError(t, err)
+		assert.Equal(t, userB, have)
+	})
+}
@@ -0,0 +1,215 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ArchivedIM is an instant message recorded in the server-side IM archive.
+type ArchivedIM struct {
+	// ID uniquely identifies the message. It's assigned when the message is
+	// archived.
+	ID int64
+	// SentAt is when the server received the message.
+	SentAt time.Time
+	// Sender is the screen name of the user who sent the message.
+	Sender IdentScreenName
+	// Recipient is the screen name of the user the message was sent to.
+	Recipient IdentScreenName
+	// Text is the message text, which may contain HTML.
+	Text string
+}
+
+// IMArchiveFilter narrows the messages returned by IMArchive. Zero-valued
+// fields match every message.
+type IMArchiveFilter struct {
+	// Query matches messages whose text contains all of its words.
+	Query string
+	// Sender matches messages sent by this user.
+	Sender IdentScreenName
+	// Recipient matches messages sent to this user.
+	Recipient IdentScreenName
+	// Since matches messages sent at or after this time.
+	Since time.Time
+	// Until matches messages sent before this time.
+	Until time.Time
+	// Limit is the maximum number of messages to return.
+	Limit int
+}
+
+// IMArchiveSettings are a user's IM archive preferences.
+type IMArchiveSettings struct {
+	// OptOut indicates that the user's conversations aren't archived.
+	OptOut bool
+	// RetentionDays is how many days the user's messages are kept, or 0 to
+	// keep them indefinitely. If nil, the server's default applies.
+	RetentionDays *int
+}
+
+// ArchiveIM records an instant message in the IM archive, unless the sender
+// or recipient opted out of archiving. The message's ID is ignored.
+func (f SQLiteUserStore) ArchiveIM(ctx context.Context, im ArchivedIM) error {
+	q := `
+		INSERT INTO imArchive (sentAt, sender, recipient, text)
+		SELECT ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1
+		                  FROM users
+		                  WHERE identScreenName IN (?, ?)
+		                    AND imArchiveOptOut)
+	`
+	_, err := f.db.ExecContext(ctx, q, im.SentAt.Unix(), im.Sender.String(), im.Recipient.String(), im.Text,
+		im.Sender.String(), im.Recipient.String())
+	return err
+}
+
+// IMArchive returns the archived messages that match filter, newest first.
+func (f SQLiteUserStore) IMArchive(ctx context.Context, filter IMArchiveFilter) ([]ArchivedIM, error) {
+	var args []any
+	clauses := []string{"1 = 1"}
+
+	if query := ftsQuery(filter.Query); query != "" {
+		args = append(args, query)
+		clauses = append(clauses, `id IN (SELECT rowid FROM imArchiveText WHERE imArchiveText MATCH ?)`)
+	}
+	if filter.Sender.String() != "" {
+		args = append(args, filter.Sender.String())
+		clauses = append(clauses, `sender = ?`)
+	}
+	if filter.Recipient.String() != "" {
+		args = append(args, filter.Recipient.String())
+		clauses = append(clauses, `recipient = ?`)
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since.Unix())
+		clauses = append(clauses, `sentAt >= ?`)
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until.Unix())
+		clauses = append(clauses, `sentAt < ?`)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	args = append(args, limit)
+
+	q := `
+		SELECT id, sentAt, sender, recipient, text
+		FROM imArchive
+		WHERE ` + strings.Join(clauses, " AND ") + `
+		ORDER BY id DESC
+		LIMIT ?
+	`
+	rows, err := f.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ims []ArchivedIM
+	for rows.Next() {
+		var sentAt int64
+		var sender, recipient string
+		im := ArchivedIM{}
+		if err := rows.Scan(&im.ID, &sentAt, &sender, &recipient, &im.Text); err != nil {
+			return nil, err
+		}
+		im.SentAt = time.Unix(sentAt, 0).UTC()
+		im.Sender = NewIdentScreenName(sender)
+		im.Recipient = NewIdentScreenName(recipient)
+		ims = append(ims, im)
+	}
+
+	return ims, rows.Err()
+}
+
+// ftsQuery turns a search string into an FTS5 query that matches text
+// containing all of its words. Each word is quoted so that FTS5 operators
+// in the search string are matched literally.
+func ftsQuery(search string) string {
+	words := strings.Fields(search)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
+
+// PurgeIMArchive deletes the archived messages that are older than the
+// retention period of their sender or recipient, whichever is shorter.
+// defaultRetentionDays applies to users without their own retention period.
+// A retention period of 0 keeps messages indefinitely. It returns the number
+// of messages deleted.
+func (f SQLiteUserStore) PurgeIMArchive(ctx context.Context, now time.Time, defaultRetentionDays int) (int64, error) {
+	// retentionDays is the retention period of a user, where the largest
+	// possible period stands in for "indefinitely"
+	retentionDays := func(col string) string {
+		return `COALESCE(NULLIF(COALESCE((SELECT imArchiveRetentionDays FROM users WHERE identScreenName = imArchive.` +
+			col + `), ?), 0), 2147483647)`
+	}
+	q := `
+		DELETE FROM imArchive
+		WHERE sentAt < ? - 86400 * MIN(` + retentionDays("sender") + `, ` + retentionDays("recipient") + `)
+	`
+	result, err := f.db.ExecContext(ctx, q, now.Unix(), defaultRetentionDays, defaultRetentionDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// IMArchiveSettings returns a user's IM archive preferences. It returns
+// ErrNoUser if the user doesn't exist.
+func (f SQLiteUserStore) IMArchiveSettings(ctx context.Context, screenName IdentScreenName) (IMArchiveSettings, error) {
+	q := `
+		SELECT imArchiveOptOut, imArchiveRetentionDays
+		FROM users
+		WHERE identScreenName = ?
+	`
+	var settings IMArchiveSettings
+	var retentionDays sql.NullInt64
+	err := f.db.QueryRowContext(ctx, q, screenName.String()).Scan(&settings.OptOut, &retentionDays)
+	if errors.Is(err, sql.ErrNoRows) {
+		return IMArchiveSettings{}, ErrNoUser
+	}
+	if err != nil {
+		return IMArchiveSettings{}, err
+	}
+	if retentionDays.Valid {
+		days := int(retentionDays.Int64)
+		settings.RetentionDays = &days
+	}
+	return settings, nil
+}
+
+// SetIMArchiveSettings replaces a user's IM archive preferences. It returns
+// ErrNoUser if the user doesn't exist.
+func (f SQLiteUserStore) SetIMArchiveSettings(ctx context.Context, screenName IdentScreenName, settings IMArchiveSettings) error {
+	q := `
+		UPDATE users
+		SET imArchiveOptOut        = ?,
+		    imArchiveRetentionDays = ?
+		WHERE identScreenName = ?
+	`
+	var retentionDays sql.NullInt64
+	if settings.RetentionDays != nil {
+		retentionDays = sql.NullInt64{Int64: int64(*settings.RetentionDays), Valid: true}
+	}
+	result, err := f.db.ExecContext(ctx, q, settings.OptOut, retentionDays, screenName.String())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNoUser
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package state
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteUserStore_IMArchive(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	ims := []ArchivedIM{
+		{SentAt: t0, Sender: NewIdentScreenName("alice"), Recipient: NewIdentScreenName("bob"), Text: "<HTML>are we still on for lunch?</HTML>"},
+		{SentAt: t0.Add(time.Minute), Sender: NewIdentScreenName("bob"), Recipient: NewIdentScreenName("alice"), Text: "yes, lunch at noon"},
+		{SentAt: t0.Add(2 * time.Minute), Sender: NewIdentScreenName("alice"), Recipient: NewIdentScreenName("carol"), Text: `say "hi" to bob OR dave`},
+	}
+	for _, im := range ims {
+		require.NoError(t, userStore.ArchiveIM(ctx, im))
+	}
+	for i := range ims {
+		ims[i].ID = int64(i + 1)
+	}
+
+	tt := []struct {
+		name   string
+		filter IMArchiveFilter
+		want   []ArchivedIM
+	}{
+		{
+			name:   "all messages, newest first",
+			filter: IMArchiveFilter{},
+			want:   []ArchivedIM{ims[2], ims[1], ims[0]},
+		},
+		{
+			name:   "by text",
+			filter: IMArchiveFilter{Query: "LUNCH"},
+			want:   []ArchivedIM{ims[1], ims[0]},
+		},
+		{
+			name:   "by text, all words must match",
+			filter: IMArchiveFilter{Query: "lunch noon"},
+			want:   []ArchivedIM{ims[1]},
+		},
+		{
+			name:   "by text containing query syntax",
+			filter: IMArchiveFilter{Query: `"hi" OR`},
+			want:   []ArchivedIM{ims[2]},
+		},
+		{
+			name:   "by sender",
+			filter: IMArchiveFilter{Sender: NewIdentScreenName("Alice")},
+			want:   []ArchivedIM{ims[2], ims[0]},
+		},
+		{
+			name:   "by recipient",
+			filter: IMArchiveFilter{Recipient: NewIdentScreenName("alice")},
+			want:   []ArchivedIM{ims[1]},
+		},
+		{
+			name:   "by time range",
+			filter: IMArchiveFilter{Since: t0.Add(time.Minute), Until: t0.Add(2 * time.Minute)},
+			want:   []ArchivedIM{ims[1]},
+		},
+		{
+			name:   "with limit",
+			filter: IMArchiveFilter{Limit: 1},
+			want:   []ArchivedIM{ims[2]},
+		},
+		{
+			name:   "no matches",
+			filter: IMArchiveFilter{Query: "dinner"},
+			want:   nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := userStore.IMArchive(ctx, tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSQLiteUserStore_ArchiveIM_OptOut(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, sn := range []string{"alice", "bob", "carol"} {
+		require.NoError(t, userStore.InsertUser(ctx, User{
+			IdentScreenName:   NewIdentScreenName(sn),
+			DisplayScreenName: DisplayScreenName(sn),
+		}))
+	}
+	require.NoError(t, userStore.SetIMArchiveSettings(ctx, NewIdentScreenName("bob"), IMArchiveSettings{OptOut: true}))
+
+	sentAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, userStore.ArchiveIM(ctx, ArchivedIM{SentAt: sentAt, Sender: NewIdentScreenName("alice"), Recipient: NewIdentScreenName("bob"), Text: "to bob"}))
+	require.NoError(t, userStore.ArchiveIM(ctx, ArchivedIM{SentAt: sentAt, Sender: NewIdentScreenName("bob"), Recipient: NewIdentScreenName("carol"), Text: "from bob"}))
+	require.NoError(t, userStore.ArchiveIM(ctx, ArchivedIM{SentAt: sentAt, Sender: NewIdentScreenName("alice"), Recipient: NewIdentScreenName("carol"), Text: "to carol"}))
+
+	got, err := userStore.IMArchive(ctx, IMArchiveFilter{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "to carol", got[0].Text)
+}
+
+func TestSQLiteUserStore_PurgeIMArchive(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, sn := range []string{"alice", "bob", "carol", "dave"} {
+		require.NoError(t, userStore.InsertUser(ctx, User{
+			IdentScreenName:   NewIdentScreenName(sn),
+			DisplayScreenName: DisplayScreenName(sn),
+		}))
+	}
+	days := func(n int) *int { return &n }
+	// bob keeps messages for 5 days, carol forever, dave and alice use the
+	// default of 30 days
+	require.NoError(t, userStore.SetIMArchiveSettings(ctx, NewIdentScreenName("bob"), IMArchiveSettings{RetentionDays: days(5)}))
+	require.NoError(t, userStore.SetIMArchiveSettings(ctx, NewIdentScreenName("carol"), IMArchiveSettings{RetentionDays: days(0)}))
+
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	archive := func(sender, recipient string, age time.Duration) {
+		require.NoError(t, userStore.ArchiveIM(ctx, ArchivedIM{
+			SentAt:    now.Add(-age),
+			Sender:    NewIdentScreenName(sender),
+			Recipient: NewIdentScreenName(recipient),
+			Text:      sender + " to " + recipient,
+		}))
+	}
+	archive("alice", "bob", 10*24*time.Hour)    // purged, past bob's 5 days
+	archive("alice", "bob", 2*24*time.Hour)     // kept
+	archive("carol", "carol", 365*24*time.Hour) // kept, carol keeps forever
+	archive("carol", "dave", 40*24*time.Hour)   // purged, past dave's 30 days
+	archive("alice", "dave", 20*24*time.Hour)   // kept
+	archive("alice", "erin", 40*24*time.Hour)   // purged, erin has no account so the default applies
+
+	deleted, err := userStore.PurgeIMArchive(ctx, now, 30)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	got, err := userStore.IMArchive(ctx, IMArchiveFilter{})
+	require.NoError(t, err)
+	var texts []string
+	for _, im := range got {
+		texts = append(texts, im.Text)
+	}
+	assert.Equal(t, []string{"alice to dave", "carol to carol", "alice to bob"}, texts)
+
+	t.Run("purged messages are no longer searchable", func(t *testing.T) {
+		got, err := userStore.IMArchive(ctx, IMArchiveFilter{Query: "erin"})
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("default of 0 keeps messages indefinitely", func(t *testing.T) {
+		archive("alice", "erin", 1000*24*time.Hour)
+		deleted, err := userStore.PurgeIMArchive(ctx, now, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+	})
+}
+
+func TestSQLiteUserStore_IMArchiveSettings(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	screenName := NewIdentScreenName("alice")
+	require.NoError(t, userStore.InsertUser(ctx, User{
+		IdentScreenName:   screenName,
+		DisplayScreenName: "alice",
+	}))
+
+	settings, err := userStore.IMArchiveSettings(ctx, screenName)
+	require.NoError(t, err)
+	assert.Equal(t, IMArchiveSettings{}, settings)
+
+	days := 7
+	want := IMArchiveSettings{OptOut: true, RetentionDays: &days}
+	require.NoError(t, userStore.SetIMArchiveSettings(ctx, screenName, want))
+
+	settings, err = userStore.IMArchiveSettings(ctx, screenName)
+	require.NoError(t, err)
+	assert.Equal(t, want, settings)
+
+	_, err = userStore.IMArchiveSettings(ctx, NewIdentScreenName("bob"))
+	assert.ErrorIs(t, err, ErrNoUser)
+	err = userStore.SetIMArchiveSettings(ctx, NewIdentScreenName("bob"), want)
+	assert.ErrorIs(t, err, ErrNoUser)
+}
@@ -0,0 +1,155 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrNoInvite indicates that an invitation does not exist.
+	ErrNoInvite = errors.New("invite does not exist")
+	// ErrInviteLimitReached indicates that the sender already sent as many
+	// invitations as they're allowed.
+	ErrInviteLimitReached = errors.New("invite limit reached")
+)
+
+// InviteStatus is the state of an invitation.
+type InviteStatus string
+
+const (
+	// InviteStatusPending invitations haven't been accepted yet.
+	InviteStatusPending InviteStatus = "pending"
+	// InviteStatusAccepted invitations were sent to an email address that
+	// now belongs to a user account.
+	InviteStatusAccepted InviteStatus = "accepted"
+)
+
+// Invite is an invitation to join the service sent by a user to an email
+// address.
+type Invite struct {
+	// ID uniquely identifies the invitation.
+	ID int64
+	// Sender is the user who sent the invitation.
+	Sender IdentScreenName
+	// Email is the address the invitation was sent to.
+	Email string
+	// Message is the personal note the sender attached to the invitation.
+	Message string
+	// CreatedAt is when the invitation was sent.
+	CreatedAt time.Time
+	// AcceptedBy is the user account that has the invitation's email
+	// address. It's empty if the invitation is pending.
+	AcceptedBy DisplayScreenName
+}
+
+// Status returns whether the invitation is pending or accepted.
+func (i Invite) Status() InviteStatus {
+	if i.AcceptedBy == "" {
+		return InviteStatusPending
+	}
+	return InviteStatusAccepted
+}
+
+// InsertInvite records an invitation and returns its ID. If limit is greater
+// than 0, the invitation is only recorded if its sender sent fewer than limit
+// invitations since the given time, otherwise it returns
+// ErrInviteLimitReached. The limit is checked by the insert itself, so
+// concurrent calls can't exceed it.
+func (f SQLiteUserStore) InsertInvite(ctx context.Context, invite Invite, since time.Time, limit int) (int64, error) {
+	q := `
+		INSERT INTO invite (sender, email, message, createdAt)
+		SELECT ?, ?, ?, ?
+		WHERE ? = 0 OR (SELECT COUNT(*) FROM invite WHERE sender = ? AND createdAt >= ?) < ?
+	`
+	res, err := f.db.ExecContext(ctx,
+		q,
+		invite.Sender.String(),
+		invite.Email,
+		invite.Message,
+		invite.CreatedAt.Unix(),
+		limit,
+		invite.Sender.String(),
+		since.Unix(),
+		limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	c, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+	if c == 0 {
+		return 0, ErrInviteLimitReached
+	}
+	return res.LastInsertId()
+}
+
+// Invites returns all invitations with the given status, oldest first. An
+// invitation is considered accepted once a user account has its email
+// address. If status is empty, all invitations are returned.
+func (f SQLiteUserStore) Invites(ctx context.Context, status InviteStatus) ([]Invite, error) {
+	q := `
+		SELECT id, sender, email, message, createdAt, acceptedBy
+		FROM (
+			SELECT
+				i.id,
+				i.sender,
+				i.email,
+				i.message,
+				i.createdAt,
+				COALESCE((
+					SELECT u.displayScreenName
+					FROM users u
+					WHERE u.emailAddress = i.email COLLATE NOCASE
+					LIMIT 1
+				), '') AS acceptedBy
+			FROM invite i
+		)
+		WHERE ? = ''
+		   OR (? = 'pending' AND acceptedBy = '')
+		   OR (? = 'accepted' AND acceptedBy != '')
+		ORDER BY id
+	`
+	rows, err := f.db.QueryContext(ctx, q, status, status, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invites []Invite
+	for rows.Next() {
+		var sender, acceptedBy string
+		var createdAt int64
+		inv := Invite{}
+		if err := rows.Scan(&inv.ID, &sender, &inv.Email, &inv.Message, &createdAt, &acceptedBy); err != nil {
+			return nil, err
+		}
+		inv.Sender = NewIdentScreenName(sender)
+		inv.CreatedAt = time.Unix(createdAt, 0).UTC()
+		inv.AcceptedBy = DisplayScreenName(acceptedBy)
+		invites = append(invites, inv)
+	}
+
+	return invites, rows.Err()
+}
+
+// DeleteInvite removes an invitation. It returns ErrNoInvite if the
+// invitation does not exist.
+func (f SQLiteUserStore) DeleteInvite(ctx context.Context, id int64) error {
+	res, err := f.db.ExecContext(ctx, `DELETE FROM invite WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoInvite
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"context"
+	"net/mail"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteUserStore_Invites(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	sender := NewIdentScreenName("userA")
+
+	id1, err := userStore.InsertInvite(ctx, Invite{Sender: sender, Email: "friend1@example.com", Message: "join me", CreatedAt: now.Add(-48 * time.Hour)}, now.Add(-24*time.Hour), 1)
+	require.NoError(t, err)
+	id2, err := userStore.InsertInvite(ctx, Invite{Sender: sender, Email: "Friend2@example.com", CreatedAt: now}, now.Add(-24*time.Hour), 1)
+	require.NoError(t, err)
+
+	// the sender already sent 1 invitation within the past day
+	_, err = userStore.InsertInvite(ctx, Invite{Sender: sender, Email: "friend3@example.com", CreatedAt: now}, now.Add(-24*time.Hour), 1)
+	assert.ErrorIs(t, err, ErrInviteLimitReached)
+
+	// the second invitee signs up with the invited email address
+	friend := User{
+		IdentScreenName:   NewIdentScreenName("friend2"),
+		DisplayScreenName: "Friend2",
+	}
+	require.NoError(t, userStore.InsertUser(ctx, friend))
+	require.NoError(t, userStore.UpdateEmailAddress(ctx, friend.IdentScreenName, &mail.Address{Address: "friend2@example.com"}))
+
+	all, err := userStore.Invites(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []Invite{
+		{
+			ID:        id1,
+			Sender:    sender,
+			Email:     "friend1@example.com",
+			Message:   "join me",
+			CreatedAt: now.Add(-48 * time.Hour),
+		},
+		{
+			ID:         id2,
+			Sender:     sender,
+			Email:      "Friend2@example.com",
+			CreatedAt:  now,
+			AcceptedBy: "Friend2",
+		},
+	}, all)
+	assert.Equal(t, InviteStatusPending, all[0].Status())
+	assert.Equal(t, InviteStatusAccepted, all[1].Status())
+
+	pending, err := userStore.Invites(ctx, InviteStatusPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, id1, pending[0].ID)
+
+	accepted, err := userStore.Invites(ctx, InviteStatusAccepted)
+	require.NoError(t, err)
+	require.Len(t, accepted, 1)
+	assert.Equal(t, id2, accepted[0].ID)
+
+	require.NoError(t, userStore.DeleteInvite(ctx, id1))
+	assert.ErrorIs(t, userStore.DeleteInvite(ctx, id1), ErrNoInvite)
+}
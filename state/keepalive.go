@@ -0,0 +1,90 @@
+package state
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// KeepAliveStats reports keepalive activity across all client connections.
+type KeepAliveStats struct {
+	// IntervalSeconds is how often keepalives are sent to each client.
+	IntervalSeconds float64 `json:"interval_seconds"`
+	// IdleTimeoutSeconds is how long a client can go without sending
+	// anything before it is disconnected.
+	IdleTimeoutSeconds float64 `json:"idle_timeout_seconds"`
+	// ProbesSent is the number of keepalives sent to clients.
+	ProbesSent uint64 `json:"probes_sent"`
+	// Reaped is the number of connections closed because the client
+	// stopped responding.
+	Reaped uint64 `json:"reaped"`
+}
+
+// KeepAliveMonitor holds the keepalive settings shared by the OSCAR and TOC
+// servers and keeps count of keepalive activity. Servers send a keepalive
+// frame to each client every interval so that half-open TCP connections
+// surface as write errors, and close connections that send nothing for
+// idleTimeout so that users behind dead connections don't stay online
+// indefinitely. A nil KeepAliveMonitor disables both. A KeepAliveMonitor is
+// safe for concurrent use by multiple goroutines.
+type KeepAliveMonitor struct {
+	idleTimeout time.Duration
+	interval    time.Duration
+	probesSent  atomic.Uint64
+	reaped      atomic.Uint64
+}
+
+// NewKeepAliveMonitor creates a new instance of KeepAliveMonitor. An interval
+// of 0 disables keepalives and an idleTimeout of 0 disables idle timeouts.
+func NewKeepAliveMonitor(interval time.Duration, idleTimeout time.Duration) *KeepAliveMonitor {
+	return &KeepAliveMonitor{
+		idleTimeout: idleTimeout,
+		interval:    interval,
+	}
+}
+
+// Interval returns how often keepalives are sent to each client. It returns
+// 0 if keepalives are disabled.
+func (m *KeepAliveMonitor) Interval() time.Duration {
+	if m == nil {
+		return 0
+	}
+	return m.interval
+}
+
+// ReadDeadline returns the time by which a client that was last heard from
+// at now must send something before its connection is closed. It returns the
+// zero time, which means no deadline, if idle timeouts are disabled.
+func (m *KeepAliveMonitor) ReadDeadline(now time.Time) time.Time {
+	if m == nil || m.idleTimeout <= 0 {
+		return time.Time{}
+	}
+	return now.Add(m.idleTimeout)
+}
+
+// ProbeSent records that a keepalive was sent to a client.
+func (m *KeepAliveMonitor) ProbeSent() {
+	if m != nil {
+		m.probesSent.Add(1)
+	}
+}
+
+// Reaped records that a connection was closed because the client stopped
+// responding.
+func (m *KeepAliveMonitor) Reaped() {
+	if m != nil {
+		m.reaped.Add(1)
+	}
+}
+
+// KeepAliveStats returns the keepalive settings and activity counters.
+func (m *KeepAliveMonitor) KeepAliveStats() KeepAliveStats {
+	if m == nil {
+		return KeepAliveStats{}
+	}
+	return KeepAliveStats{
+		IntervalSeconds:    m.interval.Seconds(),
+		IdleTimeoutSeconds: m.idleTimeout.Seconds(),
+		ProbesSent:         m.probesSent.Load(),
+		Reaped:             m.reaped.Load(),
+	}
+}
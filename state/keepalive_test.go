@@ -0,0 +1,41 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeepAliveMonitor(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewKeepAliveMonitor(time.Minute, 5*time.Minute)
+	assert.Equal(t, time.Minute, m.Interval())
+	assert.Equal(t, now.Add(5*time.Minute), m.ReadDeadline(now))
+
+	m.ProbeSent()
+	m.ProbeSent()
+	m.Reaped()
+	assert.Equal(t, KeepAliveStats{
+		IntervalSeconds:    60,
+		IdleTimeoutSeconds: 300,
+		ProbesSent:         2,
+		Reaped:             1,
+	}, m.KeepAliveStats())
+}
+
+func TestKeepAliveMonitor_Disabled(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewKeepAliveMonitor(0, 0)
+	assert.Zero(t, m.Interval())
+	assert.True(t, m.ReadDeadline(now).IsZero())
+
+	var nilMonitor *KeepAliveMonitor
+	assert.Zero(t, nilMonitor.Interval())
+	assert.True(t, nilMonitor.ReadDeadline(now).IsZero())
+	nilMonitor.ProbeSent()
+	nilMonitor.Reaped()
+	assert.Equal(t, KeepAliveStats{}, nilMonitor.KeepAliveStats())
+}
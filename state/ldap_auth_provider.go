@@ -0,0 +1,93 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// AuthProvider verifies plaintext passwords against an external identity
+// source, such as a directory server, in place of the password hashes kept
+// in the user store.
+type AuthProvider interface {
+	// Authenticate indicates whether password is correct for screenName.
+	Authenticate(ctx context.Context, screenName DisplayScreenName, password []byte) (bool, error)
+}
+
+// ldapTimeout is how long to wait for the directory server to accept a
+// connection or answer a request.
+const ldapTimeout = 10 * time.Second
+
+// ldapConn is the subset of an LDAP connection used for bind
+// authentication.
+type ldapConn interface {
+	Bind(username, password string) error
+	Close() error
+}
+
+// LDAPAuthProvider authenticates users by binding to a directory server as
+// the user. The bind DN is derived from the screen name using a template,
+// e.g. "uid=%s,ou=people,dc=example,dc=org".
+type LDAPAuthProvider struct {
+	bindDNTemplate string
+	dial           func(ctx context.Context, url string) (ldapConn, error)
+	url            string
+}
+
+// NewLDAPAuthProvider creates a new instance of LDAPAuthProvider. url is the
+// address of the directory server, e.g. "ldaps://ldap.example.org:636".
+// bindDNTemplate contains a single %s verb that is replaced by the
+// normalized screen name.
+func NewLDAPAuthProvider(url string, bindDNTemplate string) *LDAPAuthProvider {
+	return &LDAPAuthProvider{
+		bindDNTemplate: bindDNTemplate,
+		dial:           dialLDAP,
+		url:            url,
+	}
+}
+
+// Authenticate binds to the directory server as screenName. It returns false
+// if the directory rejects the credentials.
+func (p *LDAPAuthProvider) Authenticate(ctx context.Context, screenName DisplayScreenName, password []byte) (bool, error) {
+	// many directory servers treat a bind with an empty password as an
+	// anonymous bind, which succeeds regardless of the DN.
+	if len(password) == 0 {
+		return false, nil
+	}
+
+	conn, err := p.dial(ctx, p.url)
+	if err != nil {
+		return false, fmt.Errorf("error connecting to LDAP server: %w", err)
+	}
+	defer conn.Close()
+
+	ident := NewIdentScreenName(screenName.String())
+	bindDN := strings.Replace(p.bindDNTemplate, "%s", ldap.EscapeDN(ident.String()), 1)
+
+	if err := conn.Bind(bindDN, string(password)); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error binding to LDAP server: %w", err)
+	}
+
+	return true, nil
+}
+
+// dialLDAP connects to the directory server at url.
+func dialLDAP(ctx context.Context, url string) (ldapConn, error) {
+	dialer := &net.Dialer{Timeout: ldapTimeout}
+	if deadline, ok := ctx.Deadline(); ok {
+		dialer.Deadline = deadline
+	}
+	conn, err := ldap.DialURL(url, ldap.DialWithDialer(dialer))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(ldapTimeout)
+	return conn, nil
+}
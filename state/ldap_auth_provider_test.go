@@ -0,0 +1,122 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLDAPConn is an in-memory directory that accepts binds for a fixed set
+// of DN/password pairs.
+type fakeLDAPConn struct {
+	bindErr  error
+	closed   bool
+	entries  map[string]string
+	lastBind string
+}
+
+func (c *fakeLDAPConn) Bind(username, password string) error {
+	c.lastBind = username
+	if c.bindErr != nil {
+		return c.bindErr
+	}
+	if pass, ok := c.entries[username]; !ok || pass != password {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	return nil
+}
+
+func (c *fakeLDAPConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestLDAPAuthProvider_Authenticate(t *testing.T) {
+	tests := []struct {
+		name       string
+		screenName DisplayScreenName
+		password   string
+		bindErr    error
+		dialErr    error
+		wantOK     bool
+		wantBind   string
+		wantErr    bool
+	}{
+		{
+			name:       "correct password",
+			screenName: "Joe Smith",
+			password:   "thepassword",
+			wantOK:     true,
+			wantBind:   "uid=joesmith,ou=people,dc=example,dc=org",
+		},
+		{
+			name:       "wrong password",
+			screenName: "joesmith",
+			password:   "notthepassword",
+			wantOK:     false,
+			wantBind:   "uid=joesmith,ou=people,dc=example,dc=org",
+		},
+		{
+			name:       "empty password is rejected without binding",
+			screenName: "joesmith",
+			password:   "",
+			wantOK:     false,
+		},
+		{
+			name:       "screen name is escaped",
+			screenName: "joe,smith",
+			password:   "thepassword",
+			wantOK:     false,
+			wantBind:   `uid=joe\,smith,ou=people,dc=example,dc=org`,
+		},
+		{
+			name:       "bind error",
+			screenName: "joesmith",
+			password:   "thepassword",
+			bindErr:    ldap.NewError(ldap.LDAPResultUnavailable, errors.New("unavailable")),
+			wantBind:   "uid=joesmith,ou=people,dc=example,dc=org",
+			wantErr:    true,
+		},
+		{
+			name:       "dial error",
+			screenName: "joesmith",
+			password:   "thepassword",
+			dialErr:    errors.New("connection refused"),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeLDAPConn{
+				bindErr: tt.bindErr,
+				entries: map[string]string{
+					"uid=joesmith,ou=people,dc=example,dc=org": "thepassword",
+				},
+			}
+			provider := NewLDAPAuthProvider("ldap://localhost", "uid=%s,ou=people,dc=example,dc=org")
+			provider.dial = func(ctx context.Context, url string) (ldapConn, error) {
+				assert.Equal(t, "ldap://localhost", url)
+				if tt.dialErr != nil {
+					return nil, tt.dialErr
+				}
+				return conn, nil
+			}
+
+			ok, err := provider.Authenticate(context.Background(), tt.screenName, []byte(tt.password))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantBind, conn.lastBind)
+			if tt.wantBind != "" {
+				assert.True(t, conn.closed)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package state
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LoginLockout describes an account that is locked out after too many failed
+// login attempts.
+type LoginLockout struct {
+	// ScreenName is the locked account.
+	ScreenName IdentScreenName
+	// FailedAttempts is the number of consecutive failed login attempts.
+	FailedAttempts int
+	// LockedUntil is the time at which the account can log in again.
+	LockedUntil time.Time
+}
+
+// loginFailures tracks failed login attempts for a single account.
+type loginFailures struct {
+	count       int
+	firstFailed time.Time
+	lockedUntil time.Time
+}
+
+// LoginLockoutTracker counts failed login attempts per account and locks out
+// accounts that exceed a threshold of failures within a time window. Unlike
+// per-IP rate limiting, this protects accounts from password guessing
+// distributed across many hosts. Lockout state is kept in memory and is not
+// preserved across server restarts. It's not shared between the nodes of a
+// cluster either, so each node allows the full threshold of failures. A
+// LoginLockoutTracker is safe for concurrent use by multiple goroutines.
+type LoginLockoutTracker struct {
+	cooldown  time.Duration
+	lastSweep time.Time
+	mutex     sync.Mutex
+	records   map[IdentScreenName]*loginFailures
+	threshold int
+	timeNow   func() time.Time
+	window    time.Duration
+}
+
+// NewLoginLockoutTracker creates a new instance of LoginLockoutTracker. An
+// account is locked for cooldown after threshold failed login attempts occur
+// within window. A threshold of 0 disables lockouts.
+func NewLoginLockoutTracker(threshold int, window time.Duration, cooldown time.Duration) *LoginLockoutTracker {
+	return &LoginLockoutTracker{
+		cooldown:  cooldown,
+		records:   make(map[IdentScreenName]*loginFailures),
+		threshold: threshold,
+		timeNow:   time.Now,
+		window:    window,
+	}
+}
+
+// IsLocked indicates whether screenName is currently locked out.
+func (t *LoginLockoutTracker) IsLocked(screenName IdentScreenName) bool {
+	if t.threshold <= 0 {
+		return false
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	rec, ok := t.records[screenName]
+	if !ok {
+		return false
+	}
+	now := t.timeNow()
+	if t.expired(rec, now) {
+		delete(t.records, screenName)
+		return false
+	}
+	return now.Before(rec.lockedUntil)
+}
+
+// RecordFailure registers a failed login attempt for screenName. The account
+// is locked once the number of failures within the window reaches the
+// threshold.
+func (t *LoginLockoutTracker) RecordFailure(screenName IdentScreenName) {
+	if t.threshold <= 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.timeNow()
+	t.sweep(now)
+
+	rec, ok := t.records[screenName]
+	if !ok || t.expired(rec, now) {
+		// first failure or the previous window elapsed, start counting again
+		rec = &loginFailures{firstFailed: now}
+		t.records[screenName] = rec
+	}
+
+	rec.count++
+	if rec.count >= t.threshold {
+		rec.lockedUntil = now.Add(t.cooldown)
+	}
+}
+
+// RecordSuccess clears the failed login attempts for screenName after a
+// successful login.
+func (t *LoginLockoutTracker) RecordSuccess(screenName IdentScreenName) {
+	if t.threshold <= 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.records, screenName)
+}
+
+// Lockouts returns all accounts that are currently locked out, sorted by
+// screen name.
+func (t *LoginLockoutTracker) Lockouts() []LoginLockout {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.timeNow()
+	lockouts := []LoginLockout{}
+	for screenName, rec := range t.records {
+		if now.Before(rec.lockedUntil) {
+			lockouts = append(lockouts, LoginLockout{
+				ScreenName:     screenName,
+				FailedAttempts: rec.count,
+				LockedUntil:    rec.lockedUntil,
+			})
+		}
+	}
+
+	sort.Slice(lockouts, func(i, j int) bool {
+		return lockouts[i].ScreenName.String() < lockouts[j].ScreenName.String()
+	})
+
+	return lockouts
+}
+
+// ClearLockout lifts the lockout for screenName and resets its failed login
+// attempt count. It returns the state that was cleared, or false if there
+// were no failed login attempts to clear.
+func (t *LoginLockoutTracker) ClearLockout(screenName IdentScreenName) (LoginLockout, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	rec, ok := t.records[screenName]
+	if !ok {
+		return LoginLockout{}, false
+	}
+	delete(t.records, screenName)
+
+	return LoginLockout{
+		ScreenName:     screenName,
+		FailedAttempts: rec.count,
+		LockedUntil:    rec.lockedUntil,
+	}, true
+}
+
+// expired indicates whether both the counting window and the lockout of rec
+// have elapsed.
+func (t *LoginLockoutTracker) expired(rec *loginFailures, now time.Time) bool {
+	return now.Sub(rec.firstFailed) > t.window && now.After(rec.lockedUntil)
+}
+
+// sweep removes expired records so that the map doesn't grow without bound
+// when failures are spread across many accounts. Records are also evicted
+// individually as they're looked up, so a full sweep only runs once per
+// window to keep the cost of a failed login constant.
+func (t *LoginLockoutTracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.window {
+		return
+	}
+	t.lastSweep = now
+	for screenName, rec := range t.records {
+		if t.expired(rec, now) {
+			delete(t.records, screenName)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginLockoutTracker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	userA := NewIdentScreenName("userA")
+	userB := NewIdentScreenName("userB")
+
+	newTracker := func() *LoginLockoutTracker {
+		tracker := NewLoginLockoutTracker(3, 10*time.Minute, 15*time.Minute)
+		tracker.timeNow = func() time.Time {
+			return now
+		}
+		return tracker
+	}
+
+	t.Run("lock account after reaching threshold", func(t *testing.T) {
+		tracker := newTracker()
+
+		tracker.RecordFailure(userA)
+		tracker.RecordFailure(userA)
+		assert.False(t, tracker.IsLocked(userA))
+
+		tracker.RecordFailure(userA)
+		assert.True(t, tracker.IsLocked(userA))
+		assert.False(t, tracker.IsLocked(userB))
+
+		assert.Equal(t, []LoginLockout{
+			{
+				ScreenName:     userA,
+				FailedAttempts: 3,
+				LockedUntil:    now.Add(15 * time.Minute),
+			},
+		}, tracker.Lockouts())
+	})
+
+	t.Run("successful login resets failure count", func(t *testing.T) {
+		tracker := newTracker()
+
+		tracker.RecordFailure(userA)
+		tracker.RecordFailure(userA)
+		tracker.RecordSuccess(userA)
+		tracker.RecordFailure(userA)
+		assert.False(t, tracker.IsLocked(userA))
+	})
+
+	t.Run("failures outside the window are not counted", func(t *testing.T) {
+		tracker := newTracker()
+		start := now
+		defer func() { now = start }()
+
+		tracker.RecordFailure(userA)
+		tracker.RecordFailure(userA)
+		now = now.Add(11 * time.Minute)
+		tracker.RecordFailure(userA)
+		assert.False(t, tracker.IsLocked(userA))
+	})
+
+	t.Run("lockout expires after cooldown", func(t *testing.T) {
+		tracker := newTracker()
+		start := now
+		defer func() { now = start }()
+
+		tracker.RecordFailure(userA)
+		tracker.RecordFailure(userA)
+		tracker.RecordFailure(userA)
+		assert.True(t, tracker.IsLocked(userA))
+
+		now = now.Add(15 * time.Minute)
+		assert.False(t, tracker.IsLocked(userA))
+		assert.Empty(t, tracker.Lockouts())
+	})
+
+	t.Run("clear lockout", func(t *testing.T) {
+		tracker := newTracker()
+
+		tracker.RecordFailure(userA)
+		tracker.RecordFailure(userA)
+		tracker.RecordFailure(userA)
+		tracker.RecordFailure(userB)
+
+		cleared, ok := tracker.ClearLockout(userA)
+		assert.True(t, ok)
+		assert.Equal(t, LoginLockout{
+			ScreenName:     userA,
+			FailedAttempts: 3,
+			LockedUntil:    now.Add(15 * time.Minute),
+		}, cleared)
+		assert.False(t, tracker.IsLocked(userA))
+		_, ok = tracker.ClearLockout(userA)
+		assert.False(t, ok)
+		// userB has failures but isn't locked out
+		cleared, ok = tracker.ClearLockout(userB)
+		assert.True(t, ok)
+		assert.Equal(t, 1, cleared.FailedAttempts)
+		assert.True(t, cleared.LockedUntil.IsZero())
+		_, ok = tracker.ClearLockout(userB)
+		assert.False(t, ok)
+	})
+
+	t.Run("expired records are evicted", func(t *testing.T) {
+		tracker := newTracker()
+		start := now
+		defer func() { now = start }()
+
+		tracker.RecordFailure(userA)
+		tracker.RecordFailure(userB)
+		assert.Len(t, tracker.records, 2)
+
+		// userA's record is evicted when it's looked up
+		now = now.Add(11 * time.Minute)
+		assert.False(t, tracker.IsLocked(userA))
+		assert.Len(t, tracker.records, 1)
+
+		// userB's record is evicted by the sweep on the next failure
+		tracker.RecordFailure(userA)
+		assert.Len(t, tracker.records, 1)
+		assert.Contains(t, tracker.records, userA)
+	})
+
+	t.Run("zero threshold disables lockouts", func(t *testing.T) {
+		tracker := NewLoginLockoutTracker(0, 10*time.Minute, 15*time.Minute)
+
+		for i := 0; i < 10; i++ {
+			tracker.RecordFailure(userA)
+		}
+		assert.False(t, tracker.IsLocked(userA))
+		assert.Empty(t, tracker.Lockouts())
+	})
+}
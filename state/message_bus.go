@@ -0,0 +1,128 @@
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MessageBus carries messages between the nodes of a server cluster. Every
+// message published to a channel is delivered to all subscribers of that
+// channel, including subscribers on the publishing node.
+type MessageBus interface {
+	// Publish sends payload to all subscribers of channel.
+	Publish(ctx context.Context, channel string, payload []byte) error
+	// Subscribe returns a channel that receives the messages published to
+	// channel until ctx is done, at which point the returned channel is
+	// closed.
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+	// Claim atomically sets key to owner unless key is already set. The
+	// claim expires after ttl unless it's released first. It returns false
+	// if key is claimed by someone else.
+	Claim(ctx context.Context, key string, owner string, ttl time.Duration) (bool, error)
+	// Release deletes key if it's still claimed by owner.
+	Release(ctx context.Context, key string, owner string) error
+}
+
+// busClaim is a key claimed on an InProcessMessageBus.
+type busClaim struct {
+	owner   string
+	expires time.Time
+}
+
+// busSubscriber is a single subscription to an InProcessMessageBus channel.
+type busSubscriber struct {
+	ch   chan []byte
+	done <-chan struct{}
+}
+
+// InProcessMessageBus is a MessageBus that delivers messages between
+// subscribers in the same process. It's useful for tests and for running
+// several nodes in one process. An InProcessMessageBus is safe for concurrent
+// use by multiple goroutines.
+type InProcessMessageBus struct {
+	claimMutex sync.Mutex
+	claims     map[string]busClaim
+	mutex      sync.RWMutex
+	subs       map[string]map[*busSubscriber]struct{}
+}
+
+// NewInProcessMessageBus creates a new instance of InProcessMessageBus.
+func NewInProcessMessageBus() *InProcessMessageBus {
+	return &InProcessMessageBus{
+		claims: make(map[string]busClaim),
+		subs:   make(map[string]map[*busSubscriber]struct{}),
+	}
+}
+
+// Publish delivers payload to all subscribers of channel. It blocks until
+// each subscriber has received the message, its subscription ends, or ctx is
+// done.
+func (b *InProcessMessageBus) Publish(ctx context.Context, channel string, payload []byte) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for sub := range b.subs[channel] {
+		select {
+		case sub.ch <- payload:
+		case <-sub.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a channel that receives the messages published to
+// channel until ctx is done.
+func (b *InProcessMessageBus) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	sub := &busSubscriber{
+		ch:   make(chan []byte, 100),
+		done: ctx.Done(),
+	}
+
+	b.mutex.Lock()
+	if _, ok := b.subs[channel]; !ok {
+		b.subs[channel] = make(map[*busSubscriber]struct{})
+	}
+	b.subs[channel][sub] = struct{}{}
+	b.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		delete(b.subs[channel], sub)
+		if len(b.subs[channel]) == 0 {
+			delete(b.subs, channel)
+		}
+		close(sub.ch)
+	}()
+
+	return sub.ch, nil
+}
+
+// Claim sets key to owner unless key is claimed and the claim hasn't
+// expired.
+func (b *InProcessMessageBus) Claim(_ context.Context, key string, owner string, ttl time.Duration) (bool, error) {
+	b.claimMutex.Lock()
+	defer b.claimMutex.Unlock()
+
+	now := time.Now()
+	if claim, ok := b.claims[key]; ok && now.Before(claim.expires) {
+		return false, nil
+	}
+	b.claims[key] = busClaim{owner: owner, expires: now.Add(ttl)}
+	return true, nil
+}
+
+// Release deletes key if it's still claimed by owner.
+func (b *InProcessMessageBus) Release(_ context.Context, key string, owner string) error {
+	b.claimMutex.Lock()
+	defer b.claimMutex.Unlock()
+
+	if claim, ok := b.claims[key]; ok && claim.owner == owner {
+		delete(b.claims, key)
+	}
+	return nil
+}
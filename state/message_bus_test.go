@@ -0,0 +1,98 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInProcessMessageBus_PublishSubscribe(t *testing.T) {
+	bus := NewInProcessMessageBus()
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	sub1, err := bus.Subscribe(ctx1, "chan-1")
+	require.NoError(t, err)
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	sub2, err := bus.Subscribe(ctx2, "chan-1")
+	require.NoError(t, err)
+
+	other, err := bus.Subscribe(ctx2, "chan-2")
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(context.Background(), "chan-1", []byte("hello")))
+	assert.Equal(t, []byte("hello"), <-sub1)
+	assert.Equal(t, []byte("hello"), <-sub2)
+
+	select {
+	case msg := <-other:
+		t.Fatalf("unexpected message on other channel: %s", msg)
+	default:
+	}
+
+	// the channel closes once the subscription ends
+	cancel1()
+	_, ok := <-sub1
+	assert.False(t, ok)
+
+	require.NoError(t, bus.Publish(context.Background(), "chan-1", []byte("world")))
+	assert.Equal(t, []byte("world"), <-sub2)
+}
+
+func TestInProcessMessageBus_Publish_SubscriberGone(t *testing.T) {
+	bus := NewInProcessMessageBus()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := bus.Subscribe(ctx, "chan-1")
+	require.NoError(t, err)
+
+	// fill the subscriber's buffer, nobody is reading
+	for i := 0; i < 100; i++ {
+		require.NoError(t, bus.Publish(context.Background(), "chan-1", []byte("hello")))
+	}
+
+	pubCtx, pubCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer pubCancel()
+	assert.ErrorIs(t, bus.Publish(pubCtx, "chan-1", []byte("hello")), context.DeadlineExceeded)
+
+	// publishing doesn't block once the subscription ends
+	cancel()
+	assert.Eventually(t, func() bool {
+		return bus.Publish(context.Background(), "chan-1", []byte("hello")) == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestInProcessMessageBus_ClaimRelease(t *testing.T) {
+	bus := NewInProcessMessageBus()
+	ctx := context.Background()
+
+	ok, err := bus.Claim(ctx, "key-1", "owner-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = bus.Claim(ctx, "key-1", "owner-2", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// someone else's claim is left alone
+	require.NoError(t, bus.Release(ctx, "key-1", "owner-2"))
+	ok, err = bus.Claim(ctx, "key-1", "owner-2", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, bus.Release(ctx, "key-1", "owner-1"))
+	ok, err = bus.Claim(ctx, "key-1", "owner-2", time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// the claim can be taken over once it expires
+	time.Sleep(5 * time.Millisecond)
+	ok, err = bus.Claim(ctx, "key-1", "owner-3", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
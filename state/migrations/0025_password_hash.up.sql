@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN passwordHash TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS totpRecoveryCode;
+
+ALTER TABLE users
+    DROP COLUMN totpSecret;
@@ -0,0 +1,9 @@
+ALTER TABLE users
+    ADD COLUMN totpSecret TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS totpRecoveryCode
+(
+    screenName VARCHAR(16) NOT NULL,
+    codeHash   TEXT        NOT NULL,
+    PRIMARY KEY (screenName, codeHash)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_emailToken_screenName;
+DROP TABLE IF EXISTS emailToken;
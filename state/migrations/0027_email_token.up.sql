@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS emailToken
+(
+    tokenHash  TEXT PRIMARY KEY,
+    screenName VARCHAR(16) NOT NULL,
+    purpose    TEXT        NOT NULL,
+    expiresAt  INTEGER     NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_emailToken_screenName ON emailToken (screenName, purpose);
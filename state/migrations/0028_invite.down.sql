@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_invite_email;
+DROP INDEX IF EXISTS idx_invite_sender;
+DROP TABLE IF EXISTS invite;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS invite
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    sender    VARCHAR(16) NOT NULL,
+    email     TEXT        NOT NULL,
+    message   TEXT        NOT NULL DEFAULT '',
+    createdAt INTEGER     NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_invite_sender ON invite (sender, createdAt);
+CREATE INDEX IF NOT EXISTS idx_invite_email ON invite (email COLLATE NOCASE);
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN rateLimitOverrides TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_apiAdmin_tokenHash;
+DROP TABLE IF EXISTS apiAdmin;
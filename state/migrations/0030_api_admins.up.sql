@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS apiAdmin
+(
+    username     VARCHAR(64) PRIMARY KEY COLLATE NOCASE,
+    passwordHash TEXT        NOT NULL,
+    role         VARCHAR(16) NOT NULL,
+    tokenHash    TEXT        NOT NULL DEFAULT '',
+    createdAt    INTEGER     NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_apiAdmin_tokenHash ON apiAdmin (tokenHash);
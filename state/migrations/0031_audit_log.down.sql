@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS auditLog_no_delete;
+DROP TRIGGER IF EXISTS auditLog_no_update;
+DROP INDEX IF EXISTS idx_auditLog_target;
+DROP INDEX IF EXISTS idx_auditLog_actor;
+DROP INDEX IF EXISTS idx_auditLog_createdAt;
+DROP TABLE IF EXISTS auditLog;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS auditLog
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    createdAt INTEGER      NOT NULL,
+    actor     VARCHAR(64)  NOT NULL COLLATE NOCASE,
+    action    VARCHAR(128) NOT NULL,
+    target    TEXT         NOT NULL DEFAULT '' COLLATE NOCASE,
+    oldValue  TEXT         NOT NULL DEFAULT '',
+    newValue  TEXT         NOT NULL DEFAULT '',
+    sourceIP  VARCHAR(45)  NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_auditLog_createdAt ON auditLog (createdAt);
+CREATE INDEX IF NOT EXISTS idx_auditLog_actor ON auditLog (actor);
+CREATE INDEX IF NOT EXISTS idx_auditLog_target ON auditLog (target);
+CREATE TRIGGER IF NOT EXISTS auditLog_no_update
+    BEFORE UPDATE
+    ON auditLog
+BEGIN
+    SELECT RAISE(ABORT, 'audit log entries can not be modified');
+END;
+CREATE TRIGGER IF NOT EXISTS auditLog_no_delete
+    BEFORE DELETE
+    ON auditLog
+BEGIN
+    SELECT RAISE(ABORT, 'audit log entries can not be deleted');
+END;
@@ -0,0 +1,11 @@
+ALTER TABLE users
+    DROP COLUMN imArchiveRetentionDays;
+ALTER TABLE users
+    DROP COLUMN imArchiveOptOut;
+DROP TRIGGER IF EXISTS imArchive_delete;
+DROP TRIGGER IF EXISTS imArchive_insert;
+DROP TABLE IF EXISTS imArchiveText;
+DROP INDEX IF EXISTS idx_imArchive_recipient;
+DROP INDEX IF EXISTS idx_imArchive_sender;
+DROP INDEX IF EXISTS idx_imArchive_sentAt;
+DROP TABLE IF EXISTS imArchive;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS imArchive
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    sentAt    INTEGER     NOT NULL,
+    sender    VARCHAR(16) NOT NULL,
+    recipient VARCHAR(16) NOT NULL,
+    text      TEXT        NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_imArchive_sentAt ON imArchive (sentAt);
+CREATE INDEX IF NOT EXISTS idx_imArchive_sender ON imArchive (sender);
+CREATE INDEX IF NOT EXISTS idx_imArchive_recipient ON imArchive (recipient);
+CREATE VIRTUAL TABLE IF NOT EXISTS imArchiveText USING fts5(text, content='imArchive', content_rowid='id');
+CREATE TRIGGER IF NOT EXISTS imArchive_insert
+    AFTER INSERT
+    ON imArchive
+BEGIN
+    INSERT INTO imArchiveText (rowid, text) VALUES (new.id, new.text);
+END;
+CREATE TRIGGER IF NOT EXISTS imArchive_delete
+    AFTER DELETE
+    ON imArchive
+BEGIN
+    INSERT INTO imArchiveText (imArchiveText, rowid, text) VALUES ('delete', old.id, old.text);
+END;
+ALTER TABLE users
+    ADD COLUMN imArchiveOptOut BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users
+    ADD COLUMN imArchiveRetentionDays INTEGER;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_offlineMessage_sent;
+DROP INDEX IF EXISTS idx_offlineMessage_recipient;
@@ -0,0 +1,8 @@
+-- offline messages used to be saved for any recipient, so clear out the ones
+-- that can never be delivered
+DELETE
+FROM offlineMessage
+WHERE recipient NOT IN (SELECT identScreenName FROM users);
+
+CREATE INDEX idx_offlineMessage_recipient ON offlineMessage (recipient);
+CREATE INDEX idx_offlineMessage_sent ON offlineMessage (sent);
@@ -0,0 +1,2 @@
+-- Lets NextUIN find the highest UIN in a range without scanning every user.
+CREATE INDEX idx_users_uin ON users (CAST(identScreenName AS INTEGER)) WHERE isICQ = true;
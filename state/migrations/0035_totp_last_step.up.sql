@@ -0,0 +1,4 @@
+-- The time step of the last TOTP code accepted for the user. Codes from that
+-- step or earlier are rejected so that an accepted code can't be replayed.
+ALTER TABLE users
+    ADD COLUMN totpLastStep INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,35 @@
+package state
+
+import "sync"
+
+// MOTDScreenName is the sender shown for the message of the day on clients
+// that receive it as an instant message, such as TOC and Web AIM clients.
+const MOTDScreenName DisplayScreenName = "MOTD"
+
+// MOTD holds the message of the day shown to users when they sign on. The
+// message is kept in memory and reverts to the configured message when the
+// server restarts. A MOTD is safe for concurrent use by multiple goroutines.
+type MOTD struct {
+	mutex   sync.RWMutex
+	message string
+}
+
+// NewMOTD creates a new instance of MOTD with the given message. An empty
+// message means there is no message of the day.
+func NewMOTD(message string) *MOTD {
+	return &MOTD{message: message}
+}
+
+// Message returns the message of the day, or an empty string if none is set.
+func (m *MOTD) Message() string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.message
+}
+
+// SetMessage replaces the message of the day. An empty message clears it.
+func (m *MOTD) SetMessage(message string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.message = message
+}
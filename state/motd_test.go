@@ -0,0 +1,18 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMOTD(t *testing.T) {
+	motd := NewMOTD("welcome!")
+	assert.Equal(t, "welcome!", motd.Message())
+
+	motd.SetMessage("maintenance tonight at 10pm")
+	assert.Equal(t, "maintenance tonight at 10pm", motd.Message())
+
+	motd.SetMessage("")
+	assert.Empty(t, motd.Message())
+}
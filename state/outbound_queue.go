@@ -0,0 +1,202 @@
+package state
+
+import (
+	"sync/atomic"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// OverflowPolicy determines what happens when a message is relayed to a
+// session whose outbound queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowDropPresence discards queued buddy presence updates, oldest
+	// first, to make room for new messages. If the queue holds nothing but
+	// higher priority messages, the session is disconnected.
+	OverflowDropPresence OverflowPolicy = "drop-presence"
+	// OverflowDropNewest discards the message being relayed. The session is
+	// never disconnected.
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+	// OverflowDisconnect disconnects the session.
+	OverflowDisconnect OverflowPolicy = "disconnect"
+)
+
+// DefaultOutboundQueueSize is the number of messages that can wait to be
+// sent to a client before the overflow policy applies.
+const DefaultOutboundQueueSize = 1000
+
+// deliveryBufferSize is the capacity of the channel returned by
+// Session.ReceiveMessage. Messages that don't fit wait in the session's
+// outbound queue, where they are prioritized and coalesced.
+const deliveryBufferSize = 16
+
+// OutboundQueueStats reports the state of a session's outbound queue.
+type OutboundQueueStats struct {
+	// Queued is the number of messages waiting to be sent.
+	Queued int
+	// Dropped is the number of messages discarded because the queue was
+	// full.
+	Dropped uint64
+	// Coalesced is the number of buddy presence updates that replaced an
+	// earlier update for the same buddy that was still waiting to be sent.
+	Coalesced uint64
+}
+
+// OutboundQueueTotals reports outbound queue activity across all sessions
+// since the server started.
+type OutboundQueueTotals struct {
+	// Dropped is the number of messages discarded because a queue was full.
+	Dropped uint64 `json:"dropped_messages"`
+	// Coalesced is the number of buddy presence updates that replaced an
+	// earlier update for the same buddy that was still waiting to be sent.
+	Coalesced uint64 `json:"coalesced_messages"`
+	// Disconnects is the number of times a full queue caused a client to be
+	// disconnected.
+	Disconnects uint64 `json:"overflow_disconnects"`
+}
+
+// OutboundQueueMonitor counts outbound queue activity across sessions. It's
+// safe for concurrent use.
+type OutboundQueueMonitor struct {
+	dropped     atomic.Uint64
+	coalesced   atomic.Uint64
+	disconnects atomic.Uint64
+}
+
+// outboundQueueMonitor is the monitor that every session's outbound queue
+// reports to.
+var outboundQueueMonitor OutboundQueueMonitor
+
+// DefaultOutboundQueueMonitor returns the monitor that every session's
+// outbound queue reports to.
+func DefaultOutboundQueueMonitor() *OutboundQueueMonitor {
+	return &outboundQueueMonitor
+}
+
+// OutboundQueueTotals returns the activity counted so far.
+func (m *OutboundQueueMonitor) OutboundQueueTotals() OutboundQueueTotals {
+	return OutboundQueueTotals{
+		Dropped:     m.dropped.Load(),
+		Coalesced:   m.coalesced.Load(),
+		Disconnects: m.disconnects.Load(),
+	}
+}
+
+// queuedPresence is a buddy presence update waiting to be sent.
+type queuedPresence struct {
+	buddy IdentScreenName
+	msg   wire.SNACMessage
+}
+
+// outboundQueue holds messages that are waiting to be sent to a client.
+// Buddy presence updates are low priority: they are sent after other
+// messages, dropped first when the queue is full, and a newer update for a
+// buddy replaces an older one that hasn't been sent yet. Messages of the
+// same priority are sent in the order they were queued.
+type outboundQueue struct {
+	high      []wire.SNACMessage
+	low       []*queuedPresence
+	presence  map[IdentScreenName]*queuedPresence
+	dropped   uint64
+	coalesced uint64
+}
+
+// presenceBuddy returns the buddy whose presence msg updates. It returns
+// false if msg is not a buddy presence update.
+func presenceBuddy(msg wire.SNACMessage) (IdentScreenName, bool) {
+	switch body := msg.Body.(type) {
+	case wire.SNAC_0x03_0x0B_BuddyArrived:
+		return NewIdentScreenName(body.ScreenName), true
+	case wire.SNAC_0x03_0x0C_BuddyDeparted:
+		return NewIdentScreenName(body.ScreenName), true
+	}
+	return IdentScreenName{}, false
+}
+
+func (q *outboundQueue) len() int {
+	return len(q.high) + len(q.low)
+}
+
+// push adds msg to the queue, applying policy if the queue already holds
+// size messages. It returns SessSendOK if msg was queued,
+// SessMessageDropped if it was discarded, or SessQueueFull if the session
+// should be disconnected.
+func (q *outboundQueue) push(msg wire.SNACMessage, size int, policy OverflowPolicy) SessSendStatus {
+	buddy, isPresence := presenceBuddy(msg)
+
+	if isPresence {
+		if pending, ok := q.presence[buddy]; ok {
+			pending.msg = msg
+			q.coalesced++
+			outboundQueueMonitor.coalesced.Add(1)
+			return SessSendOK
+		}
+	}
+
+	if q.len() >= size {
+		switch policy {
+		case OverflowDisconnect:
+			outboundQueueMonitor.disconnects.Add(1)
+			return SessQueueFull
+		case OverflowDropNewest:
+			q.drop()
+			return SessMessageDropped
+		default: // OverflowDropPresence
+			if len(q.low) == 0 {
+				if isPresence {
+					q.drop()
+					return SessMessageDropped
+				}
+				outboundQueueMonitor.disconnects.Add(1)
+				return SessQueueFull
+			}
+			q.popLow()
+			q.drop()
+		}
+	}
+
+	if isPresence {
+		pending := &queuedPresence{buddy: buddy, msg: msg}
+		if q.presence == nil {
+			q.presence = make(map[IdentScreenName]*queuedPresence)
+		}
+		q.presence[buddy] = pending
+		q.low = append(q.low, pending)
+	} else {
+		q.high = append(q.high, msg)
+	}
+
+	return SessSendOK
+}
+
+// drop counts a discarded message.
+func (q *outboundQueue) drop() {
+	q.dropped++
+	outboundQueueMonitor.dropped.Add(1)
+}
+
+// pop removes and returns the next message to send. It returns false if the
+// queue is empty.
+func (q *outboundQueue) pop() (wire.SNACMessage, bool) {
+	if len(q.high) > 0 {
+		msg := q.high[0]
+		q.high[0] = wire.SNACMessage{}
+		q.high = q.high[1:]
+		return msg, true
+	}
+	if len(q.low) > 0 {
+		return q.popLow(), true
+	}
+	return wire.SNACMessage{}, false
+}
+
+// popLow removes and returns the oldest buddy presence update. The caller
+// must make sure there is one.
+func (q *outboundQueue) popLow() wire.SNACMessage {
+	pending := q.low[0]
+	q.low[0] = nil
+	q.low = q.low[1:]
+	delete(q.presence, pending.buddy)
+	return pending.msg
+}
@@ -0,0 +1,214 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+func arrivedMsg(screenName string, warning uint16) wire.SNACMessage {
+	return wire.SNACMessage{
+		Frame: wire.SNACFrame{FoodGroup: wire.Buddy, SubGroup: wire.BuddyArrived},
+		Body: wire.SNAC_0x03_0x0B_BuddyArrived{
+			TLVUserInfo: wire.TLVUserInfo{ScreenName: screenName, WarningLevel: warning},
+		},
+	}
+}
+
+func departedMsg(screenName string) wire.SNACMessage {
+	return wire.SNACMessage{
+		Frame: wire.SNACFrame{FoodGroup: wire.Buddy, SubGroup: wire.BuddyDeparted},
+		Body: wire.SNAC_0x03_0x0C_BuddyDeparted{
+			TLVUserInfo: wire.TLVUserInfo{ScreenName: screenName},
+		},
+	}
+}
+
+func imMsg(cookie uint64) wire.SNACMessage {
+	return wire.SNACMessage{
+		Frame: wire.SNACFrame{FoodGroup: wire.ICBM, SubGroup: wire.ICBMChannelMsgToClient},
+		Body:  wire.SNAC_0x04_0x07_ICBMChannelMsgToClient{Cookie: cookie},
+	}
+}
+
+func drain(q *outboundQueue) []wire.SNACMessage {
+	var msgs []wire.SNACMessage
+	for {
+		msg, ok := q.pop()
+		if !ok {
+			return msgs
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+func TestOutboundQueue_Priority(t *testing.T) {
+	q := outboundQueue{}
+
+	assert.Equal(t, SessSendOK, q.push(arrivedMsg("buddy1", 0), 10, OverflowDropPresence))
+	assert.Equal(t, SessSendOK, q.push(imMsg(1), 10, OverflowDropPresence))
+	assert.Equal(t, SessSendOK, q.push(departedMsg("buddy2"), 10, OverflowDropPresence))
+	assert.Equal(t, SessSendOK, q.push(imMsg(2), 10, OverflowDropPresence))
+	assert.Equal(t, 4, q.len())
+
+	assert.Equal(t, []wire.SNACMessage{
+		imMsg(1),
+		imMsg(2),
+		arrivedMsg("buddy1", 0),
+		departedMsg("buddy2"),
+	}, drain(&q))
+	assert.Equal(t, 0, q.len())
+}
+
+func TestOutboundQueue_CoalescePresence(t *testing.T) {
+	q := outboundQueue{}
+
+	assert.Equal(t, SessSendOK, q.push(arrivedMsg("buddy1", 0), 10, OverflowDropPresence))
+	assert.Equal(t, SessSendOK, q.push(arrivedMsg("buddy2", 0), 10, OverflowDropPresence))
+	assert.Equal(t, SessSendOK, q.push(arrivedMsg("Buddy 1", 10), 10, OverflowDropPresence))
+	assert.Equal(t, SessSendOK, q.push(departedMsg("buddy2"), 10, OverflowDropPresence))
+	assert.Equal(t, 2, q.len())
+	assert.Equal(t, uint64(2), q.coalesced)
+
+	// the latest update for each buddy keeps the position of the first
+	assert.Equal(t, []wire.SNACMessage{
+		arrivedMsg("Buddy 1", 10),
+		departedMsg("buddy2"),
+	}, drain(&q))
+
+	// once sent, a buddy's next update is queued rather than coalesced
+	assert.Equal(t, SessSendOK, q.push(arrivedMsg("buddy1", 0), 10, OverflowDropPresence))
+	assert.Equal(t, uint64(2), q.coalesced)
+	assert.Equal(t, 1, q.len())
+}
+
+func TestOutboundQueue_Overflow(t *testing.T) {
+	tests := []struct {
+		name string
+		// policy is the overflow policy under test
+		policy OverflowPolicy
+		// given is pushed to a queue that holds 2 messages
+		given []wire.SNACMessage
+		// push is the message pushed to the full queue
+		push wire.SNACMessage
+		// wantStatus is the status returned for push
+		wantStatus SessSendStatus
+		// wantQueue is what's left in the queue
+		wantQueue []wire.SNACMessage
+		// wantDropped is the number of dropped messages
+		wantDropped uint64
+	}{
+		{
+			name:        "drop-presence evicts the oldest presence update for an IM",
+			policy:      OverflowDropPresence,
+			given:       []wire.SNACMessage{arrivedMsg("buddy1", 0), arrivedMsg("buddy2", 0)},
+			push:        imMsg(1),
+			wantStatus:  SessSendOK,
+			wantQueue:   []wire.SNACMessage{imMsg(1), arrivedMsg("buddy2", 0)},
+			wantDropped: 1,
+		},
+		{
+			name:        "drop-presence evicts the oldest presence update for another presence update",
+			policy:      OverflowDropPresence,
+			given:       []wire.SNACMessage{imMsg(1), arrivedMsg("buddy1", 0)},
+			push:        departedMsg("buddy2"),
+			wantStatus:  SessSendOK,
+			wantQueue:   []wire.SNACMessage{imMsg(1), departedMsg("buddy2")},
+			wantDropped: 1,
+		},
+		{
+			name:        "drop-presence drops a presence update when only IMs are queued",
+			policy:      OverflowDropPresence,
+			given:       []wire.SNACMessage{imMsg(1), imMsg(2)},
+			push:        arrivedMsg("buddy1", 0),
+			wantStatus:  SessMessageDropped,
+			wantQueue:   []wire.SNACMessage{imMsg(1), imMsg(2)},
+			wantDropped: 1,
+		},
+		{
+			name:        "drop-presence disconnects when only IMs are queued",
+			policy:      OverflowDropPresence,
+			given:       []wire.SNACMessage{imMsg(1), imMsg(2)},
+			push:        imMsg(3),
+			wantStatus:  SessQueueFull,
+			wantQueue:   []wire.SNACMessage{imMsg(1), imMsg(2)},
+			wantDropped: 0,
+		},
+		{
+			name:        "empty policy behaves like drop-presence",
+			policy:      "",
+			given:       []wire.SNACMessage{arrivedMsg("buddy1", 0), imMsg(1)},
+			push:        imMsg(2),
+			wantStatus:  SessSendOK,
+			wantQueue:   []wire.SNACMessage{imMsg(1), imMsg(2)},
+			wantDropped: 1,
+		},
+		{
+			name:        "drop-newest drops the pushed message",
+			policy:      OverflowDropNewest,
+			given:       []wire.SNACMessage{arrivedMsg("buddy1", 0), imMsg(1)},
+			push:        imMsg(2),
+			wantStatus:  SessMessageDropped,
+			wantQueue:   []wire.SNACMessage{imMsg(1), arrivedMsg("buddy1", 0)},
+			wantDropped: 1,
+		},
+		{
+			name:        "disconnect disconnects",
+			policy:      OverflowDisconnect,
+			given:       []wire.SNACMessage{arrivedMsg("buddy1", 0), arrivedMsg("buddy2", 0)},
+			push:        imMsg(1),
+			wantStatus:  SessQueueFull,
+			wantQueue:   []wire.SNACMessage{arrivedMsg("buddy1", 0), arrivedMsg("buddy2", 0)},
+			wantDropped: 0,
+		},
+		{
+			name:        "a full queue still coalesces presence updates",
+			policy:      OverflowDisconnect,
+			given:       []wire.SNACMessage{arrivedMsg("buddy1", 0), imMsg(1)},
+			push:        departedMsg("buddy1"),
+			wantStatus:  SessSendOK,
+			wantQueue:   []wire.SNACMessage{imMsg(1), departedMsg("buddy1")},
+			wantDropped: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := outboundQueue{}
+			for _, msg := range tt.given {
+				assert.Equal(t, SessSendOK, q.push(msg, 2, tt.policy))
+			}
+			assert.Equal(t, tt.wantStatus, q.push(tt.push, 2, tt.policy))
+			assert.Equal(t, tt.wantDropped, q.dropped)
+			assert.Equal(t, tt.wantQueue, drain(&q))
+		})
+	}
+}
+
+func TestOutboundQueueMonitor(t *testing.T) {
+	before := DefaultOutboundQueueMonitor().OutboundQueueTotals()
+
+	q1 := outboundQueue{}
+	q1.push(arrivedMsg("buddy1", 0), 1, OverflowDropPresence)
+	q1.push(arrivedMsg("buddy1", 10), 1, OverflowDropPresence) // coalesced
+	q1.push(imMsg(1), 1, OverflowDropPresence)                 // drops the presence update
+	q1.push(imMsg(2), 1, OverflowDropPresence)                 // disconnects
+
+	// counts from every queue add up
+	q2 := outboundQueue{}
+	q2.push(imMsg(1), 1, OverflowDropNewest)
+	q2.push(imMsg(2), 1, OverflowDropNewest) // dropped
+
+	after := DefaultOutboundQueueMonitor().OutboundQueueTotals()
+	assert.Equal(t, OutboundQueueTotals{
+		Dropped:     2,
+		Coalesced:   1,
+		Disconnects: 1,
+	}, OutboundQueueTotals{
+		Dropped:     after.Dropped - before.Dropped,
+		Coalesced:   after.Coalesced - before.Coalesced,
+		Disconnects: after.Disconnects - before.Disconnects,
+	})
+}
@@ -0,0 +1,35 @@
+package state
+
+import (
+	"sync"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// RateLimits holds the rate limit classes that apply to accounts without
+// overrides. The classes can be replaced while the server runs, such as when
+// the file at RATE_LIMITS_FILE changes. A RateLimits is safe for concurrent
+// use by multiple goroutines.
+type RateLimits struct {
+	mutex   sync.RWMutex
+	classes wire.RateLimitClasses
+}
+
+// NewRateLimits creates a new instance of RateLimits with the given classes.
+func NewRateLimits(classes wire.RateLimitClasses) *RateLimits {
+	return &RateLimits{classes: classes}
+}
+
+// RateLimitClasses returns the rate limit classes.
+func (r *RateLimits) RateLimitClasses() wire.RateLimitClasses {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.classes
+}
+
+// SetRateLimitClasses replaces the rate limit classes.
+func (r *RateLimits) SetRateLimitClasses(classes wire.RateLimitClasses) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.classes = classes
+}
@@ -0,0 +1,21 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+func TestRateLimits(t *testing.T) {
+	classes := wire.DefaultRateLimitClasses()
+	rateLimits := NewRateLimits(classes)
+	assert.Equal(t, classes, rateLimits.RateLimitClasses())
+
+	override := wire.RateClass{ID: 3, WindowSize: 10, ClearLevel: 1, AlertLevel: 2, LimitLevel: 3, DisconnectLevel: 1, MaxLevel: 4}
+	next := classes.WithOverrides([]wire.RateClass{override})
+	rateLimits.SetRateLimitClasses(next)
+	assert.Equal(t, next, rateLimits.RateLimitClasses())
+	assert.Equal(t, override, rateLimits.RateLimitClasses().Get(3))
+}
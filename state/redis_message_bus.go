@@ -0,0 +1,311 @@
+package state
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// redisTimeout is how long to wait for the Redis server to answer a
+	// command.
+	redisTimeout = 5 * time.Second
+	// redisReconnectDelay is how long a subscription waits before
+	// reconnecting after losing its connection.
+	redisReconnectDelay = time.Second
+)
+
+// redisError is an error reply sent by the Redis server.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// RedisMessageBus is a MessageBus backed by the publish/subscribe commands of
+// a Redis-compatible server, such as Redis, Valkey or KeyDB. Claims are
+// stored as keys that expire. Messages are published and keys are claimed
+// over a shared connection, while each subscription gets its own connection
+// that is re-established if it drops. Messages published while a
+// subscription is reconnecting are lost. A RedisMessageBus is safe for
+// concurrent use by multiple goroutines.
+type RedisMessageBus struct {
+	addr     string
+	conn     *redisConn
+	logger   *slog.Logger
+	mutex    sync.Mutex
+	password string
+}
+
+// NewRedisMessageBus creates a new instance of RedisMessageBus. addr is the
+// host:port of the Redis server. If password is set, connections
+// authenticate with the AUTH command.
+func NewRedisMessageBus(addr string, password string, logger *slog.Logger) *RedisMessageBus {
+	return &RedisMessageBus{
+		addr:     addr,
+		logger:   logger,
+		password: password,
+	}
+}
+
+// redisReleaseScript deletes a key only if it holds the expected value, so
+// that an expired claim that someone else took over isn't released.
+const redisReleaseScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`
+
+// Publish sends payload to all subscribers of channel.
+func (b *RedisMessageBus) Publish(ctx context.Context, channel string, payload []byte) error {
+	if _, err := b.do(ctx, "PUBLISH", []byte(channel), payload); err != nil {
+		return fmt.Errorf("error publishing to %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Claim sets key to owner with the SET command's NX option, so that only
+// one caller can claim key until the claim is released or expires after
+// ttl.
+func (b *RedisMessageBus) Claim(ctx context.Context, key string, owner string, ttl time.Duration) (bool, error) {
+	reply, err := b.do(ctx, "SET", []byte(key), []byte(owner), []byte("NX"), []byte("PX"),
+		strconv.AppendInt(nil, ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, fmt.Errorf("error claiming %s: %w", key, err)
+	}
+	// the server replies OK if the key was set and nil otherwise
+	return reply != nil, nil
+}
+
+// Release deletes key if it's still claimed by owner.
+func (b *RedisMessageBus) Release(ctx context.Context, key string, owner string) error {
+	if _, err := b.do(ctx, "EVAL", []byte(redisReleaseScript), []byte("1"), []byte(key), []byte(owner)); err != nil {
+		return fmt.Errorf("error releasing %s: %w", key, err)
+	}
+	return nil
+}
+
+// do sends a command over the shared connection, which is opened if
+// needed.
+func (b *RedisMessageBus) do(ctx context.Context, cmd string, args ...[]byte) (any, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.conn == nil {
+		conn, err := b.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.conn = conn
+	}
+
+	reply, err := b.conn.do(ctx, cmd, args...)
+	if err != nil {
+		// start over with a new connection next time, unless the server
+		// merely rejected the command
+		var redisErr redisError
+		if !errors.As(err, &redisErr) {
+			_ = b.conn.Close()
+			b.conn = nil
+		}
+		return nil, err
+	}
+	return reply, nil
+}
+
+// Subscribe returns a channel that receives the messages published to
+// channel until ctx is done. It returns an error if the initial subscription
+// fails.
+func (b *RedisMessageBus) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	conn, err := b.subscribe(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan []byte, 100)
+	go func() {
+		defer close(ch)
+		for {
+			b.receive(ctx, conn, channel, ch)
+			if ctx.Err() != nil {
+				return
+			}
+			b.logger.WarnContext(ctx, "lost connection to message bus, reconnecting", "channel", channel)
+			for {
+				select {
+				case <-time.After(redisReconnectDelay):
+				case <-ctx.Done():
+					return
+				}
+				if conn, err = b.subscribe(ctx, channel); err == nil {
+					break
+				}
+				b.logger.ErrorContext(ctx, "unable to reconnect to message bus", "channel", channel, "err", err.Error())
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// subscribe opens a connection that's subscribed to channel.
+func (b *RedisMessageBus) subscribe(ctx context.Context, channel string) (*redisConn, error) {
+	conn, err := b.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.do(ctx, "SUBSCRIBE", []byte(channel)); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("error subscribing to %s: %w", channel, err)
+	}
+	return conn, nil
+}
+
+// receive forwards messages from a subscribed connection to ch until the
+// connection fails or ctx is done.
+func (b *RedisMessageBus) receive(ctx context.Context, conn *redisConn, channel string, ch chan<- []byte) {
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close() // unblock the read below
+	})
+	defer stop()
+	defer conn.Close()
+
+	for {
+		reply, err := conn.read()
+		if err != nil {
+			return
+		}
+		// pushed messages look like ["message", channel, payload]
+		msg, ok := reply.([]any)
+		if !ok || len(msg) != 3 {
+			continue
+		}
+		kind, _ := msg[0].([]byte)
+		payload, _ := msg[2].([]byte)
+		if string(kind) != "message" {
+			continue
+		}
+		select {
+		case ch <- payload:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// dial opens a connection to the Redis server and authenticates it.
+func (b *RedisMessageBus) dial(ctx context.Context) (*redisConn, error) {
+	dialer := &net.Dialer{Timeout: redisTimeout}
+	c, err := dialer.DialContext(ctx, "tcp", b.addr)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to message bus: %w", err)
+	}
+	conn := &redisConn{Conn: c, rd: bufio.NewReader(c)}
+	if b.password != "" {
+		if _, err := conn.do(ctx, "AUTH", []byte(b.password)); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("error authenticating to message bus: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// redisConn is a connection that speaks the Redis serialization protocol
+// (RESP).
+type redisConn struct {
+	net.Conn
+	rd *bufio.Reader
+}
+
+// do sends a command and returns the server's reply. An error reply is
+// returned as a redisError.
+func (c *redisConn) do(ctx context.Context, cmd string, args ...[]byte) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	defer c.SetDeadline(time.Time{})
+
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)+1), 10)
+	buf = append(buf, "\r\n"...)
+	for _, arg := range append([][]byte{[]byte(cmd)}, args...) {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, "\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := c.Write(buf); err != nil {
+		return nil, err
+	}
+
+	reply, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	if err, ok := reply.(redisError); ok {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// read parses a single reply. Simple strings and bulk strings are returned
+// as []byte, integers as int64, arrays as []any and errors as redisError.
+// Null replies are returned as nil.
+func (c *redisConn) read() (any, error) {
+	line, err := c.rd.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, rest := line[0], string(line[1:len(line)-2])
+
+	switch kind {
+	case '+':
+		return []byte(rest), nil
+	case '-':
+		return redisError(rest), nil
+	case ':':
+		return strconv.ParseInt(rest, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(c.rd, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		arr := make([]any, n)
+		for i := range arr {
+			if arr[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("unknown reply type %q", kind)
+	}
+}
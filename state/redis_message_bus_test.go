@@ -0,0 +1,219 @@
+package state
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedisServer implements the subset of the Redis protocol used by
+// RedisMessageBus. Keys don't expire.
+type fakeRedisServer struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	password string
+	subs     map[string][]net.Conn
+	conns    []net.Conn
+	keys     map[string]string
+}
+
+func newFakeRedisServer(t *testing.T, password string) *fakeRedisServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeRedisServer{
+		listener: listener,
+		password: password,
+		subs:     make(map[string][]net.Conn),
+		keys:     make(map[string]string),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mutex.Lock()
+			s.conns = append(s.conns, conn)
+			s.mutex.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		s.dropConns()
+	})
+	return s
+}
+
+// dropConns closes all client connections.
+func (s *fakeRedisServer) dropConns() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.conns = nil
+	s.subs = make(map[string][]net.Conn)
+}
+
+func (s *fakeRedisServer) serve(conn net.Conn) {
+	rc := &redisConn{Conn: conn, rd: bufio.NewReader(conn)}
+	authed := s.password == ""
+	for {
+		req, err := rc.read()
+		if err != nil {
+			return
+		}
+		args, _ := req.([]any)
+		if len(args) == 0 {
+			return
+		}
+		cmd, _ := args[0].([]byte)
+
+		s.mutex.Lock()
+		switch {
+		case strings.EqualFold(string(cmd), "AUTH"):
+			if pass, _ := args[1].([]byte); string(pass) == s.password {
+				authed = true
+				_, _ = conn.Write([]byte("+OK\r\n"))
+			} else {
+				_, _ = conn.Write([]byte("-WRONGPASS invalid password\r\n"))
+			}
+		case !authed:
+			_, _ = conn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+		case strings.EqualFold(string(cmd), "SUBSCRIBE"):
+			channel, _ := args[1].([]byte)
+			s.subs[string(channel)] = append(s.subs[string(channel)], conn)
+			_, _ = fmt.Fprintf(conn, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(channel), channel)
+		case strings.EqualFold(string(cmd), "PUBLISH"):
+			channel, _ := args[1].([]byte)
+			payload, _ := args[2].([]byte)
+			for _, sub := range s.subs[string(channel)] {
+				_, _ = fmt.Fprintf(sub, "*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(channel), channel, len(payload), payload)
+			}
+			_, _ = fmt.Fprintf(conn, ":%d\r\n", len(s.subs[string(channel)]))
+		case strings.EqualFold(string(cmd), "SET"):
+			// only SET key value NX PX ttl is supported
+			key, _ := args[1].([]byte)
+			val, _ := args[2].([]byte)
+			if _, ok := s.keys[string(key)]; ok {
+				_, _ = conn.Write([]byte("$-1\r\n"))
+			} else {
+				s.keys[string(key)] = string(val)
+				_, _ = conn.Write([]byte("+OK\r\n"))
+			}
+		case strings.EqualFold(string(cmd), "EVAL"):
+			// the only script is redisReleaseScript
+			key, _ := args[3].([]byte)
+			owner, _ := args[4].([]byte)
+			if val, ok := s.keys[string(key)]; ok && val == string(owner) {
+				delete(s.keys, string(key))
+				_, _ = conn.Write([]byte(":1\r\n"))
+			} else {
+				_, _ = conn.Write([]byte(":0\r\n"))
+			}
+		default:
+			_, _ = fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", cmd)
+		}
+		s.mutex.Unlock()
+	}
+}
+
+func (s *fakeRedisServer) subscribers(channel string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.subs[channel])
+}
+
+func TestRedisMessageBus_PublishSubscribe(t *testing.T) {
+	srv := newFakeRedisServer(t, "the-password")
+	bus := NewRedisMessageBus(srv.listener.Addr().String(), "the-password", slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub, err := bus.Subscribe(ctx, "chan-1")
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(context.Background(), "chan-1", []byte("hello\r\nworld")))
+	require.NoError(t, bus.Publish(context.Background(), "chan-2", []byte("ignored")))
+	require.NoError(t, bus.Publish(context.Background(), "chan-1", []byte{}))
+
+	assert.Equal(t, []byte("hello\r\nworld"), <-sub)
+	assert.Equal(t, []byte{}, <-sub)
+
+	cancel()
+	for range sub {
+		// drain until closed
+	}
+}
+
+func TestRedisMessageBus_ClaimRelease(t *testing.T) {
+	srv := newFakeRedisServer(t, "")
+	bus := NewRedisMessageBus(srv.listener.Addr().String(), "", slog.Default())
+	ctx := context.Background()
+
+	ok, err := bus.Claim(ctx, "key-1", "owner-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = bus.Claim(ctx, "key-1", "owner-2", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// someone else's claim is left alone
+	require.NoError(t, bus.Release(ctx, "key-1", "owner-2"))
+	ok, err = bus.Claim(ctx, "key-1", "owner-2", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, bus.Release(ctx, "key-1", "owner-1"))
+	ok, err = bus.Claim(ctx, "key-1", "owner-2", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestRedisMessageBus_WrongPassword(t *testing.T) {
+	srv := newFakeRedisServer(t, "the-password")
+	bus := NewRedisMessageBus(srv.listener.Addr().String(), "not-the-password", slog.Default())
+
+	_, err := bus.Subscribe(context.Background(), "chan-1")
+	assert.ErrorContains(t, err, "WRONGPASS")
+
+	err = bus.Publish(context.Background(), "chan-1", []byte("hello"))
+	assert.ErrorContains(t, err, "WRONGPASS")
+}
+
+func TestRedisMessageBus_Reconnect(t *testing.T) {
+	srv := newFakeRedisServer(t, "")
+	bus := NewRedisMessageBus(srv.listener.Addr().String(), "", slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub, err := bus.Subscribe(ctx, "chan-1")
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(context.Background(), "chan-1", []byte("before")))
+	assert.Equal(t, []byte("before"), <-sub)
+
+	srv.dropConns()
+
+	// the publisher finds out its connection is gone on the next publish
+	assert.Error(t, bus.Publish(context.Background(), "chan-1", []byte("lost")))
+
+	// wait for the subscriber to come back
+	assert.Eventually(t, func() bool {
+		return srv.subscribers("chan-1") == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, bus.Publish(context.Background(), "chan-1", []byte("after")))
+	assert.Equal(t, []byte("after"), <-sub)
+}
@@ -186,7 +186,21 @@ func (f SQLiteUserStore) Relationship(ctx context.Context, me IdentScreenName, t
 // a call to [SQLiteUserStore.RegisterBuddyList]. The results can be optionally
 // filtered to include only specific users by providing their identifiers in
 // the `filter` parameter.
+//
+// If the relationship cache is enabled, relationships are computed from the
+// cache instead of the database.
 func (f SQLiteUserStore) AllRelationships(ctx context.Context, me IdentScreenName, filter []IdentScreenName) ([]Relationship, error) {
+	if f.relCache != nil {
+		if relationships, ok := f.relCache.relationships(me, filter); ok {
+			return relationships, nil
+		}
+	}
+	return f.allRelationshipsSQL(ctx, me, filter)
+}
+
+// allRelationshipsSQL retrieves the relationships between me and other users
+// from the database.
+func (f SQLiteUserStore) allRelationshipsSQL(ctx context.Context, me IdentScreenName, filter []IdentScreenName) ([]Relationship, error) {
 	tpl := queryWithoutFiltering
 	args := make([]any, 1, len(filter)+1)
 	args[0] = me.String()
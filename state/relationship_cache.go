@@ -0,0 +1,492 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// RelationshipCacheStats reports the activity of the relationship cache.
+type RelationshipCacheStats struct {
+	// Enabled indicates whether relationships are served from the cache.
+	Enabled bool `json:"enabled"`
+	// Users is the number of users whose buddy lists are cached.
+	Users int `json:"users"`
+	// Lookups is the number of relationship queries served from the cache.
+	Lookups uint64 `json:"lookups"`
+	// Loads is the number of times a user's buddy lists were read from the
+	// database into the cache.
+	Loads uint64 `json:"loads"`
+	// Updates is the number of buddy list and privacy changes written
+	// through the cache.
+	Updates uint64 `json:"updates"`
+}
+
+// relFlags indicates which lists a screen name appears on.
+type relFlags struct {
+	buddy  bool
+	permit bool
+	deny   bool
+}
+
+// relItemKey identifies a feedbag item. It mirrors the primary key of the
+// feedbag table, minus the owner.
+type relItemKey struct {
+	groupID uint16
+	itemID  uint16
+}
+
+// relItem is the subset of a feedbag item that determines relationships.
+type relItem struct {
+	classID uint16
+	name    IdentScreenName
+	pdMode  wire.FeedbagPDMode
+}
+
+// relUser holds the buddy list and privacy settings of a user whose buddy
+// list is registered. It mirrors the user's rows in the buddyListMode,
+// clientSideBuddyList, and feedbag tables.
+type relUser struct {
+	useFeedbag       bool
+	clientSidePDMode wire.FeedbagPDMode
+	clientSide       map[IdentScreenName]relFlags
+	feedbag          map[relItemKey]relItem
+
+	// feedbagLists and feedbagPDMode are derived from feedbag by rebuild.
+	feedbagLists  map[IdentScreenName]relFlags
+	feedbagPDMode wire.FeedbagPDMode
+}
+
+func newRelUser() *relUser {
+	return &relUser{
+		clientSide: make(map[IdentScreenName]relFlags),
+		feedbag:    make(map[relItemKey]relItem),
+	}
+}
+
+// rebuild recomputes the buddy, permit, and deny lists and the permit/deny
+// mode stored in the user's feedbag.
+func (u *relUser) rebuild() {
+	u.feedbagLists = make(map[IdentScreenName]relFlags)
+	u.feedbagPDMode = wire.FeedbagPDModePermitAll
+	var pdItem *relItemKey
+	for key, item := range u.feedbag {
+		switch item.classID {
+		case wire.FeedbagClassIdBuddy:
+			flags := u.feedbagLists[item.name]
+			flags.buddy = true
+			u.feedbagLists[item.name] = flags
+		case wire.FeedbagClassIDPermit:
+			flags := u.feedbagLists[item.name]
+			flags.permit = true
+			u.feedbagLists[item.name] = flags
+		case wire.FeedbagClassIDDeny:
+			flags := u.feedbagLists[item.name]
+			flags.deny = true
+			u.feedbagLists[item.name] = flags
+		case wire.FeedbagClassIdPdinfo:
+			// clients keep a single PD info item. if there happen to be
+			// more, consistently pick the first one.
+			if pdItem == nil || key.groupID < pdItem.groupID ||
+				(key.groupID == pdItem.groupID && key.itemID < pdItem.itemID) {
+				pdItem = &key
+				u.feedbagPDMode = item.pdMode
+			}
+		}
+	}
+}
+
+// lists returns the user's active buddy, permit, and deny lists.
+func (u *relUser) lists() map[IdentScreenName]relFlags {
+	if u.useFeedbag {
+		return u.feedbagLists
+	}
+	return u.clientSide
+}
+
+// pdMode returns the user's active permit/deny mode.
+func (u *relUser) pdMode() wire.FeedbagPDMode {
+	if u.useFeedbag {
+		return u.feedbagPDMode
+	}
+	return u.clientSidePDMode
+}
+
+// names returns every screen name on the user's client-side and server-side
+// lists.
+func (u *relUser) names() []IdentScreenName {
+	names := make([]IdentScreenName, 0, len(u.clientSide)+len(u.feedbagLists))
+	for name := range u.clientSide {
+		names = append(names, name)
+	}
+	for name := range u.feedbagLists {
+		if _, ok := u.clientSide[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// blocks indicates whether a user with permit/deny mode pdMode blocks a user
+// that appears on their lists with flags.
+func blocks(pdMode wire.FeedbagPDMode, flags relFlags) bool {
+	switch pdMode {
+	case wire.FeedbagPDModeDenyAll:
+		return true
+	case wire.FeedbagPDModePermitSome:
+		return !flags.permit
+	case wire.FeedbagPDModeDenySome:
+		return flags.deny
+	case wire.FeedbagPDModePermitOnList:
+		return !flags.buddy
+	default:
+		return false
+	}
+}
+
+// relationshipCache is a write-through, in-memory copy of the buddy lists
+// and privacy settings of users whose buddy lists are registered. It answers
+// relationship queries the same way relationshipSQLTpl does, without
+// touching the database.
+type relationshipCache struct {
+	mutex sync.RWMutex
+	users map[IdentScreenName]*relUser
+	// listedBy maps a screen name to the registered users that have it on
+	// any of their lists.
+	listedBy map[IdentScreenName]map[IdentScreenName]struct{}
+
+	// disabled is set if the cache could not be kept consistent with the
+	// database, in which case queries fall back to the database.
+	disabled atomic.Bool
+
+	lookups atomic.Uint64
+	loads   atomic.Uint64
+	updates atomic.Uint64
+}
+
+func newRelationshipCache() *relationshipCache {
+	return &relationshipCache{
+		users:    make(map[IdentScreenName]*relUser),
+		listedBy: make(map[IdentScreenName]map[IdentScreenName]struct{}),
+	}
+}
+
+// index adds owner's list entries to the reverse index.
+func (c *relationshipCache) index(owner IdentScreenName, u *relUser) {
+	for _, name := range u.names() {
+		if c.listedBy[name] == nil {
+			c.listedBy[name] = make(map[IdentScreenName]struct{})
+		}
+		c.listedBy[name][owner] = struct{}{}
+	}
+}
+
+// unindex removes owner's list entries from the reverse index.
+func (c *relationshipCache) unindex(owner IdentScreenName, u *relUser) {
+	for _, name := range u.names() {
+		delete(c.listedBy[name], owner)
+		if len(c.listedBy[name]) == 0 {
+			delete(c.listedBy, name)
+		}
+	}
+}
+
+// put caches u as owner's buddy lists, replacing any previous entry. The
+// caller must hold the write lock.
+func (c *relationshipCache) put(owner IdentScreenName, u *relUser) {
+	c.drop(owner)
+	u.rebuild()
+	c.users[owner] = u
+	c.index(owner, u)
+}
+
+// drop removes owner from the cache. The caller must hold the write lock.
+func (c *relationshipCache) drop(owner IdentScreenName) {
+	if u, ok := c.users[owner]; ok {
+		c.unindex(owner, u)
+		delete(c.users, owner)
+	}
+}
+
+// update applies fn to owner's cached buddy lists, if owner is cached. The
+// caller must hold the write lock.
+func (c *relationshipCache) update(owner IdentScreenName, fn func(u *relUser)) {
+	u, ok := c.users[owner]
+	if !ok {
+		return
+	}
+	c.unindex(owner, u)
+	fn(u)
+	u.rebuild()
+	c.index(owner, u)
+}
+
+// relationships returns the relationships between me and other users whose
+// buddy lists are registered, optionally restricted to the users in filter.
+// It returns false if the cache is disabled.
+func (c *relationshipCache) relationships(me IdentScreenName, filter []IdentScreenName) ([]Relationship, bool) {
+	if c.disabled.Load() {
+		return nil, false
+	}
+	c.lookups.Add(1)
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	you, ok := c.users[me]
+	if !ok {
+		return nil, true
+	}
+
+	var include map[IdentScreenName]struct{}
+	if len(filter) > 0 {
+		include = make(map[IdentScreenName]struct{}, len(filter))
+		for _, sn := range filter {
+			include[sn] = struct{}{}
+		}
+	}
+
+	yourLists := you.lists()
+	var relationships []Relationship
+
+	add := func(them IdentScreenName) {
+		if include != nil {
+			if _, ok := include[them]; !ok {
+				return
+			}
+		}
+		they, ok := c.users[them]
+		if !ok {
+			return
+		}
+		yourFlags, onYourLists := yourLists[them]
+		theirFlags, onTheirLists := they.lists()[me]
+		if !onYourLists && !onTheirLists {
+			return
+		}
+		relationships = append(relationships, Relationship{
+			User:          them,
+			BlocksYou:     blocks(they.pdMode(), theirFlags),
+			YouBlock:      blocks(you.pdMode(), yourFlags),
+			IsOnTheirList: theirFlags.buddy,
+			IsOnYourList:  yourFlags.buddy,
+		})
+	}
+
+	for them := range yourLists {
+		add(them)
+	}
+	for them := range c.listedBy[me] {
+		if _, ok := yourLists[them]; ok {
+			continue // already added
+		}
+		add(them)
+	}
+
+	return relationships, true
+}
+
+// stats returns the cache's activity counters.
+func (c *relationshipCache) stats() RelationshipCacheStats {
+	c.mutex.RLock()
+	users := len(c.users)
+	c.mutex.RUnlock()
+	return RelationshipCacheStats{
+		Enabled: !c.disabled.Load(),
+		Users:   users,
+		Lookups: c.lookups.Load(),
+		Loads:   c.loads.Load(),
+		Updates: c.updates.Load(),
+	}
+}
+
+// EnableRelationshipCache makes the store answer relationship queries from an
+// in-memory copy of the buddy lists and privacy settings of users whose buddy
+// lists are registered. The copy is kept current by the store's own write
+// methods, so it must not be enabled when other processes write to the same
+// database. It must be called before the store is shared between goroutines.
+func (f *SQLiteUserStore) EnableRelationshipCache(ctx context.Context) error {
+	cache := newRelationshipCache()
+	users, err := f.loadRelUsers(ctx, "")
+	if err != nil {
+		return fmt.Errorf("loadRelUsers: %w", err)
+	}
+	for owner, u := range users {
+		cache.put(owner, u)
+	}
+	cache.loads.Add(uint64(len(users)))
+	f.relCache = cache
+	return nil
+}
+
+// RelationshipCacheStats returns the activity counters of the relationship
+// cache. Enabled is false if the cache is not enabled.
+func (f SQLiteUserStore) RelationshipCacheStats() RelationshipCacheStats {
+	if f.relCache == nil {
+		return RelationshipCacheStats{}
+	}
+	return f.relCache.stats()
+}
+
+// writeRelationships runs write, which changes the buddy lists or privacy
+// settings of owner in the database. If the relationship cache is enabled,
+// the change is mirrored by apply while holding the cache's write lock, so
+// that concurrent writers update the cache in the same order as the
+// database. If write fails, owner is reloaded from the database to undo any
+// partially applied change. An empty owner refers to all users.
+func (f SQLiteUserStore) writeRelationships(ctx context.Context, owner IdentScreenName, write func() error, apply func(c *relationshipCache) error) error {
+	if f.relCache == nil {
+		return write()
+	}
+
+	f.relCache.mutex.Lock()
+	defer f.relCache.mutex.Unlock()
+
+	f.relCache.updates.Add(1)
+
+	if err := write(); err != nil {
+		if reloadErr := f.reloadRelUsers(ctx, owner); reloadErr != nil {
+			// the cache no longer matches the database, stop using it
+			f.relCache.disabled.Store(true)
+			return fmt.Errorf("%w (unable to reload relationship cache: %w)", err, reloadErr)
+		}
+		return err
+	}
+
+	if err := apply(f.relCache); err != nil {
+		f.relCache.disabled.Store(true)
+		return fmt.Errorf("unable to update relationship cache: %w", err)
+	}
+	return nil
+}
+
+// reloadRelUsers replaces the cached buddy lists of owner with the contents
+// of the database. An empty owner reloads all users. The caller must hold the
+// cache's write lock.
+func (f SQLiteUserStore) reloadRelUsers(ctx context.Context, owner IdentScreenName) error {
+	users, err := f.loadRelUsers(ctx, owner.String())
+	if err != nil {
+		return err
+	}
+	if owner.String() == "" {
+		for sn := range f.relCache.users {
+			f.relCache.drop(sn)
+		}
+	} else {
+		f.relCache.drop(owner)
+	}
+	for sn, u := range users {
+		f.relCache.put(sn, u)
+	}
+	f.relCache.loads.Add(uint64(len(users)))
+	return nil
+}
+
+// upsertClientSide mirrors the insert or update of a clientSideBuddyList row.
+func (c *relationshipCache) upsertClientSide(me, them IdentScreenName, set func(flags *relFlags)) {
+	c.update(me, func(u *relUser) {
+		flags := u.clientSide[them]
+		set(&flags)
+		u.clientSide[them] = flags
+	})
+}
+
+// updateClientSide mirrors the update of an existing clientSideBuddyList
+// row.
+func (c *relationshipCache) updateClientSide(me, them IdentScreenName, set func(flags *relFlags)) {
+	c.update(me, func(u *relUser) {
+		if flags, ok := u.clientSide[them]; ok {
+			set(&flags)
+			u.clientSide[them] = flags
+		}
+	})
+}
+
+// loadRelUsers reads the buddy lists and privacy settings of users whose
+// buddy lists are registered. If screenName is not empty, only that user is
+// read.
+func (f SQLiteUserStore) loadRelUsers(ctx context.Context, screenName string) (map[IdentScreenName]*relUser, error) {
+	users := make(map[IdentScreenName]*relUser)
+
+	q := `
+		SELECT screenName, clientSidePDMode, useFeedbag
+		FROM buddyListMode
+		WHERE ? = '' OR screenName = ?
+	`
+	err := f.queryRows(ctx, q, []any{screenName, screenName}, func(rows *sql.Rows) error {
+		var owner string
+		u := newRelUser()
+		if err := rows.Scan(&owner, &u.clientSidePDMode, &u.useFeedbag); err != nil {
+			return err
+		}
+		users[NewIdentScreenName(owner)] = u
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error querying buddy list modes: %w", err)
+	}
+
+	q = `
+		SELECT me, them, isBuddy, isPermit, isDeny
+		FROM clientSideBuddyList
+		WHERE ? = '' OR me = ?
+	`
+	err = f.queryRows(ctx, q, []any{screenName, screenName}, func(rows *sql.Rows) error {
+		var owner, them string
+		var flags relFlags
+		if err := rows.Scan(&owner, &them, &flags.buddy, &flags.permit, &flags.deny); err != nil {
+			return err
+		}
+		if u, ok := users[NewIdentScreenName(owner)]; ok {
+			u.clientSide[NewIdentScreenName(them)] = flags
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error querying client-side buddy lists: %w", err)
+	}
+
+	q = `
+		SELECT screenName, groupID, itemID, classID, name, IFNULL(pdMode, 0)
+		FROM feedbag
+		WHERE classID IN (0, 2, 3, 4)
+		  AND (? = '' OR screenName = ?)
+	`
+	err = f.queryRows(ctx, q, []any{screenName, screenName}, func(rows *sql.Rows) error {
+		var owner, name string
+		var key relItemKey
+		var item relItem
+		if err := rows.Scan(&owner, &key.groupID, &key.itemID, &item.classID, &name, &item.pdMode); err != nil {
+			return err
+		}
+		if u, ok := users[NewIdentScreenName(owner)]; ok {
+			item.name = NewIdentScreenName(name)
+			u.feedbag[key] = item
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error querying feedbags: %w", err)
+	}
+
+	return users, nil
+}
+
+// queryRows runs a query and calls scan for each row in the result.
+func (f SQLiteUserStore) queryRows(ctx context.Context, q string, args []any, scan func(rows *sql.Rows) error) error {
+	rows, err := f.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
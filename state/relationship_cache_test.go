@@ -0,0 +1,165 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+// TestRelationshipCache_ConsistentWithSQL applies a random sequence of buddy
+// list and privacy changes and verifies after each one that the relationship
+// cache agrees with the relationship query.
+func TestRelationshipCache_ConsistentWithSQL(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	ctx := context.Background()
+	store, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+	require.NoError(t, store.EnableRelationshipCache(ctx))
+	webPD := store.NewWebPermitDenyManager()
+
+	users := []IdentScreenName{
+		NewIdentScreenName("user-a"),
+		NewIdentScreenName("user-b"),
+		NewIdentScreenName("user-c"),
+		NewIdentScreenName("user-d"),
+		NewIdentScreenName("user-e"),
+	}
+	pdModes := []wire.FeedbagPDMode{
+		wire.FeedbagPDModePermitAll,
+		wire.FeedbagPDModeDenyAll,
+		wire.FeedbagPDModePermitSome,
+		wire.FeedbagPDModeDenySome,
+		wire.FeedbagPDModePermitOnList,
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	randUser := func() IdentScreenName {
+		return users[rnd.Intn(len(users))]
+	}
+	randFeedbagItem := func() wire.FeedbagItem {
+		itemID := uint16(rnd.Intn(8))
+		switch rnd.Intn(5) {
+		case 0:
+			// the relationship query expects at most one PD info item, so
+			// always use the same item ID
+			return pdInfoItem(0, pdModes[rnd.Intn(len(pdModes))])
+		case 1:
+			return newFeedbagItem(wire.FeedbagClassIdGroup, itemID, "Buddies")
+		case 2:
+			return newFeedbagItem(wire.FeedbagClassIDPermit, itemID, randUser().String())
+		case 3:
+			return newFeedbagItem(wire.FeedbagClassIDDeny, itemID, randUser().String())
+		default:
+			// exercise screen name normalization
+			return newFeedbagItem(wire.FeedbagClassIdBuddy, itemID, fmt.Sprintf("User %s", randUser().String()[5:]))
+		}
+	}
+
+	ops := []struct {
+		name string
+		fn   func(me, them IdentScreenName) error
+	}{
+		{"RegisterBuddyList", func(me, _ IdentScreenName) error { return store.RegisterBuddyList(ctx, me) }},
+		{"UnregisterBuddyList", func(me, _ IdentScreenName) error { return store.UnregisterBuddyList(ctx, me) }},
+		{"UseFeedbag", func(me, _ IdentScreenName) error { return store.UseFeedbag(ctx, me) }},
+		{"SetPDMode", func(me, _ IdentScreenName) error {
+			return store.SetPDMode(ctx, me, pdModes[rnd.Intn(len(pdModes))])
+		}},
+		{"AddBuddy", func(me, them IdentScreenName) error { return store.AddBuddy(ctx, me, them) }},
+		{"RemoveBuddy", func(me, them IdentScreenName) error { return store.RemoveBuddy(ctx, me, them) }},
+		{"DenyBuddy", func(me, them IdentScreenName) error { return store.DenyBuddy(ctx, me, them) }},
+		{"RemoveDenyBuddy", func(me, them IdentScreenName) error { return store.RemoveDenyBuddy(ctx, me, them) }},
+		{"PermitBuddy", func(me, them IdentScreenName) error { return store.PermitBuddy(ctx, me, them) }},
+		{"RemovePermitBuddy", func(me, them IdentScreenName) error { return store.RemovePermitBuddy(ctx, me, them) }},
+		{"FeedbagUpsert", func(me, _ IdentScreenName) error {
+			return store.FeedbagUpsert(ctx, me, []wire.FeedbagItem{randFeedbagItem(), randFeedbagItem()})
+		}},
+		{"FeedbagDelete", func(me, _ IdentScreenName) error {
+			return store.FeedbagDelete(ctx, me, []wire.FeedbagItem{{ItemID: uint16(rnd.Intn(8))}})
+		}},
+		{"WebSetPDMode", func(me, _ IdentScreenName) error {
+			return webPD.SetPDMode(ctx, me, pdModes[rnd.Intn(len(pdModes))])
+		}},
+		{"WebAddPermitBuddy", func(me, them IdentScreenName) error { return webPD.AddPermitBuddy(ctx, me, them) }},
+		{"WebRemoveDenyBuddy", func(me, them IdentScreenName) error { return webPD.RemoveDenyBuddy(ctx, me, them) }},
+	}
+
+	for i := 0; i < 400; i++ {
+		me, them := randUser(), randUser()
+		op := ops[rnd.Intn(len(ops))]
+		if i%150 == 149 {
+			require.NoError(t, store.ClearBuddyListRegistry(ctx))
+		} else {
+			require.NoError(t, op.fn(me, them), op.name)
+		}
+
+		for _, user := range users {
+			filter := []IdentScreenName{randUser(), randUser()}
+			for _, filter := range [][]IdentScreenName{nil, filter} {
+				want, err := store.allRelationshipsSQL(ctx, user, filter)
+				require.NoError(t, err)
+				have, err := store.AllRelationships(ctx, user, filter)
+				require.NoError(t, err)
+				require.ElementsMatch(t, want, have, "step %d: %s(%s, %s), relationships of %s, filter %v",
+					i, op.name, me, them, user, filter)
+			}
+		}
+	}
+
+	// a cache loaded from scratch agrees with the one kept up to date
+	reloaded, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+	require.NoError(t, reloaded.EnableRelationshipCache(ctx))
+	for _, user := range users {
+		want, err := store.AllRelationships(ctx, user, nil)
+		require.NoError(t, err)
+		have, err := reloaded.AllRelationships(ctx, user, nil)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, want, have)
+	}
+}
+
+func TestSQLiteUserStore_RelationshipCacheStats(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	ctx := context.Background()
+	store, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, RelationshipCacheStats{}, store.RelationshipCacheStats())
+
+	me := NewIdentScreenName("me")
+	them := NewIdentScreenName("them")
+	require.NoError(t, store.RegisterBuddyList(ctx, me))
+
+	require.NoError(t, store.EnableRelationshipCache(ctx))
+	require.NoError(t, store.RegisterBuddyList(ctx, them))
+	require.NoError(t, store.AddBuddy(ctx, me, them))
+
+	rels, err := store.AllRelationships(ctx, me, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Relationship{{User: them, IsOnYourList: true}}, rels)
+
+	_, err = store.Relationship(ctx, them, me)
+	require.NoError(t, err)
+
+	assert.Equal(t, RelationshipCacheStats{
+		Enabled: true,
+		Users:   2,
+		Lookups: 2,
+		Loads:   2, // "me" at startup, "them" at registration
+		Updates: 2,
+	}, store.RelationshipCacheStats())
+}
@@ -2991,53 +2991,70 @@ func TestSQLiteUserStore_AllRelationships(t *testing.T) {
 		},
 	}
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			defer func() {
-				_ = os.Remove(testFile)
-			}()
+		for _, cached := range []bool{false, true} {
+			name := tt.name
+			if cached {
+				name += " (cached)"
+			}
+			t.Run(name, func(t *testing.T) {
+				defer func() {
+					_ = os.Remove(testFile)
+				}()
 
-			feedbagStore, err := NewSQLiteUserStore(testFile)
-			assert.NoError(t, err)
+				feedbagStore, err := NewSQLiteUserStore(testFile)
+				assert.NoError(t, err)
 
-			for sn, list := range tt.clientSideLists {
-				assert.NoError(t, feedbagStore.SetPDMode(context.Background(), sn, list.privacyMode))
-				for _, buddy := range list.buddyList {
-					assert.NoError(t, feedbagStore.AddBuddy(context.Background(), sn, buddy))
-				}
-				for _, buddy := range list.permitList {
-					assert.NoError(t, feedbagStore.PermitBuddy(context.Background(), sn, buddy))
-				}
-				for _, buddy := range list.denyList {
-					assert.NoError(t, feedbagStore.DenyBuddy(context.Background(), sn, buddy))
+				if cached {
+					assert.NoError(t, feedbagStore.EnableRelationshipCache(context.Background()))
 				}
-			}
 
-			for sn, list := range tt.serverSideLists {
-				assert.NoError(t, feedbagStore.UseFeedbag(context.Background(), sn))
-				itemID := uint16(1)
-				items := []wire.FeedbagItem{
-					pdInfoItem(itemID, list.privacyMode),
-				}
-				itemID++
-				for _, buddy := range list.buddyList {
-					assert.NoError(t, feedbagStore.AddBuddy(context.Background(), sn, buddy))
-					items = append(items, newFeedbagItem(wire.FeedbagClassIdBuddy, itemID, buddy.String()))
-					itemID++
+				for sn, list := range tt.clientSideLists {
+					assert.NoError(t, feedbagStore.SetPDMode(context.Background(), sn, list.privacyMode))
+					for _, buddy := range list.buddyList {
+						assert.NoError(t, feedbagStore.AddBuddy(context.Background(), sn, buddy))
+					}
+					for _, buddy := range list.permitList {
+						assert.NoError(t, feedbagStore.PermitBuddy(context.Background(), sn, buddy))
+					}
+					for _, buddy := range list.denyList {
+						assert.NoError(t, feedbagStore.DenyBuddy(context.Background(), sn, buddy))
+					}
 				}
-				for _, buddy := range list.permitList {
-					items = append(items, newFeedbagItem(wire.FeedbagClassIDPermit, itemID, buddy.String()))
-					itemID++
-				}
-				for _, buddy := range list.denyList {
-					items = append(items, newFeedbagItem(wire.FeedbagClassIDDeny, itemID, buddy.String()))
+
+				for sn, list := range tt.serverSideLists {
+					assert.NoError(t, feedbagStore.UseFeedbag(context.Background(), sn))
+					itemID := uint16(1)
+					items := []wire.FeedbagItem{
+						pdInfoItem(itemID, list.privacyMode),
+					}
 					itemID++
+					for _, buddy := range list.buddyList {
+						assert.NoError(t, feedbagStore.AddBuddy(context.Background(), sn, buddy))
+						items = append(items, newFeedbagItem(wire.FeedbagClassIdBuddy, itemID, buddy.String()))
+						itemID++
+					}
+					for _, buddy := range list.permitList {
+						items = append(items, newFeedbagItem(wire.FeedbagClassIDPermit, itemID, buddy.String()))
+						itemID++
+					}
+					for _, buddy := range list.denyList {
+						items = append(items, newFeedbagItem(wire.FeedbagClassIDDeny, itemID, buddy.String()))
+						itemID++
+					}
+					assert.NoError(t, feedbagStore.FeedbagUpsert(context.Background(), sn, items))
 				}
-				assert.NoError(t, feedbagStore.FeedbagUpsert(context.Background(), sn, items))
-			}
 
-			have, err := feedbagStore.AllRelationships(context.Background(), tt.me, tt.filter)
-			assert.NoError(t, err)
-			assert.ElementsMatch(t, tt.expect, have)
-		})
+				have, err := feedbagStore.AllRelationships(context.Background(), tt.me, tt.filter)
+				assert.NoError(t, err)
+				assert.ElementsMatch(t, tt.expect, have)
+
+				if cached {
+					// make sure the cache agrees with the database
+					fromSQL, err := feedbagStore.allRelationshipsSQL(context.Background(), tt.me, tt.filter)
+					assert.NoError(t, err)
+					assert.ElementsMatch(t, fromSQL, have)
+				}
+			})
+		}
 	}
 }
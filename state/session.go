@@ -44,6 +44,10 @@ const (
 	// SessQueueFull indicates send failed due to full queue -- client is likely
 	// dead
 	SessQueueFull
+	// SessMessageDropped indicates the message was discarded because the
+	// queue is full and the overflow policy doesn't call for disconnecting
+	// the client
+	SessMessageDropped
 )
 
 // Session represents a user's current session. Unless stated otherwise, all
@@ -64,6 +68,12 @@ type Session struct {
 	multiConnFlag           wire.MultiConnFlag
 	mutex                   sync.RWMutex
 	nowFn                   func() time.Time
+	outboundQueue           outboundQueue
+	overflowPolicy          OverflowPolicy
+	owner                   *remoteOwner
+	pumping                 bool
+	queueMutex              sync.Mutex
+	queueSize               int
 	rateLimitStates         [5]RateClassState
 	rateLimitStatesOriginal [5]RateClassState
 	remoteAddr              *netip.AddrPort
@@ -80,11 +90,12 @@ type Session struct {
 }
 
 // NewSession returns a new instance of Session. By default, the user may have
-// up to 1000 pending messages before blocking.
+// up to DefaultOutboundQueueSize pending messages, after which buddy presence
+// updates are dropped to make room.
 func NewSession() *Session {
 	now := time.Now()
 	return &Session{
-		msgCh:             make(chan wire.SNACMessage, 1000),
+		msgCh:             make(chan wire.SNACMessage, deliveryBufferSize),
 		nowFn:             time.Now,
 		stopCh:            make(chan struct{}),
 		signonTime:        now,
@@ -124,6 +135,10 @@ func NewSession() *Session {
 }
 
 func (s *Session) SetRateClasses(now time.Time, classes wire.RateLimitClasses) {
+	if !s.forward(clusterUpdate{Op: clusterOpSetRateClasses, RateClasses: classes.All()}) {
+		return
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -164,6 +179,10 @@ func (s *Session) RemoteAddr() (remoteAddr *netip.AddrPort) {
 
 // SetUserInfoFlag sets a flag to and returns UserInfoBitmask
 func (s *Session) SetUserInfoFlag(flag uint16) (flags uint16) {
+	if !s.forward(clusterUpdate{Op: clusterOpSetUserInfoFlag, Flag: flag}) {
+		return s.UserInfoBitmask()
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.userInfoBitmask |= flag
@@ -172,6 +191,10 @@ func (s *Session) SetUserInfoFlag(flag uint16) (flags uint16) {
 
 // ClearUserInfoFlag clear a flag from and returns UserInfoBitmask
 func (s *Session) ClearUserInfoFlag(flag uint16) (flags uint16) {
+	if !s.forward(clusterUpdate{Op: clusterOpClearUserInfoFlag, Flag: flag}) {
+		return s.UserInfoBitmask()
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.userInfoBitmask &^= flag
@@ -187,11 +210,17 @@ func (s *Session) UserInfoBitmask() (flags uint16) {
 
 // RateLimitStates returns the current session rate limits
 func (s *Session) RateLimitStates() [5]RateClassState {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.rateLimitStates
 }
 
 // SetUserStatusBitmask sets the user status bitmask from the client.
 func (s *Session) SetUserStatusBitmask(bitmask uint32) {
+	if !s.forward(clusterUpdate{Op: clusterOpSetUserStatus, StatusBitmask: bitmask}) {
+		return
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.userStatusBitmask = bitmask
@@ -209,6 +238,18 @@ func (s *Session) UserStatusBitmask() uint32 {
 // which rate limit class to scale. The incr param is a percentage represented as an integer
 // where 30 = 3.0%, 100 = 10.0%, etc.
 func (s *Session) ScaleWarningAndRateLimit(incr int16, classID wire.RateLimitClassID) (bool, uint16) {
+	if s.owner != nil {
+		// the owning node scales the rate limit and notifies the user
+		res, err := s.owner.update(clusterUpdate{Op: clusterOpWarn, Incr: incr, ClassID: classID})
+		if err != nil || !res.OK {
+			return false, 0
+		}
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.warning = res.Warning
+		return true, res.Warning
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -262,6 +303,10 @@ func (s *Session) ScaleWarningAndRateLimit(incr int16, classID wire.RateLimitCla
 
 // SetWarning sets the user's last warning level.
 func (s *Session) SetWarning(warning uint16) {
+	if !s.forward(clusterUpdate{Op: clusterOpSetWarning, Warning: warning}) {
+		return
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.warning = warning
@@ -348,6 +393,10 @@ func (s *Session) IdleTime() time.Time {
 
 // SetIdle sets the user's idle state.
 func (s *Session) SetIdle(dur time.Duration) {
+	if !s.forward(clusterUpdate{Op: clusterOpSetIdle, Idle: dur}) {
+		return
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.idle = true
@@ -357,6 +406,10 @@ func (s *Session) SetIdle(dur time.Duration) {
 
 // UnsetIdle removes the user's idle state.
 func (s *Session) UnsetIdle() {
+	if !s.forward(clusterUpdate{Op: clusterOpUnsetIdle}) {
+		return
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.idle = false
@@ -364,6 +417,10 @@ func (s *Session) UnsetIdle() {
 
 // SetAwayMessage sets the user's away message.
 func (s *Session) SetAwayMessage(awayMessage string) {
+	if !s.forward(clusterUpdate{Op: clusterOpSetAwayMessage, AwayMessage: awayMessage}) {
+		return
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.awayMessage = awayMessage
@@ -495,27 +552,100 @@ func (s *Session) ReceiveMessage() chan wire.SNACMessage {
 // asynchronously to the consumer of this session's messages. It returns
 // SessSendStatus to indicate whether the message was successfully sent or
 // not. This method is non-blocking.
+//
+// If the consumer falls behind, messages wait in the session's outbound
+// queue, where instant messages, chat messages and other traffic are sent
+// ahead of buddy presence updates, and a buddy presence update replaces an
+// earlier one for the same buddy that hasn't been sent yet. Once the queue
+// is full, the overflow policy decides whether the message is dropped
+// (SessMessageDropped) or the client should be disconnected
+// (SessQueueFull).
 func (s *Session) RelayMessage(msg wire.SNACMessage) SessSendStatus {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 	if s.closed {
 		return SessSendClosed
 	}
-	select {
-	case s.msgCh <- msg:
-		return SessSendOK
-	case <-s.stopCh:
-		return SessSendClosed
-	default:
-		return SessQueueFull
+
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+
+	if !s.pumping {
+		// nothing is waiting, try to hand the message straight to the
+		// consumer
+		select {
+		case s.msgCh <- msg:
+			return SessSendOK
+		case <-s.stopCh:
+			return SessSendClosed
+		default:
+		}
+	}
+
+	size := s.queueSize
+	if size <= 0 {
+		size = DefaultOutboundQueueSize
+	}
+	status := s.outboundQueue.push(msg, size, s.overflowPolicy)
+	if status == SessSendOK && !s.pumping {
+		s.pumping = true
+		go s.pump()
+	}
+	return status
+}
+
+// pump feeds the consumer from the outbound queue until the queue is empty
+// or the session closes.
+func (s *Session) pump() {
+	for {
+		s.queueMutex.Lock()
+		msg, ok := s.outboundQueue.pop()
+		if !ok {
+			s.pumping = false
+			s.queueMutex.Unlock()
+			return
+		}
+		s.queueMutex.Unlock()
+
+		select {
+		case s.msgCh <- msg:
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// SetOutboundQueue sets the number of messages that can wait to be sent to
+// the client and what happens when more arrive. A size of 0 or less selects
+// DefaultOutboundQueueSize and an empty policy selects OverflowDropPresence.
+func (s *Session) SetOutboundQueue(size int, policy OverflowPolicy) {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+	s.queueSize = size
+	s.overflowPolicy = policy
+}
+
+// OutboundQueueStats returns the state of the session's outbound queue.
+func (s *Session) OutboundQueueStats() OutboundQueueStats {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+	return OutboundQueueStats{
+		Queued:    s.outboundQueue.len(),
+		Dropped:   s.outboundQueue.dropped,
+		Coalesced: s.outboundQueue.coalesced,
 	}
 }
 
 // Close shuts down the session's ability to relay messages. Once invoked,
 // RelayMessage returns SessQueueFull and Closed returns a closed channel.
 // It is not possible to re-open message relaying once closed. It is safe to
-// call from multiple go routines.
+// call from multiple go routines. Closing a mirror of a session owned by
+// another node signs off the session on that node.
 func (s *Session) Close() {
+	if !s.forward(clusterUpdate{Op: clusterOpClose}) {
+		return
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.close()
@@ -529,6 +659,18 @@ func (s *Session) close() {
 	s.closed = true
 }
 
+// forward sends a change made to a mirror of a session owned by another
+// node to the owning node. It returns false if the owner didn't make the
+// change, in which case it must not be made to the mirror either. It returns
+// true for sessions owned by this node.
+func (s *Session) forward(u clusterUpdate) bool {
+	if s.owner == nil {
+		return true
+	}
+	res, err := s.owner.update(u)
+	return err == nil && res.OK
+}
+
 // Closed blocks until the session is closed.
 func (s *Session) Closed() <-chan struct{} {
 	return s.stopCh
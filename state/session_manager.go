@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/maphash"
 	"log/slog"
 	"sync"
 	"time"
@@ -11,56 +12,145 @@ import (
 	"github.com/mk6i/retro-aim-server/wire"
 )
 
+// SessionManager manages the BOS sessions of signed-on users and relays
+// messages between them.
+type SessionManager interface {
+	AddSession(ctx context.Context, screenName DisplayScreenName, multiSess bool) (*Session, error)
+	AllSessions() []*Session
+	RelayToAll(ctx context.Context, msg wire.SNACMessage)
+	RelayToOtherInstances(ctx context.Context, sess *Session, msg wire.SNACMessage)
+	RelayToScreenName(ctx context.Context, screenName IdentScreenName, msg wire.SNACMessage)
+	RelayToScreenNames(ctx context.Context, screenNames []IdentScreenName, msg wire.SNACMessage)
+	RemoveSession(sess *Session)
+	RetrieveSession(screenName IdentScreenName) *Session
+	RetrieveSessions(screenName IdentScreenName) []*Session
+	SignoffSession(sess *Session, cleanup func(lastInstance bool))
+}
+
+// ChatSessionManager manages the sessions of chat room participants and
+// relays messages between them.
+type ChatSessionManager interface {
+	AddSession(ctx context.Context, chatCookie string, screenName DisplayScreenName) (*Session, error)
+	AllSessions(cookie string) []*Session
+	RelayToAllExcept(ctx context.Context, cookie string, except IdentScreenName, msg wire.SNACMessage)
+	RelayToScreenName(ctx context.Context, cookie string, recipient IdentScreenName, msg wire.SNACMessage)
+	RemoveSession(sess *Session)
+	RemoveUserFromAllChats(user IdentScreenName)
+}
+
 type sessionSlot struct {
-	sess    *Session
-	removed chan bool
+	sess      *Session
+	multiSess bool
+	removed   chan bool
+	// leaving indicates that the session is signing off and waiting for
+	// SignoffSession's cleanup to finish before it's removed.
+	leaving bool
+}
+
+// active indicates whether the slot's session is signed on and not in the
+// middle of signing off.
+func (r *sessionSlot) active() bool {
+	return r.sess.SignonComplete() && !r.leaving
+}
+
+// sessionShardCount is the number of shards the session pool is split into.
+// Each shard is guarded by its own lock so that sign-ons, sign-offs and
+// message relays for different users don't contend with each other.
+const sessionShardCount = 64
+
+// sessionShard holds the sessions of the users whose screen names hash to
+// the shard.
+type sessionShard struct {
+	mutex sync.RWMutex
+	store map[IdentScreenName][]*sessionSlot
 }
 
 var errSessConflict = errors.New("session conflict: another session was created concurrently for this user")
 
 // InMemorySessionManager handles the lifecycle of a user session and provides
-// synchronized message relay between sessions in the session pool. An
+// synchronized message relay between sessions in the session pool. A user may
+// be signed on from multiple clients at once, in which case each client has
+// its own session, referred to as an instance. Sessions are keyed by screen
+// name and spread across independently locked shards. An
 // InMemorySessionManager is safe for concurrent use by multiple goroutines.
 type InMemorySessionManager struct {
-	store    map[IdentScreenName]*sessionSlot
-	mapMutex sync.RWMutex
-	logger   *slog.Logger
+	logger *slog.Logger
+	seed   maphash.Seed
+	shards [sessionShardCount]sessionShard
 }
 
 // NewInMemorySessionManager creates a new instance of InMemorySessionManager.
 func NewInMemorySessionManager(logger *slog.Logger) *InMemorySessionManager {
 	return &InMemorySessionManager{
 		logger: logger,
-		store:  make(map[IdentScreenName]*sessionSlot),
+		seed:   maphash.MakeSeed(),
 	}
 }
 
+// shard returns the shard that holds the sessions of screenName.
+func (s *InMemorySessionManager) shard(screenName IdentScreenName) *sessionShard {
+	return &s.shards[maphash.String(s.seed, screenName.String())%sessionShardCount]
+}
+
 // RelayToAll relays a message to all sessions in the session pool.
 func (s *InMemorySessionManager) RelayToAll(ctx context.Context, msg wire.SNACMessage) {
-	s.mapMutex.RLock()
-	defer s.mapMutex.RUnlock()
-	for _, rec := range s.store {
-		if !rec.sess.SignonComplete() {
-			continue
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mutex.RLock()
+		for _, recs := range shard.store {
+			for _, rec := range recs {
+				if !rec.active() {
+					continue
+				}
+				s.maybeRelayMessage(ctx, msg, rec.sess)
+			}
 		}
-		s.maybeRelayMessage(ctx, msg, rec.sess)
+		shard.mutex.RUnlock()
 	}
 }
 
-// RelayToScreenName relays a message to a session with a matching screen name.
+// RelayToScreenName relays a message to every instance of a screen name.
 func (s *InMemorySessionManager) RelayToScreenName(ctx context.Context, screenName IdentScreenName, msg wire.SNACMessage) {
-	sess := s.RetrieveSession(screenName)
-	if sess == nil {
+	if s.relayToScreenName(ctx, screenName, msg) == 0 {
 		s.logger.WarnContext(ctx, "can't send notification because user is not online", "recipient", screenName, "message", msg)
-		return
 	}
-	s.maybeRelayMessage(ctx, msg, sess)
 }
 
-// RelayToScreenNames relays a message to sessions with matching screenNames.
+// RelayToScreenNames relays a message to every instance of the given screen
+// names.
 func (s *InMemorySessionManager) RelayToScreenNames(ctx context.Context, screenNames []IdentScreenName, msg wire.SNACMessage) {
-	for _, sess := range s.retrieveByScreenNames(screenNames) {
-		s.maybeRelayMessage(ctx, msg, sess)
+	for _, screenName := range screenNames {
+		s.relayToScreenName(ctx, screenName, msg)
+	}
+}
+
+// relayToScreenName relays a message to every signed-on instance of a screen
+// name and returns the number of instances it was relayed to. The shard lock
+// is held only for the duration of the lookup and the non-blocking sends.
+func (s *InMemorySessionManager) relayToScreenName(ctx context.Context, screenName IdentScreenName, msg wire.SNACMessage) int {
+	shard := s.shard(screenName)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+	count := 0
+	for _, rec := range shard.store[screenName] {
+		if !rec.active() {
+			continue
+		}
+		s.maybeRelayMessage(ctx, msg, rec.sess)
+		count++
+	}
+	return count
+}
+
+// RelayToOtherInstances relays a message to every instance of sess's screen
+// name except sess itself. It's used to keep a user's clients in sync when
+// they're signed on from several places.
+func (s *InMemorySessionManager) RelayToOtherInstances(ctx context.Context, sess *Session, msg wire.SNACMessage) {
+	for _, other := range s.RetrieveSessions(sess.IdentScreenName()) {
+		if other == sess {
+			continue
+		}
+		s.maybeRelayMessage(ctx, msg, other)
 	}
 }
 
@@ -71,117 +161,209 @@ func (s *InMemorySessionManager) maybeRelayMessage(ctx context.Context, msg wire
 	case SessQueueFull:
 		s.logger.WarnContext(ctx, "can't send notification because queue is full", "recipient", sess.IdentScreenName(), "message", msg)
 		sess.Close()
+	case SessMessageDropped:
+		s.logger.DebugContext(ctx, "dropped notification because queue is full", "recipient", sess.IdentScreenName(), "message", msg)
 	}
 }
 
-func (s *InMemorySessionManager) AddSession(ctx context.Context, screenName DisplayScreenName) (*Session, error) {
-	s.mapMutex.Lock()
-
-	active := s.findRec(screenName.IdentScreenName())
-	if active != nil {
-		// there's an active session that needs to be removed. don't hold the
+// AddSession adds a new session for screenName to the pool. multiSess
+// indicates whether the client can share the screen name with other signed-on
+// clients. Existing instances are kicked and removed before the new session
+// is added, unless both they and the new session allow multiple sessions.
+func (s *InMemorySessionManager) AddSession(ctx context.Context, screenName DisplayScreenName, multiSess bool) (*Session, error) {
+	identScreenName := screenName.IdentScreenName()
+	shard := s.shard(identScreenName)
+	shard.mutex.Lock()
+
+	conflicts := shard.conflictingRecs(identScreenName, multiSess)
+	if len(conflicts) > 0 {
+		// there are active sessions that need to be removed. don't hold the
 		// lock while we wait.
-		s.mapMutex.Unlock()
+		shard.mutex.Unlock()
 
-		// signal to callers that this session has to go
-		active.sess.Close()
+		// signal to callers that these sessions have to go
+		for _, rec := range conflicts {
+			rec.sess.Close()
+		}
 
-		select {
-		case <-active.removed: // wait for RemoveSession to be called
-		case <-ctx.Done():
-			return nil, fmt.Errorf("waiting for previous session to terminate: %w", ctx.Err())
+		for _, rec := range conflicts {
+			select {
+			case <-rec.removed: // wait for RemoveSession to be called
+			case <-ctx.Done():
+				return nil, fmt.Errorf("waiting for previous session to terminate: %w", ctx.Err())
+			}
 		}
 
-		// the session has been removed, let's try to replace it
-		s.mapMutex.Lock()
+		// the sessions have been removed, let's try to add the new one
+		shard.mutex.Lock()
 	}
 
-	defer s.mapMutex.Unlock()
+	defer shard.mutex.Unlock()
 
-	// make sure a concurrent call didn't already add a session
-	if active != nil && s.findRec(screenName.IdentScreenName()) != nil {
+	// make sure a concurrent call didn't already add a conflicting session
+	if len(conflicts) > 0 && len(shard.conflictingRecs(identScreenName, multiSess)) > 0 {
 		return nil, errSessConflict
 	}
 
 	sess := NewSession()
-	sess.SetIdentScreenName(screenName.IdentScreenName())
+	sess.SetIdentScreenName(identScreenName)
 	sess.SetDisplayScreenName(screenName)
 
-	s.store[sess.IdentScreenName()] = &sessionSlot{
-		sess:    sess,
-		removed: make(chan bool),
+	if shard.store == nil {
+		shard.store = make(map[IdentScreenName][]*sessionSlot)
 	}
+	shard.store[identScreenName] = append(shard.store[identScreenName], &sessionSlot{
+		sess:      sess,
+		multiSess: multiSess,
+		removed:   make(chan bool),
+	})
 
 	return sess, nil
 }
 
-func (s *InMemorySessionManager) findRec(identScreenName IdentScreenName) *sessionSlot {
-	for _, rec := range s.store {
-		if identScreenName == rec.sess.IdentScreenName() {
-			return rec
+// conflictingRecs returns the instances of identScreenName that can't stay
+// signed on alongside a new session. Instances that are signing off always
+// conflict, so that a new session doesn't start before their cleanup is
+// done. The caller must hold the shard's mutex.
+func (s *sessionShard) conflictingRecs(identScreenName IdentScreenName, multiSess bool) []*sessionSlot {
+	var conflicts []*sessionSlot
+	for _, rec := range s.store[identScreenName] {
+		if !multiSess || !rec.multiSess || rec.leaving {
+			conflicts = append(conflicts, rec)
+		}
+	}
+	return conflicts
+}
+
+// kick signs off the instances of identScreenName that conflict with a new
+// session added elsewhere and waits for them to be removed.
+func (s *InMemorySessionManager) kick(ctx context.Context, identScreenName IdentScreenName, multiSess bool) error {
+	shard := s.shard(identScreenName)
+	shard.mutex.RLock()
+	conflicts := shard.conflictingRecs(identScreenName, multiSess)
+	shard.mutex.RUnlock()
+	for _, rec := range conflicts {
+		rec.sess.Close()
+	}
+	for _, rec := range conflicts {
+		select {
+		case <-rec.removed:
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for session to terminate: %w", ctx.Err())
 		}
 	}
 	return nil
 }
 
-// RemoveSession takes a session out of the session pool.
+// RemoveSession takes a session out of the session pool. The user's other
+// instances, if any, are unaffected.
 func (s *InMemorySessionManager) RemoveSession(sess *Session) {
-	s.mapMutex.Lock()
-	defer s.mapMutex.Unlock()
-	if rec, ok := s.store[sess.IdentScreenName()]; ok && rec.sess == sess {
-		delete(s.store, sess.IdentScreenName())
+	shard := s.shard(sess.IdentScreenName())
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	recs := shard.store[sess.IdentScreenName()]
+	for i, rec := range recs {
+		if rec.sess != sess {
+			continue
+		}
+		recs = append(recs[:i:i], recs[i+1:]...)
+		if len(recs) == 0 {
+			delete(shard.store, sess.IdentScreenName())
+		} else {
+			shard.store[sess.IdentScreenName()] = recs
+		}
 		close(rec.removed)
+		return
 	}
 }
 
-// RetrieveSession finds a session with a matching sessionID. Returns nil if
-// session is not found.
+// SignoffSession takes sess out of the session pool after calling cleanup,
+// which is told whether sess is the user's last instance. The decision is
+// atomic: of several instances that sign off at once, exactly one is told
+// that it's the last. While cleanup runs, sess is no longer returned as a
+// signed-on instance, and new sessions for the user wait for it to be
+// removed. This lets cleanup release state shared by the user's instances,
+// such as their buddy list registration, without racing a new sign-on.
+func (s *InMemorySessionManager) SignoffSession(sess *Session, cleanup func(lastInstance bool)) {
+	shard := s.shard(sess.IdentScreenName())
+	shard.mutex.Lock()
+	lastInstance := true
+	for _, rec := range shard.store[sess.IdentScreenName()] {
+		switch {
+		case rec.sess == sess:
+			rec.leaving = true
+		case !rec.leaving:
+			lastInstance = false
+		}
+	}
+	shard.mutex.Unlock()
+
+	cleanup(lastInstance)
+	s.RemoveSession(sess)
+}
+
+// RetrieveSession finds a session with a matching screen name. If the user
+// is signed on from several places, it returns the most recent instance.
+// Returns nil if session is not found.
 func (s *InMemorySessionManager) RetrieveSession(screenName IdentScreenName) *Session {
-	s.mapMutex.RLock()
-	defer s.mapMutex.RUnlock()
-	if rec, ok := s.store[screenName]; ok {
-		if !rec.sess.SignonComplete() {
-			return nil
+	shard := s.shard(screenName)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+	recs := shard.store[screenName]
+	for i := len(recs) - 1; i >= 0; i-- {
+		if recs[i].active() {
+			return recs[i].sess
 		}
-		return rec.sess
 	}
 	return nil
 }
 
-func (s *InMemorySessionManager) retrieveByScreenNames(screenNames []IdentScreenName) []*Session {
-	s.mapMutex.RLock()
-	defer s.mapMutex.RUnlock()
+// RetrieveSessions returns every signed-on instance of a screen name, oldest
+// first. Returns nil if the user is not online.
+func (s *InMemorySessionManager) RetrieveSessions(screenName IdentScreenName) []*Session {
+	shard := s.shard(screenName)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
 	var ret []*Session
-	for _, sn := range screenNames {
-		for _, rec := range s.store {
-			if !rec.sess.SignonComplete() {
-				continue
-			}
-			if sn == rec.sess.IdentScreenName() {
-				ret = append(ret, rec.sess)
-			}
+	for _, rec := range shard.store[screenName] {
+		if !rec.active() {
+			continue
 		}
+		ret = append(ret, rec.sess)
 	}
 	return ret
 }
 
 // Empty returns true if the session pool contains 0 sessions.
 func (s *InMemorySessionManager) Empty() bool {
-	s.mapMutex.RLock()
-	defer s.mapMutex.RUnlock()
-	return len(s.store) == 0
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mutex.RLock()
+		n := len(shard.store)
+		shard.mutex.RUnlock()
+		if n > 0 {
+			return false
+		}
+	}
+	return true
 }
 
-// AllSessions returns all sessions in the session pool.
+// AllSessions returns all sessions in the session pool, including every
+// instance of users signed on from several places.
 func (s *InMemorySessionManager) AllSessions() []*Session {
-	s.mapMutex.RLock()
-	defer s.mapMutex.RUnlock()
 	var sessions []*Session
-	for _, rec := range s.store {
-		if !rec.sess.SignonComplete() {
-			continue
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mutex.RLock()
+		for _, recs := range shard.store {
+			for _, rec := range recs {
+				if !rec.active() {
+					continue
+				}
+				sessions = append(sessions, rec.sess)
+			}
 		}
-		sessions = append(sessions, rec.sess)
+		shard.mutex.RUnlock()
 	}
 	return sessions
 }
@@ -217,7 +399,7 @@ func (s *InMemoryChatSessionManager) AddSession(ctx context.Context, chatCookie
 	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	sess, err := sessionManager.AddSession(ctx, screenName)
+	sess, err := sessionManager.AddSession(ctx, screenName, false)
 	if err != nil {
 		return nil, fmt.Errorf("AddSession: %w", err)
 	}
@@ -2,9 +2,12 @@ package state
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/mk6i/retro-aim-server/wire"
 
@@ -15,7 +18,7 @@ func TestInMemorySessionManager_AddSession(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
 	ctx := context.Background()
-	sess1, err := sm.AddSession(ctx, "user-screen-name")
+	sess1, err := sm.AddSession(ctx, "user-screen-name", false)
 	assert.NoError(t, err)
 	sess1.SetSignonComplete()
 
@@ -24,7 +27,7 @@ func TestInMemorySessionManager_AddSession(t *testing.T) {
 		sm.RemoveSession(sess1)
 	}()
 
-	sess2, err := sm.AddSession(ctx, "user-screen-name")
+	sess2, err := sm.AddSession(ctx, "user-screen-name", false)
 	assert.NoError(t, err)
 	sess2.SetSignonComplete()
 
@@ -36,7 +39,7 @@ func TestInMemorySessionManager_AddSession_Timeout(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
 	ctx, cancel := context.WithCancel(context.Background())
-	sess1, err := sm.AddSession(ctx, "user-screen-name")
+	sess1, err := sm.AddSession(ctx, "user-screen-name", false)
 	assert.NoError(t, err)
 	sess1.SetSignonComplete()
 
@@ -45,7 +48,7 @@ func TestInMemorySessionManager_AddSession_Timeout(t *testing.T) {
 		cancel()
 	}()
 
-	sess2, err := sm.AddSession(ctx, "user-screen-name")
+	sess2, err := sm.AddSession(ctx, "user-screen-name", false)
 	assert.Nil(t, sess2)
 	assert.ErrorIs(t, err, context.Canceled)
 }
@@ -54,35 +57,196 @@ func TestInMemorySessionManager_AddSession_SessionConflict(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
 	ctx := context.Background()
-	sess1, err := sm.AddSession(ctx, "user-screen-name")
+	sess1, err := sm.AddSession(ctx, "user-screen-name", false)
 	assert.NoError(t, err)
 	sess1.SetSignonComplete()
 
 	go func() {
 		<-sess1.Closed()
-		rec, ok := sm.store[NewIdentScreenName("user-screen-name")]
+		recs, ok := sm.shard(NewIdentScreenName("user-screen-name")).store[NewIdentScreenName("user-screen-name")]
 		if assert.True(t, ok) {
-			close(rec.removed)
+			close(recs[0].removed)
 		}
 	}()
 
-	sess2, err := sm.AddSession(ctx, "user-screen-name")
+	sess2, err := sm.AddSession(ctx, "user-screen-name", false)
 	assert.Nil(t, sess2)
 	assert.ErrorIs(t, err, errSessConflict)
 }
 
+func TestInMemorySessionManager_AddSession_MultiSession(t *testing.T) {
+	sm := NewInMemorySessionManager(slog.Default())
+
+	ctx := context.Background()
+	sess1, err := sm.AddSession(ctx, "user-screen-name", true)
+	assert.NoError(t, err)
+	sess1.SetSignonComplete()
+
+	sess2, err := sm.AddSession(ctx, "user-screen-name", true)
+	assert.NoError(t, err)
+	sess2.SetSignonComplete()
+
+	// both instances stay signed on
+	select {
+	case <-sess1.Closed():
+		t.Fatal("first instance should not have been kicked")
+	default:
+	}
+	assert.Equal(t, []*Session{sess1, sess2}, sm.RetrieveSessions(NewIdentScreenName("user-screen-name")))
+	assert.Same(t, sess2, sm.RetrieveSession(NewIdentScreenName("user-screen-name")))
+
+	// removing one instance leaves the other in place
+	sm.RemoveSession(sess2)
+	assert.Equal(t, []*Session{sess1}, sm.RetrieveSessions(NewIdentScreenName("user-screen-name")))
+	assert.Same(t, sess1, sm.RetrieveSession(NewIdentScreenName("user-screen-name")))
+
+	sm.RemoveSession(sess1)
+	assert.True(t, sm.Empty())
+}
+
+func TestInMemorySessionManager_AddSession_MultiSessionConflict(t *testing.T) {
+	tests := []struct {
+		name          string
+		existingMulti bool
+		newMulti      bool
+	}{
+		{
+			name:          "multi-session client kicks single-session client",
+			existingMulti: false,
+			newMulti:      true,
+		},
+		{
+			name:          "single-session client kicks multi-session client",
+			existingMulti: true,
+			newMulti:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewInMemorySessionManager(slog.Default())
+
+			ctx := context.Background()
+			sess1, err := sm.AddSession(ctx, "user-screen-name", tt.existingMulti)
+			assert.NoError(t, err)
+			sess1.SetSignonComplete()
+
+			go func() {
+				<-sess1.Closed()
+				sm.RemoveSession(sess1)
+			}()
+
+			sess2, err := sm.AddSession(ctx, "user-screen-name", tt.newMulti)
+			assert.NoError(t, err)
+			sess2.SetSignonComplete()
+
+			assert.Equal(t, []*Session{sess2}, sm.RetrieveSessions(NewIdentScreenName("user-screen-name")))
+		})
+	}
+}
+
+func TestInMemorySessionManager_SignoffSession(t *testing.T) {
+	t.Run("exactly one concurrent instance is the last", func(t *testing.T) {
+		sm := NewInMemorySessionManager(slog.Default())
+
+		sess1, err := sm.AddSession(context.Background(), "user-screen-name", true)
+		assert.NoError(t, err)
+		sess1.SetSignonComplete()
+		sess2, err := sm.AddSession(context.Background(), "user-screen-name", true)
+		assert.NoError(t, err)
+		sess2.SetSignonComplete()
+
+		var wg sync.WaitGroup
+		var lastCount atomic.Int32
+		for _, sess := range []*Session{sess1, sess2} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sm.SignoffSession(sess, func(lastInstance bool) {
+					if lastInstance {
+						lastCount.Add(1)
+					}
+				})
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), lastCount.Load())
+		assert.True(t, sm.Empty())
+	})
+
+	t.Run("signing-off instance is hidden during cleanup", func(t *testing.T) {
+		sm := NewInMemorySessionManager(slog.Default())
+
+		sess1, err := sm.AddSession(context.Background(), "user-screen-name", true)
+		assert.NoError(t, err)
+		sess1.SetSignonComplete()
+		sess2, err := sm.AddSession(context.Background(), "user-screen-name", true)
+		assert.NoError(t, err)
+		sess2.SetSignonComplete()
+
+		sm.SignoffSession(sess2, func(lastInstance bool) {
+			assert.False(t, lastInstance)
+			assert.Equal(t, []*Session{sess1}, sm.RetrieveSessions(NewIdentScreenName("user-screen-name")))
+			assert.Same(t, sess1, sm.RetrieveSession(NewIdentScreenName("user-screen-name")))
+			assert.Equal(t, []*Session{sess1}, sm.AllSessions())
+		})
+		assert.Equal(t, []*Session{sess1}, sm.RetrieveSessions(NewIdentScreenName("user-screen-name")))
+
+		sm.SignoffSession(sess1, func(lastInstance bool) {
+			assert.True(t, lastInstance)
+		})
+		assert.True(t, sm.Empty())
+	})
+
+	t.Run("new session waits for cleanup to finish", func(t *testing.T) {
+		sm := NewInMemorySessionManager(slog.Default())
+
+		sess1, err := sm.AddSession(context.Background(), "user-screen-name", true)
+		assert.NoError(t, err)
+		sess1.SetSignonComplete()
+
+		inCleanup := make(chan struct{})
+		finishCleanup := make(chan struct{})
+		go sm.SignoffSession(sess1, func(lastInstance bool) {
+			assert.True(t, lastInstance)
+			close(inCleanup)
+			<-finishCleanup
+		})
+		<-inCleanup
+
+		added := make(chan *Session)
+		go func() {
+			sess2, err := sm.AddSession(context.Background(), "user-screen-name", true)
+			assert.NoError(t, err)
+			added <- sess2
+		}()
+
+		select {
+		case <-added:
+			t.Fatal("new session was added before cleanup finished")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(finishCleanup)
+		sess2 := <-added
+		sess2.SetSignonComplete()
+		assert.Equal(t, []*Session{sess2}, sm.RetrieveSessions(NewIdentScreenName("user-screen-name")))
+	})
+}
+
 func TestInMemorySessionManager_Remove_Existing(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1Old, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1Old, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	sm.RemoveSession(user1Old)
 
-	user1New, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1New, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1New.SetSignonComplete()
 
-	user2, err := sm.AddSession(context.Background(), "user-screen-name-2")
+	user2, err := sm.AddSession(context.Background(), "user-screen-name-2", false)
 	assert.NoError(t, err)
 	user2.SetSignonComplete()
 
@@ -98,15 +262,15 @@ func TestInMemorySessionManager_Remove_Existing(t *testing.T) {
 func TestInMemorySessionManager_Remove_MissingSameScreenName(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1Old, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1Old, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	sm.RemoveSession(user1Old)
 
-	user1New, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1New, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1New.SetSignonComplete()
 
-	user2, err := sm.AddSession(context.Background(), "user-screen-name-2")
+	user2, err := sm.AddSession(context.Background(), "user-screen-name-2", false)
 	assert.NoError(t, err)
 	user2.SetSignonComplete()
 
@@ -143,7 +307,7 @@ func TestInMemorySessionManager_Empty(t *testing.T) {
 			sm := NewInMemorySessionManager(slog.Default())
 
 			for _, screenName := range tt.given {
-				sess, err := sm.AddSession(context.Background(), screenName)
+				sess, err := sm.AddSession(context.Background(), screenName, false)
 				assert.NoError(t, err)
 				sess.SetSignonComplete()
 			}
@@ -182,7 +346,7 @@ func TestInMemorySessionManager_Retrieve(t *testing.T) {
 			sm := NewInMemorySessionManager(slog.Default())
 
 			for _, screenName := range tt.given {
-				sess, err := sm.AddSession(context.Background(), screenName)
+				sess, err := sm.AddSession(context.Background(), screenName, false)
 				assert.NoError(t, err)
 				sess.SetSignonComplete()
 			}
@@ -200,13 +364,13 @@ func TestInMemorySessionManager_Retrieve(t *testing.T) {
 func TestInMemorySessionManager_RelayToScreenNames(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1.SetSignonComplete()
-	user2, err := sm.AddSession(context.Background(), "user-screen-name-2")
+	user2, err := sm.AddSession(context.Background(), "user-screen-name-2", false)
 	assert.NoError(t, err)
 	user2.SetSignonComplete()
-	user3, err := sm.AddSession(context.Background(), "user-screen-name-3")
+	user3, err := sm.AddSession(context.Background(), "user-screen-name-3", false)
 	assert.NoError(t, err)
 	user3.SetSignonComplete()
 
@@ -238,10 +402,10 @@ func TestInMemorySessionManager_RelayToScreenNames(t *testing.T) {
 func TestInMemorySessionManager_Broadcast(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1.SetSignonComplete()
-	user2, err := sm.AddSession(context.Background(), "user-screen-name-2")
+	user2, err := sm.AddSession(context.Background(), "user-screen-name-2", false)
 	assert.NoError(t, err)
 	user2.SetSignonComplete()
 
@@ -263,10 +427,10 @@ func TestInMemorySessionManager_Broadcast(t *testing.T) {
 func TestInMemorySessionManager_Broadcast_SkipClosedSession(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1.SetSignonComplete()
-	user2, err := sm.AddSession(context.Background(), "user-screen-name-2")
+	user2, err := sm.AddSession(context.Background(), "user-screen-name-2", false)
 	assert.NoError(t, err)
 	user2.SetSignonComplete()
 	user2.Close()
@@ -290,10 +454,10 @@ func TestInMemorySessionManager_Broadcast_SkipClosedSession(t *testing.T) {
 func TestInMemorySessionManager_RelayToScreenName_SessionExists(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1.SetSignonComplete()
-	user2, err := sm.AddSession(context.Background(), "user-screen-name-2")
+	user2, err := sm.AddSession(context.Background(), "user-screen-name-2", false)
 	assert.NoError(t, err)
 	user2.SetSignonComplete()
 
@@ -317,7 +481,7 @@ func TestInMemorySessionManager_RelayToScreenName_SessionExists(t *testing.T) {
 func TestInMemorySessionManager_RelayToScreenName_SessionNotExist(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1.SetSignonComplete()
 
@@ -336,34 +500,115 @@ func TestInMemorySessionManager_RelayToScreenName_SessionNotExist(t *testing.T)
 func TestInMemorySessionManager_RelayToScreenName_SkipFullSession(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1.SetSignonComplete()
 	msg := wire.SNACMessage{Frame: wire.SNACFrame{FoodGroup: wire.ICBM}}
 
+	for user1.RelayMessage(msg) != SessQueueFull {
+	}
+
+	recip := NewIdentScreenName("user-screen-name-1")
+	sm.RelayToScreenName(context.Background(), recip, msg)
+
+	// the unresponsive client gets disconnected
+	select {
+	case <-user1.Closed():
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected session to be closed")
+	}
+}
+
+func TestInMemorySessionManager_RelayToScreenName_DropWhenFull(t *testing.T) {
+	sm := NewInMemorySessionManager(slog.Default())
+
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
+	assert.NoError(t, err)
+	user1.SetSignonComplete()
+	user1.SetOutboundQueue(10, OverflowDropNewest)
+	msg := wire.SNACMessage{Frame: wire.SNACFrame{FoodGroup: wire.ICBM}}
+
 	wantCount := 0
-	for {
-		if user1.RelayMessage(msg) == SessQueueFull {
-			break
-		}
+	for user1.RelayMessage(msg) == SessSendOK {
 		wantCount++
 	}
 
 	recip := NewIdentScreenName("user-screen-name-1")
 	sm.RelayToScreenName(context.Background(), recip, msg)
 
+	// queued messages are delivered asynchronously, so wait a moment for
+	// each one
 	haveCount := 0
 loop:
 	for {
 		select {
 		case <-user1.ReceiveMessage():
 			haveCount++
-		default:
+		case <-time.After(100 * time.Millisecond):
 			break loop
 		}
 	}
 
 	assert.Equal(t, wantCount, haveCount)
+	assert.Equal(t, uint64(2), user1.OutboundQueueStats().Dropped)
+	select {
+	case <-user1.Closed():
+		assert.Fail(t, "session should stay open")
+	default:
+	}
+}
+
+func TestInMemorySessionManager_RelayToScreenName_MultiSession(t *testing.T) {
+	sm := NewInMemorySessionManager(slog.Default())
+
+	inst1, err := sm.AddSession(context.Background(), "user-screen-name-1", true)
+	assert.NoError(t, err)
+	inst1.SetSignonComplete()
+	inst2, err := sm.AddSession(context.Background(), "user-screen-name-1", true)
+	assert.NoError(t, err)
+	inst2.SetSignonComplete()
+
+	want := wire.SNACMessage{Frame: wire.SNACFrame{FoodGroup: wire.ICBM}}
+	sm.RelayToScreenName(context.Background(), NewIdentScreenName("user-screen-name-1"), want)
+
+	select {
+	case have := <-inst1.ReceiveMessage():
+		assert.Equal(t, want, have)
+	default:
+		t.Fatal("expected message for first instance")
+	}
+	select {
+	case have := <-inst2.ReceiveMessage():
+		assert.Equal(t, want, have)
+	default:
+		t.Fatal("expected message for second instance")
+	}
+}
+
+func TestInMemorySessionManager_RelayToOtherInstances(t *testing.T) {
+	sm := NewInMemorySessionManager(slog.Default())
+
+	inst1, err := sm.AddSession(context.Background(), "user-screen-name-1", true)
+	assert.NoError(t, err)
+	inst1.SetSignonComplete()
+	inst2, err := sm.AddSession(context.Background(), "user-screen-name-1", true)
+	assert.NoError(t, err)
+	inst2.SetSignonComplete()
+
+	want := wire.SNACMessage{Frame: wire.SNACFrame{FoodGroup: wire.Feedbag}}
+	sm.RelayToOtherInstances(context.Background(), inst1, want)
+
+	select {
+	case <-inst1.ReceiveMessage():
+		t.Fatal("did not expect message for originating instance")
+	default:
+	}
+	select {
+	case have := <-inst2.ReceiveMessage():
+		assert.Equal(t, want, have)
+	default:
+		t.Fatal("expected message for other instance")
+	}
 }
 
 func TestInMemoryChatSessionManager_RelayToAllExcept_HappyPath(t *testing.T) {
@@ -529,11 +774,11 @@ func TestInMemoryChatSessionManager_RemoveUserFromAllChats(t *testing.T) {
 func TestInMemorySessionManager_RelayToAll_SkipIncompleteSignon(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1.SetSignonComplete()
 
-	user2, err := sm.AddSession(context.Background(), "user-screen-name-2")
+	user2, err := sm.AddSession(context.Background(), "user-screen-name-2", false)
 	assert.NoError(t, err)
 	// user2 has not completed signon
 
@@ -556,7 +801,7 @@ func TestInMemorySessionManager_RelayToAll_SkipIncompleteSignon(t *testing.T) {
 func TestInMemorySessionManager_RetrieveSession_IncompleteSignon(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	// user1 has not completed signon
 
@@ -572,7 +817,7 @@ func TestInMemorySessionManager_RetrieveSession_IncompleteSignon(t *testing.T) {
 func TestInMemorySessionManager_RetrieveSession_CompleteSignon(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1.SetSignonComplete()
 
@@ -584,15 +829,15 @@ func TestInMemorySessionManager_RetrieveSession_CompleteSignon(t *testing.T) {
 func TestInMemorySessionManager_RelayToScreenNames_SkipIncompleteSignon(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1.SetSignonComplete()
 
-	user2, err := sm.AddSession(context.Background(), "user-screen-name-2")
+	user2, err := sm.AddSession(context.Background(), "user-screen-name-2", false)
 	assert.NoError(t, err)
 	// user2 has not completed signon
 
-	user3, err := sm.AddSession(context.Background(), "user-screen-name-3")
+	user3, err := sm.AddSession(context.Background(), "user-screen-name-3", false)
 	assert.NoError(t, err)
 	user3.SetSignonComplete()
 
@@ -625,15 +870,15 @@ func TestInMemorySessionManager_RelayToScreenNames_SkipIncompleteSignon(t *testi
 func TestInMemorySessionManager_AllSessions_SkipIncompleteSignon(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	user1.SetSignonComplete()
 
-	user2, err := sm.AddSession(context.Background(), "user-screen-name-2")
+	user2, err := sm.AddSession(context.Background(), "user-screen-name-2", false)
 	assert.NoError(t, err)
 	// user2 has not completed signon
 
-	user3, err := sm.AddSession(context.Background(), "user-screen-name-3")
+	user3, err := sm.AddSession(context.Background(), "user-screen-name-3", false)
 	assert.NoError(t, err)
 	user3.SetSignonComplete()
 
@@ -653,7 +898,7 @@ func TestInMemorySessionManager_AllSessions_SkipIncompleteSignon(t *testing.T) {
 func TestInMemorySessionManager_RelayToScreenName_IncompleteSignon(t *testing.T) {
 	sm := NewInMemorySessionManager(slog.Default())
 
-	user1, err := sm.AddSession(context.Background(), "user-screen-name-1")
+	user1, err := sm.AddSession(context.Background(), "user-screen-name-1", false)
 	assert.NoError(t, err)
 	// user1 has not completed signon
 
@@ -668,3 +913,115 @@ func TestInMemorySessionManager_RelayToScreenName_IncompleteSignon(t *testing.T)
 	default:
 	}
 }
+
+// newBenchmarkSessionManager creates a session manager with userCount
+// signed-on users whose message queues are drained in the background for the
+// duration of the benchmark.
+func newBenchmarkSessionManager(b *testing.B, userCount int) (*InMemorySessionManager, []IdentScreenName) {
+	sm := NewInMemorySessionManager(slog.New(slog.DiscardHandler))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	b.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	screenNames := make([]IdentScreenName, userCount)
+	for i := range screenNames {
+		sess, err := sm.AddSession(ctx, DisplayScreenName(fmt.Sprintf("user-%d", i)), false)
+		if err != nil {
+			b.Fatal(err)
+		}
+		sess.SetSignonComplete()
+		screenNames[i] = sess.IdentScreenName()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-sess.ReceiveMessage():
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	return sm, screenNames
+}
+
+// BenchmarkInMemorySessionManager_RelayToScreenNames simulates buddy arrival
+// fan-out, where each signed-on user notifies the 100 users that have them on
+// their buddy list.
+func BenchmarkInMemorySessionManager_RelayToScreenNames(b *testing.B) {
+	for _, userCount := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("users=%d", userCount), func(b *testing.B) {
+			sm, screenNames := newBenchmarkSessionManager(b, userCount)
+			msg := wire.SNACMessage{Frame: wire.SNACFrame{FoodGroup: wire.Buddy, SubGroup: wire.BuddyArrived}}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					start := i % len(screenNames)
+					end := min(start+100, len(screenNames))
+					sm.RelayToScreenNames(context.Background(), screenNames[start:end], msg)
+					i += 100
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkInMemorySessionManager_RetrieveSession measures concurrent
+// session lookups by screen name.
+func BenchmarkInMemorySessionManager_RetrieveSession(b *testing.B) {
+	sm, screenNames := newBenchmarkSessionManager(b, 5000)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if sm.RetrieveSession(screenNames[i%len(screenNames)]) == nil {
+				b.Error("session not found")
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkInMemorySessionManager_RelayWithChurn measures buddy fan-out
+// while other users sign on and off. Every tenth operation is a sign-on
+// followed by a sign-off.
+func BenchmarkInMemorySessionManager_RelayWithChurn(b *testing.B) {
+	sm, screenNames := newBenchmarkSessionManager(b, 5000)
+	msg := wire.SNACMessage{Frame: wire.SNACFrame{FoodGroup: wire.Buddy, SubGroup: wire.BuddyArrived}}
+
+	var worker atomic.Int32
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		// give each goroutine its own churning screen names so that sign-ons
+		// never wait on each other
+		id := worker.Add(1)
+		i := 0
+		for pb.Next() {
+			if i%10 == 0 {
+				sess, err := sm.AddSession(context.Background(), DisplayScreenName(fmt.Sprintf("churn-%d-%d", id, i%1000)), false)
+				if err != nil {
+					b.Error(err)
+					return
+				}
+				sess.SetSignonComplete()
+				sm.RemoveSession(sess)
+			} else {
+				start := (i * 100) % len(screenNames)
+				end := min(start+100, len(screenNames))
+				sm.RelayToScreenNames(context.Background(), screenNames[start:end], msg)
+			}
+			i++
+		}
+	})
+}
@@ -342,10 +342,54 @@ func TestSession_SendMessage_SessQueueFull(t *testing.T) {
 		msgCh:  make(chan wire.SNACMessage, bufSize),
 		stopCh: make(chan struct{}),
 	}
+	defer s.Close()
+	s.SetOutboundQueue(5, OverflowDisconnect)
+
 	for i := 0; i < bufSize; i++ {
-		assert.Equal(t, SessSendOK, s.RelayMessage(wire.SNACMessage{}))
+		assert.Equal(t, SessSendOK, s.RelayMessage(imMsg(uint64(i))))
+	}
+
+	// the first queued message is taken by the pump, which waits for room
+	// in the delivery buffer
+	assert.Equal(t, SessSendOK, s.RelayMessage(imMsg(10)))
+	assert.Eventually(t, func() bool {
+		return s.OutboundQueueStats().Queued == 0
+	}, time.Second, time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, SessSendOK, s.RelayMessage(imMsg(uint64(11+i))))
+	}
+	assert.Equal(t, SessQueueFull, s.RelayMessage(imMsg(16)))
+	assert.Equal(t, OutboundQueueStats{Queued: 5}, s.OutboundQueueStats())
+}
+
+func TestSession_SendMessage_SessMessageDropped(t *testing.T) {
+	s := Session{
+		msgCh:  make(chan wire.SNACMessage, 1),
+		stopCh: make(chan struct{}),
+	}
+	defer s.Close()
+	s.SetOutboundQueue(1, OverflowDropNewest)
+
+	assert.Equal(t, SessSendOK, s.RelayMessage(imMsg(1)))
+	assert.Equal(t, SessSendOK, s.RelayMessage(imMsg(2)))
+	assert.Eventually(t, func() bool {
+		return s.OutboundQueueStats().Queued == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, SessSendOK, s.RelayMessage(imMsg(3)))
+	assert.Equal(t, SessMessageDropped, s.RelayMessage(imMsg(4)))
+	assert.Equal(t, OutboundQueueStats{Queued: 1, Dropped: 1}, s.OutboundQueueStats())
+
+	// everything that wasn't dropped is delivered in order
+	for _, want := range []uint64{1, 2, 3} {
+		select {
+		case msg := <-s.ReceiveMessage():
+			assert.Equal(t, imMsg(want), msg)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for message %d", want)
+		}
 	}
-	assert.Equal(t, SessQueueFull, s.RelayMessage(wire.SNACMessage{}))
+	assert.Equal(t, OutboundQueueStats{Dropped: 1}, s.OutboundQueueStats())
 }
 
 func TestSession_Close_Twice(t *testing.T) {
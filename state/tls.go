@@ -0,0 +1,103 @@
+package state
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// NewTLSConfig returns a TLS server configuration that presents the
+// PEM-encoded certificate chain and private key at certFile and keyFile.
+// The files are reloaded when they change, so that a renewed certificate
+// takes effect without a restart. If legacyCiphers is true, the
+// configuration also accepts TLS 1.0 and 1.1 and the older cipher suites
+// that AIM 6 clients support.
+func NewTLSConfig(certFile, keyFile string, legacyCiphers bool) (*tls.Config, error) {
+	r := &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		GetCertificate: r.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+	if legacyCiphers {
+		cfg.MinVersion = tls.VersionTLS10
+		for _, suite := range tls.CipherSuites() {
+			cfg.CipherSuites = append(cfg.CipherSuites, suite.ID)
+		}
+		for _, suite := range tls.InsecureCipherSuites() {
+			cfg.CipherSuites = append(cfg.CipherSuites, suite.ID)
+		}
+	}
+	return cfg, nil
+}
+
+// certificateReloader serves a certificate loaded from disk and reloads it
+// when the certificate or key file is modified.
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mutex       sync.Mutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+// GetCertificate returns the certificate, reloading it first if either
+// file changed since it was last loaded. If the reload fails, the
+// previously loaded certificate is returned.
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	certModTime, keyModTime, err := r.modTimes()
+	if err == nil && (!certModTime.Equal(r.certModTime) || !keyModTime.Equal(r.keyModTime)) {
+		// a failed reload, for example because only one of the files has
+		// been replaced so far, is retried on the next handshake
+		_ = r.loadLocked()
+	}
+	return r.cert, nil
+}
+
+// load loads the certificate and key.
+func (r *certificateReloader) load() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.loadLocked()
+}
+
+func (r *certificateReloader) loadLocked() error {
+	certModTime, keyModTime, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("unable to load TLS certificate %s: %w", r.certFile, err)
+	}
+	r.cert = &cert
+	r.certModTime = certModTime
+	r.keyModTime = keyModTime
+	return nil
+}
+
+// modTimes returns the modification times of the certificate and key files.
+func (r *certificateReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("unable to read TLS certificate: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("unable to read TLS key: %w", err)
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
@@ -0,0 +1,87 @@
+package state
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate writes a self-signed certificate for commonName and
+// its key to certFile and keyFile.
+func writeTestCertificate(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	commonName := func(cfg *tls.Config) string {
+		cert, err := cfg.GetCertificate(&tls.ClientHelloInfo{})
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return leaf.Subject.CommonName
+	}
+
+	t.Run("missing files", func(t *testing.T) {
+		_, err := NewTLSConfig(certFile, keyFile, false)
+		assert.Error(t, err)
+	})
+
+	t.Run("reload changed certificate", func(t *testing.T) {
+		writeTestCertificate(t, certFile, keyFile, "old.example.com")
+		cfg, err := NewTLSConfig(certFile, keyFile, false)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+		assert.Equal(t, "old.example.com", commonName(cfg))
+
+		writeTestCertificate(t, certFile, keyFile, "new.example.com")
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(certFile, later, later))
+		require.NoError(t, os.Chtimes(keyFile, later, later))
+		assert.Equal(t, "new.example.com", commonName(cfg))
+
+		// keep serving the loaded certificate if the new one is invalid
+		require.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0600))
+		assert.Equal(t, "new.example.com", commonName(cfg))
+	})
+
+	t.Run("legacy ciphers", func(t *testing.T) {
+		writeTestCertificate(t, certFile, keyFile, "aim.example.com")
+		cfg, err := NewTLSConfig(certFile, keyFile, true)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS10), cfg.MinVersion)
+		assert.Contains(t, cfg.CipherSuites, tls.TLS_RSA_WITH_AES_128_CBC_SHA)
+		assert.Contains(t, cfg.CipherSuites, tls.TLS_RSA_WITH_RC4_128_SHA)
+	})
+}
@@ -0,0 +1,161 @@
+package state
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA1 shared secret from the RFC 6238 test vectors.
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).
+	EncodeToString([]byte("12345678901234567890"))
+
+func TestValidateTOTPCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		now      time.Time
+		want     bool
+		wantStep int64
+	}{
+		{
+			name:     "RFC 6238 vector at 59",
+			code:     "287082",
+			now:      time.Unix(59, 0),
+			want:     true,
+			wantStep: 1,
+		},
+		{
+			name:     "RFC 6238 vector at 1111111109",
+			code:     "081804",
+			now:      time.Unix(1111111109, 0),
+			want:     true,
+			wantStep: 1111111109 / 30,
+		},
+		{
+			name:     "RFC 6238 vector at 1234567890",
+			code:     "005924",
+			now:      time.Unix(1234567890, 0),
+			want:     true,
+			wantStep: 1234567890 / 30,
+		},
+		{
+			name:     "code from previous period is accepted",
+			code:     "005924",
+			now:      time.Unix(1234567890+30, 0),
+			want:     true,
+			wantStep: 1234567890 / 30,
+		},
+		{
+			name: "code from 2 periods ago is rejected",
+			code: "005924",
+			now:  time.Unix(1234567890+60, 0),
+			want: false,
+		},
+		{
+			name: "wrong code",
+			code: "123456",
+			now:  time.Unix(1234567890, 0),
+			want: false,
+		},
+		{
+			name: "code with wrong length",
+			code: "5924",
+			now:  time.Unix(1234567890, 0),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTPCode(rfc6238Secret, tt.code, tt.now)
+			assert.Equal(t, tt.want, ok)
+			assert.Equal(t, tt.wantStep, step)
+		})
+	}
+}
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	require.NoError(t, err)
+
+	key, err := totpEncoding.DecodeString(secret)
+	require.NoError(t, err)
+	assert.Len(t, key, totpSecretLen)
+}
+
+func TestNewTOTPRecoveryCodes(t *testing.T) {
+	codes, err := NewTOTPRecoveryCodes()
+	require.NoError(t, err)
+	assert.Len(t, codes, TOTPRecoveryCodeCount)
+
+	for _, code := range codes {
+		assert.Len(t, code, TOTPRecoveryCodeLen)
+		assert.Empty(t, strings.Trim(code, recoveryCodeAlphabet))
+	}
+}
+
+func TestTOTPKeyURI(t *testing.T) {
+	have := TOTPKeyURI("Joe Smith", "ABCDEF")
+	assert.Equal(t, "otpauth://totp/Retro%20AIM%20Server:Joe%20Smith?issuer=Retro+AIM+Server&secret=ABCDEF", have)
+}
+
+func TestUser_ValidatePlaintextPassWithCode(t *testing.T) {
+	user := User{TOTPSecret: rfc6238Secret}
+	require.NoError(t, user.HashPassword("thepassword"))
+
+	tests := []struct {
+		name         string
+		pass         string
+		wantOK       bool
+		wantPassword string
+		wantCode     string
+	}{
+		{
+			name:         "password with TOTP code",
+			pass:         "thepassword123456",
+			wantOK:       true,
+			wantPassword: "thepassword",
+			wantCode:     "123456",
+		},
+		{
+			name:         "password with recovery code",
+			pass:         "thepasswordabcdefghij",
+			wantOK:       true,
+			wantPassword: "thepassword",
+			wantCode:     "abcdefghij",
+		},
+		{
+			name:         "password without code",
+			pass:         "thepassword",
+			wantOK:       true,
+			wantPassword: "thepassword",
+			wantCode:     "",
+		},
+		{
+			name:   "wrong password with code",
+			pass:   "notthepassword123456",
+			wantOK: false,
+		},
+		{
+			name:   "short password",
+			pass:   "123",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, password, code := user.ValidatePlaintextPassWithCode([]byte(tt.pass))
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.wantPassword, string(password))
+				assert.Equal(t, tt.wantCode, code)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"unicode"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 
 	"github.com/mk6i/retro-aim-server/wire"
 )
@@ -21,8 +22,23 @@ var (
 	ErrNoUser = errors.New("user does not exist")
 	// ErrNoEmail indicates that a user has not set an email address.
 	ErrNoEmailAddress = errors.New("user has no email address")
+	// ErrBadCredentials indicates that a password did not match the stored
+	// password hash.
+	ErrBadCredentials = errors.New("invalid credentials")
+	// ErrUserSuspended indicates that a user account is suspended and may
+	// not log in.
+	ErrUserSuspended = errors.New("user account is suspended")
+	// ErrBadSecurID indicates that a user enrolled in two-factor auth
+	// supplied a missing or invalid one-time code.
+	ErrBadSecurID = errors.New("invalid SecurID code")
+	// ErrUINRangeExhausted indicates that every UIN in the registration
+	// range has been assigned.
+	ErrUINRangeExhausted = errors.New("no UINs left in registration range")
 )
 
+// PasswordHashCost is the bcrypt cost factor used to hash passwords.
+var PasswordHashCost = bcrypt.DefaultCost
+
 // IdentScreenName struct stores the normalized version of a user's screen name.
 // This format is used for uniformity in storage and comparison by removing spaces
 // and converting all characters to lowercase.
@@ -150,6 +166,34 @@ func NewStubUser(screenName DisplayScreenName) (User, error) {
 	return u, err
 }
 
+// NewExternalUser creates a new user for an account that exists in an
+// external AuthProvider. The password verified by the provider is cached in
+// the user's password hashes.
+func NewExternalUser(screenName DisplayScreenName, pass []byte) (User, error) {
+	var err error
+	if screenName.IsUIN() {
+		err = screenName.ValidateUIN()
+	} else {
+		err = screenName.ValidateAIMHandle()
+	}
+	if err != nil {
+		return User{}, err
+	}
+
+	uid, err := uuid.NewRandom()
+	if err != nil {
+		return User{}, err
+	}
+	u := User{
+		IdentScreenName:   NewIdentScreenName(string(screenName)),
+		DisplayScreenName: screenName,
+		AuthKey:           uid.String(),
+		IsICQ:             screenName.IsUIN(),
+	}
+	err = u.HashExternalPassword(pass)
+	return u, err
+}
+
 // User represents a user account.
 type User struct {
 	// IdentScreenName is the AIM screen name.
@@ -163,6 +207,14 @@ type User struct {
 	// WeakMD5Pass is the MD5 password hash format used by AIM v3.5-v4.7. This
 	// hash is used to authenticate roasted passwords for AIM v1.0-v3.0.
 	WeakMD5Pass []byte
+	// PasswordHash is a salted bcrypt hash of the password. When set, it
+	// supersedes the MD5 hashes for every login method that reveals the
+	// plaintext password. The MD5 hashes are retained for BUCP auth, whose
+	// clients only ever send an MD5 digest.
+	PasswordHash string
+	// TOTPSecret is the base32-encoded shared secret used to generate TOTP
+	// codes. It's empty if the user is not enrolled in two-factor auth.
+	TOTPSecret string
 	// IsICQ indicates whether the user is an ICQ account (true) or an AIM
 	// account (false).
 	IsICQ bool
@@ -202,6 +254,9 @@ type User struct {
 	TOCConfig string
 	// IsBot indicates whether the user is a bot.
 	IsBot bool
+	// RateLimitOverrides are rate limit classes that replace the server's
+	// classes with the same ID for this user.
+	RateLimitOverrides []wire.RateClass
 	// LastWarnUpdate is the timestamp when the user's warning level was last updated.
 	LastWarnUpdate time.Time
 	// LastWarnLevel is the warning level when the user last signed off.
@@ -406,53 +461,82 @@ func (u *User) ValidateHash(md5Hash []byte) bool {
 
 // ValidateRoastedPass validates roasted passwords for FLAP auth.
 func (u *User) ValidateRoastedPass(roastedPass []byte) bool {
-	clearPass := wire.RoastOSCARPassword(roastedPass)
-	md5Hash := wire.WeakMD5PasswordHash(string(clearPass), u.AuthKey)
-	return bytes.Equal(u.WeakMD5Pass, md5Hash)
+	return u.ValidatePlaintextPass(wire.RoastOSCARPassword(roastedPass))
 }
 
 // ValidateRoastedJavaPass validates roasted passwords for the Java AIM client FLAP auth.
 func (u *User) ValidateRoastedJavaPass(roastedPass []byte) bool {
-	clearPass := wire.RoastOSCARJavaPassword(roastedPass)
-	md5Hash := wire.WeakMD5PasswordHash(string(clearPass), u.AuthKey)
-	return bytes.Equal(u.WeakMD5Pass, md5Hash)
+	return u.ValidatePlaintextPass(wire.RoastOSCARJavaPassword(roastedPass))
 }
 
 // ValidateRoastedTOCPass validates roasted passwords for TOC auth.
 func (u *User) ValidateRoastedTOCPass(roastedPass []byte) bool {
-	clearPass := wire.RoastTOCPassword(roastedPass)
-	md5Hash := wire.WeakMD5PasswordHash(string(clearPass), u.AuthKey)
-	return bytes.Equal(u.WeakMD5Pass, md5Hash)
+	return u.ValidatePlaintextPass(wire.RoastTOCPassword(roastedPass))
 }
 
-// ValidatePlaintextPass validates plaintext passwords used in Kerberos auth.
+// ValidatePlaintextPass validates plaintext passwords used in Kerberos and
+// WebAPI auth. The bcrypt hash is checked if present, otherwise it falls back
+// to the legacy MD5 hash.
 func (u *User) ValidatePlaintextPass(plaintextPass []byte) bool {
+	if !u.LegacyHashOnly() {
+		return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), plaintextPass) == nil
+	}
 	md5Hash := wire.WeakMD5PasswordHash(string(plaintextPass), u.AuthKey)
 	return bytes.Equal(u.WeakMD5Pass, md5Hash)
 }
 
 // ValidateRoastedKerberosPass validates roasted passwords used in Kerberos auth.
 func (u *User) ValidateRoastedKerberosPass(roastedPass []byte) bool {
-	clearPass := wire.RoastKerberosPassword(roastedPass)
-	md5Hash := wire.WeakMD5PasswordHash(string(clearPass), u.AuthKey)
-	return bytes.Equal(u.WeakMD5Pass, md5Hash)
+	return u.ValidatePlaintextPass(wire.RoastKerberosPassword(roastedPass))
+}
+
+// LegacyHashOnly indicates whether the user's password is only stored as
+// MD5 hashes, which is the case for accounts created before bcrypt hashes
+// were introduced that have not logged in with a plaintext-revealing auth
+// method since.
+func (u *User) LegacyHashOnly() bool {
+	return u.PasswordHash == ""
+}
+
+// UpgradePasswordHash computes the bcrypt hash of a password that has already
+// been validated against the legacy MD5 hashes.
+func (u *User) UpgradePasswordHash(plaintextPass []byte) error {
+	hash, err := bcrypt.GenerateFromPassword(plaintextPass, PasswordHashCost)
+	if err != nil {
+		return fmt.Errorf("unable to compute password hash: %w", err)
+	}
+	u.PasswordHash = string(hash)
+	return nil
 }
 
-// HashPassword computes MD5 hashes of the user's password. It computes both
-// weak and strong variants and stores them in the struct.
+// HashPassword computes the hashes of the user's password. It computes the
+// bcrypt hash as well as both weak and strong MD5 variants and stores them in
+// the struct.
 func (u *User) HashPassword(passwd string) error {
+	if err := u.ValidatePassword(passwd); err != nil {
+		return err
+	}
+	return u.HashExternalPassword([]byte(passwd))
+}
+
+// ValidatePassword returns ErrPasswordInvalid if passwd doesn't meet the
+// password rules for the user's account type.
+func (u *User) ValidatePassword(passwd string) error {
 	if u.IsICQ {
-		if err := validateICQPassword(passwd); err != nil {
-			return err
-		}
-	} else {
-		if err := validateAIMPassword(passwd); err != nil {
-			return err
-		}
+		return validateICQPassword(passwd)
 	}
-	u.WeakMD5Pass = wire.WeakMD5PasswordHash(passwd, u.AuthKey)
-	u.StrongMD5Pass = wire.StrongMD5PasswordHash(passwd, u.AuthKey)
-	return nil
+	return validateAIMPassword(passwd)
+}
+
+// HashExternalPassword computes the hashes of a password that was verified
+// by an external AuthProvider. Unlike HashPassword, it doesn't enforce the
+// AIM and ICQ password rules, since the provider owns the password policy.
+// The cached hashes aren't used while the provider is configured, but let
+// the account keep working with its latest password if it's turned off.
+func (u *User) HashExternalPassword(pass []byte) error {
+	u.WeakMD5Pass = wire.WeakMD5PasswordHash(string(pass), u.AuthKey)
+	u.StrongMD5Pass = wire.StrongMD5PasswordHash(string(pass), u.AuthKey)
+	return u.UpgradePasswordHash(pass)
 }
 
 // validateAIMPassword returns an error if the AIM password is invalid.
@@ -477,6 +561,8 @@ func validateICQPassword(pass string) error {
 }
 
 type OfflineMessage struct {
+	// ID identifies the queued message. It's set by RetrieveMessages.
+	ID        int64
 	Sender    IdentScreenName
 	Recipient IdentScreenName
 	Message   wire.SNAC_0x04_0x06_ICBMChannelMsgToHost
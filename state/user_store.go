@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"embed"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -33,6 +34,7 @@ var (
 	ErrKeywordExists           = errors.New("keyword already exists")
 	ErrKeywordInUse            = errors.New("can't delete keyword that is associated with a user")
 	ErrKeywordNotFound         = errors.New("keyword not found")
+	ErrOfflineQueueFull        = errors.New("offline message queue is full")
 	errTooManyCategories       = errors.New("there are too many keyword categories")
 	errTooManyKeywords         = errors.New("there are too many keywords")
 )
@@ -43,7 +45,8 @@ var migrations embed.FS
 // SQLiteUserStore stores user feedbag (buddy list), profile, and
 // authentication credentials information in a SQLite database.
 type SQLiteUserStore struct {
-	db *sql.DB
+	db       *sql.DB
+	relCache *relationshipCache
 }
 
 // NewSQLiteUserStore creates a new instance of SQLiteUserStore. If the
@@ -342,6 +345,8 @@ func (f SQLiteUserStore) queryUsers(ctx context.Context, whereClause string, que
 			authKey,
 			strongMD5Pass,
 			weakMD5Pass,
+			passwordHash,
+			totpSecret,
 			confirmStatus,
 			regStatus,
 			suspendedStatus,
@@ -414,6 +419,7 @@ func (f SQLiteUserStore) queryUsers(ctx context.Context, whereClause string, que
 			aim_zipCode,
 			aim_address,
 			tocConfig,
+			rateLimitOverrides,
 			lastWarnUpdate,
 			lastWarnLevel
 		FROM users
@@ -431,6 +437,7 @@ func (f SQLiteUserStore) queryUsers(ctx context.Context, whereClause string, que
 		var u User
 		var sn string
 		var lastWarnUpdateUnix int64
+		var rateLimitOverrides string
 		err := rows.Scan(
 			&sn,
 			&u.DisplayScreenName,
@@ -438,6 +445,8 @@ func (f SQLiteUserStore) queryUsers(ctx context.Context, whereClause string, que
 			&u.AuthKey,
 			&u.StrongMD5Pass,
 			&u.WeakMD5Pass,
+			&u.PasswordHash,
+			&u.TOTPSecret,
 			&u.ConfirmStatus,
 			&u.RegStatus,
 			&u.SuspendedStatus,
@@ -510,6 +519,7 @@ func (f SQLiteUserStore) queryUsers(ctx context.Context, whereClause string, que
 			&u.AIMDirectoryInfo.ZIPCode,
 			&u.AIMDirectoryInfo.Address,
 			&u.TOCConfig,
+			&rateLimitOverrides,
 			&lastWarnUpdateUnix,
 			&u.LastWarnLevel,
 		)
@@ -518,6 +528,11 @@ func (f SQLiteUserStore) queryUsers(ctx context.Context, whereClause string, que
 		}
 		u.IdentScreenName = NewIdentScreenName(sn)
 		u.LastWarnUpdate = time.Unix(lastWarnUpdateUnix, 0).UTC()
+		if rateLimitOverrides != "" {
+			if err := json.Unmarshal([]byte(rateLimitOverrides), &u.RateLimitOverrides); err != nil {
+				return nil, fmt.Errorf("unable to parse rate limit overrides: %w", err)
+			}
+		}
 		users = append(users, u)
 	}
 	if err = rows.Err(); err != nil {
@@ -532,8 +547,8 @@ func (f SQLiteUserStore) InsertUser(ctx context.Context, u User) error {
 		return errors.New("inserting user with UIN and isICQ=false")
 	}
 	q := `
-		INSERT INTO users (identScreenName, displayScreenName, authKey, weakMD5Pass, strongMD5Pass, isICQ, isBot)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO users (identScreenName, displayScreenName, authKey, weakMD5Pass, strongMD5Pass, passwordHash, isICQ, isBot)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT (identScreenName) DO NOTHING
 	`
 	result, err := f.db.ExecContext(ctx,
@@ -543,6 +558,7 @@ func (f SQLiteUserStore) InsertUser(ctx context.Context, u User) error {
 		u.AuthKey,
 		u.WeakMD5Pass,
 		u.StrongMD5Pass,
+		u.PasswordHash,
 		u.IsICQ,
 		u.IsBot,
 	)
@@ -561,6 +577,35 @@ func (f SQLiteUserStore) InsertUser(ctx context.Context, u User) error {
 	return nil
 }
 
+// NextUIN returns the next unassigned UIN in the range [min, max], which
+// follows the highest UIN assigned so far in that range. It returns
+// ErrUINRangeExhausted if the range is used up. The UIN is not reserved, so
+// callers must handle ErrDupUser from InsertUser when racing with another
+// registration.
+func (f SQLiteUserStore) NextUIN(ctx context.Context, min uint32, max uint32) (uint32, error) {
+	// the WHERE clause must match idx_users_uin for the index to be used
+	q := `
+		SELECT COALESCE(MAX(CAST(identScreenName AS INTEGER)), 0)
+		FROM users
+		WHERE isICQ = true
+		  AND CAST(identScreenName AS INTEGER) BETWEEN ? AND ?
+	`
+	var highest int64
+	if err := f.db.QueryRowContext(ctx, q, min, max).Scan(&highest); err != nil {
+		return 0, err
+	}
+
+	next := int64(min)
+	if highest >= next {
+		next = highest + 1
+	}
+	if next > int64(max) {
+		return 0, ErrUINRangeExhausted
+	}
+
+	return uint32(next), nil
+}
+
 func (f SQLiteUserStore) DeleteUser(ctx context.Context, screenName IdentScreenName) error {
 	q := `
 		DELETE FROM users WHERE identScreenName = ?
@@ -618,10 +663,10 @@ func (f SQLiteUserStore) SetUserPassword(ctx context.Context, screenName IdentSc
 
 	q = `
 		UPDATE users
-		SET authKey = ?, weakMD5Pass = ?, strongMD5Pass = ?
+		SET authKey = ?, weakMD5Pass = ?, strongMD5Pass = ?, passwordHash = ?
 		WHERE identScreenName = ?
 	`
-	result, err := tx.ExecContext(ctx, q, u.AuthKey, u.WeakMD5Pass, u.StrongMD5Pass, screenName.String())
+	result, err := tx.ExecContext(ctx, q, u.AuthKey, u.WeakMD5Pass, u.StrongMD5Pass, u.PasswordHash, screenName.String())
 	if err != nil {
 		return err
 	}
@@ -648,6 +693,158 @@ func (f SQLiteUserStore) SetUserPassword(ctx context.Context, screenName IdentSc
 	return tx.Commit()
 }
 
+// SetPasswordHash sets the bcrypt password hash for a user whose password
+// was previously only stored as MD5 hashes. The MD5 hashes are left intact.
+func (f SQLiteUserStore) SetPasswordHash(ctx context.Context, screenName IdentScreenName, passwordHash string) error {
+	q := `
+		UPDATE users
+		SET passwordHash = ?
+		WHERE identScreenName = ?
+	`
+	result, err := f.db.ExecContext(ctx, q, passwordHash, screenName.String())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNoUser
+	}
+
+	return nil
+}
+
+// SetPasswordHashes replaces the bcrypt and MD5 password hashes of a user
+// with those held by u. The auth key is left intact.
+func (f SQLiteUserStore) SetPasswordHashes(ctx context.Context, u User) error {
+	q := `
+		UPDATE users
+		SET weakMD5Pass = ?, strongMD5Pass = ?, passwordHash = ?
+		WHERE identScreenName = ?
+	`
+	result, err := f.db.ExecContext(ctx, q, u.WeakMD5Pass, u.StrongMD5Pass, u.PasswordHash, u.IdentScreenName.String())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNoUser
+	}
+
+	return nil
+}
+
+// LegacyHashUsers returns all users whose passwords are only stored as MD5
+// hashes.
+func (f SQLiteUserStore) LegacyHashUsers(ctx context.Context) ([]User, error) {
+	users, err := f.queryUsers(ctx, `passwordHash = ''`, nil)
+	if err != nil {
+		return nil, fmt.Errorf("LegacyHashUsers: %w", err)
+	}
+	return users, nil
+}
+
+// SetTOTP enrolls a user in two-factor auth with the given TOTP secret and
+// recovery codes. Any previously issued recovery codes are revoked.
+func (f SQLiteUserStore) SetTOTP(ctx context.Context, screenName IdentScreenName, secret string, recoveryCodes []string) error {
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := `
+		UPDATE users
+		SET totpSecret = ?, totpLastStep = 0
+		WHERE identScreenName = ?
+	`
+	result, err := tx.ExecContext(ctx, q, secret, screenName.String())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNoUser
+	}
+
+	q = `DELETE FROM totpRecoveryCode WHERE screenName = ?`
+	if _, err := tx.ExecContext(ctx, q, screenName.String()); err != nil {
+		return err
+	}
+
+	q = `INSERT INTO totpRecoveryCode (screenName, codeHash) VALUES (?, ?)`
+	for _, code := range recoveryCodes {
+		if _, err := tx.ExecContext(ctx, q, screenName.String(), hashRecoveryCode(code)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ClearTOTP removes a user's TOTP secret and recovery codes, disabling
+// two-factor auth.
+func (f SQLiteUserStore) ClearTOTP(ctx context.Context, screenName IdentScreenName) error {
+	return f.SetTOTP(ctx, screenName, "", nil)
+}
+
+// ValidateSecondFactor checks a one-time code for a user enrolled in
+// two-factor auth. The code may either be a TOTP code or one of the user's
+// recovery codes. A recovery code is consumed once it's used. A TOTP code is
+// rejected if a code from the same or a later time step was already
+// accepted, so that it can't be replayed within its validity window.
+func (f SQLiteUserStore) ValidateSecondFactor(ctx context.Context, u User, code string) (bool, error) {
+	if !u.TOTPEnabled() || code == "" {
+		return false, nil
+	}
+	if step, ok := ValidateTOTPCode(u.TOTPSecret, code, time.Now()); ok {
+		// claim the time step, unless a concurrent login already did
+		q := `
+			UPDATE users
+			SET totpLastStep = ?
+			WHERE identScreenName = ? AND totpLastStep < ?
+		`
+		result, err := f.db.ExecContext(ctx, q, step, u.IdentScreenName.String(), step)
+		if err != nil {
+			return false, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return false, err
+		}
+
+		return rowsAffected > 0, nil
+	}
+	if len(code) != TOTPRecoveryCodeLen {
+		return false, nil
+	}
+
+	q := `DELETE FROM totpRecoveryCode WHERE screenName = ? AND codeHash = ?`
+	result, err := f.db.ExecContext(ctx, q, u.IdentScreenName.String(), hashRecoveryCode(code))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
 func (f SQLiteUserStore) Feedbag(ctx context.Context, screenName IdentScreenName) ([]wire.FeedbagItem, error) {
 	q := `
 		SELECT 
@@ -690,16 +887,31 @@ func (f SQLiteUserStore) FeedbagLastModified(ctx context.Context, screenName Ide
 }
 
 func (f SQLiteUserStore) FeedbagDelete(ctx context.Context, screenName IdentScreenName, items []wire.FeedbagItem) error {
-	// todo add transaction
-	q := `DELETE FROM feedbag WHERE screenName = ? AND itemID = ?`
+	write := func() error {
+		// todo add transaction
+		q := `DELETE FROM feedbag WHERE screenName = ? AND itemID = ?`
 
-	for _, item := range items {
-		if _, err := f.db.ExecContext(ctx, q, screenName.String(), item.ItemID); err != nil {
-			return err
+		for _, item := range items {
+			if _, err := f.db.ExecContext(ctx, q, screenName.String(), item.ItemID); err != nil {
+				return err
+			}
 		}
+
+		return nil
 	}
 
-	return nil
+	return f.writeRelationships(ctx, screenName, write, func(c *relationshipCache) error {
+		c.update(screenName, func(u *relUser) {
+			for _, item := range items {
+				for key := range u.feedbag {
+					if key.itemID == item.ItemID {
+						delete(u.feedbag, key)
+					}
+				}
+			}
+		})
+		return nil
+	})
 }
 
 func (f SQLiteUserStore) FeedbagUpsert(ctx context.Context, screenName IdentScreenName, items []wire.FeedbagItem) error {
@@ -714,52 +926,91 @@ func (f SQLiteUserStore) FeedbagUpsert(ctx context.Context, screenName IdentScre
 						  lastModified = UNIXEPOCH()
 	`
 
-	for _, item := range items {
-		buf := &bytes.Buffer{}
-		if err := wire.MarshalBE(item.TLVLBlock, buf); err != nil {
-			return err
-		}
+	write := func() error {
+		for _, item := range items {
+			buf := &bytes.Buffer{}
+			if err := wire.MarshalBE(item.TLVLBlock, buf); err != nil {
+				return err
+			}
 
-		if item.ClassID == wire.FeedbagClassIdBuddy ||
-			item.ClassID == wire.FeedbagClassIDPermit ||
-			item.ClassID == wire.FeedbagClassIDDeny {
-			// insert screen name identifier
-			item.Name = NewIdentScreenName(item.Name).String()
-		}
-		pdMode := uint8(0)
-		if item.ClassID == wire.FeedbagClassIdPdinfo {
-			var hasMode bool
-			pdMode, hasMode = item.Uint8(wire.FeedbagAttributesPdMode)
-			if !hasMode {
-				// by default, QIP sends a PD info item entry with no mode
-				pdMode = uint8(wire.FeedbagPDModePermitAll)
+			if isRelationshipItem(item) {
+				// insert screen name identifier
+				item.Name = NewIdentScreenName(item.Name).String()
+			}
+			_, err := f.db.ExecContext(ctx,
+				q,
+				screenName.String(),
+				item.GroupID,
+				item.ItemID,
+				item.ClassID,
+				item.Name,
+				buf.Bytes(),
+				feedbagPDMode(item))
+			if err != nil {
+				return err
 			}
 		}
-		_, err := f.db.ExecContext(ctx,
-			q,
-			screenName.String(),
-			item.GroupID,
-			item.ItemID,
-			item.ClassID,
-			item.Name,
-			buf.Bytes(),
-			pdMode)
-		if err != nil {
-			return err
-		}
+
+		return nil
 	}
 
-	return nil
+	return f.writeRelationships(ctx, screenName, write, func(c *relationshipCache) error {
+		c.update(screenName, func(u *relUser) {
+			for _, item := range items {
+				key := relItemKey{groupID: item.GroupID, itemID: item.ItemID}
+				if isRelationshipItem(item) || item.ClassID == wire.FeedbagClassIdPdinfo {
+					u.feedbag[key] = relItem{
+						classID: item.ClassID,
+						name:    NewIdentScreenName(item.Name),
+						pdMode:  wire.FeedbagPDMode(feedbagPDMode(item)),
+					}
+				} else {
+					delete(u.feedbag, key)
+				}
+			}
+		})
+		return nil
+	})
 }
 
-func (f SQLiteUserStore) ClearBuddyListRegistry(ctx context.Context) error {
-	if _, err := f.db.ExecContext(ctx, `DELETE FROM buddyListMode`); err != nil {
-		return err
+// isRelationshipItem indicates whether item is a buddy, permit, or deny list
+// entry.
+func isRelationshipItem(item wire.FeedbagItem) bool {
+	return item.ClassID == wire.FeedbagClassIdBuddy ||
+		item.ClassID == wire.FeedbagClassIDPermit ||
+		item.ClassID == wire.FeedbagClassIDDeny
+}
+
+// feedbagPDMode returns the permit/deny mode stored in a PD info item, or 0
+// for other items.
+func feedbagPDMode(item wire.FeedbagItem) uint8 {
+	if item.ClassID != wire.FeedbagClassIdPdinfo {
+		return 0
 	}
-	if _, err := f.db.ExecContext(ctx, `DELETE FROM clientSideBuddyList`); err != nil {
-		return err
+	pdMode, hasMode := item.Uint8(wire.FeedbagAttributesPdMode)
+	if !hasMode {
+		// by default, QIP sends a PD info item entry with no mode
+		pdMode = uint8(wire.FeedbagPDModePermitAll)
 	}
-	return nil
+	return pdMode
+}
+
+func (f SQLiteUserStore) ClearBuddyListRegistry(ctx context.Context) error {
+	write := func() error {
+		if _, err := f.db.ExecContext(ctx, `DELETE FROM buddyListMode`); err != nil {
+			return err
+		}
+		if _, err := f.db.ExecContext(ctx, `DELETE FROM clientSideBuddyList`); err != nil {
+			return err
+		}
+		return nil
+	}
+	return f.writeRelationships(ctx, IdentScreenName{}, write, func(c *relationshipCache) error {
+		for sn := range c.users {
+			c.drop(sn)
+		}
+		return nil
+	})
 }
 
 func (f SQLiteUserStore) RegisterBuddyList(ctx context.Context, user IdentScreenName) error {
@@ -767,18 +1018,32 @@ func (f SQLiteUserStore) RegisterBuddyList(ctx context.Context, user IdentScreen
 		INSERT INTO buddyListMode (screenName, clientSidePDMode) VALUES(?, ?)
 		ON CONFLICT (screenName) DO NOTHING
 	`
-	_, err := f.db.ExecContext(ctx, q, user.String(), wire.FeedbagPDModePermitAll)
-	return err
+	write := func() error {
+		_, err := f.db.ExecContext(ctx, q, user.String(), wire.FeedbagPDModePermitAll)
+		return err
+	}
+	return f.writeRelationships(ctx, user, write, func(c *relationshipCache) error {
+		if _, ok := c.users[user]; ok {
+			return nil
+		}
+		return f.reloadRelUsers(ctx, user)
+	})
 }
 
 func (f SQLiteUserStore) UnregisterBuddyList(ctx context.Context, user IdentScreenName) error {
-	if _, err := f.db.ExecContext(ctx, `DELETE FROM buddyListMode WHERE screenName = ?`, user.String()); err != nil {
-		return err
-	}
-	if _, err := f.db.ExecContext(ctx, `DELETE FROM clientSideBuddyList WHERE me = ?`, user.String()); err != nil {
-		return err
+	write := func() error {
+		if _, err := f.db.ExecContext(ctx, `DELETE FROM buddyListMode WHERE screenName = ?`, user.String()); err != nil {
+			return err
+		}
+		if _, err := f.db.ExecContext(ctx, `DELETE FROM clientSideBuddyList WHERE me = ?`, user.String()); err != nil {
+			return err
+		}
+		return nil
 	}
-	return nil
+	return f.writeRelationships(ctx, user, write, func(c *relationshipCache) error {
+		c.drop(user)
+		return nil
+	})
 }
 
 func (f SQLiteUserStore) UseFeedbag(ctx context.Context, screenName IdentScreenName) error {
@@ -789,8 +1054,13 @@ func (f SQLiteUserStore) UseFeedbag(ctx context.Context, screenName IdentScreenN
 			DO UPDATE SET clientSidePDMode = 0,
 						  useFeedbag       = true
 	`
-	_, err := f.db.ExecContext(ctx, q, screenName.String(), true)
-	return err
+	write := func() error {
+		_, err := f.db.ExecContext(ctx, q, screenName.String(), true)
+		return err
+	}
+	return f.writeRelationships(ctx, screenName, write, func(c *relationshipCache) error {
+		return f.reloadRelUsers(ctx, screenName)
+	})
 }
 
 func (f SQLiteUserStore) SetPDMode(ctx context.Context, me IdentScreenName, pdMode wire.FeedbagPDMode) error {
@@ -802,32 +1072,38 @@ func (f SQLiteUserStore) SetPDMode(ctx context.Context, me IdentScreenName, pdMo
 		return nil
 	}
 
-	tx, err := f.db.Begin()
-	if err != nil {
-		return err
-	}
+	write := func() error {
+		tx, err := f.db.Begin()
+		if err != nil {
+			return err
+		}
 
-	defer func() {
-		_ = tx.Rollback()
-	}()
+		defer func() {
+			_ = tx.Rollback()
+		}()
 
-	if err := setClientSidePDMode(ctx, tx, me, pdMode); err != nil {
-		return fmt.Errorf("setClientSidePDMode: %w", err)
-	}
+		if err := setClientSidePDMode(ctx, tx, me, pdMode); err != nil {
+			return fmt.Errorf("setClientSidePDMode: %w", err)
+		}
 
-	if err := clearClientSidePDFlags(ctx, tx, me, pdMode); err != nil {
-		return fmt.Errorf("clearClientSidePDFlags: %w", err)
-	}
+		if err := clearClientSidePDFlags(ctx, tx, me, pdMode); err != nil {
+			return fmt.Errorf("clearClientSidePDFlags: %w", err)
+		}
 
-	if err := clearBlankClientSideBuddies(ctx, tx, me, pdMode); err != nil {
-		return fmt.Errorf("clearBlankClientSideBuddies: %w", err)
-	}
+		if err := clearBlankClientSideBuddies(ctx, tx, me, pdMode); err != nil {
+			return fmt.Errorf("clearBlankClientSideBuddies: %w", err)
+		}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit: %w", err)
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit: %w", err)
+		}
+
+		return nil
 	}
 
-	return nil
+	return f.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		return f.reloadRelUsers(ctx, me)
+	})
 }
 
 // isPDModeEqual indicates whether the current permit/deny mode is already set
@@ -891,8 +1167,14 @@ func (f SQLiteUserStore) AddBuddy(ctx context.Context, me IdentScreenName, them
 		VALUES (?, ?, true)
 		ON CONFLICT (me, them) DO UPDATE SET isBuddy = true
 	`
-	_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
-	return err
+	write := func() error {
+		_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
+		return err
+	}
+	return f.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		c.upsertClientSide(me, them, func(flags *relFlags) { flags.buddy = true })
+		return nil
+	})
 }
 
 func (f SQLiteUserStore) RemoveBuddy(ctx context.Context, me IdentScreenName, them IdentScreenName) error {
@@ -902,8 +1184,14 @@ func (f SQLiteUserStore) RemoveBuddy(ctx context.Context, me IdentScreenName, th
 		WHERE me = ?
 		  AND them = ?
 	`
-	_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
-	return err
+	write := func() error {
+		_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
+		return err
+	}
+	return f.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		c.updateClientSide(me, them, func(flags *relFlags) { flags.buddy = false })
+		return nil
+	})
 }
 
 func (f SQLiteUserStore) DenyBuddy(ctx context.Context, me IdentScreenName, them IdentScreenName) error {
@@ -912,8 +1200,14 @@ func (f SQLiteUserStore) DenyBuddy(ctx context.Context, me IdentScreenName, them
 		VALUES (?, ?, 1)
 		ON CONFLICT (me, them) DO UPDATE SET isDeny = 1
 	`
-	_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
-	return err
+	write := func() error {
+		_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
+		return err
+	}
+	return f.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		c.upsertClientSide(me, them, func(flags *relFlags) { flags.deny = true })
+		return nil
+	})
 }
 
 func (f SQLiteUserStore) RemoveDenyBuddy(ctx context.Context, me IdentScreenName, them IdentScreenName) error {
@@ -923,8 +1217,14 @@ func (f SQLiteUserStore) RemoveDenyBuddy(ctx context.Context, me IdentScreenName
 		WHERE me = ?
 		  AND them = ?
 	`
-	_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
-	return err
+	write := func() error {
+		_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
+		return err
+	}
+	return f.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		c.updateClientSide(me, them, func(flags *relFlags) { flags.deny = false })
+		return nil
+	})
 }
 
 func (f SQLiteUserStore) PermitBuddy(ctx context.Context, me IdentScreenName, them IdentScreenName) error {
@@ -933,8 +1233,14 @@ func (f SQLiteUserStore) PermitBuddy(ctx context.Context, me IdentScreenName, th
 		VALUES (?, ?, 1)
 		ON CONFLICT (me, them) DO UPDATE SET isPermit = 1
 	`
-	_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
-	return err
+	write := func() error {
+		_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
+		return err
+	}
+	return f.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		c.upsertClientSide(me, them, func(flags *relFlags) { flags.permit = true })
+		return nil
+	})
 }
 
 func (f SQLiteUserStore) RemovePermitBuddy(ctx context.Context, me IdentScreenName, them IdentScreenName) error {
@@ -944,8 +1250,14 @@ func (f SQLiteUserStore) RemovePermitBuddy(ctx context.Context, me IdentScreenNa
 		WHERE me = ?
 		  AND them = ?
 	`
-	_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
-	return err
+	write := func() error {
+		_, err := f.db.ExecContext(ctx, q, me.String(), them.String())
+		return err
+	}
+	return f.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		c.updateClientSide(me, them, func(flags *relFlags) { flags.permit = false })
+		return nil
+	})
 }
 
 func (f SQLiteUserStore) Profile(ctx context.Context, screenName IdentScreenName) (string, error) {
@@ -1349,6 +1661,39 @@ func (f SQLiteUserStore) SetBotStatus(ctx context.Context, isBot bool, screenNam
 	return err
 }
 
+// SetRateLimitOverrides replaces the rate limit classes that override the
+// server's classes for a user. Passing no classes removes the overrides.
+func (f SQLiteUserStore) SetRateLimitOverrides(ctx context.Context, screenName IdentScreenName, classes []wire.RateClass) error {
+	var overrides string
+	if len(classes) > 0 {
+		b, err := json.Marshal(classes)
+		if err != nil {
+			return err
+		}
+		overrides = string(b)
+	}
+
+	q := `
+		UPDATE users
+		SET rateLimitOverrides = ?
+		WHERE identScreenName = ?
+	`
+	result, err := f.db.ExecContext(ctx, q, overrides, screenName.String())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNoUser
+	}
+
+	return nil
+}
+
 func (f SQLiteUserStore) SetWorkInfo(ctx context.Context, name IdentScreenName, data ICQWorkInfo) error {
 	q := `
 		UPDATE users SET 
@@ -1591,7 +1936,11 @@ func (f SQLiteUserStore) SetBasicInfo(ctx context.Context, name IdentScreenName,
 	return nil
 }
 
-func (f SQLiteUserStore) SaveMessage(ctx context.Context, offlineMessage OfflineMessage) error {
+// SaveMessage queues an offline message for delivery when the recipient
+// signs on. It returns ErrNoUser if the recipient doesn't exist, or
+// ErrOfflineQueueFull if maxQueued messages are already queued for the
+// recipient. A maxQueued of 0 means there is no limit.
+func (f SQLiteUserStore) SaveMessage(ctx context.Context, offlineMessage OfflineMessage, maxQueued int) error {
 	buf := &bytes.Buffer{}
 	if err := wire.MarshalBE(offlineMessage.Message, buf); err != nil {
 		return fmt.Errorf("marshal: %w", err)
@@ -1599,26 +1948,57 @@ func (f SQLiteUserStore) SaveMessage(ctx context.Context, offlineMessage Offline
 
 	q := `
 		INSERT INTO offlineMessage (sender, recipient, message, sent)
-		VALUES (?, ?, ?, ?)
+		SELECT ?, ?, ?, ?
+		WHERE EXISTS (SELECT 1 FROM users WHERE identScreenName = ?)
+		  AND (? = 0 OR (SELECT COUNT(*) FROM offlineMessage WHERE recipient = ?) < ?)
 	`
-	_, err := f.db.ExecContext(ctx,
+	res, err := f.db.ExecContext(ctx,
 		q,
 		offlineMessage.Sender.String(),
 		offlineMessage.Recipient.String(),
 		buf.Bytes(),
 		offlineMessage.Sent,
+		offlineMessage.Recipient.String(),
+		maxQueued,
+		offlineMessage.Recipient.String(),
+		maxQueued,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	c, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if c > 0 {
+		return nil
+	}
+
+	// find out why the message wasn't queued
+	q = `SELECT EXISTS(SELECT 1 FROM users WHERE identScreenName = ?)`
+	var exists bool
+	if err := f.db.QueryRowContext(ctx, q, offlineMessage.Recipient.String()).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNoUser
+	}
+	return ErrOfflineQueueFull
 }
 
+// RetrieveMessages returns the offline messages queued for recip, oldest
+// first.
 func (f SQLiteUserStore) RetrieveMessages(ctx context.Context, recip IdentScreenName) ([]OfflineMessage, error) {
 	q := `
-		SELECT 
-		    sender, 
+		SELECT
+		    rowid,
+		    sender,
 		    message,
 		    sent
 		FROM offlineMessage
 		WHERE recipient = ?
+		ORDER BY rowid
 	`
 	rows, err := f.db.QueryContext(ctx, q, recip.String())
 	if err != nil {
@@ -1629,10 +2009,11 @@ func (f SQLiteUserStore) RetrieveMessages(ctx context.Context, recip IdentScreen
 	var messages []OfflineMessage
 
 	for rows.Next() {
+		var id int64
 		var sender string
 		var buf []byte
 		var sent time.Time
-		if err := rows.Scan(&sender, &buf, &sent); err != nil {
+		if err := rows.Scan(&id, &sender, &buf, &sent); err != nil {
 			return nil, err
 		}
 
@@ -1642,6 +2023,7 @@ func (f SQLiteUserStore) RetrieveMessages(ctx context.Context, recip IdentScreen
 		}
 
 		messages = append(messages, OfflineMessage{
+			ID:        id,
 			Sender:    NewIdentScreenName(sender),
 			Recipient: recip,
 			Message:   msg,
@@ -1656,6 +2038,7 @@ func (f SQLiteUserStore) RetrieveMessages(ctx context.Context, recip IdentScreen
 	return messages, nil
 }
 
+// DeleteMessages deletes the offline messages queued for recip.
 func (f SQLiteUserStore) DeleteMessages(ctx context.Context, recip IdentScreenName) error {
 	q := `
 		DELETE FROM offlineMessage WHERE recipient = ?
@@ -1664,6 +2047,44 @@ func (f SQLiteUserStore) DeleteMessages(ctx context.Context, recip IdentScreenNa
 	return err
 }
 
+// DeleteMessagesByID deletes the offline messages queued for recip that have
+// the given IDs. Unlike DeleteMessages, it leaves alone messages that were
+// queued after the given ones were retrieved.
+func (f SQLiteUserStore) DeleteMessagesByID(ctx context.Context, recip IdentScreenName, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]any, 0, len(ids)+1)
+	args = append(args, recip.String())
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+
+	q := fmt.Sprintf(`
+		DELETE FROM offlineMessage
+		WHERE recipient = ? AND rowid IN (%s)
+	`, strings.Join(placeholders, ","))
+
+	_, err := f.db.ExecContext(ctx, q, args...)
+	return err
+}
+
+// DeleteExpiredMessages deletes the offline messages sent before
+// sentBefore. It returns the number of messages deleted.
+func (f SQLiteUserStore) DeleteExpiredMessages(ctx context.Context, sentBefore time.Time) (int64, error) {
+	q := `
+		DELETE FROM offlineMessage WHERE sent < ?
+	`
+	res, err := f.db.ExecContext(ctx, q, sentBefore.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func (f SQLiteUserStore) BuddyIconMetadata(ctx context.Context, screenName IdentScreenName) (*wire.BARTID, error) {
 	q := `
 		SELECT
@@ -1894,6 +2315,18 @@ func (f SQLiteUserStore) CreateKeyword(ctx context.Context, name string, categor
 	}, nil
 }
 
+// KeywordByID returns the keyword identified by id. It returns
+// ErrKeywordNotFound if the keyword does not exist.
+func (f SQLiteUserStore) KeywordByID(ctx context.Context, id uint8) (Keyword, error) {
+	keyword := Keyword{}
+	err := f.db.QueryRowContext(ctx, `SELECT id, name FROM aimKeyword WHERE id = ?`, id).
+		Scan(&keyword.ID, &keyword.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return keyword, ErrKeywordNotFound
+	}
+	return keyword, err
+}
+
 func (f SQLiteUserStore) DeleteKeyword(ctx context.Context, id uint8) error {
 	q := `DELETE FROM aimKeyword WHERE id = ?`
 	res, err := f.db.ExecContext(ctx, q, id)
@@ -465,6 +465,10 @@ func TestNewStubUser(t *testing.T) {
 	}
 	assert.NoError(t, want.HashPassword("welcome1"))
 
+	// the bcrypt hash is salted, so verify it separately
+	assert.True(t, have.ValidatePlaintextPass([]byte("welcome1")))
+	want.PasswordHash = have.PasswordHash
+
 	assert.Equal(t, want, have)
 }
 
@@ -1936,8 +1940,15 @@ func TestSQLiteUserStore_RetrieveMessages(t *testing.T) {
 		},
 	}
 
+	for _, sn := range []string{"Jack", "Anne"} {
+		assert.NoError(t, f.InsertUser(context.Background(), User{
+			IdentScreenName:   NewIdentScreenName(sn),
+			DisplayScreenName: DisplayScreenName(sn),
+		}))
+	}
+
 	for _, msg := range offlineMessages {
-		err = f.SaveMessage(context.Background(), msg)
+		err = f.SaveMessage(context.Background(), msg, 0)
 		assert.NoError(t, err)
 	}
 
@@ -1945,8 +1956,11 @@ func TestSQLiteUserStore_RetrieveMessages(t *testing.T) {
 		messages, err := f.RetrieveMessages(context.Background(), NewIdentScreenName("Jack"))
 		assert.NoError(t, err)
 		if assert.Len(t, messages, 2) {
-			assert.Equal(t, offlineMessages[0], messages[0])
-			assert.Equal(t, offlineMessages[2], messages[1])
+			// IDs are assigned in the order the messages were saved
+			want1, want2 := offlineMessages[0], offlineMessages[2]
+			want1.ID, want2.ID = 1, 3
+			assert.Equal(t, want1, messages[0])
+			assert.Equal(t, want2, messages[1])
 		}
 	})
 
@@ -1994,8 +2008,15 @@ func TestSQLiteUserStore_DeleteMessages(t *testing.T) {
 		},
 	}
 
+	for _, sn := range []string{"Jack", "Anne"} {
+		assert.NoError(t, f.InsertUser(context.Background(), User{
+			IdentScreenName:   NewIdentScreenName(sn),
+			DisplayScreenName: DisplayScreenName(sn),
+		}))
+	}
+
 	for _, msg := range offlineMessages {
-		err = f.SaveMessage(context.Background(), msg)
+		err = f.SaveMessage(context.Background(), msg, 0)
 		assert.NoError(t, err)
 	}
 
@@ -2022,6 +2043,163 @@ func TestSQLiteUserStore_DeleteMessages(t *testing.T) {
 	})
 }
 
+func TestSQLiteUserStore_DeleteMessagesByID(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	f, err := NewSQLiteUserStore(testFile)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	for _, sn := range []string{"Jack", "Anne"} {
+		assert.NoError(t, f.InsertUser(ctx, User{
+			IdentScreenName:   NewIdentScreenName(sn),
+			DisplayScreenName: DisplayScreenName(sn),
+		}))
+	}
+
+	newMsg := func(recip string, cookie uint64) OfflineMessage {
+		return OfflineMessage{
+			Sender:    NewIdentScreenName("John"),
+			Recipient: NewIdentScreenName(recip),
+			Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+				Cookie: cookie,
+			},
+			Sent: time.Now().UTC(),
+		}
+	}
+
+	assert.NoError(t, f.SaveMessage(ctx, newMsg("Jack", 1), 0))
+	assert.NoError(t, f.SaveMessage(ctx, newMsg("Anne", 2), 0))
+	assert.NoError(t, f.SaveMessage(ctx, newMsg("Jack", 3), 0))
+
+	retrieved, err := f.RetrieveMessages(ctx, NewIdentScreenName("Jack"))
+	assert.NoError(t, err)
+	assert.Len(t, retrieved, 2)
+
+	// a message queued after the retrieval must survive the deletion
+	assert.NoError(t, f.SaveMessage(ctx, newMsg("Jack", 4), 0))
+
+	ids := []int64{retrieved[0].ID, retrieved[1].ID}
+	// Anne's message ID is ignored because it's queued for someone else
+	anne, err := f.RetrieveMessages(ctx, NewIdentScreenName("Anne"))
+	assert.NoError(t, err)
+	ids = append(ids, anne[0].ID)
+	assert.NoError(t, f.DeleteMessagesByID(ctx, NewIdentScreenName("Jack"), ids))
+
+	messages, err := f.RetrieveMessages(ctx, NewIdentScreenName("Jack"))
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, uint64(4), messages[0].Message.Cookie)
+	}
+
+	messages, err = f.RetrieveMessages(ctx, NewIdentScreenName("Anne"))
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	assert.NoError(t, f.DeleteMessagesByID(ctx, NewIdentScreenName("Jack"), nil))
+}
+
+func TestSQLiteUserStore_SaveMessage(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	f, err := NewSQLiteUserStore(testFile)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	for _, sn := range []string{"Jack", "Anne"} {
+		assert.NoError(t, f.InsertUser(ctx, User{
+			IdentScreenName:   NewIdentScreenName(sn),
+			DisplayScreenName: DisplayScreenName(sn),
+		}))
+	}
+
+	newMsg := func(recip string, cookie uint64) OfflineMessage {
+		return OfflineMessage{
+			Sender:    NewIdentScreenName("John"),
+			Recipient: NewIdentScreenName(recip),
+			Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+				Cookie: cookie,
+			},
+			Sent: time.Now().UTC(),
+		}
+	}
+
+	t.Run("recipient doesn't exist", func(t *testing.T) {
+		err := f.SaveMessage(ctx, newMsg("Franke", 1), 0)
+		assert.ErrorIs(t, err, ErrNoUser)
+
+		messages, err := f.RetrieveMessages(ctx, NewIdentScreenName("Franke"))
+		assert.NoError(t, err)
+		assert.Empty(t, messages)
+	})
+
+	t.Run("queue fills up", func(t *testing.T) {
+		assert.NoError(t, f.SaveMessage(ctx, newMsg("Jack", 1), 2))
+		assert.NoError(t, f.SaveMessage(ctx, newMsg("Jack", 2), 2))
+		assert.ErrorIs(t, f.SaveMessage(ctx, newMsg("Jack", 3), 2), ErrOfflineQueueFull)
+
+		// the limit applies to each recipient separately
+		assert.NoError(t, f.SaveMessage(ctx, newMsg("Anne", 4), 2))
+
+		messages, err := f.RetrieveMessages(ctx, NewIdentScreenName("Jack"))
+		assert.NoError(t, err)
+		if assert.Len(t, messages, 2) {
+			assert.Equal(t, uint64(1), messages[0].Message.Cookie)
+			assert.Equal(t, uint64(2), messages[1].Message.Cookie)
+		}
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		assert.NoError(t, f.SaveMessage(ctx, newMsg("Jack", 5), 0))
+
+		messages, err := f.RetrieveMessages(ctx, NewIdentScreenName("Jack"))
+		assert.NoError(t, err)
+		assert.Len(t, messages, 3)
+	})
+}
+
+func TestSQLiteUserStore_DeleteExpiredMessages(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	f, err := NewSQLiteUserStore(testFile)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NoError(t, f.InsertUser(ctx, User{
+		IdentScreenName:   NewIdentScreenName("Jack"),
+		DisplayScreenName: "Jack",
+	}))
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, age := range []time.Duration{40 * 24 * time.Hour, 31 * 24 * time.Hour, 29 * 24 * time.Hour, time.Minute} {
+		assert.NoError(t, f.SaveMessage(ctx, OfflineMessage{
+			Sender:    NewIdentScreenName("John"),
+			Recipient: NewIdentScreenName("Jack"),
+			Message: wire.SNAC_0x04_0x06_ICBMChannelMsgToHost{
+				Cookie: uint64(i),
+			},
+			Sent: now.Add(-age),
+		}, 0))
+	}
+
+	deleted, err := f.DeleteExpiredMessages(ctx, now.Add(-30*24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	messages, err := f.RetrieveMessages(ctx, NewIdentScreenName("Jack"))
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, uint64(2), messages[0].Message.Cookie)
+		assert.Equal(t, uint64(3), messages[1].Message.Cookie)
+	}
+}
+
 func TestSQLiteUserStore_BuddyIconMetadataExistingRef(t *testing.T) {
 	defer func() {
 		assert.NoError(t, os.Remove(testFile))
@@ -2530,6 +2708,26 @@ func TestSQLiteUserStore_CreateKeyword(t *testing.T) {
 	})
 }
 
+func TestSQLiteUserStore_KeywordByID(t *testing.T) {
+	f, err := NewSQLiteUserStore(testFile)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	category, err := f.CreateCategory(context.Background(), "TestCategory")
+	assert.NoError(t, err)
+	keyword, err := f.CreateKeyword(context.Background(), "TestKeyword", category.ID)
+	assert.NoError(t, err)
+
+	retrieved, err := f.KeywordByID(context.Background(), keyword.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, keyword, retrieved)
+
+	_, err = f.KeywordByID(context.Background(), 99)
+	assert.ErrorIs(t, err, ErrKeywordNotFound)
+}
+
 func TestSQLiteUserStore_DeleteKeyword(t *testing.T) {
 	t.Run("Successfully Delete Keyword", func(t *testing.T) {
 		f, err := NewSQLiteUserStore(testFile)
@@ -3348,6 +3546,54 @@ func TestSQLiteUserStore_SetBotStatus(t *testing.T) {
 	assert.False(t, user.IsBot)
 }
 
+func TestSQLiteUserStore_SetRateLimitOverrides(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	f, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	screenName := NewIdentScreenName("userA")
+	err = f.InsertUser(context.Background(), User{
+		IdentScreenName:   screenName,
+		DisplayScreenName: DisplayScreenName("userA"),
+	})
+	require.NoError(t, err)
+
+	user, err := f.User(context.Background(), screenName)
+	require.NoError(t, err)
+	assert.Empty(t, user.RateLimitOverrides)
+
+	overrides := []wire.RateClass{
+		{
+			ID:              3,
+			WindowSize:      20,
+			ClearLevel:      510,
+			AlertLevel:      500,
+			LimitLevel:      400,
+			DisconnectLevel: 300,
+			MaxLevel:        600,
+		},
+	}
+	err = f.SetRateLimitOverrides(context.Background(), screenName, overrides)
+	require.NoError(t, err)
+
+	user, err = f.User(context.Background(), screenName)
+	require.NoError(t, err)
+	assert.Equal(t, overrides, user.RateLimitOverrides)
+
+	err = f.SetRateLimitOverrides(context.Background(), screenName, nil)
+	require.NoError(t, err)
+
+	user, err = f.User(context.Background(), screenName)
+	require.NoError(t, err)
+	assert.Empty(t, user.RateLimitOverrides)
+
+	err = f.SetRateLimitOverrides(context.Background(), NewIdentScreenName("some_user"), overrides)
+	assert.ErrorIs(t, err, ErrNoUser)
+}
+
 func TestSQLiteUserStore_SetWarnLevel(t *testing.T) {
 	t.Run("Happy Path - Update Warning Level for Existing User", func(t *testing.T) {
 		defer func() {
@@ -3444,3 +3690,172 @@ func TestSQLiteUserStore_DeleteBARTItem(t *testing.T) {
 		assert.ErrorIs(t, err, ErrBARTItemNotFound)
 	})
 }
+
+func TestSQLiteUserStore_LegacyHashUsers(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	legacy := User{
+		IdentScreenName:   NewIdentScreenName("legacyUser"),
+		DisplayScreenName: "legacyUser",
+		AuthKey:           "theAuthKey",
+		WeakMD5Pass:       wire.WeakMD5PasswordHash("thepassword", "theAuthKey"),
+		StrongMD5Pass:     wire.StrongMD5PasswordHash("thepassword", "theAuthKey"),
+	}
+	require.NoError(t, userStore.InsertUser(context.Background(), legacy))
+
+	modern := User{
+		IdentScreenName:   NewIdentScreenName("modernUser"),
+		DisplayScreenName: "modernUser",
+		AuthKey:           "theAuthKey",
+	}
+	require.NoError(t, modern.HashPassword("thepassword"))
+	require.NoError(t, userStore.InsertUser(context.Background(), modern))
+
+	users, err := userStore.LegacyHashUsers(context.Background())
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, legacy.IdentScreenName, users[0].IdentScreenName)
+
+	require.NoError(t, legacy.UpgradePasswordHash([]byte("thepassword")))
+	require.NoError(t, userStore.SetPasswordHash(context.Background(), legacy.IdentScreenName, legacy.PasswordHash))
+
+	users, err = userStore.LegacyHashUsers(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestSQLiteUserStore_SetPasswordHash_ErrNoUser(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	err = userStore.SetPasswordHash(context.Background(), NewIdentScreenName("some_user"), "hash")
+	assert.ErrorIs(t, err, ErrNoUser)
+}
+
+func TestSQLiteUserStore_TOTP(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	u := User{
+		IdentScreenName:   NewIdentScreenName("theUser"),
+		DisplayScreenName: "theUser",
+		AuthKey:           "theAuthKey",
+	}
+	require.NoError(t, userStore.InsertUser(context.Background(), u))
+
+	recoveryCodes := []string{"aaaaaaaaaa", "bbbbbbbbbb"}
+	err = userStore.SetTOTP(context.Background(), u.IdentScreenName, rfc6238Secret, recoveryCodes)
+	require.NoError(t, err)
+
+	have, err := userStore.User(context.Background(), u.IdentScreenName)
+	require.NoError(t, err)
+	assert.True(t, have.TOTPEnabled())
+	assert.Equal(t, rfc6238Secret, have.TOTPSecret)
+
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	require.NoError(t, err)
+	step := time.Now().Unix() / int64(totpPeriod/time.Second)
+	code := totpCode(key, uint64(step))
+
+	ok, err := userStore.ValidateSecondFactor(context.Background(), *have, code)
+	require.NoError(t, err)
+	assert.True(t, ok, "TOTP code should be accepted")
+
+	ok, err = userStore.ValidateSecondFactor(context.Background(), *have, code)
+	require.NoError(t, err)
+	assert.False(t, ok, "TOTP code should only be accepted once")
+
+	ok, err = userStore.ValidateSecondFactor(context.Background(), *have, totpCode(key, uint64(step-1)))
+	require.NoError(t, err)
+	assert.False(t, ok, "TOTP code older than the last accepted one should be rejected")
+
+	ok, err = userStore.ValidateSecondFactor(context.Background(), *have, "bbbbbbbbbb")
+	require.NoError(t, err)
+	assert.True(t, ok, "recovery code should be accepted")
+
+	ok, err = userStore.ValidateSecondFactor(context.Background(), *have, "bbbbbbbbbb")
+	require.NoError(t, err)
+	assert.False(t, ok, "recovery code should only be accepted once")
+
+	ok, err = userStore.ValidateSecondFactor(context.Background(), *have, "cccccccccc")
+	require.NoError(t, err)
+	assert.False(t, ok, "unknown recovery code should be rejected")
+
+	require.NoError(t, userStore.ClearTOTP(context.Background(), u.IdentScreenName))
+
+	have, err = userStore.User(context.Background(), u.IdentScreenName)
+	require.NoError(t, err)
+	assert.False(t, have.TOTPEnabled())
+
+	// recovery codes are revoked along with the secret
+	have.TOTPSecret = rfc6238Secret
+	ok, err = userStore.ValidateSecondFactor(context.Background(), *have, "aaaaaaaaaa")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSQLiteUserStore_SetTOTP_ErrNoUser(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	err = userStore.SetTOTP(context.Background(), NewIdentScreenName("some_user"), rfc6238Secret, nil)
+	assert.ErrorIs(t, err, ErrNoUser)
+}
+
+func TestSQLiteUserStore_NextUIN(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	insert := func(screenName DisplayScreenName) {
+		u := User{
+			IdentScreenName:   screenName.IdentScreenName(),
+			DisplayScreenName: screenName,
+			IsICQ:             screenName.IsUIN(),
+		}
+		require.NoError(t, userStore.InsertUser(context.Background(), u))
+	}
+
+	// empty range starts at min
+	uin, err := userStore.NextUIN(context.Background(), 100000, 100002)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100000), uin)
+
+	// UINs and screen names outside the range are ignored
+	insert("12345")
+	insert("200000")
+	insert("100000aim")
+	uin, err = userStore.NextUIN(context.Background(), 100000, 100002)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100000), uin)
+
+	// follows the highest UIN in the range
+	insert("100001")
+	uin, err = userStore.NextUIN(context.Background(), 100000, 100002)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100002), uin)
+
+	insert("100002")
+	_, err = userStore.NextUIN(context.Background(), 100000, 100002)
+	assert.ErrorIs(t, err, ErrUINRangeExhausted)
+}
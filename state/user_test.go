@@ -87,6 +87,8 @@ func TestUser_HashPassword(t *testing.T) {
 				require.NoError(t, err)
 				assert.Equal(t, tt.expectedWeakMD5, tt.user.WeakMD5Pass)
 				assert.Equal(t, tt.expectedStrongMD5, tt.user.StrongMD5Pass)
+				assert.False(t, tt.user.LegacyHashOnly())
+				assert.True(t, tt.user.ValidatePlaintextPass([]byte(tt.password)))
 			}
 		})
 	}
@@ -474,6 +476,34 @@ func TestUser_ValidatePlaintextPass(t *testing.T) {
 			plaintextPass: []byte("test@123!"),
 			expected:      true,
 		},
+		{
+			name: "Valid plaintext password against bcrypt hash",
+			user: User{
+				AuthKey:      "testAuthKey",
+				PasswordHash: bcryptHash(t, "testPassword"),
+			},
+			plaintextPass: []byte("testPassword"),
+			expected:      true,
+		},
+		{
+			name: "Invalid plaintext password against bcrypt hash",
+			user: User{
+				AuthKey:      "testAuthKey",
+				PasswordHash: bcryptHash(t, "testPassword"),
+			},
+			plaintextPass: []byte("wrongPassword"),
+			expected:      false,
+		},
+		{
+			name: "bcrypt hash takes precedence over stale MD5 hash",
+			user: User{
+				AuthKey:      "testAuthKey",
+				WeakMD5Pass:  wire.WeakMD5PasswordHash("oldPassword", "testAuthKey"),
+				PasswordHash: bcryptHash(t, "testPassword"),
+			},
+			plaintextPass: []byte("oldPassword"),
+			expected:      false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -536,3 +566,22 @@ func TestUser_ValidateRoastedKerberosPass(t *testing.T) {
 		})
 	}
 }
+
+func TestUser_UpgradePasswordHash(t *testing.T) {
+	u := User{
+		AuthKey:     "testAuthKey",
+		WeakMD5Pass: wire.WeakMD5PasswordHash("testPassword", "testAuthKey"),
+	}
+	assert.True(t, u.LegacyHashOnly())
+
+	require.NoError(t, u.UpgradePasswordHash([]byte("testPassword")))
+	assert.False(t, u.LegacyHashOnly())
+	assert.True(t, u.ValidatePlaintextPass([]byte("testPassword")))
+	assert.False(t, u.ValidatePlaintextPass([]byte("wrongPassword")))
+}
+
+func bcryptHash(t *testing.T, password string) string {
+	u := User{}
+	require.NoError(t, u.UpgradePasswordHash([]byte(password)))
+	return u.PasswordHash
+}
@@ -82,31 +82,115 @@ func (s *WebAPITokenStore) CleanupExpiredTokens(ctx context.Context) error {
 	return nil
 }
 
-// AuthenticateUser verifies username and password.
-// This implementation uses the existing user store for authentication.
-func (u *SQLiteUserStore) AuthenticateUser(ctx context.Context, username, password string) (*User, error) {
-	// Convert username to IdentScreenName for lookup
-	identSN := NewIdentScreenName(username)
-
-	// Try to find the user
-	user, err := u.User(ctx, identSN)
+// AuthenticateUser verifies a plaintext password for the user identified by
+// username. The password is checked against provider if set, otherwise
+// against the stored password hashes. Like the OSCAR login flow, the
+// suspension check precedes the password check. Users enrolled in two-factor
+// auth must supply a TOTP or recovery code, either in securID or appended to
+// the password. It returns ErrNoUser if the user does not exist and there's
+// no provider to create it from, ErrUserSuspended if the account is
+// suspended, ErrBadCredentials if the password does not match, and
+// ErrBadSecurID if the one-time code is missing or invalid.
+//
+// Users that only have legacy MD5 hashes get a bcrypt hash upon successful
+// authentication. When provider is set, users that exist in the provider
+// but not in the user store are created on the fly, and the local password
+// hashes are refreshed whenever they don't match the provider's password.
+func (u *SQLiteUserStore) AuthenticateUser(ctx context.Context, provider AuthProvider, username, password, securID string) (*User, error) {
+	user, err := u.User(ctx, NewIdentScreenName(username))
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user == nil {
+		if provider == nil {
+			return nil, ErrNoUser
+		}
+		return u.provisionExternalUser(ctx, provider, DisplayScreenName(username), password)
+	}
+
+	if user.SuspendedStatus > 0 {
+		return nil, ErrUserSuspended
 	}
 
-	// In development mode with DISABLE_AUTH=true, accept any password
-	// In production, this would verify the password hash
-	// For now, we'll accept any non-empty password if the user exists
 	if password == "" {
-		return nil, errors.New("password required")
+		return nil, ErrBadCredentials
+	}
+
+	validate := func(candidate []byte) (bool, error) {
+		if provider != nil {
+			return provider.Authenticate(ctx, user.DisplayScreenName, candidate)
+		}
+		return user.ValidatePlaintextPass(candidate), nil
+	}
+
+	pass := []byte(password)
+	code := securID
+	var ok bool
+	if user.TOTPEnabled() && code == "" {
+		ok, pass, code, err = ValidatePassWithCode(pass, validate)
+	} else {
+		ok, err = validate(pass)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error validating password: %w", err)
+	}
+	if !ok {
+		return nil, ErrBadCredentials
 	}
 
-	// TODO: In production, verify password hash here
-	// For development with DISABLE_AUTH, we just check if user exists
+	if user.TOTPEnabled() {
+		valid, err := u.ValidateSecondFactor(ctx, *user, code)
+		if err != nil {
+			return nil, fmt.Errorf("error validating second factor: %w", err)
+		}
+		if !valid {
+			return nil, ErrBadSecurID
+		}
+	}
+
+	switch {
+	case provider != nil && !user.ValidatePlaintextPass(pass):
+		if err := user.HashExternalPassword(pass); err != nil {
+			return nil, err
+		}
+		if err := u.SetPasswordHashes(ctx, *user); err != nil {
+			return nil, fmt.Errorf("error refreshing password hashes: %w", err)
+		}
+	case user.LegacyHashOnly():
+		if err := user.UpgradePasswordHash(pass); err != nil {
+			return nil, err
+		}
+		if err := u.SetPasswordHash(ctx, user.IdentScreenName, user.PasswordHash); err != nil {
+			return nil, fmt.Errorf("error upgrading password hash: %w", err)
+		}
+	}
 
 	return user, nil
 }
 
+// provisionExternalUser creates a user that exists in provider but not in
+// the user store. It returns ErrBadCredentials if the provider rejects the
+// credentials, so that callers count the attempt toward the login lockout.
+func (u *SQLiteUserStore) provisionExternalUser(ctx context.Context, provider AuthProvider, screenName DisplayScreenName, password string) (*User, error) {
+	ok, err := provider.Authenticate(ctx, screenName, []byte(password))
+	if err != nil {
+		return nil, fmt.Errorf("error validating password: %w", err)
+	}
+	if !ok {
+		return nil, ErrBadCredentials
+	}
+
+	newUser, err := NewExternalUser(screenName, []byte(password))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadCredentials, err)
+	}
+	if err := u.InsertUser(ctx, newUser); err != nil {
+		return nil, fmt.Errorf("error provisioning user: %w", err)
+	}
+
+	return &newUser, nil
+}
+
 // FindUserByScreenName finds a user by their screen name.
 // This is just an alias for the User method to satisfy the UserManager interface.
 func (u *SQLiteUserStore) FindUserByScreenName(ctx context.Context, screenName IdentScreenName) (*User, error) {
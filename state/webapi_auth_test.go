@@ -0,0 +1,267 @@
+package state
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mk6i/retro-aim-server/wire"
+)
+
+func TestSQLiteUserStore_AuthenticateUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		suspended  uint16
+		username   string
+		password   string
+		wantErr    error
+		wantScreen DisplayScreenName
+	}{
+		{
+			name:       "correct password",
+			username:   "the user",
+			password:   "thepassword",
+			wantScreen: "theUser",
+		},
+		{
+			name:     "wrong password",
+			username: "theuser",
+			password: "notthepassword",
+			wantErr:  ErrBadCredentials,
+		},
+		{
+			name:     "empty password",
+			username: "theuser",
+			password: "",
+			wantErr:  ErrBadCredentials,
+		},
+		{
+			name:     "user does not exist",
+			username: "someoneelse",
+			password: "thepassword",
+			wantErr:  ErrNoUser,
+		},
+		{
+			name:      "suspended user",
+			suspended: wire.LoginErrSuspendedAccount,
+			username:  "theuser",
+			password:  "thepassword",
+			wantErr:   ErrUserSuspended,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				assert.NoError(t, os.Remove(testFile))
+			}()
+
+			userStore, err := NewSQLiteUserStore(testFile)
+			require.NoError(t, err)
+
+			u := User{
+				IdentScreenName:   NewIdentScreenName("theUser"),
+				DisplayScreenName: "theUser",
+				AuthKey:           "theAuthKey",
+			}
+			require.NoError(t, u.HashPassword("thepassword"))
+			require.NoError(t, userStore.InsertUser(context.Background(), u))
+
+			if tt.suspended > 0 {
+				err = userStore.UpdateSuspendedStatus(context.Background(), tt.suspended, u.IdentScreenName)
+				require.NoError(t, err)
+			}
+
+			have, err := userStore.AuthenticateUser(context.Background(), nil, tt.username, tt.password, "")
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				require.NotNil(t, have)
+				assert.Equal(t, tt.wantScreen, have.DisplayScreenName)
+			} else {
+				assert.Nil(t, have)
+			}
+		})
+	}
+}
+
+func TestSQLiteUserStore_AuthenticateUser_UpgradesLegacyHash(t *testing.T) {
+	defer func() {
+		assert.NoError(t, os.Remove(testFile))
+	}()
+
+	userStore, err := NewSQLiteUserStore(testFile)
+	require.NoError(t, err)
+
+	u := User{
+		IdentScreenName:   NewIdentScreenName("theUser"),
+		DisplayScreenName: "theUser",
+		AuthKey:           "theAuthKey",
+		WeakMD5Pass:       wire.WeakMD5PasswordHash("thepassword", "theAuthKey"),
+		StrongMD5Pass:     wire.StrongMD5PasswordHash("thepassword", "theAuthKey"),
+	}
+	require.NoError(t, userStore.InsertUser(context.Background(), u))
+
+	_, err = userStore.AuthenticateUser(context.Background(), nil, "theUser", "thepassword", "")
+	require.NoError(t, err)
+
+	have, err := userStore.User(context.Background(), u.IdentScreenName)
+	require.NoError(t, err)
+	assert.False(t, have.LegacyHashOnly())
+	assert.True(t, have.ValidatePlaintextPass([]byte("thepassword")))
+	// MD5 hashes remain for BUCP clients
+	assert.True(t, have.ValidateHash(u.StrongMD5Pass))
+}
+
+func TestSQLiteUserStore_AuthenticateUser_TOTP(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	require.NoError(t, err)
+	code := totpCode(key, uint64(time.Now().Unix()/int64(totpPeriod/time.Second)))
+
+	tests := []struct {
+		name     string
+		password string
+		securID  string
+		wantErr  error
+	}{
+		{
+			name:     "code in securID",
+			password: "thepassword",
+			securID:  code,
+		},
+		{
+			name:     "code appended to password",
+			password: "thepassword" + code,
+		},
+		{
+			name:     "recovery code appended to password",
+			password: "thepasswordaaaaaaaaaa",
+		},
+		{
+			name:     "missing code",
+			password: "thepassword",
+			wantErr:  ErrBadSecurID,
+		},
+		{
+			name:     "wrong code",
+			password: "thepassword",
+			securID:  "000000",
+			wantErr:  ErrBadSecurID,
+		},
+		{
+			name:     "wrong password with valid code",
+			password: "notthepassword",
+			securID:  code,
+			wantErr:  ErrBadCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				assert.NoError(t, os.Remove(testFile))
+			}()
+
+			userStore, err := NewSQLiteUserStore(testFile)
+			require.NoError(t, err)
+
+			u := User{
+				IdentScreenName:   NewIdentScreenName("theUser"),
+				DisplayScreenName: "theUser",
+				AuthKey:           "theAuthKey",
+			}
+			require.NoError(t, u.HashPassword("thepassword"))
+			require.NoError(t, userStore.InsertUser(context.Background(), u))
+			err = userStore.SetTOTP(context.Background(), u.IdentScreenName, rfc6238Secret, []string{"aaaaaaaaaa"})
+			require.NoError(t, err)
+
+			have, err := userStore.AuthenticateUser(context.Background(), nil, "theUser", tt.password, tt.securID)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.NotNil(t, have)
+			}
+		})
+	}
+}
+
+// staticAuthProvider is an AuthProvider that accepts a fixed set of
+// passwords keyed by screen name.
+type staticAuthProvider map[IdentScreenName]string
+
+func (p staticAuthProvider) Authenticate(_ context.Context, screenName DisplayScreenName, password []byte) (bool, error) {
+	pass, ok := p[screenName.IdentScreenName()]
+	return ok && pass == string(password), nil
+}
+
+func TestSQLiteUserStore_AuthenticateUser_AuthProvider(t *testing.T) {
+	provider := staticAuthProvider{
+		NewIdentScreenName("theUser"): "thenewpassword",
+		NewIdentScreenName("newUser"): "thepassword",
+	}
+
+	t.Run("existing user is verified against provider and hashes are refreshed", func(t *testing.T) {
+		defer func() {
+			assert.NoError(t, os.Remove(testFile))
+		}()
+
+		userStore, err := NewSQLiteUserStore(testFile)
+		require.NoError(t, err)
+
+		u := User{
+			IdentScreenName:   NewIdentScreenName("theUser"),
+			DisplayScreenName: "theUser",
+			AuthKey:           "theAuthKey",
+		}
+		require.NoError(t, u.HashPassword("theoldpassword"))
+		require.NoError(t, userStore.InsertUser(context.Background(), u))
+
+		_, err = userStore.AuthenticateUser(context.Background(), provider, "theUser", "theoldpassword", "")
+		assert.ErrorIs(t, err, ErrBadCredentials)
+
+		_, err = userStore.AuthenticateUser(context.Background(), provider, "theUser", "thenewpassword", "")
+		require.NoError(t, err)
+
+		have, err := userStore.User(context.Background(), u.IdentScreenName)
+		require.NoError(t, err)
+		assert.True(t, have.ValidatePlaintextPass([]byte("thenewpassword")))
+		// MD5 hashes are refreshed for BUCP clients
+		assert.True(t, have.ValidateHash(wire.StrongMD5PasswordHash("thenewpassword", u.AuthKey)))
+	})
+
+	t.Run("unknown user is provisioned from provider", func(t *testing.T) {
+		defer func() {
+			assert.NoError(t, os.Remove(testFile))
+		}()
+
+		userStore, err := NewSQLiteUserStore(testFile)
+		require.NoError(t, err)
+
+		have, err := userStore.AuthenticateUser(context.Background(), provider, "newUser", "thepassword", "")
+		require.NoError(t, err)
+		assert.Equal(t, DisplayScreenName("newUser"), have.DisplayScreenName)
+
+		stored, err := userStore.User(context.Background(), NewIdentScreenName("newUser"))
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		assert.True(t, stored.ValidatePlaintextPass([]byte("thepassword")))
+	})
+
+	t.Run("unknown user rejected by provider", func(t *testing.T) {
+		defer func() {
+			assert.NoError(t, os.Remove(testFile))
+		}()
+
+		userStore, err := NewSQLiteUserStore(testFile)
+		require.NoError(t, err)
+
+		_, err = userStore.AuthenticateUser(context.Background(), provider, "someoneElse", "thepassword", "")
+		assert.ErrorIs(t, err, ErrBadCredentials)
+
+		stored, err := userStore.User(context.Background(), NewIdentScreenName("someoneElse"))
+		require.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+}
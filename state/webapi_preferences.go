@@ -81,8 +81,13 @@ func (m *WebPermitDenyManager) SetPDMode(ctx context.Context, screenName IdentSc
 		ON CONFLICT (screenName)
 		DO UPDATE SET clientSidePDMode = excluded.clientSidePDMode
 	`
-	_, err := m.store.db.ExecContext(ctx, q, screenName.String(), int(mode))
-	return err
+	write := func() error {
+		_, err := m.store.db.ExecContext(ctx, q, screenName.String(), int(mode))
+		return err
+	}
+	return m.store.writeRelationships(ctx, screenName, write, func(c *relationshipCache) error {
+		return m.store.reloadRelUsers(ctx, screenName)
+	})
 }
 
 // GetPDMode retrieves the permit/deny mode for a user.
@@ -159,8 +164,14 @@ func (m *WebPermitDenyManager) AddPermitBuddy(ctx context.Context, me IdentScree
 		VALUES (?, ?, 1)
 		ON CONFLICT (me, them) DO UPDATE SET isPermit = 1
 	`
-	_, err := m.store.db.ExecContext(ctx, q, me.String(), them.String())
-	return err
+	write := func() error {
+		_, err := m.store.db.ExecContext(ctx, q, me.String(), them.String())
+		return err
+	}
+	return m.store.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		c.upsertClientSide(me, them, func(flags *relFlags) { flags.permit = true })
+		return nil
+	})
 }
 
 // RemovePermitBuddy removes a user from the permit list.
@@ -170,8 +181,14 @@ func (m *WebPermitDenyManager) RemovePermitBuddy(ctx context.Context, me IdentSc
 		SET isPermit = 0
 		WHERE me = ? AND them = ?
 	`
-	_, err := m.store.db.ExecContext(ctx, q, me.String(), them.String())
-	return err
+	write := func() error {
+		_, err := m.store.db.ExecContext(ctx, q, me.String(), them.String())
+		return err
+	}
+	return m.store.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		c.updateClientSide(me, them, func(flags *relFlags) { flags.permit = false })
+		return nil
+	})
 }
 
 // AddDenyBuddy adds a user to the deny list.
@@ -181,8 +198,14 @@ func (m *WebPermitDenyManager) AddDenyBuddy(ctx context.Context, me IdentScreenN
 		VALUES (?, ?, 1)
 		ON CONFLICT (me, them) DO UPDATE SET isDeny = 1
 	`
-	_, err := m.store.db.ExecContext(ctx, q, me.String(), them.String())
-	return err
+	write := func() error {
+		_, err := m.store.db.ExecContext(ctx, q, me.String(), them.String())
+		return err
+	}
+	return m.store.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		c.upsertClientSide(me, them, func(flags *relFlags) { flags.deny = true })
+		return nil
+	})
 }
 
 // RemoveDenyBuddy removes a user from the deny list.
@@ -192,6 +215,12 @@ func (m *WebPermitDenyManager) RemoveDenyBuddy(ctx context.Context, me IdentScre
 		SET isDeny = 0
 		WHERE me = ? AND them = ?
 	`
-	_, err := m.store.db.ExecContext(ctx, q, me.String(), them.String())
-	return err
+	write := func() error {
+		_, err := m.store.db.ExecContext(ctx, q, me.String(), them.String())
+		return err
+	}
+	return m.store.writeRelationships(ctx, me, write, func(c *relationshipCache) error {
+		c.updateClientSide(me, them, func(flags *relFlags) { flags.deny = false })
+		return nil
+	})
 }
@@ -102,9 +102,40 @@ func (s *WebAPISession) handleSNACMessage(msg wire.SNACMessage) {
 		s.handleICBMMessage(msg)
 	case wire.Buddy:
 		s.handleBuddyMessage(msg)
+	case wire.OService:
+		s.handleOServiceMessage(msg)
 	}
 }
 
+// handleOServiceMessage handles OService SNAC messages.
+func (s *WebAPISession) handleOServiceMessage(msg wire.SNACMessage) {
+	if msg.Frame.SubGroup != wire.OServiceMotd {
+		return
+	}
+	body, ok := msg.Body.(wire.SNAC_0x01_0x13_OServiceMotd)
+	if !ok {
+		return
+	}
+	if motd, hasMsg := body.String(wire.OServiceTLVTagsMotdMessage); hasMsg && motd != "" {
+		s.PushMOTD(motd)
+	}
+}
+
+// PushMOTD delivers the message of the day as an IM event from
+// MOTDScreenName, since Web AIM clients have no dedicated message of the day
+// display.
+func (s *WebAPISession) PushMOTD(message string) {
+	if s.EventQueue == nil || !s.IsSubscribedTo("im") {
+		return
+	}
+
+	s.EventQueue.Push(types.EventTypeIM, types.IMEvent{
+		From:      MOTDScreenName.String(),
+		Message:   message,
+		Timestamp: float64(time.Now().Unix()),
+	})
+}
+
 // handleICBMMessage handles ICBM (instant messaging) SNAC messages.
 func (s *WebAPISession) handleICBMMessage(msg wire.SNACMessage) {
 	switch msg.Frame.SubGroup {
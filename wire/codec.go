@@ -0,0 +1,285 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+//go:generate go run ../cmd/marshal_generator -o marshal_gen.go -test marshal_gen_test.go frames.go snacs.go tlv.go
+
+// This file contains the runtime support for the reflection-free encoders
+// and decoders in marshal_gen.go. MarshalBE, MarshalLE, UnmarshalBE and
+// UnmarshalLE use the generated code for the types it covers and fall back
+// to reflection for everything else. The generated code must produce the
+// same bytes and values as the reflective path, including the way it
+// reports truncated input.
+
+// byteOrder encodes integers in a fixed byte order.
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// maxPooledBufSize is the capacity beyond which encode buffers are not
+// returned to bufPool.
+const maxPooledBufSize = 64 * 1024
+
+// bufPool holds scratch buffers for encoding to writers other than
+// *bytes.Buffer.
+var bufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 512)
+		return &b
+	},
+}
+
+// marshalGenerated encodes v with its generated encoder. It returns false if
+// v has no generated encoder.
+func marshalGenerated(v any, w io.Writer, order byteOrder) (bool, error) {
+	if buf, ok := w.(*bytes.Buffer); ok {
+		// encode straight into the buffer's spare capacity
+		b, ok := appendGenerated(v, buf.AvailableBuffer(), order)
+		if !ok {
+			return false, nil
+		}
+		_, err := buf.Write(b)
+		return true, err
+	}
+
+	bp := bufPool.Get().(*[]byte)
+	b, ok := appendGenerated(v, (*bp)[:0], order)
+	if !ok {
+		bufPool.Put(bp)
+		return false, nil
+	}
+	_, err := w.Write(b)
+	// don't hang on to the occasional huge buffer
+	if cap(b) <= maxPooledBufSize {
+		*bp = b[:0]
+		bufPool.Put(bp)
+	}
+	return true, err
+}
+
+// unmarshalGenerated decodes into v with its generated decoder. It returns
+// false if v has no generated decoder.
+func unmarshalGenerated(v any, r io.Reader, order binary.ByteOrder, bnd bounds) (bool, error) {
+	return decodeGenerated(v, &decoder{r: r, order: order, bounds: bnd})
+}
+
+// decoder reads the fields of a message in sequence.
+type decoder struct {
+	r       io.Reader
+	order   binary.ByteOrder
+	scratch [8]byte
+	// sub is set when the decoder reads a length-prefixed block, which
+	// tells how much of the block is left.
+	sub    *bytes.Reader
+	bounds bounds
+}
+
+// more indicates whether a length-prefixed block has bytes left to read.
+func (d *decoder) more() bool {
+	return d.sub.Len() > 0
+}
+
+// read fills the first n bytes of the scratch buffer.
+func (d *decoder) read(n int) ([]byte, error) {
+	if _, err := io.ReadFull(d.r, d.scratch[:n]); err != nil {
+		return nil, err
+	}
+	return d.scratch[:n], nil
+}
+
+// readLen reads a uint8 or uint16 length or count prefix.
+func (d *decoder) readLen(size int) (int, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	if size == 1 {
+		return int(b[0]), nil
+	}
+	return int(d.order.Uint16(b)), nil
+}
+
+// readBytes reads a length-prefixed blob. It returns nil for an empty blob.
+func (d *decoder) readBytes(size int) ([]byte, error) {
+	n, err := d.readLen(size)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.bounds.checkLen(n); err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// readBlock reads a length-prefixed blob and returns a decoder for its
+// contents.
+func (d *decoder) readBlock(size int) (*decoder, error) {
+	n, err := d.readLen(size)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.bounds.checkLen(n); err != nil {
+		return nil, err
+	}
+	bnd, err := d.bounds.nested()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return nil, err
+	}
+	br := bytes.NewReader(b)
+	return &decoder{r: br, order: d.order, sub: br, bounds: bnd}, nil
+}
+
+// checkListLen checks the number of elements in a list.
+func (d *decoder) checkListLen(n int) error {
+	return d.bounds.checkListLen(n)
+}
+
+// readElems reads n single-byte elements. The reflective decoder reads
+// elements one at a time, so running out of input partway through is
+// reported as io.EOF rather than io.ErrUnexpectedEOF.
+func (d *decoder) readElems(dst []byte) error {
+	if _, err := io.ReadFull(d.r, dst); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return io.EOF
+		}
+		return err
+	}
+	return nil
+}
+
+// readRest reads everything up to the end of input. It returns nil if there
+// is nothing left.
+func (d *decoder) readRest() ([]byte, error) {
+	b, err := io.ReadAll(d.r)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return b, nil
+}
+
+func decodeUint8[T ~uint8](d *decoder, dst *T) error {
+	b, err := d.read(1)
+	if err != nil {
+		return err
+	}
+	*dst = T(b[0])
+	return nil
+}
+
+func decodeUint16[T ~uint16](d *decoder, dst *T) error {
+	b, err := d.read(2)
+	if err != nil {
+		return err
+	}
+	*dst = T(d.order.Uint16(b))
+	return nil
+}
+
+func decodeUint32[T ~uint32](d *decoder, dst *T) error {
+	b, err := d.read(4)
+	if err != nil {
+		return err
+	}
+	*dst = T(d.order.Uint32(b))
+	return nil
+}
+
+func decodeUint64[T ~uint64](d *decoder, dst *T) error {
+	b, err := d.read(8)
+	if err != nil {
+		return err
+	}
+	*dst = T(d.order.Uint64(b))
+	return nil
+}
+
+// decodeString reads a string with a size-byte length prefix, removing the
+// null terminator if nullTerm is set.
+func decodeString[T ~string](d *decoder, dst *T, size int, nullTerm bool) error {
+	n, err := d.readLen(size)
+	if err != nil {
+		return err
+	}
+	if err := d.bounds.checkLen(n); err != nil {
+		return err
+	}
+	if n == 0 {
+		*dst = ""
+		return nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return err
+	}
+	if nullTerm {
+		if b[n-1] != 0x00 {
+			return errNotNullTerminated
+		}
+		b = b[:n-1]
+	}
+	*dst = T(b)
+	return nil
+}
+
+// appendLen appends a size-byte length or count prefix. Like the reflective
+// encoder, it truncates values that don't fit.
+func appendLen(b []byte, order byteOrder, size int, n int) []byte {
+	if size == 1 {
+		return append(b, uint8(n))
+	}
+	return order.AppendUint16(b, uint16(n))
+}
+
+// putLen sets the size-byte length prefix at b[at:] to the number of bytes
+// that follow it.
+func putLen(b []byte, order byteOrder, size int, at int) {
+	n := len(b) - at - size
+	if size == 1 {
+		b[at] = uint8(n)
+	} else {
+		order.PutUint16(b[at:], uint16(n))
+	}
+}
+
+// appendString appends s with a size-byte length prefix, adding a null
+// terminator to non-empty strings if nullTerm is set.
+func appendString(b []byte, order byteOrder, s string, size int, nullTerm bool) []byte {
+	n := len(s)
+	if nullTerm && s != "" {
+		n++
+	}
+	b = appendLen(b, order, size, n)
+	b = append(b, s...)
+	if nullTerm && s != "" {
+		b = append(b, 0x00)
+	}
+	return b
+}
+
+// newOf returns a pointer to a new zero value of the type p points to. It
+// lets generated code allocate anonymous struct types without spelling them
+// out.
+func newOf[T any](p *T) *T {
+	return new(T)
+}
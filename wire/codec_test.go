@@ -0,0 +1,255 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fillRandom sets v to a random value. Slices are either nil or non-empty,
+// since that's how the decoders represent them, and optional structs are
+// set half of the time.
+func fillRandom(rnd *rand.Rand, v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(rnd.Uint64())
+	case reflect.String:
+		b := make([]byte, rnd.Intn(10))
+		for i := range b {
+			b[i] = byte('a' + rnd.Intn(26))
+		}
+		v.SetString(string(b))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fillRandom(rnd, v.Index(i), depth)
+		}
+	case reflect.Slice:
+		if depth > 3 || rnd.Intn(4) == 0 {
+			return
+		}
+		n := 1 + rnd.Intn(3)
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			fillRandom(rnd, v.Index(i), depth+1)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fillRandom(rnd, v.Field(i), depth)
+		}
+	case reflect.Ptr:
+		if depth > 3 || rnd.Intn(2) == 0 {
+			return
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		fillRandom(rnd, v.Elem(), depth+1)
+	default:
+		panic(fmt.Sprintf("unsupported kind %s", v.Kind()))
+	}
+}
+
+// reflectMarshal encodes v with the reflective encoder.
+func reflectMarshal(v any, order binary.ByteOrder) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := marshal(reflect.TypeOf(v), reflect.ValueOf(v), "", buf, order)
+	return buf.Bytes(), err
+}
+
+// assertSameDecode decodes b with the reflective and generated decoders
+// and checks that they agree.
+func assertSameDecode(t *testing.T, typ reflect.Type, b []byte, order binary.ByteOrder, limits DecodeLimits) {
+	t.Helper()
+
+	want := reflect.New(typ)
+	wantErr := unmarshal(typ, want.Elem(), "", bytes.NewReader(b), order, bounds{DecodeLimits: limits})
+
+	have := reflect.New(typ)
+	ok, haveErr := unmarshalGenerated(have.Interface(), bytes.NewReader(b), order, bounds{DecodeLimits: limits})
+	require.True(t, ok)
+
+	if wantErr != nil {
+		require.Error(t, haveErr, "input: %x", b)
+		assert.Equal(t, errors.Is(wantErr, io.EOF), errors.Is(haveErr, io.EOF), "input: %x", b)
+		assert.Equal(t, errors.Is(wantErr, io.ErrUnexpectedEOF), errors.Is(haveErr, io.ErrUnexpectedEOF), "input: %x", b)
+		assert.Equal(t, errors.Is(wantErr, ErrDecodeLimitExceeded), errors.Is(haveErr, ErrDecodeLimitExceeded), "input: %x", b)
+		return
+	}
+	require.NoError(t, haveErr, "input: %x", b)
+	assert.Equal(t, want.Interface(), have.Interface(), "input: %x", b)
+}
+
+func TestGenerated_Equivalence(t *testing.T) {
+	orders := []byteOrder{binary.BigEndian, binary.LittleEndian}
+	// tight limits that random messages often exceed
+	limits := DecodeLimits{MaxTLVSize: 6, MaxListLen: 2, MaxDepth: 2}
+
+	for _, sample := range generatedTypes {
+		typ := reflect.TypeOf(sample)
+		t.Run(typ.Name(), func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 50; i++ {
+				v := reflect.New(typ).Elem()
+				if i > 0 {
+					// the first round checks the zero value
+					fillRandom(rnd, v, 0)
+				}
+				for _, order := range orders {
+					want, err := reflectMarshal(v.Interface(), order)
+					require.NoError(t, err)
+
+					have, ok := appendGenerated(v.Interface(), nil, order)
+					require.True(t, ok)
+					assert.Equal(t, want, have)
+
+					// decode the whole message and every truncation of it
+					for end := len(want); end >= 0; end-- {
+						assertSameDecode(t, typ, want[:end], order, DecodeLimits{})
+					}
+					assertSameDecode(t, typ, want, order, limits)
+
+					// decode garbage
+					garbage := make([]byte, rnd.Intn(64))
+					rnd.Read(garbage)
+					assertSameDecode(t, typ, garbage, order, DecodeLimits{})
+					assertSameDecode(t, typ, garbage, order, limits)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerated_Fallback(t *testing.T) {
+	// types without a generated encoder go through the reflective path
+	_, ok := appendGenerated(ICQMessageReplyEnvelope{}, nil, binary.BigEndian)
+	assert.False(t, ok)
+	_, ok = appendGenerated(&SNAC_0x01_0x02_OServiceClientOnline{}, nil, binary.BigEndian)
+	assert.False(t, ok)
+
+	ok, err := unmarshalGenerated(&SNACMessage{}, bytes.NewReader(nil), binary.BigEndian, bounds{})
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	// anonymous structs aren't generated
+	buf := &bytes.Buffer{}
+	assert.NoError(t, MarshalBE(struct{ Val uint16 }{Val: 1}, buf))
+	assert.Equal(t, []byte{0x00, 0x01}, buf.Bytes())
+}
+
+func TestGenerated_NonBufferWriter(t *testing.T) {
+	snac := SNAC_0x01_0x0F_OServiceUserInfoUpdate{
+		UserInfo: []TLVUserInfo{{ScreenName: "user1", WarningLevel: 10}},
+	}
+	want, err := reflectMarshal(snac, binary.BigEndian)
+	assert.NoError(t, err)
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(MarshalBE(snac, pw))
+	}()
+	have, err := io.ReadAll(pr)
+	assert.NoError(t, err)
+	assert.Equal(t, want, have)
+
+	assert.ErrorIs(t, MarshalBE(snac, errWriter{}), ErrMarshalFailure)
+}
+
+// benchmarkMessages are common messages on the hot path.
+var benchmarkMessages = []any{
+	FLAPFrame{
+		StartMarker: 42,
+		FrameType:   FLAPFrameData,
+		Sequence:    1234,
+		Payload:     bytes.Repeat([]byte{0x01}, 64),
+	},
+	SNAC_0x03_0x0B_BuddyArrived{
+		TLVUserInfo: TLVUserInfo{
+			ScreenName:   "screenname",
+			WarningLevel: 10,
+			TLVBlock: TLVBlock{
+				TLVList: TLVList{
+					NewTLVBE(OServiceUserInfoUserFlags, uint16(0x0010)),
+					NewTLVBE(OServiceUserInfoSignonTOD, uint32(1234567890)),
+					NewTLVBE(OServiceUserInfoIdleTime, uint16(0)),
+					NewTLVBE(OServiceUserInfoStatus, uint32(0)),
+				},
+			},
+		},
+	},
+	SNAC_0x04_0x07_ICBMChannelMsgToClient{
+		Cookie:    1234,
+		ChannelID: ICBMChannelIM,
+		TLVUserInfo: TLVUserInfo{
+			ScreenName:   "screenname",
+			WarningLevel: 10,
+		},
+		TLVRestBlock: TLVRestBlock{
+			TLVList: TLVList{
+				NewTLVBE(ICBMTLVAOLIMData, bytes.Repeat([]byte{0x02}, 128)),
+				NewTLVBE(ICBMTLVWantEvents, []byte{}),
+			},
+		},
+	},
+}
+
+func BenchmarkMarshalBE(b *testing.B) {
+	for _, msg := range benchmarkMessages {
+		b.Run(reflect.TypeOf(msg).Name()+"/reflective", func(b *testing.B) {
+			buf := &bytes.Buffer{}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				if err := marshal(reflect.TypeOf(msg), reflect.ValueOf(msg), "", buf, binary.BigEndian); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(reflect.TypeOf(msg).Name()+"/generated", func(b *testing.B) {
+			buf := &bytes.Buffer{}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				if err := MarshalBE(msg, buf); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshalBE(b *testing.B) {
+	for _, msg := range benchmarkMessages {
+		buf := &bytes.Buffer{}
+		if err := MarshalBE(msg, buf); err != nil {
+			b.Fatal(err)
+		}
+		typ := reflect.TypeOf(msg)
+		r := bytes.NewReader(buf.Bytes())
+
+		b.Run(typ.Name()+"/reflective", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.Reset(buf.Bytes())
+				v := reflect.New(typ)
+				if err := unmarshal(typ, v.Elem(), "", r, binary.BigEndian, bounds{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(typ.Name()+"/generated", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.Reset(buf.Bytes())
+				if err := UnmarshalBE(reflect.New(typ).Interface(), r); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -16,7 +16,14 @@ var (
 
 // UnmarshalBE unmarshalls OSCAR protocol messages in big-endian format.
 func UnmarshalBE(v any, r io.Reader) error {
-	if err := unmarshal(reflect.TypeOf(v).Elem(), reflect.ValueOf(v).Elem(), "", r, binary.BigEndian); err != nil {
+	r, limits := decodeLimitsOf(r)
+	if ok, err := unmarshalGenerated(v, r, binary.BigEndian, bounds{DecodeLimits: limits}); ok {
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrUnmarshalFailure, err)
+		}
+		return nil
+	}
+	if err := unmarshal(reflect.TypeOf(v).Elem(), reflect.ValueOf(v).Elem(), "", r, binary.BigEndian, bounds{DecodeLimits: limits}); err != nil {
 		return fmt.Errorf("%w: %w", ErrUnmarshalFailure, err)
 	}
 	return nil
@@ -24,14 +31,21 @@ func UnmarshalBE(v any, r io.Reader) error {
 
 // UnmarshalLE unmarshalls OSCAR protocol messages in little-endian format.
 func UnmarshalLE(v any, r io.Reader) error {
-	if err := unmarshal(reflect.TypeOf(v).Elem(), reflect.ValueOf(v).Elem(), "", r, binary.LittleEndian); err != nil {
+	r, limits := decodeLimitsOf(r)
+	if ok, err := unmarshalGenerated(v, r, binary.LittleEndian, bounds{DecodeLimits: limits}); ok {
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrUnmarshalFailure, err)
+		}
+		return nil
+	}
+	if err := unmarshal(reflect.TypeOf(v).Elem(), reflect.ValueOf(v).Elem(), "", r, binary.LittleEndian, bounds{DecodeLimits: limits}); err != nil {
 		return fmt.Errorf("%w: %w", ErrUnmarshalFailure, err)
 	}
 	return nil
 }
 
 // MarshalLE marshals ICQ protocol messages in little-endian format.
-func unmarshal(t reflect.Type, v reflect.Value, tag reflect.StructTag, r io.Reader, order binary.ByteOrder) error {
+func unmarshal(t reflect.Type, v reflect.Value, tag reflect.StructTag, r io.Reader, order binary.ByteOrder, bnd bounds) error {
 	oscTag, err := parseOSCARTag(tag)
 	if err != nil {
 		return fmt.Errorf("error parsing tag: %w", err)
@@ -39,7 +53,7 @@ func unmarshal(t reflect.Type, v reflect.Value, tag reflect.StructTag, r io.Read
 
 	if oscTag.optional {
 		v.Set(reflect.New(t.Elem()))
-		err := unmarshalStruct(t.Elem(), v.Elem(), oscTag, r, order)
+		err := unmarshalStruct(t.Elem(), v.Elem(), oscTag, r, order, bnd)
 		if errors.Is(err, io.EOF) {
 			// no values to read, but that's ok since this struct is optional
 			v.Set(reflect.Zero(t))
@@ -52,13 +66,13 @@ func unmarshal(t reflect.Type, v reflect.Value, tag reflect.StructTag, r io.Read
 
 	switch v.Kind() {
 	case reflect.Array:
-		return unmarshalArray(v, r, order)
+		return unmarshalArray(v, r, order, bnd)
 	case reflect.Slice:
-		return unmarshalSlice(v, oscTag, r, order)
+		return unmarshalSlice(v, oscTag, r, order, bnd)
 	case reflect.String:
-		return unmarshalString(v, oscTag, r, order)
+		return unmarshalString(v, oscTag, r, order, bnd)
 	case reflect.Struct:
-		return unmarshalStruct(t, v, oscTag, r, order)
+		return unmarshalStruct(t, v, oscTag, r, order, bnd)
 	case reflect.Uint8:
 		var l uint8
 		if err := binary.Read(r, order, &l); err != nil {
@@ -92,13 +106,13 @@ func unmarshal(t reflect.Type, v reflect.Value, tag reflect.StructTag, r io.Read
 	}
 }
 
-func unmarshalArray(v reflect.Value, r io.Reader, order binary.ByteOrder) error {
+func unmarshalArray(v reflect.Value, r io.Reader, order binary.ByteOrder, bnd bounds) error {
 	arrLen := v.Len()
 	arrType := v.Type().Elem()
 
 	for i := 0; i < arrLen; i++ {
 		elem := reflect.New(arrType).Elem()
-		if err := unmarshal(arrType, elem, "", r, order); err != nil {
+		if err := unmarshal(arrType, elem, "", r, order, bnd); err != nil {
 			return err
 		}
 		v.Index(i).Set(elem)
@@ -107,7 +121,7 @@ func unmarshalArray(v reflect.Value, r io.Reader, order binary.ByteOrder) error
 	return nil
 }
 
-func unmarshalSlice(v reflect.Value, oscTag oscarTag, r io.Reader, order binary.ByteOrder) error {
+func unmarshalSlice(v reflect.Value, oscTag oscarTag, r io.Reader, order binary.ByteOrder, bnd bounds) error {
 	slice := reflect.New(v.Type()).Elem()
 	elemType := v.Type().Elem()
 
@@ -116,6 +130,16 @@ func unmarshalSlice(v reflect.Value, oscTag oscarTag, r io.Reader, order binary.
 		if err != nil {
 			return err
 		}
+		if err := bnd.checkLen(bufLen); err != nil {
+			return err
+		}
+		inner := bnd
+		if elemType.Kind() != reflect.Uint8 {
+			// a byte slice is a blob rather than a nested block
+			if inner, err = bnd.nested(); err != nil {
+				return err
+			}
+		}
 		b := make([]byte, bufLen)
 		if bufLen > 0 {
 			if _, err := io.ReadFull(r, b); err != nil {
@@ -125,20 +149,28 @@ func unmarshalSlice(v reflect.Value, oscTag oscarTag, r io.Reader, order binary.
 		buf := bytes.NewBuffer(b)
 		for buf.Len() > 0 {
 			elem := reflect.New(elemType).Elem()
-			if err := unmarshal(elemType, elem, "", buf, order); err != nil {
+			if err := unmarshal(elemType, elem, "", buf, order, inner); err != nil {
 				return err
 			}
 			slice = reflect.Append(slice, elem)
+			if elemType.Kind() != reflect.Uint8 {
+				if err := bnd.checkListLen(slice.Len()); err != nil {
+					return err
+				}
+			}
 		}
 	} else if oscTag.hasCountPrefix {
 		count, err := unmarshalUnsignedInt(oscTag.countPrefix, r, order)
 		if err != nil {
 			return err
 		}
+		if err := bnd.checkListLen(count); err != nil {
+			return err
+		}
 
 		for i := 0; i < count; i++ {
 			elem := reflect.New(elemType).Elem()
-			if err := unmarshal(elemType, elem, "", r, order); err != nil {
+			if err := unmarshal(elemType, elem, "", r, order, bnd); err != nil {
 				return err
 			}
 			slice = reflect.Append(slice, elem)
@@ -146,20 +178,25 @@ func unmarshalSlice(v reflect.Value, oscTag oscarTag, r io.Reader, order binary.
 	} else {
 		for {
 			elem := reflect.New(elemType).Elem()
-			if err := unmarshal(elemType, elem, "", r, order); err != nil {
+			if err := unmarshal(elemType, elem, "", r, order, bnd); err != nil {
 				if errors.Is(err, io.EOF) {
 					break
 				}
 				return err
 			}
 			slice = reflect.Append(slice, elem)
+			if elemType.Kind() != reflect.Uint8 {
+				if err := bnd.checkListLen(slice.Len()); err != nil {
+					return err
+				}
+			}
 		}
 	}
 	v.Set(slice)
 	return nil
 }
 
-func unmarshalString(v reflect.Value, oscTag oscarTag, r io.Reader, order binary.ByteOrder) error {
+func unmarshalString(v reflect.Value, oscTag oscarTag, r io.Reader, order binary.ByteOrder, bnd bounds) error {
 	if !oscTag.hasLenPrefix {
 		return fmt.Errorf("missing len_prefix tag")
 	}
@@ -167,6 +204,9 @@ func unmarshalString(v reflect.Value, oscTag oscarTag, r io.Reader, order binary
 	if err != nil {
 		return err
 	}
+	if err := bnd.checkLen(bufLen); err != nil {
+		return err
+	}
 	buf := make([]byte, bufLen)
 	if bufLen > 0 {
 		if _, err := io.ReadFull(r, buf); err != nil {
@@ -185,12 +225,18 @@ func unmarshalString(v reflect.Value, oscTag oscarTag, r io.Reader, order binary
 	return nil
 }
 
-func unmarshalStruct(t reflect.Type, v reflect.Value, oscTag oscarTag, r io.Reader, order binary.ByteOrder) error {
+func unmarshalStruct(t reflect.Type, v reflect.Value, oscTag oscarTag, r io.Reader, order binary.ByteOrder, bnd bounds) error {
 	if oscTag.hasLenPrefix {
 		bufLen, err := unmarshalUnsignedInt(oscTag.lenPrefix, r, order)
 		if err != nil {
 			return err
 		}
+		if err := bnd.checkLen(bufLen); err != nil {
+			return err
+		}
+		if bnd, err = bnd.nested(); err != nil {
+			return err
+		}
 		b := make([]byte, bufLen)
 		if bufLen > 0 {
 			if _, err := io.ReadFull(r, b); err != nil {
@@ -211,7 +257,7 @@ func unmarshalStruct(t reflect.Type, v reflect.Value, oscTag oscarTag, r io.Read
 					errNonOptionalPointer, field.Name, field.Type.Elem().Kind())
 			}
 		}
-		if err := unmarshal(field.Type, value, field.Tag, r, order); err != nil {
+		if err := unmarshal(field.Type, value, field.Tag, r, order, bnd); err != nil {
 			return err
 		}
 	}
@@ -20,6 +20,12 @@ var (
 
 // MarshalBE marshals OSCAR protocol messages in big-endian format.
 func MarshalBE(v any, w io.Writer) error {
+	if ok, err := marshalGenerated(v, w, binary.BigEndian); ok {
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMarshalFailure, err)
+		}
+		return nil
+	}
 	if err := marshal(reflect.TypeOf(v), reflect.ValueOf(v), "", w, binary.BigEndian); err != nil {
 		return fmt.Errorf("%w: %w", ErrMarshalFailure, err)
 	}
@@ -28,6 +34,12 @@ func MarshalBE(v any, w io.Writer) error {
 
 // MarshalLE marshals ICQ protocol messages in little-endian format.
 func MarshalLE(v any, w io.Writer) error {
+	if ok, err := marshalGenerated(v, w, binary.LittleEndian); ok {
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMarshalFailure, err)
+		}
+		return nil
+	}
 	if err := marshal(reflect.TypeOf(v), reflect.ValueOf(v), "", w, binary.LittleEndian); err != nil {
 		return fmt.Errorf("%w: %w", ErrMarshalFailure, err)
 	}
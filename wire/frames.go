@@ -63,13 +63,16 @@ type SNACMessage struct {
 
 // NewFlapClient creates a new FLAP client instance. startSeq is the initial
 // sequence value, which is typically 0. r receives FLAP messages, w writes
-// FLAP messages.
+// FLAP messages. If r was created by WithDecodeLimits, received frames and
+// their contents are decoded with its limits.
 func NewFlapClient(startSeq uint32, r io.Reader, w io.Writer) *FlapClient {
+	r, limits := decodeLimitsOf(r)
 	return &FlapClient{
 		sequence: startSeq,
 		r:        r,
 		w:        w,
 		mutex:    sync.Mutex{},
+		limits:   limits,
 	}
 }
 
@@ -82,6 +85,7 @@ type FlapClient struct {
 	r        io.Reader
 	w        io.Writer
 	mutex    sync.Mutex
+	limits   DecodeLimits
 }
 
 // Fixes a race condition caused by testify. Yup...
@@ -125,12 +129,12 @@ func (f *FlapClient) SendSignonFrame(tlvs []TLV) error {
 // ReceiveSignonFrame receives a signon FLAP response message.
 func (f *FlapClient) ReceiveSignonFrame() (FLAPSignonFrame, error) {
 	flap := FLAPFrame{}
-	if err := UnmarshalBE(&flap, f.r); err != nil {
+	if err := f.receiveFrame(&flap); err != nil {
 		return FLAPSignonFrame{}, err
 	}
 
 	signonFrame := FLAPSignonFrame{}
-	if err := UnmarshalBE(&signonFrame, bytes.NewBuffer(flap.Payload)); err != nil {
+	if err := UnmarshalBE(&signonFrame, f.PayloadReader(flap)); err != nil {
 		return FLAPSignonFrame{}, err
 	}
 
@@ -141,7 +145,7 @@ func (f *FlapClient) ReceiveSignonFrame() (FLAPSignonFrame, error) {
 // FLAP frame is a data frame.
 func (f *FlapClient) ReceiveFLAP() (FLAPFrame, error) {
 	flap := FLAPFrame{}
-	err := UnmarshalBE(&flap, f.r)
+	err := f.receiveFrame(&flap)
 	if err != nil {
 		err = fmt.Errorf("unable to unmarshal FLAP frame: %w", err)
 	}
@@ -262,12 +266,26 @@ func (f *FlapClient) SendKeepAliveFrame() error {
 // ReceiveSNAC receives a SNAC message wrapped in a FLAP frame.
 func (f *FlapClient) ReceiveSNAC(frame *SNACFrame, body any) error {
 	flap := FLAPFrame{}
-	if err := UnmarshalBE(&flap, f.r); err != nil {
+	if err := f.receiveFrame(&flap); err != nil {
 		return err
 	}
-	buf := bytes.NewBuffer(flap.Payload)
+	buf := f.PayloadReader(flap)
 	if err := UnmarshalBE(frame, buf); err != nil {
 		return err
 	}
 	return UnmarshalBE(body, buf)
 }
+
+// receiveFrame receives a FLAP frame, rejecting payloads larger than the
+// MaxFrameSize limit before they are read.
+func (f *FlapClient) receiveFrame(flap *FLAPFrame) error {
+	// the payload is the frame's only length-prefixed field
+	limits := DecodeLimits{MaxTLVSize: f.limits.MaxFrameSize}
+	return UnmarshalBE(flap, WithDecodeLimits(f.r, limits))
+}
+
+// PayloadReader returns a reader for the frame payload that carries the
+// client's decode limits.
+func (f *FlapClient) PayloadReader(flap FLAPFrame) io.Reader {
+	return WithDecodeLimits(bytes.NewBuffer(flap.Payload), f.limits)
+}
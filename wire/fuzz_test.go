@@ -0,0 +1,144 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// fuzzLimits are the decode limits that fuzz targets decode with, so that
+// the fuzzer exercises the limit checks along with the decoders.
+var fuzzLimits = DecodeLimits{
+	MaxFrameSize: 1024,
+	MaxTLVSize:   256,
+	MaxListLen:   16,
+	MaxDepth:     4,
+}
+
+// encodeSeed marshals v into a fuzz seed.
+func encodeSeed(f *testing.F, v any, marshal func(v any, w *bytes.Buffer) error) []byte {
+	f.Helper()
+	buf := &bytes.Buffer{}
+	if err := marshal(v, buf); err != nil {
+		f.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func marshalBE(v any, w *bytes.Buffer) error { return MarshalBE(v, w) }
+
+func marshalLE(v any, w *bytes.Buffer) error { return MarshalLE(v, w) }
+
+func FuzzFlapClient_ReceiveFLAP(f *testing.F) {
+	f.Add(encodeSeed(f, FLAPFrame{
+		StartMarker: 42,
+		FrameType:   FLAPFrameData,
+		Sequence:    1,
+		Payload:     []byte{1, 2, 3, 4},
+	}, marshalBE))
+	f.Add(encodeSeed(f, FLAPFrame{
+		StartMarker: 42,
+		FrameType:   FLAPFrameData,
+		Payload:     make([]byte, 2048),
+	}, marshalBE))
+	f.Add([]byte{42, 2, 0, 1, 0xff, 0xff})
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r := WithDecodeLimits(bytes.NewReader(b), fuzzLimits)
+		flapc := NewFlapClient(0, r, &bytes.Buffer{})
+		frame, err := flapc.ReceiveFLAP()
+		if err != nil {
+			return
+		}
+		if len(frame.Payload) > fuzzLimits.MaxFrameSize {
+			t.Fatalf("accepted %d byte payload", len(frame.Payload))
+		}
+	})
+}
+
+func FuzzUnmarshalBE_SNAC(f *testing.F) {
+	f.Add(encodeSeed(f, SNACFrame{
+		FoodGroup: ICBM,
+		SubGroup:  ICBMChannelMsgToHost,
+		RequestID: 1234,
+	}, marshalBE))
+	f.Add(append(encodeSeed(f, SNACFrame{
+		FoodGroup: Feedbag,
+		SubGroup:  FeedbagInsertItem,
+	}, marshalBE), encodeSeed(f, SNAC_0x13_0x08_FeedbagInsertItem{
+		Items: []FeedbagItem{
+			{Name: "friends", ClassID: FeedbagClassIdGroup},
+			{Name: "them", ClassID: FeedbagClassIdBuddy},
+		},
+	}, marshalBE)...))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r := WithDecodeLimits(bytes.NewReader(b), fuzzLimits)
+		frame := SNACFrame{}
+		if err := UnmarshalBE(&frame, r); err != nil {
+			return
+		}
+		body := SNAC_0x13_0x08_FeedbagInsertItem{}
+		if err := UnmarshalBE(&body, r); err != nil {
+			return
+		}
+		if len(body.Items) > fuzzLimits.MaxListLen {
+			t.Fatalf("accepted %d items", len(body.Items))
+		}
+	})
+}
+
+func FuzzUnmarshalBE_TLV(f *testing.F) {
+	f.Add(encodeSeed(f, TLVLBlock{
+		TLVList: TLVList{
+			NewTLVBE(1, uint16(1234)),
+			NewTLVBE(2, "hello"),
+		},
+	}, marshalBE))
+	f.Add(encodeSeed(f, TLVBlock{
+		TLVList: TLVList{
+			NewTLVBE(1, make([]byte, 512)),
+		},
+	}, marshalBE))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		for _, v := range []any{&TLVRestBlock{}, &TLVBlock{}, &TLVLBlock{}} {
+			err := UnmarshalBE(v, WithDecodeLimits(bytes.NewReader(b), fuzzLimits))
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, ErrUnmarshalFailure) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	})
+}
+
+func FuzzUnmarshalLE_ICQ(f *testing.F) {
+	f.Add(encodeSeed(f, ICQMessageReplyEnvelope{
+		Message: ICQMetadataWithSubType{
+			ICQMetadata: ICQMetadata{
+				UIN:     100003,
+				ReqType: ICQDBQueryMetaReq,
+				Seq:     1,
+			},
+			Optional: &struct {
+				ReqSubType uint16
+			}{
+				ReqSubType: ICQDBQueryMetaReqShortInfo,
+			},
+		},
+	}, marshalLE))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		envelope := ICQMessageRequestEnvelope{}
+		if err := UnmarshalLE(&envelope, WithDecodeLimits(bytes.NewReader(b), fuzzLimits)); err != nil {
+			return
+		}
+		if len(envelope.Body) > fuzzLimits.MaxTLVSize {
+			t.Fatalf("accepted %d byte body", len(envelope.Body))
+		}
+		md := ICQMetadataWithSubType{}
+		_ = UnmarshalLE(&md, WithDecodeLimits(bytes.NewReader(envelope.Body), fuzzLimits))
+	})
+}
@@ -0,0 +1,91 @@
+package wire
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrDecodeLimitExceeded indicates that a message was rejected because it
+// exceeded a DecodeLimits setting.
+var ErrDecodeLimitExceeded = errors.New("decode limit exceeded")
+
+// DecodeLimits bounds what a client can make the server allocate when it
+// decodes messages from the network. Length and count prefixes come straight
+// from the client, so without limits a client could claim large sizes that
+// the decoder trusts. A zero value for any setting means no limit.
+type DecodeLimits struct {
+	// MaxFrameSize is the largest FLAP frame payload accepted, in bytes.
+	MaxFrameSize int
+	// MaxTLVSize is the largest TLV value, string or other length-prefixed
+	// field accepted, in bytes.
+	MaxTLVSize int
+	// MaxListLen is the largest number of elements accepted in a list, such
+	// as a TLV list.
+	MaxListLen int
+	// MaxDepth is the deepest that length-prefixed blocks can be nested.
+	MaxDepth int
+}
+
+// limitedReader is a reader that tells UnmarshalBE and UnmarshalLE what
+// limits to enforce.
+type limitedReader struct {
+	r      io.Reader
+	limits DecodeLimits
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	return l.r.Read(p)
+}
+
+// WithDecodeLimits returns a reader that reads from r. UnmarshalBE,
+// UnmarshalLE and FlapClient enforce limits on messages decoded from the
+// returned reader. Messages decoded from other readers are not limited.
+func WithDecodeLimits(r io.Reader, limits DecodeLimits) io.Reader {
+	if l, ok := r.(*limitedReader); ok {
+		r = l.r
+	}
+	return &limitedReader{r: r, limits: limits}
+}
+
+// decodeLimitsOf returns the reader wrapped by WithDecodeLimits and its
+// limits, or r and no limits if r wasn't wrapped.
+func decodeLimitsOf(r io.Reader) (io.Reader, DecodeLimits) {
+	if l, ok := r.(*limitedReader); ok {
+		return l.r, l.limits
+	}
+	return r, DecodeLimits{}
+}
+
+// bounds tracks a decode's progress against its limits.
+type bounds struct {
+	DecodeLimits
+	// depth is the number of length-prefixed blocks that enclose the value
+	// being decoded.
+	depth int
+}
+
+// checkLen checks the length of a length-prefixed field.
+func (b bounds) checkLen(n int) error {
+	if b.MaxTLVSize > 0 && n > b.MaxTLVSize {
+		return fmt.Errorf("%w: field length %d exceeds %d bytes", ErrDecodeLimitExceeded, n, b.MaxTLVSize)
+	}
+	return nil
+}
+
+// checkListLen checks the number of elements in a list.
+func (b bounds) checkListLen(n int) error {
+	if b.MaxListLen > 0 && n > b.MaxListLen {
+		return fmt.Errorf("%w: list length %d exceeds %d elements", ErrDecodeLimitExceeded, n, b.MaxListLen)
+	}
+	return nil
+}
+
+// nested returns the bounds for the contents of a length-prefixed block.
+func (b bounds) nested() (bounds, error) {
+	b.depth++
+	if b.MaxDepth > 0 && b.depth > b.MaxDepth {
+		return b, fmt.Errorf("%w: nesting depth exceeds %d", ErrDecodeLimitExceeded, b.MaxDepth)
+	}
+	return b, nil
+}
@@ -0,0 +1,143 @@
+package wire
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalBE_DecodeLimits(t *testing.T) {
+	type nested struct {
+		Inner struct {
+			Val TLVBlock `oscar:"len_prefix=uint16"`
+		} `oscar:"len_prefix=uint16"`
+	}
+
+	tests := []struct {
+		name      string
+		given     any
+		prototype any
+		limits    DecodeLimits
+		wantErr   error
+	}{
+		{
+			name:      "within limits",
+			given:     TLVBlock{TLVList: TLVList{NewTLVBE(1, []byte{1, 2, 3, 4})}},
+			prototype: &TLVBlock{},
+			limits:    DecodeLimits{MaxTLVSize: 4, MaxListLen: 1, MaxDepth: 1},
+		},
+		{
+			name:      "no limits",
+			given:     TLVBlock{TLVList: TLVList{NewTLVBE(1, make([]byte, 1024))}},
+			prototype: &TLVBlock{},
+		},
+		{
+			name:      "TLV too long",
+			given:     TLVBlock{TLVList: TLVList{NewTLVBE(1, []byte{1, 2, 3, 4, 5})}},
+			prototype: &TLVBlock{},
+			limits:    DecodeLimits{MaxTLVSize: 4},
+			wantErr:   ErrDecodeLimitExceeded,
+		},
+		{
+			name:      "string too long",
+			given:     SNAC_0x0E_0x02_ChatRoomInfoUpdate{Exchange: 4, Cookie: "the-cookie"},
+			prototype: &SNAC_0x0E_0x02_ChatRoomInfoUpdate{},
+			limits:    DecodeLimits{MaxTLVSize: 4},
+			wantErr:   ErrDecodeLimitExceeded,
+		},
+		{
+			name: "counted list too long",
+			given: TLVBlock{TLVList: TLVList{
+				NewTLVBE(1, uint8(1)),
+				NewTLVBE(2, uint8(2)),
+				NewTLVBE(3, uint8(3)),
+			}},
+			prototype: &TLVBlock{},
+			limits:    DecodeLimits{MaxListLen: 2},
+			wantErr:   ErrDecodeLimitExceeded,
+		},
+		{
+			name: "length-prefixed list too long",
+			given: TLVLBlock{TLVList: TLVList{
+				NewTLVBE(1, uint8(1)),
+				NewTLVBE(2, uint8(2)),
+				NewTLVBE(3, uint8(3)),
+			}},
+			prototype: &TLVLBlock{},
+			limits:    DecodeLimits{MaxListLen: 2},
+			wantErr:   ErrDecodeLimitExceeded,
+		},
+		{
+			name: "unprefixed list too long",
+			given: TLVRestBlock{TLVList: TLVList{
+				NewTLVBE(1, uint8(1)),
+				NewTLVBE(2, uint8(2)),
+				NewTLVBE(3, uint8(3)),
+			}},
+			prototype: &TLVRestBlock{},
+			limits:    DecodeLimits{MaxListLen: 2},
+			wantErr:   ErrDecodeLimitExceeded,
+		},
+		{
+			name:      "nested too deeply",
+			given:     nested{},
+			prototype: &nested{},
+			limits:    DecodeLimits{MaxDepth: 1},
+			wantErr:   ErrDecodeLimitExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, MarshalBE(tt.given, buf))
+
+			err := UnmarshalBE(tt.prototype, WithDecodeLimits(buf, tt.limits))
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.given, *tt.prototype.(*TLVBlock))
+			}
+		})
+	}
+}
+
+func TestFlapClient_ReceiveFLAP_MaxFrameSize(t *testing.T) {
+	frame := FLAPFrame{
+		StartMarker: 42,
+		FrameType:   FLAPFrameData,
+		Payload:     []byte{1, 2, 3, 4, 5},
+	}
+
+	t.Run("frame within limit", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, MarshalBE(frame, buf))
+
+		flapc := NewFlapClient(0, WithDecodeLimits(buf, DecodeLimits{MaxFrameSize: 5, MaxTLVSize: 1}), io.Discard)
+		have, err := flapc.ReceiveFLAP()
+		assert.NoError(t, err)
+		assert.Equal(t, frame, have)
+	})
+
+	t.Run("frame too large", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, MarshalBE(frame, buf))
+
+		flapc := NewFlapClient(0, WithDecodeLimits(buf, DecodeLimits{MaxFrameSize: 4}), io.Discard)
+		_, err := flapc.ReceiveFLAP()
+		assert.ErrorIs(t, err, ErrDecodeLimitExceeded)
+	})
+
+	t.Run("payload decoded with limits", func(t *testing.T) {
+		payload := &bytes.Buffer{}
+		require.NoError(t, MarshalBE(SNACFrame{FoodGroup: ICBM}, payload))
+		require.NoError(t, MarshalBE(TLVRestBlock{TLVList: TLVList{NewTLVBE(1, "hello")}}, payload))
+		buf := &bytes.Buffer{}
+		require.NoError(t, MarshalBE(FLAPFrame{StartMarker: 42, FrameType: FLAPFrameData, Payload: payload.Bytes()}, buf))
+
+		flapc := NewFlapClient(0, WithDecodeLimits(buf, DecodeLimits{MaxTLVSize: 4}), io.Discard)
+		err := flapc.ReceiveSNAC(&SNACFrame{}, &TLVRestBlock{})
+		assert.ErrorIs(t, err, ErrDecodeLimitExceeded)
+	})
+}
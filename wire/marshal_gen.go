@@ -0,0 +1,5086 @@
+// Code generated by marshal_generator. DO NOT EDIT.
+
+package wire
+
+import (
+	"errors"
+	"io"
+)
+
+// appendGenerated appends the encoding of v to b. It returns false if v
+// has no generated encoder.
+func appendGenerated(v any, b []byte, order byteOrder) ([]byte, bool) {
+	switch v := v.(type) {
+	case BARTID:
+		return v.appendOSCAR(b, order), true
+	case BARTInfo:
+		return v.appendOSCAR(b, order), true
+	case BartIDsWName:
+		return v.appendOSCAR(b, order), true
+	case BartQueryReplyID:
+		return v.appendOSCAR(b, order), true
+	case FLAPFrame:
+		return v.appendOSCAR(b, order), true
+	case FLAPFrameDisconnect:
+		return v.appendOSCAR(b, order), true
+	case FLAPSignonFrame:
+		return v.appendOSCAR(b, order), true
+	case FeedbagItem:
+		return v.appendOSCAR(b, order), true
+	case ICBMCh1Fragment:
+		return v.appendOSCAR(b, order), true
+	case ICBMCh1Message:
+		return v.appendOSCAR(b, order), true
+	case ICBMCh2Fragment:
+		return v.appendOSCAR(b, order), true
+	case ICBMCh4Message:
+		return v.appendOSCAR(b, order), true
+	case ICBMRoomInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQDCInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQEmail:
+		return v.appendOSCAR(b, order), true
+	case ICQInterests:
+		return v.appendOSCAR(b, order), true
+	case ICQMessageRequestEnvelope:
+		return v.appendOSCAR(b, order), true
+	case ICQMetadata:
+		return v.appendOSCAR(b, order), true
+	case ICQMetadataWithSubType:
+		return v.appendOSCAR(b, order), true
+	case ICQNewUINRequest:
+		return v.appendOSCAR(b, order), true
+	case ICQNewUINResponse:
+		return v.appendOSCAR(b, order), true
+	case ICQUserSearchRecord:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x0041_DBQueryOfflineMsgReply:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x0042_DBQueryOfflineMsgReplyLast:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x03EA_DBQueryMetaReqSetBasicInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x03F3_DBQueryMetaReqSetWorkInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x03FD_DBQueryMetaReqSetMoreInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x0406_DBQueryMetaReqSetNotes:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x040B_DBQueryMetaReqSetEmails:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x0410_DBQueryMetaReqSetInterests:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x041A_DBQueryMetaReqSetAffiliations:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x0424_DBQueryMetaReqSetPermissions:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x04BA_DBQueryMetaReqShortInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x0515_DBQueryMetaReqSearchByDetails:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x051F_DBQueryMetaReqSearchByUIN:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x0529_DBQueryMetaReqSearchByEmail:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x0533_DBQueryMetaReqSearchWhitePages:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x055F_DBQueryMetaReqSearchWhitePages2:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x0569_DBQueryMetaReqSearchByUIN2:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x0573_DBQueryMetaReqSearchByEmail3:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07D0_0x0898_DBQueryMetaReqXMLReq:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x00C8_DBQueryMetaReplyBasicInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x00D2_DBQueryMetaReplyWorkInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x00DC_DBQueryMetaReplyMoreInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x00E6_DBQueryMetaReplyNotes:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x00EB_DBQueryMetaReplyExtEmailInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x00F0_DBQueryMetaReplyInterests:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x00FA_DBQueryMetaReplyAffiliations:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x0104_DBQueryMetaReplyShortInfo:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x010E_DBQueryMetaReplyHomePageCat:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x01AE_DBQueryMetaReplyLastUserFound:
+		return v.appendOSCAR(b, order), true
+	case ICQ_0x07DA_0x08A2_DBQueryMetaReplyXMLData:
+		return v.appendOSCAR(b, order), true
+	case KerberosBOSServerInfo:
+		return v.appendOSCAR(b, order), true
+	case KerberosLoginRequestTicket:
+		return v.appendOSCAR(b, order), true
+	case KerberosTicket:
+		return v.appendOSCAR(b, order), true
+	case ODirKeywordListItem:
+		return v.appendOSCAR(b, order), true
+	case RateParamsSNAC:
+		return v.appendOSCAR(b, order), true
+	case SNACError:
+		return v.appendOSCAR(b, order), true
+	case SNACFrame:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x02_OServiceClientOnline:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x03_OServiceHostOnline:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x04_OServiceServiceRequest:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x04_TLVRoomInfo:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x05_OServiceServiceResponse:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x07_OServiceRateParamsReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x08_OServiceRateParamsSubAdd:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x0A_OServiceRateParamsChange:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x0F_OServiceUserInfoUpdate:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x10_OServiceEvilNotification:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x11_OServiceIdleNotification:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x13_OServiceMotd:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x14_OServiceSetPrivacyFlags:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x17_OServiceClientVersions:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x18_OServiceHostVersions:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x1E_OServiceSetUserInfoFields:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x21_OServiceBARTReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x01_0x23_OServiceBART2Reply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x03_LocateRightsReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x04_LocateSetInfo:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x05_LocateUserInfoQuery:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x06_LocateUserInfoReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x09_LocateSetDirInfo:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x0A_LocateSetDirReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x0B_LocateGetDirInfo:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x0C_LocateGetDirReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x0F_LocateSetKeywordInfo:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x10_LocateSetKeywordReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x02_0x15_LocateUserInfoQuery2:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x03_0x02_BuddyRightsQuery:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x03_0x03_BuddyRightsReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x03_0x04_BuddyAddBuddies:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x03_0x05_BuddyDelBuddies:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x03_0x0B_BuddyArrived:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x03_0x0C_BuddyDeparted:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x02_ICBMAddParameters:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x05_ICBMParameterReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x06_ICBMChannelMsgToHost:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x07_ICBMChannelMsgToClient:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x08_ICBMEvilRequest:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x09_ICBMEvilReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x0B_ICBMClientErr:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x0C_ICBMHostAck:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x10_ICBMSinRetrieve:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x14_ICBMClientEvent:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x04_0x17_ICBMSinReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x050C_0x0002_KerberosLoginRequest:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x050C_0x0003_KerberosLoginSuccessResponse:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x050C_0x0004_KerberosLoginErrResponse:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x06_0x02_InviteRequestQuery:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x06_0x03_InviteRequestReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x07_0x02_AdminInfoQuery:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x07_0x03_AdminInfoReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x07_0x04_AdminInfoChangeRequest:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x07_0x05_AdminChangeReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x07_0x06_AdminConfirmRequest:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x07_0x07_AdminConfirmReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x08_0x02_PopupDisplay:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x09_0x03_PermitDenyRightsReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x09_0x04_PermitDenySetGroupPermitMask:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x09_0x05_PermitDenyAddPermListEntries:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x09_0x06_PermitDenyDelPermListEntries:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x09_0x07_PermitDenyAddDenyListEntries:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x09_0x08_PermitDenyDelDenyListEntries:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0A_0x02_UserLookupFindByEmail:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0A_0x03_UserLookupFindReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0B_0x02_StatsSetMinReportInterval:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0B_0x03_StatsReportEvents:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0B_0x04_StatsReportAck:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0D_0x03_ChatNavRequestExchangeInfo:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0D_0x04_ChatNavRequestRoomInfo:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0D_0x09_ChatNavNavInfo:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0D_0x09_TLVExchangeInfo:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0E_0x02_ChatRoomInfoUpdate:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0E_0x03_ChatUsersJoined:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0E_0x04_ChatUsersLeft:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0E_0x05_ChatChannelMsgToHost:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0E_0x06_ChatChannelMsgToClient:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0F_0x02_InfoQuery:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0F_0x04_KeywordListQuery:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x0F_0x04_KeywordListReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x10_0x02_BARTUploadQuery:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x10_0x03_BARTUploadReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x10_0x04_BARTDownloadQuery:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x10_0x05_BARTDownloadReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x10_0x06_BARTDownload2Query:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x10_0x07_BARTDownload2Reply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x02_FeedbagRightsQuery:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x03_FeedbagRightsReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x05_FeedbagQueryIfModified:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x06_FeedbagReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x08_FeedbagInsertItem:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x09_FeedbagUpdateItem:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x0A_FeedbagDeleteItem:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x0E_FeedbagStatus:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x11_FeedbagStartCluster:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x18_FeedbagRequestAuthorizationToHost:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x1A_FeedbagRespondAuthorizeToHost:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x13_0x1B_FeedbagRespondAuthorizeToClient:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x15_0x02_BQuery:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x15_0x02_DBReply:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x17_0x02_BUCPLoginRequest:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x17_0x03_BUCPLoginResponse:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x17_0x04_BUCPRegisterRequest:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x17_0x05_BUCPRegisterResponse:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x17_0x06_BUCPChallengeRequest:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x17_0x07_BUCPChallengeResponse:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x17_0x0A_BUCPSecuridRequest:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x17_0x0B_BUCPSecuridResponse:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x17_0x0C_BUCPRegistrationImageRequest:
+		return v.appendOSCAR(b, order), true
+	case SNAC_0x17_0x0D_BUCPRegistrationImageReply:
+		return v.appendOSCAR(b, order), true
+	case TLV:
+		return v.appendOSCAR(b, order), true
+	case TLVBlock:
+		return v.appendOSCAR(b, order), true
+	case TLVLBlock:
+		return v.appendOSCAR(b, order), true
+	case TLVRestBlock:
+		return v.appendOSCAR(b, order), true
+	case TLVUserInfo:
+		return v.appendOSCAR(b, order), true
+	}
+	return b, false
+}
+
+// decodeGenerated decodes into v. It returns false if v has no generated
+// decoder.
+func decodeGenerated(v any, d *decoder) (bool, error) {
+	switch v := v.(type) {
+	case *BARTID:
+		return true, v.decodeOSCAR(d)
+	case *BARTInfo:
+		return true, v.decodeOSCAR(d)
+	case *BartIDsWName:
+		return true, v.decodeOSCAR(d)
+	case *BartQueryReplyID:
+		return true, v.decodeOSCAR(d)
+	case *FLAPFrame:
+		return true, v.decodeOSCAR(d)
+	case *FLAPFrameDisconnect:
+		return true, v.decodeOSCAR(d)
+	case *FLAPSignonFrame:
+		return true, v.decodeOSCAR(d)
+	case *FeedbagItem:
+		return true, v.decodeOSCAR(d)
+	case *ICBMCh1Fragment:
+		return true, v.decodeOSCAR(d)
+	case *ICBMCh1Message:
+		return true, v.decodeOSCAR(d)
+	case *ICBMCh2Fragment:
+		return true, v.decodeOSCAR(d)
+	case *ICBMCh4Message:
+		return true, v.decodeOSCAR(d)
+	case *ICBMRoomInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQDCInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQEmail:
+		return true, v.decodeOSCAR(d)
+	case *ICQInterests:
+		return true, v.decodeOSCAR(d)
+	case *ICQMessageRequestEnvelope:
+		return true, v.decodeOSCAR(d)
+	case *ICQMetadata:
+		return true, v.decodeOSCAR(d)
+	case *ICQMetadataWithSubType:
+		return true, v.decodeOSCAR(d)
+	case *ICQNewUINRequest:
+		return true, v.decodeOSCAR(d)
+	case *ICQNewUINResponse:
+		return true, v.decodeOSCAR(d)
+	case *ICQUserSearchRecord:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x0041_DBQueryOfflineMsgReply:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x0042_DBQueryOfflineMsgReplyLast:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x03EA_DBQueryMetaReqSetBasicInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x03F3_DBQueryMetaReqSetWorkInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x03FD_DBQueryMetaReqSetMoreInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x0406_DBQueryMetaReqSetNotes:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x040B_DBQueryMetaReqSetEmails:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x0410_DBQueryMetaReqSetInterests:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x041A_DBQueryMetaReqSetAffiliations:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x0424_DBQueryMetaReqSetPermissions:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x04BA_DBQueryMetaReqShortInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x0515_DBQueryMetaReqSearchByDetails:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x051F_DBQueryMetaReqSearchByUIN:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x0529_DBQueryMetaReqSearchByEmail:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x0533_DBQueryMetaReqSearchWhitePages:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x055F_DBQueryMetaReqSearchWhitePages2:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x0569_DBQueryMetaReqSearchByUIN2:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x0573_DBQueryMetaReqSearchByEmail3:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07D0_0x0898_DBQueryMetaReqXMLReq:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x00C8_DBQueryMetaReplyBasicInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x00D2_DBQueryMetaReplyWorkInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x00DC_DBQueryMetaReplyMoreInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x00E6_DBQueryMetaReplyNotes:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x00EB_DBQueryMetaReplyExtEmailInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x00F0_DBQueryMetaReplyInterests:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x00FA_DBQueryMetaReplyAffiliations:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x0104_DBQueryMetaReplyShortInfo:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x010E_DBQueryMetaReplyHomePageCat:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x01AE_DBQueryMetaReplyLastUserFound:
+		return true, v.decodeOSCAR(d)
+	case *ICQ_0x07DA_0x08A2_DBQueryMetaReplyXMLData:
+		return true, v.decodeOSCAR(d)
+	case *KerberosBOSServerInfo:
+		return true, v.decodeOSCAR(d)
+	case *KerberosLoginRequestTicket:
+		return true, v.decodeOSCAR(d)
+	case *KerberosTicket:
+		return true, v.decodeOSCAR(d)
+	case *ODirKeywordListItem:
+		return true, v.decodeOSCAR(d)
+	case *RateParamsSNAC:
+		return true, v.decodeOSCAR(d)
+	case *SNACError:
+		return true, v.decodeOSCAR(d)
+	case *SNACFrame:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x02_OServiceClientOnline:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x03_OServiceHostOnline:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x04_OServiceServiceRequest:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x04_TLVRoomInfo:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x05_OServiceServiceResponse:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x07_OServiceRateParamsReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x08_OServiceRateParamsSubAdd:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x0A_OServiceRateParamsChange:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x0F_OServiceUserInfoUpdate:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x10_OServiceEvilNotification:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x11_OServiceIdleNotification:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x13_OServiceMotd:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x14_OServiceSetPrivacyFlags:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x17_OServiceClientVersions:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x18_OServiceHostVersions:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x1E_OServiceSetUserInfoFields:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x21_OServiceBARTReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x01_0x23_OServiceBART2Reply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x03_LocateRightsReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x04_LocateSetInfo:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x05_LocateUserInfoQuery:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x06_LocateUserInfoReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x09_LocateSetDirInfo:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x0A_LocateSetDirReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x0B_LocateGetDirInfo:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x0C_LocateGetDirReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x0F_LocateSetKeywordInfo:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x10_LocateSetKeywordReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x02_0x15_LocateUserInfoQuery2:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x03_0x02_BuddyRightsQuery:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x03_0x03_BuddyRightsReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x03_0x04_BuddyAddBuddies:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x03_0x05_BuddyDelBuddies:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x03_0x0B_BuddyArrived:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x03_0x0C_BuddyDeparted:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x02_ICBMAddParameters:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x05_ICBMParameterReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x06_ICBMChannelMsgToHost:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x07_ICBMChannelMsgToClient:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x08_ICBMEvilRequest:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x09_ICBMEvilReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x0B_ICBMClientErr:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x0C_ICBMHostAck:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x10_ICBMSinRetrieve:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x14_ICBMClientEvent:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x04_0x17_ICBMSinReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x050C_0x0002_KerberosLoginRequest:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x050C_0x0003_KerberosLoginSuccessResponse:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x050C_0x0004_KerberosLoginErrResponse:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x06_0x02_InviteRequestQuery:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x06_0x03_InviteRequestReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x07_0x02_AdminInfoQuery:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x07_0x03_AdminInfoReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x07_0x04_AdminInfoChangeRequest:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x07_0x05_AdminChangeReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x07_0x06_AdminConfirmRequest:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x07_0x07_AdminConfirmReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x08_0x02_PopupDisplay:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x09_0x03_PermitDenyRightsReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x09_0x04_PermitDenySetGroupPermitMask:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x09_0x05_PermitDenyAddPermListEntries:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x09_0x06_PermitDenyDelPermListEntries:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x09_0x07_PermitDenyAddDenyListEntries:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x09_0x08_PermitDenyDelDenyListEntries:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0A_0x02_UserLookupFindByEmail:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0A_0x03_UserLookupFindReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0B_0x02_StatsSetMinReportInterval:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0B_0x03_StatsReportEvents:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0B_0x04_StatsReportAck:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0D_0x03_ChatNavRequestExchangeInfo:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0D_0x04_ChatNavRequestRoomInfo:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0D_0x09_ChatNavNavInfo:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0D_0x09_TLVExchangeInfo:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0E_0x02_ChatRoomInfoUpdate:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0E_0x03_ChatUsersJoined:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0E_0x04_ChatUsersLeft:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0E_0x05_ChatChannelMsgToHost:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0E_0x06_ChatChannelMsgToClient:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0F_0x02_InfoQuery:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0F_0x04_KeywordListQuery:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x0F_0x04_KeywordListReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x10_0x02_BARTUploadQuery:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x10_0x03_BARTUploadReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x10_0x04_BARTDownloadQuery:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x10_0x05_BARTDownloadReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x10_0x06_BARTDownload2Query:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x10_0x07_BARTDownload2Reply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x02_FeedbagRightsQuery:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x03_FeedbagRightsReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x05_FeedbagQueryIfModified:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x06_FeedbagReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x08_FeedbagInsertItem:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x09_FeedbagUpdateItem:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x0A_FeedbagDeleteItem:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x0E_FeedbagStatus:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x11_FeedbagStartCluster:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x18_FeedbagRequestAuthorizationToHost:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x1A_FeedbagRespondAuthorizeToHost:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x13_0x1B_FeedbagRespondAuthorizeToClient:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x15_0x02_BQuery:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x15_0x02_DBReply:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x17_0x02_BUCPLoginRequest:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x17_0x03_BUCPLoginResponse:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x17_0x04_BUCPRegisterRequest:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x17_0x05_BUCPRegisterResponse:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x17_0x06_BUCPChallengeRequest:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x17_0x07_BUCPChallengeResponse:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x17_0x0A_BUCPSecuridRequest:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x17_0x0B_BUCPSecuridResponse:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x17_0x0C_BUCPRegistrationImageRequest:
+		return true, v.decodeOSCAR(d)
+	case *SNAC_0x17_0x0D_BUCPRegistrationImageReply:
+		return true, v.decodeOSCAR(d)
+	case *TLV:
+		return true, v.decodeOSCAR(d)
+	case *TLVBlock:
+		return true, v.decodeOSCAR(d)
+	case *TLVLBlock:
+		return true, v.decodeOSCAR(d)
+	case *TLVRestBlock:
+		return true, v.decodeOSCAR(d)
+	case *TLVUserInfo:
+		return true, v.decodeOSCAR(d)
+	}
+	return false, nil
+}
+
+func (v BARTID) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Type))
+	b = v.BARTInfo.appendOSCAR(b, order)
+	return b
+}
+
+func (v *BARTID) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Type); err != nil {
+		return err
+	}
+	if err := v.BARTInfo.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v BARTInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = append(b, uint8(v.Flags))
+	at1 := len(b)
+	b = append(b, make([]byte, 1)...)
+	b = append(b, v.Hash...)
+	putLen(b, order, 1, at1)
+	return b
+}
+
+func (v *BARTInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeUint8(d, &v.Flags); err != nil {
+		return err
+	}
+	{
+		b, err := d.readBytes(1)
+		if err != nil {
+			return err
+		}
+		v.Hash = b
+	}
+	return nil
+}
+
+func (v BartIDsWName) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	at1 := len(b)
+	b = append(b, make([]byte, 1)...)
+	for i2 := range v.IDs {
+		b = v.IDs[i2].appendOSCAR(b, order)
+	}
+	putLen(b, order, 1, at1)
+	return b
+}
+
+func (v *BartIDsWName) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	{
+		d1, err := d.readBlock(1)
+		if err != nil {
+			return err
+		}
+		v.IDs = nil
+		for d1.more() {
+			var e2 BARTID
+			if err := e2.decodeOSCAR(d1); err != nil {
+				return err
+			}
+			v.IDs = append(v.IDs, e2)
+			if err := d.checkListLen(len(v.IDs)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v BartQueryReplyID) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.QueryID.appendOSCAR(b, order)
+	b = append(b, uint8(v.Code))
+	b = v.ReplyID.appendOSCAR(b, order)
+	return b
+}
+
+func (v *BartQueryReplyID) decodeOSCAR(d *decoder) error {
+	if err := v.QueryID.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Code); err != nil {
+		return err
+	}
+	if err := v.ReplyID.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v FLAPFrame) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = append(b, uint8(v.StartMarker))
+	b = append(b, uint8(v.FrameType))
+	b = order.AppendUint16(b, uint16(v.Sequence))
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = append(b, v.Payload...)
+	putLen(b, order, 2, at1)
+	return b
+}
+
+func (v *FLAPFrame) decodeOSCAR(d *decoder) error {
+	if err := decodeUint8(d, &v.StartMarker); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.FrameType); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Sequence); err != nil {
+		return err
+	}
+	{
+		b, err := d.readBytes(2)
+		if err != nil {
+			return err
+		}
+		v.Payload = b
+	}
+	return nil
+}
+
+func (v FLAPFrameDisconnect) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = append(b, uint8(v.StartMarker))
+	b = append(b, uint8(v.FrameType))
+	b = order.AppendUint16(b, uint16(v.Sequence))
+	return b
+}
+
+func (v *FLAPFrameDisconnect) decodeOSCAR(d *decoder) error {
+	if err := decodeUint8(d, &v.StartMarker); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.FrameType); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Sequence); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v FLAPSignonFrame) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.FLAPVersion))
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *FLAPSignonFrame) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.FLAPVersion); err != nil {
+		return err
+	}
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v FeedbagItem) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.Name), 2, false)
+	b = order.AppendUint16(b, uint16(v.GroupID))
+	b = order.AppendUint16(b, uint16(v.ItemID))
+	b = order.AppendUint16(b, uint16(v.ClassID))
+	b = v.TLVLBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *FeedbagItem) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.Name, 2, false); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.GroupID); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ItemID); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ClassID); err != nil {
+		return err
+	}
+	if err := v.TLVLBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICBMCh1Fragment) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = append(b, uint8(v.ID))
+	b = append(b, uint8(v.Version))
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = append(b, v.Payload...)
+	putLen(b, order, 2, at1)
+	return b
+}
+
+func (v *ICBMCh1Fragment) decodeOSCAR(d *decoder) error {
+	if err := decodeUint8(d, &v.ID); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Version); err != nil {
+		return err
+	}
+	{
+		b, err := d.readBytes(2)
+		if err != nil {
+			return err
+		}
+		v.Payload = b
+	}
+	return nil
+}
+
+func (v ICBMCh1Message) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Charset))
+	b = order.AppendUint16(b, uint16(v.Language))
+	b = append(b, v.Text...)
+	return b
+}
+
+func (v *ICBMCh1Message) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Charset); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Language); err != nil {
+		return err
+	}
+	{
+		b, err := d.readRest()
+		if err != nil {
+			return err
+		}
+		v.Text = b
+	}
+	return nil
+}
+
+func (v ICBMCh2Fragment) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Type))
+	b = append(b, v.Cookie[:]...)
+	b = append(b, v.Capability[:]...)
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *ICBMCh2Fragment) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Type); err != nil {
+		return err
+	}
+	if err := d.readElems(v.Cookie[:]); err != nil {
+		return err
+	}
+	if err := d.readElems(v.Capability[:]); err != nil {
+		return err
+	}
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICBMCh4Message) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.UIN))
+	b = append(b, uint8(v.MessageType))
+	b = append(b, uint8(v.Flags))
+	b = appendString(b, order, string(v.Message), 2, true)
+	return b
+}
+
+func (v *ICBMCh4Message) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.UIN); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.MessageType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Flags); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Message, 2, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICBMRoomInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Exchange))
+	b = appendString(b, order, string(v.Cookie), 1, false)
+	b = order.AppendUint16(b, uint16(v.Instance))
+	return b
+}
+
+func (v *ICBMRoomInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Exchange); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Cookie, 1, false); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Instance); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQDCInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.IP))
+	b = order.AppendUint32(b, uint32(v.Port))
+	b = append(b, uint8(v.DCType))
+	b = order.AppendUint16(b, uint16(v.ProtoVersion))
+	b = order.AppendUint32(b, uint32(v.AuthCookie))
+	b = order.AppendUint32(b, uint32(v.WebPort))
+	b = order.AppendUint32(b, uint32(v.ClientFutures))
+	b = order.AppendUint32(b, uint32(v.LastUpdateTime))
+	b = order.AppendUint32(b, uint32(v.LastExtInfoUpdateTime))
+	b = order.AppendUint32(b, uint32(v.LastExtStatusUpdateTime))
+	b = order.AppendUint16(b, uint16(v.Unknown))
+	return b
+}
+
+func (v *ICQDCInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.IP); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Port); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.DCType); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ProtoVersion); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.AuthCookie); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.WebPort); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.ClientFutures); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.LastUpdateTime); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.LastExtInfoUpdateTime); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.LastExtStatusUpdateTime); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Unknown); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQEmail) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.Email), 2, true)
+	return b
+}
+
+func (v *ICQEmail) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.Email, 2, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQInterests) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Code))
+	b = appendString(b, order, string(v.Keyword), 2, true)
+	return b
+}
+
+func (v *ICQInterests) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Code); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Keyword, 2, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQMessageRequestEnvelope) appendOSCAR(b []byte, order byteOrder) []byte {
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = append(b, v.Body...)
+	putLen(b, order, 2, at1)
+	return b
+}
+
+func (v *ICQMessageRequestEnvelope) decodeOSCAR(d *decoder) error {
+	{
+		b, err := d.readBytes(2)
+		if err != nil {
+			return err
+		}
+		v.Body = b
+	}
+	return nil
+}
+
+func (v ICQMetadata) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.UIN))
+	b = order.AppendUint16(b, uint16(v.ReqType))
+	b = order.AppendUint16(b, uint16(v.Seq))
+	return b
+}
+
+func (v *ICQMetadata) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.UIN); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqType); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Seq); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQMetadataWithSubType) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	if v.Optional != nil {
+		b = order.AppendUint16(b, uint16(v.Optional.ReqSubType))
+	}
+	return b
+}
+
+func (v *ICQMetadataWithSubType) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	p1 := newOf(v.Optional)
+	err2 := func() error {
+		if err := decodeUint16(d, &(*p1).ReqSubType); err != nil {
+			return err
+		}
+		return nil
+	}()
+	switch {
+	case errors.Is(err2, io.EOF):
+		v.Optional = nil
+	case err2 != nil:
+		return err2
+	default:
+		v.Optional = p1
+	}
+	return nil
+}
+
+func (v ICQNewUINRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.Unknown1))
+	b = order.AppendUint16(b, uint16(v.Unknown2))
+	b = order.AppendUint16(b, uint16(v.Unknown3))
+	b = order.AppendUint32(b, uint32(v.Unknown4))
+	b = order.AppendUint32(b, uint32(v.Unknown5))
+	b = order.AppendUint32(b, uint32(v.Cookie1))
+	b = order.AppendUint32(b, uint32(v.Cookie2))
+	b = order.AppendUint32(b, uint32(v.Unknown6))
+	b = order.AppendUint32(b, uint32(v.Unknown7))
+	b = order.AppendUint32(b, uint32(v.Unknown8))
+	b = order.AppendUint32(b, uint32(v.Unknown9))
+	b = appendString(b, order, string(v.Password), 2, true)
+	b = order.AppendUint32(b, uint32(v.Cookie3))
+	b = order.AppendUint32(b, uint32(v.Unknown10))
+	return b
+}
+
+func (v *ICQNewUINRequest) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.Unknown1); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Unknown2); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Unknown3); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown4); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown5); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Cookie1); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Cookie2); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown6); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown7); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown8); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown9); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Password, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Cookie3); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown10); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQNewUINResponse) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.Unknown1))
+	b = order.AppendUint16(b, uint16(v.Unknown2))
+	b = order.AppendUint16(b, uint16(v.Unknown3))
+	b = order.AppendUint32(b, uint32(v.Unknown4))
+	b = order.AppendUint32(b, uint32(v.Unknown5))
+	b = order.AppendUint32(b, uint32(v.Cookie1))
+	b = order.AppendUint32(b, uint32(v.Cookie2))
+	b = order.AppendUint32(b, uint32(v.Unknown6))
+	b = order.AppendUint32(b, uint32(v.Unknown7))
+	b = order.AppendUint32(b, uint32(v.UIN))
+	b = order.AppendUint32(b, uint32(v.Cookie3))
+	return b
+}
+
+func (v *ICQNewUINResponse) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.Unknown1); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Unknown2); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Unknown3); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown4); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown5); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Cookie1); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Cookie2); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown6); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown7); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.UIN); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Cookie3); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQUserSearchRecord) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.UIN))
+	b = appendString(b, order, string(v.Nickname), 2, true)
+	b = appendString(b, order, string(v.FirstName), 2, true)
+	b = appendString(b, order, string(v.LastName), 2, true)
+	b = appendString(b, order, string(v.Email), 2, true)
+	b = append(b, uint8(v.Authorization))
+	b = order.AppendUint16(b, uint16(v.OnlineStatus))
+	b = append(b, uint8(v.Gender))
+	b = order.AppendUint16(b, uint16(v.Age))
+	return b
+}
+
+func (v *ICQUserSearchRecord) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.UIN); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Nickname, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.FirstName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.LastName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Email, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Authorization); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.OnlineStatus); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Gender); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Age); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x0041_DBQueryOfflineMsgReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint32(b, uint32(v.SenderUIN))
+	b = order.AppendUint16(b, uint16(v.Year))
+	b = append(b, uint8(v.Month))
+	b = append(b, uint8(v.Day))
+	b = append(b, uint8(v.Hour))
+	b = append(b, uint8(v.Minute))
+	b = append(b, uint8(v.MsgType))
+	b = append(b, uint8(v.Flags))
+	b = appendString(b, order, string(v.Message), 2, true)
+	return b
+}
+
+func (v *ICQ_0x0041_DBQueryOfflineMsgReply) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.SenderUIN); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Year); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Month); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Day); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Hour); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Minute); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.MsgType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Flags); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Message, 2, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x0042_DBQueryOfflineMsgReplyLast) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = append(b, uint8(v.DroppedMessages))
+	return b
+}
+
+func (v *ICQ_0x0042_DBQueryOfflineMsgReplyLast) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.DroppedMessages); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x03EA_DBQueryMetaReqSetBasicInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.Nickname), 2, true)
+	b = appendString(b, order, string(v.FirstName), 2, true)
+	b = appendString(b, order, string(v.LastName), 2, true)
+	b = appendString(b, order, string(v.EmailAddress), 2, true)
+	b = appendString(b, order, string(v.City), 2, true)
+	b = appendString(b, order, string(v.State), 2, true)
+	b = appendString(b, order, string(v.Phone), 2, true)
+	b = appendString(b, order, string(v.Fax), 2, true)
+	b = appendString(b, order, string(v.HomeAddress), 2, true)
+	b = appendString(b, order, string(v.CellPhone), 2, true)
+	b = appendString(b, order, string(v.ZIP), 2, true)
+	b = order.AppendUint16(b, uint16(v.CountryCode))
+	b = append(b, uint8(v.GMTOffset))
+	b = append(b, uint8(v.PublishEmail))
+	return b
+}
+
+func (v *ICQ_0x07D0_0x03EA_DBQueryMetaReqSetBasicInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.Nickname, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.FirstName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.LastName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.EmailAddress, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.City, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.State, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Phone, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Fax, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.HomeAddress, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.CellPhone, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ZIP, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.CountryCode); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.GMTOffset); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.PublishEmail); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x03F3_DBQueryMetaReqSetWorkInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.City), 2, true)
+	b = appendString(b, order, string(v.State), 2, true)
+	b = appendString(b, order, string(v.Phone), 2, true)
+	b = appendString(b, order, string(v.Fax), 2, true)
+	b = appendString(b, order, string(v.Address), 2, true)
+	b = appendString(b, order, string(v.ZIP), 2, true)
+	b = order.AppendUint16(b, uint16(v.CountryCode))
+	b = appendString(b, order, string(v.Company), 2, true)
+	b = appendString(b, order, string(v.Department), 2, true)
+	b = appendString(b, order, string(v.Position), 2, true)
+	b = order.AppendUint16(b, uint16(v.OccupationCode))
+	b = appendString(b, order, string(v.WebPage), 2, true)
+	return b
+}
+
+func (v *ICQ_0x07D0_0x03F3_DBQueryMetaReqSetWorkInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.City, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.State, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Phone, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Fax, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Address, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ZIP, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.CountryCode); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Company, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Department, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Position, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.OccupationCode); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.WebPage, 2, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x03FD_DBQueryMetaReqSetMoreInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = append(b, uint8(v.Age))
+	b = order.AppendUint16(b, uint16(v.Gender))
+	b = appendString(b, order, string(v.HomePageAddr), 2, true)
+	b = order.AppendUint16(b, uint16(v.BirthYear))
+	b = append(b, uint8(v.BirthMonth))
+	b = append(b, uint8(v.BirthDay))
+	b = append(b, uint8(v.Lang1))
+	b = append(b, uint8(v.Lang2))
+	b = append(b, uint8(v.Lang3))
+	return b
+}
+
+func (v *ICQ_0x07D0_0x03FD_DBQueryMetaReqSetMoreInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeUint8(d, &v.Age); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Gender); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.HomePageAddr, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.BirthYear); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.BirthMonth); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.BirthDay); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Lang1); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Lang2); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Lang3); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x0406_DBQueryMetaReqSetNotes) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.Notes), 2, true)
+	return b
+}
+
+func (v *ICQ_0x07D0_0x0406_DBQueryMetaReqSetNotes) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.Notes, 2, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x040B_DBQueryMetaReqSetEmails) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendLen(b, order, 1, len(v.Emails))
+	for i1 := range v.Emails {
+		b = append(b, uint8(v.Emails[i1].Publish))
+		b = appendString(b, order, string(v.Emails[i1].Email), 2, true)
+	}
+	return b
+}
+
+func (v *ICQ_0x07D0_0x040B_DBQueryMetaReqSetEmails) decodeOSCAR(d *decoder) error {
+	{
+		n1, err := d.readLen(1)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.Emails = nil
+		for range n1 {
+			var e2 struct {
+				Publish uint8
+				Email   string `oscar:"len_prefix=uint16,nullterm"`
+			}
+			if err := decodeUint8(d, &e2.Publish); err != nil {
+				return err
+			}
+			if err := decodeString(d, &e2.Email, 2, true); err != nil {
+				return err
+			}
+			v.Emails = append(v.Emails, e2)
+		}
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x0410_DBQueryMetaReqSetInterests) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendLen(b, order, 1, len(v.Interests))
+	for i1 := range v.Interests {
+		b = order.AppendUint16(b, uint16(v.Interests[i1].Code))
+		b = appendString(b, order, string(v.Interests[i1].Keyword), 2, true)
+	}
+	return b
+}
+
+func (v *ICQ_0x07D0_0x0410_DBQueryMetaReqSetInterests) decodeOSCAR(d *decoder) error {
+	{
+		n1, err := d.readLen(1)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.Interests = nil
+		for range n1 {
+			var e2 struct {
+				Code    uint16
+				Keyword string `oscar:"len_prefix=uint16,nullterm"`
+			}
+			if err := decodeUint16(d, &e2.Code); err != nil {
+				return err
+			}
+			if err := decodeString(d, &e2.Keyword, 2, true); err != nil {
+				return err
+			}
+			v.Interests = append(v.Interests, e2)
+		}
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x041A_DBQueryMetaReqSetAffiliations) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendLen(b, order, 1, len(v.PastAffiliations))
+	for i1 := range v.PastAffiliations {
+		b = order.AppendUint16(b, uint16(v.PastAffiliations[i1].Code))
+		b = appendString(b, order, string(v.PastAffiliations[i1].Keyword), 2, true)
+	}
+	b = appendLen(b, order, 1, len(v.Affiliations))
+	for i2 := range v.Affiliations {
+		b = order.AppendUint16(b, uint16(v.Affiliations[i2].Code))
+		b = appendString(b, order, string(v.Affiliations[i2].Keyword), 2, true)
+	}
+	return b
+}
+
+func (v *ICQ_0x07D0_0x041A_DBQueryMetaReqSetAffiliations) decodeOSCAR(d *decoder) error {
+	{
+		n1, err := d.readLen(1)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.PastAffiliations = nil
+		for range n1 {
+			var e2 struct {
+				Code    uint16
+				Keyword string `oscar:"len_prefix=uint16,nullterm"`
+			}
+			if err := decodeUint16(d, &e2.Code); err != nil {
+				return err
+			}
+			if err := decodeString(d, &e2.Keyword, 2, true); err != nil {
+				return err
+			}
+			v.PastAffiliations = append(v.PastAffiliations, e2)
+		}
+	}
+	{
+		n3, err := d.readLen(1)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n3); err != nil {
+			return err
+		}
+		v.Affiliations = nil
+		for range n3 {
+			var e4 struct {
+				Code    uint16
+				Keyword string `oscar:"len_prefix=uint16,nullterm"`
+			}
+			if err := decodeUint16(d, &e4.Code); err != nil {
+				return err
+			}
+			if err := decodeString(d, &e4.Keyword, 2, true); err != nil {
+				return err
+			}
+			v.Affiliations = append(v.Affiliations, e4)
+		}
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x0424_DBQueryMetaReqSetPermissions) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = append(b, uint8(v.Authorization))
+	b = append(b, uint8(v.WebAware))
+	b = append(b, uint8(v.DCPerms))
+	b = append(b, uint8(v.Unknown))
+	return b
+}
+
+func (v *ICQ_0x07D0_0x0424_DBQueryMetaReqSetPermissions) decodeOSCAR(d *decoder) error {
+	if err := decodeUint8(d, &v.Authorization); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.WebAware); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.DCPerms); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Unknown); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x04BA_DBQueryMetaReqShortInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.UIN))
+	return b
+}
+
+func (v *ICQ_0x07D0_0x04BA_DBQueryMetaReqShortInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.UIN); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x0515_DBQueryMetaReqSearchByDetails) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.FirstName), 2, true)
+	b = appendString(b, order, string(v.LastName), 2, true)
+	b = appendString(b, order, string(v.NickName), 2, true)
+	return b
+}
+
+func (v *ICQ_0x07D0_0x0515_DBQueryMetaReqSearchByDetails) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.FirstName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.LastName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.NickName, 2, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x051F_DBQueryMetaReqSearchByUIN) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.UIN))
+	return b
+}
+
+func (v *ICQ_0x07D0_0x051F_DBQueryMetaReqSearchByUIN) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.UIN); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x0529_DBQueryMetaReqSearchByEmail) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.Email), 2, true)
+	return b
+}
+
+func (v *ICQ_0x07D0_0x0529_DBQueryMetaReqSearchByEmail) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.Email, 2, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x0533_DBQueryMetaReqSearchWhitePages) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.FirstName), 2, true)
+	b = appendString(b, order, string(v.LastName), 2, true)
+	b = appendString(b, order, string(v.Nickname), 2, true)
+	b = appendString(b, order, string(v.Email), 2, true)
+	b = order.AppendUint16(b, uint16(v.MinAge))
+	b = order.AppendUint16(b, uint16(v.MaxAge))
+	b = append(b, uint8(v.Gender))
+	b = append(b, uint8(v.SpeakingLang))
+	b = appendString(b, order, string(v.City), 2, true)
+	b = appendString(b, order, string(v.State), 2, true)
+	b = order.AppendUint16(b, uint16(v.CountryCode))
+	b = appendString(b, order, string(v.Company), 2, true)
+	b = appendString(b, order, string(v.Department), 2, true)
+	b = appendString(b, order, string(v.Position), 2, true)
+	b = order.AppendUint16(b, uint16(v.OccupationCode))
+	b = order.AppendUint16(b, uint16(v.PastCode))
+	b = appendString(b, order, string(v.PastKeywords), 2, true)
+	b = order.AppendUint16(b, uint16(v.InterestsCode))
+	b = appendString(b, order, string(v.InterestsKeyword), 2, true)
+	b = order.AppendUint16(b, uint16(v.AffiliationsCode))
+	b = appendString(b, order, string(v.AffiliationsKeyword), 2, true)
+	b = order.AppendUint16(b, uint16(v.HomePageCode))
+	b = appendString(b, order, string(v.HomePageKeywords), 2, true)
+	b = append(b, uint8(v.SearchScope))
+	return b
+}
+
+func (v *ICQ_0x07D0_0x0533_DBQueryMetaReqSearchWhitePages) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.FirstName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.LastName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Nickname, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Email, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.MinAge); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.MaxAge); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Gender); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.SpeakingLang); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.City, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.State, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.CountryCode); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Company, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Department, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Position, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.OccupationCode); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.PastCode); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.PastKeywords, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.InterestsCode); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.InterestsKeyword, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.AffiliationsCode); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.AffiliationsKeyword, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.HomePageCode); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.HomePageKeywords, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.SearchScope); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x055F_DBQueryMetaReqSearchWhitePages2) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *ICQ_0x07D0_0x055F_DBQueryMetaReqSearchWhitePages2) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x0569_DBQueryMetaReqSearchByUIN2) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *ICQ_0x07D0_0x0569_DBQueryMetaReqSearchByUIN2) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x0573_DBQueryMetaReqSearchByEmail3) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *ICQ_0x07D0_0x0573_DBQueryMetaReqSearchByEmail3) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07D0_0x0898_DBQueryMetaReqXMLReq) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.XMLRequest), 2, true)
+	return b
+}
+
+func (v *ICQ_0x07D0_0x0898_DBQueryMetaReqXMLReq) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.XMLRequest, 2, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x00C8_DBQueryMetaReplyBasicInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	b = appendString(b, order, string(v.Nickname), 2, true)
+	b = appendString(b, order, string(v.FirstName), 2, true)
+	b = appendString(b, order, string(v.LastName), 2, true)
+	b = appendString(b, order, string(v.Email), 2, true)
+	b = appendString(b, order, string(v.City), 2, true)
+	b = appendString(b, order, string(v.State), 2, true)
+	b = appendString(b, order, string(v.Phone), 2, true)
+	b = appendString(b, order, string(v.Fax), 2, true)
+	b = appendString(b, order, string(v.Address), 2, true)
+	b = appendString(b, order, string(v.CellPhone), 2, true)
+	b = appendString(b, order, string(v.ZIP), 2, true)
+	b = order.AppendUint16(b, uint16(v.CountryCode))
+	b = append(b, uint8(v.GMTOffset))
+	b = append(b, uint8(v.AuthFlag))
+	b = append(b, uint8(v.WebAware))
+	b = append(b, uint8(v.DCPerms))
+	b = append(b, uint8(v.PublishEmail))
+	return b
+}
+
+func (v *ICQ_0x07DA_0x00C8_DBQueryMetaReplyBasicInfo) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Nickname, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.FirstName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.LastName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Email, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.City, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.State, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Phone, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Fax, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Address, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.CellPhone, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ZIP, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.CountryCode); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.GMTOffset); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.AuthFlag); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.WebAware); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.DCPerms); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.PublishEmail); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x00D2_DBQueryMetaReplyWorkInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	b = v.ICQ_0x07D0_0x03F3_DBQueryMetaReqSetWorkInfo.appendOSCAR(b, order)
+	return b
+}
+
+func (v *ICQ_0x07DA_0x00D2_DBQueryMetaReplyWorkInfo) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	if err := v.ICQ_0x07D0_0x03F3_DBQueryMetaReqSetWorkInfo.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x00DC_DBQueryMetaReplyMoreInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	b = v.ICQ_0x07D0_0x03FD_DBQueryMetaReqSetMoreInfo.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.Unknown))
+	b = appendString(b, order, string(v.City), 2, true)
+	b = appendString(b, order, string(v.State), 2, true)
+	b = order.AppendUint16(b, uint16(v.CountryCode))
+	b = append(b, uint8(v.TimeZone))
+	return b
+}
+
+func (v *ICQ_0x07DA_0x00DC_DBQueryMetaReplyMoreInfo) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	if err := v.ICQ_0x07D0_0x03FD_DBQueryMetaReqSetMoreInfo.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Unknown); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.City, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.State, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.CountryCode); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.TimeZone); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x00E6_DBQueryMetaReplyNotes) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	b = v.ICQ_0x07D0_0x0406_DBQueryMetaReqSetNotes.appendOSCAR(b, order)
+	return b
+}
+
+func (v *ICQ_0x07DA_0x00E6_DBQueryMetaReplyNotes) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	if err := v.ICQ_0x07D0_0x0406_DBQueryMetaReqSetNotes.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x00EB_DBQueryMetaReplyExtEmailInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	b = appendLen(b, order, 1, len(v.Emails))
+	for i1 := range v.Emails {
+		b = append(b, uint8(v.Emails[i1].Flag))
+		b = appendString(b, order, string(v.Emails[i1].Email), 2, true)
+	}
+	return b
+}
+
+func (v *ICQ_0x07DA_0x00EB_DBQueryMetaReplyExtEmailInfo) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	{
+		n1, err := d.readLen(1)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.Emails = nil
+		for range n1 {
+			var e2 struct {
+				Flag  uint8
+				Email string `oscar:"len_prefix=uint16,nullterm"`
+			}
+			if err := decodeUint8(d, &e2.Flag); err != nil {
+				return err
+			}
+			if err := decodeString(d, &e2.Email, 2, true); err != nil {
+				return err
+			}
+			v.Emails = append(v.Emails, e2)
+		}
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x00F0_DBQueryMetaReplyInterests) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	b = appendLen(b, order, 1, len(v.Interests))
+	for i1 := range v.Interests {
+		b = order.AppendUint16(b, uint16(v.Interests[i1].Code))
+		b = appendString(b, order, string(v.Interests[i1].Keyword), 2, true)
+	}
+	return b
+}
+
+func (v *ICQ_0x07DA_0x00F0_DBQueryMetaReplyInterests) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	{
+		n1, err := d.readLen(1)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.Interests = nil
+		for range n1 {
+			var e2 struct {
+				Code    uint16
+				Keyword string `oscar:"len_prefix=uint16,nullterm"`
+			}
+			if err := decodeUint16(d, &e2.Code); err != nil {
+				return err
+			}
+			if err := decodeString(d, &e2.Keyword, 2, true); err != nil {
+				return err
+			}
+			v.Interests = append(v.Interests, e2)
+		}
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x00FA_DBQueryMetaReplyAffiliations) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	b = v.ICQ_0x07D0_0x041A_DBQueryMetaReqSetAffiliations.appendOSCAR(b, order)
+	return b
+}
+
+func (v *ICQ_0x07DA_0x00FA_DBQueryMetaReplyAffiliations) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	if err := v.ICQ_0x07D0_0x041A_DBQueryMetaReqSetAffiliations.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x0104_DBQueryMetaReplyShortInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	b = appendString(b, order, string(v.Nickname), 2, true)
+	b = appendString(b, order, string(v.FirstName), 2, true)
+	b = appendString(b, order, string(v.LastName), 2, true)
+	b = appendString(b, order, string(v.Email), 2, true)
+	b = append(b, uint8(v.Authorization))
+	b = append(b, uint8(v.Unknown))
+	b = append(b, uint8(v.Gender))
+	return b
+}
+
+func (v *ICQ_0x07DA_0x0104_DBQueryMetaReplyShortInfo) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Nickname, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.FirstName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.LastName, 2, true); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Email, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Authorization); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Unknown); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Gender); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x010E_DBQueryMetaReplyHomePageCat) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	b = append(b, uint8(v.Enabled))
+	b = order.AppendUint16(b, uint16(v.CatCode))
+	b = appendString(b, order, string(v.Keywords), 2, true)
+	b = append(b, uint8(v.Unknown))
+	return b
+}
+
+func (v *ICQ_0x07DA_0x010E_DBQueryMetaReplyHomePageCat) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Enabled); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.CatCode); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Keywords, 2, true); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Unknown); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x01AE_DBQueryMetaReplyLastUserFound) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = v.Details.appendOSCAR(b, order)
+	putLen(b, order, 2, at1)
+	if v.LastMessageFooter != nil {
+		b = order.AppendUint32(b, uint32(v.LastMessageFooter.FoundUsersLeft))
+	}
+	return b
+}
+
+func (v *ICQ_0x07DA_0x01AE_DBQueryMetaReplyLastUserFound) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	d1, err := d.readBlock(2)
+	if err != nil {
+		return err
+	}
+	if err := v.Details.decodeOSCAR(d1); err != nil {
+		return err
+	}
+	p2 := newOf(v.LastMessageFooter)
+	err3 := func() error {
+		if err := decodeUint32(d, &(*p2).FoundUsersLeft); err != nil {
+			return err
+		}
+		return nil
+	}()
+	switch {
+	case errors.Is(err3, io.EOF):
+		v.LastMessageFooter = nil
+	case err3 != nil:
+		return err3
+	default:
+		v.LastMessageFooter = p2
+	}
+	return nil
+}
+
+func (v ICQ_0x07DA_0x08A2_DBQueryMetaReplyXMLData) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.ICQMetadata.appendOSCAR(b, order)
+	b = order.AppendUint16(b, uint16(v.ReqSubType))
+	b = append(b, uint8(v.Success))
+	b = appendString(b, order, string(v.XML), 2, true)
+	return b
+}
+
+func (v *ICQ_0x07DA_0x08A2_DBQueryMetaReplyXMLData) decodeOSCAR(d *decoder) error {
+	if err := v.ICQMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ReqSubType); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Success); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.XML, 2, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v KerberosBOSServerInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Unknown))
+	b = v.ConnectionInfo.appendOSCAR(b, order)
+	return b
+}
+
+func (v *KerberosBOSServerInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Unknown); err != nil {
+		return err
+	}
+	if err := v.ConnectionInfo.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v KerberosLoginRequestTicket) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.Marker))
+	b = order.AppendUint16(b, uint16(v.Version))
+	b = order.AppendUint32(b, uint32(v.Flags))
+	b = order.AppendUint16(b, uint16(v.Unknown))
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = append(b, v.Password...)
+	putLen(b, order, 2, at1)
+	b = v.PasswordMetadata.appendOSCAR(b, order)
+	return b
+}
+
+func (v *KerberosLoginRequestTicket) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.Marker); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Version); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Flags); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Unknown); err != nil {
+		return err
+	}
+	{
+		b, err := d.readBytes(2)
+		if err != nil {
+			return err
+		}
+		v.Password = b
+	}
+	if err := v.PasswordMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v KerberosTicket) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.PVNO))
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = append(b, v.EncTicket...)
+	putLen(b, order, 2, at1)
+	b = appendString(b, order, string(v.TicketRealm), 2, false)
+	b = appendString(b, order, string(v.ServicePrincipal), 2, false)
+	b = appendString(b, order, string(v.ClientRealm), 2, false)
+	b = appendString(b, order, string(v.ClientPrincipal), 2, false)
+	b = append(b, uint8(v.KVNO))
+	at2 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = append(b, v.SessionKey...)
+	putLen(b, order, 2, at2)
+	b = order.AppendUint32(b, uint32(v.Unknown1))
+	b = order.AppendUint32(b, uint32(v.Unknown2))
+	b = order.AppendUint32(b, uint32(v.AuthTime))
+	b = order.AppendUint32(b, uint32(v.StartTime))
+	b = order.AppendUint32(b, uint32(v.EndTime))
+	b = order.AppendUint32(b, uint32(v.RenewTill))
+	b = order.AppendUint32(b, uint32(v.Unknown4))
+	b = order.AppendUint32(b, uint32(v.Unknown5))
+	b = order.AppendUint32(b, uint32(v.Unknown6))
+	b = v.ConnectionMetadata.appendOSCAR(b, order)
+	return b
+}
+
+func (v *KerberosTicket) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.PVNO); err != nil {
+		return err
+	}
+	{
+		b, err := d.readBytes(2)
+		if err != nil {
+			return err
+		}
+		v.EncTicket = b
+	}
+	if err := decodeString(d, &v.TicketRealm, 2, false); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ServicePrincipal, 2, false); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ClientRealm, 2, false); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ClientPrincipal, 2, false); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.KVNO); err != nil {
+		return err
+	}
+	{
+		b, err := d.readBytes(2)
+		if err != nil {
+			return err
+		}
+		v.SessionKey = b
+	}
+	if err := decodeUint32(d, &v.Unknown1); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown2); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.AuthTime); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.StartTime); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.EndTime); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.RenewTill); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown4); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown5); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown6); err != nil {
+		return err
+	}
+	if err := v.ConnectionMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v ODirKeywordListItem) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = append(b, uint8(v.Type))
+	b = append(b, uint8(v.ID))
+	b = appendString(b, order, string(v.Name), 2, false)
+	return b
+}
+
+func (v *ODirKeywordListItem) decodeOSCAR(d *decoder) error {
+	if err := decodeUint8(d, &v.Type); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.ID); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Name, 2, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v RateParamsSNAC) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.ID))
+	b = order.AppendUint32(b, uint32(v.WindowSize))
+	b = order.AppendUint32(b, uint32(v.ClearLevel))
+	b = order.AppendUint32(b, uint32(v.AlertLevel))
+	b = order.AppendUint32(b, uint32(v.LimitLevel))
+	b = order.AppendUint32(b, uint32(v.DisconnectLevel))
+	b = order.AppendUint32(b, uint32(v.CurrentLevel))
+	b = order.AppendUint32(b, uint32(v.MaxLevel))
+	if v.V2Params != nil {
+		b = order.AppendUint32(b, uint32(v.V2Params.LastTime))
+		b = append(b, uint8(v.V2Params.DroppingSNACs))
+	}
+	return b
+}
+
+func (v *RateParamsSNAC) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.ID); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.WindowSize); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.ClearLevel); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.AlertLevel); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.LimitLevel); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.DisconnectLevel); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.CurrentLevel); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.MaxLevel); err != nil {
+		return err
+	}
+	p1 := newOf(v.V2Params)
+	err2 := func() error {
+		if err := decodeUint32(d, &(*p1).LastTime); err != nil {
+			return err
+		}
+		if err := decodeUint8(d, &(*p1).DroppingSNACs); err != nil {
+			return err
+		}
+		return nil
+	}()
+	switch {
+	case errors.Is(err2, io.EOF):
+		v.V2Params = nil
+	case err2 != nil:
+		return err2
+	default:
+		v.V2Params = p1
+	}
+	return nil
+}
+
+func (v SNACError) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Code))
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNACError) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Code); err != nil {
+		return err
+	}
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNACFrame) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.FoodGroup))
+	b = order.AppendUint16(b, uint16(v.SubGroup))
+	b = order.AppendUint16(b, uint16(v.Flags))
+	b = order.AppendUint32(b, uint32(v.RequestID))
+	return b
+}
+
+func (v *SNACFrame) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.FoodGroup); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.SubGroup); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Flags); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.RequestID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x02_OServiceClientOnline) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.GroupVersions {
+		b = order.AppendUint16(b, uint16(v.GroupVersions[i1].FoodGroup))
+		b = order.AppendUint16(b, uint16(v.GroupVersions[i1].Version))
+		b = order.AppendUint16(b, uint16(v.GroupVersions[i1].ToolID))
+		b = order.AppendUint16(b, uint16(v.GroupVersions[i1].ToolVersion))
+	}
+	return b
+}
+
+func (v *SNAC_0x01_0x02_OServiceClientOnline) decodeOSCAR(d *decoder) error {
+	v.GroupVersions = nil
+	for {
+		var e1 struct {
+			FoodGroup   uint16
+			Version     uint16
+			ToolID      uint16
+			ToolVersion uint16
+		}
+		err2 := func() error {
+			if err := decodeUint16(d, &e1.FoodGroup); err != nil {
+				return err
+			}
+			if err := decodeUint16(d, &e1.Version); err != nil {
+				return err
+			}
+			if err := decodeUint16(d, &e1.ToolID); err != nil {
+				return err
+			}
+			if err := decodeUint16(d, &e1.ToolVersion); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.GroupVersions = append(v.GroupVersions, e1)
+		if err := d.checkListLen(len(v.GroupVersions)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x03_OServiceHostOnline) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.FoodGroups {
+		b = order.AppendUint16(b, uint16(v.FoodGroups[i1]))
+	}
+	return b
+}
+
+func (v *SNAC_0x01_0x03_OServiceHostOnline) decodeOSCAR(d *decoder) error {
+	v.FoodGroups = nil
+	for {
+		var e1 uint16
+		err2 := func() error {
+			if err := decodeUint16(d, &e1); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.FoodGroups = append(v.FoodGroups, e1)
+		if err := d.checkListLen(len(v.FoodGroups)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x04_OServiceServiceRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.FoodGroup))
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x01_0x04_OServiceServiceRequest) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.FoodGroup); err != nil {
+		return err
+	}
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x04_TLVRoomInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Exchange))
+	b = appendString(b, order, string(v.Cookie), 1, false)
+	b = order.AppendUint16(b, uint16(v.InstanceNumber))
+	return b
+}
+
+func (v *SNAC_0x01_0x04_TLVRoomInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Exchange); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Cookie, 1, false); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.InstanceNumber); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x05_OServiceServiceResponse) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x01_0x05_OServiceServiceResponse) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x07_OServiceRateParamsReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendLen(b, order, 2, len(v.RateClasses))
+	for i1 := range v.RateClasses {
+		b = v.RateClasses[i1].appendOSCAR(b, order)
+	}
+	for i2 := range v.RateGroups {
+		b = order.AppendUint16(b, uint16(v.RateGroups[i2].ID))
+		b = appendLen(b, order, 2, len(v.RateGroups[i2].Pairs))
+		for i3 := range v.RateGroups[i2].Pairs {
+			b = order.AppendUint16(b, uint16(v.RateGroups[i2].Pairs[i3].FoodGroup))
+			b = order.AppendUint16(b, uint16(v.RateGroups[i2].Pairs[i3].SubGroup))
+		}
+	}
+	return b
+}
+
+func (v *SNAC_0x01_0x07_OServiceRateParamsReply) decodeOSCAR(d *decoder) error {
+	{
+		n1, err := d.readLen(2)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.RateClasses = nil
+		for range n1 {
+			var e2 RateParamsSNAC
+			if err := e2.decodeOSCAR(d); err != nil {
+				return err
+			}
+			v.RateClasses = append(v.RateClasses, e2)
+		}
+	}
+	v.RateGroups = nil
+	for {
+		var e3 struct {
+			ID    uint16
+			Pairs []struct {
+				FoodGroup uint16
+				SubGroup  uint16
+			} `oscar:"count_prefix=uint16"`
+		}
+		err4 := func() error {
+			if err := decodeUint16(d, &e3.ID); err != nil {
+				return err
+			}
+			{
+				n5, err := d.readLen(2)
+				if err != nil {
+					return err
+				}
+				if err := d.checkListLen(n5); err != nil {
+					return err
+				}
+				e3.Pairs = nil
+				for range n5 {
+					var e6 struct {
+						FoodGroup uint16
+						SubGroup  uint16
+					}
+					if err := decodeUint16(d, &e6.FoodGroup); err != nil {
+						return err
+					}
+					if err := decodeUint16(d, &e6.SubGroup); err != nil {
+						return err
+					}
+					e3.Pairs = append(e3.Pairs, e6)
+				}
+			}
+			return nil
+		}()
+		if errors.Is(err4, io.EOF) {
+			break
+		}
+		if err4 != nil {
+			return err4
+		}
+		v.RateGroups = append(v.RateGroups, e3)
+		if err := d.checkListLen(len(v.RateGroups)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x08_OServiceRateParamsSubAdd) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.ClassIDs {
+		b = order.AppendUint16(b, uint16(v.ClassIDs[i1]))
+	}
+	return b
+}
+
+func (v *SNAC_0x01_0x08_OServiceRateParamsSubAdd) decodeOSCAR(d *decoder) error {
+	v.ClassIDs = nil
+	for {
+		var e1 uint16
+		err2 := func() error {
+			if err := decodeUint16(d, &e1); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.ClassIDs = append(v.ClassIDs, e1)
+		if err := d.checkListLen(len(v.ClassIDs)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x0A_OServiceRateParamsChange) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Code))
+	b = v.Rate.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x01_0x0A_OServiceRateParamsChange) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Code); err != nil {
+		return err
+	}
+	if err := v.Rate.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x0F_OServiceUserInfoUpdate) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.UserInfo {
+		b = v.UserInfo[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *SNAC_0x01_0x0F_OServiceUserInfoUpdate) decodeOSCAR(d *decoder) error {
+	v.UserInfo = nil
+	for {
+		var e1 TLVUserInfo
+		err2 := func() error {
+			if err := e1.decodeOSCAR(d); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.UserInfo = append(v.UserInfo, e1)
+		if err := d.checkListLen(len(v.UserInfo)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x10_OServiceEvilNotification) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.NewEvil))
+	if v.Snitcher != nil {
+		b = v.Snitcher.TLVUserInfo.appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *SNAC_0x01_0x10_OServiceEvilNotification) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.NewEvil); err != nil {
+		return err
+	}
+	p1 := newOf(v.Snitcher)
+	err2 := func() error {
+		if err := (*p1).TLVUserInfo.decodeOSCAR(d); err != nil {
+			return err
+		}
+		return nil
+	}()
+	switch {
+	case errors.Is(err2, io.EOF):
+		v.Snitcher = nil
+	case err2 != nil:
+		return err2
+	default:
+		v.Snitcher = p1
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x11_OServiceIdleNotification) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.IdleTime))
+	return b
+}
+
+func (v *SNAC_0x01_0x11_OServiceIdleNotification) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.IdleTime); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x13_OServiceMotd) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.MotdType))
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x01_0x13_OServiceMotd) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.MotdType); err != nil {
+		return err
+	}
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x14_OServiceSetPrivacyFlags) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.PrivacyFlags))
+	return b
+}
+
+func (v *SNAC_0x01_0x14_OServiceSetPrivacyFlags) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.PrivacyFlags); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x17_OServiceClientVersions) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Versions {
+		b = order.AppendUint16(b, uint16(v.Versions[i1]))
+	}
+	return b
+}
+
+func (v *SNAC_0x01_0x17_OServiceClientVersions) decodeOSCAR(d *decoder) error {
+	v.Versions = nil
+	for {
+		var e1 uint16
+		err2 := func() error {
+			if err := decodeUint16(d, &e1); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Versions = append(v.Versions, e1)
+		if err := d.checkListLen(len(v.Versions)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x18_OServiceHostVersions) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Versions {
+		b = order.AppendUint16(b, uint16(v.Versions[i1]))
+	}
+	return b
+}
+
+func (v *SNAC_0x01_0x18_OServiceHostVersions) decodeOSCAR(d *decoder) error {
+	v.Versions = nil
+	for {
+		var e1 uint16
+		err2 := func() error {
+			if err := decodeUint16(d, &e1); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Versions = append(v.Versions, e1)
+		if err := d.checkListLen(len(v.Versions)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x1E_OServiceSetUserInfoFields) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x01_0x1E_OServiceSetUserInfoFields) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x21_OServiceBARTReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.BARTID.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x01_0x21_OServiceBARTReply) decodeOSCAR(d *decoder) error {
+	if err := v.BARTID.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x01_0x23_OServiceBART2Reply) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.ReplyID {
+		b = v.ReplyID[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *SNAC_0x01_0x23_OServiceBART2Reply) decodeOSCAR(d *decoder) error {
+	v.ReplyID = nil
+	for {
+		var e1 BartQueryReplyID
+		err2 := func() error {
+			if err := e1.decodeOSCAR(d); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.ReplyID = append(v.ReplyID, e1)
+		if err := d.checkListLen(len(v.ReplyID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x03_LocateRightsReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x02_0x03_LocateRightsReply) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x04_LocateSetInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x02_0x04_LocateSetInfo) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x05_LocateUserInfoQuery) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Type))
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	return b
+}
+
+func (v *SNAC_0x02_0x05_LocateUserInfoQuery) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Type); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x06_LocateUserInfoReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVUserInfo.appendOSCAR(b, order)
+	b = v.LocateInfo.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x02_0x06_LocateUserInfoReply) decodeOSCAR(d *decoder) error {
+	if err := v.TLVUserInfo.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := v.LocateInfo.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x09_LocateSetDirInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x02_0x09_LocateSetDirInfo) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x0A_LocateSetDirReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Result))
+	return b
+}
+
+func (v *SNAC_0x02_0x0A_LocateSetDirReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Result); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x0B_LocateGetDirInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	return b
+}
+
+func (v *SNAC_0x02_0x0B_LocateGetDirInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x0C_LocateGetDirReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Status))
+	b = v.TLVBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x02_0x0C_LocateGetDirReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Status); err != nil {
+		return err
+	}
+	if err := v.TLVBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x0F_LocateSetKeywordInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x02_0x0F_LocateSetKeywordInfo) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x10_LocateSetKeywordReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Unknown))
+	return b
+}
+
+func (v *SNAC_0x02_0x10_LocateSetKeywordReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Unknown); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x02_0x15_LocateUserInfoQuery2) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.Type2))
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	return b
+}
+
+func (v *SNAC_0x02_0x15_LocateUserInfoQuery2) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.Type2); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x03_0x02_BuddyRightsQuery) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x03_0x02_BuddyRightsQuery) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x03_0x03_BuddyRightsReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x03_0x03_BuddyRightsReply) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x03_0x04_BuddyAddBuddies) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Buddies {
+		b = appendString(b, order, string(v.Buddies[i1].ScreenName), 1, false)
+	}
+	return b
+}
+
+func (v *SNAC_0x03_0x04_BuddyAddBuddies) decodeOSCAR(d *decoder) error {
+	v.Buddies = nil
+	for {
+		var e1 struct {
+			ScreenName string `oscar:"len_prefix=uint8"`
+		}
+		err2 := func() error {
+			if err := decodeString(d, &e1.ScreenName, 1, false); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Buddies = append(v.Buddies, e1)
+		if err := d.checkListLen(len(v.Buddies)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x03_0x05_BuddyDelBuddies) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Buddies {
+		b = appendString(b, order, string(v.Buddies[i1].ScreenName), 1, false)
+	}
+	return b
+}
+
+func (v *SNAC_0x03_0x05_BuddyDelBuddies) decodeOSCAR(d *decoder) error {
+	v.Buddies = nil
+	for {
+		var e1 struct {
+			ScreenName string `oscar:"len_prefix=uint8"`
+		}
+		err2 := func() error {
+			if err := decodeString(d, &e1.ScreenName, 1, false); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Buddies = append(v.Buddies, e1)
+		if err := d.checkListLen(len(v.Buddies)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x03_0x0B_BuddyArrived) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVUserInfo.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x03_0x0B_BuddyArrived) decodeOSCAR(d *decoder) error {
+	if err := v.TLVUserInfo.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x03_0x0C_BuddyDeparted) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVUserInfo.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x03_0x0C_BuddyDeparted) decodeOSCAR(d *decoder) error {
+	if err := v.TLVUserInfo.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x04_0x02_ICBMAddParameters) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Channel))
+	b = order.AppendUint32(b, uint32(v.ICBMFlags))
+	b = order.AppendUint16(b, uint16(v.MaxIncomingICBMLen))
+	b = order.AppendUint16(b, uint16(v.MaxSourceEvil))
+	b = order.AppendUint16(b, uint16(v.MaxDestinationEvil))
+	b = order.AppendUint32(b, uint32(v.MinInterICBMInterval))
+	return b
+}
+
+func (v *SNAC_0x04_0x02_ICBMAddParameters) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Channel); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.ICBMFlags); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.MaxIncomingICBMLen); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.MaxSourceEvil); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.MaxDestinationEvil); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.MinInterICBMInterval); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x04_0x05_ICBMParameterReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.MaxSlots))
+	b = order.AppendUint32(b, uint32(v.ICBMFlags))
+	b = order.AppendUint16(b, uint16(v.MaxIncomingICBMLen))
+	b = order.AppendUint16(b, uint16(v.MaxSourceEvil))
+	b = order.AppendUint16(b, uint16(v.MaxDestinationEvil))
+	b = order.AppendUint32(b, uint32(v.MinInterICBMInterval))
+	return b
+}
+
+func (v *SNAC_0x04_0x05_ICBMParameterReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.MaxSlots); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.ICBMFlags); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.MaxIncomingICBMLen); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.MaxSourceEvil); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.MaxDestinationEvil); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.MinInterICBMInterval); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x04_0x06_ICBMChannelMsgToHost) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint64(b, uint64(v.Cookie))
+	b = order.AppendUint16(b, uint16(v.ChannelID))
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x04_0x06_ICBMChannelMsgToHost) decodeOSCAR(d *decoder) error {
+	if err := decodeUint64(d, &v.Cookie); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ChannelID); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x04_0x07_ICBMChannelMsgToClient) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint64(b, uint64(v.Cookie))
+	b = order.AppendUint16(b, uint16(v.ChannelID))
+	b = v.TLVUserInfo.appendOSCAR(b, order)
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x04_0x07_ICBMChannelMsgToClient) decodeOSCAR(d *decoder) error {
+	if err := decodeUint64(d, &v.Cookie); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ChannelID); err != nil {
+		return err
+	}
+	if err := v.TLVUserInfo.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x04_0x08_ICBMEvilRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.SendAs))
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	return b
+}
+
+func (v *SNAC_0x04_0x08_ICBMEvilRequest) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.SendAs); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x04_0x09_ICBMEvilReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.EvilDeltaApplied))
+	b = order.AppendUint16(b, uint16(v.UpdatedEvilValue))
+	return b
+}
+
+func (v *SNAC_0x04_0x09_ICBMEvilReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.EvilDeltaApplied); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.UpdatedEvilValue); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x04_0x0B_ICBMClientErr) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint64(b, uint64(v.Cookie))
+	b = order.AppendUint16(b, uint16(v.ChannelID))
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = order.AppendUint16(b, uint16(v.Code))
+	b = append(b, v.ErrInfo...)
+	return b
+}
+
+func (v *SNAC_0x04_0x0B_ICBMClientErr) decodeOSCAR(d *decoder) error {
+	if err := decodeUint64(d, &v.Cookie); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ChannelID); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Code); err != nil {
+		return err
+	}
+	{
+		b, err := d.readRest()
+		if err != nil {
+			return err
+		}
+		v.ErrInfo = b
+	}
+	return nil
+}
+
+func (v SNAC_0x04_0x0C_ICBMHostAck) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint64(b, uint64(v.Cookie))
+	b = order.AppendUint16(b, uint16(v.ChannelID))
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	return b
+}
+
+func (v *SNAC_0x04_0x0C_ICBMHostAck) decodeOSCAR(d *decoder) error {
+	if err := decodeUint64(d, &v.Cookie); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ChannelID); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x04_0x10_ICBMSinRetrieve) appendOSCAR(b []byte, order byteOrder) []byte {
+	return b
+}
+
+func (v *SNAC_0x04_0x10_ICBMSinRetrieve) decodeOSCAR(d *decoder) error {
+	return nil
+}
+
+func (v SNAC_0x04_0x14_ICBMClientEvent) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint64(b, uint64(v.Cookie))
+	b = order.AppendUint16(b, uint16(v.ChannelID))
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = order.AppendUint16(b, uint16(v.Event))
+	return b
+}
+
+func (v *SNAC_0x04_0x14_ICBMClientEvent) decodeOSCAR(d *decoder) error {
+	if err := decodeUint64(d, &v.Cookie); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ChannelID); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Event); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x04_0x17_ICBMSinReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	return b
+}
+
+func (v *SNAC_0x04_0x17_ICBMSinReply) decodeOSCAR(d *decoder) error {
+	return nil
+}
+
+func (v SNAC_0x050C_0x0002_KerberosLoginRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.RequestID))
+	b = order.AppendUint32(b, uint32(v.ClientIP))
+	b = order.AppendUint32(b, uint32(v.ClientCOOLVersionMajor))
+	b = order.AppendUint32(b, uint32(v.ClientCOOLVersionMinor))
+	b = order.AppendUint32(b, uint32(v.PaddingOrZero))
+	b = v.KerberosPayload.appendOSCAR(b, order)
+	b = order.AppendUint32(b, uint32(v.LocaleFlags1))
+	b = order.AppendUint32(b, uint32(v.LocaleFlags2))
+	b = appendString(b, order, string(v.CountryCode), 2, false)
+	b = appendString(b, order, string(v.LanguageCode), 2, false)
+	b = v.ServiceContextBlock.appendOSCAR(b, order)
+	b = order.AppendUint32(b, uint32(v.VersionOrFlags))
+	b = append(b, uint8(v.AuthType))
+	b = appendString(b, order, string(v.ClientPrincipal), 2, false)
+	b = appendString(b, order, string(v.ServicePrincipal), 2, false)
+	b = order.AppendUint32(b, uint32(v.Reserved1))
+	b = order.AppendUint16(b, uint16(v.RealmCount))
+	b = v.TicketRequestMetadata.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x050C_0x0002_KerberosLoginRequest) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.RequestID); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.ClientIP); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.ClientCOOLVersionMajor); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.ClientCOOLVersionMinor); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.PaddingOrZero); err != nil {
+		return err
+	}
+	if err := v.KerberosPayload.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.LocaleFlags1); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.LocaleFlags2); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.CountryCode, 2, false); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.LanguageCode, 2, false); err != nil {
+		return err
+	}
+	if err := v.ServiceContextBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.VersionOrFlags); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.AuthType); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ClientPrincipal, 2, false); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ServicePrincipal, 2, false); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Reserved1); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.RealmCount); err != nil {
+		return err
+	}
+	if err := v.TicketRequestMetadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x050C_0x0003_KerberosLoginSuccessResponse) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.RequestID))
+	b = order.AppendUint32(b, uint32(v.Epoch))
+	b = order.AppendUint32(b, uint32(v.Reserved))
+	b = appendString(b, order, string(v.ClientPrincipal), 2, false)
+	b = appendString(b, order, string(v.ClientRealm), 2, false)
+	b = appendLen(b, order, 2, len(v.Tickets))
+	for i1 := range v.Tickets {
+		b = v.Tickets[i1].appendOSCAR(b, order)
+	}
+	b = v.Extensions.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x050C_0x0003_KerberosLoginSuccessResponse) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.RequestID); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Epoch); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Reserved); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ClientPrincipal, 2, false); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ClientRealm, 2, false); err != nil {
+		return err
+	}
+	{
+		n1, err := d.readLen(2)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.Tickets = nil
+		for range n1 {
+			var e2 KerberosTicket
+			if err := e2.decodeOSCAR(d); err != nil {
+				return err
+			}
+			v.Tickets = append(v.Tickets, e2)
+		}
+	}
+	if err := v.Extensions.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x050C_0x0004_KerberosLoginErrResponse) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.KerbRequestID))
+	b = appendString(b, order, string(v.ScreenName), 2, false)
+	b = order.AppendUint16(b, uint16(v.ErrCode))
+	b = appendString(b, order, string(v.Message), 2, false)
+	b = order.AppendUint32(b, uint32(v.Unknown1))
+	b = v.Metadata.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x050C_0x0004_KerberosLoginErrResponse) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.KerbRequestID); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.ScreenName, 2, false); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.ErrCode); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Message, 2, false); err != nil {
+		return err
+	}
+	if err := decodeUint32(d, &v.Unknown1); err != nil {
+		return err
+	}
+	if err := v.Metadata.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x06_0x02_InviteRequestQuery) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x06_0x02_InviteRequestQuery) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x06_0x03_InviteRequestReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	return b
+}
+
+func (v *SNAC_0x06_0x03_InviteRequestReply) decodeOSCAR(d *decoder) error {
+	return nil
+}
+
+func (v SNAC_0x07_0x02_AdminInfoQuery) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x07_0x02_AdminInfoQuery) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x07_0x03_AdminInfoReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Permissions))
+	b = v.TLVBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x07_0x03_AdminInfoReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Permissions); err != nil {
+		return err
+	}
+	if err := v.TLVBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x07_0x04_AdminInfoChangeRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x07_0x04_AdminInfoChangeRequest) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x07_0x05_AdminChangeReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Permissions))
+	b = v.TLVBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x07_0x05_AdminChangeReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Permissions); err != nil {
+		return err
+	}
+	if err := v.TLVBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x07_0x06_AdminConfirmRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	return b
+}
+
+func (v *SNAC_0x07_0x06_AdminConfirmRequest) decodeOSCAR(d *decoder) error {
+	return nil
+}
+
+func (v SNAC_0x07_0x07_AdminConfirmReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Status))
+	b = v.TLV.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x07_0x07_AdminConfirmReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Status); err != nil {
+		return err
+	}
+	if err := v.TLV.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x08_0x02_PopupDisplay) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x08_0x02_PopupDisplay) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x09_0x03_PermitDenyRightsReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x09_0x03_PermitDenyRightsReply) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x09_0x04_PermitDenySetGroupPermitMask) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.PermMask))
+	return b
+}
+
+func (v *SNAC_0x09_0x04_PermitDenySetGroupPermitMask) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.PermMask); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x09_0x05_PermitDenyAddPermListEntries) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Users {
+		b = appendString(b, order, string(v.Users[i1].ScreenName), 1, false)
+	}
+	return b
+}
+
+func (v *SNAC_0x09_0x05_PermitDenyAddPermListEntries) decodeOSCAR(d *decoder) error {
+	v.Users = nil
+	for {
+		var e1 struct {
+			ScreenName string `oscar:"len_prefix=uint8"`
+		}
+		err2 := func() error {
+			if err := decodeString(d, &e1.ScreenName, 1, false); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Users = append(v.Users, e1)
+		if err := d.checkListLen(len(v.Users)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x09_0x06_PermitDenyDelPermListEntries) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Users {
+		b = appendString(b, order, string(v.Users[i1].ScreenName), 1, false)
+	}
+	return b
+}
+
+func (v *SNAC_0x09_0x06_PermitDenyDelPermListEntries) decodeOSCAR(d *decoder) error {
+	v.Users = nil
+	for {
+		var e1 struct {
+			ScreenName string `oscar:"len_prefix=uint8"`
+		}
+		err2 := func() error {
+			if err := decodeString(d, &e1.ScreenName, 1, false); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Users = append(v.Users, e1)
+		if err := d.checkListLen(len(v.Users)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x09_0x07_PermitDenyAddDenyListEntries) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Users {
+		b = appendString(b, order, string(v.Users[i1].ScreenName), 1, false)
+	}
+	return b
+}
+
+func (v *SNAC_0x09_0x07_PermitDenyAddDenyListEntries) decodeOSCAR(d *decoder) error {
+	v.Users = nil
+	for {
+		var e1 struct {
+			ScreenName string `oscar:"len_prefix=uint8"`
+		}
+		err2 := func() error {
+			if err := decodeString(d, &e1.ScreenName, 1, false); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Users = append(v.Users, e1)
+		if err := d.checkListLen(len(v.Users)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x09_0x08_PermitDenyDelDenyListEntries) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Users {
+		b = appendString(b, order, string(v.Users[i1].ScreenName), 1, false)
+	}
+	return b
+}
+
+func (v *SNAC_0x09_0x08_PermitDenyDelDenyListEntries) decodeOSCAR(d *decoder) error {
+	v.Users = nil
+	for {
+		var e1 struct {
+			ScreenName string `oscar:"len_prefix=uint8"`
+		}
+		err2 := func() error {
+			if err := decodeString(d, &e1.ScreenName, 1, false); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Users = append(v.Users, e1)
+		if err := d.checkListLen(len(v.Users)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x0A_0x02_UserLookupFindByEmail) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = append(b, v.Email...)
+	return b
+}
+
+func (v *SNAC_0x0A_0x02_UserLookupFindByEmail) decodeOSCAR(d *decoder) error {
+	{
+		b, err := d.readRest()
+		if err != nil {
+			return err
+		}
+		v.Email = b
+	}
+	return nil
+}
+
+func (v SNAC_0x0A_0x03_UserLookupFindReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x0A_0x03_UserLookupFindReply) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0B_0x02_StatsSetMinReportInterval) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.MinReportInterval))
+	return b
+}
+
+func (v *SNAC_0x0B_0x02_StatsSetMinReportInterval) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.MinReportInterval); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0B_0x03_StatsReportEvents) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x0B_0x03_StatsReportEvents) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0B_0x04_StatsReportAck) appendOSCAR(b []byte, order byteOrder) []byte {
+	return b
+}
+
+func (v *SNAC_0x0B_0x04_StatsReportAck) decodeOSCAR(d *decoder) error {
+	return nil
+}
+
+func (v SNAC_0x0D_0x03_ChatNavRequestExchangeInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Exchange))
+	return b
+}
+
+func (v *SNAC_0x0D_0x03_ChatNavRequestExchangeInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Exchange); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0D_0x04_ChatNavRequestRoomInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Exchange))
+	b = appendString(b, order, string(v.Cookie), 1, false)
+	b = order.AppendUint16(b, uint16(v.InstanceNumber))
+	b = append(b, uint8(v.DetailLevel))
+	return b
+}
+
+func (v *SNAC_0x0D_0x04_ChatNavRequestRoomInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Exchange); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Cookie, 1, false); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.InstanceNumber); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.DetailLevel); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0D_0x09_ChatNavNavInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x0D_0x09_ChatNavNavInfo) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0D_0x09_TLVExchangeInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Identifier))
+	b = v.TLVBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x0D_0x09_TLVExchangeInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Identifier); err != nil {
+		return err
+	}
+	if err := v.TLVBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0E_0x02_ChatRoomInfoUpdate) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Exchange))
+	b = appendString(b, order, string(v.Cookie), 1, false)
+	b = order.AppendUint16(b, uint16(v.InstanceNumber))
+	b = append(b, uint8(v.DetailLevel))
+	b = v.TLVBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x0E_0x02_ChatRoomInfoUpdate) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Exchange); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Cookie, 1, false); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.InstanceNumber); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.DetailLevel); err != nil {
+		return err
+	}
+	if err := v.TLVBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0E_0x03_ChatUsersJoined) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Users {
+		b = v.Users[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *SNAC_0x0E_0x03_ChatUsersJoined) decodeOSCAR(d *decoder) error {
+	v.Users = nil
+	for {
+		var e1 TLVUserInfo
+		err2 := func() error {
+			if err := e1.decodeOSCAR(d); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Users = append(v.Users, e1)
+		if err := d.checkListLen(len(v.Users)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x0E_0x04_ChatUsersLeft) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Users {
+		b = v.Users[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *SNAC_0x0E_0x04_ChatUsersLeft) decodeOSCAR(d *decoder) error {
+	v.Users = nil
+	for {
+		var e1 TLVUserInfo
+		err2 := func() error {
+			if err := e1.decodeOSCAR(d); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Users = append(v.Users, e1)
+		if err := d.checkListLen(len(v.Users)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x0E_0x05_ChatChannelMsgToHost) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint64(b, uint64(v.Cookie))
+	b = order.AppendUint16(b, uint16(v.Channel))
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x0E_0x05_ChatChannelMsgToHost) decodeOSCAR(d *decoder) error {
+	if err := decodeUint64(d, &v.Cookie); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Channel); err != nil {
+		return err
+	}
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0E_0x06_ChatChannelMsgToClient) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint64(b, uint64(v.Cookie))
+	b = order.AppendUint16(b, uint16(v.Channel))
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x0E_0x06_ChatChannelMsgToClient) decodeOSCAR(d *decoder) error {
+	if err := decodeUint64(d, &v.Cookie); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Channel); err != nil {
+		return err
+	}
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0F_0x02_InfoQuery) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x0F_0x02_InfoQuery) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x0F_0x04_KeywordListQuery) appendOSCAR(b []byte, order byteOrder) []byte {
+	return b
+}
+
+func (v *SNAC_0x0F_0x04_KeywordListQuery) decodeOSCAR(d *decoder) error {
+	return nil
+}
+
+func (v SNAC_0x0F_0x04_KeywordListReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Status))
+	b = appendLen(b, order, 2, len(v.Interests))
+	for i1 := range v.Interests {
+		b = v.Interests[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *SNAC_0x0F_0x04_KeywordListReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Status); err != nil {
+		return err
+	}
+	{
+		n1, err := d.readLen(2)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.Interests = nil
+		for range n1 {
+			var e2 ODirKeywordListItem
+			if err := e2.decodeOSCAR(d); err != nil {
+				return err
+			}
+			v.Interests = append(v.Interests, e2)
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x10_0x02_BARTUploadQuery) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Type))
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = append(b, v.Data...)
+	putLen(b, order, 2, at1)
+	return b
+}
+
+func (v *SNAC_0x10_0x02_BARTUploadQuery) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Type); err != nil {
+		return err
+	}
+	{
+		b, err := d.readBytes(2)
+		if err != nil {
+			return err
+		}
+		v.Data = b
+	}
+	return nil
+}
+
+func (v SNAC_0x10_0x03_BARTUploadReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = append(b, uint8(v.Code))
+	b = v.ID.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x10_0x03_BARTUploadReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint8(d, &v.Code); err != nil {
+		return err
+	}
+	if err := v.ID.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x10_0x04_BARTDownloadQuery) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = append(b, uint8(v.Command))
+	b = v.BARTID.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x10_0x04_BARTDownloadQuery) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Command); err != nil {
+		return err
+	}
+	if err := v.BARTID.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x10_0x05_BARTDownloadReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = v.BARTID.appendOSCAR(b, order)
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = append(b, v.Data...)
+	putLen(b, order, 2, at1)
+	return b
+}
+
+func (v *SNAC_0x10_0x05_BARTDownloadReply) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	if err := v.BARTID.decodeOSCAR(d); err != nil {
+		return err
+	}
+	{
+		b, err := d.readBytes(2)
+		if err != nil {
+			return err
+		}
+		v.Data = b
+	}
+	return nil
+}
+
+func (v SNAC_0x10_0x06_BARTDownload2Query) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = appendLen(b, order, 1, len(v.IDs))
+	for i1 := range v.IDs {
+		b = v.IDs[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *SNAC_0x10_0x06_BARTDownload2Query) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	{
+		n1, err := d.readLen(1)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.IDs = nil
+		for range n1 {
+			var e2 BARTID
+			if err := e2.decodeOSCAR(d); err != nil {
+				return err
+			}
+			v.IDs = append(v.IDs, e2)
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x10_0x07_BARTDownload2Reply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = v.ReplyID.appendOSCAR(b, order)
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = append(b, v.Data...)
+	putLen(b, order, 2, at1)
+	return b
+}
+
+func (v *SNAC_0x10_0x07_BARTDownload2Reply) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	if err := v.ReplyID.decodeOSCAR(d); err != nil {
+		return err
+	}
+	{
+		b, err := d.readBytes(2)
+		if err != nil {
+			return err
+		}
+		v.Data = b
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x02_FeedbagRightsQuery) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x13_0x02_FeedbagRightsQuery) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x03_FeedbagRightsReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x13_0x03_FeedbagRightsReply) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x05_FeedbagQueryIfModified) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint32(b, uint32(v.LastUpdate))
+	b = append(b, uint8(v.Count))
+	return b
+}
+
+func (v *SNAC_0x13_0x05_FeedbagQueryIfModified) decodeOSCAR(d *decoder) error {
+	if err := decodeUint32(d, &v.LastUpdate); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Count); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x06_FeedbagReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = append(b, uint8(v.Version))
+	b = appendLen(b, order, 2, len(v.Items))
+	for i1 := range v.Items {
+		b = v.Items[i1].appendOSCAR(b, order)
+	}
+	b = order.AppendUint32(b, uint32(v.LastUpdate))
+	return b
+}
+
+func (v *SNAC_0x13_0x06_FeedbagReply) decodeOSCAR(d *decoder) error {
+	if err := decodeUint8(d, &v.Version); err != nil {
+		return err
+	}
+	{
+		n1, err := d.readLen(2)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.Items = nil
+		for range n1 {
+			var e2 FeedbagItem
+			if err := e2.decodeOSCAR(d); err != nil {
+				return err
+			}
+			v.Items = append(v.Items, e2)
+		}
+	}
+	if err := decodeUint32(d, &v.LastUpdate); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x08_FeedbagInsertItem) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Items {
+		b = v.Items[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *SNAC_0x13_0x08_FeedbagInsertItem) decodeOSCAR(d *decoder) error {
+	v.Items = nil
+	for {
+		var e1 FeedbagItem
+		err2 := func() error {
+			if err := e1.decodeOSCAR(d); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Items = append(v.Items, e1)
+		if err := d.checkListLen(len(v.Items)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x09_FeedbagUpdateItem) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Items {
+		b = v.Items[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *SNAC_0x13_0x09_FeedbagUpdateItem) decodeOSCAR(d *decoder) error {
+	v.Items = nil
+	for {
+		var e1 FeedbagItem
+		err2 := func() error {
+			if err := e1.decodeOSCAR(d); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Items = append(v.Items, e1)
+		if err := d.checkListLen(len(v.Items)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x0A_FeedbagDeleteItem) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Items {
+		b = v.Items[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *SNAC_0x13_0x0A_FeedbagDeleteItem) decodeOSCAR(d *decoder) error {
+	v.Items = nil
+	for {
+		var e1 FeedbagItem
+		err2 := func() error {
+			if err := e1.decodeOSCAR(d); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Items = append(v.Items, e1)
+		if err := d.checkListLen(len(v.Items)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x0E_FeedbagStatus) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.Results {
+		b = order.AppendUint16(b, uint16(v.Results[i1]))
+	}
+	return b
+}
+
+func (v *SNAC_0x13_0x0E_FeedbagStatus) decodeOSCAR(d *decoder) error {
+	v.Results = nil
+	for {
+		var e1 uint16
+		err2 := func() error {
+			if err := decodeUint16(d, &e1); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.Results = append(v.Results, e1)
+		if err := d.checkListLen(len(v.Results)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x11_FeedbagStartCluster) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x13_0x11_FeedbagStartCluster) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x18_FeedbagRequestAuthorizationToHost) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = appendString(b, order, string(v.Reason), 2, false)
+	b = order.AppendUint16(b, uint16(v.Unknown))
+	return b
+}
+
+func (v *SNAC_0x13_0x18_FeedbagRequestAuthorizationToHost) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Reason, 2, false); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.Unknown); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x1A_FeedbagRespondAuthorizeToHost) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = append(b, uint8(v.Accepted))
+	b = appendString(b, order, string(v.Reason), 2, false)
+	return b
+}
+
+func (v *SNAC_0x13_0x1A_FeedbagRespondAuthorizeToHost) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Accepted); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Reason, 2, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x13_0x1B_FeedbagRespondAuthorizeToClient) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = append(b, uint8(v.Accepted))
+	b = appendString(b, order, string(v.Reason), 2, false)
+	return b
+}
+
+func (v *SNAC_0x13_0x1B_FeedbagRespondAuthorizeToClient) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	if err := decodeUint8(d, &v.Accepted); err != nil {
+		return err
+	}
+	if err := decodeString(d, &v.Reason, 2, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x15_0x02_BQuery) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x15_0x02_BQuery) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x15_0x02_DBReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x15_0x02_DBReply) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x17_0x02_BUCPLoginRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x17_0x02_BUCPLoginRequest) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x17_0x03_BUCPLoginResponse) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x17_0x03_BUCPLoginResponse) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x17_0x04_BUCPRegisterRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x17_0x04_BUCPRegisterRequest) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x17_0x05_BUCPRegisterResponse) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x17_0x05_BUCPRegisterResponse) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x17_0x06_BUCPChallengeRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x17_0x06_BUCPChallengeRequest) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x17_0x07_BUCPChallengeResponse) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.AuthKey), 2, false)
+	return b
+}
+
+func (v *SNAC_0x17_0x07_BUCPChallengeResponse) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.AuthKey, 2, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x17_0x0A_BUCPSecuridRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	return b
+}
+
+func (v *SNAC_0x17_0x0A_BUCPSecuridRequest) decodeOSCAR(d *decoder) error {
+	return nil
+}
+
+func (v SNAC_0x17_0x0B_BUCPSecuridResponse) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.SecurID), 2, false)
+	return b
+}
+
+func (v *SNAC_0x17_0x0B_BUCPSecuridResponse) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.SecurID, 2, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x17_0x0C_BUCPRegistrationImageRequest) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x17_0x0C_BUCPRegistrationImageRequest) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v SNAC_0x17_0x0D_BUCPRegistrationImageReply) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = v.TLVRestBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *SNAC_0x17_0x0D_BUCPRegistrationImageReply) decodeOSCAR(d *decoder) error {
+	if err := v.TLVRestBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v TLV) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = order.AppendUint16(b, uint16(v.Tag))
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	b = append(b, v.Value...)
+	putLen(b, order, 2, at1)
+	return b
+}
+
+func (v *TLV) decodeOSCAR(d *decoder) error {
+	if err := decodeUint16(d, &v.Tag); err != nil {
+		return err
+	}
+	{
+		b, err := d.readBytes(2)
+		if err != nil {
+			return err
+		}
+		v.Value = b
+	}
+	return nil
+}
+
+func (v TLVBlock) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendLen(b, order, 2, len(v.TLVList))
+	for i1 := range v.TLVList {
+		b = v.TLVList[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *TLVBlock) decodeOSCAR(d *decoder) error {
+	{
+		n1, err := d.readLen(2)
+		if err != nil {
+			return err
+		}
+		if err := d.checkListLen(n1); err != nil {
+			return err
+		}
+		v.TLVList = nil
+		for range n1 {
+			var e2 TLV
+			if err := e2.decodeOSCAR(d); err != nil {
+				return err
+			}
+			v.TLVList = append(v.TLVList, e2)
+		}
+	}
+	return nil
+}
+
+func (v TLVLBlock) appendOSCAR(b []byte, order byteOrder) []byte {
+	at1 := len(b)
+	b = append(b, make([]byte, 2)...)
+	for i2 := range v.TLVList {
+		b = v.TLVList[i2].appendOSCAR(b, order)
+	}
+	putLen(b, order, 2, at1)
+	return b
+}
+
+func (v *TLVLBlock) decodeOSCAR(d *decoder) error {
+	{
+		d1, err := d.readBlock(2)
+		if err != nil {
+			return err
+		}
+		v.TLVList = nil
+		for d1.more() {
+			var e2 TLV
+			if err := e2.decodeOSCAR(d1); err != nil {
+				return err
+			}
+			v.TLVList = append(v.TLVList, e2)
+			if err := d.checkListLen(len(v.TLVList)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v TLVRestBlock) appendOSCAR(b []byte, order byteOrder) []byte {
+	for i1 := range v.TLVList {
+		b = v.TLVList[i1].appendOSCAR(b, order)
+	}
+	return b
+}
+
+func (v *TLVRestBlock) decodeOSCAR(d *decoder) error {
+	v.TLVList = nil
+	for {
+		var e1 TLV
+		err2 := func() error {
+			if err := e1.decodeOSCAR(d); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if errors.Is(err2, io.EOF) {
+			break
+		}
+		if err2 != nil {
+			return err2
+		}
+		v.TLVList = append(v.TLVList, e1)
+		if err := d.checkListLen(len(v.TLVList)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v TLVUserInfo) appendOSCAR(b []byte, order byteOrder) []byte {
+	b = appendString(b, order, string(v.ScreenName), 1, false)
+	b = order.AppendUint16(b, uint16(v.WarningLevel))
+	b = v.TLVBlock.appendOSCAR(b, order)
+	return b
+}
+
+func (v *TLVUserInfo) decodeOSCAR(d *decoder) error {
+	if err := decodeString(d, &v.ScreenName, 1, false); err != nil {
+		return err
+	}
+	if err := decodeUint16(d, &v.WarningLevel); err != nil {
+		return err
+	}
+	if err := v.TLVBlock.decodeOSCAR(d); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Code generated by marshal_generator. DO NOT EDIT.
+
+package wire
+
+// generatedTypes lists a value of each type that has a generated encoder
+// and decoder.
+var generatedTypes = []any{
+	BARTID{},
+	BARTInfo{},
+	BartIDsWName{},
+	BartQueryReplyID{},
+	FLAPFrame{},
+	FLAPFrameDisconnect{},
+	FLAPSignonFrame{},
+	FeedbagItem{},
+	ICBMCh1Fragment{},
+	ICBMCh1Message{},
+	ICBMCh2Fragment{},
+	ICBMCh4Message{},
+	ICBMRoomInfo{},
+	ICQDCInfo{},
+	ICQEmail{},
+	ICQInterests{},
+	ICQMessageRequestEnvelope{},
+	ICQMetadata{},
+	ICQMetadataWithSubType{},
+	ICQNewUINRequest{},
+	ICQNewUINResponse{},
+	ICQUserSearchRecord{},
+	ICQ_0x0041_DBQueryOfflineMsgReply{},
+	ICQ_0x0042_DBQueryOfflineMsgReplyLast{},
+	ICQ_0x07D0_0x03EA_DBQueryMetaReqSetBasicInfo{},
+	ICQ_0x07D0_0x03F3_DBQueryMetaReqSetWorkInfo{},
+	ICQ_0x07D0_0x03FD_DBQueryMetaReqSetMoreInfo{},
+	ICQ_0x07D0_0x0406_DBQueryMetaReqSetNotes{},
+	ICQ_0x07D0_0x040B_DBQueryMetaReqSetEmails{},
+	ICQ_0x07D0_0x0410_DBQueryMetaReqSetInterests{},
+	ICQ_0x07D0_0x041A_DBQueryMetaReqSetAffiliations{},
+	ICQ_0x07D0_0x0424_DBQueryMetaReqSetPermissions{},
+	ICQ_0x07D0_0x04BA_DBQueryMetaReqShortInfo{},
+	ICQ_0x07D0_0x0515_DBQueryMetaReqSearchByDetails{},
+	ICQ_0x07D0_0x051F_DBQueryMetaReqSearchByUIN{},
+	ICQ_0x07D0_0x0529_DBQueryMetaReqSearchByEmail{},
+	ICQ_0x07D0_0x0533_DBQueryMetaReqSearchWhitePages{},
+	ICQ_0x07D0_0x055F_DBQueryMetaReqSearchWhitePages2{},
+	ICQ_0x07D0_0x0569_DBQueryMetaReqSearchByUIN2{},
+	ICQ_0x07D0_0x0573_DBQueryMetaReqSearchByEmail3{},
+	ICQ_0x07D0_0x0898_DBQueryMetaReqXMLReq{},
+	ICQ_0x07DA_0x00C8_DBQueryMetaReplyBasicInfo{},
+	ICQ_0x07DA_0x00D2_DBQueryMetaReplyWorkInfo{},
+	ICQ_0x07DA_0x00DC_DBQueryMetaReplyMoreInfo{},
+	ICQ_0x07DA_0x00E6_DBQueryMetaReplyNotes{},
+	ICQ_0x07DA_0x00EB_DBQueryMetaReplyExtEmailInfo{},
+	ICQ_0x07DA_0x00F0_DBQueryMetaReplyInterests{},
+	ICQ_0x07DA_0x00FA_DBQueryMetaReplyAffiliations{},
+	ICQ_0x07DA_0x0104_DBQueryMetaReplyShortInfo{},
+	ICQ_0x07DA_0x010E_DBQueryMetaReplyHomePageCat{},
+	ICQ_0x07DA_0x01AE_DBQueryMetaReplyLastUserFound{},
+	ICQ_0x07DA_0x08A2_DBQueryMetaReplyXMLData{},
+	KerberosBOSServerInfo{},
+	KerberosLoginRequestTicket{},
+	KerberosTicket{},
+	ODirKeywordListItem{},
+	RateParamsSNAC{},
+	SNACError{},
+	SNACFrame{},
+	SNAC_0x01_0x02_OServiceClientOnline{},
+	SNAC_0x01_0x03_OServiceHostOnline{},
+	SNAC_0x01_0x04_OServiceServiceRequest{},
+	SNAC_0x01_0x04_TLVRoomInfo{},
+	SNAC_0x01_0x05_OServiceServiceResponse{},
+	SNAC_0x01_0x07_OServiceRateParamsReply{},
+	SNAC_0x01_0x08_OServiceRateParamsSubAdd{},
+	SNAC_0x01_0x0A_OServiceRateParamsChange{},
+	SNAC_0x01_0x0F_OServiceUserInfoUpdate{},
+	SNAC_0x01_0x10_OServiceEvilNotification{},
+	SNAC_0x01_0x11_OServiceIdleNotification{},
+	SNAC_0x01_0x13_OServiceMotd{},
+	SNAC_0x01_0x14_OServiceSetPrivacyFlags{},
+	SNAC_0x01_0x17_OServiceClientVersions{},
+	SNAC_0x01_0x18_OServiceHostVersions{},
+	SNAC_0x01_0x1E_OServiceSetUserInfoFields{},
+	SNAC_0x01_0x21_OServiceBARTReply{},
+	SNAC_0x01_0x23_OServiceBART2Reply{},
+	SNAC_0x02_0x03_LocateRightsReply{},
+	SNAC_0x02_0x04_LocateSetInfo{},
+	SNAC_0x02_0x05_LocateUserInfoQuery{},
+	SNAC_0x02_0x06_LocateUserInfoReply{},
+	SNAC_0x02_0x09_LocateSetDirInfo{},
+	SNAC_0x02_0x0A_LocateSetDirReply{},
+	SNAC_0x02_0x0B_LocateGetDirInfo{},
+	SNAC_0x02_0x0C_LocateGetDirReply{},
+	SNAC_0x02_0x0F_LocateSetKeywordInfo{},
+	SNAC_0x02_0x10_LocateSetKeywordReply{},
+	SNAC_0x02_0x15_LocateUserInfoQuery2{},
+	SNAC_0x03_0x02_BuddyRightsQuery{},
+	SNAC_0x03_0x03_BuddyRightsReply{},
+	SNAC_0x03_0x04_BuddyAddBuddies{},
+	SNAC_0x03_0x05_BuddyDelBuddies{},
+	SNAC_0x03_0x0B_BuddyArrived{},
+	SNAC_0x03_0x0C_BuddyDeparted{},
+	SNAC_0x04_0x02_ICBMAddParameters{},
+	SNAC_0x04_0x05_ICBMParameterReply{},
+	SNAC_0x04_0x06_ICBMChannelMsgToHost{},
+	SNAC_0x04_0x07_ICBMChannelMsgToClient{},
+	SNAC_0x04_0x08_ICBMEvilRequest{},
+	SNAC_0x04_0x09_ICBMEvilReply{},
+	SNAC_0x04_0x0B_ICBMClientErr{},
+	SNAC_0x04_0x0C_ICBMHostAck{},
+	SNAC_0x04_0x10_ICBMSinRetrieve{},
+	SNAC_0x04_0x14_ICBMClientEvent{},
+	SNAC_0x04_0x17_ICBMSinReply{},
+	SNAC_0x050C_0x0002_KerberosLoginRequest{},
+	SNAC_0x050C_0x0003_KerberosLoginSuccessResponse{},
+	SNAC_0x050C_0x0004_KerberosLoginErrResponse{},
+	SNAC_0x06_0x02_InviteRequestQuery{},
+	SNAC_0x06_0x03_InviteRequestReply{},
+	SNAC_0x07_0x02_AdminInfoQuery{},
+	SNAC_0x07_0x03_AdminInfoReply{},
+	SNAC_0x07_0x04_AdminInfoChangeRequest{},
+	SNAC_0x07_0x05_AdminChangeReply{},
+	SNAC_0x07_0x06_AdminConfirmRequest{},
+	SNAC_0x07_0x07_AdminConfirmReply{},
+	SNAC_0x08_0x02_PopupDisplay{},
+	SNAC_0x09_0x03_PermitDenyRightsReply{},
+	SNAC_0x09_0x04_PermitDenySetGroupPermitMask{},
+	SNAC_0x09_0x05_PermitDenyAddPermListEntries{},
+	SNAC_0x09_0x06_PermitDenyDelPermListEntries{},
+	SNAC_0x09_0x07_PermitDenyAddDenyListEntries{},
+	SNAC_0x09_0x08_PermitDenyDelDenyListEntries{},
+	SNAC_0x0A_0x02_UserLookupFindByEmail{},
+	SNAC_0x0A_0x03_UserLookupFindReply{},
+	SNAC_0x0B_0x02_StatsSetMinReportInterval{},
+	SNAC_0x0B_0x03_StatsReportEvents{},
+	SNAC_0x0B_0x04_StatsReportAck{},
+	SNAC_0x0D_0x03_ChatNavRequestExchangeInfo{},
+	SNAC_0x0D_0x04_ChatNavRequestRoomInfo{},
+	SNAC_0x0D_0x09_ChatNavNavInfo{},
+	SNAC_0x0D_0x09_TLVExchangeInfo{},
+	SNAC_0x0E_0x02_ChatRoomInfoUpdate{},
+	SNAC_0x0E_0x03_ChatUsersJoined{},
+	SNAC_0x0E_0x04_ChatUsersLeft{},
+	SNAC_0x0E_0x05_ChatChannelMsgToHost{},
+	SNAC_0x0E_0x06_ChatChannelMsgToClient{},
+	SNAC_0x0F_0x02_InfoQuery{},
+	SNAC_0x0F_0x04_KeywordListQuery{},
+	SNAC_0x0F_0x04_KeywordListReply{},
+	SNAC_0x10_0x02_BARTUploadQuery{},
+	SNAC_0x10_0x03_BARTUploadReply{},
+	SNAC_0x10_0x04_BARTDownloadQuery{},
+	SNAC_0x10_0x05_BARTDownloadReply{},
+	SNAC_0x10_0x06_BARTDownload2Query{},
+	SNAC_0x10_0x07_BARTDownload2Reply{},
+	SNAC_0x13_0x02_FeedbagRightsQuery{},
+	SNAC_0x13_0x03_FeedbagRightsReply{},
+	SNAC_0x13_0x05_FeedbagQueryIfModified{},
+	SNAC_0x13_0x06_FeedbagReply{},
+	SNAC_0x13_0x08_FeedbagInsertItem{},
+	SNAC_0x13_0x09_FeedbagUpdateItem{},
+	SNAC_0x13_0x0A_FeedbagDeleteItem{},
+	SNAC_0x13_0x0E_FeedbagStatus{},
+	SNAC_0x13_0x11_FeedbagStartCluster{},
+	SNAC_0x13_0x18_FeedbagRequestAuthorizationToHost{},
+	SNAC_0x13_0x1A_FeedbagRespondAuthorizeToHost{},
+	SNAC_0x13_0x1B_FeedbagRespondAuthorizeToClient{},
+	SNAC_0x15_0x02_BQuery{},
+	SNAC_0x15_0x02_DBReply{},
+	SNAC_0x17_0x02_BUCPLoginRequest{},
+	SNAC_0x17_0x03_BUCPLoginResponse{},
+	SNAC_0x17_0x04_BUCPRegisterRequest{},
+	SNAC_0x17_0x05_BUCPRegisterResponse{},
+	SNAC_0x17_0x06_BUCPChallengeRequest{},
+	SNAC_0x17_0x07_BUCPChallengeResponse{},
+	SNAC_0x17_0x0A_BUCPSecuridRequest{},
+	SNAC_0x17_0x0B_BUCPSecuridResponse{},
+	SNAC_0x17_0x0C_BUCPRegistrationImageRequest{},
+	SNAC_0x17_0x0D_BUCPRegistrationImageReply{},
+	TLV{},
+	TLVBlock{},
+	TLVLBlock{},
+	TLVRestBlock{},
+	TLVUserInfo{},
+}
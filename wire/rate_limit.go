@@ -1,7 +1,9 @@
 package wire
 
 import (
+	"fmt"
 	"iter"
+	"maps"
 	"time"
 )
 
@@ -119,6 +121,15 @@ func (r RateLimitClasses) All() [5]RateClass {
 	return r.classes
 }
 
+// WithOverrides returns a copy of r in which each class in overrides replaces
+// the class with the same ID. Overrides must have valid IDs.
+func (r RateLimitClasses) WithOverrides(overrides []RateClass) RateLimitClasses {
+	for _, class := range overrides {
+		r.classes[class.ID-1] = class
+	}
+	return r
+}
+
 // DefaultSNACRateLimits returns the default SNAC rate limit mapping used at
 // one point by the original AIM service, as memorialized by the iserverd
 // project.
@@ -425,6 +436,20 @@ func (rg SNACRateLimits) All() iter.Seq[struct {
 	}
 }
 
+// WithClass returns a copy of rg that maps the given SNAC food group and
+// subgroup to a rate limit class.
+func (rg SNACRateLimits) WithClass(foodGroup uint16, subGroup uint16, classID RateLimitClassID) SNACRateLimits {
+	lookup := make(map[uint16]map[uint16]RateLimitClassID, len(rg.lookup)+1)
+	for fg, subGroups := range rg.lookup {
+		lookup[fg] = maps.Clone(subGroups)
+	}
+	if lookup[foodGroup] == nil {
+		lookup[foodGroup] = make(map[uint16]RateLimitClassID)
+	}
+	lookup[foodGroup][subGroup] = classID
+	return SNACRateLimits{lookup: lookup}
+}
+
 // RateClassLookup returns the RateLimitClassID associated with the given SNAC
 // food group and subgroup.
 //
@@ -459,6 +484,24 @@ type RateClass struct {
 	MaxLevel        int32            // Maximum allowed value for the moving average.
 }
 
+// Validate checks that the class has an ID from 1 to 5, a positive window
+// size and levels that fall in order from DisconnectLevel up to MaxLevel.
+func (rc RateClass) Validate() error {
+	switch {
+	case rc.ID < 1 || rc.ID > 5:
+		return fmt.Errorf("invalid rate class ID %d: must be between 1 and 5", rc.ID)
+	case rc.WindowSize < 1:
+		return fmt.Errorf("invalid window size %d for rate class %d: must be greater than 0", rc.WindowSize, rc.ID)
+	case rc.DisconnectLevel < 0 ||
+		rc.DisconnectLevel > rc.LimitLevel ||
+		rc.LimitLevel > rc.AlertLevel ||
+		rc.AlertLevel > rc.ClearLevel ||
+		rc.ClearLevel > rc.MaxLevel:
+		return fmt.Errorf("invalid levels for rate class %d: must satisfy 0 <= disconnect <= limit <= alert <= clear <= max", rc.ID)
+	}
+	return nil
+}
+
 // CheckRateLimit calculates a rate limit status and a new moving average based on
 // the time elapsed between the last event and the current event, a specified rate
 // class, and whether the system is currently limited.
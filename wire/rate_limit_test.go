@@ -291,3 +291,66 @@ func TestSNACRateLimits_All_YieldStopsEarly(t *testing.T) {
 	// Should only yield one entry
 	assert.Equal(t, 1, count)
 }
+
+func TestRateLimitClasses_WithOverrides(t *testing.T) {
+	classes := DefaultRateLimitClasses()
+	override := RateClass{
+		ID:              3,
+		WindowSize:      10,
+		ClearLevel:      100,
+		AlertLevel:      90,
+		LimitLevel:      80,
+		DisconnectLevel: 70,
+		MaxLevel:        200,
+	}
+
+	have := classes.WithOverrides([]RateClass{override})
+
+	assert.Equal(t, override, have.Get(3))
+	assert.Equal(t, classes.Get(1), have.Get(1))
+	// the original is left as-is
+	assert.Equal(t, DefaultRateLimitClasses(), classes)
+}
+
+func TestSNACRateLimits_WithClass(t *testing.T) {
+	limits := DefaultSNACRateLimits()
+
+	have := limits.WithClass(ICBM, ICBMChannelMsgToHost, 5).WithClass(0xFFFF, 0x0001, 2)
+
+	classID, ok := have.RateClassLookup(ICBM, ICBMChannelMsgToHost)
+	assert.True(t, ok)
+	assert.Equal(t, RateLimitClassID(5), classID)
+	classID, ok = have.RateClassLookup(0xFFFF, 0x0001)
+	assert.True(t, ok)
+	assert.Equal(t, RateLimitClassID(2), classID)
+
+	// the original is left as-is
+	classID, _ = limits.RateClassLookup(ICBM, ICBMChannelMsgToHost)
+	assert.Equal(t, RateLimitClassID(3), classID)
+	_, ok = limits.RateClassLookup(0xFFFF, 0x0001)
+	assert.False(t, ok)
+}
+
+func TestRateClass_Validate(t *testing.T) {
+	valid := DefaultRateLimitClasses().Get(1)
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(rc *RateClass)
+	}{
+		{name: "ID too low", modify: func(rc *RateClass) { rc.ID = 0 }},
+		{name: "ID too high", modify: func(rc *RateClass) { rc.ID = 6 }},
+		{name: "empty window", modify: func(rc *RateClass) { rc.WindowSize = 0 }},
+		{name: "negative disconnect level", modify: func(rc *RateClass) { rc.DisconnectLevel = -1 }},
+		{name: "limit below disconnect", modify: func(rc *RateClass) { rc.LimitLevel = rc.DisconnectLevel - 1 }},
+		{name: "clear above max", modify: func(rc *RateClass) { rc.ClearLevel = rc.MaxLevel + 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := valid
+			tt.modify(&rc)
+			assert.Error(t, rc.Validate())
+		})
+	}
+}
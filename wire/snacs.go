@@ -224,11 +224,18 @@ const (
 
 	OServiceTLVTagsReconnectHere uint16 = 0x05
 	OServiceTLVTagsLoginCookie   uint16 = 0x06
+	OServiceTLVTagsMotdMessage   uint16 = 0x0B
 	OServiceTLVTagsGroupID       uint16 = 0x0D
 	OServiceTLVTagsSSLCertName   uint16 = 0x8D
 	OServiceTLVTagsSSLState      uint16 = 0x8E
 	OserviceTLVTagsSSLUseSSL     uint16 = 0x8C
 
+	OServiceMotdTypeMandatoryUpgrade uint16 = 0x01
+	OServiceMotdTypeAdvisableUpgrade uint16 = 0x02
+	OServiceMotdTypeSystemBulletin   uint16 = 0x03
+	OServiceMotdTypeNormal           uint16 = 0x04
+	OServiceMotdTypeNews             uint16 = 0x06
+
 	OServiceDiscErrNewLogin   uint8 = 0x01
 	OServiceDiscErrAccDeleted uint8 = 0x02
 
@@ -341,6 +348,13 @@ type SNAC_0x01_0x11_OServiceIdleNotification struct {
 	IdleTime uint32
 }
 
+// Sent by the server to display the message of the day
+// - OServiceTLVTagsMotdMessage: the message text
+type SNAC_0x01_0x13_OServiceMotd struct {
+	MotdType uint16
+	TLVRestBlock
+}
+
 type SNAC_0x01_0x14_OServiceSetPrivacyFlags struct {
 	PrivacyFlags uint32
 }
@@ -822,6 +836,8 @@ type SNAC_0x04_0x0C_ICBMHostAck struct {
 	ScreenName string `oscar:"len_prefix=uint8"`
 }
 
+type SNAC_0x04_0x10_ICBMSinRetrieve struct{}
+
 type SNAC_0x04_0x14_ICBMClientEvent struct {
 	Cookie     uint64
 	ChannelID  uint16
@@ -829,6 +845,8 @@ type SNAC_0x04_0x14_ICBMClientEvent struct {
 	Event      uint16
 }
 
+type SNAC_0x04_0x17_ICBMSinReply struct{}
+
 //
 // 0x05: Advert
 //
@@ -847,8 +865,20 @@ const (
 	InviteErr          uint16 = 0x0001
 	InviteRequestQuery uint16 = 0x0002
 	InviteRequestReply uint16 = 0x0003
+
+	InviteTLVEmailAddress uint16 = 0x0011
+	InviteTLVMessage      uint16 = 0x0015
 )
 
+// Sent when the user invites a friend to join AIM by email
+// - InviteTLVEmailAddress
+// - InviteTLVMessage
+type SNAC_0x06_0x02_InviteRequestQuery struct {
+	TLVRestBlock
+}
+
+type SNAC_0x06_0x03_InviteRequestReply struct{}
+
 //
 // 0x07: Admin
 //
@@ -961,8 +991,24 @@ type SNAC_0x07_0x07_AdminConfirmReply struct {
 const (
 	PopupErr     uint16 = 0x0001
 	PopupDisplay uint16 = 0x0002
+
+	PopupTLVMessage uint16 = 0x0001
+	PopupTLVURL     uint16 = 0x0002
+	PopupTLVWidth   uint16 = 0x0003
+	PopupTLVHeight  uint16 = 0x0004
+	PopupTLVDelay   uint16 = 0x0005
 )
 
+// Sent by the server to display a popup window on the client
+// - PopupTLVMessage: HTML body of the popup
+// - PopupTLVURL: URL of a page to show in the popup
+// - PopupTLVWidth: popup width in pixels
+// - PopupTLVHeight: popup height in pixels
+// - PopupTLVDelay: seconds to wait before showing the popup
+type SNAC_0x08_0x02_PopupDisplay struct {
+	TLVRestBlock
+}
+
 //
 // 0x09: PermitDeny
 //
@@ -2149,11 +2195,26 @@ const (
 	BUCPLoginRequest             uint16 = 0x0002
 	BUCPLoginResponse            uint16 = 0x0003
 	BUCPRegisterRequest          uint16 = 0x0004
+	BUCPRegisterResponse         uint16 = 0x0005
 	BUCPChallengeRequest         uint16 = 0x0006
 	BUCPChallengeResponse        uint16 = 0x0007
 	BUCPAsasnRequest             uint16 = 0x0008
 	BUCPSecuridRequest           uint16 = 0x000A
+	BUCPSecuridResponse          uint16 = 0x000B
 	BUCPRegistrationImageRequest uint16 = 0x000C
+	BUCPRegistrationImageReply   uint16 = 0x000D
+)
+
+const (
+	// BUCPRegisterTLVTagsICQInfo contains an ICQNewUINRequest or
+	// ICQNewUINResponse. AIM registration requests carry the screen name
+	// in the same tag (LoginTLVTagsScreenName) along with
+	// LoginTLVTagsRoastedPassword.
+	BUCPRegisterTLVTagsICQInfo uint16 = 0x01
+	BUCPRegisterTLVTagsCaptcha uint16 = 0x09
+
+	BUCPRegistrationImageTLVTagsMIMEType uint16 = 0x01
+	BUCPRegistrationImageTLVTagsImage    uint16 = 0x02
 )
 
 type SNAC_0x17_0x02_BUCPLoginRequest struct {
@@ -2164,6 +2225,69 @@ type SNAC_0x17_0x03_BUCPLoginResponse struct {
 	TLVRestBlock
 }
 
+type SNAC_0x17_0x04_BUCPRegisterRequest struct {
+	TLVRestBlock
+}
+
+type SNAC_0x17_0x05_BUCPRegisterResponse struct {
+	TLVRestBlock
+}
+
+// ICQNewUINRequest is the registration info sent by ICQ clients to request
+// a new UIN. It is encoded in little-endian order. The cookies are opaque
+// values that the server echoes back in ICQNewUINResponse.
+type ICQNewUINRequest struct {
+	Unknown1  uint32
+	Unknown2  uint16 // 0x0028
+	Unknown3  uint16 // 0x0003
+	Unknown4  uint32
+	Unknown5  uint32
+	Cookie1   uint32
+	Cookie2   uint32
+	Unknown6  uint32
+	Unknown7  uint32
+	Unknown8  uint32
+	Unknown9  uint32
+	Password  string `oscar:"len_prefix=uint16,nullterm"`
+	Cookie3   uint32
+	Unknown10 uint32
+}
+
+// ICQNewUINResponse tells an ICQ client the UIN assigned by a successful
+// registration. It is encoded in little-endian order.
+type ICQNewUINResponse struct {
+	Unknown1 uint32
+	Unknown2 uint16 // 0x002D
+	Unknown3 uint16 // 0x0003
+	Unknown4 uint32
+	Unknown5 uint32
+	Cookie1  uint32
+	Cookie2  uint32
+	Unknown6 uint32
+	Unknown7 uint32
+	UIN      uint32
+	Cookie3  uint32
+}
+
+// SNAC_0x17_0x0A_BUCPSecuridRequest asks the client for the one-time code of
+// an account enrolled in two-factor auth. The client prompts the user and
+// answers with SNAC_0x17_0x0B_BUCPSecuridResponse.
+type SNAC_0x17_0x0A_BUCPSecuridRequest struct{}
+
+// SNAC_0x17_0x0B_BUCPSecuridResponse contains the one-time code entered by
+// the user.
+type SNAC_0x17_0x0B_BUCPSecuridResponse struct {
+	SecurID string `oscar:"len_prefix=uint16"`
+}
+
+type SNAC_0x17_0x0C_BUCPRegistrationImageRequest struct {
+	TLVRestBlock
+}
+
+type SNAC_0x17_0x0D_BUCPRegistrationImageReply struct {
+	TLVRestBlock
+}
+
 type SNAC_0x17_0x06_BUCPChallengeRequest struct {
 	TLVRestBlock
 }